@@ -273,7 +273,7 @@ func UpdateAdminInstanceTypePermissions(c *gin.Context) {
 
 // AdminInstanceAction 管理员执行实例操作
 // @Summary 管理员执行实例操作
-// @Description 管理员对实例执行启动、停止、重启等操作
+// @Description 管理员对实例执行启动、停止、重启、重置等操作，重置时可通过imageId指定重装的系统镜像
 // @Tags 管理员管理
 // @Accept json
 // @Produce json
@@ -399,6 +399,51 @@ func ResetInstancePassword(c *gin.Context) {
 	common.ResponseSuccess(c, response, "密码重置任务创建成功")
 }
 
+// MigrateInstance 管理员迁移实例
+// @Summary 管理员迁移实例
+// @Description 将实例冷迁移到同类型的其他Provider：停机导出、在目标节点创建并导入，随后切换实例归属、端口映射、流量监控和资源占用，最后删除源实例
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.MigrateInstanceRequest true "迁移实例请求参数"
+// @Success 200 {object} common.Response{data=admin.MigrateInstanceResponse} "任务创建成功，返回任务ID"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/instances/{id}/migrate [post]
+func MigrateInstance(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.MigrateInstanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	instanceService := instance.NewService(task.GetTaskService())
+	taskID, err := instanceService.MigrateInstance(uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例迁移任务失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Uint("targetProviderID", req.TargetProviderID),
+			zap.Error(err))
+		if err.Error() == "实例不存在" {
+			common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, admin.MigrateInstanceResponse{TaskID: taskID}, "迁移任务创建成功")
+}
+
 // GetInstanceNewPassword 管理员获取实例重置后的新密码
 // @Summary 管理员获取实例重置后的新密码
 // @Description 通过任务ID获取实例重置后的新密码
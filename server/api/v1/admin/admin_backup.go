@@ -0,0 +1,260 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/backup"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetBackupList 获取备份列表
+// @Summary 获取备份列表
+// @Description 管理员分页获取所有实例备份
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "实例名称"
+// @Param instanceId query int false "实例ID"
+// @Param providerId query int false "Provider ID"
+// @Param userId query int false "用户ID"
+// @Param status query string false "状态"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/backups [get]
+func GetBackupList(c *gin.Context) {
+	var req admin.BackupListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	backups, total, err := backup.NewService().ListBackups(req)
+	if err != nil {
+		global.APP_LOG.Error("获取备份列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取备份列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, backups, total, req.Page, req.PageSize)
+}
+
+// GetInstanceBackupsAdmin 获取指定实例的备份
+// @Summary 获取实例备份列表
+// @Description 管理员获取指定实例的所有备份
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]provider.InstanceBackup} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/instances/{id}/backups [get]
+func GetInstanceBackupsAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	backups, err := backup.NewService().ListInstanceBackups(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, backups)
+}
+
+// CreateInstanceBackupAdmin 为实例创建备份
+// @Summary 创建实例备份
+// @Description 管理员为指定实例创建备份，不受用户等级备份权限限制
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=object} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/instances/{id}/backups [post]
+func CreateInstanceBackupAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "实例不存在"))
+		return
+	}
+
+	bak, taskModel, err := backup.NewService().CreateBackup(&instance, providerModel.BackupTriggerManual, false)
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例备份失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, gin.H{
+		"taskId":   taskModel.ID,
+		"backupId": bak.ID,
+	}, "备份任务创建成功")
+}
+
+// RestoreBackupAdmin 恢复备份
+// @Summary 恢复备份
+// @Description 管理员将备份恢复到指定实例，目标实例可以位于其他同类型的Provider上
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "备份ID"
+// @Param request body admin.RestoreBackupRequest true "恢复备份请求参数"
+// @Success 200 {object} common.Response{data=object} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "备份或实例不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/backups/{id}/restore [post]
+func RestoreBackupAdmin(c *gin.Context) {
+	backupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的备份ID"))
+		return
+	}
+
+	var req admin.RestoreBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	backupService := backup.NewService()
+	bak, err := backupService.GetBackup(uint(backupID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	var target providerModel.Instance
+	if err := global.APP_DB.First(&target, req.TargetInstanceID).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "目标实例不存在"))
+		return
+	}
+
+	taskModel, err := backupService.RestoreBackup(bak, &target)
+	if err != nil {
+		global.APP_LOG.Warn("管理员恢复备份失败",
+			zap.Uint64("backupID", backupID),
+			zap.Uint("targetInstanceID", req.TargetInstanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, gin.H{
+		"taskId":   taskModel.ID,
+		"backupId": bak.ID,
+	}, "备份恢复任务创建成功")
+}
+
+// DeleteBackupAdmin 删除备份
+// @Summary 删除备份
+// @Description 管理员删除指定备份及其归档文件
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "备份ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "备份不存在"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /admin/backups/{id} [delete]
+func DeleteBackupAdmin(c *gin.Context) {
+	backupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的备份ID"))
+		return
+	}
+
+	backupService := backup.NewService()
+	bak, err := backupService.GetBackup(uint(backupID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	if err := backupService.DeleteBackup(bak); err != nil {
+		global.APP_LOG.Warn("管理员删除备份失败",
+			zap.Uint64("backupID", backupID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "备份删除成功")
+}
+
+// SetBackupScheduleAdmin 设置实例定时备份计划
+// @Summary 设置实例定时备份计划
+// @Description 管理员创建或更新指定实例的定时备份计划
+// @Tags 备份管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.BackupScheduleRequest true "定时备份计划参数"
+// @Success 200 {object} common.Response{data=provider.BackupSchedule} "设置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "设置失败"
+// @Router /admin/instances/{id}/backup-schedule [put]
+func SetBackupScheduleAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.BackupScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "实例不存在"))
+		return
+	}
+
+	schedule, err := backup.NewService().SetSchedule(&instance, req, false)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, schedule, "定时备份设置成功")
+}
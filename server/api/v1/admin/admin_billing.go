@@ -0,0 +1,251 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/billing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetBillingPriceList 获取价格规则列表
+// @Summary 获取价格规则列表
+// @Description 管理员分页获取实例价格规则，金额单位为分
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "规则名称"
+// @Param providerId query int false "Provider ID"
+// @Param instanceType query string false "实例类型"
+// @Param enabled query bool false "是否启用"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/billing/prices [get]
+func GetBillingPriceList(c *gin.Context) {
+	var req admin.BillingPriceListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	prices, total, err := billing.NewService().ListPrices(req)
+	if err != nil {
+		global.APP_LOG.Error("获取价格规则列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取价格规则列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, prices, total, req.Page, req.PageSize)
+}
+
+// CreateBillingPrice 创建价格规则
+// @Summary 创建价格规则
+// @Description 管理员创建实例价格规则，小时价格和月价格至少设置一项，设置了小时价格时按小时计费
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.BillingPriceRequest true "价格规则"
+// @Success 200 {object} common.Response{data=provider.BillingPrice} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/billing/prices [post]
+func CreateBillingPrice(c *gin.Context) {
+	var req admin.BillingPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	price, err := billing.NewService().CreatePrice(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, price, "价格规则创建成功")
+}
+
+// UpdateBillingPrice 更新价格规则
+// @Summary 更新价格规则
+// @Description 管理员更新实例价格规则，已扣款的周期不受影响
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "价格规则ID"
+// @Param request body admin.BillingPriceRequest true "价格规则"
+// @Success 200 {object} common.Response{data=provider.BillingPrice} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/billing/prices/{id} [put]
+func UpdateBillingPrice(c *gin.Context) {
+	priceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的价格规则ID"))
+		return
+	}
+
+	var req admin.BillingPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	price, err := billing.NewService().UpdatePrice(uint(priceID), req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, price, "价格规则更新成功")
+}
+
+// DeleteBillingPrice 删除价格规则
+// @Summary 删除价格规则
+// @Description 管理员删除实例价格规则，不影响已产生的流水
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "价格规则ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /admin/billing/prices/{id} [delete]
+func DeleteBillingPrice(c *gin.Context) {
+	priceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的价格规则ID"))
+		return
+	}
+
+	if err := billing.NewService().DeletePrice(uint(priceID)); err != nil {
+		global.APP_LOG.Warn("删除价格规则失败", zap.Uint64("priceID", priceID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "价格规则删除成功")
+}
+
+// GetBalanceTransactions 获取余额流水
+// @Summary 获取余额流水
+// @Description 管理员分页获取所有用户的余额流水
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param userId query int false "用户ID"
+// @Param instanceId query int false "实例ID"
+// @Param type query string false "流水类型：recharge, charge, adjust, refund"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/billing/transactions [get]
+func GetBalanceTransactions(c *gin.Context) {
+	var req admin.BalanceTransactionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	records, total, err := billing.NewService().ListTransactions(req.UserID, req.InstanceID, req.Type, req.Page, req.PageSize)
+	if err != nil {
+		global.APP_LOG.Error("获取余额流水失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取余额流水失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, records, total, req.Page, req.PageSize)
+}
+
+// GetUserBalance 获取用户余额
+// @Summary 获取用户余额
+// @Description 管理员获取指定用户的余额和当前运行实例的小时费用
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} common.Response{data=user.BalanceResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/users/{id}/balance [get]
+func GetUserBalance(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的用户ID"))
+		return
+	}
+
+	balance, err := billing.NewService().GetBalanceInfo(uint(userID))
+	if err != nil {
+		global.APP_LOG.Error("获取用户余额失败", zap.Uint64("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取用户余额失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, balance)
+}
+
+// AdjustUserBalance 变更用户余额
+// @Summary 变更用户余额
+// @Description 管理员为用户充值、退款或调整余额，每次变更都会写入不可修改的流水；余额变为正数后自动解冻因余额不足被冻结的实例
+// @Tags 计费管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body admin.BalanceAdjustRequest true "变更参数"
+// @Success 200 {object} common.Response{data=user.BalanceTransaction} "变更成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "未授权"
+// @Router /admin/users/{id}/balance [post]
+func AdjustUserBalance(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的用户ID"))
+		return
+	}
+
+	var req admin.BalanceAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	operatorID, err := getUserIDFromContext(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	record, err := billing.NewService().AdjustBalance(operatorID, uint(userID), req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, record, "余额变更成功")
+}
@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetDomainList 获取域名绑定列表
+// @Summary 获取域名绑定列表
+// @Description 管理员分页获取所有用户的域名绑定
+// @Tags 域名绑定管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "域名"
+// @Param userId query int false "用户ID"
+// @Param providerId query int false "Provider ID"
+// @Param status query string false "状态" Enums(pending, active, error)
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/domains [get]
+func GetDomainList(c *gin.Context) {
+	var req admin.DomainListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	bindings, total, err := domain.NewService().ListDomains(req)
+	if err != nil {
+		global.APP_LOG.Error("获取域名绑定列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取域名绑定列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, bindings, total, req.Page, req.PageSize)
+}
+
+// DeleteDomainAdmin 删除域名绑定
+// @Summary 删除域名绑定
+// @Description 管理员删除任意用户的域名绑定并从节点的反向代理中移除
+// @Tags 域名绑定管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名绑定ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "域名绑定不存在"
+// @Router /admin/domains/{id} [delete]
+func DeleteDomainAdmin(c *gin.Context) {
+	domainID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的域名绑定ID"))
+		return
+	}
+
+	if err := domain.NewService().DeleteDomainAdmin(uint(domainID)); err != nil {
+		if errors.Is(err, domain.ErrDomainNotFound) {
+			common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "域名绑定已删除")
+}
+
+// SyncProviderDomains 重新下发节点的反向代理配置
+// @Summary 重新下发节点的反向代理配置
+// @Description 节点重装nginx或配置被手动修改后，按该Provider上的全部域名绑定重新生成并下发反向代理配置
+// @Tags 域名绑定管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response "同步成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "同步失败"
+// @Router /admin/providers/{id}/domains/sync [post]
+func SyncProviderDomains(c *gin.Context) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return
+	}
+
+	if err := domain.NewService().SyncProvider(uint(providerID)); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "反向代理配置已同步")
+}
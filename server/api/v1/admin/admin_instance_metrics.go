@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/instancemetrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetInstanceMetricsAdmin 获取实例资源使用曲线
+// @Summary 获取实例资源使用曲线
+// @Description 获取任意实例的CPU、内存、磁盘使用曲线，24小时以内为原始采样，7天及以上为小时汇总（含平均值与最大值）
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param range query string false "时间范围：1h, 6h, 24h, 7d, 30d" default(24h)
+// @Success 200 {object} common.Response{data=monitoring.ResourceUsageSeries} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/instances/{id}/metrics [get]
+func GetInstanceMetricsAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	metricsRange := c.DefaultQuery("range", instancemetrics.DefaultRange)
+	if !instancemetrics.IsValidRange(metricsRange) {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的时间范围"))
+		return
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", instanceID).Count(&count).Error; err != nil || count == 0 {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "实例不存在"))
+		return
+	}
+
+	series, err := instancemetrics.NewService().GetSeries(uint(instanceID), metricsRange)
+	if err != nil {
+		global.APP_LOG.Error("获取实例资源使用历史失败", zap.Uint64("instanceID", instanceID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取实例资源使用历史失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, series)
+}
+
+// GetProviderInstanceMetrics 获取Provider上各实例当前的资源使用情况
+// @Summary 获取Provider上各实例当前的资源使用情况
+// @Description 获取Provider上所有运行中实例最近一次采集到的CPU、内存、磁盘使用情况，按CPU使用率从高到低排列
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=[]instancemetrics.InstanceLatestUsage} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/instance-metrics [get]
+func GetProviderInstanceMetrics(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	usages, err := instancemetrics.NewService().GetProviderLatest(p.ID)
+	if err != nil {
+		global.APP_LOG.Error("获取实例资源使用情况失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取实例资源使用情况失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, usages)
+}
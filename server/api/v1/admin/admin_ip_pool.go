@@ -0,0 +1,251 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/ipam"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetIPPoolList 获取地址池列表
+// @Summary 获取地址池列表
+// @Description 管理员分页获取独立IP地址池及其使用情况（总数、已分配、保留、空闲）
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "名称或网段"
+// @Param providerId query int false "Provider ID"
+// @Param family query string false "地址族：ipv4, ipv6"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/ip-pools [get]
+func GetIPPoolList(c *gin.Context) {
+	var req admin.IPPoolListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	pools, total, err := ipam.NewService().ListPools(req)
+	if err != nil {
+		global.APP_LOG.Error("获取地址池列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取地址池列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, pools, total, req.Page, req.PageSize)
+}
+
+// GetIPPool 获取地址池详情
+// @Summary 获取地址池详情
+// @Description 管理员获取单个地址池及其使用情况
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址池ID"
+// @Success 200 {object} common.Response{data=ipam.PoolView} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/ip-pools/{id} [get]
+func GetIPPool(c *gin.Context) {
+	poolID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址池ID"))
+		return
+	}
+
+	pool, err := ipam.NewService().GetPool(uint(poolID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, pool)
+}
+
+// CreateIPPool 创建地址池
+// @Summary 创建地址池
+// @Description 管理员为Provider登记独立IP网段或地址列表。未指定地址列表时使用网段内全部可用地址，网关地址不参与分配
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.CreateIPPoolRequest true "地址池参数"
+// @Success 200 {object} common.Response{data=ipam.PoolView} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/ip-pools [post]
+func CreateIPPool(c *gin.Context) {
+	var req admin.CreateIPPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	pool, err := ipam.NewService().CreatePool(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, pool, "地址池创建成功")
+}
+
+// UpdateIPPool 更新地址池
+// @Summary 更新地址池
+// @Description 管理员更新地址池名称、网关、状态和描述，停用后不再分配新地址
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址池ID"
+// @Param request body admin.UpdateIPPoolRequest true "地址池参数"
+// @Success 200 {object} common.Response{data=ipam.PoolView} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/ip-pools/{id} [put]
+func UpdateIPPool(c *gin.Context) {
+	poolID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址池ID"))
+		return
+	}
+
+	var req admin.UpdateIPPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	pool, err := ipam.NewService().UpdatePool(uint(poolID), req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, pool, "地址池更新成功")
+}
+
+// DeleteIPPool 删除地址池
+// @Summary 删除地址池
+// @Description 管理员删除地址池，仍有地址分配给实例时无法删除
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址池ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/ip-pools/{id} [delete]
+func DeleteIPPool(c *gin.Context) {
+	poolID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址池ID"))
+		return
+	}
+
+	if err := ipam.NewService().DeletePool(uint(poolID)); err != nil {
+		global.APP_LOG.Warn("删除地址池失败", zap.Uint64("poolID", poolID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "地址池删除成功")
+}
+
+// GetIPPoolAddresses 获取地址池中的地址
+// @Summary 获取地址池地址列表
+// @Description 管理员分页查看地址池中的地址、状态及分配的实例
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址池ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "地址"
+// @Param status query string false "状态：free, allocated, reserved"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/ip-pools/{id}/addresses [get]
+func GetIPPoolAddresses(c *gin.Context) {
+	poolID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址池ID"))
+		return
+	}
+
+	var req admin.IPAddressListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 500 {
+		req.PageSize = 10
+	}
+
+	addresses, total, err := ipam.NewService().ListAddresses(uint(poolID), req)
+	if err != nil {
+		global.APP_LOG.Error("获取地址列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取地址列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, addresses, total, req.Page, req.PageSize)
+}
+
+// UpdateIPPoolAddress 保留或取消保留地址
+// @Summary 更新地址状态
+// @Description 管理员将空闲地址设为保留（不参与分配）或取消保留，已分配的地址不能修改
+// @Tags 地址池管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "地址池ID"
+// @Param addressId path int true "地址ID"
+// @Param request body admin.UpdateIPAddressRequest true "地址状态"
+// @Success 200 {object} common.Response "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/ip-pools/{id}/addresses/{addressId} [put]
+func UpdateIPPoolAddress(c *gin.Context) {
+	poolID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址池ID"))
+		return
+	}
+	addressID, err := strconv.ParseUint(c.Param("addressId"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的地址ID"))
+		return
+	}
+
+	var req admin.UpdateIPAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	if err := ipam.NewService().UpdateAddressStatus(uint(poolID), uint(addressID), req.Status); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "地址状态更新成功")
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/notify"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetNotificationTemplates 获取通知模板列表
+// @Summary 获取通知模板列表
+// @Description 获取所有通知事件的模板，未自定义的事件返回内置模板，variables为模板可用变量
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]notify.TemplateInfo} "获取成功"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/notification-templates [get]
+func GetNotificationTemplates(c *gin.Context) {
+	templates, err := notify.NewService().ListTemplates()
+	if err != nil {
+		global.APP_LOG.Error("获取通知模板失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取通知模板失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, templates)
+}
+
+// UpdateNotificationTemplate 更新通知模板
+// @Summary 更新通知模板
+// @Description 自定义通知事件的标题和正文，使用Go text/template语法，如 {{.InstanceName}}
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "通知事件"
+// @Param request body admin.NotificationTemplateRequest true "模板内容"
+// @Success 200 {object} common.Response{data=system.NotificationTemplate} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/notification-templates/{event} [put]
+func UpdateNotificationTemplate(c *gin.Context) {
+	var req admin.NotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	tpl, err := notify.NewService().UpdateTemplate(c.Param("event"), req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, tpl, "通知模板更新成功")
+}
+
+// ResetNotificationTemplate 恢复默认通知模板
+// @Summary 恢复默认通知模板
+// @Description 删除通知事件的自定义模板，恢复为内置模板
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "通知事件"
+// @Success 200 {object} common.Response "恢复成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/notification-templates/{event} [delete]
+func ResetNotificationTemplate(c *gin.Context) {
+	if err := notify.NewService().ResetTemplate(c.Param("event")); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "已恢复默认模板")
+}
+
+// GetNotificationLogs 获取通知发送记录
+// @Summary 获取通知发送记录
+// @Description 管理员分页获取所有用户的通知发送记录
+// @Tags 通知管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param userId query int false "用户ID"
+// @Param event query string false "通知事件"
+// @Param channel query string false "发送渠道：email, telegram"
+// @Param status query string false "发送结果：sent, failed"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/notification-logs [get]
+func GetNotificationLogs(c *gin.Context) {
+	var req admin.NotificationLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	logs, total, err := notify.NewService().ListLogs(req.UserID, req.Event, req.Channel, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		global.APP_LOG.Error("获取通知记录失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取通知记录失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, logs, total, req.Page, req.PageSize)
+}
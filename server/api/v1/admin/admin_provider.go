@@ -399,7 +399,9 @@ func CheckProviderHealth(c *gin.Context) {
 	if err != nil {
 		// 错误消息
 		errorMsg := "健康检查失败"
-		if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "i/o timeout") {
+		if utils.IsHostKeyMismatch(err) {
+			errorMsg = "SSH主机密钥与记录不一致，请核实节点后确认新的主机密钥"
+		} else if strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "i/o timeout") {
 			errorMsg = "健康检查超时，请检查网络连接或服务器状态"
 		} else if strings.Contains(err.Error(), "connection refused") {
 			errorMsg = "无法连接到服务器，请检查服务器状态和网络配置"
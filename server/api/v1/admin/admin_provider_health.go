@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/healthhistory"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// loadProviderFromParam 解析路径中的Provider ID并确认Provider存在
+func loadProviderFromParam(c *gin.Context) (*providerModel.Provider, bool) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Provider ID"))
+		return nil, false
+	}
+	var p providerModel.Provider
+	if err := global.APP_DB.Select("id, name, status").First(&p, providerID).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "Provider不存在"))
+		return nil, false
+	}
+	return &p, true
+}
+
+// GetProvidersUptime 获取所有Provider的可用率
+// @Summary 获取所有Provider的可用率
+// @Description 根据健康检查历史计算所有Provider最近24小时、7天、30天的可用率，部分可用（partial）计为可用，未监控时段不计入
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]healthhistory.ProviderUptime} "获取成功"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/uptime [get]
+func GetProvidersUptime(c *gin.Context) {
+	result, err := healthhistory.NewService().GetAllUptime()
+	if err != nil {
+		global.APP_LOG.Error("获取Provider可用率失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取Provider可用率失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, result)
+}
+
+// GetProviderUptime 获取Provider可用率
+// @Summary 获取Provider可用率
+// @Description 根据健康检查历史计算Provider最近24小时、7天、30天的可用率
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=healthhistory.ProviderUptime} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/uptime [get]
+func GetProviderUptime(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := healthhistory.NewService().GetUptime(p)
+	if err != nil {
+		global.APP_LOG.Error("获取Provider可用率失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取Provider可用率失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, result)
+}
+
+// GetProviderOutages 获取Provider离线记录
+// @Summary 获取Provider离线记录
+// @Description 获取Provider最近若干天内的离线时段及持续时长，按开始时间倒序，end为空表示仍处于离线状态
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param days query int false "统计天数，最大为历史保留天数" default(30)
+// @Success 200 {object} common.Response{data=[]healthhistory.Outage} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/outages [get]
+func GetProviderOutages(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的统计天数"))
+		return
+	}
+	if retention := global.APP_CONFIG.Health.HistoryRetentionDays; retention > 0 && days > retention {
+		days = retention
+	}
+
+	outages, err := healthhistory.NewService().GetOutages(p.ID, days)
+	if err != nil {
+		global.APP_LOG.Error("获取Provider离线记录失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取Provider离线记录失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, outages)
+}
+
+// GetProviderHealthHistory 获取Provider健康检查历史
+// @Summary 获取Provider健康检查历史
+// @Description 分页获取Provider的原始健康检查记录，按检查时间倒序
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/health-history [get]
+func GetProviderHealthHistory(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req common.PageInfo
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	records, total, err := healthhistory.NewService().ListRecords(p.ID, req.Page, req.PageSize)
+	if err != nil {
+		global.APP_LOG.Error("获取健康检查历史失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取健康检查历史失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, records, total, req.Page, req.PageSize)
+}
@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	adminProvider "oneclickvirt/service/admin/provider"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetProviderHostKey 获取Provider的SSH主机密钥
+// @Summary 获取Provider的SSH主机密钥
+// @Description 获取首次连接时记录的SSH主机密钥指纹，以及检测到不一致时待确认的新密钥指纹；尚未连接过时返回空
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response{data=provider.ProviderHostKey} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/providers/{id}/host-key [get]
+func GetProviderHostKey(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	record, err := adminProvider.NewService().GetHostKey(p.ID)
+	if err != nil {
+		global.APP_LOG.Error("获取Provider SSH主机密钥失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取SSH主机密钥失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, record)
+}
+
+// AcceptProviderHostKey 确认Provider新的SSH主机密钥
+// @Summary 确认Provider新的SSH主机密钥
+// @Description 节点主机密钥变化后连接会被拒绝，管理员核实后提交待确认密钥的指纹以替换已记录的密钥，随后重新连接并检查健康状态
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Param request body admin.AcceptProviderHostKeyRequest true "待确认密钥的SHA256指纹"
+// @Success 200 {object} common.Response{data=provider.ProviderHostKey} "确认成功"
+// @Failure 400 {object} common.Response "参数错误或指纹不一致"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Router /admin/providers/{id}/host-key/accept [post]
+func AcceptProviderHostKey(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	var req admin.AcceptProviderHostKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	record, err := adminProvider.NewService().AcceptHostKey(p.ID, req.Fingerprint)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, record, "已确认新的SSH主机密钥")
+}
+
+// ResetProviderHostKey 清除Provider的SSH主机密钥记录
+// @Summary 清除Provider的SSH主机密钥记录
+// @Description 清除已记录的SSH主机密钥，下次连接时重新记录，用于节点重装系统等已知的密钥变更
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Provider ID"
+// @Success 200 {object} common.Response "清除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "Provider不存在"
+// @Failure 500 {object} common.Response "清除失败"
+// @Router /admin/providers/{id}/host-key [delete]
+func ResetProviderHostKey(c *gin.Context) {
+	p, ok := loadProviderFromParam(c)
+	if !ok {
+		return
+	}
+
+	if err := adminProvider.NewService().ResetHostKey(p.ID); err != nil {
+		global.APP_LOG.Error("清除Provider SSH主机密钥失败", zap.Uint("providerID", p.ID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "清除SSH主机密钥失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "已清除SSH主机密钥记录，下次连接时将重新记录")
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/securitygroup"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSecurityGroupList 获取安全组列表
+// @Summary 获取安全组列表
+// @Description 管理员分页获取所有用户的安全组及其规则
+// @Tags 安全组管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "安全组名称"
+// @Param userId query int false "用户ID"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/security-groups [get]
+func GetSecurityGroupList(c *gin.Context) {
+	var req admin.SecurityGroupListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	groups, total, err := securitygroup.NewService().ListAllGroups(req)
+	if err != nil {
+		global.APP_LOG.Error("获取安全组列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取安全组列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, groups, total, req.Page, req.PageSize)
+}
+
+// ReapplyInstanceSecurityGroup 重新下发实例的安全组规则
+// @Summary 重新下发实例的安全组规则
+// @Description 宿主机重启、容器IP变化或规则被手动修改后，按实例绑定的安全组重新下发防火墙规则
+// @Tags 安全组管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.Task} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或实例未绑定安全组"
+// @Router /admin/instances/{id}/security-group/reapply [post]
+func ReapplyInstanceSecurityGroup(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	taskModel, err := securitygroup.NewService().Reapply(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, taskModel, "安全组规则正在重新下发")
+}
@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/snapshot"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSnapshotList 获取快照列表
+// @Summary 获取快照列表
+// @Description 管理员分页获取所有实例快照
+// @Tags 快照管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "快照名称或实例名称"
+// @Param instanceId query int false "实例ID"
+// @Param providerId query int false "Provider ID"
+// @Param userId query int false "用户ID"
+// @Param status query string false "状态"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/snapshots [get]
+func GetSnapshotList(c *gin.Context) {
+	var req admin.SnapshotListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	snapshots, total, err := snapshot.NewService().ListSnapshots(req)
+	if err != nil {
+		global.APP_LOG.Error("获取快照列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取快照列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, snapshots, total, req.Page, req.PageSize)
+}
+
+// GetInstanceSnapshotsAdmin 获取指定实例的快照
+// @Summary 获取实例快照列表
+// @Description 管理员获取指定实例的所有快照
+// @Tags 快照管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]provider.InstanceSnapshot} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/instances/{id}/snapshots [get]
+func GetInstanceSnapshotsAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	snapshots, err := snapshot.NewService().ListInstanceSnapshots(uint(instanceID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, snapshots)
+}
+
+// CreateInstanceSnapshotAdmin 为实例创建快照
+// @Summary 创建实例快照
+// @Description 管理员为指定实例创建快照，不受用户等级快照数量限制
+// @Tags 快照管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body admin.CreateSnapshotRequest true "创建快照请求参数（名称为空时自动生成）"
+// @Success 200 {object} common.Response{data=object} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/instances/{id}/snapshots [post]
+func CreateInstanceSnapshotAdmin(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req admin.CreateSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, "实例不存在"))
+		return
+	}
+
+	snap, taskModel, err := snapshot.NewService().CreateSnapshot(&instance, req.Name, req.Description, false)
+	if err != nil {
+		global.APP_LOG.Warn("管理员创建实例快照失败",
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, gin.H{
+		"taskId":     taskModel.ID,
+		"snapshotId": snap.ID,
+	}, "快照任务创建成功")
+}
+
+// RestoreSnapshotAdmin 恢复快照
+// @Summary 恢复快照
+// @Description 管理员将实例恢复到指定快照
+// @Tags 快照管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "快照ID"
+// @Success 200 {object} common.Response{data=object} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "快照不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/snapshots/{id}/restore [post]
+func RestoreSnapshotAdmin(c *gin.Context) {
+	snapshotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的快照ID"))
+		return
+	}
+
+	snapshotService := snapshot.NewService()
+	snap, instance, err := snapshotService.GetSnapshot(uint(snapshotID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	taskModel, err := snapshotService.RestoreSnapshot(instance, snap)
+	if err != nil {
+		global.APP_LOG.Warn("管理员恢复快照失败",
+			zap.Uint64("snapshotID", snapshotID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, gin.H{
+		"taskId":     taskModel.ID,
+		"snapshotId": snap.ID,
+	}, "快照恢复任务创建成功")
+}
+
+// DeleteSnapshotAdmin 删除快照
+// @Summary 删除快照
+// @Description 管理员删除指定快照
+// @Tags 快照管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "快照ID"
+// @Success 200 {object} common.Response{data=object} "删除成功或任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 404 {object} common.Response "快照不存在"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /admin/snapshots/{id} [delete]
+func DeleteSnapshotAdmin(c *gin.Context) {
+	snapshotID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的快照ID"))
+		return
+	}
+
+	snapshotService := snapshot.NewService()
+	snap, instance, err := snapshotService.GetSnapshot(uint(snapshotID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+		return
+	}
+
+	taskModel, err := snapshotService.DeleteSnapshot(instance, snap)
+	if err != nil {
+		global.APP_LOG.Warn("管理员删除快照失败",
+			zap.Uint64("snapshotID", snapshotID),
+			zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	var taskID uint
+	if taskModel != nil {
+		taskID = taskModel.ID
+	}
+	common.ResponseSuccess(c, gin.H{
+		"taskId":     taskID,
+		"snapshotId": snap.ID,
+	}, "快照删除任务创建成功")
+}
@@ -10,6 +10,7 @@ import (
 	"oneclickvirt/model/admin"
 	"oneclickvirt/model/common"
 	"oneclickvirt/service/admin/user"
+	authService "oneclickvirt/service/auth"
 
 	"github.com/gin-gonic/gin"
 )
@@ -447,3 +448,35 @@ func ResetUserPasswordAndNotify(c *gin.Context) {
 
 	common.ResponseSuccess(c, nil, "重置用户密码成功，新密码已发送到用户绑定的通信渠道")
 }
+
+// ResetUserTwoFactor 管理员重置用户两步验证
+// @Summary 管理员重置用户两步验证
+// @Description 用户丢失验证器和恢复码时由管理员清除其两步验证绑定；若系统强制管理员启用两步验证，该管理员下次登录时需重新绑定
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} common.Response "重置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "重置失败"
+// @Router /admin/users/{id}/2fa [delete]
+func ResetUserTwoFactor(c *gin.Context) {
+	if !requireAdminOnly(c) {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInvalidParam, "无效的用户ID"))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	if err := twoFactorService.AdminReset(uint(userID)); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "重置用户两步验证成功")
+}
@@ -0,0 +1,248 @@
+package admin
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/webhook"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetWebhookList 获取Webhook列表
+// @Summary 获取Webhook列表
+// @Description 管理员分页获取所有Webhook配置
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param keyword query string false "名称或地址"
+// @Param enabled query bool false "是否启用"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/webhooks [get]
+func GetWebhookList(c *gin.Context) {
+	var req admin.WebhookListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	webhooks, total, err := webhook.NewService().ListWebhooks(req)
+	if err != nil {
+		global.APP_LOG.Error("获取Webhook列表失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取Webhook列表失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, webhooks, total, req.Page, req.PageSize)
+}
+
+// GetWebhookEvents 获取可订阅的事件列表
+// @Summary 获取Webhook事件列表
+// @Description 获取Webhook可订阅的事件类型，* 表示订阅全部事件
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]string} "获取成功"
+// @Router /admin/webhooks/events [get]
+func GetWebhookEvents(c *gin.Context) {
+	common.ResponseSuccess(c, webhook.NewService().GetEvents())
+}
+
+// CreateWebhook 创建Webhook
+// @Summary 创建Webhook
+// @Description 管理员创建Webhook，未填写签名密钥时自动生成
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body admin.CreateWebhookRequest true "Webhook参数"
+// @Success 200 {object} common.Response{data=system.Webhook} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req admin.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	hook, err := webhook.NewService().CreateWebhook(req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, hook, "Webhook创建成功")
+}
+
+// UpdateWebhook 更新Webhook
+// @Summary 更新Webhook
+// @Description 管理员更新Webhook配置，签名密钥留空时保持不变
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body admin.UpdateWebhookRequest true "Webhook参数"
+// @Success 200 {object} common.Response{data=system.Webhook} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Router /admin/webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Webhook ID"))
+		return
+	}
+
+	var req admin.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	hook, err := webhook.NewService().UpdateWebhook(uint(webhookID), req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, hook, "Webhook更新成功")
+}
+
+// DeleteWebhook 删除Webhook
+// @Summary 删除Webhook
+// @Description 管理员删除Webhook，未完成的投递将不再重试
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /admin/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Webhook ID"))
+		return
+	}
+
+	if err := webhook.NewService().DeleteWebhook(uint(webhookID)); err != nil {
+		global.APP_LOG.Warn("删除Webhook失败", zap.Uint64("webhookID", webhookID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "Webhook删除成功")
+}
+
+// TestWebhook 发送测试事件
+// @Summary 发送Webhook测试事件
+// @Description 向指定Webhook同步发送一条 webhook.test 事件并返回投递结果，不重试
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} common.Response{data=system.WebhookDelivery} "发送完成"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "发送失败"
+// @Router /admin/webhooks/{id}/test [post]
+func TestWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的Webhook ID"))
+		return
+	}
+
+	delivery, err := webhook.NewService().SendTest(uint(webhookID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, delivery, "测试事件已发送")
+}
+
+// GetWebhookDeliveries 获取Webhook投递记录
+// @Summary 获取Webhook投递记录
+// @Description 管理员分页获取Webhook投递记录
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param webhookId query int false "Webhook ID"
+// @Param event query string false "事件类型"
+// @Param status query string false "投递状态"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /admin/webhook-deliveries [get]
+func GetWebhookDeliveries(c *gin.Context) {
+	var req admin.WebhookDeliveryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	deliveries, total, err := webhook.NewService().ListDeliveries(req)
+	if err != nil {
+		global.APP_LOG.Error("获取Webhook投递记录失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取投递记录失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, deliveries, total, req.Page, req.PageSize)
+}
+
+// RedeliverWebhook 重新投递
+// @Summary 重新投递Webhook
+// @Description 重置指定投递记录的重试次数并立即重新发送
+// @Tags Webhook管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "投递记录ID"
+// @Success 200 {object} common.Response{data=system.WebhookDelivery} "发送完成"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 500 {object} common.Response "发送失败"
+// @Router /admin/webhook-deliveries/{id}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	deliveryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的投递记录ID"))
+		return
+	}
+
+	delivery, err := webhook.NewService().Redeliver(uint(deliveryID))
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, delivery, "已重新投递")
+}
@@ -1,11 +1,13 @@
 package auth
 
 import (
+	"oneclickvirt/service/audit"
 	auth2 "oneclickvirt/service/auth"
 	"strings"
 
 	"oneclickvirt/global"
 	"oneclickvirt/middleware"
+	adminModel "oneclickvirt/model/admin"
 	"oneclickvirt/model/auth"
 	"oneclickvirt/model/common"
 
@@ -15,7 +17,7 @@ import (
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录接口，验证用户名密码并返回JWT token
+// @Description 用户登录接口，验证用户名密码并返回JWT token；启用两步验证时返回 twoFactorToken
 // @Tags 认证管理
 // @Accept json
 // @Produce json
@@ -33,7 +35,7 @@ func Login(c *gin.Context) {
 	}
 
 	authService := auth2.AuthService{}
-	user, token, err := authService.Login(req)
+	user, token, challenge, err := authService.Login(req)
 	if err != nil {
 		global.APP_LOG.Warn("用户登录失败",
 			zap.String("username", req.Username),
@@ -43,14 +45,22 @@ func Login(c *gin.Context) {
 		// 使用认证验证服务分类错误
 		authValidationService := auth2.AuthValidationService{}
 		appErr := authValidationService.ClassifyLoginError(err)
+		recordLoginAudit(c, nil, req.Username, "", "login", false, appErr.Error())
 		common.ResponseWithError(c, appErr)
 		return
 	}
 
+	// 需要两步验证时仅返回临时令牌，前端需调用 /auth/login/2fa 完成登录
+	if challenge != nil {
+		common.ResponseSuccess(c, challenge, "请完成两步验证")
+		return
+	}
+
 	global.APP_LOG.Info("用户登录成功",
 		zap.String("username", req.Username),
 		zap.Uint("user_id", user.ID),
 		zap.String("ip", c.ClientIP()))
+	recordLoginAudit(c, &user.ID, user.Username, user.UserType, "login", true, "登录成功")
 
 	common.ResponseSuccess(c, gin.H{
 		"user":  user,
@@ -267,3 +277,20 @@ func SendVerifyCode(c *gin.Context) {
 
 	common.ResponseSuccess(c, nil, "验证码已发送，请查收")
 }
+
+// recordLoginAudit 记录登录审计日志，登录接口不经过认证中间件，因此单独记录
+func recordLoginAudit(c *gin.Context, userID *uint, username, userType, action string, success bool, message string) {
+	audit.Record(&adminModel.AuditLog{
+		UserID:    userID,
+		Username:  audit.Truncate(username, 64),
+		UserType:  userType,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Action:    action,
+		Resource:  "auth",
+		Success:   success,
+		ClientIP:  c.ClientIP(),
+		UserAgent: audit.Truncate(c.Request.UserAgent(), 255),
+		Response:  audit.Truncate(message, 500),
+	})
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	auth2 "oneclickvirt/service/auth"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/auth"
+	"oneclickvirt/model/common"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VerifyTwoFactorLogin 两步验证登录
+// @Summary 两步验证登录
+// @Description 提交第一步登录返回的临时令牌和验证码（或恢复码）完成登录；若为登录时强制绑定，返回首次生成的恢复码
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param request body auth.TwoFactorLoginRequest true "两步验证参数"
+// @Success 200 {object} common.Response{data=object} "登录成功"
+// @Failure 400 {object} common.Response "请求参数错误"
+// @Failure 401 {object} common.Response "验证码错误或令牌无效"
+// @Failure 429 {object} common.Response "失败次数过多"
+// @Router /auth/login/2fa [post]
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var req auth.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	twoFactorService := auth2.TwoFactorService{}
+	user, token, recoveryCodes, err := twoFactorService.VerifyLogin(req)
+	if err != nil {
+		global.APP_LOG.Warn("两步验证登录失败",
+			zap.String("error", err.Error()),
+			zap.String("ip", c.ClientIP()))
+		recordLoginAudit(c, nil, "", "", "login/2fa", false, err.Error())
+		if appErr, ok := err.(*common.AppError); ok {
+			common.ResponseWithError(c, appErr)
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	global.APP_LOG.Info("用户登录成功",
+		zap.String("username", user.Username),
+		zap.Uint("user_id", user.ID),
+		zap.String("ip", c.ClientIP()))
+	recordLoginAudit(c, &user.ID, user.Username, user.UserType, "login/2fa", true, "登录成功")
+
+	data := gin.H{
+		"user":  user,
+		"token": token,
+	}
+	if len(recoveryCodes) > 0 {
+		data["recoveryCodes"] = recoveryCodes
+	}
+	common.ResponseSuccess(c, data)
+}
+
+// BeginTwoFactorLoginSetup 登录时绑定两步验证
+// @Summary 登录时绑定两步验证
+// @Description 系统强制管理员启用两步验证而账号尚未绑定时，使用临时令牌获取验证器绑定信息
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param request body auth.TwoFactorLoginSetupRequest true "临时令牌"
+// @Success 200 {object} common.Response{data=user.TwoFactorSetupResponse} "获取成功"
+// @Failure 400 {object} common.Response "请求参数错误"
+// @Failure 401 {object} common.Response "令牌无效"
+// @Router /auth/login/2fa/setup [post]
+func BeginTwoFactorLoginSetup(c *gin.Context) {
+	var req auth.TwoFactorLoginSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	twoFactorService := auth2.TwoFactorService{}
+	setup, err := twoFactorService.BeginLoginSetup(req)
+	if err != nil {
+		if appErr, ok := err.(*common.AppError); ok {
+			common.ResponseWithError(c, appErr)
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, setup)
+}
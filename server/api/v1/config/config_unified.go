@@ -225,6 +225,9 @@ func getUserConfig(cm *config.ConfigManager, authCtx *authModel.AuthContext) map
 			"max-instances": limitInfo.MaxInstances,
 			"max-resources": limitInfo.MaxResources,
 			"max-traffic":   limitInfo.MaxTraffic,
+			"max-snapshots": limitInfo.MaxSnapshots,
+			"max-backups":   limitInfo.MaxBackups,
+			"max-domains":   limitInfo.MaxDomains,
 		}
 	}
 
@@ -265,6 +268,7 @@ func getAdminConfig(cm *config.ConfigManager) map[string]interface{} {
 		"telegramBotToken":         global.APP_CONFIG.Auth.TelegramBotToken,
 		"qqAppID":                  global.APP_CONFIG.Auth.QQAppID,
 		"qqAppKey":                 global.APP_CONFIG.Auth.QQAppKey,
+		"forceAdminTwoFactor":      global.APP_CONFIG.Auth.ForceAdminTwoFactor,
 	}
 
 	// 邀请码配置
@@ -281,6 +285,9 @@ func getAdminConfig(cm *config.ConfigManager) map[string]interface{} {
 			"max-instances": limitInfo.MaxInstances,
 			"max-resources": limitInfo.MaxResources,
 			"max-traffic":   limitInfo.MaxTraffic,
+			"max-snapshots": limitInfo.MaxSnapshots,
+			"max-backups":   limitInfo.MaxBackups,
+			"max-domains":   limitInfo.MaxDomains,
 		}
 	}
 
@@ -303,6 +310,50 @@ func getAdminConfig(cm *config.ConfigManager) map[string]interface{} {
 		"defaultLanguage": global.APP_CONFIG.Other.DefaultLanguage,
 	}
 
+	// 自动调度配置
+	result["placement"] = map[string]interface{}{
+		"strategy": global.APP_CONFIG.Placement.Strategy,
+		"weights": map[string]interface{}{
+			"cpu":       global.APP_CONFIG.Placement.Weights.CPU,
+			"memory":    global.APP_CONFIG.Placement.Weights.Memory,
+			"disk":      global.APP_CONFIG.Placement.Weights.Disk,
+			"instances": global.APP_CONFIG.Placement.Weights.Instances,
+		},
+	}
+
+	// 计费配置
+	result["billing"] = map[string]interface{}{
+		"enabled":             global.APP_CONFIG.Billing.Enabled,
+		"currency":            global.APP_CONFIG.Billing.Currency,
+		"chargeInterval":      global.APP_CONFIG.Billing.ChargeInterval,
+		"lowBalanceThreshold": global.APP_CONFIG.Billing.LowBalanceThreshold,
+	}
+
+	// 通知配置
+	result["notify"] = map[string]interface{}{
+		"enabled":            global.APP_CONFIG.Notify.Enabled,
+		"expiryReminderDays": global.APP_CONFIG.Notify.ExpiryReminderDays,
+	}
+
+	// 健康检查历史配置
+	result["health"] = map[string]interface{}{
+		"historyRetentionDays": global.APP_CONFIG.Health.HistoryRetentionDays,
+	}
+
+	// 实例资源采集配置
+	result["metrics"] = map[string]interface{}{
+		"enabled":             global.APP_CONFIG.Metrics.Enabled,
+		"collectInterval":     global.APP_CONFIG.Metrics.CollectInterval,
+		"rawRetentionDays":    global.APP_CONFIG.Metrics.RawRetentionDays,
+		"hourlyRetentionDays": global.APP_CONFIG.Metrics.HourlyRetentionDays,
+	}
+
+	// 审计日志配置
+	result["audit"] = map[string]interface{}{
+		"enabled":       global.APP_CONFIG.Audit.Enabled,
+		"retentionDays": global.APP_CONFIG.Audit.RetentionDays,
+	}
+
 	return result
 } // unflattenConfig 将扁平化的配置（如 quota.defaultLevel）转换为嵌套结构（如 quota: { defaultLevel: 1 }）
 func unflattenConfig(flatConfig map[string]interface{}) map[string]interface{} {
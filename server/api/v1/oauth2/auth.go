@@ -113,7 +113,7 @@ func OAuth2Callback(c *gin.Context) {
 	}
 
 	// 处理回调
-	usr, token, err := oauthService.HandleCallback(providerID, code)
+	usr, token, challenge, err := oauthService.HandleCallback(providerID, code)
 	if err != nil {
 		global.APP_LOG.Error("OAuth2回调处理失败",
 			zap.Uint("provider_id", providerID),
@@ -136,6 +136,18 @@ func OAuth2Callback(c *gin.Context) {
 	// 获取前端URL配置，如果没有配置，尝试智能检测
 	frontendURL := global.APP_CONFIG.System.FrontendURL
 
+	// 需要两步验证时传递临时令牌，由前端调用 /auth/login/2fa 完成登录
+	params := url.Values{}
+	if challenge != nil {
+		params.Set("oauth2_2fa_token", challenge.TwoFactorToken)
+		if challenge.TwoFactorSetupRequired {
+			params.Set("two_factor_setup", "1")
+		}
+	} else {
+		params.Set("oauth2_token", token)
+	}
+	params.Set("username", usr.Username)
+
 	// 返回HTML页面，通过JavaScript跳转并携带token参数
 	// localStorage在不同端口/域名下是隔离的，所以必须通过URL参数传递
 	html := fmt.Sprintf(`<!DOCTYPE html>
@@ -198,8 +210,7 @@ func OAuth2Callback(c *gin.Context) {
     <script>
         (function() {
             try {
-                var token = '%s';
-                var username = '%s';
+                var query = '%s';
                 var configuredFrontendURL = '%s';
                 
                 // 使用配置的前端URL
@@ -216,8 +227,7 @@ func OAuth2Callback(c *gin.Context) {
                 }
                 
                 // 将token作为URL参数传递（解决跨域localStorage隔离问题）
-                var redirectURL = frontendURL + '?oauth2_token=' + encodeURIComponent(token) + 
-                                  '&username=' + encodeURIComponent(username);
+                var redirectURL = frontendURL + '?' + query;
                 
                 console.log('OAuth2跳转到:', redirectURL);
                 
@@ -232,7 +242,7 @@ func OAuth2Callback(c *gin.Context) {
         })();
     </script>
 </body>
-</html>`, token, url.QueryEscape(usr.Username), frontendURL)
+</html>`, params.Encode(), frontendURL)
 
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	c.String(http.StatusOK, html)
@@ -3,6 +3,7 @@ package public
 import (
 	"net/http"
 	"oneclickvirt/service/auth"
+	"oneclickvirt/service/database"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/service/system"
 	"os"
@@ -137,11 +138,12 @@ func CheckInit(c *gin.Context) {
 func TestDatabaseConnection(c *gin.Context) {
 	var req struct {
 		Type     string `json:"type" binding:"required"`
-		Host     string `json:"host" binding:"required"`
-		Port     string `json:"port" binding:"required"`
-		Database string `json:"database" binding:"required"`
-		Username string `json:"username" binding:"required"`
+		Host     string `json:"host"`
+		Port     string `json:"port"`
+		Database string `json:"database"`
+		Username string `json:"username"`
 		Password string `json:"password"`
+		SSLMode  string `json:"sslMode"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -150,20 +152,24 @@ func TestDatabaseConnection(c *gin.Context) {
 		return
 	}
 
-	// 支持MySQL和MariaDB
-	if req.Type != "mysql" && req.Type != "mariadb" {
-		c.JSON(http.StatusBadRequest, common.Error("仅支持MySQL和MariaDB数据库"))
+	// 支持MySQL、MariaDB、PostgreSQL和SQLite
+	if !database.IsSupportedType(req.Type) {
+		c.JSON(http.StatusBadRequest, common.Error("仅支持MySQL、MariaDB、PostgreSQL和SQLite数据库"))
 		return
 	}
 
 	// 使用InitService测试连接
 	initService := &system.InitService{}
 
-	// 转换端口字符串为整数
-	port, err := strconv.Atoi(req.Port)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, common.Error("端口格式错误"))
-		return
+	// 转换端口字符串为整数，为空时使用默认端口
+	var port int
+	if req.Port != "" {
+		p, err := strconv.Atoi(req.Port)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, common.Error("端口格式错误"))
+			return
+		}
+		port = p
 	}
 
 	dbConfig := configModel.DatabaseConfig{
@@ -173,6 +179,7 @@ func TestDatabaseConnection(c *gin.Context) {
 		Database: req.Database,
 		Username: req.Username,
 		Password: req.Password,
+		SSLMode:  req.SSLMode,
 	}
 
 	if err := initService.TestDatabaseConnection(dbConfig); err != nil {
@@ -225,6 +232,7 @@ func InitSystem(c *gin.Context) {
 			Database string `json:"database"`
 			Username string `json:"username"`
 			Password string `json:"password"`
+			SSLMode  string `json:"sslMode"`
 		} `json:"database" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -233,11 +241,15 @@ func InitSystem(c *gin.Context) {
 	}
 
 	// 先检查数据库配置并确保数据库连接
-	// 转换端口字符串为整数
-	port, err := strconv.Atoi(req.Database.Port)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, common.Error("数据库端口格式错误"))
-		return
+	// 转换端口字符串为整数，为空时使用默认端口（SQLite不需要端口）
+	var port int
+	if req.Database.Port != "" {
+		p, err := strconv.Atoi(req.Database.Port)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, common.Error("数据库端口格式错误"))
+			return
+		}
+		port = p
 	}
 
 	// 创建数据库配置
@@ -248,6 +260,7 @@ func InitSystem(c *gin.Context) {
 		Database: req.Database.Database,
 		Username: req.Database.Username,
 		Password: req.Database.Password,
+		SSLMode:  req.Database.SSLMode,
 	}
 
 	// 初始化服务
@@ -391,7 +404,7 @@ func GetPublicSystemConfig(c *gin.Context) {
 		}
 
 		if err := global.APP_DB.Table("system_configs").
-			Select("`key`, `value`").
+			Select([]string{"key", "value"}).
 			Where("is_public = ? AND deleted_at IS NULL", true).
 			Find(&configs).Error; err == nil {
 			global.APP_LOG.Info("从数据库查询到的公开配置数量", zap.Int("count", len(configs)))
@@ -462,7 +475,7 @@ func GetRecommendedDatabaseType(c *gin.Context) {
 		"recommendedType": recommendedType,
 		"reason":          reason,
 		"architecture":    arch,
-		"supportedTypes":  []string{"mysql", "mariadb"},
+		"supportedTypes":  database.SupportedTypes,
 	}
 
 	c.JSON(http.StatusOK, common.Success(response))
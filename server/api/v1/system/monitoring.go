@@ -2,11 +2,15 @@ package system
 
 import (
 	"net/http"
+	"oneclickvirt/service/audit"
 	"oneclickvirt/service/resources"
 
+	"oneclickvirt/global"
+	"oneclickvirt/model/admin"
 	"oneclickvirt/model/common"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type MonitoringApi struct{}
@@ -104,35 +108,47 @@ func GetSystemLogs(c *gin.Context) {
 
 // GetOperationLogs 获取操作审计日志
 // @Summary 获取操作审计日志
-// @Description 获取用户操作审计日志
+// @Description 分页获取管理员和用户写操作及登录的审计日志，可按操作者、资源、操作、结果和时间范围过滤
 // @Tags 管理员管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param user_id query int false "用户ID"
-// @Param action query string false "操作类型"
-// @Param start_time query string false "开始时间"
-// @Param end_time query string false "结束时间"
-// @Param limit query int false "返回条数" default(100)
-// @Param offset query int false "偏移量" default(0)
-// @Success 200 {object} common.Response{data=[]object} "获取成功"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(20)
+// @Param keyword query string false "请求路径"
+// @Param userId query int false "操作者用户ID"
+// @Param username query string false "操作者用户名"
+// @Param userType query string false "操作者角色" Enums(admin, user)
+// @Param resource query string false "资源类型，如 users、instances、auth"
+// @Param resourceId query string false "资源ID"
+// @Param action query string false "操作，如 create、update、delete、login"
+// @Param method query string false "请求方法"
+// @Param success query bool false "操作是否成功"
+// @Param startTime query string false "开始时间，RFC3339或 2006-01-02 15:04:05"
+// @Param endTime query string false "结束时间，RFC3339或 2006-01-02 15:04:05"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
 // @Failure 500 {object} common.Response "获取失败"
-// @Router /api/v1/admin/monitoring/audit-logs [get]
+// @Router /admin/monitoring/audit-logs [get]
 func GetOperationLogs(c *gin.Context) {
-	userID := c.Query("user_id")
-	action := c.Query("action")
-	startTime := c.Query("start_time")
-	endTime := c.Query("end_time")
-	limit := c.DefaultQuery("limit", "100")
-	offset := c.DefaultQuery("offset", "0")
-
-	// 使用服务层获取审计日志
-	monitoringService := resources.MonitoringService{}
-	auditLogs := monitoringService.GetOperationLogs(userID, action, startTime, endTime, limit, offset)
-
-	c.JSON(http.StatusOK, common.Response{
-		Code: 200,
-		Msg:  "获取操作日志成功",
-		Data: auditLogs,
-	})
+	var req admin.AuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	logs, total, err := audit.NewService().List(req)
+	if err != nil {
+		global.APP_LOG.Warn("获取审计日志失败", zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, logs, total, req.Page, req.PageSize)
 }
@@ -12,6 +12,7 @@ import (
 	"oneclickvirt/model/common"
 	"oneclickvirt/model/resource"
 	"oneclickvirt/model/user"
+	"oneclickvirt/service/instancemetrics"
 	userService "oneclickvirt/service/user"
 	"oneclickvirt/utils"
 
@@ -181,7 +182,7 @@ func GetUserInstances(c *gin.Context) {
 
 // InstanceAction 实例操作
 // @Summary 实例操作
-// @Description 对用户实例执行操作（启动、停止、重启等）
+// @Description 对用户实例执行操作（启动、停止、重启、重置等），重置时可通过imageId指定重装的系统镜像
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -611,7 +612,7 @@ func CancelUserTask(c *gin.Context) {
 
 // CreateUserInstance 创建实例
 // @Summary 创建实例
-// @Description 用户创建新的虚拟机或容器实例（异步处理）
+// @Description 用户创建新的虚拟机或容器实例（异步处理）。providerId为空时按region/country、镜像和规格由调度器自动选择节点，调度策略由管理员在placement配置中设置
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -720,6 +721,11 @@ func GetUserInstanceDetail(c *gin.Context) {
 		return
 	}
 
+	// 只读API令牌不返回实例登录密码
+	if !middleware.CanAccessInstanceCredentials(c) {
+		detail.Password = ""
+	}
+
 	common.ResponseSuccess(c, detail)
 }
 
@@ -797,13 +803,15 @@ func GetActiveReservations(c *gin.Context) {
 
 // GetInstanceMonitoring 获取实例监控数据
 // @Summary 获取实例监控数据
-// @Description 获取用户实例的监控数据，包括流量统计信息
+// @Description 获取用户实例的监控数据，包括流量统计信息以及CPU、内存、磁盘使用曲线
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "实例ID"
+// @Param range query string false "资源使用曲线的时间范围：1h, 6h, 24h, 7d, 30d" default(24h)
 // @Success 200 {object} common.Response{data=user.InstanceMonitoringResponse} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
 // @Failure 401 {object} common.Response "用户未登录"
 // @Failure 403 {object} common.Response "实例不存在或无权限"
 // @Failure 500 {object} common.Response "服务器内部错误"
@@ -822,8 +830,14 @@ func GetInstanceMonitoring(c *gin.Context) {
 		return
 	}
 
+	metricsRange := c.DefaultQuery("range", instancemetrics.DefaultRange)
+	if !instancemetrics.IsValidRange(metricsRange) {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的时间范围"))
+		return
+	}
+
 	userServiceInstance := userService.NewService()
-	monitoring, err := userServiceInstance.GetInstanceMonitoring(userID, uint(instanceID))
+	monitoring, err := userServiceInstance.GetInstanceMonitoring(userID, uint(instanceID), metricsRange)
 	if err != nil {
 		if err.Error() == "实例不存在" {
 			common.ResponseWithError(c, common.NewError(common.CodeForbidden, "实例不存在或无权限"))
@@ -0,0 +1,110 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	authService "oneclickvirt/service/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAPITokens 获取API令牌列表
+// @Summary 获取API令牌列表
+// @Description 获取当前用户的所有个人API令牌（含已撤销和已过期的令牌），不返回令牌明文
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]user.APIToken} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/api-tokens [get]
+func GetAPITokens(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	apiTokenService := authService.APITokenService{}
+	tokens, err := apiTokenService.ListTokens(userID)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, tokens)
+}
+
+// CreateAPIToken 创建API令牌
+// @Summary 创建API令牌
+// @Description 创建用于脚本访问的个人API令牌，以 Authorization: Bearer ocv_xxx 方式使用；令牌明文仅在创建时返回一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.CreateAPITokenRequest true "令牌参数"
+// @Success 200 {object} common.Response{data=user.CreateAPITokenResponse} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 409 {object} common.Response "令牌数量已达上限"
+// @Router /user/api-tokens [post]
+func CreateAPIToken(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	apiTokenService := authService.APITokenService{}
+	token, err := apiTokenService.CreateToken(userID, req)
+	if err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, token, "API令牌创建成功，请妥善保存，令牌仅显示一次")
+}
+
+// RevokeAPIToken 撤销API令牌
+// @Summary 撤销API令牌
+// @Description 撤销指定的个人API令牌，撤销后立即失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "令牌ID"
+// @Success 200 {object} common.Response "撤销成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "令牌不存在"
+// @Router /user/api-tokens/{id} [delete]
+func RevokeAPIToken(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的令牌ID"))
+		return
+	}
+
+	apiTokenService := authService.APITokenService{}
+	if err := apiTokenService.RevokeToken(userID, uint(tokenID)); err != nil {
+		common.ResponseWithError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "API令牌已撤销")
+}
@@ -0,0 +1,307 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	userService "oneclickvirt/service/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// parseBackupPathID 解析路径中的ID参数
+func parseBackupPathID(c *gin.Context, param, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, message))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondBackupError 统一处理备份操作错误
+func respondBackupError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "实例不存在或无权限", "备份不存在或无权限":
+		common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+	default:
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+	}
+}
+
+// GetInstanceBackups 获取实例备份列表
+// @Summary 获取实例备份列表
+// @Description 获取用户实例的所有备份（包括已完成和失败的备份）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]provider.InstanceBackup} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/instances/{id}/backups [get]
+func GetInstanceBackups(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, ok := parseBackupPathID(c, "id", "无效的实例ID")
+	if !ok {
+		return
+	}
+
+	backups, err := userService.NewService().GetInstanceBackups(userID, instanceID)
+	if err != nil {
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, backups)
+}
+
+// CreateInstanceBackup 创建实例备份
+// @Summary 创建实例备份
+// @Description 导出用户实例并保存到备份存储，创建异步任务执行；超出用户等级保留数量时自动删除最旧的备份
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=user.BackupTaskResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /user/instances/{id}/backups [post]
+func CreateInstanceBackup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, ok := parseBackupPathID(c, "id", "无效的实例ID")
+	if !ok {
+		return
+	}
+
+	resp, err := userService.NewService().CreateInstanceBackup(userID, instanceID)
+	if err != nil {
+		global.APP_LOG.Warn("用户创建实例备份失败",
+			zap.Uint("userID", userID),
+			zap.Uint("instanceID", instanceID),
+			zap.Error(err))
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, resp, "备份任务创建成功")
+}
+
+// RestoreBackup 恢复备份
+// @Summary 恢复备份
+// @Description 将备份恢复到用户的实例（源实例或同类型的其他实例），目标实例的现有数据将被覆盖
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param backupId path int true "备份ID"
+// @Param request body user.RestoreBackupRequest true "恢复备份请求参数"
+// @Success 200 {object} common.Response{data=user.BackupTaskResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "备份或实例不存在或无权限"
+// @Failure 500 {object} common.Response "创建任务失败"
+// @Router /user/backups/{backupId}/restore [post]
+func RestoreBackup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	backupID, ok := parseBackupPathID(c, "backupId", "无效的备份ID")
+	if !ok {
+		return
+	}
+
+	var req user.RestoreBackupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	resp, err := userService.NewService().RestoreBackup(userID, backupID, req)
+	if err != nil {
+		global.APP_LOG.Warn("用户恢复备份失败",
+			zap.Uint("userID", userID),
+			zap.Uint("backupID", backupID),
+			zap.Uint("targetInstanceID", req.TargetInstanceID),
+			zap.Error(err))
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, resp, "备份恢复任务创建成功")
+}
+
+// DeleteBackup 删除备份
+// @Summary 删除备份
+// @Description 删除用户的备份及其归档文件
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param backupId path int true "备份ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "备份不存在或无权限"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /user/backups/{backupId} [delete]
+func DeleteBackup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	backupID, ok := parseBackupPathID(c, "backupId", "无效的备份ID")
+	if !ok {
+		return
+	}
+
+	if err := userService.NewService().DeleteBackup(userID, backupID); err != nil {
+		global.APP_LOG.Warn("用户删除备份失败",
+			zap.Uint("userID", userID),
+			zap.Uint("backupID", backupID),
+			zap.Error(err))
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "备份删除成功")
+}
+
+// GetBackupSchedule 获取实例定时备份计划
+// @Summary 获取实例定时备份计划
+// @Description 获取用户实例的定时备份计划，未设置时返回空
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=provider.BackupSchedule} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/instances/{id}/backup-schedule [get]
+func GetBackupSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, ok := parseBackupPathID(c, "id", "无效的实例ID")
+	if !ok {
+		return
+	}
+
+	schedule, err := userService.NewService().GetBackupSchedule(userID, instanceID)
+	if err != nil {
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, schedule)
+}
+
+// SetBackupSchedule 设置实例定时备份计划
+// @Summary 设置实例定时备份计划
+// @Description 创建或更新用户实例的定时备份计划
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.BackupScheduleRequest true "定时备份计划参数"
+// @Success 200 {object} common.Response{data=provider.BackupSchedule} "设置成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "设置失败"
+// @Router /user/instances/{id}/backup-schedule [put]
+func SetBackupSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, ok := parseBackupPathID(c, "id", "无效的实例ID")
+	if !ok {
+		return
+	}
+
+	var req user.BackupScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	schedule, err := userService.NewService().SetBackupSchedule(userID, instanceID, req)
+	if err != nil {
+		global.APP_LOG.Warn("用户设置定时备份失败",
+			zap.Uint("userID", userID),
+			zap.Uint("instanceID", instanceID),
+			zap.Error(err))
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, schedule, "定时备份设置成功")
+}
+
+// DeleteBackupSchedule 删除实例定时备份计划
+// @Summary 删除实例定时备份计划
+// @Description 删除用户实例的定时备份计划，已有备份不受影响
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 500 {object} common.Response "删除失败"
+// @Router /user/instances/{id}/backup-schedule [delete]
+func DeleteBackupSchedule(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, ok := parseBackupPathID(c, "id", "无效的实例ID")
+	if !ok {
+		return
+	}
+
+	if err := userService.NewService().DeleteBackupSchedule(userID, instanceID); err != nil {
+		respondBackupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "定时备份已删除")
+}
@@ -0,0 +1,84 @@
+package user
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/billing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetBalance 获取当前用户余额
+// @Summary 获取用户余额
+// @Description 获取当前用户的预付费余额、累计充值与消费以及运行中实例的小时费用，金额单位为分
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=user.BalanceResponse} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/billing/balance [get]
+func GetBalance(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	balance, err := billing.NewService().GetBalanceInfo(userID)
+	if err != nil {
+		global.APP_LOG.Error("获取用户余额失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取余额失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, balance)
+}
+
+// GetBalanceTransactions 获取当前用户余额流水
+// @Summary 获取余额流水
+// @Description 分页获取当前用户的充值、扣款、调整和退款流水
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param instanceId query int false "实例ID"
+// @Param type query string false "流水类型：recharge, charge, adjust, refund"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/billing/transactions [get]
+func GetBalanceTransactions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.BalanceTransactionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	records, total, err := billing.NewService().ListTransactions(userID, req.InstanceID, req.Type, req.Page, req.PageSize)
+	if err != nil {
+		global.APP_LOG.Error("获取余额流水失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取余额流水失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, records, total, req.Page, req.PageSize)
+}
@@ -0,0 +1,223 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ConsoleWebSocket 处理WebSocket控制台连接
+// @Summary WebSocket控制台连接
+// @Description 通过虚拟化平台API连接实例的串口或VNC控制台，实例网络或sshd异常时也可使用。
+// @Description VNC控制台在连接后首先发送一条文本消息 {"type":"vnc-auth","password":"..."}，之后为RFB二进制数据
+// @Tags 用户/实例
+// @Accept json
+// @Produce json
+// @Param id path uint true "实例ID"
+// @Param type query string false "控制台类型：serial（默认）或 vnc"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} common.Response "请求参数错误"
+// @Failure 401 {object} common.Response "未授权"
+// @Failure 404 {object} common.Response "实例不存在"
+// @Failure 500 {object} common.Response "服务器错误"
+// @Router /v1/user/instances/{id}/console [get]
+func ConsoleWebSocket(c *gin.Context) {
+	// 获取用户ID
+	userIDInterface, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{"code": 401, "message": "未授权"})
+		return
+	}
+	userID := userIDInterface.(uint)
+
+	// 获取实例ID
+	instanceID := c.Param("id")
+	if instanceID == "" {
+		c.JSON(400, gin.H{"code": 400, "message": "实例ID不能为空"})
+		return
+	}
+
+	consoleType := c.DefaultQuery("type", provider.ConsoleTypeSerial)
+	if consoleType != provider.ConsoleTypeSerial && consoleType != provider.ConsoleTypeVNC {
+		c.JSON(400, gin.H{"code": 400, "message": "不支持的控制台类型"})
+		return
+	}
+
+	// 获取实例信息（仅限实例所有者）
+	var instance providerModel.Instance
+	err := global.APP_DB.Select("id", "name", "provider_id", "status").
+		Where("id = ? AND user_id = ?", instanceID, userID).
+		First(&instance).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"code": 404, "message": "实例不存在"})
+			return
+		}
+		global.APP_LOG.Error("查询实例失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 500, "message": "查询实例失败"})
+		return
+	}
+
+	// 检查实例状态
+	if instance.Status != "running" {
+		c.JSON(400, gin.H{"code": 400, "message": "实例未运行，无法连接控制台"})
+		return
+	}
+
+	providerApiService := &providerService.ProviderApiService{}
+	prov, _, err := providerApiService.GetProviderByID(instance.ProviderID)
+	if err != nil {
+		global.APP_LOG.Error("获取Provider失败", zap.Uint("providerID", instance.ProviderID), zap.Error(err))
+		c.JSON(500, gin.H{"code": 500, "message": "获取Provider失败"})
+		return
+	}
+	consoleProvider, ok := prov.(provider.ConsoleProvider)
+	if !ok {
+		c.JSON(400, gin.H{"code": 400, "message": fmt.Sprintf("该实例所在的Provider（%s）不支持控制台", prov.GetType())})
+		return
+	}
+
+	// 升级到WebSocket
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		global.APP_LOG.Error("WebSocket升级失败", zap.Error(err))
+		return
+	}
+	defer ws.Close()
+
+	openCtx, openCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	session, err := consoleProvider.OpenConsole(openCtx, instance.Name, consoleType)
+	openCancel()
+	if err != nil {
+		global.APP_LOG.Error("控制台连接失败",
+			zap.Uint("instanceID", instance.ID),
+			zap.String("type", consoleType),
+			zap.Error(err))
+		ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("控制台连接失败: %v\r\n", err)))
+		return
+	}
+	defer session.Close()
+
+	var wsWriteMu sync.Mutex
+	writeWS := func(messageType int, data []byte) error {
+		wsWriteMu.Lock()
+		defer wsWriteMu.Unlock()
+		return ws.WriteMessage(messageType, data)
+	}
+
+	// VNC控制台先下发认证密码，前端noVNC使用该密码完成RFB认证
+	if consoleType == provider.ConsoleTypeVNC {
+		authMsg, _ := json.Marshal(gin.H{"type": "vnc-auth", "password": session.Password()})
+		if err := writeWS(websocket.TextMessage, authMsg); err != nil {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	errChan := make(chan error, 2)
+	wg := &sync.WaitGroup{}
+
+	// WebSocket -> 控制台
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("控制台WebSocket读取goroutine panic", zap.Any("panic", r))
+			}
+		}()
+
+		for {
+			messageType, message, err := ws.ReadMessage()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if messageType != websocket.TextMessage && messageType != websocket.BinaryMessage {
+				continue
+			}
+
+			// 串口控制台的文本消息可能是终端大小调整或心跳
+			if consoleType == provider.ConsoleTypeSerial && messageType == websocket.TextMessage {
+				var msg map[string]interface{}
+				if err := json.Unmarshal(message, &msg); err == nil {
+					if msg["type"] == "resize" {
+						cols, colsOK := msg["cols"].(float64)
+						rows, rowsOK := msg["rows"].(float64)
+						if colsOK && rowsOK {
+							if err := session.Resize(int(cols), int(rows)); err != nil {
+								global.APP_LOG.Warn("控制台窗口大小调整失败", zap.Error(err))
+							}
+							continue
+						}
+					}
+					if msg["type"] == "ping" {
+						continue
+					}
+				}
+			}
+
+			if err := session.Write(message); err != nil {
+				errChan <- err
+				return
+			}
+		}
+	}()
+
+	// 控制台 -> WebSocket
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("控制台输出goroutine panic", zap.Any("panic", r))
+			}
+		}()
+
+		for {
+			data, err := session.Read()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			if len(data) == 0 {
+				continue
+			}
+			if err := writeWS(websocket.BinaryMessage, data); err != nil {
+				errChan <- err
+				return
+			}
+		}
+	}()
+
+	// 等待连接结束或超时
+	select {
+	case <-ctx.Done():
+		global.APP_LOG.Info("控制台连接超时", zap.Uint("instanceID", instance.ID))
+	case err := <-errChan:
+		if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+			global.APP_LOG.Warn("控制台会话结束", zap.Uint("instanceID", instance.ID), zap.Error(err))
+		}
+	}
+
+	// 关闭两端连接，使阻塞在读取上的goroutine退出
+	session.Close()
+	ws.Close()
+	wg.Wait()
+	global.APP_LOG.Debug("控制台WebSocket已关闭", zap.Uint("instanceID", instance.ID))
+}
@@ -0,0 +1,206 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// respondDomainError 统一处理域名绑定操作错误
+func respondDomainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInstanceNotFound):
+		common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+	case errors.Is(err, domain.ErrDomainNotFound):
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+	default:
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+	}
+}
+
+// GetUserDomains 获取域名绑定列表
+// @Summary 获取域名绑定列表
+// @Description 获取当前用户绑定的全部域名及其验证和生效状态
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]provider.InstanceDomain} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/domains [get]
+func GetUserDomains(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	bindings, err := domain.NewService().ListUserDomains(userID, 0)
+	if err != nil {
+		global.APP_LOG.Error("获取域名绑定列表失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取域名绑定列表失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, bindings)
+}
+
+// GetInstanceDomains 获取实例绑定的域名
+// @Summary 获取实例绑定的域名
+// @Description 获取指定实例绑定的域名列表
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=[]provider.InstanceDomain} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/instances/{id}/domains [get]
+func GetInstanceDomains(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	bindings, err := domain.NewService().ListUserDomains(userID, uint(instanceID))
+	if err != nil {
+		global.APP_LOG.Error("获取实例域名绑定失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取域名绑定列表失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, bindings)
+}
+
+// CreateInstanceDomain 为实例绑定域名
+// @Summary 为实例绑定域名
+// @Description 将域名绑定到实例内部端口，由节点上的反向代理转发。http协议按Host头转发节点80端口，https协议按SNI透传节点443端口（证书由实例自行管理）。
+// @Description 绑定后需验证域名所有权：添加TXT记录 _oneclickvirt-verify.<域名>，值为返回的verifyToken。已验证过的域名再次绑定时直接生效；其他用户已验证的域名不能绑定，未验证的绑定保留7天
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.CreateDomainRequest true "绑定域名请求参数"
+// @Success 200 {object} common.Response{data=provider.InstanceDomain} "绑定成功"
+// @Failure 400 {object} common.Response "参数错误、域名已被绑定或超出等级限制"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Router /user/instances/{id}/domains [post]
+func CreateInstanceDomain(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.CreateDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	binding, err := domain.NewService().CreateDomain(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("用户绑定域名失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.String("domain", req.Domain),
+			zap.Error(err))
+		respondDomainError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, binding, "域名已绑定")
+}
+
+// VerifyUserDomain 验证域名所有权
+// @Summary 验证域名所有权
+// @Description 按绑定时选择的方式验证域名所有权，通过后反向代理立即生效；对配置失败的绑定会重新下发节点配置
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名绑定ID"
+// @Success 200 {object} common.Response{data=provider.InstanceDomain} "验证成功"
+// @Failure 400 {object} common.Response "验证失败"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "域名绑定不存在"
+// @Router /user/domains/{id}/verify [post]
+func VerifyUserDomain(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	domainID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的域名绑定ID"))
+		return
+	}
+
+	binding, err := domain.NewService().VerifyDomain(userID, uint(domainID))
+	if err != nil {
+		respondDomainError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, binding, "域名验证通过")
+}
+
+// DeleteUserDomain 删除域名绑定
+// @Summary 删除域名绑定
+// @Description 删除域名绑定并从节点的反向代理中移除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "域名绑定ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "域名绑定不存在"
+// @Router /user/domains/{id} [delete]
+func DeleteUserDomain(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	domainID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的域名绑定ID"))
+		return
+	}
+
+	if err := domain.NewService().DeleteDomain(userID, uint(domainID)); err != nil {
+		respondDomainError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "域名绑定已删除")
+}
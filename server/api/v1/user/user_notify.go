@@ -0,0 +1,145 @@
+package user
+
+import (
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/notify"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetNotificationSetting 获取通知设置
+// @Summary 获取通知设置
+// @Description 获取当前用户的通知渠道和订阅的通知类型，默认全部关闭
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=user.NotificationSetting} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/notification-settings [get]
+func GetNotificationSetting(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	setting, err := notify.NewService().GetSetting(userID)
+	if err != nil {
+		global.APP_LOG.Error("获取通知设置失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取通知设置失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, setting)
+}
+
+// UpdateNotificationSetting 更新通知设置
+// @Summary 更新通知设置
+// @Description 设置接收通知的渠道（邮件发送到账号绑定的邮箱，Telegram需填写Chat ID）以及订阅的通知类型
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.UpdateNotificationSettingRequest true "通知设置"
+// @Success 200 {object} common.Response{data=user.NotificationSetting} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/notification-settings [put]
+func UpdateNotificationSetting(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.UpdateNotificationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	setting, err := notify.NewService().UpdateSetting(userID, req)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, setting, "通知设置已更新")
+}
+
+// TestNotification 发送测试通知
+// @Summary 发送测试通知
+// @Description 向当前用户已启用的通知渠道发送一条测试通知
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response "发送成功"
+// @Failure 400 {object} common.Response "发送失败"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/notification-settings/test [post]
+func TestNotification(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	if err := notify.NewService().SendTest(userID); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "测试通知已发送")
+}
+
+// GetNotificationLogs 获取通知记录
+// @Summary 获取通知记录
+// @Description 分页获取发送给当前用户的通知记录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param event query string false "通知事件"
+// @Param channel query string false "发送渠道：email, telegram"
+// @Param status query string false "发送结果：sent, failed"
+// @Success 200 {object} common.Response{data=object} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/notification-logs [get]
+func GetNotificationLogs(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.NotificationLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 10
+	}
+
+	logs, total, err := notify.NewService().ListLogs(userID, req.Event, req.Channel, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		global.APP_LOG.Error("获取通知记录失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取通知记录失败"))
+		return
+	}
+
+	common.ResponseSuccessWithPagination(c, logs, total, req.Page, req.PageSize)
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	userService "oneclickvirt/service/user"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ResizeInstance 调整实例规格
+// @Summary 调整实例规格
+// @Description 调整用户实例的CPU、内存和磁盘规格，未传的规格保持不变，磁盘只能扩容。新规格需满足用户等级限制、配额和节点资源预算，创建异步任务执行
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.ResizeInstanceRequest true "调整规格请求参数"
+// @Success 200 {object} common.Response{data=user.ResizeInstanceResponse} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或超出限制"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Router /user/instances/{id}/resize [post]
+func ResizeInstance(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.ResizeInstanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	task, err := userService.NewService().ResizeInstance(userID, uint(instanceID), req)
+	if err != nil {
+		global.APP_LOG.Warn("用户创建调整规格任务失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		if err.Error() == "实例不存在或无权限" {
+			common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+			return
+		}
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	common.ResponseSuccess(c, user.ResizeInstanceResponse{TaskID: task.ID}, "调整规格任务创建成功")
+}
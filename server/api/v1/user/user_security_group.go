@@ -0,0 +1,281 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/securitygroup"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// respondSecurityGroupError 统一处理安全组操作错误
+func respondSecurityGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, securitygroup.ErrInstanceNotFound):
+		common.ResponseWithError(c, common.NewError(common.CodeForbidden, err.Error()))
+	case errors.Is(err, securitygroup.ErrGroupNotFound):
+		common.ResponseWithError(c, common.NewError(common.CodeNotFound, err.Error()))
+	default:
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+	}
+}
+
+// GetSecurityGroups 获取安全组列表
+// @Summary 获取安全组列表
+// @Description 获取当前用户的所有安全组及其规则和绑定的实例数
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]provider.SecurityGroup} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 500 {object} common.Response "获取失败"
+// @Router /user/security-groups [get]
+func GetSecurityGroups(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	groups, err := securitygroup.NewService().ListGroups(userID)
+	if err != nil {
+		global.APP_LOG.Error("获取安全组列表失败", zap.Uint("userID", userID), zap.Error(err))
+		common.ResponseWithError(c, common.NewError(common.CodeInternalError, "获取安全组列表失败"))
+		return
+	}
+
+	common.ResponseSuccess(c, groups)
+}
+
+// CreateSecurityGroup 创建安全组
+// @Summary 创建安全组
+// @Description 创建入站防火墙规则集合。拒绝规则优先于允许规则，未匹配的入站流量按默认动作处理；默认动作为deny时需显式放行SSH等端口。端口为实例内部端口
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.SecurityGroupRequest true "安全组"
+// @Success 200 {object} common.Response{data=provider.SecurityGroup} "创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/security-groups [post]
+func CreateSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.SecurityGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	group, err := securitygroup.NewService().CreateGroup(userID, req)
+	if err != nil {
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, group, "安全组创建成功")
+}
+
+// UpdateSecurityGroup 更新安全组
+// @Summary 更新安全组
+// @Description 更新安全组并整体替换规则，已绑定的实例会自动重新下发规则
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "安全组ID"
+// @Param request body user.SecurityGroupRequest true "安全组"
+// @Success 200 {object} common.Response{data=provider.SecurityGroup} "更新成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "安全组不存在"
+// @Router /user/security-groups/{id} [put]
+func UpdateSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的安全组ID"))
+		return
+	}
+
+	var req user.SecurityGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误: "+err.Error()))
+		return
+	}
+
+	group, err := securitygroup.NewService().UpdateGroup(userID, uint(groupID), req)
+	if err != nil {
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, group, "安全组已更新")
+}
+
+// DeleteSecurityGroup 删除安全组
+// @Summary 删除安全组
+// @Description 删除安全组，仍有实例绑定时需先解除绑定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "安全组ID"
+// @Success 200 {object} common.Response "删除成功"
+// @Failure 400 {object} common.Response "参数错误或仍有实例绑定"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 404 {object} common.Response "安全组不存在"
+// @Router /user/security-groups/{id} [delete]
+func DeleteSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的安全组ID"))
+		return
+	}
+
+	if err := securitygroup.NewService().DeleteGroup(userID, uint(groupID)); err != nil {
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "安全组已删除")
+}
+
+// GetInstanceSecurityGroup 获取实例绑定的安全组
+// @Summary 获取实例绑定的安全组
+// @Description 获取实例绑定的安全组及规则的应用状态，未绑定时返回null
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=provider.InstanceSecurityGroup} "获取成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Router /user/instances/{id}/security-group [get]
+func GetInstanceSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	binding, err := securitygroup.NewService().GetInstanceGroup(userID, uint(instanceID))
+	if err != nil {
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, binding)
+}
+
+// BindInstanceSecurityGroup 为实例绑定安全组
+// @Summary 为实例绑定安全组
+// @Description 为实例绑定安全组（已绑定时替换），创建异步任务在宿主机上下发规则
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Param request body user.BindSecurityGroupRequest true "绑定安全组请求参数"
+// @Success 200 {object} common.Response{data=admin.Task} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Failure 404 {object} common.Response "安全组不存在"
+// @Router /user/instances/{id}/security-group [put]
+func BindInstanceSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	var req user.BindSecurityGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "参数错误"))
+		return
+	}
+
+	taskModel, err := securitygroup.NewService().BindInstance(userID, uint(instanceID), req.SecurityGroupID)
+	if err != nil {
+		global.APP_LOG.Warn("用户绑定实例安全组失败",
+			zap.Uint("userID", userID),
+			zap.Uint64("instanceID", instanceID),
+			zap.Error(err))
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, taskModel, "安全组已绑定，规则正在下发")
+}
+
+// UnbindInstanceSecurityGroup 解除实例的安全组
+// @Summary 解除实例的安全组
+// @Description 解除实例绑定的安全组，创建异步任务移除宿主机上的防火墙规则
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "实例ID"
+// @Success 200 {object} common.Response{data=admin.Task} "任务创建成功"
+// @Failure 400 {object} common.Response "参数错误或未绑定安全组"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 403 {object} common.Response "实例不存在或无权限"
+// @Router /user/instances/{id}/security-group [delete]
+func UnbindInstanceSecurityGroup(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, "无效的实例ID"))
+		return
+	}
+
+	taskModel, err := securitygroup.NewService().UnbindInstance(userID, uint(instanceID))
+	if err != nil {
+		respondSecurityGroupError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, taskModel, "安全组已解除，规则正在移除")
+}
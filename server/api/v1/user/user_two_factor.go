@@ -0,0 +1,178 @@
+package user
+
+import (
+	"oneclickvirt/model/common"
+	"oneclickvirt/model/user"
+	authService "oneclickvirt/service/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondTwoFactorError 统一处理两步验证操作错误
+func respondTwoFactorError(c *gin.Context, err error) {
+	if appErr, ok := err.(*common.AppError); ok {
+		common.ResponseWithError(c, appErr)
+		return
+	}
+	common.ResponseWithError(c, common.NewError(common.CodeInternalError, err.Error()))
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前用户是否启用两步验证、是否被系统要求启用以及剩余恢复码数量
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=user.TwoFactorStatusResponse} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Router /user/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	status, err := twoFactorService.GetStatus(userID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, status)
+}
+
+// SetupTwoFactor 获取两步验证绑定信息
+// @Summary 获取两步验证绑定信息
+// @Description 生成新的TOTP密钥和otpauth://绑定URI（可生成二维码），需调用启用接口提交验证码后生效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=user.TwoFactorSetupResponse} "获取成功"
+// @Failure 401 {object} common.Response "用户未登录"
+// @Failure 409 {object} common.Response "已启用两步验证"
+// @Router /user/2fa/setup [post]
+func SetupTwoFactor(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	setup, err := twoFactorService.Setup(userID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, setup)
+}
+
+// EnableTwoFactor 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交验证器App中的验证码确认绑定，返回一次性恢复码（仅显示一次）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} common.Response{data=user.TwoFactorRecoveryCodesResponse} "启用成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "验证码错误"
+// @Router /user/2fa/enable [post]
+func EnableTwoFactor(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	codes, err := twoFactorService.Enable(userID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, user.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, "两步验证已启用")
+}
+
+// DisableTwoFactor 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交验证码或恢复码关闭两步验证；系统强制管理员启用时不可关闭
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.TwoFactorCodeRequest true "验证码或恢复码"
+// @Success 200 {object} common.Response "关闭成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "验证码错误"
+// @Failure 403 {object} common.Response "系统要求启用两步验证"
+// @Router /user/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	if err := twoFactorService.Disable(userID, req.Code); err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, nil, "两步验证已关闭")
+}
+
+// RegenerateTwoFactorRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 提交验证器App中的验证码重新生成恢复码，旧恢复码全部失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body user.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} common.Response{data=user.TwoFactorRecoveryCodesResponse} "生成成功"
+// @Failure 400 {object} common.Response "参数错误"
+// @Failure 401 {object} common.Response "验证码错误"
+// @Router /user/2fa/recovery-codes [post]
+func RegenerateTwoFactorRecoveryCodes(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeUnauthorized, err.Error()))
+		return
+	}
+
+	var req user.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.ResponseWithError(c, common.NewError(common.CodeValidationError, err.Error()))
+		return
+	}
+
+	twoFactorService := authService.TwoFactorService{}
+	codes, err := twoFactorService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	common.ResponseSuccess(c, user.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, "恢复码已重新生成")
+}
@@ -14,6 +14,14 @@ type Server struct {
 	CDN        CDN        `mapstructure:"cdn" json:"cdn" yaml:"cdn"`
 	Task       Task       `mapstructure:"task" json:"task" yaml:"task"`
 	Upload     Upload     `mapstructure:"upload" json:"upload" yaml:"upload"`
+	Backup     Backup     `mapstructure:"backup" json:"backup" yaml:"backup"`
+	Placement  Placement  `mapstructure:"placement" json:"placement" yaml:"placement"`
+	Billing    Billing    `mapstructure:"billing" json:"billing" yaml:"billing"`
+	Notify     Notify     `mapstructure:"notify" json:"notify" yaml:"notify"`
+	Health     Health     `mapstructure:"health" json:"health" yaml:"health"`
+	Metrics    Metrics    `mapstructure:"metrics" json:"metrics" yaml:"metrics"`
+	Audit      Audit      `mapstructure:"audit" json:"audit" yaml:"audit"`
+	Encryption Encryption `mapstructure:"encryption" json:"encryption" yaml:"encryption"`
 	Other      Other      `mapstructure:"other" json:"other" yaml:"other"`
 }
 
@@ -40,6 +48,7 @@ type Auth struct {
 	TelegramBotToken         string `mapstructure:"telegram-bot-token" json:"telegram-bot-token" yaml:"telegram-bot-token"`
 	QQAppID                  string `mapstructure:"qq-app-id" json:"qq-app-id" yaml:"qq-app-id"`
 	QQAppKey                 string `mapstructure:"qq-app-key" json:"qq-app-key" yaml:"qq-app-key"`
+	ForceAdminTwoFactor      bool   `mapstructure:"force-admin-two-factor" json:"force-admin-two-factor" yaml:"force-admin-two-factor"` // 是否强制管理员启用两步验证
 }
 
 type Quota struct {
@@ -60,22 +69,27 @@ type InstanceTypePermissions struct {
 type LevelLimitInfo struct {
 	MaxInstances int                    `mapstructure:"max-instances" json:"max-instances" yaml:"max-instances"`
 	MaxResources map[string]interface{} `mapstructure:"max-resources" json:"max-resources" yaml:"max-resources"`
-	MaxTraffic   int64                  `mapstructure:"max-traffic" json:"max-traffic" yaml:"max-traffic"` // 最大流量限制（MB）
-	ExpiryDays   int                    `mapstructure:"expiry-days" json:"expiry-days" yaml:"expiry-days"` // 新注册用户的默认过期天数，0表示不过期
+	MaxTraffic   int64                  `mapstructure:"max-traffic" json:"max-traffic" yaml:"max-traffic"`       // 最大流量限制（MB）
+	ExpiryDays   int                    `mapstructure:"expiry-days" json:"expiry-days" yaml:"expiry-days"`       // 新注册用户的默认过期天数，0表示不过期
+	MaxSnapshots int                    `mapstructure:"max-snapshots" json:"max-snapshots" yaml:"max-snapshots"` // 每个实例最多保留的快照数，0表示不允许创建快照
+	MaxBackups   int                    `mapstructure:"max-backups" json:"max-backups" yaml:"max-backups"`       // 每个用户最多保留的备份数，超出时自动删除最旧的备份，0表示不允许备份
+	MaxDomains   int                    `mapstructure:"max-domains" json:"max-domains" yaml:"max-domains"`       // 每个用户最多绑定的域名数，0表示不允许绑定域名
 }
 
 type System struct {
-	Env                     string `mapstructure:"env" json:"env" yaml:"env"`                                                                      // 环境值
-	Addr                    int    `mapstructure:"addr" json:"addr" yaml:"addr"`                                                                   // 端口值
-	DbType                  string `mapstructure:"db-type" json:"db-type" yaml:"db-type"`                                                          // 数据库类型:mysql(默认)|mariadb
-	OssType                 string `mapstructure:"oss-type" json:"oss-type" yaml:"oss-type"`                                                       // Oss类型
-	UseMultipoint           bool   `mapstructure:"use-multipoint" json:"use-multipoint" yaml:"use-multipoint"`                                     // 多点登录拦截
-	UseRedis                bool   `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"`                                                    // 使用redis
-	LimitCountIP            int    `mapstructure:"iplimit-count" json:"iplimit-count" yaml:"iplimit-count"`                                        // IP限流计数
-	LimitTimeIP             int    `mapstructure:"iplimit-time" json:"iplimit-time" yaml:"iplimit-time"`                                           // IP限流时间
-	FrontendURL             string `mapstructure:"frontend-url" json:"frontend-url" yaml:"frontend-url"`                                           // 前端URL，用于OAuth2回调跳转
-	ProviderInactiveHours   int    `mapstructure:"provider-inactive-hours" json:"provider-inactive-hours" yaml:"provider-inactive-hours"`          // Provider不活动阈值（小时），默认72小时
-	OAuth2StateTokenMinutes int    `mapstructure:"oauth2-state-token-minutes" json:"oauth2-state-token-minutes" yaml:"oauth2-state-token-minutes"` // OAuth2 State令牌有效期（分钟），默认15分钟
+	Env                     string   `mapstructure:"env" json:"env" yaml:"env"`                                                                      // 环境值
+	Addr                    int      `mapstructure:"addr" json:"addr" yaml:"addr"`                                                                   // 端口值
+	DbType                  string   `mapstructure:"db-type" json:"db-type" yaml:"db-type"`                                                          // 数据库类型:mysql(默认)|mariadb|postgres|sqlite
+	OssType                 string   `mapstructure:"oss-type" json:"oss-type" yaml:"oss-type"`                                                       // Oss类型
+	UseMultipoint           bool     `mapstructure:"use-multipoint" json:"use-multipoint" yaml:"use-multipoint"`                                     // 多点登录拦截
+	UseRedis                bool     `mapstructure:"use-redis" json:"use-redis" yaml:"use-redis"`                                                    // 使用redis
+	EnableIPLimit           bool     `mapstructure:"iplimit-enabled" json:"iplimit-enabled" yaml:"iplimit-enabled"`                                  // 是否启用全局IP限流，默认关闭
+	LimitCountIP            int      `mapstructure:"iplimit-count" json:"iplimit-count" yaml:"iplimit-count"`                                        // IP限流计数
+	LimitTimeIP             int      `mapstructure:"iplimit-time" json:"iplimit-time" yaml:"iplimit-time"`                                           // IP限流时间
+	TrustedProxies          []string `mapstructure:"trusted-proxies" json:"trusted-proxies" yaml:"trusted-proxies"`                                  // 可信反向代理IP或CIDR，IP限流只从这些代理转发的X-Forwarded-For中取客户端IP
+	FrontendURL             string   `mapstructure:"frontend-url" json:"frontend-url" yaml:"frontend-url"`                                           // 前端URL，用于OAuth2回调跳转
+	ProviderInactiveHours   int      `mapstructure:"provider-inactive-hours" json:"provider-inactive-hours" yaml:"provider-inactive-hours"`          // Provider不活动阈值（小时），默认72小时
+	OAuth2StateTokenMinutes int      `mapstructure:"oauth2-state-token-minutes" json:"oauth2-state-token-minutes" yaml:"oauth2-state-token-minutes"` // OAuth2 State令牌有效期（分钟），默认15分钟
 }
 
 type JWT struct {
@@ -85,7 +99,7 @@ type JWT struct {
 	Issuer      string `mapstructure:"issuer" json:"issuer" yaml:"issuer"`                   // 签发者
 }
 
-// Database 数据库配置，支持MySQL和MariaDB
+// Database 数据库配置，MySQL、MariaDB、PostgreSQL和SQLite共用，SQLite使用 db-name 作为数据库文件路径
 type Mysql struct {
 	Path         string `mapstructure:"path" json:"path" yaml:"path"`                               // 服务器地址:端口
 	Port         string `mapstructure:"port" json:"port" yaml:"port"`                               //:端口
@@ -140,3 +154,76 @@ type Task struct {
 type Upload struct {
 	MaxAvatarSize int64 `mapstructure:"max-avatar-size" json:"max-avatar-size" yaml:"max-avatar-size"` // 头像最大大小（MB）
 }
+
+// Backup 实例备份配置
+type Backup struct {
+	StorageType   string   `mapstructure:"storage-type" json:"storage-type" yaml:"storage-type"`          // 备份存储类型：local（控制端本地，默认）| s3
+	LocalPath     string   `mapstructure:"local-path" json:"local-path" yaml:"local-path"`                // 本地存储目录，默认 storage/backups
+	RemoteTempDir string   `mapstructure:"remote-temp-dir" json:"remote-temp-dir" yaml:"remote-temp-dir"` // 节点上导出/导入归档的临时目录，默认 /var/tmp/oneclickvirt-backups
+	S3            BackupS3 `mapstructure:"s3" json:"s3" yaml:"s3"`
+}
+
+// BackupS3 S3兼容存储配置
+type BackupS3 struct {
+	Endpoint     string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                   // 服务地址，如 https://s3.amazonaws.com 或 http://minio:9000
+	Region       string `mapstructure:"region" json:"region" yaml:"region"`                         // 区域，默认 us-east-1
+	Bucket       string `mapstructure:"bucket" json:"bucket" yaml:"bucket"`                         // 存储桶
+	AccessKey    string `mapstructure:"access-key" json:"access-key" yaml:"access-key"`             // 访问密钥ID
+	SecretKey    string `mapstructure:"secret-key" json:"secret-key" yaml:"secret-key"`             // 访问密钥
+	Prefix       string `mapstructure:"prefix" json:"prefix" yaml:"prefix"`                         // 对象键前缀
+	UsePathStyle bool   `mapstructure:"use-path-style" json:"use-path-style" yaml:"use-path-style"` // 使用路径风格访问（MinIO等通常需要开启）
+}
+
+// Placement 自动选择节点（调度）配置
+type Placement struct {
+	Strategy string           `mapstructure:"strategy" json:"strategy" yaml:"strategy"` // 调度策略：spread（分散，默认）| pack（集中）| weighted（加权）
+	Weights  PlacementWeights `mapstructure:"weights" json:"weights" yaml:"weights"`    // weighted策略各项剩余资源的权重
+}
+
+// PlacementWeights 加权调度策略的权重，全部为0时使用等权重
+type PlacementWeights struct {
+	CPU       float64 `mapstructure:"cpu" json:"cpu" yaml:"cpu"`                   // 剩余CPU比例权重
+	Memory    float64 `mapstructure:"memory" json:"memory" yaml:"memory"`          // 剩余内存比例权重
+	Disk      float64 `mapstructure:"disk" json:"disk" yaml:"disk"`                // 剩余磁盘比例权重
+	Instances float64 `mapstructure:"instances" json:"instances" yaml:"instances"` // 剩余实例名额比例权重
+}
+
+// Billing 预付费计费配置，金额单位均为分
+type Billing struct {
+	Enabled             bool   `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                                           // 是否启用计费，关闭时不扣款也不校验余额
+	Currency            string `mapstructure:"currency" json:"currency" yaml:"currency"`                                        // 货币代码，仅用于展示，默认CNY
+	ChargeInterval      int    `mapstructure:"charge-interval" json:"charge-interval" yaml:"charge-interval"`                   // 扣款检查间隔（分钟），默认10
+	LowBalanceThreshold int64  `mapstructure:"low-balance-threshold" json:"low-balance-threshold" yaml:"low-balance-threshold"` // 低余额提醒阈值（分）
+}
+
+// Notify 用户通知配置，邮件使用 auth 中的SMTP设置，Telegram使用 auth 中的Bot Token
+type Notify struct {
+	Enabled            bool `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                                        // 是否启用通知
+	ExpiryReminderDays int  `mapstructure:"expiry-reminder-days" json:"expiry-reminder-days" yaml:"expiry-reminder-days"` // 到期前多少天发送提醒，默认3
+}
+
+// Health Provider健康检查历史配置
+type Health struct {
+	HistoryRetentionDays int `mapstructure:"history-retention-days" json:"history-retention-days" yaml:"history-retention-days"` // 健康检查历史保留天数，默认90，至少30以支持30天可用率统计
+}
+
+// Metrics 实例资源使用（CPU、内存、磁盘）采集配置
+type Metrics struct {
+	Enabled             bool `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                                           // 是否启用实例资源采集
+	CollectInterval     int  `mapstructure:"collect-interval" json:"collect-interval" yaml:"collect-interval"`                // 采集间隔（分钟），默认5
+	RawRetentionDays    int  `mapstructure:"raw-retention-days" json:"raw-retention-days" yaml:"raw-retention-days"`          // 原始采样保留天数，默认3
+	HourlyRetentionDays int  `mapstructure:"hourly-retention-days" json:"hourly-retention-days" yaml:"hourly-retention-days"` // 小时汇总保留天数，默认90
+}
+
+// Audit 操作审计日志配置
+type Audit struct {
+	Enabled       bool `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                      // 是否记录管理员和用户的写操作
+	RetentionDays int  `mapstructure:"retention-days" json:"retention-days" yaml:"retention-days"` // 审计日志保留天数，默认180
+}
+
+// Encryption 敏感字段加密配置
+// 主密钥优先从环境变量 ONECLICKVIRT_MASTER_KEY 读取，未设置时读取 KeyFile，
+// 文件不存在且数据库中没有已加密的凭据时自动生成，否则拒绝启动
+type Encryption struct {
+	KeyFile string `mapstructure:"key-file" json:"key-file" yaml:"key-file"` // 主密钥文件路径，默认 storage/master.key
+}
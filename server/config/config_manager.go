@@ -29,11 +29,13 @@ var systemLevelConfigKeys = map[string]bool{
 	"system.db-type":                    true,
 	"system.env":                        true,
 	"system.frontend-url":               true,
+	"system.iplimit-enabled":            true,
 	"system.iplimit-count":              true,
 	"system.iplimit-time":               true,
 	"system.oauth2-state-token-minutes": true,
 	"system.oss-type":                   true,
 	"system.provider-inactive-hours":    true,
+	"system.trusted-proxies":            true,
 	"system.use-multipoint":             true,
 	"system.use-redis":                  true,
 
@@ -59,6 +61,9 @@ var systemLevelConfigKeys = map[string]bool{
 	"redis.password": true,
 	"redis.db":       true,
 
+	// 加密配置（主密钥必须在连接数据库前加载）
+	"encryption.key-file": true,
+
 	// Zap 日志配置（日志系统启动必需）
 	"zap.level":              true,
 	"zap.format":             true,
@@ -236,6 +241,77 @@ func (cm *ConfigManager) initValidationRules() {
 		},
 	}
 
+	// 自动调度策略验证规则
+	cm.validationRules["placement.strategy"] = ConfigValidationRule{
+		Required: false,
+		Type:     "string",
+	}
+
+	// 计费配置验证规则
+	cm.validationRules["billing.enabled"] = ConfigValidationRule{
+		Required: false,
+		Type:     "bool",
+	}
+	cm.validationRules["billing.charge-interval"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 1440,
+	}
+	cm.validationRules["billing.low-balance-threshold"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 0,
+	}
+
+	// 通知配置验证规则
+	cm.validationRules["notify.enabled"] = ConfigValidationRule{
+		Required: false,
+		Type:     "bool",
+	}
+	cm.validationRules["notify.expiry-reminder-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 90,
+	}
+
+	// 健康检查历史验证规则
+	cm.validationRules["health.history-retention-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 30,
+		MaxValue: 3650,
+	}
+
+	// 实例资源采集验证规则
+	cm.validationRules["metrics.collect-interval"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 60,
+	}
+	cm.validationRules["metrics.raw-retention-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 1,
+		MaxValue: 30,
+	}
+	cm.validationRules["metrics.hourly-retention-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 7,
+		MaxValue: 3650,
+	}
+
+	// 审计日志验证规则
+	cm.validationRules["audit.retention-days"] = ConfigValidationRule{
+		Required: false,
+		Type:     "int",
+		MinValue: 7,
+		MaxValue: 3650,
+	}
+
 	// 更多验证规则...
 }
 
@@ -487,7 +563,10 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 				"disk":      1024,
 				"bandwidth": 100,
 			},
-			"max-traffic": 102400,
+			"max-traffic":   102400,
+			"max-snapshots": 1,
+			"max-backups":   1,
+			"max-domains":   1,
 		},
 		"2": {
 			"max-instances": 3,
@@ -497,7 +576,10 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 				"disk":      20480,
 				"bandwidth": 200,
 			},
-			"max-traffic": 204800,
+			"max-traffic":   204800,
+			"max-snapshots": 2,
+			"max-backups":   2,
+			"max-domains":   3,
 		},
 		"3": {
 			"max-instances": 5,
@@ -507,7 +589,10 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 				"disk":      40960,
 				"bandwidth": 500,
 			},
-			"max-traffic": 307200,
+			"max-traffic":   307200,
+			"max-snapshots": 3,
+			"max-backups":   3,
+			"max-domains":   5,
 		},
 		"4": {
 			"max-instances": 10,
@@ -517,7 +602,10 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 				"disk":      81920,
 				"bandwidth": 1000,
 			},
-			"max-traffic": 409600,
+			"max-traffic":   409600,
+			"max-snapshots": 5,
+			"max-backups":   5,
+			"max-domains":   10,
 		},
 		"5": {
 			"max-instances": 20,
@@ -527,7 +615,10 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 				"disk":      163840,
 				"bandwidth": 2000,
 			},
-			"max-traffic": 512000,
+			"max-traffic":   512000,
+			"max-snapshots": 10,
+			"max-backups":   10,
+			"max-domains":   20,
 		},
 	}
 
@@ -577,6 +668,54 @@ func (cm *ConfigManager) validateLevelLimits(value interface{}) error {
 			}
 		}
 
+		// 验证并填充 max-snapshots（允许为0，表示不允许创建快照）
+		maxSnapshots, exists := limitMap["max-snapshots"]
+		if !exists || maxSnapshots == nil {
+			if hasDefault {
+				limitMap["max-snapshots"] = defaultConfig["max-snapshots"]
+				cm.logger.Info("自动填充默认配置",
+					zap.String("level", levelStr),
+					zap.String("field", "max-snapshots"),
+					zap.Any("value", defaultConfig["max-snapshots"]))
+			} else {
+				limitMap["max-snapshots"] = 0
+			}
+		} else if err := validateNonNegativeNumber(maxSnapshots, fmt.Sprintf("等级 %s 的 max-snapshots", levelStr)); err != nil {
+			return err
+		}
+
+		// 验证并填充 max-backups（允许为0，表示不允许备份）
+		maxBackups, exists := limitMap["max-backups"]
+		if !exists || maxBackups == nil {
+			if hasDefault {
+				limitMap["max-backups"] = defaultConfig["max-backups"]
+				cm.logger.Info("自动填充默认配置",
+					zap.String("level", levelStr),
+					zap.String("field", "max-backups"),
+					zap.Any("value", defaultConfig["max-backups"]))
+			} else {
+				limitMap["max-backups"] = 0
+			}
+		} else if err := validateNonNegativeNumber(maxBackups, fmt.Sprintf("等级 %s 的 max-backups", levelStr)); err != nil {
+			return err
+		}
+
+		// 验证并填充 max-domains（允许为0，表示不允许绑定域名）
+		maxDomains, exists := limitMap["max-domains"]
+		if !exists || maxDomains == nil {
+			if hasDefault {
+				limitMap["max-domains"] = defaultConfig["max-domains"]
+				cm.logger.Info("自动填充默认配置",
+					zap.String("level", levelStr),
+					zap.String("field", "max-domains"),
+					zap.Any("value", defaultConfig["max-domains"]))
+			} else {
+				limitMap["max-domains"] = 0
+			}
+		} else if err := validateNonNegativeNumber(maxDomains, fmt.Sprintf("等级 %s 的 max-domains", levelStr)); err != nil {
+			return err
+		}
+
 		// 验证并填充 max-resources
 		maxResources, exists := limitMap["max-resources"]
 		if !exists || maxResources == nil {
@@ -647,6 +786,31 @@ func validatePositiveNumber(value interface{}, fieldName string) error {
 	return nil
 }
 
+// validateNonNegativeNumber 验证数值必须为非负数
+func validateNonNegativeNumber(value interface{}, fieldName string) error {
+	switch v := value.(type) {
+	case int:
+		if v < 0 {
+			return fmt.Errorf("%s 不能小于0", fieldName)
+		}
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("%s 不能小于0", fieldName)
+		}
+	case float64:
+		if v < 0 {
+			return fmt.Errorf("%s 不能小于0", fieldName)
+		}
+	case float32:
+		if v < 0 {
+			return fmt.Errorf("%s 不能小于0", fieldName)
+		}
+	default:
+		return fmt.Errorf("%s 必须是数值类型", fieldName)
+	}
+	return nil
+}
+
 // flattenConfig 将嵌套配置展开为扁平的 key-value 对
 // 例如: {"quota": {"levelLimits": {...}}} => {"quota.levelLimits": {...}}
 func (cm *ConfigManager) flattenConfig(config map[string]interface{}, prefix string) map[string]interface{} {
@@ -984,7 +1148,7 @@ func (cm *ConfigManager) saveConfigToDBWithTx(tx *gorm.DB, key string, value int
 
 	// 先尝试查找已存在的配置
 	var existingConfig SystemConfig
-	err := tx.Where("`key` = ?", key).First(&existingConfig).Error
+	err := tx.Where(map[string]interface{}{"key": key}).First(&existingConfig).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// 记录不存在，创建新记录
@@ -1360,6 +1524,7 @@ func getDefaultConfigMap() map[string]interface{} {
 			"telegram-bot-token":         "",
 			"qq-app-id":                  "",
 			"qq-app-key":                 "",
+			"force-admin-two-factor":     false,
 		},
 		"quota": map[string]interface{}{
 			"default-level": 1,
@@ -1379,7 +1544,10 @@ func getDefaultConfigMap() map[string]interface{} {
 						"memory": 1024,
 						"disk":   10,
 					},
-					"max-traffic": 0,
+					"max-traffic":   0,
+					"max-snapshots": 1,
+					"max-backups":   1,
+					"max-domains":   1,
 				},
 				"2": map[string]interface{}{
 					"max-instances": 3,
@@ -1388,7 +1556,10 @@ func getDefaultConfigMap() map[string]interface{} {
 						"memory": 1024,
 						"disk":   20,
 					},
-					"max-traffic": 0,
+					"max-traffic":   0,
+					"max-snapshots": 2,
+					"max-backups":   2,
+					"max-domains":   3,
 				},
 				"3": map[string]interface{}{
 					"max-instances": 5,
@@ -1397,7 +1568,10 @@ func getDefaultConfigMap() map[string]interface{} {
 						"memory": 2048,
 						"disk":   40,
 					},
-					"max-traffic": 0,
+					"max-traffic":   0,
+					"max-snapshots": 3,
+					"max-backups":   3,
+					"max-domains":   5,
 				},
 				"4": map[string]interface{}{
 					"max-instances": 10,
@@ -1406,7 +1580,10 @@ func getDefaultConfigMap() map[string]interface{} {
 						"memory": 4096,
 						"disk":   80,
 					},
-					"max-traffic": 0,
+					"max-traffic":   0,
+					"max-snapshots": 5,
+					"max-backups":   5,
+					"max-domains":   10,
 				},
 				"5": map[string]interface{}{
 					"max-instances": 20,
@@ -1415,7 +1592,10 @@ func getDefaultConfigMap() map[string]interface{} {
 						"memory": 8192,
 						"disk":   160,
 					},
-					"max-traffic": 0,
+					"max-traffic":   0,
+					"max-snapshots": 10,
+					"max-backups":   10,
+					"max-domains":   20,
 				},
 			},
 		},
@@ -1441,8 +1621,10 @@ func getDefaultConfigMap() map[string]interface{} {
 			"oss-type":                   "local",
 			"use-multipoint":             false,
 			"use-redis":                  false,
-			"iplimit-count":              100,
+			"iplimit-enabled":            false,
+			"iplimit-count":              15000,
 			"iplimit-time":               3600,
+			"trusted-proxies":            []string{},
 			"frontend-url":               "",
 			"provider-inactive-hours":    72,
 			"oauth2-state-token-minutes": 15,
@@ -1460,6 +1642,38 @@ func getDefaultConfigMap() map[string]interface{} {
 			"max-avatar-size":  5.0,
 			"default-language": "zh",
 		},
+		"placement": map[string]interface{}{
+			"strategy": "spread",
+			"weights": map[string]interface{}{
+				"cpu":       1.0,
+				"memory":    1.0,
+				"disk":      1.0,
+				"instances": 1.0,
+			},
+		},
+		"billing": map[string]interface{}{
+			"enabled":               false,
+			"currency":              "CNY",
+			"charge-interval":       10,
+			"low-balance-threshold": 1000,
+		},
+		"notify": map[string]interface{}{
+			"enabled":              false,
+			"expiry-reminder-days": 3,
+		},
+		"health": map[string]interface{}{
+			"history-retention-days": 90,
+		},
+		"metrics": map[string]interface{}{
+			"enabled":               true,
+			"collect-interval":      5,
+			"raw-retention-days":    3,
+			"hourly-retention-days": 90,
+		},
+		"audit": map[string]interface{}{
+			"enabled":        true,
+			"retention-days": 180,
+		},
 	}
 }
 
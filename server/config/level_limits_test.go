@@ -116,6 +116,32 @@ func TestValidateLevelLimitsWithDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "缺少 max-snapshots - 应该自动填充",
+			input: map[string]interface{}{
+				"4": map[string]interface{}{
+					"max-instances": 10,
+					"max-traffic":   409600,
+				},
+			},
+			expectError: false,
+			checkFields: map[string]map[string]interface{}{
+				"4": {
+					"max-snapshots": 5, // 应该被自动填充
+				},
+			},
+		},
+		{
+			name: "max-snapshots 为负数 - 应该报错",
+			input: map[string]interface{}{
+				"2": map[string]interface{}{
+					"max-instances": 3,
+					"max-traffic":   204800,
+					"max-snapshots": -1,
+				},
+			},
+			expectError: true,
+		},
 		{
 			name: "完全空的配置 - 应该全部自动填充",
 			input: map[string]interface{}{
@@ -59,6 +59,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("system.oss-type", "local")
 	v.SetDefault("system.use-multipoint", false)
 	v.SetDefault("system.use-redis", false)
+	v.SetDefault("system.iplimit-enabled", false)
 	v.SetDefault("system.iplimit-count", 15000)
 	v.SetDefault("system.iplimit-time", 3600)
 
@@ -85,6 +86,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("zap.encode-level", "LowercaseColorLevelEncoder")
 	v.SetDefault("zap.stacktrace-key", "stacktrace")
 	v.SetDefault("zap.log-in-console", true)
+
+	v.SetDefault("encryption.key-file", "storage/master.key")
 }
 
 // generateSecureJWTKey 生成安全的JWT密钥
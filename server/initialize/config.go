@@ -8,6 +8,7 @@ import (
 
 	"oneclickvirt/config"
 	"oneclickvirt/global"
+	"oneclickvirt/service/database"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -74,6 +75,7 @@ func getDefaultConfig() config.Server {
 			TelegramBotToken:         "",
 			QQAppID:                  "",
 			QQAppKey:                 "",
+			ForceAdminTwoFactor:      false,
 		},
 		Quota: config.Quota{
 			DefaultLevel: 1,
@@ -148,6 +150,9 @@ func getDefaultConfig() config.Server {
 			Password: "",
 			DB:       0,
 		},
+		Encryption: config.Encryption{
+			KeyFile: "storage/master.key",
+		},
 	}
 }
 
@@ -178,11 +183,13 @@ func createDefaultConfigFile(configPath string) error {
 // 智能选择数据库类型
 func selectDatabaseType(cfg *config.Server) {
 	switch cfg.System.DbType {
-	case "mysql", "mariadb":
+	case "mysql", "mariadb", "postgres":
 		if cfg.Mysql.Dbname == "" && !cfg.Mysql.AutoCreate {
 			fmt.Printf("[CONFIG] %s配置不完整且未启用自动创建数据库\n", cfg.System.DbType)
 		}
 		fmt.Printf("[CONFIG] 使用数据库类型: %s\n", cfg.System.DbType)
+	case "sqlite":
+		fmt.Printf("[CONFIG] 使用数据库类型: sqlite，数据库文件: %s\n", database.SQLitePath(cfg.Mysql.Dbname))
 	default:
 		// 默认使用MySQL，但允许在Docker环境中动态检测
 		detectedType := detectDatabaseType()
@@ -195,7 +202,7 @@ func selectDatabaseType(cfg *config.Server) {
 func detectDatabaseType() string {
 	// 检查环境变量
 	if dbType := os.Getenv("DB_TYPE"); dbType != "" {
-		if dbType == "mysql" || dbType == "mariadb" {
+		if database.IsSupportedType(dbType) {
 			return dbType
 		}
 	}
@@ -79,6 +79,30 @@ func syncConfigToGlobal(key string, oldValue, newValue interface{}) error {
 		if otherConfig, ok := newValue.(map[string]interface{}); ok {
 			syncOtherConfig(otherConfig)
 		}
+	case "placement":
+		if placementConfig, ok := newValue.(map[string]interface{}); ok {
+			syncPlacementConfig(placementConfig)
+		}
+	case "billing":
+		if billingConfig, ok := newValue.(map[string]interface{}); ok {
+			syncBillingConfig(billingConfig)
+		}
+	case "notify":
+		if notifyConfig, ok := newValue.(map[string]interface{}); ok {
+			syncNotifyConfig(notifyConfig)
+		}
+	case "health":
+		if healthConfig, ok := newValue.(map[string]interface{}); ok {
+			syncHealthConfig(healthConfig)
+		}
+	case "metrics":
+		if metricsConfig, ok := newValue.(map[string]interface{}); ok {
+			syncMetricsConfig(metricsConfig)
+		}
+	case "audit":
+		if auditConfig, ok := newValue.(map[string]interface{}); ok {
+			syncAuditConfig(auditConfig)
+		}
 	}
 	return nil
 }
@@ -100,6 +124,9 @@ func syncAuthConfig(authConfig map[string]interface{}) {
 	if v, ok := authConfig["enable-oauth2"].(bool); ok {
 		global.APP_CONFIG.Auth.EnableOAuth2 = v
 	}
+	if v, ok := authConfig["force-admin-two-factor"].(bool); ok {
+		global.APP_CONFIG.Auth.ForceAdminTwoFactor = v
+	}
 }
 
 // syncInviteCodeConfig 同步邀请码配置
@@ -165,6 +192,24 @@ func syncQuotaConfig(quotaConfig map[string]interface{}) {
 					levelLimit.MaxTraffic = int64(v)
 				}
 
+				if v, ok := limitMap["max-snapshots"].(float64); ok {
+					levelLimit.MaxSnapshots = int(v)
+				} else if v, ok := limitMap["max-snapshots"].(int); ok {
+					levelLimit.MaxSnapshots = v
+				}
+
+				if v, ok := limitMap["max-backups"].(float64); ok {
+					levelLimit.MaxBackups = int(v)
+				} else if v, ok := limitMap["max-backups"].(int); ok {
+					levelLimit.MaxBackups = v
+				}
+
+				if v, ok := limitMap["max-domains"].(float64); ok {
+					levelLimit.MaxDomains = int(v)
+				} else if v, ok := limitMap["max-domains"].(int); ok {
+					levelLimit.MaxDomains = v
+				}
+
 				global.APP_CONFIG.Quota.LevelLimits[level] = levelLimit
 			}
 		}
@@ -232,6 +277,9 @@ func syncSystemConfig(systemConfig map[string]interface{}) {
 	if v, ok := systemConfig["use-redis"].(bool); ok {
 		global.APP_CONFIG.System.UseRedis = v
 	}
+	if v, ok := systemConfig["iplimit-enabled"].(bool); ok {
+		global.APP_CONFIG.System.EnableIPLimit = v
+	}
 	if v, ok := systemConfig["iplimit-count"].(float64); ok {
 		global.APP_CONFIG.System.LimitCountIP = int(v)
 	} else if v, ok := systemConfig["iplimit-count"].(int); ok {
@@ -245,6 +293,15 @@ func syncSystemConfig(systemConfig map[string]interface{}) {
 	if v, ok := systemConfig["frontend-url"].(string); ok {
 		global.APP_CONFIG.System.FrontendURL = v
 	}
+	if v, ok := systemConfig["trusted-proxies"].([]interface{}); ok {
+		proxies := make([]string, 0, len(v))
+		for _, item := range v {
+			if proxy, ok := item.(string); ok {
+				proxies = append(proxies, proxy)
+			}
+		}
+		global.APP_CONFIG.System.TrustedProxies = proxies
+	}
 }
 
 // syncJWTConfig 同步JWT配置
@@ -326,3 +383,110 @@ func syncOtherConfig(otherConfig map[string]interface{}) {
 		global.APP_CONFIG.Other.DefaultLanguage = v
 	}
 }
+
+// syncPlacementConfig 同步自动调度配置
+func syncPlacementConfig(placementConfig map[string]interface{}) {
+	if v, ok := placementConfig["strategy"].(string); ok {
+		global.APP_CONFIG.Placement.Strategy = v
+	}
+	weights, ok := placementConfig["weights"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	targets := map[string]*float64{
+		"cpu":       &global.APP_CONFIG.Placement.Weights.CPU,
+		"memory":    &global.APP_CONFIG.Placement.Weights.Memory,
+		"disk":      &global.APP_CONFIG.Placement.Weights.Disk,
+		"instances": &global.APP_CONFIG.Placement.Weights.Instances,
+	}
+	for key, target := range targets {
+		switch v := weights[key].(type) {
+		case float64:
+			*target = v
+		case int:
+			*target = float64(v)
+		case int64:
+			*target = float64(v)
+		}
+	}
+}
+
+// syncBillingConfig 同步计费配置
+func syncBillingConfig(billingConfig map[string]interface{}) {
+	if v, ok := billingConfig["enabled"].(bool); ok {
+		global.APP_CONFIG.Billing.Enabled = v
+	}
+	if v, ok := billingConfig["currency"].(string); ok {
+		global.APP_CONFIG.Billing.Currency = v
+	}
+	switch v := billingConfig["charge-interval"].(type) {
+	case float64:
+		global.APP_CONFIG.Billing.ChargeInterval = int(v)
+	case int:
+		global.APP_CONFIG.Billing.ChargeInterval = v
+	}
+	switch v := billingConfig["low-balance-threshold"].(type) {
+	case float64:
+		global.APP_CONFIG.Billing.LowBalanceThreshold = int64(v)
+	case int:
+		global.APP_CONFIG.Billing.LowBalanceThreshold = int64(v)
+	case int64:
+		global.APP_CONFIG.Billing.LowBalanceThreshold = v
+	}
+}
+
+// syncNotifyConfig 同步通知配置
+func syncNotifyConfig(notifyConfig map[string]interface{}) {
+	if v, ok := notifyConfig["enabled"].(bool); ok {
+		global.APP_CONFIG.Notify.Enabled = v
+	}
+	switch v := notifyConfig["expiry-reminder-days"].(type) {
+	case float64:
+		global.APP_CONFIG.Notify.ExpiryReminderDays = int(v)
+	case int:
+		global.APP_CONFIG.Notify.ExpiryReminderDays = v
+	}
+}
+
+// syncHealthConfig 同步健康检查历史配置
+func syncHealthConfig(healthConfig map[string]interface{}) {
+	switch v := healthConfig["history-retention-days"].(type) {
+	case float64:
+		global.APP_CONFIG.Health.HistoryRetentionDays = int(v)
+	case int:
+		global.APP_CONFIG.Health.HistoryRetentionDays = v
+	}
+}
+
+// syncMetricsConfig 同步实例资源采集配置
+func syncMetricsConfig(metricsConfig map[string]interface{}) {
+	if v, ok := metricsConfig["enabled"].(bool); ok {
+		global.APP_CONFIG.Metrics.Enabled = v
+	}
+	targets := map[string]*int{
+		"collect-interval":      &global.APP_CONFIG.Metrics.CollectInterval,
+		"raw-retention-days":    &global.APP_CONFIG.Metrics.RawRetentionDays,
+		"hourly-retention-days": &global.APP_CONFIG.Metrics.HourlyRetentionDays,
+	}
+	for key, target := range targets {
+		switch v := metricsConfig[key].(type) {
+		case float64:
+			*target = int(v)
+		case int:
+			*target = v
+		}
+	}
+}
+
+// syncAuditConfig 同步审计日志配置
+func syncAuditConfig(auditConfig map[string]interface{}) {
+	if v, ok := auditConfig["enabled"].(bool); ok {
+		global.APP_CONFIG.Audit.Enabled = v
+	}
+	switch v := auditConfig["retention-days"].(type) {
+	case float64:
+		global.APP_CONFIG.Audit.RetentionDays = int(v)
+	case int:
+		global.APP_CONFIG.Audit.RetentionDays = v
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/initialize/internal"
 	"oneclickvirt/model/config"
+	"oneclickvirt/service/database"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -105,7 +106,7 @@ func (dm *DatabaseManager) updateGlobalStats() {
 func (dm *DatabaseManager) connect() (*gorm.DB, error) {
 	global.APP_LOG.Info("正在连接数据库...")
 
-	db, err := GormMysqlConnect(dm.config)
+	db, err := GormConnect(dm.config)
 	if err != nil {
 		return nil, err
 	}
@@ -295,37 +296,20 @@ func (dm *DatabaseManager) Shutdown() {
 	}
 }
 
-// GormMysqlConnect 直接连接数据库（不经过管理器）
-func GormMysqlConnect(cfg config.MysqlConfig) (*gorm.DB, error) {
-	m := global.APP_CONFIG.Mysql
-	dbType := global.APP_CONFIG.System.DbType
-	if dbType == "" {
-		dbType = "mysql"
-	}
-
+// GormConnect 直接连接数据库（不经过管理器）
+func GormConnect(cfg config.MysqlConfig) (*gorm.DB, error) {
 	// 使用传入的配置或全局配置
-	if cfg.Path == "" {
-		cfg = config.MysqlConfig{
-			Path:         m.Path,
-			Port:         m.Port,
-			Config:       m.Config,
-			Dbname:       m.Dbname,
-			Username:     m.Username,
-			Password:     m.Password,
-			MaxIdleConns: m.MaxIdleConns,
-			MaxOpenConns: m.MaxOpenConns,
-			LogMode:      m.LogMode,
-			LogZap:       m.LogZap,
-			MaxLifetime:  m.MaxLifetime,
-			AutoCreate:   m.AutoCreate,
-		}
+	if cfg == (config.MysqlConfig{}) {
+		cfg = currentDatabaseConfig()
 	}
 
-	db, err := internal.GormMysql(cfg)
+	db, err := internal.GormOpen(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	db.InstanceSet("gorm:table_options", "ENGINE="+m.Engine)
+	if database.IsMySQLCompatible(cfg.DbType) && global.APP_CONFIG.Mysql.Engine != "" {
+		db.InstanceSet("gorm:table_options", "ENGINE="+global.APP_CONFIG.Mysql.Engine)
+	}
 	return db, nil
 }
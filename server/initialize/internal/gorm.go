@@ -8,28 +8,35 @@ import (
 
 	"oneclickvirt/global"
 	"oneclickvirt/model/config"
+	"oneclickvirt/service/database"
+	"oneclickvirt/service/secret"
 
 	"go.uber.org/zap"
 
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// GormMysql 初始化数据库（支持MySQL和MariaDB），支持自动创建数据库
-func GormMysql(m config.MysqlConfig) (*gorm.DB, error) {
-	// 检查基本参数
-	if m.Username == "" {
-		return nil, fmt.Errorf("数据库用户名不能为空")
+// GormOpen 初始化数据库（支持MySQL、MariaDB、PostgreSQL和SQLite），支持自动创建数据库
+func GormOpen(m config.MysqlConfig) (*gorm.DB, error) {
+	if m.DbType == "" {
+		m.DbType = database.TypeMySQL
 	}
-	if m.Path == "" {
-		m.Path = "127.0.0.1"
+	if !database.IsSupportedType(m.DbType) {
+		return nil, fmt.Errorf("不支持的数据库类型: %s", m.DbType)
 	}
-	if m.Port == "" {
-		m.Port = "3306"
+
+	// 检查基本参数，SQLite为本地文件，不需要地址和账号
+	if m.DbType != database.TypeSQLite {
+		if m.Username == "" {
+			return nil, fmt.Errorf("数据库用户名不能为空")
+		}
+		if m.Path == "" {
+			m.Path = "127.0.0.1"
+		}
 	}
-	if m.Config == "" {
-		m.Config = "charset=utf8mb4&parseTime=True&loc=Local&time_zone=%27%2B08%3A00%27"
+	if m.Port == "" {
+		m.Port = database.DefaultPort(m.DbType)
 	}
 
 	// 如果没有指定数据库名且需要自动创建，则设置默认数据库名
@@ -37,34 +44,40 @@ func GormMysql(m config.MysqlConfig) (*gorm.DB, error) {
 		m.Dbname = "oneclickvirt" // 默认数据库名
 	}
 
-	// 如果启用自动创建且有权限，尝试创建数据库
-	if m.AutoCreate && m.Username == "root" && m.Dbname != "" {
-		if err := createDatabaseIfNotExists(m); err != nil {
+	// 如果启用自动创建且有权限，尝试创建数据库（SQLite总是需要确保目录存在）
+	if m.DbType == database.TypeSQLite ||
+		(m.AutoCreate && m.Dbname != "" && (m.Username == "root" || m.DbType == database.TypePostgres)) {
+		if created, err := database.CreateDatabaseIfNotExists(m); err != nil {
 			global.APP_LOG.Warn("自动创建数据库失败", zap.Error(err))
 		} else {
+			if created {
+				global.APP_LOG.Info("自动创建数据库成功", zap.String("database", m.Dbname))
+			}
 			global.APP_LOG.Info("数据库检查/创建完成", zap.String("database", m.Dbname))
 		}
 	}
 
-	// 构建DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
-		m.Username, m.Password, m.Path, m.Port, m.Dbname, m.Config)
-
-	mysqlConfig := mysql.Config{
-		DSN:                       dsn,
-		DefaultStringSize:         191,   // string 类型字段的默认长度
-		SkipInitializeWithVersion: false, // 根据版本自动配置
+	dialector, err := database.NewDialector(m)
+	if err != nil {
+		return nil, err
 	}
 
 	gormConfig := gormConfig(m.LogMode, m.LogZap)
 
-	db, err := gorm.Open(mysql.New(mysqlConfig), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
 
+	// 加密字段按列名更新时也要加密
+	if err := secret.RegisterCallbacks(db); err != nil {
+		return nil, fmt.Errorf("注册加密回调失败: %w", err)
+	}
+
 	// 设置表选项为InnoDB引擎
-	db.InstanceSet("gorm:table_options", "ENGINE=InnoDB")
+	if database.IsMySQLCompatible(m.DbType) {
+		db.InstanceSet("gorm:table_options", "ENGINE=InnoDB")
+	}
 
 	// 配置连接池
 	sqlDB, err := db.DB()
@@ -95,39 +108,6 @@ func GormMysql(m config.MysqlConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
-// createDatabaseIfNotExists 创建数据库（如果不存在）
-func createDatabaseIfNotExists(m config.MysqlConfig) error {
-	// 连接数据库服务器（不指定数据库）
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?%s",
-		m.Username, m.Password, m.Path, m.Port, m.Config)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-	if err != nil {
-		return fmt.Errorf("连接数据库服务器失败: %w", err)
-	}
-
-	// 检查数据库是否存在
-	var count int64
-	err = db.Raw("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", m.Dbname).Scan(&count).Error
-	if err != nil {
-		return fmt.Errorf("检查数据库是否存在失败: %w", err)
-	}
-
-	// 如果数据库不存在则创建
-	if count == 0 {
-		createSQL := fmt.Sprintf("CREATE DATABASE `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", m.Dbname)
-		err = db.Exec(createSQL).Error
-		if err != nil {
-			return fmt.Errorf("创建数据库失败: %w", err)
-		}
-		global.APP_LOG.Info("自动创建数据库成功", zap.String("database", m.Dbname))
-	}
-
-	return nil
-}
-
 // gormConfig 根据配置决定是否开启日志
 func gormConfig(mod string, zap bool) (config *gorm.Config) {
 	config = &gorm.Config{DisableForeignKeyConstraintWhenMigrating: false}
@@ -0,0 +1,122 @@
+package initialize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"oneclickvirt/core"
+	"oneclickvirt/global"
+	"oneclickvirt/model/config"
+	"oneclickvirt/service/migration"
+)
+
+const migrateUsage = `用法: %s migrate <命令>
+
+命令:
+  status        查看各迁移的执行状态和当前结构版本
+  up [版本号]   执行未完成的迁移，指定版本号时只执行到该版本
+  down [数量]   按倒序回滚最近执行的迁移，默认回滚1个
+
+服务启动时会自动执行未完成的迁移；数据库结构版本高于程序支持的版本时服务拒绝启动。
+降级程序前，先用当前版本的程序执行 down 回滚到旧版本支持的结构版本，再替换程序。`
+
+// RunMigrateCommand 执行数据库结构迁移相关的命令行子命令，返回进程退出码
+func RunMigrateCommand(args []string) int {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Printf(migrateUsage+"\n", filepath.Base(os.Args[0]))
+		return 2
+	}
+
+	var arg int
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			fmt.Printf("[ERROR] 参数必须是正整数: %s\n", args[1])
+			return 2
+		}
+		arg = n
+	}
+
+	switch args[0] {
+	case "status", "up", "down":
+	default:
+		fmt.Printf(migrateUsage+"\n", filepath.Base(os.Args[0]))
+		return 2
+	}
+
+	global.APP_VP = core.Viper()
+	global.APP_LOG = core.Zap()
+
+	// 直接连接数据库，不经过 Gorm()，避免连接时自动执行迁移
+	db, err := GormConnect(config.MysqlConfig{})
+	if err != nil {
+		fmt.Printf("[ERROR] 数据库连接失败，请检查 config.yaml 中的数据库配置: %v\n", err)
+		return 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	runner := migration.NewRunner(db)
+	var done []migration.Migration
+	switch args[0] {
+	case "status":
+		return printMigrationStatus(runner)
+	case "up":
+		done, err = runner.Up(uint(arg))
+	case "down":
+		if arg == 0 {
+			arg = 1
+		}
+		done, err = runner.Down(arg)
+	}
+
+	for _, m := range done {
+		fmt.Printf("[MIGRATE] %s %s\n", args[0], m)
+	}
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		return 1
+	}
+	if len(done) == 0 {
+		fmt.Println("[MIGRATE] 没有需要处理的迁移")
+	}
+	if current, err := runner.CurrentVersion(); err == nil {
+		fmt.Printf("[SUCCESS] 当前结构版本: %d\n", current)
+	}
+	return 0
+}
+
+// printMigrationStatus 输出迁移状态表
+func printMigrationStatus(runner *migration.Runner) int {
+	statuses, err := runner.Status()
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		return 1
+	}
+	current, err := runner.CurrentVersion()
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("当前结构版本: %d，程序支持的最高版本: %d\n\n", current, runner.LatestVersion())
+	for _, s := range statuses {
+		state, appliedAt := "未执行", "-"
+		if s.Applied {
+			state = "已执行"
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Unknown {
+			state = "未知迁移"
+		}
+		fmt.Printf("%04d  %-24s  %s  %s\n", s.Version, s.Name, state, appliedAt)
+	}
+
+	if err := runner.CheckCompatible(); err != nil {
+		fmt.Printf("\n[WARN] %v\n", err)
+	}
+	return 0
+}
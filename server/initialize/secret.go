@@ -0,0 +1,140 @@
+package initialize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"oneclickvirt/core"
+	"oneclickvirt/global"
+	"oneclickvirt/service/secret"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// initializeSecretKeyring 加载敏感字段加密主密钥，加载失败时无法读写凭据，直接退出
+// 未找到主密钥时暂不生成，连接数据库后由 ensureSecretKeyring 确认没有加密数据再生成
+func initializeSecretKeyring() {
+	kr, source, err := secret.LoadKeyring(global.APP_CONFIG.Encryption.KeyFile)
+	if errors.Is(err, secret.ErrKeyringNotFound) {
+		global.APP_LOG.Info("未找到主密钥，连接数据库后确认没有已加密的凭据再生成", zap.String("source", source))
+		return
+	}
+	if err != nil {
+		global.APP_LOG.Fatal("加载主密钥失败", zap.Error(err))
+	}
+	secret.SetKeyring(kr)
+
+	global.APP_LOG.Info("主密钥加载完成",
+		zap.String("source", source),
+		zap.String("primaryKey", kr.PrimaryID()),
+		zap.Int("keys", kr.Len()))
+}
+
+// ensureSecretKeyring 连接数据库后检查主密钥与已加密的数据是否匹配
+// 数据库中已有加密数据时不能生成新密钥（如未共享 storage/ 的其他副本或密钥文件丢失），
+// 否则该进程会用其他副本无法解密的密钥写入凭据，读取到无法解密的行时整个查询都会失败
+func ensureSecretKeyring(db *gorm.DB) {
+	keyIDs, err := secret.StoredKeyIDs(db)
+	if err != nil {
+		global.APP_LOG.Fatal("检查已加密的凭据失败", zap.Error(err))
+	}
+
+	if !secret.KeyringLoaded() {
+		if len(keyIDs) > 0 {
+			global.APP_LOG.Fatal(fmt.Sprintf("数据库中已有加密的凭据，但未找到主密钥，请通过环境变量 %s 或配置 encryption.key-file 提供加密这些数据的主密钥", secret.MasterKeyEnv),
+				zap.Strings("keyIds", keyIDs))
+		}
+		kr, source, generated, err := secret.GenerateKeyFile(global.APP_CONFIG.Encryption.KeyFile)
+		if err != nil {
+			global.APP_LOG.Fatal("生成主密钥失败", zap.Error(err))
+		}
+		secret.SetKeyring(kr)
+		if generated {
+			global.APP_LOG.Warn("未找到主密钥，已生成新的主密钥文件，请妥善备份，丢失后已加密的凭据将无法解密；多实例部署时各实例必须使用相同的主密钥",
+				zap.String("source", source))
+		}
+		global.APP_LOG.Info("主密钥加载完成",
+			zap.String("source", source),
+			zap.String("primaryKey", kr.PrimaryID()),
+			zap.Int("keys", kr.Len()))
+	}
+
+	if missing := secret.MissingKeys(keyIDs); len(missing) > 0 {
+		global.APP_LOG.Fatal(fmt.Sprintf("数据库中的凭据使用了密钥环中不存在的主密钥，请在环境变量 %s 或配置 encryption.key-file 中补充这些密钥", secret.MasterKeyEnv),
+			zap.Strings("missingKeyIds", missing))
+	}
+}
+
+const secretsUsage = `用法: %s secrets <命令>
+
+命令:
+  generate-key  生成新的主密钥（输出一行，格式为 <密钥ID>:<base64密钥>）
+  encrypt       加密数据库中尚未加密的Provider和实例凭据
+  rotate        使用当前主密钥重新加密全部凭据
+
+主密钥优先读取环境变量 ONECLICKVIRT_MASTER_KEY，未设置时读取配置 encryption.key-file（默认 storage/master.key），
+每行一个密钥，第一行为当前密钥，其余为只用于解密的旧密钥。
+
+轮换主密钥:
+  1. 执行 generate-key，把输出的新密钥插入到密钥文件（或环境变量）的第一行，保留旧密钥
+  2. 重启服务，之后写入的数据使用新密钥加密，旧数据仍可用旧密钥解密
+  3. 执行 rotate，用新密钥重新加密全部数据
+  4. 从密钥文件中删除旧密钥并重启服务`
+
+// RunSecretsCommand 执行敏感字段加密相关的命令行子命令，返回进程退出码
+func RunSecretsCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Printf(secretsUsage+"\n", filepath.Base(os.Args[0]))
+		return 2
+	}
+
+	switch args[0] {
+	case "generate-key":
+		line, err := secret.GenerateKeyLine()
+		if err != nil {
+			fmt.Printf("[ERROR] %v\n", err)
+			return 1
+		}
+		fmt.Println(line)
+		return 0
+	case "encrypt", "rotate":
+	default:
+		fmt.Printf(secretsUsage+"\n", filepath.Base(os.Args[0]))
+		return 2
+	}
+
+	global.APP_VP = core.Viper()
+	global.APP_LOG = core.Zap()
+	initializeSecretKeyring()
+
+	// 连接时会先执行表结构迁移，确保加密字段的列足够容纳密文
+	global.APP_DB = Gorm()
+	if global.APP_DB == nil {
+		fmt.Println("[ERROR] 数据库连接失败，请检查 config.yaml 中的数据库配置")
+		return 1
+	}
+
+	var (
+		stats []secret.MigrateStats
+		err   error
+	)
+	if args[0] == "rotate" {
+		stats, err = secret.Rotate(global.APP_DB)
+	} else {
+		stats, err = secret.EncryptExisting(global.APP_DB)
+	}
+
+	for _, s := range stats {
+		fmt.Printf("[SECRETS] 表 %s: 扫描 %d 行，加密明文 %d 个，重新加密 %d 个，并发修改跳过 %d 行\n",
+			s.Table, s.Rows, s.Encrypted, s.Rotated, s.Skipped)
+	}
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		return 1
+	}
+	fmt.Println("[SUCCESS] 完成")
+	return 0
+}
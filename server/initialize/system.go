@@ -2,11 +2,15 @@ package initialize
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"oneclickvirt/core"
 	"oneclickvirt/global"
+	"oneclickvirt/service/audit"
 	"oneclickvirt/service/auth"
+	"oneclickvirt/service/cache"
+	"oneclickvirt/service/leader"
 	"oneclickvirt/service/lifecycle"
 	"oneclickvirt/service/log"
 	"oneclickvirt/service/pmacct"
@@ -60,9 +64,17 @@ func InitializeSystem() {
 	global.APP_LOG.Debug("HTTP Client Manager已初始化")
 	_ = httpManager // 避免未使用警告
 
-	// 初始化LRU验证码缓存
-	global.APP_CAPTCHA_STORE = utils.NewLRUCaptchaCache(utils.MaxCaptchaItems)
-	global.APP_LOG.Debug("LRU验证码缓存初始化完成", zap.Int("capacity", utils.MaxCaptchaItems))
+	// 初始化键值存储（启用Redis时黑名单、验证码、缓存和限流计数在多实例间共享）
+	store := cache.InitStore()
+
+	// 初始化验证码存储：共享存储可用时使用共享存储，否则使用LRU内存缓存
+	if store.Shared() {
+		global.APP_CAPTCHA_STORE = cache.NewCaptchaStore(store)
+		global.APP_LOG.Debug("共享验证码存储初始化完成")
+	} else {
+		global.APP_CAPTCHA_STORE = utils.NewLRUCaptchaCache(utils.MaxCaptchaItems)
+		global.APP_LOG.Debug("LRU验证码缓存初始化完成", zap.Int("capacity", utils.MaxCaptchaItems))
+	}
 
 	// 初始化存储目录结构
 	initializeStorage()
@@ -70,6 +82,9 @@ func InitializeSystem() {
 	// 启动日志轮转定时任务
 	initializeLogRotation()
 
+	// 加载敏感字段加密主密钥（读写Provider和实例凭据前必须完成）
+	initializeSecretKeyring()
+
 	// 尝试连接数据库，但不强制要求成功
 	global.APP_DB = Gorm()
 	isSystemInitialized := CheckSystemInitialized()
@@ -309,10 +324,25 @@ func syncProvidersDataOnStartup() {
 		zap.Int("success", successCount))
 }
 
+// schedulerLeaseName 后台调度器使用的领导者租约名称
+const schedulerLeaseName = "scheduler"
+
+var (
+	schedulersInitialized bool
+	leaderStopMu          sync.Mutex
+	leaderStopFuncs       []func() // 领导者实例上运行的调度器的停止函数，按启动顺序保存
+)
+
 // initializeSchedulers 初始化调度器服务
+// 任务服务、HTTP服务在所有实例上运行；后台调度器只在选举出的领导者实例上运行，
+// 避免多实例部署时流量采集、流量超限停机、过期冻结等周期任务被重复执行
 func initializeSchedulers() {
+	schedulersInitialized = true
 	lifecycleMgr := lifecycle.GetManager()
 
+	// 注册审计日志写入器，最先注册的服务最后关闭，使关闭期间产生的审计日志也能写入
+	lifecycleMgr.Register("AuditRecorder", audit.GetRecorder())
+
 	// 初始化任务服务（只有在数据库已初始化时才创建）
 	taskService := task.GetTaskService()
 	// 设置全局任务服务实例，避免循环依赖
@@ -320,27 +350,12 @@ func initializeSchedulers() {
 	// 注册任务服务到生命周期管理器
 	lifecycleMgr.Register("TaskService", taskService)
 
-	// 启动前先同步Provider层面的数据（资源和流量统计）
-	syncProvidersDataOnStartup()
-
-	// 启动调度器服务
-	schedulerService := scheduler.NewSchedulerService(taskService)
-	global.APP_SCHEDULER = schedulerService
-	schedulerService.StartScheduler()
-	lifecycleMgr.Register("SchedulerService", schedulerService)
-
-	// 启动监控调度器（使用全局shutdown context确保可以正确关闭）
-	pmacctService := pmacct.NewService()
-	monitoringSchedulerService := scheduler.NewMonitoringSchedulerService(pmacctService)
-	global.APP_MONITORING_SCHEDULER = monitoringSchedulerService
-	monitoringSchedulerService.Start(global.APP_SHUTDOWN_CONTEXT)
-	lifecycleMgr.Register("MonitoringScheduler", monitoringSchedulerService)
-
-	// 启动Provider健康检查调度器（使用全局shutdown context确保可以正确关闭）
-	providerHealthSchedulerService := scheduler.NewProviderHealthSchedulerService()
-	global.APP_PROVIDER_HEALTH_SCHEDULER = providerHealthSchedulerService
-	providerHealthSchedulerService.Start(global.APP_SHUTDOWN_CONTEXT)
-	lifecycleMgr.Register("ProviderHealthScheduler", providerHealthSchedulerService)
+	// 启动领导者选举，成为领导者后再启动各调度器
+	elector := leader.NewElector(schedulerLeaseName,
+		func(ctx context.Context) { startLeaderServices(ctx, taskService) },
+		stopLeaderServices)
+	elector.Start(global.APP_SHUTDOWN_CONTEXT)
+	lifecycleMgr.Register("LeaderElector", elector)
 
 	// 注册pmacct批处理器
 	pmacctBatchProcessor := pmacct.GetBatchProcessor()
@@ -366,6 +381,9 @@ func initializeSchedulers() {
 	jwtBlacklistService := auth.GetJWTBlacklistService()
 	lifecycleMgr.Register("JWTBlacklistService", jwtBlacklistService)
 
+	// 注册键值存储
+	lifecycleMgr.Register("CacheStore", cache.GetStore())
+
 	// 注册验证码缓存
 	if global.APP_CAPTCHA_STORE != nil {
 		lifecycleMgr.Register("CaptchaCache", global.APP_CAPTCHA_STORE)
@@ -386,6 +404,85 @@ func initializeSchedulers() {
 	global.APP_LOG.Info("所有调度器和全局服务已启动并注册到生命周期管理器")
 }
 
+// startLeaderServices 成为领导者后启动后台调度器
+// 调度器停止后无法再次启动，因此每次当选都创建新的实例
+func startLeaderServices(ctx context.Context, taskService *task.TaskService) {
+	leaderStopMu.Lock()
+	defer leaderStopMu.Unlock()
+
+	// 已停止的实例中断的任务由领导者统一标记为失败（之后由调度器定期检查）
+	taskService.CleanupInterruptedTasks()
+
+	// 启动前先同步Provider层面的数据（资源和流量统计）
+	syncProvidersDataOnStartup()
+
+	// 启动调度器服务
+	schedulerService := scheduler.NewSchedulerService(taskService)
+	global.APP_SCHEDULER = schedulerService
+	schedulerService.StartScheduler()
+
+	// 启动监控调度器（ctx在失去领导权或系统关闭时取消）
+	pmacctService := pmacct.NewService()
+	monitoringSchedulerService := scheduler.NewMonitoringSchedulerService(pmacctService)
+	global.APP_MONITORING_SCHEDULER = monitoringSchedulerService
+	monitoringSchedulerService.Start(ctx)
+
+	// 启动Provider健康检查调度器
+	providerHealthSchedulerService := scheduler.NewProviderHealthSchedulerService()
+	global.APP_PROVIDER_HEALTH_SCHEDULER = providerHealthSchedulerService
+	providerHealthSchedulerService.Start(ctx)
+
+	// 启动定时备份调度器
+	backupSchedulerService := scheduler.NewBackupSchedulerService()
+	backupSchedulerService.Start(ctx)
+
+	// 启动Webhook重试调度器
+	webhookSchedulerService := scheduler.NewWebhookSchedulerService()
+	webhookSchedulerService.Start(ctx)
+
+	// 启动实例计费调度器
+	billingSchedulerService := scheduler.NewBillingSchedulerService()
+	billingSchedulerService.Start(ctx)
+
+	// 启动到期提醒调度器
+	notifySchedulerService := scheduler.NewNotifySchedulerService()
+	notifySchedulerService.Start(ctx)
+
+	// 启动实例资源采集调度器
+	metricsSchedulerService := scheduler.NewMetricsSchedulerService()
+	metricsSchedulerService.Start(ctx)
+
+	leaderStopFuncs = []func(){
+		schedulerService.StopScheduler,
+		monitoringSchedulerService.Stop,
+		providerHealthSchedulerService.Stop,
+		backupSchedulerService.Stop,
+		webhookSchedulerService.Stop,
+		billingSchedulerService.Stop,
+		notifySchedulerService.Stop,
+		metricsSchedulerService.Stop,
+	}
+
+	global.APP_LOG.Info("领导者后台调度器已启动", zap.Int("count", len(leaderStopFuncs)))
+}
+
+// stopLeaderServices 失去领导权（或系统关闭）时按启动的逆序停止后台调度器
+func stopLeaderServices() {
+	leaderStopMu.Lock()
+	defer leaderStopMu.Unlock()
+
+	for i := len(leaderStopFuncs) - 1; i >= 0; i-- {
+		leaderStopFuncs[i]()
+	}
+	leaderStopFuncs = nil
+
+	global.APP_SCHEDULER = nil
+	global.APP_MONITORING_SCHEDULER = nil
+	global.APP_PROVIDER_HEALTH_SCHEDULER = nil
+
+	global.APP_LOG.Info("领导者后台调度器已停止")
+}
+
 // InitializePostSystemInit 系统初始化完成后的完整初始化
 func InitializePostSystemInit() {
 	// 重新初始化数据库连接（确保使用最新配置）
@@ -406,7 +503,7 @@ func InitializePostSystemInit() {
 	initializeJWTService()
 
 	// 初始化任务服务（如果还未初始化）
-	if global.APP_SCHEDULER == nil {
+	if !schedulersInitialized {
 		initializeSchedulers()
 	}
 }
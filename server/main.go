@@ -42,6 +42,16 @@ func main() {
 	// 确保从正确的工作目录运行
 	ensureCorrectWorkingDirectory()
 
+	// 凭据加密迁移和主密钥轮换子命令
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		os.Exit(initialize.RunSecretsCommand(os.Args[2:]))
+	}
+
+	// 数据库结构迁移子命令
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(initialize.RunMigrateCommand(os.Args[2:]))
+	}
+
 	// 设置系统初始化完成后的回调函数
 	initialize.SetSystemInitCallback()
 
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/service/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// auditMaxBodySize 审计记录读取的最大请求体
+	auditMaxBodySize = 64 << 10
+	// auditMaxResponseSize 审计记录捕获的最大响应体，只用于解析响应码和消息
+	auditMaxResponseSize = 4 << 10
+)
+
+// auditResponseWriter 捕获响应体开头部分
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if remaining := auditMaxResponseSize - w.body.Len(); remaining > 0 {
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		w.body.Write(data[:remaining])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	if remaining := auditMaxResponseSize - w.body.Len(); remaining > 0 {
+		if len(s) < remaining {
+			remaining = len(s)
+		}
+		w.body.WriteString(s[:remaining])
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// AuditLog 审计日志中间件，记录已认证用户的写操作，需放在 RequireAuth 之后
+func AuditLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || !global.APP_CONFIG.Audit.Enabled {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		contentType := c.ContentType()
+
+		// 文件上传只记录大小，不读取内容
+		var body []byte
+		if c.Request.Body != nil && !strings.HasPrefix(contentType, "multipart/") &&
+			c.Request.ContentLength >= 0 && c.Request.ContentLength <= auditMaxBodySize {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, auditMaxBodySize))
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		entry := &adminModel.AuditLog{
+			CreatedAt:  start,
+			Method:     method,
+			Path:       audit.Truncate(c.Request.URL.Path, 255),
+			StatusCode: c.Writer.Status(),
+			Latency:    time.Since(start).Milliseconds(),
+			ClientIP:   c.ClientIP(),
+			UserAgent:  audit.Truncate(c.Request.UserAgent(), 255),
+		}
+		if authCtx, ok := GetAuthContext(c); ok {
+			userID := authCtx.UserID
+			entry.UserID = &userID
+			entry.Username = authCtx.Username
+			entry.UserType = authCtx.UserType
+			entry.APITokenID = authCtx.APITokenID
+		}
+
+		fullPath := c.FullPath()
+		if fullPath == "" {
+			fullPath = c.Request.URL.Path
+		}
+		entry.Resource, entry.Action = audit.ParseRoute(method, fullPath)
+		if len(c.Params) > 0 {
+			entry.ResourceID = audit.Truncate(c.Params[0].Value, 64)
+		}
+
+		if len(body) > 0 {
+			entry.Request = audit.SummarizeRequest(c.Request.URL.RawQuery, contentType, body)
+		} else {
+			entry.Request = audit.SummarizeRequest(c.Request.URL.RawQuery, "", nil)
+			if c.Request.ContentLength > 0 {
+				entry.Request = strings.TrimSpace(entry.Request + "\nbody: [not recorded]")
+			}
+		}
+
+		entry.Success, entry.Response = audit.ParseResponse(entry.StatusCode, writer.body.Bytes())
+		audit.Record(entry)
+	}
+}
@@ -36,6 +36,18 @@ func GetUserIDFromContext(c *gin.Context) (uint, error) {
 	return authCtx.UserID, nil
 }
 
+// apiTokenAllowedKey 标记当前路由组允许使用API令牌认证
+const apiTokenAllowedKey = "api_token_allowed"
+
+// AllowAPIToken 允许路由组使用API令牌认证，需放在 RequireAuth 之前
+// 未启用的路由组（管理员、配置、Provider等）只接受浏览器会话的JWT
+func AllowAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiTokenAllowedKey, true)
+		c.Next()
+	}
+}
+
 // RequireAuth 统一的认证中间件
 func RequireAuth(minLevel auth.AuthLevel) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -45,8 +57,15 @@ func RequireAuth(minLevel auth.AuthLevel) gin.HandlerFunc {
 			return
 		}
 
-		// 验证JWT Token并获取最新权限
-		authCtx, claims, err := validateJWTTokenWithClaims(c)
+		// API令牌与JWT共用认证头，按前缀区分
+		var authCtx *auth.AuthContext
+		var claims *jwt.MapClaims
+		var err error
+		if token := extractToken(c); auth2.IsAPIToken(token) {
+			authCtx, err = validateAPIToken(c, token)
+		} else {
+			authCtx, claims, err = validateJWTTokenWithClaims(c)
+		}
 		if err != nil {
 			respondAuthError(c, err)
 			return
@@ -72,8 +91,8 @@ func RequireAuth(minLevel auth.AuthLevel) gin.HandlerFunc {
 			return
 		}
 
-		// 检查token是否需要刷新（滑动过期机制）
-		if utils.ShouldRefreshToken(claims) {
+		// 检查token是否需要刷新（滑动过期机制，API令牌不刷新）
+		if claims != nil && utils.ShouldRefreshToken(claims) {
 			// 生成新token
 			newToken, err := utils.GenerateToken(authCtx.UserID, authCtx.Username, authCtx.UserType)
 			if err != nil {
@@ -145,8 +164,8 @@ func RequireResourcePermission(resource string) gin.HandlerFunc {
 	}
 }
 
-// validateJWTTokenWithClaims 验证JWT Token并获取最新用户权限（返回claims用于刷新检查）
-func validateJWTTokenWithClaims(c *gin.Context) (*auth.AuthContext, *jwt.MapClaims, error) {
+// extractToken 获取请求携带的认证令牌
+func extractToken(c *gin.Context) string {
 	// 优先从 Authorization 头获取token
 	token := c.GetHeader("Authorization")
 	if token == "" {
@@ -154,13 +173,66 @@ func validateJWTTokenWithClaims(c *gin.Context) (*auth.AuthContext, *jwt.MapClai
 		token = c.Query("token")
 	}
 
-	if token == "" {
-		return nil, nil, common.NewError(common.CodeUnauthorized, "未提供认证令牌")
-	}
-
 	if after, ok := strings.CutPrefix(token, "Bearer "); ok {
 		token = after
 	}
+	return token
+}
+
+// validateAPIToken 验证API令牌，权限级别与令牌所属用户一致，并按令牌权限范围限制可访问的接口
+func validateAPIToken(c *gin.Context, token string) (*auth.AuthContext, error) {
+	if !c.GetBool(apiTokenAllowedKey) {
+		return nil, common.NewError(common.CodeForbidden, "该接口不支持使用API令牌")
+	}
+
+	apiTokenService := auth2.APITokenService{}
+	apiToken, err := apiTokenService.Authenticate(token, c.ClientIP())
+	if err != nil {
+		return nil, err
+	}
+
+	// 按匹配到的路由模板判断权限范围，避免请求路径中的参数影响匹配
+	route := strings.TrimPrefix(c.FullPath(), "/api/v1")
+	if err := apiTokenService.CheckScope(apiToken, c.Request.Method, route); err != nil {
+		global.APP_LOG.Debug("API令牌权限范围不足",
+			zap.Uint("tokenID", apiToken.ID),
+			zap.Uint("userID", apiToken.UserID),
+			zap.String("scopes", apiToken.Scopes),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("method", c.Request.Method))
+		return nil, err
+	}
+
+	userAuth, err := getUserAuthInfo(apiToken.UserID)
+	if err != nil {
+		return nil, common.NewError(common.CodeUnauthorized, "获取用户权限失败")
+	}
+	userAuth.APITokenID = apiToken.ID
+	userAuth.APITokenScopes = apiToken.ScopeList()
+	return userAuth, nil
+}
+
+// CanAccessInstanceCredentials 当前请求能否读取实例登录凭据
+// 浏览器会话不受限制；API令牌必须拥有 instances 范围，只读令牌不能取得实例密码
+func CanAccessInstanceCredentials(c *gin.Context) bool {
+	authCtx, exists := GetAuthContext(c)
+	if !exists || authCtx.APITokenID == 0 {
+		return exists
+	}
+	for _, scope := range authCtx.APITokenScopes {
+		if scope == user.APITokenScopeInstances {
+			return true
+		}
+	}
+	return false
+}
+
+// validateJWTTokenWithClaims 验证JWT Token并获取最新用户权限（返回claims用于刷新检查）
+func validateJWTTokenWithClaims(c *gin.Context) (*auth.AuthContext, *jwt.MapClaims, error) {
+	token := extractToken(c)
+	if token == "" {
+		return nil, nil, common.NewError(common.CodeUnauthorized, "未提供认证令牌")
+	}
 
 	// 使用JWT验证逻辑
 	claims, err := utils.ValidateToken(token)
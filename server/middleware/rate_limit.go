@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	"oneclickvirt/service/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IPRateLimit 按客户端IP限流（system.iplimit-count 次 / system.iplimit-time 秒）
+// 需通过 system.iplimit-enabled 显式启用，计数保存在共享存储中，多实例部署时共用同一限额
+func IPRateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := global.APP_CONFIG.System
+		if !cfg.EnableIPLimit || cfg.LimitCountIP <= 0 || cfg.LimitTimeIP <= 0 {
+			c.Next()
+			return
+		}
+
+		clientIP := rateLimitClientIP(c.RemoteIP(), c.GetHeader("X-Forwarded-For"), cfg.TrustedProxies)
+		key := fmt.Sprintf("ratelimit:ip:%s", clientIP)
+		count, err := cache.GetStore().Incr(key, time.Duration(cfg.LimitTimeIP)*time.Second)
+		if err != nil {
+			// 存储不可用时不阻断正常请求
+			global.APP_LOG.Warn("IP限流计数失败", zap.String("ip", clientIP), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if count > int64(cfg.LimitCountIP) {
+			c.Header("Retry-After", strconv.Itoa(cfg.LimitTimeIP))
+			common.ResponseWithError(c, common.NewError(common.CodeTooManyRequests))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitClientIP 获取用于限流的客户端IP
+// 路由为兼容各种部署方式信任所有代理，c.ClientIP() 可被伪造的 X-Forwarded-For 操纵，
+// 这里只在直连地址属于可信代理时才读取 X-Forwarded-For，并从右向左跳过可信代理，取第一个不可信的地址
+func rateLimitClientIP(remoteIP, forwardedFor string, trustedProxies []string) string {
+	trusted := parseTrustedProxies(trustedProxies)
+	if !ipTrusted(remoteIP, trusted) || forwardedFor == "" {
+		return remoteIP
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// 格式错误的地址无法继续向前追溯，使用已确认的最近一跳地址
+			return remoteIP
+		}
+		if !ipTrusted(hop, trusted) {
+			return hop
+		}
+		remoteIP = hop
+	}
+	return remoteIP
+}
+
+// parseTrustedProxies 解析可信代理列表，支持单个IP和CIDR
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	return nets
+}
+
+// ipTrusted 判断地址是否属于可信代理
+func ipTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range trusted {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
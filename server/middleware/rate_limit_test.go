@@ -0,0 +1,26 @@
+package middleware
+
+import "testing"
+
+func TestRateLimitClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "192.168.1.1"}
+
+	cases := []struct {
+		name, remote, xff, want string
+	}{
+		{"未配置可信代理时忽略X-Forwarded-For", "203.0.113.5", "1.2.3.4", "203.0.113.5"},
+		{"经可信代理转发时取真实客户端", "10.0.0.2", "198.51.100.7", "198.51.100.7"},
+		{"多级代理时跳过可信代理", "10.0.0.2", "198.51.100.7, 192.168.1.1", "198.51.100.7"},
+		{"客户端伪造的前缀被忽略", "10.0.0.2", "1.2.3.4, 198.51.100.7", "198.51.100.7"},
+		{"格式错误时使用直连的可信代理地址", "10.0.0.2", "garbage", "10.0.0.2"},
+	}
+	for _, tc := range cases {
+		proxies := trusted
+		if tc.remote == "203.0.113.5" {
+			proxies = nil
+		}
+		if got := rateLimitClientIP(tc.remote, tc.xff, proxies); got != tc.want {
+			t.Errorf("%s: 得到 %s，期望 %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -19,7 +19,7 @@ type Task struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                                                                         // 软删除时间
 
 	// 任务基本信息
-	TaskType string `json:"taskType" gorm:"not null;size:32"`                                                                               // 任务类型：create, start, stop, restart, reset, delete, reset-password
+	TaskType string `json:"taskType" gorm:"not null;size:32"`                                                                               // 任务类型：create, start, stop, restart, reset, delete, reset-password, create-snapshot, restore-snapshot, delete-snapshot, backup, restore, migrate
 	Status   string `json:"status" gorm:"default:pending;size:32;index:idx_status_created,priority:1;index:idx_provider_status,priority:2"` // 任务状态：pending, processing, running, completed, failed, cancelling, cancelled, timeout
 	Progress int    `json:"progress" gorm:"default:0"`                                                                                      // 任务执行进度百分比（0-100）
 
@@ -42,9 +42,10 @@ type Task struct {
 	PreallocatedBandwidth int `json:"preallocatedBandwidth" gorm:"default:0"` // 预分配的带宽(Mbps)
 
 	// 关联信息
-	UserID     uint  `json:"userId" gorm:"index:idx_user_created,priority:1;index:idx_user_status,priority:1"` // 任务所属用户ID
-	ProviderID *uint `json:"providerId" gorm:"index:idx_provider_status,priority:1"`                           // 执行任务的Provider ID（可为空）
-	InstanceID *uint `json:"instanceId"`                                                                       // 关联的实例ID（可选，用于实例相关任务）
+	UserID     uint   `json:"userId" gorm:"index:idx_user_created,priority:1;index:idx_user_status,priority:1"` // 任务所属用户ID
+	ProviderID *uint  `json:"providerId" gorm:"index:idx_provider_status,priority:1"`                           // 执行任务的Provider ID（可为空）
+	InstanceID *uint  `json:"instanceId"`                                                                       // 关联的实例ID（可选，用于实例相关任务）
+	HolderID   string `json:"holderId" gorm:"size:128;index"`                                                   // 执行任务的面板实例标识（running状态时有效）
 
 	// 关联对象
 	Provider *providerModel.Provider `json:"provider,omitempty" gorm:"foreignKey:ProviderID"` // 关联的Provider对象
@@ -62,19 +63,25 @@ func (t *Task) BeforeCreate(tx *gorm.DB) error {
 // AuditLog 审计日志模型
 type AuditLog struct {
 	ID         uint           `json:"id" gorm:"primarykey"`
-	CreatedAt  time.Time      `json:"createdAt"`
+	CreatedAt  time.Time      `json:"createdAt" gorm:"index"`
 	UpdatedAt  time.Time      `json:"updatedAt"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"` // 软删除字段
-	UserID     *uint          `json:"userId"`         // 改为可空，未登录用户可能没有UserID
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`      // 软删除字段
+	UserID     *uint          `json:"userId" gorm:"index"` // 改为可空，未登录用户可能没有UserID
 	Username   string         `json:"username" gorm:"size:64"`
+	UserType   string         `json:"userType" gorm:"size:16"`     // 操作者角色：admin, user
+	APITokenID uint           `json:"apiTokenId" gorm:"default:0"` // 通过API令牌操作时的令牌ID
 	Method     string         `json:"method" gorm:"size:16"`
 	Path       string         `json:"path" gorm:"size:255"`
+	Action     string         `json:"action" gorm:"size:64;index"`   // 操作，如 create、update、delete、login、reset-password
+	Resource   string         `json:"resource" gorm:"size:64;index"` // 目标资源类型，如 users、instances
+	ResourceID string         `json:"resourceId" gorm:"size:64"`     // 目标资源ID
+	Success    bool           `json:"success" gorm:"index"`          // 操作是否成功
 	StatusCode int            `json:"statusCode"`
-	Latency    int64          `json:"latency"`
+	Latency    int64          `json:"latency"` // 耗时（毫秒）
 	ClientIP   string         `json:"clientIP" gorm:"size:64"`
 	UserAgent  string         `json:"userAgent" gorm:"size:255"`
-	Request    string         `json:"request" gorm:"type:text"`
-	Response   string         `json:"response" gorm:"type:text"`
+	Request    string         `json:"request" gorm:"type:text"`  // 请求摘要，敏感字段已脱敏
+	Response   string         `json:"response" gorm:"type:text"` // 响应消息
 }
 
 // SystemConfig 系统配置模型
@@ -31,7 +31,7 @@ type ConfigurationTask struct {
 	ResultData   string `json:"resultData" gorm:"type:text"` // JSON格式存储结果数据
 
 	// 日志相关
-	LogOutput  string `json:"logOutput" gorm:"type:longtext"` // 完整的执行日志
+	LogOutput  string `json:"logOutput" gorm:"size:16777217"` // 完整的执行日志（MySQL为longtext）
 	LogSummary string `json:"logSummary" gorm:"type:text"`    // 日志摘要
 
 	// 关联信息
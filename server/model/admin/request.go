@@ -260,6 +260,11 @@ type UnfreezeProviderRequest struct {
 	ExpiresAt string `json:"expiresAt"` // 新的过期时间，格式: "2006-01-02 15:04:05"
 }
 
+// AcceptProviderHostKeyRequest 确认Provider新的SSH主机密钥，指纹需与检测到的待确认密钥一致
+type AcceptProviderHostKeyRequest struct {
+	Fingerprint string `json:"fingerprint" binding:"required"`
+}
+
 // TestSSHConnectionRequest 测试SSH连接请求
 type TestSSHConnectionRequest struct {
 	Host      string `json:"host" binding:"required"`     // SSH服务器地址
@@ -322,7 +327,8 @@ type InstanceListRequest struct {
 }
 
 type InstanceActionRequest struct {
-	Action string `json:"action" binding:"required"`
+	Action  string `json:"action" binding:"required"`
+	ImageID uint   `json:"imageId"` // 重置时可选的目标系统镜像ID，为空时使用原镜像
 }
 
 // ResetInstancePasswordRequest 管理员重置实例密码请求
@@ -474,7 +480,8 @@ type CreateInstanceTaskRequest struct {
 	DiskId      string `json:"diskId"`
 	BandwidthId string `json:"bandwidthId"`
 	Description string `json:"description"`
-	SessionId   string `json:"sessionId"` // 会话ID，用于新的资源预留机制
+	SessionId   string `json:"sessionId"`          // 会话ID，用于新的资源预留机制
+	UserData    string `json:"userData,omitempty"` // 用户提供的cloud-init user-data或启动脚本，重置实例时重新应用
 }
 
 // InstanceOperationTaskRequest 实例操作任务数据结构（启动、停止、重启、重置）
@@ -483,6 +490,14 @@ type InstanceOperationTaskRequest struct {
 	ProviderId uint `json:"providerId"`
 }
 
+// ResetInstanceTaskRequest 重置实例任务数据结构
+type ResetInstanceTaskRequest struct {
+	InstanceId     uint   `json:"instanceId"`
+	ProviderId     uint   `json:"providerId"`
+	OriginalStatus string `json:"originalStatus"`    // 实例重置前的原始状态
+	ImageId        uint   `json:"imageId,omitempty"` // 目标系统镜像ID，为0时使用原镜像重装
+}
+
 // DeleteInstanceTaskRequest 删除实例任务数据结构
 type DeleteInstanceTaskRequest struct {
 	InstanceId     uint `json:"instanceId"`
@@ -517,6 +532,94 @@ type DeletePortMappingTaskRequest struct {
 	ProviderID uint `json:"providerId"` // Provider ID
 }
 
+// SnapshotTaskRequest 快照任务数据结构（创建、恢复、删除共用）
+type SnapshotTaskRequest struct {
+	SnapshotID uint `json:"snapshotId"` // 快照ID
+	InstanceID uint `json:"instanceId"` // 实例ID
+	ProviderID uint `json:"providerId"` // Provider ID
+}
+
+// SnapshotListRequest 快照列表请求
+type SnapshotListRequest struct {
+	common.PageInfo
+	InstanceID uint   `json:"instanceId" form:"instanceId"`
+	ProviderID uint   `json:"providerId" form:"providerId"`
+	UserID     uint   `json:"userId" form:"userId"`
+	Status     string `json:"status" form:"status"`
+}
+
+// CreateSnapshotRequest 管理员创建快照请求
+type CreateSnapshotRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=40"`         // 快照名称，为空时自动生成
+	Description string `json:"description" binding:"omitempty,max=255"` // 快照描述
+}
+
+// BackupTaskRequest 备份任务数据结构
+type BackupTaskRequest struct {
+	BackupID   uint `json:"backupId"`   // 备份ID
+	InstanceID uint `json:"instanceId"` // 实例ID
+	ProviderID uint `json:"providerId"` // Provider ID
+}
+
+// RestoreBackupTaskRequest 恢复备份任务数据结构
+type RestoreBackupTaskRequest struct {
+	BackupID         uint   `json:"backupId"`         // 备份ID
+	TargetInstanceID uint   `json:"targetInstanceId"` // 恢复目标实例ID
+	ProviderID       uint   `json:"providerId"`       // 目标实例所在Provider ID
+	OriginalStatus   string `json:"originalStatus"`   // 目标实例恢复前的状态
+}
+
+// BackupListRequest 备份列表请求
+type BackupListRequest struct {
+	common.PageInfo
+	InstanceID uint   `json:"instanceId" form:"instanceId"`
+	ProviderID uint   `json:"providerId" form:"providerId"`
+	UserID     uint   `json:"userId" form:"userId"`
+	Status     string `json:"status" form:"status"`
+}
+
+// RestoreBackupRequest 恢复备份请求
+type RestoreBackupRequest struct {
+	TargetInstanceID uint `json:"targetInstanceId" binding:"required"` // 恢复目标实例ID，可以是源实例或同类型的其他实例
+}
+
+// BackupScheduleRequest 设置定时备份请求
+type BackupScheduleRequest struct {
+	Enabled       bool `json:"enabled"`                                        // 是否启用
+	IntervalHours int  `json:"intervalHours" binding:"required,min=1,max=720"` // 备份间隔（小时）
+}
+
+// MigrateInstanceRequest 管理员迁移实例请求
+type MigrateInstanceRequest struct {
+	TargetProviderID uint `json:"targetProviderId" binding:"required"` // 目标Provider ID，类型需与源Provider一致
+}
+
+// MigrateInstanceTaskRequest 迁移实例任务数据结构
+type MigrateInstanceTaskRequest struct {
+	InstanceID       uint   `json:"instanceId"`       // 实例ID
+	SourceProviderID uint   `json:"sourceProviderId"` // 源Provider ID
+	TargetProviderID uint   `json:"targetProviderId"` // 目标Provider ID
+	OriginalStatus   string `json:"originalStatus"`   // 迁移前的实例状态
+}
+
+// ResizeInstanceTaskRequest 调整实例规格任务数据结构
+type ResizeInstanceTaskRequest struct {
+	InstanceID     uint   `json:"instanceId"`     // 实例ID
+	ProviderID     uint   `json:"providerId"`     // Provider ID
+	OldCPU         int    `json:"oldCpu"`         // 调整前CPU核心数
+	OldMemory      int64  `json:"oldMemory"`      // 调整前内存（MB）
+	OldDisk        int64  `json:"oldDisk"`        // 调整前磁盘（MB）
+	NewCPU         int    `json:"newCpu"`         // 调整后CPU核心数
+	NewMemory      int64  `json:"newMemory"`      // 调整后内存（MB）
+	NewDisk        int64  `json:"newDisk"`        // 调整后磁盘（MB）
+	OriginalStatus string `json:"originalStatus"` // 调整前的实例状态
+}
+
+// ApplyFirewallTaskRequest 应用实例防火墙任务数据结构
+type ApplyFirewallTaskRequest struct {
+	InstanceID uint `json:"instanceId"` // 实例ID，未绑定安全组时移除实例防火墙
+}
+
 // CheckPortAvailabilityRequest 检查端口可用性请求
 type CheckPortAvailabilityRequest struct {
 	ProviderID uint   `json:"providerId" binding:"required"`                  // Provider ID
@@ -534,3 +637,158 @@ type CheckPortAvailabilityResponse struct {
 	PortRange        string `json:"portRange"`        // 端口范围描述（如 "10000-10009"）
 	Suggestion       string `json:"suggestion"`       // 建议（如果有冲突，提供替代方案）
 }
+
+// SecurityGroupListRequest 安全组列表请求
+type SecurityGroupListRequest struct {
+	common.PageInfo
+	UserID uint `json:"userId" form:"userId"`
+}
+
+// AuditLogListRequest 审计日志列表请求，Keyword按请求路径模糊匹配
+type AuditLogListRequest struct {
+	common.PageInfo
+	UserID     uint   `json:"userId" form:"userId"`
+	Username   string `json:"username" form:"username"`
+	UserType   string `json:"userType" form:"userType"`
+	Resource   string `json:"resource" form:"resource"`
+	ResourceID string `json:"resourceId" form:"resourceId"`
+	Action     string `json:"action" form:"action"`
+	Method     string `json:"method" form:"method"`
+	Success    *bool  `json:"success" form:"success"`
+	StartTime  string `json:"startTime" form:"startTime"`
+	EndTime    string `json:"endTime" form:"endTime"`
+}
+
+// DomainListRequest 域名绑定列表请求
+type DomainListRequest struct {
+	common.PageInfo
+	UserID     uint   `json:"userId" form:"userId"`
+	ProviderID uint   `json:"providerId" form:"providerId"`
+	Status     string `json:"status" form:"status"`
+}
+
+// WebhookListRequest Webhook列表请求
+type WebhookListRequest struct {
+	common.PageInfo
+	Enabled *bool `json:"enabled" form:"enabled"`
+}
+
+// CreateWebhookRequest 创建Webhook请求
+type CreateWebhookRequest struct {
+	Name        string   `json:"name" binding:"required,max=64"`               // 名称
+	URL         string   `json:"url" binding:"required,url,max=512"`           // 接收地址
+	Secret      string   `json:"secret" binding:"max=128"`                     // HMAC签名密钥，为空时自动生成
+	Events      []string `json:"events" binding:"required,min=1"`              // 订阅的事件，* 表示全部
+	Enabled     bool     `json:"enabled"`                                      // 是否启用
+	Description string   `json:"description" binding:"max=255"`                // 描述
+	MaxAttempts int      `json:"maxAttempts" binding:"omitempty,min=1,max=20"` // 最大投递次数，默认8
+}
+
+// UpdateWebhookRequest 更新Webhook请求
+type UpdateWebhookRequest struct {
+	Name        string   `json:"name" binding:"required,max=64"`               // 名称
+	URL         string   `json:"url" binding:"required,url,max=512"`           // 接收地址
+	Secret      string   `json:"secret" binding:"max=128"`                     // HMAC签名密钥，为空时保持不变
+	Events      []string `json:"events" binding:"required,min=1"`              // 订阅的事件，* 表示全部
+	Enabled     bool     `json:"enabled"`                                      // 是否启用
+	Description string   `json:"description" binding:"max=255"`                // 描述
+	MaxAttempts int      `json:"maxAttempts" binding:"omitempty,min=1,max=20"` // 最大投递次数，默认8
+}
+
+// WebhookDeliveryListRequest Webhook投递记录列表请求
+type WebhookDeliveryListRequest struct {
+	common.PageInfo
+	WebhookID uint   `json:"webhookId" form:"webhookId"`
+	Event     string `json:"event" form:"event"`
+	Status    string `json:"status" form:"status"`
+}
+
+// IPPoolListRequest 地址池列表请求
+type IPPoolListRequest struct {
+	common.PageInfo
+	ProviderID uint   `json:"providerId" form:"providerId"`
+	Family     string `json:"family" form:"family"`
+}
+
+// CreateIPPoolRequest 创建地址池请求
+type CreateIPPoolRequest struct {
+	ProviderID  uint     `json:"providerId" binding:"required"`                    // 所属Provider ID
+	Name        string   `json:"name" binding:"required,max=64"`                   // 地址池名称
+	CIDR        string   `json:"cidr" binding:"required,max=64"`                   // 网段，如 203.0.113.0/24
+	Gateway     string   `json:"gateway" binding:"max=64"`                         // 网关地址
+	Addresses   []string `json:"addresses"`                                        // 可分配的地址列表，为空时使用网段内全部可用地址
+	Status      string   `json:"status" binding:"omitempty,oneof=active disabled"` // 状态，默认active
+	Description string   `json:"description" binding:"max=255"`                    // 描述
+}
+
+// UpdateIPPoolRequest 更新地址池请求
+type UpdateIPPoolRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`                  // 地址池名称
+	Gateway     string `json:"gateway" binding:"max=64"`                        // 网关地址
+	Status      string `json:"status" binding:"required,oneof=active disabled"` // 状态
+	Description string `json:"description" binding:"max=255"`                   // 描述
+}
+
+// IPAddressListRequest 地址池地址列表请求
+type IPAddressListRequest struct {
+	common.PageInfo
+	Status string `json:"status" form:"status"`
+}
+
+// UpdateIPAddressRequest 更新地址状态请求（保留或取消保留）
+type UpdateIPAddressRequest struct {
+	Status string `json:"status" binding:"required,oneof=free reserved"`
+}
+
+// BillingPriceListRequest 价格规则列表请求
+type BillingPriceListRequest struct {
+	common.PageInfo
+	ProviderID   uint   `json:"providerId" form:"providerId"`
+	InstanceType string `json:"instanceType" form:"instanceType"`
+	Enabled      *bool  `json:"enabled" form:"enabled"`
+}
+
+// BillingPriceRequest 创建或更新价格规则请求，金额单位为分
+type BillingPriceRequest struct {
+	Name         string `json:"name" binding:"required,max=64"`                      // 规则名称
+	ProviderID   uint   `json:"providerId"`                                          // 适用的Provider，0表示全部
+	InstanceType string `json:"instanceType" binding:"omitempty,oneof=container vm"` // 适用的实例类型，为空表示全部
+	CPU          int    `json:"cpu" binding:"min=0"`                                 // 适用的CPU核心数，0表示不限
+	Memory       int64  `json:"memory" binding:"min=0"`                              // 适用的内存（MB），0表示不限
+	Disk         int64  `json:"disk" binding:"min=0"`                                // 适用的磁盘（MB），0表示不限
+	HourlyPrice  int64  `json:"hourlyPrice" binding:"min=0"`                         // 小时价格（分）
+	MonthlyPrice int64  `json:"monthlyPrice" binding:"min=0"`                        // 月价格（分）
+	Enabled      bool   `json:"enabled"`                                             // 是否启用
+	Description  string `json:"description" binding:"max=255"`                       // 说明
+}
+
+// BalanceAdjustRequest 管理员变更用户余额请求，金额单位为分
+type BalanceAdjustRequest struct {
+	Type        string `json:"type" binding:"required,oneof=recharge adjust refund"` // 类型：recharge充值, adjust调整, refund退款
+	Amount      int64  `json:"amount" binding:"required"`                            // 金额（分），充值和退款必须为正数，调整可为负数
+	Description string `json:"description" binding:"max=255"`                        // 说明
+}
+
+// BalanceTransactionListRequest 余额流水列表请求
+type BalanceTransactionListRequest struct {
+	common.PageInfo
+	UserID     uint   `json:"userId" form:"userId"`
+	InstanceID uint   `json:"instanceId" form:"instanceId"`
+	Type       string `json:"type" form:"type"`
+}
+
+// NotificationTemplateRequest 更新通知模板请求，使用Go text/template语法
+type NotificationTemplateRequest struct {
+	Subject string `json:"subject" binding:"required,max=255"` // 标题模板
+	Body    string `json:"body" binding:"required"`            // 正文模板
+	Enabled bool   `json:"enabled"`                            // 是否发送该事件的通知
+}
+
+// NotificationLogListRequest 通知发送记录列表请求
+type NotificationLogListRequest struct {
+	common.PageInfo
+	UserID  uint   `json:"userId" form:"userId"`
+	Event   string `json:"event" form:"event"`
+	Channel string `json:"channel" form:"channel"`
+	Status  string `json:"status" form:"status"`
+}
@@ -160,6 +160,11 @@ type ResetInstancePasswordResponse struct {
 	TaskID uint `json:"taskId"` // 异步任务ID
 }
 
+// MigrateInstanceResponse 管理员迁移实例响应
+type MigrateInstanceResponse struct {
+	TaskID uint `json:"taskId"` // 异步任务ID
+}
+
 // GetInstancePasswordResponse 获取实例新密码响应
 type GetInstancePasswordResponse struct {
 	NewPassword string `json:"newPassword"`
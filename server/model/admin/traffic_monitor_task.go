@@ -22,7 +22,7 @@ type TrafficMonitorTask struct {
 	TotalCount   int            `gorm:"default:0" json:"totalCount"`                               // 总实例数
 	SuccessCount int            `gorm:"default:0" json:"successCount"`                             // 成功数量
 	FailedCount  int            `gorm:"default:0" json:"failedCount"`                              // 失败数量
-	Output       string         `gorm:"type:longtext" json:"output"`                               // 详细输出日志
+	Output       string         `gorm:"size:16777217" json:"output"`                               // 详细输出日志（MySQL为longtext）
 	ErrorMsg     string         `gorm:"type:text" json:"errorMsg,omitempty"`                       // 错误信息
 }
 
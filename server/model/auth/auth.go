@@ -55,6 +55,25 @@ type GetCaptchaRequest struct {
 	Height int    `json:"height,omitempty"`
 }
 
+// TwoFactorLoginRequest 登录第二步：提交两步验证码
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"twoFactorToken" binding:"required"` // 第一步登录返回的临时令牌
+	Code           string `json:"code" binding:"required"`           // 验证器App中的6位验证码或恢复码
+}
+
+// TwoFactorLoginSetupRequest 强制启用两步验证的管理员在登录时绑定验证器
+type TwoFactorLoginSetupRequest struct {
+	TwoFactorToken string `json:"twoFactorToken" binding:"required"` // 第一步登录返回的临时令牌
+}
+
+// TwoFactorChallenge 需要两步验证时的第一步登录结果
+type TwoFactorChallenge struct {
+	TwoFactorRequired      bool   `json:"twoFactorRequired"`      // 需要提交两步验证码
+	TwoFactorSetupRequired bool   `json:"twoFactorSetupRequired"` // 系统要求启用但尚未绑定，需先调用绑定接口
+	TwoFactorToken         string `json:"twoFactorToken"`         // 临时令牌，仅可用于完成两步验证
+	ExpiresIn              int    `json:"expiresIn"`              // 临时令牌有效期（秒）
+}
+
 // LoginResponse 登录响应
 type LoginResponse struct {
 	Token string `json:"token"`
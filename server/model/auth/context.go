@@ -11,11 +11,13 @@ const (
 
 // AuthContext 认证上下文
 type AuthContext struct {
-	UserID       uint     `json:"user_id"`
-	Username     string   `json:"username"`
-	UserType     string   `json:"user_type"`      // 当前有效的用户类型
-	Level        int      `json:"level"`          // 当前有效权限级别
-	BaseUserType string   `json:"base_user_type"` // 用户基础类型
-	AllUserTypes []string `json:"all_user_types"` // 用户拥有的所有权限类型
-	IsEffective  bool     `json:"is_effective"`   // 权限是否有效
+	UserID         uint     `json:"user_id"`
+	Username       string   `json:"username"`
+	UserType       string   `json:"user_type"`        // 当前有效的用户类型
+	Level          int      `json:"level"`            // 当前有效权限级别
+	BaseUserType   string   `json:"base_user_type"`   // 用户基础类型
+	AllUserTypes   []string `json:"all_user_types"`   // 用户拥有的所有权限类型
+	IsEffective    bool     `json:"is_effective"`     // 权限是否有效
+	APITokenID     uint     `json:"api_token_id"`     // 通过API令牌认证时的令牌ID，JWT认证时为0
+	APITokenScopes []string `json:"api_token_scopes"` // 通过API令牌认证时令牌的权限范围
 }
@@ -21,6 +21,7 @@ const (
 	CodeNotFound        = 1005
 	CodeConflict        = 1006
 	CodeValidationError = 1007
+	CodeTooManyRequests = 1008
 
 	// 用户相关错误 2000-2999
 	CodeUserNotFound       = 2001
@@ -47,6 +48,9 @@ const (
 	CodeTokenGenerateError      = 4006
 	CodeOAuth2Failed            = 4007 // OAuth2认证失败
 	CodeOAuth2RegistrationLimit = 4008 // OAuth2注册已达限制
+	CodeTwoFactorInvalid        = 4009 // 两步验证码错误
+	CodeTwoFactorLocked         = 4010 // 两步验证失败次数过多
+	CodeTwoFactorTokenInvalid   = 4011 // 两步验证令牌无效或已过期
 
 	// 系统相关错误 5000-5999
 	CodeConfigError      = 5001
@@ -67,6 +71,7 @@ var ErrorMessages = map[int]string{
 	CodeNotFound:                "资源不存在",
 	CodeConflict:                "资源冲突",
 	CodeValidationError:         "数据验证失败",
+	CodeTooManyRequests:         "请求过于频繁，请稍后再试",
 	CodeUserNotFound:            "用户不存在",
 	CodeUserExists:              "用户已存在",
 	CodeUsernameExists:          "用户名已存在",
@@ -87,6 +92,9 @@ var ErrorMessages = map[int]string{
 	CodeTokenGenerateError:      "令牌生成失败",
 	CodeOAuth2Failed:            "OAuth2认证失败",
 	CodeOAuth2RegistrationLimit: "OAuth2注册已达到限制",
+	CodeTwoFactorInvalid:        "两步验证码错误",
+	CodeTwoFactorLocked:         "两步验证失败次数过多，请稍后再试",
+	CodeTwoFactorTokenInvalid:   "两步验证已过期，请重新登录",
 	CodeConfigError:             "配置错误",
 	CodeDatabaseError:           "数据库错误",
 	CodeCacheError:              "缓存错误",
@@ -178,7 +186,7 @@ func getHTTPCode(code int) int {
 	switch code {
 	case CodeInvalidParam, CodeValidationError, CodeCaptchaInvalid, CodeCaptchaRequired, CodeInviteCodeInvalid, CodeInviteCodeExpired, CodeInviteCodeUsed:
 		return http.StatusBadRequest
-	case CodeUnauthorized, CodeInvalidCredentials:
+	case CodeUnauthorized, CodeInvalidCredentials, CodeTwoFactorInvalid, CodeTwoFactorTokenInvalid:
 		return http.StatusUnauthorized
 	case CodeForbidden, CodePermissionDeny, CodeUserPermissionDeny, CodeUserDisabled:
 		return http.StatusForbidden
@@ -188,6 +196,8 @@ func getHTTPCode(code int) int {
 		return http.StatusConflict
 	case CodeRequestTooLarge:
 		return http.StatusRequestEntityTooLarge
+	case CodeTooManyRequests, CodeTwoFactorLocked:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
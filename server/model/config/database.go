@@ -1,7 +1,9 @@
 package config
 
-// MysqlConfig MySQL数据库配置
+// MysqlConfig 数据库连接配置
+// 除MySQL/MariaDB外也用于PostgreSQL和SQLite，SQLite使用 Dbname 作为数据库文件路径
 type MysqlConfig struct {
+	DbType       string // 数据库类型：mysql|mariadb|postgres|sqlite，为空时按mysql处理
 	Path         string
 	Port         string
 	Config       string
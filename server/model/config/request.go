@@ -22,6 +22,7 @@ type AuthConfig struct {
 	TelegramBotToken         string `json:"telegramBotToken"`
 	QQAppID                  string `json:"qqAppID"`
 	QQAppKey                 string `json:"qqAppKey"`
+	ForceAdminTwoFactor      bool   `json:"forceAdminTwoFactor"` // 是否强制管理员启用两步验证
 }
 
 type InviteCodeConfig struct {
@@ -44,17 +45,20 @@ type LevelLimitInfo struct {
 	MaxResources map[string]interface{} `json:"maxResources"`
 	MaxTraffic   int64                  `json:"maxTraffic"`                                // 最大流量限制(MB)
 	ExpiryDays   int                    `json:"expiryDays"`                                // 新注册用户的默认过期天数，0表示不过期
+	MaxSnapshots int                    `json:"maxSnapshots"`                              // 每个实例最多保留的快照数，0表示不允许创建快照
+	MaxBackups   int                    `json:"maxBackups"`                                // 每个用户最多保留的备份数，0表示不允许备份
+	MaxDomains   int                    `json:"maxDomains"`                                // 每个用户最多绑定的域名数，0表示不允许绑定域名
 	ExpiryTime   *time.Time             `json:"expiryTime,omitempty" swaggertype:"string"` // 具体过期时间（用于计算，前端不需要传）
 }
 
 // DatabaseConfig 数据库初始化配置
 type DatabaseConfig struct {
 	Type         string `json:"type" binding:"required"`
-	Host         string `json:"host" binding:"required"`
-	Port         int    `json:"port" binding:"required,min=1,max=65535"`
-	Username     string `json:"username" binding:"required"`
+	Host         string `json:"host"`                                     // SQLite不需要
+	Port         int    `json:"port" binding:"omitempty,min=1,max=65535"` // 为空时使用默认端口
+	Username     string `json:"username"`                                 // SQLite不需要
 	Password     string `json:"password"`
-	Database     string `json:"database" binding:"required"`
+	Database     string `json:"database"` // SQLite为数据库文件路径
 	DatabaseName string `json:"databaseName"`
 	SSLMode      string `json:"sslMode"`
 }
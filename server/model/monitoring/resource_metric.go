@@ -0,0 +1,57 @@
+package monitoring
+
+import "time"
+
+// 资源监控数据精度
+const (
+	MetricResolutionRaw  = "raw"  // 原始采样，每个采集周期一条
+	MetricResolutionHour = "hour" // 小时汇总，由原始采样降采样得到
+)
+
+// InstanceResourceMetric 实例CPU、内存、磁盘使用情况时间序列
+// 原始采样保留较短时间，过期前按小时汇总（平均值与最大值）后长期保留
+type InstanceResourceMetric struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	InstanceID uint      `json:"instance_id" gorm:"index:idx_instance_metric_time,priority:1;not null"`       // 实例ID
+	ProviderID uint      `json:"provider_id" gorm:"index:idx_metric_provider_id;not null"`                    // Provider ID
+	UserID     uint      `json:"user_id" gorm:"index:idx_metric_user_id;not null"`                            // 用户ID
+	Resolution string    `json:"resolution" gorm:"size:8;index:idx_instance_metric_time,priority:2;not null"` // 数据精度：raw, hour
+	Timestamp  time.Time `json:"timestamp" gorm:"index:idx_instance_metric_time,priority:3;not null"`         // 采样时间，小时汇总为该小时的开始时间
+
+	CPUPercent      float64 `json:"cpu_percent"`        // CPU使用率（%，按分配的核心数归一化）
+	CPUPercentMax   float64 `json:"cpu_percent_max"`    // 汇总周期内的CPU最高使用率（%）
+	MemoryUsedMB    int64   `json:"memory_used_mb"`     // 已用内存（MB）
+	MemoryUsedMaxMB int64   `json:"memory_used_max_mb"` // 汇总周期内的最高内存使用（MB）
+	MemoryTotalMB   int64   `json:"memory_total_mb"`    // 内存上限（MB）
+	DiskUsedMB      int64   `json:"disk_used_mb"`       // 根磁盘已用空间（MB），平台不支持时为0
+	DiskTotalMB     int64   `json:"disk_total_mb"`      // 根磁盘大小（MB）
+	Samples         int     `json:"samples"`            // 汇总的原始采样数，原始采样为1
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (InstanceResourceMetric) TableName() string {
+	return "instance_resource_metrics"
+}
+
+// ResourceUsagePoint 资源使用曲线上的一个点
+type ResourceUsagePoint struct {
+	Timestamp       time.Time `json:"timestamp"`
+	CPUPercent      float64   `json:"cpuPercent"`      // CPU使用率（%）
+	CPUPercentMax   float64   `json:"cpuPercentMax"`   // CPU最高使用率（%），原始采样与CPUPercent相同
+	MemoryUsedMB    int64     `json:"memoryUsedMB"`    // 已用内存（MB）
+	MemoryUsedMaxMB int64     `json:"memoryUsedMaxMB"` // 最高内存使用（MB）
+	MemoryTotalMB   int64     `json:"memoryTotalMB"`   // 内存上限（MB）
+	DiskUsedMB      int64     `json:"diskUsedMB"`      // 根磁盘已用空间（MB）
+	DiskTotalMB     int64     `json:"diskTotalMB"`     // 根磁盘大小（MB）
+}
+
+// ResourceUsageSeries 实例在一个时间范围内的资源使用曲线
+type ResourceUsageSeries struct {
+	InstanceID uint                 `json:"instanceId"`
+	Range      string               `json:"range"`      // 时间范围：1h, 6h, 24h, 7d, 30d
+	Resolution string               `json:"resolution"` // 数据精度：raw, hour
+	Latest     *ResourceUsagePoint  `json:"latest"`     // 最近一次采样，实例未运行或长时间未采集到时为空
+	Points     []ResourceUsagePoint `json:"points"`
+}
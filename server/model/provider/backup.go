@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 备份状态
+const (
+	BackupStatusCreating  = "creating"  // 创建中
+	BackupStatusAvailable = "available" // 可用
+	BackupStatusFailed    = "failed"    // 失败
+)
+
+// 备份触发方式
+const (
+	BackupTriggerManual    = "manual"    // 手动
+	BackupTriggerScheduled = "scheduled" // 定时
+)
+
+// InstanceBackup 实例备份模型
+// 备份归档保存在控制端本地或S3兼容存储中，实例删除后备份仍然保留
+type InstanceBackup struct {
+	// 基础字段
+	ID        uint           `json:"id" gorm:"primarykey"`                     // 备份主键ID
+	UUID      string         `json:"uuid" gorm:"uniqueIndex;not null;size:36"` // 备份唯一标识符
+	CreatedAt time.Time      `json:"createdAt"`                                // 创建时间
+	UpdatedAt time.Time      `json:"updatedAt"`                                // 更新时间
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                           // 软删除时间
+
+	// 备份信息
+	Status       string     `json:"status" gorm:"default:creating;size:16;index"` // 备份状态：creating, available, failed
+	Trigger      string     `json:"trigger" gorm:"default:manual;size:16"`        // 触发方式：manual, scheduled
+	ErrorMessage string     `json:"errorMessage" gorm:"size:512"`                 // 失败时的错误信息
+	StorageType  string     `json:"storageType" gorm:"size:16"`                   // 存储类型：local, s3
+	StorageKey   string     `json:"-" gorm:"size:512"`                            // 归档在存储中的路径
+	Size         int64      `json:"size" gorm:"default:0"`                        // 归档大小（字节）
+	CompletedAt  *time.Time `json:"completedAt"`                                  // 完成时间
+
+	// 源实例信息（实例删除后仍可用于恢复到其他实例）
+	InstanceID   uint   `json:"instanceId" gorm:"not null;index"` // 源实例ID
+	InstanceName string `json:"instanceName" gorm:"size:128"`     // 源实例名称
+	InstanceType string `json:"instanceType" gorm:"size:16"`      // 实例类型：container, vm
+	OSType       string `json:"osType" gorm:"size:64"`            // 操作系统
+	ProviderID   uint   `json:"providerId" gorm:"not null;index"` // 源Provider ID
+	ProviderType string `json:"providerType" gorm:"size:32"`      // Provider类型：lxd, incus, proxmox, docker
+	UserID       uint   `json:"userId" gorm:"not null;index"`     // 所属用户ID
+}
+
+func (b *InstanceBackup) BeforeCreate(tx *gorm.DB) error {
+	b.UUID = uuid.New().String()
+	return nil
+}
+
+// BackupSchedule 实例定时备份计划
+type BackupSchedule struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	InstanceID    uint       `json:"instanceId" gorm:"uniqueIndex;not null"` // 实例ID，每个实例最多一个计划
+	UserID        uint       `json:"userId" gorm:"not null;index"`           // 所属用户ID
+	Enabled       bool       `json:"enabled" gorm:"default:true"`            // 是否启用
+	IntervalHours int        `json:"intervalHours" gorm:"not null"`          // 备份间隔（小时）
+	NextRunAt     time.Time  `json:"nextRunAt" gorm:"index"`                 // 下次执行时间
+	LastRunAt     *time.Time `json:"lastRunAt"`                              // 上次执行时间
+}
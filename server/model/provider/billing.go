@@ -0,0 +1,116 @@
+package provider
+
+import "time"
+
+// 计费周期
+const (
+	BillingCycleHourly  = "hourly"  // 按小时
+	BillingCycleMonthly = "monthly" // 按月（30天）
+)
+
+// BillingPrice 管理员定义的实例价格规则，金额单位为分
+// 规格字段为0、ProviderID为0、InstanceType为空时表示不限制，匹配时优先使用条件最具体的规则
+type BillingPrice struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Name         string `json:"name" gorm:"size:64;not null"`      // 规则名称
+	ProviderID   uint   `json:"providerId" gorm:"index;default:0"` // 适用的Provider，0表示全部
+	InstanceType string `json:"instanceType" gorm:"size:16"`       // 适用的实例类型：container, vm，为空表示全部
+	CPU          int    `json:"cpu" gorm:"default:0"`              // 适用的CPU核心数，0表示不限
+	Memory       int64  `json:"memory" gorm:"default:0"`           // 适用的内存（MB），0表示不限
+	Disk         int64  `json:"disk" gorm:"default:0"`             // 适用的磁盘（MB），0表示不限
+	HourlyPrice  int64  `json:"hourlyPrice" gorm:"default:0"`      // 小时价格（分）
+	MonthlyPrice int64  `json:"monthlyPrice" gorm:"default:0"`     // 月价格（分），按30天计
+	Enabled      bool   `json:"enabled" gorm:"index"`              // 是否启用
+	Description  string `json:"description" gorm:"size:255"`       // 说明
+}
+
+func (BillingPrice) TableName() string {
+	return "billing_prices"
+}
+
+// Cycle 价格规则的计费周期：设置了小时价格时按小时计费，否则按月计费
+func (p *BillingPrice) Cycle() string {
+	if p.HourlyPrice > 0 {
+		return BillingCycleHourly
+	}
+	return BillingCycleMonthly
+}
+
+// PeriodCost 一个计费周期的金额和时长
+func (p *BillingPrice) PeriodCost() (int64, time.Duration) {
+	if p.Cycle() == BillingCycleHourly {
+		return p.HourlyPrice, time.Hour
+	}
+	return p.MonthlyPrice, 30 * 24 * time.Hour
+}
+
+// HourlyCost 折算为每小时的金额，用于展示
+func (p *BillingPrice) HourlyCost() int64 {
+	if p.Cycle() == BillingCycleHourly {
+		return p.HourlyPrice
+	}
+	return p.MonthlyPrice / (30 * 24)
+}
+
+// Matches 规则是否适用于指定实例
+func (p *BillingPrice) Matches(inst *Instance) bool {
+	if p.ProviderID != 0 && p.ProviderID != inst.ProviderID {
+		return false
+	}
+	if p.InstanceType != "" && p.InstanceType != inst.InstanceType {
+		return false
+	}
+	if p.CPU != 0 && p.CPU != inst.CPU {
+		return false
+	}
+	if p.Memory != 0 && p.Memory != inst.Memory {
+		return false
+	}
+	if p.Disk != 0 && p.Disk != inst.Disk {
+		return false
+	}
+	return true
+}
+
+// Specificity 规则的具体程度，用于在多条规则同时匹配时选择最具体的一条
+func (p *BillingPrice) Specificity() int {
+	score := 0
+	if p.ProviderID != 0 {
+		score += 16
+	}
+	if p.InstanceType != "" {
+		score += 8
+	}
+	if p.CPU != 0 {
+		score += 4
+	}
+	if p.Memory != 0 {
+		score += 2
+	}
+	if p.Disk != 0 {
+		score++
+	}
+	return score
+}
+
+// InstanceBilling 实例计费状态，记录已付费截止时间
+type InstanceBilling struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	InstanceID    uint       `json:"instanceId" gorm:"uniqueIndex;not null"` // 实例
+	UserID        uint       `json:"userId" gorm:"index;not null"`           // 实例所属用户
+	PriceID       uint       `json:"priceId"`                                // 最近一次扣款使用的价格规则
+	Cycle         string     `json:"cycle" gorm:"size:16"`                   // 最近一次扣款的计费周期
+	PaidUntil     time.Time  `json:"paidUntil" gorm:"index"`                 // 已付费截止时间
+	LastChargedAt *time.Time `json:"lastChargedAt"`                          // 最近一次扣款时间
+	TotalCharged  int64      `json:"totalCharged" gorm:"default:0"`          // 累计扣款（分）
+}
+
+func (InstanceBilling) TableName() string {
+	return "instance_billings"
+}
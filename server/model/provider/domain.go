@@ -0,0 +1,48 @@
+package provider
+
+import "time"
+
+// 域名转发协议
+const (
+	DomainProtocolHTTP  = "http"  // 节点80端口按Host头转发HTTP请求
+	DomainProtocolHTTPS = "https" // 节点443端口按SNI透传TLS连接，证书由实例自行管理
+)
+
+// 域名所有权验证方式
+// 节点上的反向代理由所有租户共享，域名解析到节点不能证明域名归属，新绑定只使用DNS验证
+const (
+	DomainVerifyDNS  = "dns"  // TXT记录 _oneclickvirt-verify.<domain> 的值为验证令牌
+	DomainVerifyHTTP = "http" // 已停用：由节点上的反向代理返回验证令牌，仅用于识别旧的绑定记录
+)
+
+// 域名绑定状态
+const (
+	DomainStatusPending = "pending" // 等待所有权验证
+	DomainStatusActive  = "active"  // 已验证且反向代理已生效
+	DomainStatusError   = "error"   // 已验证，但反向代理配置失败
+)
+
+// InstanceDomain 实例绑定的域名，由Provider节点上共享的nginx反向代理转发到实例内部端口
+// 同一域名可分别绑定http和https两条记录；未验证的绑定不占用域名，多个用户可同时等待验证，
+// 其中一个用户验证通过后其他用户的未验证绑定被删除
+type InstanceDomain struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID       uint       `json:"userId" gorm:"index;not null"`
+	InstanceID   uint       `json:"instanceId" gorm:"index;not null"`
+	ProviderID   uint       `json:"providerId" gorm:"index;not null"`
+	Domain       string     `json:"domain" gorm:"size:253;not null;index:idx_domain_protocol"`
+	Protocol     string     `json:"protocol" gorm:"size:8;not null;index:idx_domain_protocol"` // http, https
+	TargetPort   int        `json:"targetPort" gorm:"not null"`                                // 实例内部端口
+	VerifyMethod string     `json:"verifyMethod" gorm:"size:8"`                                // dns（http为停用前创建的旧记录）
+	VerifyToken  string     `json:"verifyToken" gorm:"size:64"`                                // 验证令牌
+	Status       string     `json:"status" gorm:"size:16;index;default:pending"`               // pending, active, error
+	VerifiedAt   *time.Time `json:"verifiedAt"`                                                // 所有权验证通过时间
+	LastError    string     `json:"lastError" gorm:"size:255"`                                 // 最近一次验证或配置失败的原因
+}
+
+func (InstanceDomain) TableName() string {
+	return "instance_domains"
+}
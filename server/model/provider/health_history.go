@@ -0,0 +1,19 @@
+package provider
+
+import "time"
+
+// ProviderHealthRecord Provider健康检查历史，每次检查写入一条，超过保留期后清理
+type ProviderHealthRecord struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	ProviderID uint      `json:"providerId" gorm:"not null;index:idx_provider_health_time,priority:1"`
+	CheckedAt  time.Time `json:"checkedAt" gorm:"not null;index:idx_provider_health_time,priority:2;index"`
+	Status     string    `json:"status" gorm:"size:16"`    // 整体状态：active, partial, inactive
+	SSHStatus  string    `json:"sshStatus" gorm:"size:16"` // SSH状态：online, offline
+	APIStatus  string    `json:"apiStatus" gorm:"size:16"` // API状态：online, offline, unknown
+	DurationMs int64     `json:"durationMs"`               // 检查耗时（毫秒）
+	Error      string    `json:"error" gorm:"size:255"`    // 检查错误信息
+}
+
+func (ProviderHealthRecord) TableName() string {
+	return "provider_health_records"
+}
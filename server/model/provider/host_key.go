@@ -0,0 +1,29 @@
+package provider
+
+import "time"
+
+// SSHStatusKeyMismatch 节点SSH主机密钥与记录不一致时的SSH状态，需管理员核实并确认新密钥后才会重新连接
+const SSHStatusKeyMismatch = "key_mismatch"
+
+// ProviderHostKey Provider节点的SSH主机密钥
+// 首次连接时记录（TOFU），之后每次连接都必须与记录一致；检测到不一致时拒绝连接并保存新密钥等待管理员确认
+type ProviderHostKey struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	ProviderID  uint   `json:"providerId" gorm:"uniqueIndex;not null"`
+	KeyType     string `json:"keyType" gorm:"size:64"`      // 如 ssh-ed25519、ecdsa-sha2-nistp256
+	PublicKey   string `json:"publicKey" gorm:"type:text"`  // authorized_keys格式的公钥
+	Fingerprint string `json:"fingerprint" gorm:"size:128"` // SHA256指纹
+
+	// 检测到的不一致密钥，管理员确认后替换已记录的密钥
+	PendingKeyType     string     `json:"pendingKeyType" gorm:"size:64"`
+	PendingPublicKey   string     `json:"pendingPublicKey" gorm:"type:text"`
+	PendingFingerprint string     `json:"pendingFingerprint" gorm:"size:128"`
+	MismatchAt         *time.Time `json:"mismatchAt"` // 最近一次检测到不一致的时间，为空表示密钥一致
+}
+
+func (ProviderHostKey) TableName() string {
+	return "provider_host_keys"
+}
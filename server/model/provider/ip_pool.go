@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 地址族
+const (
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
+
+// 地址池状态
+const (
+	IPPoolStatusActive   = "active"   // 参与分配
+	IPPoolStatusDisabled = "disabled" // 停止分配，已分配的地址不受影响
+)
+
+// 地址状态
+const (
+	IPAddressStatusFree      = "free"      // 空闲
+	IPAddressStatusAllocated = "allocated" // 已分配给实例
+	IPAddressStatusReserved  = "reserved"  // 管理员保留，不参与分配
+)
+
+// IPPool 独立IP地址池
+// 用于 dedicated_ipv4 / dedicated_ipv4_ipv6 网络类型的Provider，为实例分配独立公网地址
+type IPPool struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	ProviderID  uint   `json:"providerId" gorm:"not null;index"`           // 所属Provider ID
+	Name        string `json:"name" gorm:"size:64;not null"`               // 地址池名称
+	Family      string `json:"family" gorm:"size:8;not null"`              // 地址族：ipv4, ipv6
+	CIDR        string `json:"cidr" gorm:"size:64;not null"`               // 网段，决定分配地址的前缀长度
+	Gateway     string `json:"gateway" gorm:"size:64"`                     // 网关地址
+	Status      string `json:"status" gorm:"size:16;default:active;index"` // 状态：active, disabled
+	Description string `json:"description" gorm:"size:255"`                // 描述
+}
+
+// IPAddress 地址池中的单个地址
+type IPAddress struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	PoolID      uint       `json:"poolId" gorm:"not null;uniqueIndex:idx_ip_pool_address"`          // 所属地址池ID
+	ProviderID  uint       `json:"providerId" gorm:"not null;index"`                                // 所属Provider ID（冗余字段，便于分配时查询）
+	Family      string     `json:"family" gorm:"size:8;not null"`                                   // 地址族：ipv4, ipv6
+	Address     string     `json:"address" gorm:"size:64;not null;uniqueIndex:idx_ip_pool_address"` // IP地址
+	Status      string     `json:"status" gorm:"size:16;default:free;index"`                        // 状态：free, allocated, reserved
+	InstanceID  *uint      `json:"instanceId" gorm:"index"`                                         // 已分配的实例ID
+	AllocatedAt *time.Time `json:"allocatedAt"`                                                     // 分配时间
+}
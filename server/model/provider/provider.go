@@ -90,9 +90,9 @@ type Provider struct {
 	PortIP   string `json:"portIP" gorm:"size:255"`                      // 端口映射使用的公网IP（非必填，若为空则使用Endpoint）
 	SSHPort  int    `json:"sshPort" gorm:"default:22"`                   // SSH连接端口
 	Username string `json:"username" gorm:"size:128"`                    // SSH连接用户名
-	Password string `json:"-" gorm:"size:255"`                           // SSH连接密码（不返回给前端）
-	SSHKey   string `json:"-" gorm:"type:text"`                          // SSH私钥（不返回给前端，优先于密码使用）
-	Token    string `json:"-" gorm:"size:255"`                           // API访问令牌（不返回给前端）
+	Password string `json:"-" gorm:"type:text;serializer:encrypted"`     // SSH连接密码（加密存储，不返回给前端）
+	SSHKey   string `json:"-" gorm:"type:text;serializer:encrypted"`     // SSH私钥（加密存储，不返回给前端，优先于密码使用）
+	Token    string `json:"-" gorm:"type:text;serializer:encrypted"`     // API访问令牌（加密存储，不返回给前端）
 	Config   string `json:"config" gorm:"type:text"`                     // 额外配置信息（JSON格式）
 
 	// 状态和地理信息
@@ -138,14 +138,14 @@ type Provider struct {
 	LastSSHCheck    *time.Time `json:"lastSshCheck"`                             // 最后一次SSH健康检查时间
 
 	// 配置管理字段
-	AuthConfig       string     `json:"-" gorm:"type:text"`                  // 完整认证配置JSON（不返回给前端）
-	ConfigVersion    int        `json:"configVersion" gorm:"default:0"`      // 配置版本号
-	AutoConfigured   bool       `json:"autoConfigured" gorm:"default:false"` // 是否已经自动配置完成
-	LastConfigUpdate *time.Time `json:"lastConfigUpdate"`                    // 最后一次配置更新时间
-	ConfigBackupPath string     `json:"configBackupPath" gorm:"size:512"`    // 配置备份文件路径
-	CertContent      string     `json:"-" gorm:"type:text"`                  // 证书内容（不返回给前端）
-	KeyContent       string     `json:"-" gorm:"type:text"`                  // 私钥内容（不返回给前端）
-	TokenContent     string     `json:"-" gorm:"type:text"`                  // Token内容JSON格式（不返回给前端）
+	AuthConfig       string     `json:"-" gorm:"type:text;serializer:encrypted"` // 完整认证配置JSON（加密存储，不返回给前端）
+	ConfigVersion    int        `json:"configVersion" gorm:"default:0"`          // 配置版本号
+	AutoConfigured   bool       `json:"autoConfigured" gorm:"default:false"`     // 是否已经自动配置完成
+	LastConfigUpdate *time.Time `json:"lastConfigUpdate"`                        // 最后一次配置更新时间
+	ConfigBackupPath string     `json:"configBackupPath" gorm:"size:512"`        // 配置备份文件路径
+	CertContent      string     `json:"-" gorm:"type:text"`                      // 证书内容（不返回给前端）
+	KeyContent       string     `json:"-" gorm:"type:text;serializer:encrypted"` // 私钥内容（加密存储，不返回给前端）
+	TokenContent     string     `json:"-" gorm:"type:text;serializer:encrypted"` // Token内容JSON格式（加密存储，不返回给前端）
 
 	// 节点硬件资源信息（通过SSH查询获得）
 	NodeCPUCores    int   `json:"nodeCpuCores" gorm:"default:0"`    // 节点总CPU核心数
@@ -339,8 +339,9 @@ type Instance struct {
 	PortRangeEnd   int    `json:"portRangeEnd"`                // 端口映射范围结束
 
 	// 访问凭据
-	Username string `json:"username" gorm:"size:64"`  // 登录用户名
-	Password string `json:"password" gorm:"size:128"` // 登录密码
+	Username string `json:"username" gorm:"size:64"`                        // 登录用户名
+	Password string `json:"password" gorm:"type:text;serializer:encrypted"` // 登录密码（加密存储）
+	UserData string `json:"-" gorm:"type:text"`                             // 创建时用户提供的cloud-init user-data或启动脚本，重置实例时重新应用
 
 	// 系统信息
 	OSType string `json:"osType" gorm:"size:64"` // 操作系统类型：ubuntu, centos, debian等
@@ -442,6 +443,15 @@ type ProviderImage struct {
 	Metadata    map[string]string `json:"metadata"`
 }
 
+// ProviderSnapshot 快照信息
+type ProviderSnapshot struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Size        string            `json:"size"`
+	Created     time.Time         `json:"created"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
 // ProviderInstanceConfig 实例配置
 type ProviderInstanceConfig struct {
 	Name         string            `json:"name"`
@@ -456,7 +466,8 @@ type ProviderInstanceConfig struct {
 	Ports        []string          `json:"ports"`
 	Env          map[string]string `json:"env"`
 	Metadata     map[string]string `json:"metadata"`
-	InstanceType string            `json:"instance_type"` // container 或 vm
+	InstanceType string            `json:"instance_type"`       // container 或 vm
+	UserData     string            `json:"user_data,omitempty"` // 用户提供的cloud-init user-data（Docker为启动脚本）
 
 	// 容器特殊配置选项（仅适用于 LXD 和 Incus 的容器实例）
 	Privileged   *bool   `json:"privileged,omitempty"`   // 容器特权模式，使用指针以区分 false 和未设置
@@ -0,0 +1,79 @@
+package provider
+
+import "time"
+
+// 安全组规则动作
+const (
+	SecurityGroupActionAllow = "allow" // 允许
+	SecurityGroupActionDeny  = "deny"  // 拒绝
+)
+
+// 安全组规则协议
+const (
+	SecurityGroupProtocolTCP  = "tcp"
+	SecurityGroupProtocolUDP  = "udp"
+	SecurityGroupProtocolICMP = "icmp" // 同时匹配ICMP和ICMPv6
+	SecurityGroupProtocolAll  = "all"
+)
+
+// 安全组应用状态
+const (
+	SecurityGroupApplyPending = "pending" // 等待应用
+	SecurityGroupApplyApplied = "applied" // 已应用
+	SecurityGroupApplyFailed  = "failed"  // 应用失败
+)
+
+// SecurityGroup 用户定义的入站防火墙规则集合，可绑定到该用户的多个实例
+// 拒绝规则优先于允许规则，未匹配任何规则的入站流量按 DefaultAction 处理；出站流量不受限制
+type SecurityGroup struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID        uint                `json:"userId" gorm:"index;not null"`              // 所属用户
+	Name          string              `json:"name" gorm:"size:64;not null"`              // 名称
+	Description   string              `json:"description" gorm:"size:255"`               // 说明
+	DefaultAction string              `json:"defaultAction" gorm:"size:8;default:allow"` // 未匹配规则的入站流量：allow, deny（deny时需要显式放行SSH等端口）
+	Rules         []SecurityGroupRule `json:"rules" gorm:"foreignKey:SecurityGroupID"`   // 规则
+	InstanceCount int64               `json:"instanceCount" gorm:"-"`                    // 绑定的实例数
+}
+
+func (SecurityGroup) TableName() string {
+	return "security_groups"
+}
+
+// SecurityGroupRule 安全组入站规则
+// 端口为实例内部端口（端口映射的目标端口），PortStart为0表示全部端口；Source为空表示任意来源（IPv4和IPv6）
+type SecurityGroupRule struct {
+	ID              uint      `json:"id" gorm:"primarykey"`
+	CreatedAt       time.Time `json:"createdAt"`
+	SecurityGroupID uint      `json:"securityGroupId" gorm:"index;not null"`
+	Action          string    `json:"action" gorm:"size:8;not null"`   // allow, deny
+	Protocol        string    `json:"protocol" gorm:"size:8;not null"` // tcp, udp, icmp, all
+	PortStart       int       `json:"portStart" gorm:"default:0"`      // 起始端口
+	PortEnd         int       `json:"portEnd" gorm:"default:0"`        // 结束端口
+	Source          string    `json:"source" gorm:"size:64"`           // 来源CIDR
+	Description     string    `json:"description" gorm:"size:255"`     // 说明
+}
+
+func (SecurityGroupRule) TableName() string {
+	return "security_group_rules"
+}
+
+// InstanceSecurityGroup 实例绑定的安全组，每个实例最多绑定一个
+type InstanceSecurityGroup struct {
+	ID              uint       `json:"id" gorm:"primarykey"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	InstanceID      uint       `json:"instanceId" gorm:"uniqueIndex;not null"`
+	SecurityGroupID uint       `json:"securityGroupId" gorm:"index;not null"`
+	ApplyStatus     string     `json:"applyStatus" gorm:"size:16;default:pending"` // pending, applied, failed
+	ApplyError      string     `json:"applyError" gorm:"size:255"`                 // 最近一次应用失败的原因
+	AppliedAt       *time.Time `json:"appliedAt"`                                  // 最近一次成功应用的时间
+
+	SecurityGroup *SecurityGroup `json:"securityGroup,omitempty" gorm:"foreignKey:SecurityGroupID"`
+}
+
+func (InstanceSecurityGroup) TableName() string {
+	return "instance_security_groups"
+}
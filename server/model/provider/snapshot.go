@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 快照状态
+const (
+	SnapshotStatusCreating  = "creating"  // 创建中
+	SnapshotStatusAvailable = "available" // 可用
+	SnapshotStatusRestoring = "restoring" // 恢复中
+	SnapshotStatusDeleting  = "deleting"  // 删除中
+	SnapshotStatusFailed    = "failed"    // 失败
+)
+
+// InstanceSnapshot 实例快照模型
+type InstanceSnapshot struct {
+	// 基础字段
+	ID        uint           `json:"id" gorm:"primarykey"`                     // 快照主键ID
+	UUID      string         `json:"uuid" gorm:"uniqueIndex;not null;size:36"` // 快照唯一标识符
+	CreatedAt time.Time      `json:"createdAt"`                                // 创建时间
+	UpdatedAt time.Time      `json:"updatedAt"`                                // 更新时间
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                           // 软删除时间
+
+	// 快照信息
+	Name         string `json:"name" gorm:"not null;size:64"`                                                         // 快照名称（Provider上的实际名称）
+	Description  string `json:"description" gorm:"size:255"`                                                          // 快照描述
+	Status       string `json:"status" gorm:"default:creating;size:16;index:idx_snapshot_instance_status,priority:2"` // 快照状态：creating, available, restoring, deleting, failed
+	ErrorMessage string `json:"errorMessage" gorm:"size:512"`                                                         // 最近一次操作失败的错误信息
+	InstanceName string `json:"instanceName" gorm:"size:128"`                                                         // 创建快照时的实例名称
+	ProviderType string `json:"providerType" gorm:"size:32"`                                                          // Provider类型：lxd, incus, proxmox, docker
+	InstanceID   uint   `json:"instanceId" gorm:"not null;index:idx_snapshot_instance_status,priority:1"`             // 关联的实例ID
+	ProviderID   uint   `json:"providerId" gorm:"not null;index"`                                                     // 关联的Provider ID
+	UserID       uint   `json:"userId" gorm:"not null;index"`                                                         // 所属用户ID
+}
+
+func (s *InstanceSnapshot) BeforeCreate(tx *gorm.DB) error {
+	s.UUID = uuid.New().String()
+	return nil
+}
@@ -0,0 +1,17 @@
+package system
+
+import "time"
+
+// InstanceHeartbeat 实例心跳表
+// 每个面板实例周期性续约自己的心跳记录，心跳过期说明实例已宕机或已停止，
+// 用于判断该实例上执行中的任务是否已被中断
+type InstanceHeartbeat struct {
+	HolderID  string    `gorm:"primaryKey;type:varchar(128);comment:实例标识" json:"holder_id"`
+	StartedAt time.Time `gorm:"comment:实例启动时间" json:"started_at"`
+	RenewedAt time.Time `gorm:"comment:最后续约时间" json:"renewed_at"`
+	ExpiresAt time.Time `gorm:"index;comment:心跳过期时间" json:"expires_at"`
+}
+
+func (InstanceHeartbeat) TableName() string {
+	return "instance_heartbeats"
+}
@@ -0,0 +1,17 @@
+package system
+
+import "time"
+
+// LeaderLease 领导者租约表
+// 多个面板实例共享同一数据库时，通过租约选出唯一的领导者运行后台调度器
+type LeaderLease struct {
+	Name       string    `gorm:"primaryKey;type:varchar(64);comment:租约名称" json:"name"`
+	HolderID   string    `gorm:"type:varchar(128);not null;comment:当前持有者标识" json:"holder_id"`
+	AcquiredAt time.Time `gorm:"comment:获得租约时间" json:"acquired_at"`
+	RenewedAt  time.Time `gorm:"comment:最后续约时间" json:"renewed_at"`
+	ExpiresAt  time.Time `gorm:"index;comment:租约过期时间" json:"expires_at"`
+}
+
+func (LeaderLease) TableName() string {
+	return "leader_leases"
+}
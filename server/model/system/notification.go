@@ -0,0 +1,74 @@
+package system
+
+import "time"
+
+// 通知事件类型
+const (
+	NotifyEventUserExpiring     = "user.expiring"     // 用户账号即将到期
+	NotifyEventInstanceExpiring = "instance.expiring" // 实例即将到期
+	NotifyEventProviderExpiring = "provider.expiring" // 节点即将到期（通知管理员）
+	NotifyEventFrozen           = "frozen"            // 账号或实例被冻结
+	NotifyEventTrafficLimited   = "traffic.limited"   // 实例因流量超限被停止
+	NotifyEventTaskFailed       = "task.failed"       // 任务执行失败
+	NotifyEventTest             = "notify.test"       // 测试通知
+)
+
+// NotifyEvents 支持自定义模板的通知事件
+var NotifyEvents = []string{
+	NotifyEventUserExpiring,
+	NotifyEventInstanceExpiring,
+	NotifyEventProviderExpiring,
+	NotifyEventFrozen,
+	NotifyEventTrafficLimited,
+	NotifyEventTaskFailed,
+	NotifyEventTest,
+}
+
+// 通知渠道
+const (
+	NotifyChannelEmail    = "email"
+	NotifyChannelTelegram = "telegram"
+)
+
+// 通知发送状态
+const (
+	NotifyStatusSent   = "sent"
+	NotifyStatusFailed = "failed"
+)
+
+// NotificationTemplate 管理员自定义的通知模板，未自定义的事件使用内置模板
+// Subject 和 Body 使用 Go text/template 语法，可引用事件数据中的字段，如 {{.InstanceName}}
+type NotificationTemplate struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Event   string `json:"event" gorm:"uniqueIndex;size:64;not null"` // 通知事件
+	Subject string `json:"subject" gorm:"size:255;not null"`          // 标题（邮件主题，Telegram消息首行）
+	Body    string `json:"body" gorm:"type:text"`                     // 正文
+	Enabled bool   `json:"enabled"`                                   // 是否发送该事件的通知
+}
+
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
+
+// NotificationLog 通知发送记录
+type NotificationLog struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+
+	UserID    uint   `json:"userId" gorm:"index"`         // 接收用户
+	Event     string `json:"event" gorm:"size:64;index"`  // 通知事件
+	Channel   string `json:"channel" gorm:"size:16"`      // 发送渠道：email, telegram
+	Recipient string `json:"recipient" gorm:"size:128"`   // 接收地址：邮箱或Telegram Chat ID
+	Subject   string `json:"subject" gorm:"size:255"`     // 标题
+	Content   string `json:"content" gorm:"type:text"`    // 正文
+	Status    string `json:"status" gorm:"size:16;index"` // 发送结果：sent, failed
+	Error     string `json:"error" gorm:"size:512"`       // 失败原因
+	DedupKey  string `json:"-" gorm:"size:128;index"`     // 去重键，同一键对同一用户只发送一次（如到期提醒）
+}
+
+func (NotificationLog) TableName() string {
+	return "notification_logs"
+}
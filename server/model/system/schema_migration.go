@@ -0,0 +1,15 @@
+package system
+
+import "time"
+
+// SchemaMigration 数据库结构迁移记录表
+// 每执行一个版本的迁移写入一行，回滚时删除对应行
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false;comment:迁移版本号" json:"version"`
+	Name      string    `gorm:"type:varchar(128);not null;comment:迁移名称" json:"name"`
+	AppliedAt time.Time `gorm:"comment:执行时间" json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -37,4 +37,5 @@ const (
 	CacheDir   = "cache"
 	TempDir    = "temp"
 	AvatarsDir = "uploads/avatars"
+	BackupsDir = "backups"
 )
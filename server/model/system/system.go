@@ -91,8 +91,8 @@ type Announcement struct {
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 	Title         string         `json:"title" gorm:"not null;size:255"`
-	Content       string         `json:"content" gorm:"type:longtext"`         // 改为longtext支持富文本
-	ContentHTML   string         `json:"contentHtml" gorm:"type:longtext"`     // 渲染后的HTML内容
+	Content       string         `json:"content" gorm:"size:16777217"`         // 富文本内容，size超过16MB时MySQL使用longtext，其他数据库为text
+	ContentHTML   string         `json:"contentHtml" gorm:"size:16777217"`     // 渲染后的HTML内容
 	Type          string         `json:"type" gorm:"size:32;default:homepage"` // homepage=首页公告, topbar=顶部栏公告
 	Priority      int            `json:"priority" gorm:"default:0"`            // 优先级，数字越大越靠前
 	Status        int            `json:"status" gorm:"default:1"`              // 1=启用 0=禁用
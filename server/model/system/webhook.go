@@ -0,0 +1,120 @@
+package system
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook事件类型
+const (
+	WebhookEventTaskCompleted          = "task.completed"            // 任务完成
+	WebhookEventTaskFailed             = "task.failed"               // 任务失败
+	WebhookEventInstanceFrozen         = "instance.frozen"           // 实例随节点过期被冻结
+	WebhookEventInstanceExpired        = "instance.expired"          // 实例到期被冻结
+	WebhookEventInstanceTrafficLimited = "instance.traffic_limited"  // 实例因流量超限被限制
+	WebhookEventProviderUnhealthy      = "provider.unhealthy"        // Provider健康检查离线
+	WebhookEventBalanceLow             = "billing.balance_low"       // 用户余额低于提醒阈值
+	WebhookEventBalanceExhausted       = "billing.balance_exhausted" // 用户余额不足，实例已冻结
+	WebhookEventTest                   = "webhook.test"              // 测试事件
+	WebhookEventAll                    = "*"                         // 订阅全部事件
+)
+
+// WebhookEvents 可订阅的事件列表
+var WebhookEvents = []string{
+	WebhookEventTaskCompleted,
+	WebhookEventTaskFailed,
+	WebhookEventInstanceFrozen,
+	WebhookEventInstanceExpired,
+	WebhookEventInstanceTrafficLimited,
+	WebhookEventProviderUnhealthy,
+	WebhookEventBalanceLow,
+	WebhookEventBalanceExhausted,
+}
+
+// Webhook投递状态
+const (
+	WebhookDeliveryPending = "pending" // 等待投递或等待重试
+	WebhookDeliverySuccess = "success" // 投递成功
+	WebhookDeliveryFailed  = "failed"  // 重试耗尽后失败
+)
+
+// Webhook 出站Webhook配置
+type Webhook struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Name        string `json:"name" gorm:"size:64;not null"`          // 名称
+	URL         string `json:"url" gorm:"size:512;not null"`          // 接收地址
+	Secret      string `json:"secret" gorm:"size:128;not null"`       // HMAC签名密钥
+	Events      string `json:"events" gorm:"type:text"`               // 订阅的事件，逗号分隔，* 表示全部
+	Enabled     bool   `json:"enabled" gorm:"index"`                  // 是否启用
+	Description string `json:"description" gorm:"size:255"`           // 描述
+	MaxAttempts int    `json:"maxAttempts" gorm:"not null;default:8"` // 最大投递次数（含首次）
+
+	LastDeliveryAt     *time.Time `json:"lastDeliveryAt"`                    // 最近一次投递时间
+	LastDeliveryStatus string     `json:"lastDeliveryStatus" gorm:"size:16"` // 最近一次投递结果
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList 返回订阅的事件列表
+func (w *Webhook) EventList() []string {
+	var events []string
+	for _, e := range strings.Split(w.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Subscribes 是否订阅了指定事件
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.EventList() {
+		if e == WebhookEventAll || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery Webhook投递记录，同时作为持久化的重试队列
+type WebhookDelivery struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UUID      string    `json:"uuid" gorm:"uniqueIndex;not null;size:36"` // 投递唯一标识，随请求头发送便于接收方去重
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	WebhookID uint   `json:"webhookId" gorm:"not null;index"`                                                 // 所属Webhook
+	Event     string `json:"event" gorm:"size:64;index"`                                                      // 事件类型
+	Payload   string `json:"payload" gorm:"type:text"`                                                        // 请求体
+	Status    string `json:"status" gorm:"size:16;default:pending;index:idx_webhook_delivery_due,priority:1"` // 投递状态：pending, success, failed
+
+	Attempts    int        `json:"attempts" gorm:"default:0"`                                    // 已投递次数
+	MaxAttempts int        `json:"maxAttempts" gorm:"default:8"`                                 // 最大投递次数
+	NextRetryAt *time.Time `json:"nextRetryAt" gorm:"index:idx_webhook_delivery_due,priority:2"` // 下次投递时间
+
+	ResponseCode int        `json:"responseCode"`                  // 最近一次响应状态码
+	ResponseBody string     `json:"responseBody" gorm:"size:1024"` // 最近一次响应内容（截断）
+	ErrorMessage string     `json:"errorMessage" gorm:"size:512"`  // 最近一次错误信息
+	DurationMs   int64      `json:"durationMs"`                    // 最近一次请求耗时（毫秒）
+	DeliveredAt  *time.Time `json:"deliveredAt"`                   // 投递成功时间
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.UUID == "" {
+		d.UUID = uuid.New().String()
+	}
+	return nil
+}
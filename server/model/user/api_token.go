@@ -0,0 +1,72 @@
+package user
+
+import (
+	"strings"
+	"time"
+)
+
+// APITokenPrefix API令牌的固定前缀，用于与JWT区分
+const APITokenPrefix = "ocv_"
+
+// API令牌权限范围
+const (
+	APITokenScopeRead      = "read"      // 只读：允许所有GET请求
+	APITokenScopeInstances = "instances" // 实例：创建、操作、删除实例及其快照、备份、任务
+	APITokenScopePorts     = "ports"     // 端口映射
+	APITokenScopeTraffic   = "traffic"   // 流量统计
+)
+
+// APITokenScopes 所有可用的权限范围
+var APITokenScopes = []string{
+	APITokenScopeRead,
+	APITokenScopeInstances,
+	APITokenScopePorts,
+	APITokenScopeTraffic,
+}
+
+// APIToken 用户个人API令牌，用于脚本和CI等非浏览器场景
+type APIToken struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID     uint       `json:"userId" gorm:"index;not null"`          // 所属用户
+	Name       string     `json:"name" gorm:"size:64;not null"`          // 令牌名称
+	Prefix     string     `json:"prefix" gorm:"size:16;not null"`        // 令牌明文前几位，便于用户识别
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"` // 令牌SHA-256哈希，明文仅在创建时返回一次
+	Scopes     string     `json:"scopes" gorm:"size:128;not null"`       // 权限范围，逗号分隔
+	ExpiresAt  *time.Time `json:"expiresAt"`                             // 过期时间，为空表示永不过期
+	LastUsedAt *time.Time `json:"lastUsedAt"`                            // 最近使用时间
+	LastUsedIP string     `json:"lastUsedIp" gorm:"size:64"`             // 最近使用IP
+	RevokedAt  *time.Time `json:"revokedAt" gorm:"index"`                // 撤销时间，非空表示已撤销
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// ScopeList 返回权限范围列表
+func (t *APIToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// HasScope 是否拥有指定权限范围
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive 令牌是否可用（未撤销且未过期）
+func (t *APIToken) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || t.ExpiresAt.After(now)
+}
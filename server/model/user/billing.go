@@ -0,0 +1,67 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 余额流水类型
+const (
+	BalanceTxRecharge = "recharge" // 充值
+	BalanceTxCharge   = "charge"   // 实例计费扣款
+	BalanceTxAdjust   = "adjust"   // 管理员调整（可正可负）
+	BalanceTxRefund   = "refund"   // 退款
+)
+
+// ErrBalanceTransactionImmutable 余额流水写入后不允许修改或删除
+var ErrBalanceTransactionImmutable = errors.New("余额流水不可修改或删除")
+
+// UserBalance 用户预付费余额，金额单位均为分
+type UserBalance struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID             uint       `json:"userId" gorm:"uniqueIndex;not null"` // 所属用户
+	Balance            int64      `json:"balance" gorm:"not null;default:0"`  // 当前余额（分）
+	TotalRecharged     int64      `json:"totalRecharged" gorm:"default:0"`    // 累计充值（分）
+	TotalCharged       int64      `json:"totalCharged" gorm:"default:0"`      // 累计消费（分）
+	LowBalanceWarnedAt *time.Time `json:"lowBalanceWarnedAt"`                 // 最近一次低余额提醒时间，余额回升到阈值以上后清空
+}
+
+func (UserBalance) TableName() string {
+	return "user_balances"
+}
+
+// BalanceTransaction 余额流水，只允许新增，不允许修改或删除
+type BalanceTransaction struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+
+	UserID       uint       `json:"userId" gorm:"not null;index"` // 所属用户
+	Type         string     `json:"type" gorm:"size:16;not null"` // 流水类型：recharge, charge, adjust, refund
+	Amount       int64      `json:"amount" gorm:"not null"`       // 变动金额（分），扣款为负数
+	BalanceAfter int64      `json:"balanceAfter" gorm:"not null"` // 变动后余额（分）
+	InstanceID   *uint      `json:"instanceId" gorm:"index"`      // 关联实例（计费扣款时）
+	PriceID      *uint      `json:"priceId"`                      // 使用的价格规则（计费扣款时）
+	PeriodStart  *time.Time `json:"periodStart"`                  // 计费周期开始（计费扣款时）
+	PeriodEnd    *time.Time `json:"periodEnd"`                    // 计费周期结束（计费扣款时）
+	OperatorID   uint       `json:"operatorId"`                   // 操作的管理员ID，系统自动扣款为0
+	Description  string     `json:"description" gorm:"size:255"`  // 说明
+}
+
+func (BalanceTransaction) TableName() string {
+	return "balance_transactions"
+}
+
+// BeforeUpdate 禁止修改已写入的流水
+func (t *BalanceTransaction) BeforeUpdate(tx *gorm.DB) error {
+	return ErrBalanceTransactionImmutable
+}
+
+// BeforeDelete 禁止删除已写入的流水
+func (t *BalanceTransaction) BeforeDelete(tx *gorm.DB) error {
+	return ErrBalanceTransactionImmutable
+}
@@ -0,0 +1,25 @@
+package user
+
+import "time"
+
+// NotificationSetting 用户通知偏好，默认不接收任何通知，需要用户主动开启
+type NotificationSetting struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID uint `json:"userId" gorm:"uniqueIndex;not null"` // 所属用户
+
+	EmailEnabled    bool   `json:"emailEnabled"`                  // 通过邮件接收，发送到用户绑定的邮箱
+	TelegramEnabled bool   `json:"telegramEnabled"`               // 通过Telegram接收
+	TelegramChatID  string `json:"telegramChatId" gorm:"size:64"` // Telegram Chat ID，需先向Bot发送过消息
+
+	ExpiryReminder bool `json:"expiryReminder"` // 账号、实例、节点到期提醒
+	FrozenNotice   bool `json:"frozenNotice"`   // 冻结通知
+	TrafficNotice  bool `json:"trafficNotice"`  // 流量超限通知
+	TaskFailNotice bool `json:"taskFailNotice"` // 任务失败通知
+}
+
+func (NotificationSetting) TableName() string {
+	return "notification_settings"
+}
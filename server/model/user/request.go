@@ -15,6 +15,7 @@ type ClaimResourceRequest struct {
 type InstanceActionRequest struct {
 	InstanceID uint   `json:"instanceId" binding:"required"`
 	Action     string `json:"action" binding:"required"`
+	ImageID    uint   `json:"imageId"` // 重置时可选的目标系统镜像ID，为空时使用原镜像
 }
 
 type UserInstanceListRequest struct {
@@ -54,6 +55,62 @@ type ResetInstancePasswordRequest struct {
 	// 不需要传递任何参数，由后端自动生成新密码
 }
 
+// ResizeInstanceRequest 调整实例规格请求，未传的规格保持不变
+type ResizeInstanceRequest struct {
+	CPUId    string `json:"cpuId"`    // CPU规格ID
+	MemoryId string `json:"memoryId"` // 内存规格ID
+	DiskId   string `json:"diskId"`   // 磁盘规格ID，只能扩容
+}
+
+// CreateSnapshotRequest 创建实例快照请求
+type CreateSnapshotRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=40"`         // 快照名称，为空时自动生成
+	Description string `json:"description" binding:"omitempty,max=255"` // 快照描述
+}
+
+// SecurityGroupRuleRequest 安全组规则
+// 端口为实例内部端口，tcp/udp的起始端口为0表示全部端口，结束端口为0表示单个端口；来源为空表示任意地址
+type SecurityGroupRuleRequest struct {
+	Action      string `json:"action" binding:"required,oneof=allow deny"`         // 动作
+	Protocol    string `json:"protocol" binding:"required,oneof=tcp udp icmp all"` // 协议
+	PortStart   int    `json:"portStart" binding:"min=0,max=65535"`                // 起始端口
+	PortEnd     int    `json:"portEnd" binding:"min=0,max=65535"`                  // 结束端口
+	Source      string `json:"source" binding:"omitempty,max=64"`                  // 来源IP或CIDR
+	Description string `json:"description" binding:"omitempty,max=255"`            // 说明
+}
+
+// SecurityGroupRequest 创建或更新安全组请求，更新时整体替换规则
+type SecurityGroupRequest struct {
+	Name          string                     `json:"name" binding:"required,max=64"`                     // 名称
+	Description   string                     `json:"description" binding:"omitempty,max=255"`            // 说明
+	DefaultAction string                     `json:"defaultAction" binding:"omitempty,oneof=allow deny"` // 未匹配规则的入站流量，默认allow
+	Rules         []SecurityGroupRuleRequest `json:"rules" binding:"dive"`                               // 规则
+}
+
+// BindSecurityGroupRequest 实例绑定安全组请求
+type BindSecurityGroupRequest struct {
+	SecurityGroupID uint `json:"securityGroupId" binding:"required"` // 安全组ID，必须属于当前用户
+}
+
+// CreateDomainRequest 绑定域名请求
+type CreateDomainRequest struct {
+	Domain       string `json:"domain" binding:"required,max=253"`             // 域名
+	Protocol     string `json:"protocol" binding:"required,oneof=http https"`  // http按Host转发80端口，https按SNI透传443端口
+	TargetPort   int    `json:"targetPort" binding:"required,min=1,max=65535"` // 实例内部端口
+	VerifyMethod string `json:"verifyMethod" binding:"omitempty,oneof=dns"`    // 所有权验证方式，只支持dns
+}
+
+// RestoreBackupRequest 恢复备份请求
+type RestoreBackupRequest struct {
+	TargetInstanceID uint `json:"targetInstanceId" binding:"required"` // 恢复目标实例ID，必须属于当前用户
+}
+
+// BackupScheduleRequest 设置定时备份请求
+type BackupScheduleRequest struct {
+	Enabled       bool `json:"enabled"`                                        // 是否启用
+	IntervalHours int  `json:"intervalHours" binding:"required,min=1,max=720"` // 备份间隔（小时）
+}
+
 // UserTasksRequest 用户任务列表请求
 type UserTasksRequest struct {
 	common.PageInfo
@@ -73,14 +130,18 @@ type SystemImagesRequest struct {
 // CreateInstanceRequest 创建实例请求
 // 安全设计：所有参数都是从后端预定义配置中选择的ID，不允许自定义输入
 // 实例名称由后端根据provider名称自动生成
+// ProviderId 为空时进入自动调度模式，按 Region/Country 和镜像、规格由调度器选择节点
 type CreateInstanceRequest struct {
-	ProviderId  uint   `json:"providerId" binding:"required"`  // 节点ID
+	ProviderId  uint   `json:"providerId"`                     // 节点ID，为空时自动选择
+	Region      string `json:"region"`                         // 自动选择节点时限定的地区
+	Country     string `json:"country"`                        // 自动选择节点时限定的国家或国家代码
 	ImageId     uint   `json:"imageId" binding:"required"`     // 镜像ID（从数据库获取）
 	CPUId       string `json:"cpuId" binding:"required"`       // CPU规格ID
 	MemoryId    string `json:"memoryId" binding:"required"`    // 内存规格ID
 	DiskId      string `json:"diskId" binding:"required"`      // 磁盘规格ID
 	BandwidthId string `json:"bandwidthId" binding:"required"` // 带宽规格ID
 	Description string `json:"description"`                    // 描述信息
+	UserData    string `json:"userData"`                       // 可选的cloud-init user-data或启动脚本
 }
 
 // QuotaCheckRequest 配额检查请求
@@ -92,3 +153,41 @@ type QuotaCheckRequest struct {
 	Disk         int    `json:"disk"`
 	Bandwidth    int    `json:"bandwidth"`
 }
+
+// TwoFactorCodeRequest 提交两步验证码请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 6位验证码，关闭两步验证时也可使用恢复码
+}
+
+// CreateAPITokenRequest 创建API令牌请求
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=64"`         // 令牌名称
+	Scopes        []string `json:"scopes" binding:"required,min=1"`        // 权限范围：read、instances、ports、traffic
+	ExpiresInDays int      `json:"expiresInDays" binding:"min=0,max=3650"` // 有效天数，0表示永不过期
+}
+
+// BalanceTransactionListRequest 余额流水列表请求
+type BalanceTransactionListRequest struct {
+	common.PageInfo
+	InstanceID uint   `json:"instanceId" form:"instanceId"`
+	Type       string `json:"type" form:"type"`
+}
+
+// UpdateNotificationSettingRequest 更新通知设置请求
+type UpdateNotificationSettingRequest struct {
+	EmailEnabled    bool   `json:"emailEnabled"`                    // 通过邮件接收
+	TelegramEnabled bool   `json:"telegramEnabled"`                 // 通过Telegram接收
+	TelegramChatID  string `json:"telegramChatId" binding:"max=64"` // Telegram Chat ID
+	ExpiryReminder  bool   `json:"expiryReminder"`                  // 到期提醒
+	FrozenNotice    bool   `json:"frozenNotice"`                    // 冻结通知
+	TrafficNotice   bool   `json:"trafficNotice"`                   // 流量超限通知
+	TaskFailNotice  bool   `json:"taskFailNotice"`                  // 任务失败通知
+}
+
+// NotificationLogListRequest 通知记录列表请求
+type NotificationLogListRequest struct {
+	common.PageInfo
+	Event   string `json:"event" form:"event"`
+	Channel string `json:"channel" form:"channel"`
+	Status  string `json:"status" form:"status"`
+}
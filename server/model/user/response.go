@@ -3,6 +3,7 @@ package user
 import (
 	"time"
 
+	"oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
 )
 
@@ -146,10 +147,8 @@ type UserInstanceDetailResponse struct {
 
 // InstanceMonitoringResponse 实例监控数据响应
 type InstanceMonitoringResponse struct {
-	// CPUUsage    float64     `json:"cpuUsage"`    // 已移除：硬件资源使用率监控
-	// MemoryUsage float64     `json:"memoryUsage"` // 已移除：硬件资源使用率监控
-	// DiskUsage   float64     `json:"diskUsage"`   // 已移除：硬件资源使用率监控
-	TrafficData TrafficData `json:"trafficData"` // 流量详细数据（基于pmacct）
+	TrafficData  TrafficData                     `json:"trafficData"`  // 流量详细数据（基于pmacct）
+	ResourceData *monitoring.ResourceUsageSeries `json:"resourceData"` // CPU、内存、磁盘使用曲线，未启用采集时为空
 }
 
 // TrafficData 流量数据结构
@@ -273,8 +272,61 @@ type ResetInstancePasswordResponse struct {
 	TaskID uint `json:"taskId"`
 }
 
+// ResizeInstanceResponse 调整实例规格响应
+type ResizeInstanceResponse struct {
+	TaskID uint `json:"taskId"`
+}
+
+// SnapshotTaskResponse 快照操作响应
+type SnapshotTaskResponse struct {
+	TaskID     uint `json:"taskId"`
+	SnapshotID uint `json:"snapshotId"`
+}
+
+// BackupTaskResponse 备份操作响应
+type BackupTaskResponse struct {
+	TaskID   uint `json:"taskId"`
+	BackupID uint `json:"backupId"`
+}
+
 // GetInstancePasswordResponse 获取实例新密码响应
 type GetInstancePasswordResponse struct {
 	NewPassword string `json:"newPassword"`
 	ResetTime   int64  `json:"resetTime"`
 }
+
+// TwoFactorSetupResponse 绑定两步验证响应
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`          // Base32密钥，供无法扫码时手动输入
+	ProvisioningURI string `json:"provisioningUri"` // otpauth:// URI，前端据此生成二维码
+}
+
+// TwoFactorStatusResponse 两步验证状态
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`                // 是否已启用
+	EnabledAt              *time.Time `json:"enabledAt"`              // 启用时间
+	Required               bool       `json:"required"`               // 系统是否要求当前用户启用
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"` // 剩余可用恢复码数量
+}
+
+// TwoFactorRecoveryCodesResponse 恢复码响应（仅在生成时返回一次）
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// CreateAPITokenResponse 创建API令牌响应，明文令牌仅返回一次
+type CreateAPITokenResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// BalanceResponse 用户余额信息，金额单位为分
+type BalanceResponse struct {
+	BillingEnabled      bool   `json:"billingEnabled"`      // 系统是否启用计费
+	Currency            string `json:"currency"`            // 货币代码
+	Balance             int64  `json:"balance"`             // 当前余额
+	TotalRecharged      int64  `json:"totalRecharged"`      // 累计充值
+	TotalCharged        int64  `json:"totalCharged"`        // 累计消费
+	LowBalanceThreshold int64  `json:"lowBalanceThreshold"` // 低余额提醒阈值
+	HourlyCost          int64  `json:"hourlyCost"`          // 当前运行中实例按小时折算的费用
+}
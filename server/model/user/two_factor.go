@@ -0,0 +1,25 @@
+package user
+
+import "time"
+
+// UserTwoFactor 用户TOTP两步验证配置
+type UserTwoFactor struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID        uint       `json:"userId" gorm:"uniqueIndex;not null"` // 用户ID
+	Enabled       bool       `json:"enabled" gorm:"default:false"`       // 是否已启用
+	Secret        string     `json:"-" gorm:"size:64"`                   // 已启用的TOTP密钥（Base32）
+	PendingSecret string     `json:"-" gorm:"size:64"`                   // 绑定中、尚未通过验证码确认的密钥
+	RecoveryCodes string     `json:"-" gorm:"type:text"`                 // 恢复码的bcrypt哈希（JSON数组），每个只能使用一次
+	LastUsedStep  int64      `json:"-" gorm:"default:0"`                 // 最近一次成功使用的时间步，用于拒绝验证码重放
+	EnabledAt     *time.Time `json:"enabledAt"`                          // 启用时间
+
+	FailedAttempts int        `json:"-" gorm:"default:0"` // 连续校验失败次数
+	LockedUntil    *time.Time `json:"-"`                  // 失败次数过多时的锁定截止时间
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
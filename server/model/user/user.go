@@ -31,6 +31,9 @@ type User struct {
 	Level    int    `json:"level" gorm:"default:1;index:idx_level"`   // 用户等级，用于权限控制
 	UserType string `json:"userType" gorm:"default:user;size:16"`     // 用户类型：user, admin, super_admin等
 
+	// 两步验证（密钥等敏感信息存放在 user_two_factors 表）
+	TwoFactorEnabled bool `json:"twoFactorEnabled" gorm:"default:false"` // 是否已启用TOTP两步验证
+
 	// 配额管理（两阶段配额系统）
 	UsedQuota    int `json:"usedQuota" gorm:"default:0"`    // 已确认使用的配额（稳定状态实例）
 	PendingQuota int `json:"pendingQuota" gorm:"default:0"` // 待确认的配额（创建中/重置中实例）
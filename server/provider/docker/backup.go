@@ -0,0 +1,119 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// Docker的备份为 docker commit 后 docker save 得到的镜像归档，
+// 恢复时加载镜像并按目标容器的运行参数重建容器。挂载卷中的数据不包含在备份中。
+
+// backupCommandTimeout 导出/导入命令的默认超时
+const backupCommandTimeout = 2 * time.Hour
+
+// backupImageRepo 备份镜像仓库名（镜像仓库名必须为小写）
+func backupImageRepo(instanceID string) string {
+	return fmt.Sprintf("oneclickvirt-backup/%s", strings.ToLower(instanceID))
+}
+
+// ExportInstance 将容器导出为镜像归档
+func (d *DockerProvider) ExportInstance(ctx context.Context, instanceID, exportDir string) (string, error) {
+	if !d.connected {
+		return "", fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return "", fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	tarPath := fmt.Sprintf("%s/%s-%s.tar", strings.TrimRight(exportDir, "/"), instanceID, time.Now().Format("20060102150405"))
+	archivePath := tarPath + ".gz"
+	if _, err := d.sshClient.Execute(fmt.Sprintf("mkdir -p %s", exportDir)); err != nil {
+		return "", fmt.Errorf("创建导出目录失败: %w", err)
+	}
+
+	exportRef := fmt.Sprintf("%s:export", backupImageRepo(instanceID))
+	if output, err := d.sshClient.Execute(fmt.Sprintf("docker commit --pause=true %s %s", instanceID, exportRef)); err != nil {
+		return "", fmt.Errorf("failed to commit container: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	// 临时镜像只用于导出
+	defer d.sshClient.Execute(fmt.Sprintf("docker rmi %s 2>/dev/null || true", exportRef))
+
+	cmd := fmt.Sprintf("docker save -o %s %s && gzip -f %s", tarPath, exportRef, tarPath)
+	if output, err := d.sshClient.ExecuteWithTimeout(cmd, utils.TimeoutFromContext(ctx, backupCommandTimeout)); err != nil {
+		d.sshClient.Execute(fmt.Sprintf("rm -f %s %s", tarPath, archivePath))
+		return "", fmt.Errorf("导出容器镜像失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功导出Docker容器",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("archive", archivePath))
+	return archivePath, nil
+}
+
+// ImportInstance 加载镜像归档并重建容器
+func (d *DockerProvider) ImportInstance(ctx context.Context, instanceID, archivePath string) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	output, err := d.sshClient.ExecuteWithTimeout(fmt.Sprintf("docker load -i %s", archivePath), utils.TimeoutFromContext(ctx, backupCommandTimeout))
+	if err != nil {
+		return fmt.Errorf("加载备份镜像失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	var loadedRef string
+	for _, line := range strings.Split(output, "\n") {
+		if ref, found := strings.CutPrefix(strings.TrimSpace(line), "Loaded image:"); found {
+			loadedRef = strings.TrimSpace(ref)
+		}
+	}
+	if loadedRef == "" {
+		return fmt.Errorf("无法识别加载的备份镜像, output: %s", utils.TruncateString(output, 200))
+	}
+
+	currentRef := fmt.Sprintf("%s:current", backupImageRepo(instanceID))
+	if _, err := d.sshClient.Execute(fmt.Sprintf("docker tag %s %s", loadedRef, currentRef)); err != nil {
+		return fmt.Errorf("标记备份镜像失败: %w", err)
+	}
+	if loadedRef != currentRef {
+		d.sshClient.Execute(fmt.Sprintf("docker rmi %s 2>/dev/null || true", loadedRef))
+	}
+
+	if err := d.recreateContainerWithImage(instanceID, currentRef); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("通过SSH成功导入Docker容器",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("archive", archivePath))
+	return nil
+}
+
+// DownloadFile 从节点下载文件
+func (d *DockerProvider) DownloadFile(ctx context.Context, remotePath string, w io.Writer) (int64, error) {
+	if d.sshClient == nil {
+		return 0, fmt.Errorf("SSH连接不可用，无法传输文件")
+	}
+	return d.sshClient.DownloadToWriter(remotePath, w)
+}
+
+// UploadFile 上传文件到节点
+func (d *DockerProvider) UploadFile(ctx context.Context, r io.Reader, remotePath string) error {
+	if d.sshClient == nil {
+		return fmt.Errorf("SSH连接不可用，无法传输文件")
+	}
+	return d.sshClient.UploadFromReader(r, remotePath, 0600)
+}
@@ -57,6 +57,7 @@ func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig
 	}
 
 	sshConfig := utils.SSHConfig{
+		ProviderID:     config.ID,
 		Host:           config.Host,
 		Port:           config.Port,
 		Username:       config.Username,
@@ -75,6 +76,8 @@ func (d *DockerProvider) Connect(ctx context.Context, config provider.NodeConfig
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
+		ProviderID:    config.ID,
+		ProviderName:  config.Name,
 		Host:          config.Host,
 		Port:          config.Port,
 		Username:      config.Username,
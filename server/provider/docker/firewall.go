@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// firewallChain 容器专用的iptables链名，链名最长28个字符，使用容器名的哈希
+func firewallChain(instanceName string) string {
+	sum := sha1.Sum([]byte(instanceName))
+	return "OCV-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// buildFirewallScript 生成设置容器防火墙的shell脚本
+// 每个地址族一条专用链，由DOCKER-USER按容器IP跳转；链内依次为：已建立连接放行、拒绝规则、允许规则、默认动作。
+// 允许使用RETURN返回DOCKER-USER，由Docker自身的规则完成转发
+func buildFirewallScript(chain string, ipv4, ipv6 string, policy *provider.FirewallPolicy) string {
+	var b strings.Builder
+	b.WriteString("set -e\n")
+
+	families := []struct {
+		cmd, addr, icmp string
+		isV6            bool
+	}{
+		{"iptables", ipv4, "icmp", false},
+		{"ip6tables", ipv6, "ipv6-icmp", true},
+	}
+	for _, f := range families {
+		// 清理旧的跳转规则和链，容器重建后IP可能变化
+		fmt.Fprintf(&b, "%s -S DOCKER-USER 2>/dev/null | grep -- '-j %s$' | sed 's/^-A/-D/' | while read -r rule; do %s $rule; done\n", f.cmd, chain, f.cmd)
+		fmt.Fprintf(&b, "%s -F %s 2>/dev/null || true\n", f.cmd, chain)
+		fmt.Fprintf(&b, "%s -X %s 2>/dev/null || true\n", f.cmd, chain)
+		if policy == nil || f.addr == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s -N %s\n", f.cmd, chain)
+		fmt.Fprintf(&b, "%s -A %s -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN\n", f.cmd, chain)
+		for _, action := range []string{"deny", "allow"} {
+			target := "RETURN"
+			if action == "deny" {
+				target = "DROP"
+			}
+			for _, r := range policy.Rules {
+				if r.Action != action || (r.Source != "" && strings.Contains(r.Source, ":") != f.isV6) {
+					continue
+				}
+				rule := fmt.Sprintf("%s -A %s", f.cmd, chain)
+				switch r.Protocol {
+				case "tcp", "udp":
+					rule += " -p " + r.Protocol
+					if r.PortStart > 0 && r.PortEnd > r.PortStart {
+						rule += fmt.Sprintf(" --dport %d:%d", r.PortStart, r.PortEnd)
+					} else if r.PortStart > 0 {
+						rule += fmt.Sprintf(" --dport %d", r.PortStart)
+					}
+				case "icmp":
+					rule += " -p " + f.icmp
+				}
+				if r.Source != "" {
+					rule += " -s " + r.Source
+				}
+				b.WriteString(rule + " -j " + target + "\n")
+			}
+		}
+		if policy.DefaultAction == "deny" {
+			fmt.Fprintf(&b, "%s -A %s -j DROP\n", f.cmd, chain)
+		}
+		fmt.Fprintf(&b, "%s -I DOCKER-USER -d %s -j %s\n", f.cmd, f.addr, chain)
+	}
+	return b.String()
+}
+
+// ApplyFirewall 为容器设置入站防火墙
+// 规则写在宿主机的DOCKER-USER链中，宿主机重启或容器IP变化后需要重新应用
+func (d *DockerProvider) ApplyFirewall(ctx context.Context, instanceID string, policy provider.FirewallPolicy) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	output, err := d.sshClient.Execute(fmt.Sprintf("docker inspect -f '{{range .NetworkSettings.Networks}}{{.IPAddress}} {{.GlobalIPv6Address}}{{end}}' %s", instanceID))
+	if err != nil {
+		return fmt.Errorf("获取容器IP失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	fields := strings.Fields(output)
+	var ipv4, ipv6 string
+	for _, addr := range fields {
+		if strings.Contains(addr, ":") {
+			if ipv6 == "" {
+				ipv6 = addr
+			}
+		} else if ipv4 == "" {
+			ipv4 = addr
+		}
+	}
+	if ipv4 == "" && ipv6 == "" {
+		return fmt.Errorf("容器没有可用的IP地址")
+	}
+
+	if err := d.runFirewallScript(instanceID, buildFirewallScript(firewallChain(instanceID), ipv4, ipv6, &policy)); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("通过SSH成功设置Docker容器防火墙",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("ipv4", ipv4),
+		zap.String("ipv6", ipv6),
+		zap.Int("rules", len(policy.Rules)),
+		zap.String("defaultAction", policy.DefaultAction))
+	return nil
+}
+
+// RemoveFirewall 删除容器的防火墙链和跳转规则
+func (d *DockerProvider) RemoveFirewall(ctx context.Context, instanceID string) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	return d.runFirewallScript(instanceID, buildFirewallScript(firewallChain(instanceID), "", "", nil))
+}
+
+// runFirewallScript 上传并执行防火墙脚本
+func (d *DockerProvider) runFirewallScript(instanceID, script string) error {
+	tmpFile := fmt.Sprintf("/tmp/oneclickvirt-fw-%s.sh", instanceID)
+	if err := d.sshClient.UploadContent(script, tmpFile, 0700); err != nil {
+		return fmt.Errorf("上传防火墙脚本失败: %w", err)
+	}
+	defer d.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpFile))
+
+	if output, err := d.sshClient.Execute(fmt.Sprintf("bash %s", tmpFile)); err != nil {
+		return fmt.Errorf("设置容器防火墙失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return nil
+}
@@ -465,7 +465,19 @@ func (d *DockerProvider) sshCreateInstanceWithProgress(ctx context.Context, conf
 		cmd += fmt.Sprintf(" -e %s=%s", key, value)
 	}
 
+	// 用户提供的启动脚本通过包装入口执行，执行完毕后继续运行镜像原有的入口命令
+	entrypointArgs := ""
+	if config.UserData != "" {
+		runArgs, originalArgs, err := d.prepareUserDataEntrypoint(config.Name, imageNameWithPrefix, config.UserData)
+		if err != nil {
+			return fmt.Errorf("准备user-data启动脚本失败: %w", err)
+		}
+		cmd += runArgs
+		entrypointArgs = originalArgs
+	}
+
 	cmd += fmt.Sprintf(" %s", imageNameWithPrefix)
+	cmd += entrypointArgs
 
 	updateProgress(95, "执行Docker创建命令...")
 	global.APP_LOG.Info("开始执行Docker创建命令",
@@ -827,6 +839,7 @@ func (d *DockerProvider) sshDeleteInstance(ctx context.Context, id string) error
 			// 如果所有命令都成功执行，验证容器是否真的被删除
 			if success {
 				if d.verifyContainerDeleted(ctx, id) {
+					d.cleanupUserData(id)
 					global.APP_LOG.Info("Docker实例删除成功",
 						zap.String("id", utils.TruncateString(id, 32)),
 						zap.String("strategy", strategy.name),
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// dockerStatsLine docker stats --format '{{json .}}' 的单行输出
+type dockerStatsLine struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`  // 如 "12.34%"，100%表示占满一个核心
+	MemUsage string `json:"MemUsage"` // 如 "120.5MiB / 1GiB"
+}
+
+// GetInstancesUsage 批量获取所有运行中容器的资源使用情况
+// docker stats 不提供容器磁盘使用量，磁盘相关字段为0
+func (d *DockerProvider) GetInstancesUsage(ctx context.Context) ([]provider.InstanceUsage, error) {
+	if !d.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	// Docker provider只支持SSH，检查执行规则
+	if d.config.ExecutionRule == "api_only" {
+		return nil, fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	output, err := d.sshClient.Execute("docker stats --no-stream --format '{{json .}}'")
+	if err != nil {
+		return nil, fmt.Errorf("获取容器资源使用情况失败: %w", err)
+	}
+
+	var usages []provider.InstanceUsage
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var stats dockerStatsLine
+		if err := json.Unmarshal([]byte(line), &stats); err != nil {
+			global.APP_LOG.Debug("跳过无法解析的docker stats输出", zap.String("line", line), zap.Error(err))
+			continue
+		}
+
+		usage := provider.InstanceUsage{Name: stats.Name, ID: stats.Name}
+		usage.CPUPercent, _ = strconv.ParseFloat(strings.TrimSuffix(stats.CPUPerc, "%"), 64)
+		if used, total, ok := strings.Cut(stats.MemUsage, "/"); ok {
+			usage.MemoryUsedMB = parseDockerSize(used) / 1024 / 1024
+			usage.MemoryTotalMB = parseDockerSize(total) / 1024 / 1024
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// parseDockerSize 解析docker输出的容量（如 "1.5GiB"、"512kB"），返回字节数，无法解析时返回0
+func parseDockerSize(s string) int64 {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+		{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"KB", 1e3}, {"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(value * u.factor)
+		}
+	}
+	return 0
+}
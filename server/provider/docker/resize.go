@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstance 调整容器的CPU和内存限制（docker update）
+// 容器的存储大小在创建时由存储驱动确定，不支持在线扩容
+func (d *DockerProvider) ResizeInstance(ctx context.Context, instanceID string, spec provider.ResizeSpec) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	if spec.DiskMB > 0 {
+		return fmt.Errorf("Docker容器不支持调整磁盘大小")
+	}
+
+	if spec.CPU <= 0 && spec.MemoryMB <= 0 {
+		return nil
+	}
+
+	cmd := "docker update"
+	if spec.CPU > 0 {
+		cmd += fmt.Sprintf(" --cpus=%d", spec.CPU)
+	}
+	if spec.MemoryMB > 0 {
+		// 创建时未指定 --memory-swap，Docker默认交换上限为内存的两倍，这里保持一致；
+		// 同时设置两者可避免扩容内存时超过原有交换上限而失败
+		cmd += fmt.Sprintf(" --memory=%dm --memory-swap=%dm", spec.MemoryMB, spec.MemoryMB*2)
+	}
+	cmd += " " + instanceID
+
+	if output, err := d.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("failed to update container: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功调整Docker容器规格",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.Int("cpu", spec.CPU),
+		zap.Int64("memoryMB", spec.MemoryMB))
+	return nil
+}
@@ -0,0 +1,288 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// Docker没有原生快照，这里通过 docker commit 将容器文件系统保存为镜像来实现快照，
+// 恢复时使用快照镜像按原容器的运行参数重建容器。
+
+// snapshotCurrentTag 恢复后容器实际使用的镜像标签
+// 容器不直接引用快照标签，这样删除快照时只会移除标签而不会因镜像被占用而失败
+const snapshotCurrentTag = "current"
+
+// snapshotImageRepo 快照镜像仓库名（镜像仓库名必须为小写）
+func snapshotImageRepo(instanceID string) string {
+	return fmt.Sprintf("oneclickvirt-snapshot/%s", strings.ToLower(instanceID))
+}
+
+// snapshotImageRef 快照镜像完整引用
+func snapshotImageRef(instanceID, snapshotName string) string {
+	return fmt.Sprintf("%s:%s", snapshotImageRepo(instanceID), strings.ToLower(snapshotName))
+}
+
+// CreateSnapshot 创建容器快照（docker commit）
+func (d *DockerProvider) CreateSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// Docker provider只支持SSH，检查执行规则
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	imageRef := snapshotImageRef(instanceID, snapshotName)
+	cmd := fmt.Sprintf("docker commit --pause=true %s %s", instanceID, imageRef)
+	output, err := d.sshClient.Execute(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to commit container: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功创建Docker快照",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("image", imageRef))
+	return nil
+}
+
+// ListSnapshots 列出容器快照
+func (d *DockerProvider) ListSnapshots(ctx context.Context, instanceID string) ([]provider.Snapshot, error) {
+	if !d.connected {
+		return nil, fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return nil, fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	cmd := fmt.Sprintf("docker images %s --format '{{.Tag}}|{{.CreatedAt}}|{{.Size}}|{{.ID}}'", snapshotImageRepo(instanceID))
+	output, err := d.sshClient.Execute(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []provider.Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) < 4 || parts[0] == "" || parts[0] == "<none>" || parts[0] == snapshotCurrentTag {
+			continue
+		}
+
+		// CreatedAt 格式示例：2024-01-01 10:00:00 +0800 CST
+		created, _ := time.Parse("2006-01-02 15:04:05 -0700 MST", parts[1])
+		snapshots = append(snapshots, provider.Snapshot{
+			Name:    parts[0],
+			Created: created,
+			Size:    parts[2],
+			Metadata: map[string]string{
+				"imageId": parts[3],
+			},
+		})
+	}
+
+	return snapshots, nil
+}
+
+// dockerContainerInspect 重建容器所需的 docker inspect 字段
+type dockerContainerInspect struct {
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	Config struct {
+		Hostname string `json:"Hostname"`
+	} `json:"Config"`
+	HostConfig struct {
+		NanoCpus     int64             `json:"NanoCpus"`
+		Memory       int64             `json:"Memory"`
+		Binds        []string          `json:"Binds"`
+		CapAdd       []string          `json:"CapAdd"`
+		NetworkMode  string            `json:"NetworkMode"`
+		Privileged   bool              `json:"Privileged"`
+		StorageOpt   map[string]string `json:"StorageOpt"`
+		PortBindings map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"PortBindings"`
+		RestartPolicy struct {
+			Name string `json:"Name"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+}
+
+// buildRunCommandFromInspect 根据原容器的运行参数构建使用快照镜像的 docker run 命令
+func buildRunCommandFromInspect(name, imageRef string, info *dockerContainerInspect) string {
+	cmd := fmt.Sprintf("docker run -d --name %s", name)
+
+	if info.Config.Hostname != "" {
+		cmd += fmt.Sprintf(" --hostname %s", info.Config.Hostname)
+	}
+	if info.HostConfig.NetworkMode != "" && info.HostConfig.NetworkMode != "default" && info.HostConfig.NetworkMode != "bridge" {
+		cmd += fmt.Sprintf(" --network=%s", info.HostConfig.NetworkMode)
+	}
+	if info.HostConfig.NanoCpus > 0 {
+		cmd += fmt.Sprintf(" --cpus=%g", float64(info.HostConfig.NanoCpus)/1e9)
+	}
+	if info.HostConfig.Memory > 0 {
+		cmd += fmt.Sprintf(" --memory=%db", info.HostConfig.Memory)
+	}
+	for key, value := range info.HostConfig.StorageOpt {
+		cmd += fmt.Sprintf(" --storage-opt %s=%s", key, value)
+	}
+	if info.HostConfig.Privileged {
+		cmd += " --privileged"
+	}
+	if info.HostConfig.RestartPolicy.Name != "" && info.HostConfig.RestartPolicy.Name != "no" {
+		cmd += fmt.Sprintf(" --restart=%s", info.HostConfig.RestartPolicy.Name)
+	}
+
+	// 端口映射保持稳定顺序，便于排查
+	containerPorts := make([]string, 0, len(info.HostConfig.PortBindings))
+	for containerPort := range info.HostConfig.PortBindings {
+		containerPorts = append(containerPorts, containerPort)
+	}
+	sort.Strings(containerPorts)
+	for _, containerPort := range containerPorts {
+		for _, binding := range info.HostConfig.PortBindings[containerPort] {
+			hostIP := binding.HostIP
+			if hostIP == "" {
+				hostIP = "0.0.0.0"
+			}
+			cmd += fmt.Sprintf(" -p %s:%s:%s", hostIP, binding.HostPort, containerPort)
+		}
+	}
+
+	for _, bind := range info.HostConfig.Binds {
+		cmd += fmt.Sprintf(" -v %s", bind)
+	}
+	for _, capability := range info.HostConfig.CapAdd {
+		cmd += fmt.Sprintf(" --cap-add=%s", capability)
+	}
+
+	return cmd + " " + imageRef
+}
+
+// RestoreSnapshot 使用快照镜像重建容器
+func (d *DockerProvider) RestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	imageRef := snapshotImageRef(instanceID, snapshotName)
+	if !d.imageExists(imageRef) {
+		return fmt.Errorf("快照镜像 %s 不存在", imageRef)
+	}
+
+	currentRef := fmt.Sprintf("%s:%s", snapshotImageRepo(instanceID), snapshotCurrentTag)
+	if _, err := d.sshClient.Execute(fmt.Sprintf("docker tag %s %s", imageRef, currentRef)); err != nil {
+		return fmt.Errorf("标记快照镜像失败: %w", err)
+	}
+
+	if err := d.recreateContainerWithImage(instanceID, currentRef); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("通过SSH成功恢复Docker快照",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("image", imageRef))
+	return nil
+}
+
+// recreateContainerWithImage 保持原容器的运行参数，使用指定镜像重建容器
+// 重建失败时还原原容器，原容器处于停止状态时重建后也保持停止
+func (d *DockerProvider) recreateContainerWithImage(instanceID, imageRef string) error {
+	// 读取原容器的运行参数
+	output, err := d.sshClient.Execute(fmt.Sprintf("docker inspect --format '{{json .}}' %s", instanceID))
+	if err != nil {
+		return fmt.Errorf("获取容器配置失败: %w", err)
+	}
+	var info dockerContainerInspect
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &info); err != nil {
+		return fmt.Errorf("解析容器配置失败: %w", err)
+	}
+
+	runCmd := buildRunCommandFromInspect(instanceID, imageRef, &info)
+	backupName := fmt.Sprintf("%s-snapshot-bak", instanceID)
+
+	// 先停止并重命名原容器，重建失败时可以还原
+	d.sshClient.Execute(fmt.Sprintf("docker rm -f %s 2>/dev/null || true", backupName))
+	if _, err := d.sshClient.Execute(fmt.Sprintf("docker stop %s", instanceID)); err != nil {
+		return fmt.Errorf("停止原容器失败: %w", err)
+	}
+	if _, err := d.sshClient.Execute(fmt.Sprintf("docker rename %s %s", instanceID, backupName)); err != nil {
+		if info.State.Running {
+			d.sshClient.Execute(fmt.Sprintf("docker start %s", instanceID))
+		}
+		return fmt.Errorf("重命名原容器失败: %w", err)
+	}
+
+	global.APP_LOG.Info("使用镜像重建容器",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("image", imageRef),
+		zap.String("command", utils.TruncateString(runCmd, 500)))
+
+	if runOutput, err := d.sshClient.Execute(runCmd); err != nil {
+		// 重建失败，还原原容器
+		d.sshClient.Execute(fmt.Sprintf("docker rm -f %s 2>/dev/null || true", instanceID))
+		d.sshClient.Execute(fmt.Sprintf("docker rename %s %s", backupName, instanceID))
+		if info.State.Running {
+			d.sshClient.Execute(fmt.Sprintf("docker start %s", instanceID))
+		}
+		return fmt.Errorf("重建容器失败: %w, output: %s", err, utils.TruncateString(runOutput, 200))
+	}
+
+	// 重建成功后删除备份容器
+	if _, err := d.sshClient.Execute(fmt.Sprintf("docker rm -f %s", backupName)); err != nil {
+		global.APP_LOG.Warn("删除重建前的备份容器失败",
+			zap.String("backup", backupName),
+			zap.Error(err))
+	}
+
+	// 原容器处于停止状态时，重建后也保持停止
+	if !info.State.Running {
+		d.sshClient.Execute(fmt.Sprintf("docker stop %s", instanceID))
+	}
+	return nil
+}
+
+// DeleteSnapshot 删除容器快照镜像
+func (d *DockerProvider) DeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !d.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if d.config.ExecutionRule == "api_only" {
+		return fmt.Errorf("Docker provider不支持API调用，无法使用api_only执行规则")
+	}
+
+	imageRef := snapshotImageRef(instanceID, snapshotName)
+	output, err := d.sshClient.Execute(fmt.Sprintf("docker rmi %s", imageRef))
+	if err != nil {
+		// 镜像不存在，视为删除成功
+		if strings.Contains(output, "No such image") {
+			global.APP_LOG.Info("快照镜像已不存在，视为删除成功",
+				zap.String("image", imageRef))
+			return nil
+		}
+		return fmt.Errorf("failed to delete snapshot image: %w", err)
+	}
+
+	global.APP_LOG.Info("通过SSH成功删除Docker快照",
+		zap.String("id", utils.TruncateString(instanceID, 32)),
+		zap.String("image", imageRef))
+	return nil
+}
@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	userDataHostDir      = "/root/oneclickvirt_userdata" // 节点上存放容器启动脚本的目录
+	userDataContainerDir = "/oneclickvirt"               // 容器内挂载点
+)
+
+// userDataEntrypointScript 容器入口包装脚本
+// 首次启动时执行用户脚本（输出写入日志），之后exec镜像原有的入口命令
+const userDataEntrypointScript = `#!/bin/sh
+if [ ! -f /var/lib/oneclickvirt-user-data.done ]; then
+	%s > /var/log/oneclickvirt-user-data.log 2>&1
+	mkdir -p /var/lib && touch /var/lib/oneclickvirt-user-data.done
+fi
+exec "$@"
+`
+
+// prepareUserDataEntrypoint 将用户脚本和入口包装脚本上传到节点
+// 返回需要追加到 docker run 镜像名之前的参数，以及镜像名之后的原入口命令参数
+func (d *DockerProvider) prepareUserDataEntrypoint(containerName, image, userData string) (string, string, error) {
+	entrypoint, cmd, err := d.getImageEntrypoint(image)
+	if err != nil {
+		return "", "", err
+	}
+	originalArgs := append(entrypoint, cmd...)
+	if len(originalArgs) == 0 {
+		return "", "", fmt.Errorf("镜像 %s 未定义入口命令", image)
+	}
+
+	hostDir := fmt.Sprintf("%s/%s", userDataHostDir, containerName)
+	if err := d.sshClient.UploadContent(userData, hostDir+"/user-data", 0755); err != nil {
+		return "", "", fmt.Errorf("上传启动脚本失败: %w", err)
+	}
+
+	// 有shebang时直接执行，否则使用sh解释
+	runUserData := "sh " + userDataContainerDir + "/user-data"
+	if strings.HasPrefix(userData, "#!") {
+		runUserData = userDataContainerDir + "/user-data"
+	}
+	if err := d.sshClient.UploadContent(fmt.Sprintf(userDataEntrypointScript, runUserData), hostDir+"/entrypoint.sh", 0755); err != nil {
+		return "", "", fmt.Errorf("上传入口脚本失败: %w", err)
+	}
+
+	runArgs := fmt.Sprintf(" -v %s:%s:ro --entrypoint %s/entrypoint.sh", hostDir, userDataContainerDir, userDataContainerDir)
+
+	quoted := make([]string, 0, len(originalArgs))
+	for _, arg := range originalArgs {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	global.APP_LOG.Info("已准备容器启动脚本",
+		zap.String("name", utils.TruncateString(containerName, 32)),
+		zap.Int("size", len(userData)),
+		zap.Strings("originalEntrypoint", originalArgs))
+
+	return runArgs, " " + strings.Join(quoted, " "), nil
+}
+
+// getImageEntrypoint 获取镜像原有的 Entrypoint 和 Cmd
+func (d *DockerProvider) getImageEntrypoint(image string) ([]string, []string, error) {
+	output, err := d.sshClient.Execute(fmt.Sprintf("docker image inspect %s --format '{{json .Config.Entrypoint}}|{{json .Config.Cmd}}'", image))
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取镜像入口命令失败: %w", err)
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(output), "|", 2)
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("无法解析镜像入口命令: %s", output)
+	}
+
+	var entrypoint, cmd []string
+	if err := json.Unmarshal([]byte(parts[0]), &entrypoint); err != nil {
+		return nil, nil, fmt.Errorf("解析Entrypoint失败: %w", err)
+	}
+	if err := json.Unmarshal([]byte(parts[1]), &cmd); err != nil {
+		return nil, nil, fmt.Errorf("解析Cmd失败: %w", err)
+	}
+	return entrypoint, cmd, nil
+}
+
+// cleanupUserData 删除容器的启动脚本目录
+func (d *DockerProvider) cleanupUserData(containerName string) {
+	if containerName == "" || strings.ContainsAny(containerName, "/. ") {
+		return
+	}
+	if _, err := d.sshClient.Execute(fmt.Sprintf("rm -rf %s/%s", userDataHostDir, containerName)); err != nil {
+		global.APP_LOG.Debug("清理容器启动脚本失败（可忽略）",
+			zap.String("name", utils.TruncateString(containerName, 32)),
+			zap.Error(err))
+	}
+}
+
+// shellQuote 使用单引号转义shell参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -192,7 +192,7 @@ func (d *DockerHealthChecker) checkSSH(ctx context.Context) error {
 	config := &ssh.ClientConfig{
 		User:            d.config.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: utils.ProviderHostKeyCallback(d.config.ProviderID),
 		Timeout:         d.config.Timeout,
 	}
 
@@ -171,7 +171,7 @@ func (i *IncusHealthChecker) checkSSH(ctx context.Context) error {
 	config := &ssh.ClientConfig{
 		User:            i.config.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: utils.ProviderHostKeyCallback(i.config.ProviderID),
 		Timeout:         i.config.Timeout,
 	}
 
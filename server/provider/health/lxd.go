@@ -171,7 +171,7 @@ func (l *LXDHealthChecker) checkSSH(ctx context.Context) error {
 	config := &ssh.ClientConfig{
 		User:            l.config.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: utils.ProviderHostKeyCallback(l.config.ProviderID),
 		Timeout:         l.config.Timeout,
 	}
 
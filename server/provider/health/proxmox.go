@@ -171,7 +171,7 @@ func (p *ProxmoxHealthChecker) checkSSH(ctx context.Context) error {
 	config := &ssh.ClientConfig{
 		User:            p.config.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: utils.ProviderHostKeyCallback(p.config.ProviderID),
 		Timeout:         p.config.Timeout,
 	}
 
@@ -391,7 +391,7 @@ func (phc *ProviderHealthChecker) GetSystemResourceInfoWithKey(ctx context.Conte
 	config := &ssh.ClientConfig{
 		User:            localUsername,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: utils.ProviderHostKeyCallback(localProviderID),
 		Timeout:         30 * time.Second,
 	}
 
@@ -0,0 +1,184 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// backupCommandTimeout 导出/导入命令的默认超时
+const backupCommandTimeout = 2 * time.Hour
+
+// ExportInstance 将实例导出为归档文件（incus export）
+func (i *IncusProvider) ExportInstance(ctx context.Context, instanceID, exportDir string) (string, error) {
+	if !i.connected {
+		return "", fmt.Errorf("provider not connected")
+	}
+
+	// 导出/导入需要在节点本地生成文件，只通过SSH进行
+	if !i.shouldUseSSH() {
+		return "", fmt.Errorf("执行规则不允许使用SSH，无法导出实例")
+	}
+
+	archivePath := fmt.Sprintf("%s/%s-%s.tar.gz", strings.TrimRight(exportDir, "/"), instanceID, time.Now().Format("20060102150405"))
+	if _, err := i.sshClient.Execute(fmt.Sprintf("mkdir -p %s", exportDir)); err != nil {
+		return "", fmt.Errorf("创建导出目录失败: %w", err)
+	}
+
+	cmd := fmt.Sprintf("incus export %s %s --instance-only", instanceID, archivePath)
+	output, err := i.sshClient.ExecuteWithTimeout(cmd, utils.TimeoutFromContext(ctx, backupCommandTimeout))
+	if err != nil {
+		i.sshClient.Execute(fmt.Sprintf("rm -f %s", archivePath))
+		return "", fmt.Errorf("导出实例失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功导出Incus实例",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("archive", archivePath))
+	return archivePath, nil
+}
+
+// ImportInstance 使用归档文件覆盖恢复实例（incus import）
+// 保留目标实例原有的设备和资源配置，使恢复到其他实例/节点时网络和端口映射不受影响
+func (i *IncusProvider) ImportInstance(ctx context.Context, instanceID, archivePath string) error {
+	if !i.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法导入实例")
+	}
+
+	statusOutput, _ := i.sshClient.Execute(fmt.Sprintf("incus info %s | grep \"Status:\" | awk '{print $2}'", instanceID))
+	wasRunning := strings.EqualFold(strings.TrimSpace(statusOutput), "running")
+
+	backupName := fmt.Sprintf("%s-restore-bak", instanceID)
+	i.sshClient.Execute(fmt.Sprintf("incus delete %s --force 2>/dev/null || true", backupName))
+	i.sshClient.Execute(fmt.Sprintf("incus stop %s --force 2>/dev/null || true", instanceID))
+	if output, err := i.sshClient.Execute(fmt.Sprintf("incus move %s %s", instanceID, backupName)); err != nil {
+		if wasRunning {
+			i.sshClient.Execute(fmt.Sprintf("incus start %s", instanceID))
+		}
+		return fmt.Errorf("重命名原实例失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	// rollback 导入失败时还原原实例
+	rollback := func() {
+		i.sshClient.Execute(fmt.Sprintf("incus delete %s --force 2>/dev/null || true", instanceID))
+		i.sshClient.Execute(fmt.Sprintf("incus move %s %s", backupName, instanceID))
+		if wasRunning {
+			i.sshClient.Execute(fmt.Sprintf("incus start %s", instanceID))
+		}
+	}
+
+	cmd := fmt.Sprintf("incus import %s %s", archivePath, instanceID)
+	if output, err := i.sshClient.ExecuteWithTimeout(cmd, utils.TimeoutFromContext(ctx, backupCommandTimeout)); err != nil {
+		rollback()
+		return fmt.Errorf("导入实例失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	if err := i.sshApplyRestoredConfig(instanceID, backupName); err != nil {
+		rollback()
+		return fmt.Errorf("恢复实例配置失败: %w", err)
+	}
+
+	if _, err := i.sshClient.Execute(fmt.Sprintf("incus delete %s --force", backupName)); err != nil {
+		global.APP_LOG.Warn("删除恢复前的备份实例失败",
+			zap.String("backup", backupName),
+			zap.Error(err))
+	}
+
+	if wasRunning {
+		if _, err := i.sshClient.Execute(fmt.Sprintf("incus start %s", instanceID)); err != nil {
+			global.APP_LOG.Warn("恢复后启动实例失败",
+				zap.String("id", instanceID),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功导入Incus实例",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("archive", archivePath))
+	return nil
+}
+
+// sshApplyRestoredConfig 将原实例的设备、profile和非volatile配置应用到导入后的实例
+// 镜像信息（image.*）和文件系统相关的volatile配置保持为归档中的值，网卡MAC沿用原实例
+func (i *IncusProvider) sshApplyRestoredConfig(instanceID, originalName string) error {
+	var original, imported map[string]interface{}
+	for name, target := range map[string]*map[string]interface{}{originalName: &original, instanceID: &imported} {
+		output, err := i.sshClient.Execute(fmt.Sprintf("incus query /1.0/instances/%s", name))
+		if err != nil {
+			return fmt.Errorf("获取实例 %s 配置失败: %w", name, err)
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(output)), target); err != nil {
+			return fmt.Errorf("解析实例 %s 配置失败: %w", name, err)
+		}
+	}
+
+	config := make(map[string]interface{})
+	if importedConfig, ok := imported["config"].(map[string]interface{}); ok {
+		for key, value := range importedConfig {
+			if strings.HasPrefix(key, "image.") || strings.HasPrefix(key, "volatile.") {
+				config[key] = value
+			}
+		}
+	}
+	if originalConfig, ok := original["config"].(map[string]interface{}); ok {
+		for key, value := range originalConfig {
+			if !strings.HasPrefix(key, "image.") && !strings.HasPrefix(key, "volatile.") {
+				config[key] = value
+			} else if strings.HasSuffix(key, ".hwaddr") {
+				config[key] = value
+			}
+		}
+	}
+
+	update := map[string]interface{}{
+		"architecture": imported["architecture"],
+		"config":       config,
+		"devices":      original["devices"],
+		"profiles":     original["profiles"],
+		"ephemeral":    imported["ephemeral"],
+		"description":  imported["description"],
+	}
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := fmt.Sprintf("/tmp/oneclickvirt-restore-%s.json", instanceID)
+	if err := i.sshClient.UploadContent(string(data), tmpFile, 0600); err != nil {
+		return fmt.Errorf("上传实例配置失败: %w", err)
+	}
+	defer i.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpFile))
+
+	if output, err := i.sshClient.Execute(fmt.Sprintf("incus query -X PUT --data \"$(cat %s)\" /1.0/instances/%s", tmpFile, instanceID)); err != nil {
+		return fmt.Errorf("%w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return nil
+}
+
+// DownloadFile 从节点下载文件
+func (i *IncusProvider) DownloadFile(ctx context.Context, remotePath string, w io.Writer) (int64, error) {
+	if !i.shouldUseSSH() {
+		return 0, fmt.Errorf("执行规则不允许使用SSH，无法传输文件")
+	}
+	return i.sshClient.DownloadToWriter(remotePath, w)
+}
+
+// UploadFile 上传文件到节点
+func (i *IncusProvider) UploadFile(ctx context.Context, r io.Reader, remotePath string) error {
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法传输文件")
+	}
+	return i.sshClient.UploadFromReader(r, remotePath, 0600)
+}
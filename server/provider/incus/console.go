@@ -0,0 +1,168 @@
+package incus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// OpenConsole 通过API打开实例串口控制台（/1.0/instances/<name>/console）
+// Incus的图形控制台为SPICE协议，这里只支持文本串口控制台
+func (i *IncusProvider) OpenConsole(ctx context.Context, instanceID, consoleType string) (provider.ConsoleSession, error) {
+	if !i.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if consoleType != provider.ConsoleTypeSerial {
+		return nil, fmt.Errorf("Incus不支持的控制台类型: %s", consoleType)
+	}
+	if !i.hasAPIAccess() {
+		return nil, fmt.Errorf("控制台需要配置Incus API证书")
+	}
+
+	apiURL := fmt.Sprintf("https://%s:8443/1.0/instances/%s/console", i.config.Host, url.PathEscape(instanceID))
+	payload, _ := json.Marshal(map[string]interface{}{
+		"type":   "console",
+		"width":  80,
+		"height": 24,
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var opResponse struct {
+		Operation string `json:"operation"`
+		Error     string `json:"error"`
+		Metadata  struct {
+			ID       string `json:"id"`
+			Metadata struct {
+				FDs map[string]string `json:"fds"`
+			} `json:"metadata"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&opResponse); err != nil {
+		return nil, fmt.Errorf("解析控制台响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("failed to open console: %d %s", resp.StatusCode, opResponse.Error)
+	}
+
+	dataSecret := opResponse.Metadata.Metadata.FDs["0"]
+	controlSecret := opResponse.Metadata.Metadata.FDs["control"]
+	if opResponse.Operation == "" || dataSecret == "" {
+		return nil, fmt.Errorf("控制台响应缺少websocket凭据")
+	}
+
+	dataConn, err := i.dialOperationWebsocket(ctx, opResponse.Operation, dataSecret)
+	if err != nil {
+		return nil, fmt.Errorf("连接控制台websocket失败: %w", err)
+	}
+
+	session := &incusConsoleSession{conn: dataConn}
+	if controlSecret != "" {
+		controlConn, err := i.dialOperationWebsocket(ctx, opResponse.Operation, controlSecret)
+		if err != nil {
+			// 控制通道仅用于调整窗口大小，连接失败不影响使用
+			global.APP_LOG.Warn("连接控制台控制通道失败",
+				zap.String("instance", instanceID),
+				zap.Error(err))
+		} else {
+			session.control = controlConn
+		}
+	}
+
+	global.APP_LOG.Info("Incus控制台连接成功", zap.String("instance", instanceID))
+	return session, nil
+}
+
+// dialOperationWebsocket 连接异步操作的websocket
+func (i *IncusProvider) dialOperationWebsocket(ctx context.Context, operation, secret string) (*websocket.Conn, error) {
+	wsURL := fmt.Sprintf("wss://%s:8443%s/websocket?secret=%s", i.config.Host, operation, url.QueryEscape(secret))
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 15 * time.Second,
+		TLSClientConfig:  i.transport.TLSClientConfig,
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("status %d: %w", resp.StatusCode, err)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// incusConsoleSession Incus控制台会话
+// 数据通道双向透传原始字节，窗口调整通过控制通道发送 window-resize 命令
+type incusConsoleSession struct {
+	conn      *websocket.Conn
+	control   *websocket.Conn
+	writeMu   sync.Mutex
+	controlMu sync.Mutex
+	closeOnce sync.Once
+}
+
+// Read 读取控制台输出
+func (s *incusConsoleSession) Read() ([]byte, error) {
+	_, data, err := s.conn.ReadMessage()
+	return data, err
+}
+
+// Write 写入控制台输入
+func (s *incusConsoleSession) Write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// Resize 调整终端大小
+func (s *incusConsoleSession) Resize(cols, rows int) error {
+	if s.control == nil {
+		return nil
+	}
+	s.controlMu.Lock()
+	defer s.controlMu.Unlock()
+	return s.control.WriteJSON(map[string]interface{}{
+		"command": "window-resize",
+		"args": map[string]string{
+			"width":  strconv.Itoa(cols),
+			"height": strconv.Itoa(rows),
+		},
+	})
+}
+
+// Password 串口控制台无需密码
+func (s *incusConsoleSession) Password() string {
+	return ""
+}
+
+// Close 关闭会话
+func (s *incusConsoleSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		if s.control != nil {
+			s.control.Close()
+		}
+		err = s.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,57 @@
+package incus
+
+import (
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// configureDedicatedIP 使用地址池分配的独立IP配置实例网络
+// 以路由模式（nictype=routed）网卡替换默认的桥接网卡eth0，宿主机通过默认出口网卡代答并路由该地址，
+// 实例内使用Incus自动配置的链路本地网关（169.254.0.1 / fe80::1），需在实例停止时调用
+func (i *IncusProvider) configureDedicatedIP(instanceName string, networkConfig NetworkConfig) error {
+	parent, err := i.getDefaultRouteInterface()
+	if err != nil {
+		return err
+	}
+
+	options := []string{"nictype=routed", "name=eth0", "parent=" + parent}
+	if networkConfig.DedicatedIPv4 != "" {
+		options = append(options, "ipv4.address="+networkConfig.DedicatedIPv4)
+	}
+	if networkConfig.DedicatedIPv6 != "" {
+		options = append(options, "ipv6.address="+networkConfig.DedicatedIPv6)
+	}
+
+	// eth0 来自profile时删除会失败，可忽略；同名的实例设备会覆盖profile中的设备
+	i.sshClient.Execute(fmt.Sprintf("incus config device remove %s eth0", instanceName))
+
+	cmd := fmt.Sprintf("incus config device add %s eth0 nic %s", instanceName, strings.Join(options, " "))
+	if _, err := i.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("添加路由模式网卡失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已配置实例独立IP",
+		zap.String("instanceName", instanceName),
+		zap.String("parent", parent),
+		zap.String("ipv4", networkConfig.DedicatedIPv4),
+		zap.String("ipv6", networkConfig.DedicatedIPv6))
+	return nil
+}
+
+// getDefaultRouteInterface 获取宿主机默认路由的出口网卡
+func (i *IncusProvider) getDefaultRouteInterface() (string, error) {
+	output, err := i.sshClient.Execute("ip route | grep default | awk '{print $5}' | head -1")
+	if err != nil {
+		return "", fmt.Errorf("获取默认出口网卡失败: %w", err)
+	}
+	iface := utils.CleanCommandOutput(output)
+	if iface == "" {
+		return "", fmt.Errorf("无法获取默认出口网卡")
+	}
+	return iface, nil
+}
@@ -104,6 +104,7 @@ func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig)
 	}
 
 	sshConfig := utils.SSHConfig{
+		ProviderID:     config.ID,
 		Host:           config.Host,
 		Port:           config.Port,
 		Username:       config.Username,
@@ -121,6 +122,8 @@ func (i *IncusProvider) Connect(ctx context.Context, config provider.NodeConfig)
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
+		ProviderID:    config.ID,
+		ProviderName:  config.Name,
 		Host:          config.Host,
 		Port:          config.Port,
 		Username:      config.Username,
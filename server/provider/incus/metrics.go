@@ -0,0 +1,117 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// incusInstanceState 实例列表中与资源使用相关的字段（API recursion=2 与 incus list --format json 结构相同）
+type incusInstanceState struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	State  *struct {
+		CPU struct {
+			Usage uint64 `json:"usage"`
+		} `json:"cpu"`
+		Memory struct {
+			Usage int64 `json:"usage"`
+			Total int64 `json:"total"`
+		} `json:"memory"`
+		Disk map[string]struct {
+			Usage int64 `json:"usage"`
+			Total int64 `json:"total"`
+		} `json:"disk"`
+	} `json:"state"`
+}
+
+// GetInstancesUsage 批量获取所有运行中实例的资源使用情况
+// Incus只提供累计CPU时间，使用率由调用方根据两次采样计算
+func (i *IncusProvider) GetInstancesUsage(ctx context.Context) ([]provider.InstanceUsage, error) {
+	if !i.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if i.shouldUseAPI() {
+		usages, err := i.apiGetInstancesUsage(ctx)
+		if err == nil {
+			return usages, nil
+		}
+		global.APP_LOG.Warn("Incus API失败", zap.Error(err))
+
+		// 检查是否可以回退到SSH
+		if !i.shouldFallbackToSSH() {
+			return nil, fmt.Errorf("API调用失败且不允许回退到SSH: %w", err)
+		}
+		global.APP_LOG.Debug("回退到SSH执行 - 获取实例资源使用情况")
+	}
+
+	// 如果执行规则不允许使用SSH，则返回错误
+	if !i.shouldUseSSH() {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	output, err := i.sshClient.Execute("incus list --format json")
+	if err != nil {
+		return nil, fmt.Errorf("获取实例状态失败: %w", err)
+	}
+	var states []incusInstanceState
+	if err := json.Unmarshal([]byte(output), &states); err != nil {
+		return nil, fmt.Errorf("解析实例状态失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return convertIncusUsage(states), nil
+}
+
+// apiGetInstancesUsage 通过API一次获取所有实例及其状态
+func (i *IncusProvider) apiGetInstancesUsage(ctx context.Context) ([]provider.InstanceUsage, error) {
+	url := fmt.Sprintf("https://%s:8443/1.0/instances?recursion=2", i.config.Host)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Metadata []incusInstanceState `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return convertIncusUsage(response.Metadata), nil
+}
+
+// convertIncusUsage 将实例状态转换为资源使用情况，只保留运行中的实例
+func convertIncusUsage(states []incusInstanceState) []provider.InstanceUsage {
+	usages := make([]provider.InstanceUsage, 0, len(states))
+	for _, s := range states {
+		if s.Status != "Running" || s.State == nil {
+			continue
+		}
+		usage := provider.InstanceUsage{
+			Name:          s.Name,
+			ID:            s.Name,
+			CPUTimeNs:     s.State.CPU.Usage,
+			MemoryUsedMB:  s.State.Memory.Usage / 1024 / 1024,
+			MemoryTotalMB: s.State.Memory.Total / 1024 / 1024,
+		}
+		if root, ok := s.State.Disk["root"]; ok {
+			usage.DiskUsedMB = root.Usage / 1024 / 1024
+			usage.DiskTotalMB = root.Total / 1024 / 1024
+		}
+		usages = append(usages, usage)
+	}
+	return usages
+}
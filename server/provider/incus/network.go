@@ -26,6 +26,8 @@ type NetworkConfig struct {
 	NetworkType           string // 网络配置类型：nat_ipv4, nat_ipv4_ipv6, dedicated_ipv4, dedicated_ipv4_ipv6, ipv6_only
 	IPv4PortMappingMethod string // IPv4端口映射方式：device_proxy, iptables, native
 	IPv6PortMappingMethod string // IPv6端口映射方式：device_proxy, iptables, native
+	DedicatedIPv4         string // 从地址池分配的独立IPv4地址
+	DedicatedIPv6         string // 从地址池分配的独立IPv6地址
 }
 
 // parseNetworkConfigFromInstanceConfig 从实例配置中解析网络配置
@@ -103,6 +105,12 @@ func (i *IncusProvider) parseNetworkConfigFromInstanceConfig(config provider.Ins
 		zap.String("ipv4PortMethod", networkConfig.IPv4PortMappingMethod),
 		zap.String("ipv6PortMethod", networkConfig.IPv6PortMappingMethod))
 
+	// 独立IP网络类型下由地址池分配的公网地址
+	if config.Metadata != nil {
+		networkConfig.DedicatedIPv4 = config.Metadata["dedicated_ipv4"]
+		networkConfig.DedicatedIPv6 = config.Metadata["dedicated_ipv6"]
+	}
+
 	// 从Metadata中解析端口信息（允许实例级别的配置覆盖Provider级别的配置）
 	if config.Metadata != nil {
 		if sshPort, ok := config.Metadata["ssh_port"]; ok {
@@ -238,9 +246,19 @@ func (i *IncusProvider) configureInstanceNetwork(ctx context.Context, config pro
 		global.APP_LOG.Warn("配置网络限速失败", zap.Error(err))
 	}
 
-	// 设置IP地址绑定
-	if err := i.setIPAddressBinding(config.Name, instanceIP); err != nil {
-		global.APP_LOG.Warn("设置IP地址绑定失败", zap.Error(err))
+	if networkConfig.DedicatedIPv4 != "" || networkConfig.DedicatedIPv6 != "" {
+		// 使用地址池分配的独立IP替换桥接网卡
+		if err := i.configureDedicatedIP(config.Name, networkConfig); err != nil {
+			return fmt.Errorf("配置独立IP失败: %w", err)
+		}
+		if networkConfig.DedicatedIPv4 != "" {
+			instanceIP = networkConfig.DedicatedIPv4
+		}
+	} else {
+		// 设置IP地址绑定
+		if err := i.setIPAddressBinding(config.Name, instanceIP); err != nil {
+			global.APP_LOG.Warn("设置IP地址绑定失败", zap.Error(err))
+		}
 	}
 
 	// 配置端口映射 - 在实例停止时添加 proxy 设备
@@ -266,6 +284,10 @@ func (i *IncusProvider) configureInstanceNetwork(ctx context.Context, config pro
 
 	// 配置IPv6网络（如果启用）
 	hasIPv6 := networkConfig.NetworkType == "nat_ipv4_ipv6" || networkConfig.NetworkType == "dedicated_ipv4_ipv6" || networkConfig.NetworkType == "ipv6_only"
+	// 地址池分配的独立IPv6由路由模式网卡直接提供，无需额外配置
+	if networkConfig.DedicatedIPv6 != "" {
+		hasIPv6 = false
+	}
 	if hasIPv6 {
 		if err := i.configureIPv6Network(ctx, config.Name, hasIPv6, networkConfig.IPv6PortMappingMethod); err != nil {
 			global.APP_LOG.Warn("配置IPv6网络失败", zap.Error(err))
@@ -0,0 +1,56 @@
+package incus
+
+import (
+	"context"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstance 调整实例规格（limits.cpu、limits.memory 和 root 设备大小）
+func (i *IncusProvider) ResizeInstance(ctx context.Context, instanceID string, spec provider.ResizeSpec) error {
+	if !i.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 规格调整通过incus命令进行，只通过SSH执行
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法调整实例规格")
+	}
+
+	if spec.CPU > 0 {
+		cmd := fmt.Sprintf("incus config set %s limits.cpu=%d", instanceID, spec.CPU)
+		if output, err := i.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("调整CPU失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	if spec.MemoryMB > 0 {
+		cmd := fmt.Sprintf("incus config set %s limits.memory=%dMiB", instanceID, spec.MemoryMB)
+		if output, err := i.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("调整内存失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	if spec.DiskMB > 0 {
+		// root 设备在创建时已覆盖到实例上，直接设置；来自profile时先覆盖再设置大小
+		cmd := fmt.Sprintf("incus config device set %s root size=%dMiB", instanceID, spec.DiskMB)
+		if _, err := i.sshClient.Execute(cmd); err != nil {
+			overrideCmd := fmt.Sprintf("incus config device override %s root size=%dMiB", instanceID, spec.DiskMB)
+			if output, err := i.sshClient.Execute(overrideCmd); err != nil {
+				return fmt.Errorf("扩容磁盘失败: %w, output: %s", err, utils.TruncateString(output, 200))
+			}
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功调整Incus实例规格",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.Int("cpu", spec.CPU),
+		zap.Int64("memoryMB", spec.MemoryMB),
+		zap.Int64("diskMB", spec.DiskMB))
+	return nil
+}
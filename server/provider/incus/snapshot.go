@@ -0,0 +1,368 @@
+package incus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// CreateSnapshot 创建实例快照
+func (i *IncusProvider) CreateSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !i.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if i.shouldUseAPI() {
+		if err := i.apiCreateSnapshot(ctx, instanceID, snapshotName); err == nil {
+			global.APP_LOG.Info("Incus API调用成功 - 创建快照",
+				zap.String("id", utils.TruncateString(instanceID, 50)),
+				zap.String("snapshot", snapshotName))
+			return nil
+		} else {
+			// 检查是否可回退到SSH并确保SSH健康
+			if fallbackErr := i.ensureSSHBeforeFallback(err, "创建快照"); fallbackErr != nil {
+				return fallbackErr
+			}
+		}
+	}
+
+	// 如果执行规则不允许使用SSH，则返回错误
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	return i.sshCreateSnapshot(ctx, instanceID, snapshotName)
+}
+
+// ListSnapshots 列出实例快照
+func (i *IncusProvider) ListSnapshots(ctx context.Context, instanceID string) ([]provider.Snapshot, error) {
+	if !i.connected {
+		return nil, fmt.Errorf("provider not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if i.shouldUseAPI() {
+		if snapshots, err := i.apiListSnapshots(ctx, instanceID); err == nil {
+			return snapshots, nil
+		} else {
+			// 检查是否可回退到SSH并确保SSH健康
+			if fallbackErr := i.ensureSSHBeforeFallback(err, "获取快照列表"); fallbackErr != nil {
+				return nil, fallbackErr
+			}
+		}
+	}
+
+	// 如果执行规则不允许使用SSH，则返回错误
+	if !i.shouldUseSSH() {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	return i.sshListSnapshots(ctx, instanceID)
+}
+
+// RestoreSnapshot 将实例恢复到指定快照
+func (i *IncusProvider) RestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !i.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if i.shouldUseAPI() {
+		if err := i.apiRestoreSnapshot(ctx, instanceID, snapshotName); err == nil {
+			global.APP_LOG.Info("Incus API调用成功 - 恢复快照",
+				zap.String("id", utils.TruncateString(instanceID, 50)),
+				zap.String("snapshot", snapshotName))
+			return nil
+		} else {
+			// 检查是否可回退到SSH并确保SSH健康
+			if fallbackErr := i.ensureSSHBeforeFallback(err, "恢复快照"); fallbackErr != nil {
+				return fallbackErr
+			}
+		}
+	}
+
+	// 如果执行规则不允许使用SSH，则返回错误
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	return i.sshRestoreSnapshot(ctx, instanceID, snapshotName)
+}
+
+// DeleteSnapshot 删除实例快照
+func (i *IncusProvider) DeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !i.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if i.shouldUseAPI() {
+		if err := i.apiDeleteSnapshot(ctx, instanceID, snapshotName); err == nil {
+			global.APP_LOG.Info("Incus API调用成功 - 删除快照",
+				zap.String("id", utils.TruncateString(instanceID, 50)),
+				zap.String("snapshot", snapshotName))
+			return nil
+		} else {
+			// 检查是否可回退到SSH并确保SSH健康
+			if fallbackErr := i.ensureSSHBeforeFallback(err, "删除快照"); fallbackErr != nil {
+				return fallbackErr
+			}
+		}
+	}
+
+	// 如果执行规则不允许使用SSH，则返回错误
+	if !i.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	return i.sshDeleteSnapshot(ctx, instanceID, snapshotName)
+}
+
+func (i *IncusProvider) apiCreateSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	url := fmt.Sprintf("https://%s:8443/1.0/instances/%s/snapshots", i.config.Host, instanceID)
+	payload := map[string]interface{}{
+		"name":     snapshotName,
+		"stateful": false,
+	}
+
+	jsonData, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to create snapshot: %d", resp.StatusCode)
+	}
+
+	return i.apiWaitOperation(ctx, resp)
+}
+
+func (i *IncusProvider) apiListSnapshots(ctx context.Context, instanceID string) ([]provider.Snapshot, error) {
+	url := fmt.Sprintf("https://%s:8443/1.0/instances/%s/snapshots?recursion=1", i.config.Host, instanceID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list snapshots: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Metadata []struct {
+			Name      string    `json:"name"`
+			CreatedAt time.Time `json:"created_at"`
+			Stateful  bool      `json:"stateful"`
+			Size      int64     `json:"size"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]provider.Snapshot, 0, len(response.Metadata))
+	for _, item := range response.Metadata {
+		snapshots = append(snapshots, provider.Snapshot{
+			Name:    item.Name,
+			Created: item.CreatedAt,
+			Size:    fmt.Sprintf("%d", item.Size),
+			Metadata: map[string]string{
+				"stateful": fmt.Sprintf("%t", item.Stateful),
+			},
+		})
+	}
+
+	return snapshots, nil
+}
+
+func (i *IncusProvider) apiRestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	url := fmt.Sprintf("https://%s:8443/1.0/instances/%s", i.config.Host, instanceID)
+	payload := map[string]interface{}{
+		"restore": snapshotName,
+	}
+
+	jsonData, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to restore snapshot: %d", resp.StatusCode)
+	}
+
+	return i.apiWaitOperation(ctx, resp)
+}
+
+func (i *IncusProvider) apiDeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	url := fmt.Sprintf("https://%s:8443/1.0/instances/%s/snapshots/%s", i.config.Host, instanceID, snapshotName)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := i.apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// 快照不存在，视为删除成功
+		return nil
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to delete snapshot: %d", resp.StatusCode)
+	}
+
+	return i.apiWaitOperation(ctx, resp)
+}
+
+// apiWaitOperation 等待Incus异步操作完成
+// 快照相关操作需要确认真正执行成功后才能更新数据库状态
+func (i *IncusProvider) apiWaitOperation(ctx context.Context, resp *http.Response) error {
+	var asyncResponse struct {
+		Operation string `json:"operation"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&asyncResponse); err != nil {
+		return fmt.Errorf("解析异步操作响应失败: %w", err)
+	}
+	if asyncResponse.Operation == "" {
+		return nil
+	}
+
+	url := fmt.Sprintf("https://%s:8443%s/wait?timeout=300", i.config.Host, asyncResponse.Operation)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	// 等待接口会阻塞到操作结束，不能使用默认的30秒超时
+	client := &http.Client{Transport: i.apiClient.Transport}
+	waitResp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer waitResp.Body.Close()
+
+	var operationResponse struct {
+		Metadata struct {
+			StatusCode int    `json:"status_code"`
+			Err        string `json:"err"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(waitResp.Body).Decode(&operationResponse); err != nil {
+		return fmt.Errorf("解析操作结果失败: %w", err)
+	}
+
+	if operationResponse.Metadata.StatusCode != http.StatusOK {
+		return fmt.Errorf("operation failed: %s", operationResponse.Metadata.Err)
+	}
+
+	return nil
+}
+
+func (i *IncusProvider) sshCreateSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus snapshot create %s %s", instanceID, snapshotName))
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功创建Incus快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
+
+func (i *IncusProvider) sshListSnapshots(ctx context.Context, instanceID string) ([]provider.Snapshot, error) {
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus query /1.0/instances/%s/snapshots?recursion=1", instanceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var items []struct {
+		Name      string    `json:"name"`
+		CreatedAt time.Time `json:"created_at"`
+		Stateful  bool      `json:"stateful"`
+		Size      int64     `json:"size"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &items); err != nil {
+		return nil, fmt.Errorf("解析快照列表失败: %w", err)
+	}
+
+	snapshots := make([]provider.Snapshot, 0, len(items))
+	for _, item := range items {
+		snapshots = append(snapshots, provider.Snapshot{
+			Name:    item.Name,
+			Created: item.CreatedAt,
+			Size:    fmt.Sprintf("%d", item.Size),
+			Metadata: map[string]string{
+				"stateful": fmt.Sprintf("%t", item.Stateful),
+			},
+		})
+	}
+
+	return snapshots, nil
+}
+
+func (i *IncusProvider) sshRestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus snapshot restore %s %s", instanceID, snapshotName))
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功恢复Incus快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
+
+func (i *IncusProvider) sshDeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	output, err := i.sshClient.Execute(fmt.Sprintf("incus snapshot delete %s %s", instanceID, snapshotName))
+	if err != nil {
+		// 快照不存在，视为删除成功
+		if strings.Contains(output, "not found") {
+			global.APP_LOG.Info("快照已不存在，视为删除成功",
+				zap.String("id", utils.TruncateString(instanceID, 50)),
+				zap.String("snapshot", snapshotName))
+			return nil
+		}
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	global.APP_LOG.Info("通过SSH成功删除Incus快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
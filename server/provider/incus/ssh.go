@@ -266,6 +266,13 @@ func (i *IncusProvider) sshCreateInstanceWithProgress(ctx context.Context, confi
 		}
 	}
 
+	// 应用用户提供的user-data（需在首次启动前设置）
+	if config.UserData != "" {
+		if err := i.sshSetInstanceUserData(config.Name, config.UserData); err != nil {
+			return fmt.Errorf("应用user-data失败: %w", err)
+		}
+	}
+
 	updateProgress(45, "配置实例安全设置...")
 	// 配置安全设置
 	if err := i.configureInstanceSecurity(ctx, config); err != nil {
@@ -0,0 +1,149 @@
+package lxd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// lxdACLRule 网络ACL规则，字段与 lxc network acl edit 的格式一致
+type lxdACLRule struct {
+	Action          string `json:"action"`
+	Protocol        string `json:"protocol,omitempty"`
+	Source          string `json:"source,omitempty"`
+	DestinationPort string `json:"destination_port,omitempty"`
+	State           string `json:"state"`
+}
+
+// aclName 实例专用的网络ACL名称
+func aclName(instanceName string) string {
+	return "ocv-" + instanceName
+}
+
+// buildACLRules 将防火墙策略转换为ACL入站规则
+// LXD按 drop、reject、allow 的顺序评估规则，与拒绝优先的语义一致；ICMP需要按地址族拆分
+func buildACLRules(policy provider.FirewallPolicy) []lxdACLRule {
+	rules := make([]lxdACLRule, 0, len(policy.Rules))
+	for _, r := range policy.Rules {
+		action := "allow"
+		if r.Action == "deny" {
+			action = "drop"
+		}
+		base := lxdACLRule{Action: action, Source: r.Source, State: "enabled"}
+
+		switch r.Protocol {
+		case "tcp", "udp":
+			base.Protocol = r.Protocol
+			if r.PortStart > 0 {
+				base.DestinationPort = fmt.Sprint(r.PortStart)
+				if r.PortEnd > r.PortStart {
+					base.DestinationPort = fmt.Sprintf("%d-%d", r.PortStart, r.PortEnd)
+				}
+			}
+			rules = append(rules, base)
+		case "icmp":
+			isV6 := strings.Contains(r.Source, ":")
+			if r.Source == "" || !isV6 {
+				rule := base
+				rule.Protocol = "icmp4"
+				rules = append(rules, rule)
+			}
+			if r.Source == "" || isV6 {
+				rule := base
+				rule.Protocol = "icmp6"
+				rules = append(rules, rule)
+			}
+		default:
+			rules = append(rules, base)
+		}
+	}
+	return rules
+}
+
+// ApplyFirewall 为实例设置入站防火墙
+// 通过实例专用的网络ACL实现，只支持受管桥接网络上的网卡（默认的eth0），以路由模式绑定独立IP的实例不支持
+func (l *LXDProvider) ApplyFirewall(ctx context.Context, instanceID string, policy provider.FirewallPolicy) error {
+	if !l.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 网络ACL通过lxc命令管理，只通过SSH执行
+	if !l.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法设置实例防火墙")
+	}
+
+	name := aclName(instanceID)
+	data, err := json.Marshal(map[string]interface{}{
+		"description": fmt.Sprintf("oneclickvirt security group for %s", instanceID),
+		"ingress":     buildACLRules(policy),
+		"egress":      []lxdACLRule{},
+		"config":      map[string]string{},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.sshClient.Execute(fmt.Sprintf("lxc network acl show %s", name)); err != nil {
+		if output, err := l.sshClient.Execute(fmt.Sprintf("lxc network acl create %s", name)); err != nil {
+			return fmt.Errorf("创建网络ACL失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	tmpFile := fmt.Sprintf("/tmp/oneclickvirt-acl-%s.json", instanceID)
+	if err := l.sshClient.UploadContent(string(data), tmpFile, 0600); err != nil {
+		return fmt.Errorf("上传网络ACL规则失败: %w", err)
+	}
+	defer l.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpFile))
+
+	if output, err := l.sshClient.Execute(fmt.Sprintf("lxc network acl edit %s < %s", name, tmpFile)); err != nil {
+		return fmt.Errorf("更新网络ACL规则失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	defaultIngress := "allow"
+	if policy.DefaultAction == "deny" {
+		defaultIngress = "drop"
+	}
+	options := fmt.Sprintf("security.acls=%s security.acls.default.ingress.action=%s security.acls.default.egress.action=allow", name, defaultIngress)
+	// eth0 来自profile时需要先覆盖到实例上
+	if _, err := l.sshClient.Execute(fmt.Sprintf("lxc config device set %s eth0 %s", instanceID, options)); err != nil {
+		if output, err := l.sshClient.Execute(fmt.Sprintf("lxc config device override %s eth0 %s", instanceID, options)); err != nil {
+			return fmt.Errorf("绑定网络ACL失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功设置LXD实例防火墙",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.Int("rules", len(policy.Rules)),
+		zap.String("defaultAction", policy.DefaultAction))
+	return nil
+}
+
+// RemoveFirewall 解除实例的网络ACL并删除，实例或ACL不存在时忽略
+func (l *LXDProvider) RemoveFirewall(ctx context.Context, instanceID string) error {
+	if !l.connected {
+		return fmt.Errorf("provider not connected")
+	}
+	if !l.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法移除实例防火墙")
+	}
+
+	name := aclName(instanceID)
+	for _, key := range []string{"security.acls", "security.acls.default.ingress.action", "security.acls.default.egress.action"} {
+		l.sshClient.Execute(fmt.Sprintf("lxc config device unset %s eth0 %s", instanceID, key))
+	}
+
+	if _, err := l.sshClient.Execute(fmt.Sprintf("lxc network acl show %s", name)); err != nil {
+		return nil
+	}
+	if output, err := l.sshClient.Execute(fmt.Sprintf("lxc network acl delete %s", name)); err != nil {
+		return fmt.Errorf("删除网络ACL失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return nil
+}
@@ -267,6 +267,26 @@ func (l *LXDProvider) configureVMSettings(ctx context.Context, instanceName stri
 	return nil
 }
 
+// sshSetInstanceUserData 通过SSH设置实例的 cloud-init.user-data
+// user-data为多行文本，先上传到节点临时文件再读取，避免命令行转义问题
+func (l *LXDProvider) sshSetInstanceUserData(instanceName, userData string) error {
+	tmpPath := fmt.Sprintf("/tmp/oneclickvirt-%s-user-data", instanceName)
+	if err := l.sshClient.UploadContent(userData, tmpPath, 0600); err != nil {
+		return fmt.Errorf("上传user-data失败: %w", err)
+	}
+	defer l.sshClient.Execute(fmt.Sprintf("rm -f %s", tmpPath))
+
+	cmd := fmt.Sprintf("lxc config set %s cloud-init.user-data \"$(cat %s)\"", instanceName, tmpPath)
+	if _, err := l.sshClient.Execute(cmd); err != nil {
+		return fmt.Errorf("设置cloud-init.user-data失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已设置实例user-data",
+		zap.String("instance", instanceName),
+		zap.Int("size", len(userData)))
+	return nil
+}
+
 // configureInstanceSSHPassword 专门用于设置实例的SSH密码
 func (l *LXDProvider) configureInstanceSSHPassword(ctx context.Context, config provider.InstanceConfig) error {
 	global.APP_LOG.Info("开始配置实例SSH密码",
@@ -105,6 +105,7 @@ func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) e
 
 	// 尝试 SSH 连接
 	sshConfig := utils.SSHConfig{
+		ProviderID:     config.ID,
 		Host:           config.Host,
 		Port:           config.Port,
 		Username:       config.Username,
@@ -124,6 +125,8 @@ func (l *LXDProvider) Connect(ctx context.Context, config provider.NodeConfig) e
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
+		ProviderID:    config.ID,
+		ProviderName:  config.Name,
 		Host:          config.Host,
 		Port:          config.Port,
 		Username:      config.Username,
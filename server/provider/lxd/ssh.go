@@ -381,6 +381,13 @@ func (l *LXDProvider) sshCreateInstanceWithProgress(ctx context.Context, config
 		}
 	}
 
+	// 应用用户提供的user-data（需在首次启动前设置）
+	if config.UserData != "" {
+		if err := l.sshSetInstanceUserData(config.Name, config.UserData); err != nil {
+			return fmt.Errorf("应用user-data失败: %w", err)
+		}
+	}
+
 	updateProgress(45, "配置实例存储...")
 	// 配置存储（如果需要）
 	if err := l.configureInstanceStorage(ctx, config); err != nil {
@@ -454,6 +454,7 @@ func (d *DockerPortMapping) getSSHClient(providerInfo *provider.Provider) (*util
 
 	// 创建SSH配置
 	config := utils.SSHConfig{
+		ProviderID: providerInfo.ID,
 		Host:       authConfig.SSH.Host,
 		Port:       authConfig.SSH.Port,
 		Username:   authConfig.SSH.Username,
@@ -506,6 +506,7 @@ func (i *IptablesPortMapping) createSSHClient(providerInfo *provider.Provider) (
 	host, port := i.parseEndpoint(providerInfo.Endpoint)
 
 	sshConfig := utils.SSHConfig{
+		ProviderID:     providerInfo.ID,
 		Host:           host,
 		Port:           port,
 		Username:       providerInfo.Username,
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 
@@ -15,6 +16,7 @@ type Instance = provider.ProviderInstance
 type Image = provider.ProviderImage
 type InstanceConfig = provider.ProviderInstanceConfig
 type NodeConfig = provider.ProviderNodeConfig
+type Snapshot = provider.ProviderSnapshot
 
 // ProgressCallback 进度回调函数类型
 type ProgressCallback func(percentage int, message string)
@@ -61,6 +63,109 @@ type Provider interface {
 	ExecuteSSHCommand(ctx context.Context, command string) (string, error)
 }
 
+// SnapshotProvider 快照能力接口（可选）
+// 并非所有Provider都支持快照，调用方需通过类型断言判断是否实现
+type SnapshotProvider interface {
+	CreateSnapshot(ctx context.Context, instanceID, snapshotName string) error
+	ListSnapshots(ctx context.Context, instanceID string) ([]Snapshot, error)
+	RestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error
+	DeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error
+}
+
+// BackupProvider 备份能力接口（可选）
+// 实例在节点本地导出为归档文件，归档通过SSH在节点与控制端之间传输
+type BackupProvider interface {
+	// ExportInstance 将实例导出到节点的exportDir目录，返回归档文件路径
+	ExportInstance(ctx context.Context, instanceID, exportDir string) (string, error)
+	// ImportInstance 使用节点上的归档文件覆盖恢复已存在的实例
+	ImportInstance(ctx context.Context, instanceID, archivePath string) error
+	// DownloadFile 将节点上的文件写入w
+	DownloadFile(ctx context.Context, remotePath string, w io.Writer) (int64, error)
+	// UploadFile 将r中的内容上传到节点
+	UploadFile(ctx context.Context, r io.Reader, remotePath string) error
+}
+
+// ResizeSpec 实例规格调整参数，值为0表示该项保持不变
+type ResizeSpec struct {
+	CPU      int   // CPU核心数
+	MemoryMB int64 // 内存大小（MB）
+	DiskMB   int64 // 磁盘大小（MB），只允许扩容
+}
+
+// ResizeProvider 规格调整能力接口（可选）
+// 在线调整实例的CPU、内存和磁盘，磁盘只能扩容；部分平台的虚拟机需重启后新规格才完全生效
+type ResizeProvider interface {
+	ResizeInstance(ctx context.Context, instanceID string, spec ResizeSpec) error
+}
+
+// InstanceUsage 实例资源使用情况，值为0表示平台未提供该项
+type InstanceUsage struct {
+	Name          string  // 实例名称
+	ID            string  // 平台内的实例ID（Proxmox为VMID，其余平台与名称相同）
+	CPUPercent    float64 // CPU使用率（%，100表示占满一个核心）
+	CPUTimeNs     uint64  // 累计CPU时间（纳秒），平台只提供累计值时由调用方根据两次采样的差值计算使用率
+	MemoryUsedMB  int64   // 已用内存（MB）
+	MemoryTotalMB int64   // 内存上限（MB）
+	DiskUsedMB    int64   // 根磁盘已用空间（MB）
+	DiskTotalMB   int64   // 根磁盘大小（MB）
+}
+
+// MetricsProvider 资源监控能力接口（可选）
+// 一次调用批量返回节点上所有运行中实例的资源使用情况，避免逐个实例请求
+type MetricsProvider interface {
+	GetInstancesUsage(ctx context.Context) ([]InstanceUsage, error)
+}
+
+// FirewallRule 实例入站防火墙规则
+type FirewallRule struct {
+	Action    string // allow, deny
+	Protocol  string // tcp, udp, icmp, all
+	PortStart int    // 起始端口，0表示全部端口
+	PortEnd   int    // 结束端口
+	Source    string // 来源CIDR，空表示任意来源
+}
+
+// FirewallPolicy 实例入站防火墙策略，拒绝规则优先于允许规则，未匹配的流量按DefaultAction处理
+type FirewallPolicy struct {
+	Rules         []FirewallRule
+	DefaultAction string // allow, deny
+}
+
+// FirewallProvider 实例防火墙能力接口（可选）
+// 在宿主机侧对实例的入站流量进行过滤，实例内部无法绕过；重复调用ApplyFirewall会整体替换之前的规则
+type FirewallProvider interface {
+	ApplyFirewall(ctx context.Context, instanceID string, policy FirewallPolicy) error
+	RemoveFirewall(ctx context.Context, instanceID string) error
+}
+
+// 控制台类型
+const (
+	ConsoleTypeSerial = "serial" // 文本串口控制台
+	ConsoleTypeVNC    = "vnc"    // VNC图形控制台（RFB协议）
+)
+
+// ConsoleProvider 控制台能力接口（可选）
+// 通过虚拟化平台API连接实例控制台，不依赖实例内部的网络和sshd
+type ConsoleProvider interface {
+	// OpenConsole 打开实例控制台，不支持的控制台类型返回错误
+	OpenConsole(ctx context.Context, instanceID, consoleType string) (ConsoleSession, error)
+}
+
+// ConsoleSession 控制台会话
+// Read/Write传递的是原始的终端字节流或RFB数据，平台特有的帧格式由实现负责转换
+type ConsoleSession interface {
+	// Read 读取控制台输出
+	Read() ([]byte, error)
+	// Write 写入控制台输入
+	Write(data []byte) error
+	// Resize 调整终端大小，VNC控制台忽略
+	Resize(cols, rows int) error
+	// Password 返回VNC认证密码，串口控制台为空
+	Password() string
+	// Close 关闭会话
+	Close() error
+}
+
 // Registry Provider 注册表
 type Registry struct {
 	providers map[string]func() Provider
@@ -1153,6 +1153,13 @@ func (p *ProxmoxProvider) apiCreateVM(ctx context.Context, vmid int, config prov
 		_, _ = p.sshClient.Execute(fmt.Sprintf("qm set %d --ide1 %s:cloudinit", vmid, storage))
 	}
 
+	// 应用用户提供的user-data
+	if config.UserData != "" {
+		if err := p.configureVMUserData(vmid, config.UserData); err != nil {
+			return fmt.Errorf("应用user-data失败: %w", err)
+		}
+	}
+
 	// 调整磁盘大小
 	// Proxmox 不支持缩小磁盘，所以需要先检查当前磁盘大小，只在需要扩大时才resize
 	diskFormatted := convertDiskFormat(config.Disk)
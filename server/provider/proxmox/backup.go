@@ -0,0 +1,165 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// backupCommandTimeout 导出/导入命令的默认超时
+const backupCommandTimeout = 2 * time.Hour
+
+// restorePreservedConfigPattern 恢复时保留目标实例原值的配置项
+// 归档中的名称、网络和资源规格属于源实例，恢复到其他实例时不能覆盖目标实例的配置
+var restorePreservedConfigPattern = regexp.MustCompile(`^(name|hostname|net\d+|ipconfig\d+|cores|memory|swap|cpulimit|nameserver|searchdomain):\s*(.+)$`)
+
+// ExportInstance 将实例导出为归档文件（vzdump）
+func (p *ProxmoxProvider) ExportInstance(ctx context.Context, instanceID, exportDir string) (string, error) {
+	if !p.connected {
+		return "", fmt.Errorf("provider not connected")
+	}
+
+	// vzdump依赖节点本地命令，只通过SSH进行
+	if !p.shouldUseSSH() {
+		return "", fmt.Errorf("执行规则不允许使用SSH，无法导出实例")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	// 容器所在存储不一定支持快照，使用suspend模式保证兼容
+	mode, dumpType := "snapshot", "qemu"
+	if instanceType == "container" {
+		mode, dumpType = "suspend", "lxc"
+	}
+
+	exportDir = strings.TrimRight(exportDir, "/")
+	if _, err := p.sshClient.Execute(fmt.Sprintf("mkdir -p %s", exportDir)); err != nil {
+		return "", fmt.Errorf("创建导出目录失败: %w", err)
+	}
+
+	cmd := fmt.Sprintf("vzdump %s --dumpdir %s --mode %s --compress zstd", vmid, exportDir, mode)
+	output, err := p.sshClient.ExecuteWithTimeout(cmd, utils.TimeoutFromContext(ctx, backupCommandTimeout))
+	if err != nil {
+		return "", fmt.Errorf("failed to vzdump %s %s: %w, output: %s", instanceType, vmid, err, utils.TruncateString(output, 200))
+	}
+
+	archiveOutput, err := p.sshClient.Execute(fmt.Sprintf("ls -1t %s/vzdump-%s-%s-*.zst 2>/dev/null | head -n 1", exportDir, dumpType, vmid))
+	archivePath := strings.TrimSpace(archiveOutput)
+	if err != nil || archivePath == "" {
+		return "", fmt.Errorf("未找到vzdump生成的归档文件")
+	}
+	// vzdump会同时生成日志文件，归档已经找到后删除
+	p.sshClient.Execute(fmt.Sprintf("rm -f %s/vzdump-%s-%s-*.log", exportDir, dumpType, vmid))
+
+	global.APP_LOG.Info("通过SSH成功导出Proxmox实例",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.String("archive", archivePath))
+	return archivePath, nil
+}
+
+// ImportInstance 使用归档文件覆盖恢复实例（qmrestore / pct restore）
+func (p *ProxmoxProvider) ImportInstance(ctx context.Context, instanceID, archivePath string) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法导入实例")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	cmdPrefix, err := snapshotCommand(instanceType)
+	if err != nil {
+		return err
+	}
+
+	// 记录需要保留的配置项
+	configOutput, err := p.sshClient.Execute(fmt.Sprintf("%s config %s", cmdPrefix, vmid))
+	if err != nil {
+		return fmt.Errorf("获取实例配置失败: %w", err)
+	}
+	var preserved []string
+	for _, line := range strings.Split(configOutput, "\n") {
+		if match := restorePreservedConfigPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			preserved = append(preserved, fmt.Sprintf("--%s '%s'", match[1], strings.TrimSpace(match[2])))
+		}
+	}
+
+	statusOutput, _ := p.sshClient.Execute(fmt.Sprintf("%s status %s", cmdPrefix, vmid))
+	wasRunning := strings.Contains(statusOutput, "running")
+	if wasRunning {
+		if _, err := p.sshClient.Execute(fmt.Sprintf("%s stop %s", cmdPrefix, vmid)); err != nil {
+			return fmt.Errorf("停止实例失败: %w", err)
+		}
+	}
+
+	var restoreCmd string
+	if instanceType == "container" {
+		restoreCmd = fmt.Sprintf("pct restore %s %s --force 1", vmid, archivePath)
+	} else {
+		restoreCmd = fmt.Sprintf("qmrestore %s %s --force 1", archivePath, vmid)
+	}
+	if output, err := p.sshClient.ExecuteWithTimeout(restoreCmd, utils.TimeoutFromContext(ctx, backupCommandTimeout)); err != nil {
+		if wasRunning {
+			p.sshClient.Execute(fmt.Sprintf("%s start %s", cmdPrefix, vmid))
+		}
+		return fmt.Errorf("failed to restore %s %s: %w, output: %s", instanceType, vmid, err, utils.TruncateString(output, 200))
+	}
+
+	if len(preserved) > 0 {
+		if output, err := p.sshClient.Execute(fmt.Sprintf("%s set %s %s", cmdPrefix, vmid, strings.Join(preserved, " "))); err != nil {
+			global.APP_LOG.Warn("恢复后还原实例配置失败",
+				zap.String("vmid", vmid),
+				zap.String("output", utils.TruncateString(output, 200)),
+				zap.Error(err))
+		}
+	}
+
+	if wasRunning {
+		if _, err := p.sshClient.Execute(fmt.Sprintf("%s start %s", cmdPrefix, vmid)); err != nil {
+			global.APP_LOG.Warn("恢复后启动实例失败",
+				zap.String("vmid", vmid),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功导入Proxmox实例",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.String("archive", archivePath))
+	return nil
+}
+
+// DownloadFile 从节点下载文件
+func (p *ProxmoxProvider) DownloadFile(ctx context.Context, remotePath string, w io.Writer) (int64, error) {
+	if !p.shouldUseSSH() {
+		return 0, fmt.Errorf("执行规则不允许使用SSH，无法传输文件")
+	}
+	return p.sshClient.DownloadToWriter(remotePath, w)
+}
+
+// UploadFile 上传文件到节点
+func (p *ProxmoxProvider) UploadFile(ctx context.Context, r io.Reader, remotePath string) error {
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法传输文件")
+	}
+	return p.sshClient.UploadFromReader(r, remotePath, 0600)
+}
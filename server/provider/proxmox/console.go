@@ -0,0 +1,236 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// proxmoxProxyTicket termproxy/vncproxy 接口返回的连接凭据
+type proxmoxProxyTicket struct {
+	Port   json.Number `json:"port"`
+	Ticket string      `json:"ticket"`
+	User   string      `json:"user"`
+}
+
+// OpenConsole 通过API打开实例控制台
+// 串口控制台使用 termproxy（虚拟机需配置 serial0 串口），VNC控制台使用 vncproxy，
+// 两者都通过 vncwebsocket 接口建立websocket连接
+func (p *ProxmoxProvider) OpenConsole(ctx context.Context, instanceID, consoleType string) (provider.ConsoleSession, error) {
+	if !p.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !p.hasAPIAccess() {
+		return nil, fmt.Errorf("控制台需要配置Proxmox API Token")
+	}
+
+	var proxyPath string
+	switch consoleType {
+	case provider.ConsoleTypeSerial:
+		proxyPath = "termproxy"
+	case provider.ConsoleTypeVNC:
+		proxyPath = "vncproxy"
+	default:
+		return nil, fmt.Errorf("不支持的控制台类型: %s", consoleType)
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	var apiType string
+	switch instanceType {
+	case "vm":
+		apiType = "qemu"
+	case "container":
+		apiType = "lxc"
+	default:
+		return nil, fmt.Errorf("unknown instance type: %s", instanceType)
+	}
+
+	ticket, err := p.apiCreateProxyTicket(ctx, apiType, vmid, proxyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 建立websocket连接
+	wsURL := fmt.Sprintf("wss://%s:8006/api2/json/nodes/%s/%s/%s/vncwebsocket?port=%s&vncticket=%s",
+		p.config.Host, p.node, apiType, vmid, ticket.Port.String(), url.QueryEscape(ticket.Ticket))
+	// vncwebsocket 同样需要API Token认证
+	header := http.Header{}
+	p.setAPIAuth(&http.Request{Header: header})
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 15 * time.Second,
+		Subprotocols:     []string{"binary"},
+		TLSClientConfig:  p.transport.TLSClientConfig,
+	}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("连接控制台websocket失败: status %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("连接控制台websocket失败: %w", err)
+	}
+
+	session := &proxmoxConsoleSession{
+		conn:        conn,
+		consoleType: consoleType,
+		done:        make(chan struct{}),
+	}
+	if consoleType == provider.ConsoleTypeVNC {
+		// vncproxy 返回的ticket同时作为VNC认证密码
+		session.password = ticket.Ticket
+	} else {
+		// termproxy 要求连接后先发送 "用户:ticket" 完成认证
+		if err := session.writeRaw([]byte(ticket.User + ":" + ticket.Ticket + "\n")); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("控制台认证失败: %w", err)
+		}
+		// 认证成功时服务端首先返回 "OK"
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		_, reply, err := conn.ReadMessage()
+		conn.SetReadDeadline(time.Time{})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("控制台认证失败: %w", err)
+		}
+		if !strings.HasPrefix(string(reply), "OK") {
+			conn.Close()
+			return nil, fmt.Errorf("控制台认证失败: %s", string(reply))
+		}
+		go session.keepalive()
+	}
+
+	global.APP_LOG.Info("Proxmox控制台连接成功",
+		zap.String("instance", instanceID),
+		zap.String("vmid", vmid),
+		zap.String("type", consoleType))
+
+	return session, nil
+}
+
+// apiCreateProxyTicket 调用 termproxy/vncproxy 接口获取连接凭据
+func (p *ProxmoxProvider) apiCreateProxyTicket(ctx context.Context, apiType, vmid, proxyPath string) (*proxmoxProxyTicket, error) {
+	apiURL := fmt.Sprintf("https://%s:8006/api2/json/nodes/%s/%s/%s/%s", p.config.Host, p.node, apiType, vmid, proxyPath)
+
+	var body string
+	if proxyPath == "vncproxy" {
+		body = `{"websocket":1}`
+	} else {
+		body = `{}`
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setAPIAuth(req)
+
+	resp, err := p.apiClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("执行API请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var respData struct {
+		Data proxmoxProxyTicket `json:"data"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("创建控制台代理失败: status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&respData); err != nil {
+		return nil, fmt.Errorf("解析控制台代理响应失败: %w", err)
+	}
+	if respData.Data.Ticket == "" || respData.Data.Port.String() == "" {
+		return nil, fmt.Errorf("控制台代理响应缺少ticket或端口")
+	}
+	return &respData.Data, nil
+}
+
+// proxmoxConsoleSession Proxmox控制台会话
+// termproxy 的输入需封装为 "0:长度:数据"，窗口调整为 "1:列:行:"；输出为原始字节。
+// vncproxy 的数据直接透传RFB协议
+type proxmoxConsoleSession struct {
+	conn        *websocket.Conn
+	consoleType string
+	password    string
+	writeMu     sync.Mutex
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// keepalive termproxy 空闲一段时间后会断开，需要定期发送心跳 "2"
+func (s *proxmoxConsoleSession) keepalive() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.writeRaw([]byte("2")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *proxmoxConsoleSession) writeRaw(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// Read 读取控制台输出
+func (s *proxmoxConsoleSession) Read() ([]byte, error) {
+	_, data, err := s.conn.ReadMessage()
+	return data, err
+}
+
+// Write 写入控制台输入
+func (s *proxmoxConsoleSession) Write(data []byte) error {
+	if s.consoleType == provider.ConsoleTypeVNC {
+		return s.writeRaw(data)
+	}
+	frame := make([]byte, 0, len(data)+16)
+	frame = append(frame, fmt.Sprintf("0:%d:", len(data))...)
+	frame = append(frame, data...)
+	return s.writeRaw(frame)
+}
+
+// Resize 调整终端大小
+func (s *proxmoxConsoleSession) Resize(cols, rows int) error {
+	if s.consoleType == provider.ConsoleTypeVNC {
+		return nil
+	}
+	return s.writeRaw([]byte(fmt.Sprintf("1:%d:%d:", cols, rows)))
+}
+
+// Password 返回VNC认证密码
+func (s *proxmoxConsoleSession) Password() string {
+	return s.password
+}
+
+// Close 关闭会话
+func (s *proxmoxConsoleSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
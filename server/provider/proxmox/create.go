@@ -585,6 +585,13 @@ func (p *ProxmoxProvider) createVM(ctx context.Context, vmid int, config provide
 		global.APP_LOG.Warn("设置云初始化失败", zap.Int("vmid", vmid), zap.Error(err))
 	}
 
+	// 应用用户提供的user-data
+	if config.UserData != "" {
+		if err := p.configureVMUserData(vmid, config.UserData); err != nil {
+			return fmt.Errorf("应用user-data失败: %v", err)
+		}
+	}
+
 	updateProgress(85, "调整磁盘大小...")
 
 	// 调整磁盘大小
@@ -0,0 +1,131 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+
+	"go.uber.org/zap"
+)
+
+// publicBridge 宿主机公网网桥，独立IP直接桥接到该网桥
+const publicBridge = "vmbr0"
+
+var netRateRegexp = regexp.MustCompile(`rate=([0-9.]+)`)
+
+// dedicatedIPConfig 地址池分配的独立IP
+type dedicatedIPConfig struct {
+	IPv4        string
+	IPv4Prefix  string
+	IPv4Gateway string
+	IPv6        string
+	IPv6Prefix  string
+	IPv6Gateway string
+}
+
+// parseDedicatedIPConfig 从实例Metadata中读取地址池分配的独立IP
+func parseDedicatedIPConfig(config provider.InstanceConfig) dedicatedIPConfig {
+	if config.Metadata == nil {
+		return dedicatedIPConfig{}
+	}
+	return dedicatedIPConfig{
+		IPv4:        config.Metadata["dedicated_ipv4"],
+		IPv4Prefix:  config.Metadata["dedicated_ipv4_prefix"],
+		IPv4Gateway: config.Metadata["dedicated_ipv4_gateway"],
+		IPv6:        config.Metadata["dedicated_ipv6"],
+		IPv6Prefix:  config.Metadata["dedicated_ipv6_prefix"],
+		IPv6Gateway: config.Metadata["dedicated_ipv6_gateway"],
+	}
+}
+
+// ipv4Options 生成 ip=/gw= 配置项
+func (d dedicatedIPConfig) ipv4Options() []string {
+	options := []string{fmt.Sprintf("ip=%s/%s", d.IPv4, d.IPv4Prefix)}
+	if d.IPv4Gateway != "" {
+		options = append(options, "gw="+d.IPv4Gateway)
+	}
+	return options
+}
+
+// ipv6Options 生成 ip6=/gw6= 配置项
+func (d dedicatedIPConfig) ipv6Options() []string {
+	options := []string{fmt.Sprintf("ip6=%s/%s", d.IPv6, d.IPv6Prefix)}
+	if d.IPv6Gateway != "" {
+		options = append(options, "gw6="+d.IPv6Gateway)
+	}
+	return options
+}
+
+// configureDedicatedIP 使用地址池分配的独立IP配置实例网络
+// 分配了IPv4时将 net0 改为桥接到公网网桥并配置该地址（同时分配了IPv6时一并配置）；
+// 仅分配了IPv6时在公网网桥上新增 net1 接口。需在实例启动前调用
+func (p *ProxmoxProvider) configureDedicatedIP(ctx context.Context, vmid int, config provider.InstanceConfig) error {
+	dedicated := parseDedicatedIPConfig(config)
+	if dedicated.IPv4 == "" && dedicated.IPv6 == "" {
+		return nil
+	}
+
+	var cmds []string
+	if config.InstanceType == "container" {
+		if dedicated.IPv4 != "" {
+			options := append([]string{"name=eth0", "bridge=" + publicBridge}, dedicated.ipv4Options()...)
+			if dedicated.IPv6 != "" {
+				options = append(options, dedicated.ipv6Options()...)
+			}
+			if rate := p.getNetRate(vmid, "pct", "net0"); rate != "" {
+				options = append(options, "rate="+rate)
+			}
+			cmds = append(cmds, fmt.Sprintf("pct set %d --net0 %s", vmid, strings.Join(options, ",")))
+		} else {
+			options := append([]string{"name=eth1", "bridge=" + publicBridge}, dedicated.ipv6Options()...)
+			cmds = append(cmds, fmt.Sprintf("pct set %d --net1 %s", vmid, strings.Join(options, ",")))
+		}
+	} else {
+		if dedicated.IPv4 != "" {
+			net0 := "virtio,bridge=" + publicBridge + ",firewall=0"
+			if rate := p.getNetRate(vmid, "qm", "net0"); rate != "" {
+				net0 += ",rate=" + rate
+			}
+			ipconfig := dedicated.ipv4Options()
+			if dedicated.IPv6 != "" {
+				ipconfig = append(ipconfig, dedicated.ipv6Options()...)
+			}
+			cmds = append(cmds,
+				fmt.Sprintf("qm set %d --net0 %s", vmid, net0),
+				fmt.Sprintf("qm set %d --ipconfig0 %s", vmid, strings.Join(ipconfig, ",")))
+		} else {
+			cmds = append(cmds,
+				fmt.Sprintf("qm set %d --net1 virtio,bridge=%s,firewall=0", vmid, publicBridge),
+				fmt.Sprintf("qm set %d --ipconfig1 %s", vmid, strings.Join(dedicated.ipv6Options(), ",")))
+		}
+	}
+
+	for _, cmd := range cmds {
+		if _, err := p.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("配置独立IP失败: %w", err)
+		}
+	}
+
+	global.APP_LOG.Info("已配置实例独立IP",
+		zap.Int("vmid", vmid),
+		zap.String("type", config.InstanceType),
+		zap.String("ipv4", dedicated.IPv4),
+		zap.String("ipv6", dedicated.IPv6))
+	return nil
+}
+
+// getNetRate 读取网卡当前的 rate 限速配置，用于替换网卡时保留带宽限制
+func (p *ProxmoxProvider) getNetRate(vmid int, tool, netName string) string {
+	output, err := p.sshClient.Execute(fmt.Sprintf("%s config %d | grep '^%s:'", tool, vmid, netName))
+	if err != nil {
+		return ""
+	}
+	if match := netRateRegexp.FindStringSubmatch(output); len(match) == 2 {
+		return match[1]
+	}
+	return ""
+}
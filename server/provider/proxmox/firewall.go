@@ -0,0 +1,178 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// netConfigLine 实例配置中的网卡行，如 net0: virtio=BC:24:11:00:00:01,bridge=vmbr1,firewall=0
+var netConfigLine = regexp.MustCompile(`^(net\d+):\s*(.+)$`)
+
+// buildFirewallConfig 生成实例防火墙配置文件（/etc/pve/firewall/<vmid>.fw）
+// Proxmox按顺序匹配规则，拒绝规则排在允许规则之前；ICMP需要按地址族拆分
+func buildFirewallConfig(policy provider.FirewallPolicy) string {
+	policyIn := "ACCEPT"
+	if policy.DefaultAction == "deny" {
+		policyIn = "DROP"
+	}
+
+	var deny, allow []string
+	for _, r := range policy.Rules {
+		action := "ACCEPT"
+		if r.Action == "deny" {
+			action = "DROP"
+		}
+
+		var protocols []string
+		switch r.Protocol {
+		case "tcp", "udp":
+			protocols = []string{r.Protocol}
+		case "icmp":
+			isV6 := strings.Contains(r.Source, ":")
+			if r.Source == "" || !isV6 {
+				protocols = append(protocols, "icmp")
+			}
+			if r.Source == "" || isV6 {
+				protocols = append(protocols, "ipv6-icmp")
+			}
+		default:
+			protocols = []string{""}
+		}
+
+		for _, proto := range protocols {
+			line := "IN " + action
+			if proto != "" {
+				line += " -p " + proto
+			}
+			if proto == "tcp" || proto == "udp" {
+				if r.PortStart > 0 && r.PortEnd > r.PortStart {
+					line += fmt.Sprintf(" -dport %d:%d", r.PortStart, r.PortEnd)
+				} else if r.PortStart > 0 {
+					line += fmt.Sprintf(" -dport %d", r.PortStart)
+				}
+			}
+			if r.Source != "" {
+				line += " -source " + r.Source
+			}
+			if action == "DROP" {
+				deny = append(deny, line)
+			} else {
+				allow = append(allow, line)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# managed by oneclickvirt, do not edit\n")
+	b.WriteString("[OPTIONS]\n")
+	b.WriteString("enable: 1\n")
+	b.WriteString("policy_in: " + policyIn + "\n")
+	b.WriteString("policy_out: ACCEPT\n\n")
+	b.WriteString("[RULES]\n")
+	for _, line := range append(deny, allow...) {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// ApplyFirewall 为实例设置入站防火墙
+// 写入实例级防火墙配置并为所有网卡启用firewall标记；需要在数据中心级别启用防火墙后才会生效
+func (p *ProxmoxProvider) ApplyFirewall(ctx context.Context, instanceID string, policy provider.FirewallPolicy) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 防火墙配置通过pve配置文件和qm/pct命令管理，只通过SSH进行
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法设置实例防火墙")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	var tool string
+	switch instanceType {
+	case "vm":
+		tool = "qm"
+	case "container":
+		tool = "pct"
+	default:
+		return fmt.Errorf("unknown instance type: %s", instanceType)
+	}
+
+	fwPath := fmt.Sprintf("/etc/pve/firewall/%s.fw", vmid)
+	if err := p.sshClient.UploadContent(buildFirewallConfig(policy), fwPath, 0640); err != nil {
+		return fmt.Errorf("写入实例防火墙配置失败: %w", err)
+	}
+
+	if err := p.enableNICFirewall(tool, vmid); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("通过SSH成功设置Proxmox实例防火墙",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.Int("rules", len(policy.Rules)),
+		zap.String("defaultAction", policy.DefaultAction))
+	return nil
+}
+
+// enableNICFirewall 为实例的所有网卡启用firewall标记，已启用的网卡不做修改
+func (p *ProxmoxProvider) enableNICFirewall(tool, vmid string) error {
+	output, err := p.sshClient.Execute(fmt.Sprintf("%s config %s", tool, vmid))
+	if err != nil {
+		return fmt.Errorf("获取实例配置失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		m := netConfigLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		device, value := m[1], m[2]
+		switch {
+		case strings.Contains(value, "firewall=1"):
+			continue
+		case strings.Contains(value, "firewall=0"):
+			value = strings.Replace(value, "firewall=0", "firewall=1", 1)
+		default:
+			value += ",firewall=1"
+		}
+		cmd := fmt.Sprintf("%s set %s --%s '%s'", tool, vmid, device, value)
+		if output, err := p.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("启用网卡%s防火墙失败: %w, output: %s", device, err, utils.TruncateString(output, 200))
+		}
+	}
+	return nil
+}
+
+// RemoveFirewall 删除实例防火墙配置，网卡的firewall标记保留（没有实例级配置时不做过滤）
+func (p *ProxmoxProvider) RemoveFirewall(ctx context.Context, instanceID string) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法移除实例防火墙")
+	}
+
+	vmid, _, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		// 实例已不存在时没有需要清理的配置
+		return nil
+	}
+
+	if output, err := p.sshClient.Execute(fmt.Sprintf("rm -f /etc/pve/firewall/%s.fw", vmid)); err != nil {
+		return fmt.Errorf("删除实例防火墙配置失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return nil
+}
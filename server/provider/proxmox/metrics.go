@@ -0,0 +1,116 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// proxmoxResource /cluster/resources 中与资源使用相关的字段（数值单位：字节，cpu为占maxcpu的比例）
+type proxmoxResource struct {
+	VMID    json.Number `json:"vmid"`
+	Name    string      `json:"name"`
+	Node    string      `json:"node"`
+	Status  string      `json:"status"`
+	CPU     float64     `json:"cpu"`
+	MaxCPU  float64     `json:"maxcpu"`
+	Mem     int64       `json:"mem"`
+	MaxMem  int64       `json:"maxmem"`
+	Disk    int64       `json:"disk"`
+	MaxDisk int64       `json:"maxdisk"`
+}
+
+// GetInstancesUsage 批量获取当前节点上所有运行中实例的资源使用情况
+// 使用 /cluster/resources 一次获取虚拟机和容器的当前状态；虚拟机未安装guest agent时磁盘已用空间为0
+func (p *ProxmoxProvider) GetInstancesUsage(ctx context.Context) ([]provider.InstanceUsage, error) {
+	if !p.connected {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	// 根据执行规则判断使用哪种方式
+	if p.shouldUseAPI() {
+		usages, err := p.apiGetInstancesUsage(ctx)
+		if err == nil {
+			return usages, nil
+		}
+		global.APP_LOG.Warn("Proxmox API失败 - 获取实例资源使用情况", zap.Error(err))
+
+		// 检查是否可以回退到SSH
+		if !p.shouldFallbackToSSH() {
+			return nil, fmt.Errorf("API调用失败且不允许回退到SSH: %w", err)
+		}
+		global.APP_LOG.Debug("回退到SSH方式 - 获取实例资源使用情况")
+	}
+
+	// 使用SSH方式
+	if !p.shouldUseSSH() {
+		return nil, fmt.Errorf("执行规则不允许使用SSH")
+	}
+
+	output, err := p.sshClient.Execute("pvesh get /cluster/resources --type vm --output-format json")
+	if err != nil {
+		return nil, fmt.Errorf("获取实例状态失败: %w", err)
+	}
+	var resources []proxmoxResource
+	if err := json.Unmarshal([]byte(output), &resources); err != nil {
+		return nil, fmt.Errorf("解析实例状态失败: %w, output: %s", err, utils.TruncateString(output, 200))
+	}
+	return p.convertUsage(resources), nil
+}
+
+// apiGetInstancesUsage 通过API获取实例资源使用情况
+func (p *ProxmoxProvider) apiGetInstancesUsage(ctx context.Context) ([]provider.InstanceUsage, error) {
+	url := fmt.Sprintf("https://%s:8006/api2/json/cluster/resources?type=vm", p.config.Host)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置认证头
+	p.setAPIAuth(req)
+
+	resp, err := p.apiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API请求失败，状态码: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data []proxmoxResource `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return p.convertUsage(response.Data), nil
+}
+
+// convertUsage 转换为资源使用情况，只保留当前节点上运行中的实例
+func (p *ProxmoxProvider) convertUsage(resources []proxmoxResource) []provider.InstanceUsage {
+	usages := make([]provider.InstanceUsage, 0, len(resources))
+	for _, r := range resources {
+		if r.Status != "running" || (p.node != "" && r.Node != p.node) {
+			continue
+		}
+		usages = append(usages, provider.InstanceUsage{
+			Name:          r.Name,
+			ID:            r.VMID.String(),
+			CPUPercent:    r.CPU * r.MaxCPU * 100,
+			MemoryUsedMB:  r.Mem / 1024 / 1024,
+			MemoryTotalMB: r.MaxMem / 1024 / 1024,
+			DiskUsedMB:    r.Disk / 1024 / 1024,
+			DiskTotalMB:   r.MaxDisk / 1024 / 1024,
+		})
+	}
+	return usages
+}
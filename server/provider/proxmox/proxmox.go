@@ -151,6 +151,7 @@ func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfi
 
 	// 尝试 SSH 连接
 	sshConfig := utils.SSHConfig{
+		ProviderID:     config.ID,
 		Host:           config.Host,
 		Port:           config.Port,
 		Username:       config.Username,
@@ -192,6 +193,8 @@ func (p *ProxmoxProvider) Connect(ctx context.Context, config provider.NodeConfi
 
 	// 初始化健康检查器，使用Provider的SSH连接，避免创建独立连接导致节点混淆
 	healthConfig := health.HealthConfig{
+		ProviderID:    config.ID,
+		ProviderName:  config.Name,
 		Host:          config.Host,
 		Port:          config.Port,
 		Username:      config.Username,
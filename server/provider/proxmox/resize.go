@@ -0,0 +1,72 @@
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstance 调整实例规格
+// 虚拟机使用 qm set / qm resize scsi0，容器使用 pct set / pct resize rootfs；
+// 虚拟机未启用CPU/内存热插拔时，新的CPU和内存在下次重启后生效
+func (p *ProxmoxProvider) ResizeInstance(ctx context.Context, instanceID string, spec provider.ResizeSpec) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 规格调整依赖qm/pct命令，只通过SSH进行
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法调整实例规格")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	var tool, diskName string
+	switch instanceType {
+	case "vm":
+		tool, diskName = "qm", "scsi0"
+	case "container":
+		tool, diskName = "pct", "rootfs"
+	default:
+		return fmt.Errorf("unknown instance type: %s", instanceType)
+	}
+
+	var options []string
+	if spec.CPU > 0 {
+		options = append(options, fmt.Sprintf("--cores %d", spec.CPU))
+	}
+	if spec.MemoryMB > 0 {
+		options = append(options, fmt.Sprintf("--memory %d", spec.MemoryMB))
+	}
+	if len(options) > 0 {
+		cmd := fmt.Sprintf("%s set %s %s", tool, vmid, strings.Join(options, " "))
+		if output, err := p.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("调整CPU/内存失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	if spec.DiskMB > 0 {
+		cmd := fmt.Sprintf("%s resize %s %s %dM", tool, vmid, diskName, spec.DiskMB)
+		if output, err := p.sshClient.Execute(cmd); err != nil {
+			return fmt.Errorf("扩容磁盘失败: %w, output: %s", err, utils.TruncateString(output, 200))
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功调整Proxmox实例规格",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.Int("cpu", spec.CPU),
+		zap.Int64("memoryMB", spec.MemoryMB),
+		zap.Int64("diskMB", spec.DiskMB))
+	return nil
+}
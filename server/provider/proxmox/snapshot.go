@@ -0,0 +1,205 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// snapshotCommand 根据实例类型返回快照命令前缀（qm 或 pct）
+func snapshotCommand(instanceType string) (string, error) {
+	switch instanceType {
+	case "vm":
+		return "qm", nil
+	case "container":
+		return "pct", nil
+	default:
+		return "", fmt.Errorf("unknown instance type: %s", instanceType)
+	}
+}
+
+// CreateSnapshot 创建实例快照
+func (p *ProxmoxProvider) CreateSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	// 快照操作依赖qm/pct命令，只通过SSH进行
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法操作快照")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	cmd, err := snapshotCommand(instanceType)
+	if err != nil {
+		return err
+	}
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("%s snapshot %s %s", cmd, vmid, snapshotName))
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot for %s %s: %w, output: %s", instanceType, vmid, err, utils.TruncateString(output, 200))
+	}
+
+	global.APP_LOG.Info("通过SSH成功创建Proxmox快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
+
+// ListSnapshots 列出实例快照
+func (p *ProxmoxProvider) ListSnapshots(ctx context.Context, instanceID string) ([]provider.Snapshot, error) {
+	if !p.connected {
+		return nil, fmt.Errorf("provider not connected")
+	}
+
+	if !p.shouldUseSSH() {
+		return nil, fmt.Errorf("执行规则不允许使用SSH，无法操作快照")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	apiType := "qemu"
+	if instanceType == "container" {
+		apiType = "lxc"
+	}
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("pvesh get /nodes/%s/%s/%s/snapshot --output-format json", p.node, apiType, vmid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var items []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		SnapTime    int64  `json:"snaptime"`
+		Parent      string `json:"parent"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &items); err != nil {
+		return nil, fmt.Errorf("解析快照列表失败: %w", err)
+	}
+
+	snapshots := make([]provider.Snapshot, 0, len(items))
+	for _, item := range items {
+		// current 表示当前状态，不是真正的快照
+		if item.Name == "current" {
+			continue
+		}
+		snapshots = append(snapshots, provider.Snapshot{
+			Name:        item.Name,
+			Description: item.Description,
+			Created:     time.Unix(item.SnapTime, 0),
+			Metadata: map[string]string{
+				"vmid":   vmid,
+				"parent": item.Parent,
+			},
+		})
+	}
+
+	return snapshots, nil
+}
+
+// RestoreSnapshot 将实例回滚到指定快照
+func (p *ProxmoxProvider) RestoreSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法操作快照")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	cmd, err := snapshotCommand(instanceType)
+	if err != nil {
+		return err
+	}
+
+	// 记录回滚前的运行状态，不含内存状态的快照回滚后实例会处于停止状态
+	statusOutput, _ := p.sshClient.Execute(fmt.Sprintf("%s status %s", cmd, vmid))
+	wasRunning := strings.Contains(statusOutput, "running")
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("%s rollback %s %s", cmd, vmid, snapshotName))
+	if err != nil {
+		return fmt.Errorf("failed to rollback %s %s: %w, output: %s", instanceType, vmid, err, utils.TruncateString(output, 200))
+	}
+
+	if wasRunning {
+		statusOutput, _ = p.sshClient.Execute(fmt.Sprintf("%s status %s", cmd, vmid))
+		if !strings.Contains(statusOutput, "running") {
+			if _, err := p.sshClient.Execute(fmt.Sprintf("%s start %s", cmd, vmid)); err != nil {
+				global.APP_LOG.Warn("快照回滚后启动实例失败",
+					zap.String("vmid", vmid),
+					zap.String("type", instanceType),
+					zap.Error(err))
+			}
+		}
+	}
+
+	global.APP_LOG.Info("通过SSH成功回滚Proxmox快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
+
+// DeleteSnapshot 删除实例快照
+func (p *ProxmoxProvider) DeleteSnapshot(ctx context.Context, instanceID, snapshotName string) error {
+	if !p.connected {
+		return fmt.Errorf("provider not connected")
+	}
+
+	if !p.shouldUseSSH() {
+		return fmt.Errorf("执行规则不允许使用SSH，无法操作快照")
+	}
+
+	vmid, instanceType, err := p.findVMIDByNameOrID(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to find instance %s: %w", instanceID, err)
+	}
+
+	cmd, err := snapshotCommand(instanceType)
+	if err != nil {
+		return err
+	}
+
+	output, err := p.sshClient.Execute(fmt.Sprintf("%s delsnapshot %s %s", cmd, vmid, snapshotName))
+	if err != nil {
+		// 快照不存在，视为删除成功
+		if strings.Contains(output, "does not exist") {
+			global.APP_LOG.Info("快照已不存在，视为删除成功",
+				zap.String("vmid", vmid),
+				zap.String("snapshot", snapshotName))
+			return nil
+		}
+		return fmt.Errorf("failed to delete snapshot for %s %s: %w", instanceType, vmid, err)
+	}
+
+	global.APP_LOG.Info("通过SSH成功删除Proxmox快照",
+		zap.String("id", utils.TruncateString(instanceID, 50)),
+		zap.String("vmid", vmid),
+		zap.String("type", instanceType),
+		zap.String("snapshot", snapshotName))
+	return nil
+}
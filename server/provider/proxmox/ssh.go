@@ -852,11 +852,18 @@ func (p *ProxmoxProvider) sshSetInstancePassword(ctx context.Context, instanceID
 // configureInstanceNetwork 配置实例网络
 func (p *ProxmoxProvider) configureInstanceNetwork(ctx context.Context, vmid int, config provider.InstanceConfig) error {
 	// 根据实例类型配置网络
+	var err error
 	if config.InstanceType == "container" {
-		return p.configureContainerNetwork(ctx, vmid, config)
+		err = p.configureContainerNetwork(ctx, vmid, config)
 	} else {
-		return p.configureVMNetwork(ctx, vmid, config)
+		err = p.configureVMNetwork(ctx, vmid, config)
 	}
+	if err != nil {
+		return err
+	}
+
+	// 地址池分配了独立IP时覆盖默认的NAT网络配置
+	return p.configureDedicatedIP(ctx, vmid, config)
 }
 
 // configureContainerNetwork 配置容器网络
@@ -873,6 +880,11 @@ func (p *ProxmoxProvider) configureContainerNetwork(ctx context.Context, vmid in
 		networkConfig.NetworkType == "dedicated_ipv4_ipv6" ||
 		networkConfig.NetworkType == "ipv6_only"
 
+	// 地址池已分配独立IPv6时不再使用宿主机的IPv6配置
+	if parseDedicatedIPConfig(config).IPv6 != "" {
+		hasIPv6 = false
+	}
+
 	if hasIPv6 {
 		// 配置IPv6网络（会根据NetworkType自动处理IPv4+IPv6或纯IPv6）
 		if err := p.configureInstanceIPv6(ctx, vmid, config, "container"); err != nil {
@@ -915,6 +927,11 @@ func (p *ProxmoxProvider) configureVMNetwork(ctx context.Context, vmid int, conf
 		networkConfig.NetworkType == "dedicated_ipv4_ipv6" ||
 		networkConfig.NetworkType == "ipv6_only"
 
+	// 地址池已分配独立IPv6时不再使用宿主机的IPv6配置
+	if parseDedicatedIPConfig(config).IPv6 != "" {
+		hasIPv6 = false
+	}
+
 	if hasIPv6 {
 		// 配置IPv6网络（会根据NetworkType自动处理IPv4+IPv6或纯IPv6）
 		if err := p.configureInstanceIPv6(ctx, vmid, config, "vm"); err != nil {
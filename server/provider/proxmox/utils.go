@@ -311,11 +311,79 @@ func (p *ProxmoxProvider) cleanupVMFiles(ctx context.Context, vmid string) error
 		}
 	}
 
+	// 删除user-data snippets文件
+	p.removeVMUserData(ctx, vmid)
+
 	// 删除VM目录
 	vmDir := fmt.Sprintf("/root/vm%s", vmid)
 	return p.safeRemove(ctx, vmDir)
 }
 
+// userDataSnippetName 虚拟机user-data在snippets存储中的文件名
+func userDataSnippetName(vmid string) string {
+	return fmt.Sprintf("oneclickvirt-%s-user-data.yaml", vmid)
+}
+
+// findSnippetsStorage 查找启用了snippets内容类型的可用存储
+func (p *ProxmoxProvider) findSnippetsStorage() (string, error) {
+	output, err := p.sshClient.Execute("pvesm status --content snippets 2>/dev/null | awk 'NR > 1 && $3 == \"active\" {print $1}' | head -n 1")
+	if err != nil {
+		return "", fmt.Errorf("查询snippets存储失败: %w", err)
+	}
+	storage := strings.TrimSpace(output)
+	if storage == "" {
+		return "", fmt.Errorf("节点上没有启用snippets内容类型的存储")
+	}
+	return storage, nil
+}
+
+// configureVMUserData 将用户提供的user-data写入snippets存储并挂载为cloud-init vendor-data
+// 使用vendor-data而不是user，避免覆盖Proxmox根据ciuser/cipassword生成的user配置
+func (p *ProxmoxProvider) configureVMUserData(vmid int, userData string) error {
+	storage, err := p.findSnippetsStorage()
+	if err != nil {
+		return err
+	}
+
+	volid := fmt.Sprintf("%s:snippets/%s", storage, userDataSnippetName(strconv.Itoa(vmid)))
+	pathOutput, err := p.sshClient.Execute(fmt.Sprintf("pvesm path '%s'", volid))
+	if err != nil {
+		return fmt.Errorf("解析snippets路径失败: %w", err)
+	}
+	snippetPath := strings.TrimSpace(pathOutput)
+	if snippetPath == "" {
+		return fmt.Errorf("无法解析snippets路径: %s", volid)
+	}
+
+	if err := p.sshClient.UploadContent(userData, snippetPath, 0644); err != nil {
+		return fmt.Errorf("上传user-data失败: %w", err)
+	}
+
+	if _, err := p.sshClient.Execute(fmt.Sprintf("qm set %d --cicustom vendor=%s", vmid, volid)); err != nil {
+		return fmt.Errorf("设置cicustom失败: %w", err)
+	}
+
+	global.APP_LOG.Info("已应用虚拟机user-data",
+		zap.Int("vmid", vmid),
+		zap.String("volid", volid),
+		zap.Int("size", len(userData)))
+	return nil
+}
+
+// removeVMUserData 删除虚拟机的user-data snippets文件（不存在时忽略）
+func (p *ProxmoxProvider) removeVMUserData(ctx context.Context, vmid string) {
+	storage, err := p.findSnippetsStorage()
+	if err != nil {
+		return
+	}
+	pathOutput, _ := p.sshClient.Execute(fmt.Sprintf("pvesm path '%s:snippets/%s' 2>/dev/null || true", storage, userDataSnippetName(vmid)))
+	if snippetPath := strings.TrimSpace(pathOutput); snippetPath != "" {
+		if err := p.safeRemove(ctx, snippetPath); err != nil {
+			global.APP_LOG.Warn("删除user-data文件失败", zap.String("vmid", vmid), zap.Error(err))
+		}
+	}
+}
+
 // cleanupCTFiles 清理CT相关文件
 func (p *ProxmoxProvider) cleanupCTFiles(ctx context.Context, ctid string) error {
 	global.APP_LOG.Info("清理CT文件", zap.String("ctid", ctid))
@@ -14,7 +14,7 @@ import (
 // InitAdminRouter 管理员路由
 func InitAdminRouter(Router *gin.RouterGroup) {
 	AdminGroup := Router.Group("/v1/admin")
-	AdminGroup.Use(middleware.RequireAuth(authModel.AuthLevelAdmin))
+	AdminGroup.Use(middleware.RequireAuth(authModel.AuthLevelAdmin), middleware.AuditLog())
 	{
 		// 仪表盘
 		AdminGroup.GET("/dashboard", admin.GetAdminDashboard)
@@ -31,6 +31,7 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.PUT("/users/:id/status", admin.UpdateUserStatus)
 		AdminGroup.PUT("/users/:id/level", admin.UpdateUserLevel)
 		AdminGroup.PUT("/users/:id/reset-password", admin.ResetUserPassword)
+		AdminGroup.DELETE("/users/:id/2fa", admin.ResetUserTwoFactor)
 		AdminGroup.PUT("/users/batch-level", admin.AdminBatchUpdateUserLevel)
 		AdminGroup.PUT("/users/batch-status", admin.AdminBatchUpdateUserStatus)
 		AdminGroup.POST("/users/batch-delete", admin.AdminBatchDeleteUsers)
@@ -43,6 +44,8 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.POST("/instances/:id/action", admin.AdminInstanceAction)
 		AdminGroup.PUT("/instances/:id/reset-password", admin.ResetInstancePassword)
 		AdminGroup.GET("/instances/:id/password/:taskId", admin.GetInstanceNewPassword)
+		AdminGroup.POST("/instances/:id/migrate", admin.MigrateInstance)
+		AdminGroup.GET("/instances/:id/metrics", admin.GetInstanceMetricsAdmin)
 		AdminGroup.GET("/instance-type-permissions", admin.GetAdminInstanceTypePermissions)
 		AdminGroup.PUT("/instance-type-permissions", admin.UpdateAdminInstanceTypePermissions)
 		AdminGroup.GET("/instances/:id/ssh", admin.AdminSSHWebSocket) // 管理员WebSocket SSH连接
@@ -98,6 +101,20 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.POST("/providers/:id/health-check", admin.CheckProviderHealth)
 		AdminGroup.GET("/providers/:id/status", admin.GetProviderStatus)
 
+		// 健康检查历史与可用率
+		AdminGroup.GET("/providers/uptime", admin.GetProvidersUptime)
+		AdminGroup.GET("/providers/:id/uptime", admin.GetProviderUptime)
+		AdminGroup.GET("/providers/:id/outages", admin.GetProviderOutages)
+		AdminGroup.GET("/providers/:id/health-history", admin.GetProviderHealthHistory)
+
+		// SSH主机密钥
+		AdminGroup.GET("/providers/:id/host-key", admin.GetProviderHostKey)
+		AdminGroup.POST("/providers/:id/host-key/accept", admin.AcceptProviderHostKey)
+		AdminGroup.DELETE("/providers/:id/host-key", admin.ResetProviderHostKey)
+
+		// 实例资源使用
+		AdminGroup.GET("/providers/:id/instance-metrics", admin.GetProviderInstanceMetrics)
+
 		// 配置导出
 		AdminGroup.POST("/providers/export-configs", admin.ExportProviderConfigs)
 
@@ -133,6 +150,64 @@ func InitAdminRouter(Router *gin.RouterGroup) {
 		AdminGroup.GET("/providers/:id/port-usage", admin.GetProviderPortUsage)
 		AdminGroup.GET("/instances/:id/port-mappings", admin.GetInstancePortMappings)
 
+		// 快照管理
+		AdminGroup.GET("/snapshots", admin.GetSnapshotList)
+		AdminGroup.POST("/snapshots/:id/restore", admin.RestoreSnapshotAdmin)
+		AdminGroup.DELETE("/snapshots/:id", admin.DeleteSnapshotAdmin)
+		AdminGroup.GET("/instances/:id/snapshots", admin.GetInstanceSnapshotsAdmin)
+		AdminGroup.POST("/instances/:id/snapshots", admin.CreateInstanceSnapshotAdmin)
+
+		// 备份管理
+		AdminGroup.GET("/backups", admin.GetBackupList)
+		AdminGroup.POST("/backups/:id/restore", admin.RestoreBackupAdmin) // 可恢复到其他同类型Provider上的实例
+		AdminGroup.DELETE("/backups/:id", admin.DeleteBackupAdmin)
+		AdminGroup.GET("/instances/:id/backups", admin.GetInstanceBackupsAdmin)
+		AdminGroup.POST("/instances/:id/backups", admin.CreateInstanceBackupAdmin)
+		AdminGroup.PUT("/instances/:id/backup-schedule", admin.SetBackupScheduleAdmin)
+
+		// 安全组管理
+		AdminGroup.GET("/security-groups", admin.GetSecurityGroupList)
+		AdminGroup.POST("/instances/:id/security-group/reapply", admin.ReapplyInstanceSecurityGroup)
+
+		// 域名绑定管理
+		AdminGroup.GET("/domains", admin.GetDomainList)
+		AdminGroup.DELETE("/domains/:id", admin.DeleteDomainAdmin)
+		AdminGroup.POST("/providers/:id/domains/sync", admin.SyncProviderDomains)
+
+		// Webhook管理
+		AdminGroup.GET("/webhooks", admin.GetWebhookList)
+		AdminGroup.GET("/webhooks/events", admin.GetWebhookEvents)
+		AdminGroup.POST("/webhooks", admin.CreateWebhook)
+		AdminGroup.PUT("/webhooks/:id", admin.UpdateWebhook)
+		AdminGroup.DELETE("/webhooks/:id", admin.DeleteWebhook)
+		AdminGroup.POST("/webhooks/:id/test", admin.TestWebhook) // 同步发送测试事件
+		AdminGroup.GET("/webhook-deliveries", admin.GetWebhookDeliveries)
+		AdminGroup.POST("/webhook-deliveries/:id/redeliver", admin.RedeliverWebhook)
+
+		// 独立IP地址池管理
+		AdminGroup.GET("/ip-pools", admin.GetIPPoolList)
+		AdminGroup.POST("/ip-pools", admin.CreateIPPool)
+		AdminGroup.GET("/ip-pools/:id", admin.GetIPPool)
+		AdminGroup.PUT("/ip-pools/:id", admin.UpdateIPPool)
+		AdminGroup.DELETE("/ip-pools/:id", admin.DeleteIPPool)
+		AdminGroup.GET("/ip-pools/:id/addresses", admin.GetIPPoolAddresses)
+		AdminGroup.PUT("/ip-pools/:id/addresses/:addressId", admin.UpdateIPPoolAddress)
+
+		// 计费管理
+		AdminGroup.GET("/billing/prices", admin.GetBillingPriceList)
+		AdminGroup.POST("/billing/prices", admin.CreateBillingPrice)
+		AdminGroup.PUT("/billing/prices/:id", admin.UpdateBillingPrice)
+		AdminGroup.DELETE("/billing/prices/:id", admin.DeleteBillingPrice)
+		AdminGroup.GET("/billing/transactions", admin.GetBalanceTransactions)
+		AdminGroup.GET("/users/:id/balance", admin.GetUserBalance)
+		AdminGroup.POST("/users/:id/balance", admin.AdjustUserBalance) // 充值、退款或调整余额
+
+		// 通知管理
+		AdminGroup.GET("/notification-templates", admin.GetNotificationTemplates)
+		AdminGroup.PUT("/notification-templates/:event", admin.UpdateNotificationTemplate)
+		AdminGroup.DELETE("/notification-templates/:event", admin.ResetNotificationTemplate) // 恢复内置模板
+		AdminGroup.GET("/notification-logs", admin.GetNotificationLogs)
+
 		// 流量管理API
 		adminTrafficAPI := &traffic.AdminTrafficAPI{}
 		AdminGroup.GET("/traffic/overview", adminTrafficAPI.GetSystemTrafficOverview)
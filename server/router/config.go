@@ -12,7 +12,7 @@ import (
 func InitConfigRouter(Router *gin.RouterGroup) {
 	// 统一配置API
 	ConfigGroup := Router.Group("/v1/config")
-	ConfigGroup.Use(middleware.RequireAuth(authModel.AuthLevelAdmin))
+	ConfigGroup.Use(middleware.RequireAuth(authModel.AuthLevelAdmin), middleware.AuditLog())
 	{
 		ConfigGroup.GET("", config.GetUnifiedConfig)
 		ConfigGroup.PUT("", config.UpdateUnifiedConfig)
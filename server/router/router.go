@@ -13,11 +13,13 @@ func InitAuthRouter(Router *gin.RouterGroup) {
 	AuthRouter := Router.Group("v1/auth")
 	{
 		AuthRouter.POST("login", auth.Login)
+		AuthRouter.POST("login/2fa", auth.VerifyTwoFactorLogin)           // 两步验证登录
+		AuthRouter.POST("login/2fa/setup", auth.BeginTwoFactorLoginSetup) // 登录时绑定两步验证
 		AuthRouter.POST("register", auth.Register)
 		AuthRouter.GET("captcha", auth.GetCaptcha)
 		AuthRouter.POST("send-verify-code", auth.SendVerifyCode) // 发送登录验证码
 		AuthRouter.POST("forgot-password", auth.ForgotPassword)
 		AuthRouter.POST("reset-password", auth.ResetPassword)
-		AuthRouter.POST("logout", middleware.RequireAuth(authModel.AuthLevelUser), auth.Logout)
+		AuthRouter.POST("logout", middleware.RequireAuth(authModel.AuthLevelUser), middleware.AuditLog(), auth.Logout)
 	}
 }
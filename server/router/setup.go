@@ -52,6 +52,7 @@ func SetupRouter() *gin.Engine {
 
 	// API路由组
 	ApiGroup := Router.Group("/api")
+	ApiGroup.Use(middleware.IPRateLimit()) // IP限流，默认关闭，需通过 system.iplimit-enabled 启用
 	{
 		// 健康检查也在API路径下，保持与前端一致
 		ApiGroup.GET("/health", public.HealthCheck)
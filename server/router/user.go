@@ -15,7 +15,7 @@ import (
 // InitUserRouter 用户路由
 func InitUserRouter(Router *gin.RouterGroup) {
 	UserGroup := Router.Group("/v1")
-	UserGroup.Use(middleware.RequireAuth(authModel.AuthLevelUser))
+	UserGroup.Use(middleware.AllowAPIToken(), middleware.RequireAuth(authModel.AuthLevelUser), middleware.AuditLog()) // 个人API令牌只能访问用户路由
 	{
 		// 用户管理
 		UserGroup.GET("/user/profile", user.GetUserInfo)
@@ -25,6 +25,18 @@ func InitUserRouter(Router *gin.RouterGroup) {
 		UserGroup.GET("/user/dashboard", user.GetUserDashboard)
 		UserGroup.GET("/user/limits", user.GetUserLimits)
 
+		// 两步验证
+		UserGroup.GET("/user/2fa", user.GetTwoFactorStatus)
+		UserGroup.POST("/user/2fa/setup", user.SetupTwoFactor)
+		UserGroup.POST("/user/2fa/enable", user.EnableTwoFactor)
+		UserGroup.POST("/user/2fa/disable", user.DisableTwoFactor)
+		UserGroup.POST("/user/2fa/recovery-codes", user.RegenerateTwoFactorRecoveryCodes)
+
+		// API令牌
+		UserGroup.GET("/user/api-tokens", user.GetAPITokens)
+		UserGroup.POST("/user/api-tokens", user.CreateAPIToken)
+		UserGroup.DELETE("/user/api-tokens/:id", user.RevokeAPIToken)
+
 		// 实例管理
 		UserGroup.GET("/user/instances", user.GetUserInstances)
 		UserGroup.POST("/user/instances", user.CreateUserInstance)
@@ -34,13 +46,52 @@ func InitUserRouter(Router *gin.RouterGroup) {
 		UserGroup.GET("/user/instances/:id/pmacct/query", user.QueryInstancePmacctData)
 		UserGroup.PUT("/user/instances/:id/reset-password", user.ResetInstancePassword)
 		UserGroup.GET("/user/instances/:id/password/:taskId", user.GetInstanceNewPassword)
+		UserGroup.POST("/user/instances/:id/resize", user.ResizeInstance)
 		UserGroup.GET("/user/instances/:id/ports", user.GetInstancePorts)
-		UserGroup.GET("/user/instances/:id/ssh", user.SSHWebSocket) // WebSocket SSH连接
+		UserGroup.GET("/user/instances/:id/snapshots", user.GetInstanceSnapshots)
+		UserGroup.POST("/user/instances/:id/snapshots", user.CreateInstanceSnapshot)
+		UserGroup.POST("/user/instances/:id/snapshots/:snapshotId/restore", user.RestoreInstanceSnapshot)
+		UserGroup.DELETE("/user/instances/:id/snapshots/:snapshotId", user.DeleteInstanceSnapshot)
+		UserGroup.GET("/user/instances/:id/backups", user.GetInstanceBackups)
+		UserGroup.POST("/user/instances/:id/backups", user.CreateInstanceBackup)
+		UserGroup.GET("/user/instances/:id/backup-schedule", user.GetBackupSchedule)
+		UserGroup.PUT("/user/instances/:id/backup-schedule", user.SetBackupSchedule)
+		UserGroup.DELETE("/user/instances/:id/backup-schedule", user.DeleteBackupSchedule)
+		UserGroup.GET("/user/instances/:id/security-group", user.GetInstanceSecurityGroup)
+		UserGroup.PUT("/user/instances/:id/security-group", user.BindInstanceSecurityGroup)
+		UserGroup.DELETE("/user/instances/:id/security-group", user.UnbindInstanceSecurityGroup)
+		UserGroup.GET("/user/instances/:id/domains", user.GetInstanceDomains)
+		UserGroup.POST("/user/instances/:id/domains", user.CreateInstanceDomain)
+		UserGroup.POST("/user/backups/:backupId/restore", user.RestoreBackup)
+		UserGroup.DELETE("/user/backups/:backupId", user.DeleteBackup)
+		UserGroup.GET("/user/instances/:id/ssh", user.SSHWebSocket)         // WebSocket SSH连接
+		UserGroup.GET("/user/instances/:id/console", user.ConsoleWebSocket) // WebSocket 串口/VNC控制台
 		UserGroup.POST("/user/instances/action", user.InstanceAction)
 
+		// 安全组
+		UserGroup.GET("/user/security-groups", user.GetSecurityGroups)
+		UserGroup.POST("/user/security-groups", user.CreateSecurityGroup)
+		UserGroup.PUT("/user/security-groups/:id", user.UpdateSecurityGroup)
+		UserGroup.DELETE("/user/security-groups/:id", user.DeleteSecurityGroup)
+
+		// 域名绑定
+		UserGroup.GET("/user/domains", user.GetUserDomains)
+		UserGroup.POST("/user/domains/:id/verify", user.VerifyUserDomain)
+		UserGroup.DELETE("/user/domains/:id", user.DeleteUserDomain)
+
 		// 端口映射
 		UserGroup.GET("/user/port-mappings", user.GetUserPortMappings)
 
+		// 余额与计费
+		UserGroup.GET("/user/billing/balance", user.GetBalance)
+		UserGroup.GET("/user/billing/transactions", user.GetBalanceTransactions)
+
+		// 通知设置
+		UserGroup.GET("/user/notification-settings", user.GetNotificationSetting)
+		UserGroup.PUT("/user/notification-settings", user.UpdateNotificationSetting)
+		UserGroup.POST("/user/notification-settings/test", user.TestNotification)
+		UserGroup.GET("/user/notification-logs", user.GetNotificationLogs)
+
 		// 资源管理
 		UserGroup.GET("/user/resources/available", user.GetAvailableResources)
 		UserGroup.POST("/user/resources/claim", user.ClaimResource)
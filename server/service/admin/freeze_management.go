@@ -7,6 +7,7 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/model/user"
+	"oneclickvirt/service/notify"
 	"oneclickvirt/service/scheduler"
 
 	"go.uber.org/zap"
@@ -135,7 +136,8 @@ func (s *FreezeManagementService) SetInstanceExpiry(instanceID uint, expiresAt t
 
 // FreezeProvider 手动冻结Provider
 func (s *FreezeManagementService) FreezeProvider(providerID uint, reason string) error {
-	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+	var frozenInstances []provider.Instance
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
 
 		if reason == "" {
@@ -154,6 +156,11 @@ func (s *FreezeManagementService) FreezeProvider(providerID uint, reason string)
 		}
 
 		// 冻结该Provider下所有未手动设置过期时间的实例
+		if err := tx.Select("id, name, user_id").
+			Where("provider_id = ? AND is_manual_expiry = ? AND is_frozen = ?", providerID, false, false).
+			Find(&frozenInstances).Error; err != nil {
+			return err
+		}
 		if err := tx.Model(&provider.Instance{}).
 			Where("provider_id = ? AND is_manual_expiry = ? AND is_frozen = ?", providerID, false, false).
 			Updates(map[string]interface{}{
@@ -166,6 +173,14 @@ func (s *FreezeManagementService) FreezeProvider(providerID uint, reason string)
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range frozenInstances {
+		notify.NotifyFrozen(inst.UserID, "实例 "+inst.Name, "node_frozen")
+	}
+	return nil
 }
 
 // FreezeInstance 手动冻结实例
@@ -176,13 +191,21 @@ func (s *FreezeManagementService) FreezeInstance(instanceID uint, reason string)
 		reason = "manual"
 	}
 
-	return global.APP_DB.Model(&provider.Instance{}).
+	if err := global.APP_DB.Model(&provider.Instance{}).
 		Where("id = ?", instanceID).
 		Updates(map[string]interface{}{
 			"is_frozen":     true,
 			"frozen_at":     now,
 			"frozen_reason": reason,
-		}).Error
+		}).Error; err != nil {
+		return err
+	}
+
+	var inst provider.Instance
+	if err := global.APP_DB.Select("id, name, user_id").First(&inst, instanceID).Error; err == nil {
+		notify.NotifyFrozen(inst.UserID, "实例 "+inst.Name, reason)
+	}
+	return nil
 }
 
 // UnfreezeUser 解冻用户
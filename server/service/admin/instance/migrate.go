@@ -0,0 +1,175 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	resourceModel "oneclickvirt/model/resource"
+	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/ipam"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrateInstance 管理员将实例冷迁移到同类型的其他Provider（异步任务）
+func (s *Service) MigrateInstance(instanceID uint, req adminModel.MigrateInstanceRequest) (uint, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("实例不存在")
+		}
+		return 0, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return 0, fmt.Errorf("实例当前状态为 %s，只有运行中或已停止的实例可以迁移", instance.Status)
+	}
+	if req.TargetProviderID == instance.ProviderID {
+		return 0, errors.New("目标Provider与实例当前所在Provider相同")
+	}
+
+	var existingTask adminModel.Task
+	if err := global.APP_DB.Where("instance_id = ? AND status IN ('pending', 'running')", instance.ID).First(&existingTask).Error; err == nil {
+		return 0, fmt.Errorf("实例有正在进行的%s任务，请稍后重试", existingTask.TaskType)
+	}
+
+	if err := s.validateMigrationTarget(&instance, req.TargetProviderID); err != nil {
+		return 0, err
+	}
+
+	taskReq := adminModel.MigrateInstanceTaskRequest{
+		InstanceID:       instance.ID,
+		SourceProviderID: instance.ProviderID,
+		TargetProviderID: req.TargetProviderID,
+		OriginalStatus:   instance.Status,
+	}
+	taskDataJSON, err := json.Marshal(taskReq)
+	if err != nil {
+		return 0, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	// 先锁定实例状态，防止创建任务期间有其他操作
+	result := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", instance.ID, instance.Status).
+		Update("status", "migrating")
+	if result.Error != nil {
+		return 0, fmt.Errorf("更新实例状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, errors.New("实例状态已变化，请刷新后重试")
+	}
+
+	task, err := s.taskService.CreateTask(instance.UserID, &instance.ProviderID, &instance.ID, "migrate", string(taskDataJSON), 7200)
+	if err != nil {
+		global.APP_DB.Model(&providerModel.Instance{}).
+			Where("id = ? AND status = ?", instance.ID, "migrating").
+			Update("status", instance.Status)
+		return 0, fmt.Errorf("创建迁移任务失败: %v", err)
+	}
+
+	// 迁移由管理员发起，不允许用户取消
+	if err := global.APP_DB.Model(task).Update("is_force_stoppable", false).Error; err != nil {
+		global.APP_LOG.Warn("更新任务可取消状态失败", zap.Uint("taskId", task.ID), zap.Error(err))
+	}
+
+	global.APP_LOG.Info("管理员创建实例迁移任务成功",
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name),
+		zap.Uint("sourceProviderId", instance.ProviderID),
+		zap.Uint("targetProviderId", req.TargetProviderID),
+		zap.Uint("taskId", task.ID))
+
+	return task.ID, nil
+}
+
+// validateMigrationTarget 检查目标Provider能否接收实例
+func (s *Service) validateMigrationTarget(instance *providerModel.Instance, targetProviderID uint) error {
+	var source, target providerModel.Provider
+	if err := global.APP_DB.First(&source, instance.ProviderID).Error; err != nil {
+		return fmt.Errorf("获取源Provider失败: %v", err)
+	}
+	if err := global.APP_DB.First(&target, targetProviderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("目标Provider不存在")
+		}
+		return fmt.Errorf("获取目标Provider失败: %v", err)
+	}
+
+	if target.Type != source.Type {
+		return fmt.Errorf("目标Provider类型 %s 与源Provider类型 %s 不一致", target.Type, source.Type)
+	}
+	if target.Status != "active" {
+		return errors.New("目标Provider未启用")
+	}
+	if target.IsFrozen {
+		return errors.New("目标Provider已被冻结")
+	}
+	if target.ExpiresAt != nil && target.ExpiresAt.Before(time.Now()) {
+		return errors.New("目标Provider已过期")
+	}
+	if err := ipam.NewService().ValidateMigration(instance.ID, &source, &target); err != nil {
+		return err
+	}
+
+	resourceService := &resources.ResourceService{}
+	if err := resourceService.ValidateInstanceTypeSupport(target.ID, instance.InstanceType); err != nil {
+		return err
+	}
+	checkResult, err := resourceService.CheckProviderResources(resourceModel.ResourceCheckRequest{
+		ProviderID:   target.ID,
+		InstanceType: instance.InstanceType,
+		CPU:          instance.CPU,
+		Memory:       instance.Memory,
+		Disk:         instance.Disk,
+	})
+	if err != nil {
+		return fmt.Errorf("检查目标Provider资源失败: %v", err)
+	}
+	if !checkResult.Allowed {
+		return fmt.Errorf("目标Provider资源不足: %s", checkResult.Reason)
+	}
+
+	if err := resources.NewQuotaService().ValidateInstanceTransfer(instance, target.ID); err != nil {
+		return err
+	}
+
+	// 迁移依赖源和目标Provider的导出/导入能力
+	providerApiService := &provider2.ProviderApiService{}
+	for _, providerID := range []uint{source.ID, target.ID} {
+		prov, _, err := providerApiService.GetProviderByID(providerID)
+		if err != nil {
+			return fmt.Errorf("连接Provider失败: %v", err)
+		}
+		if _, ok := prov.(provider.BackupProvider); !ok {
+			return fmt.Errorf("Provider类型 %s 不支持实例迁移", prov.GetType())
+		}
+	}
+
+	var count int64
+	if err := global.APP_DB.Unscoped().Model(&providerModel.Instance{}).
+		Where("name = ? AND provider_id = ?", instance.Name, target.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("检查目标Provider实例名称失败: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("目标Provider上已存在同名实例 %s", instance.Name)
+	}
+
+	var image systemModel.SystemImage
+	if err := global.APP_DB.Where("name = ? AND provider_type = ? AND instance_type = ? AND architecture = ?",
+		instance.Image, target.Type, instance.InstanceType, target.Architecture).
+		First(&image).Error; err != nil {
+		return fmt.Errorf("目标Provider缺少镜像 %s（%s）", instance.Image, target.Architecture)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"oneclickvirt/service/database"
+	"oneclickvirt/service/images"
 	"oneclickvirt/service/interfaces"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/service/traffic"
@@ -435,9 +436,20 @@ func (s *Service) InstanceAction(instanceID uint, req admin.InstanceActionReques
 			"providerId": instance.ProviderID,
 		}
 
-		// 如果是重置操作，在创建任务前就添加原始状态
+		// 如果是重置操作，在创建任务前就添加原始状态；指定了目标镜像时重装为该镜像
 		if req.Action == "reset" {
 			taskData["originalStatus"] = instance.Status
+			if req.ImageID > 0 {
+				var provider providerModel.Provider
+				if err := global.APP_DB.First(&provider, instance.ProviderID).Error; err != nil {
+					return fmt.Errorf("获取Provider信息失败: %v", err)
+				}
+				imageService := &images.ImageService{}
+				if _, err := imageService.ValidateReinstallImage(req.ImageID, &instance, &provider); err != nil {
+					return err
+				}
+				taskData["imageId"] = req.ImageID
+			}
 		}
 
 		// 将taskData序列化为JSON字符串
@@ -7,8 +7,10 @@ import (
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/provider/health"
 	"oneclickvirt/service/database"
+	"oneclickvirt/service/healthhistory"
 	"oneclickvirt/service/images"
 	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
 	"strings"
 	"time"
 
@@ -129,6 +131,11 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 		}
 	}
 
+	// 主机密钥不一致时连接被拒绝，单独标记以便管理员核实并确认新密钥
+	if sshStatus != "online" && utils.HasHostKeyMismatch(localProviderID) {
+		sshStatus = providerModel.SSHStatusKeyMismatch
+	}
+
 	// 如果SSH连接成功且（强制刷新或资源信息尚未同步），获取系统资源信息
 	shouldSyncResources := sshStatus == "online" && (forceRefresh || !provider.ResourceSynced)
 	if shouldSyncResources {
@@ -208,7 +215,7 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 	// 更新整体状态
 	if sshStatus == "online" && (apiStatus == "online" || apiStatus == "N/A" || apiStatus == "unknown") {
 		provider.Status = "active"
-	} else if sshStatus == "offline" && apiStatus == "offline" {
+	} else if (sshStatus == "offline" || sshStatus == providerModel.SSHStatusKeyMismatch) && apiStatus == "offline" {
 		provider.Status = "inactive"
 	} else {
 		provider.Status = "partial" // 部分连接正常
@@ -222,6 +229,9 @@ func (s *Service) CheckProviderHealthWithOptions(providerID uint, forceRefresh b
 		return fmt.Errorf("保存Provider状态失败: %w", dbErr)
 	}
 
+	// 记录健康检查历史，用于可用率统计
+	healthhistory.Record(localProviderID, provider.Status, sshStatus, apiStatus, time.Since(now), err)
+
 	// 如果健康检查有错误，返回该错误（这样前端可以获取具体错误信息）
 	return err
 }
@@ -139,6 +139,21 @@ func (s *Service) DeleteProvider(providerID uint, forceDelete bool) error {
 				zap.Int64("count", instanceResult.RowsAffected))
 		}
 
+		// 硬删除独立IP地址池及其地址
+		if err := tx.Where("provider_id = ?", providerID).Delete(&providerModel.IPAddress{}).Error; err != nil {
+			global.APP_LOG.Error("删除Provider地址池地址失败", zap.Error(err))
+			return err
+		}
+		if err := tx.Unscoped().Where("provider_id = ?", providerID).Delete(&providerModel.IPPool{}).Error; err != nil {
+			global.APP_LOG.Error("删除Provider地址池失败", zap.Error(err))
+			return err
+		}
+
+		if err := tx.Where("provider_id = ?", providerID).Delete(&providerModel.ProviderHostKey{}).Error; err != nil {
+			global.APP_LOG.Error("删除Provider SSH主机密钥失败", zap.Error(err))
+			return err
+		}
+
 		// 5. 硬删除Provider本身
 		if err := tx.Unscoped().Delete(&providerModel.Provider{}, providerID).Error; err != nil {
 			global.APP_LOG.Error("删除Provider记录失败", zap.Error(err))
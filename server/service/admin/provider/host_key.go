@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	providerService "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GetHostKey 获取Provider已记录的SSH主机密钥，尚未连接过时返回nil
+func (s *Service) GetHostKey(providerID uint) (*providerModel.ProviderHostKey, error) {
+	var record providerModel.ProviderHostKey
+	err := global.APP_DB.Where("provider_id = ?", providerID).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询SSH主机密钥失败: %v", err)
+	}
+	return &record, nil
+}
+
+// AcceptHostKey 确认检测到的新主机密钥，替换已记录的密钥后重新连接并检查健康状态
+// 指纹必须与待确认密钥一致，避免管理员确认的密钥在查看后又被替换
+func (s *Service) AcceptHostKey(providerID uint, fingerprint string) (*providerModel.ProviderHostKey, error) {
+	record, err := s.GetHostKey(providerID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.PendingFingerprint == "" {
+		return nil, fmt.Errorf("没有待确认的SSH主机密钥")
+	}
+	if record.PendingFingerprint != fingerprint {
+		return nil, fmt.Errorf("指纹与检测到的新密钥不一致，请刷新后重新核对")
+	}
+
+	oldFingerprint := record.Fingerprint
+	result := global.APP_DB.Model(&providerModel.ProviderHostKey{}).
+		Where("id = ? AND pending_fingerprint = ?", record.ID, fingerprint).
+		Updates(map[string]interface{}{
+			"key_type":            record.PendingKeyType,
+			"public_key":          record.PendingPublicKey,
+			"fingerprint":         record.PendingFingerprint,
+			"pending_key_type":    "",
+			"pending_public_key":  "",
+			"pending_fingerprint": "",
+			"mismatch_at":         nil,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新SSH主机密钥失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("待确认的SSH主机密钥已变化，请刷新后重新核对")
+	}
+
+	global.APP_LOG.Warn("管理员已确认Provider新的SSH主机密钥",
+		zap.Uint("providerID", providerID),
+		zap.String("oldFingerprint", oldFingerprint),
+		zap.String("newFingerprint", fingerprint))
+
+	s.reconnectAfterHostKeyChange(providerID)
+	return s.GetHostKey(providerID)
+}
+
+// ResetHostKey 清除已记录的SSH主机密钥，下次连接时重新记录（用于节点重装系统等已知的密钥变更）
+func (s *Service) ResetHostKey(providerID uint) error {
+	if err := global.APP_DB.Where("provider_id = ?", providerID).Delete(&providerModel.ProviderHostKey{}).Error; err != nil {
+		return fmt.Errorf("清除SSH主机密钥失败: %v", err)
+	}
+
+	global.APP_LOG.Warn("管理员已清除Provider的SSH主机密钥记录", zap.Uint("providerID", providerID))
+
+	s.reconnectAfterHostKeyChange(providerID)
+	return nil
+}
+
+// reconnectAfterHostKeyChange 主机密钥变更后丢弃旧连接，重新加载Provider并刷新健康状态
+func (s *Service) reconnectAfterHostKeyChange(providerID uint) {
+	if global.APP_SSH_POOL != nil {
+		if pool, ok := global.APP_SSH_POOL.(interface {
+			Remove(uint)
+		}); ok {
+			pool.Remove(providerID)
+		}
+	}
+
+	go func() {
+		if err := providerService.GetProviderService().ReloadProvider(providerID); err != nil {
+			global.APP_LOG.Warn("主机密钥变更后重新加载Provider失败",
+				zap.Uint("providerID", providerID),
+				zap.Error(err))
+		}
+		if err := s.CheckProviderHealth(providerID); err != nil {
+			global.APP_LOG.Warn("主机密钥变更后健康检查失败",
+				zap.Uint("providerID", providerID),
+				zap.Error(err))
+		}
+	}()
+}
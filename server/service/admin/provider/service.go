@@ -285,6 +285,9 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 		provider.ExpiresAt = &defaultExpiry
 	}
 
+	// SSH地址或端口变化后已记录的主机密钥不再适用，下次连接时重新记录
+	sshAddressChanged := req.Endpoint != provider.Endpoint || req.SSHPort != provider.SSHPort
+
 	provider.Name = req.Name
 	provider.Type = req.Type
 	provider.Endpoint = req.Endpoint
@@ -538,6 +541,12 @@ func (s *Service) UpdateProvider(req admin.UpdateProviderRequest) error {
 			return err
 		}
 
+		if sshAddressChanged {
+			if err := tx.Where("provider_id = ?", provider.ID).Delete(&providerModel.ProviderHostKey{}).Error; err != nil {
+				return fmt.Errorf("清除SSH主机密钥记录失败: %v", err)
+			}
+		}
+
 		// 同步更新该Provider下所有实例的到期时间
 		if provider.ExpiresAt != nil {
 			if err := tx.Model(&providerModel.Instance{}).
@@ -314,6 +314,10 @@ func (s *Service) DeleteUser(userID uint) error {
 	// 使用数据库抽象层进行硬删除（永久删除）
 	dbService := database.GetDatabaseService()
 	if err := dbService.ExecuteTransaction(context.Background(), func(tx *gorm.DB) error {
+		// 清理用户的API令牌
+		if err := tx.Where("user_id = ?", userID).Delete(&userModel.APIToken{}).Error; err != nil {
+			return err
+		}
 		// 使用Unscoped().Delete进行硬删除，彻底从数据库中移除记录
 		return tx.Unscoped().Delete(&userModel.User{}, userID).Error
 	}); err != nil {
@@ -378,6 +382,10 @@ func (s *Service) BatchDeleteUsers(userIDs []uint) error {
 
 	dbService := database.GetDatabaseService()
 	return dbService.ExecuteTransaction(context.Background(), func(tx *gorm.DB) error {
+		// 清理用户的API令牌
+		if err := tx.Where("user_id IN ?", userIDs).Delete(&userModel.APIToken{}).Error; err != nil {
+			return err
+		}
 		// 使用Unscoped().Delete进行硬删除，彻底从数据库中移除记录
 		return tx.Unscoped().Delete(&userModel.User{}, userIDs).Error
 	})
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// queueSize 待写入审计日志的缓冲队列长度，队列满时丢弃并记录告警，不阻塞请求
+	queueSize = 1024
+	// batchSize 每批写入的最大条数
+	batchSize = 100
+	// flushInterval 未攒满一批时的最长等待时间
+	flushInterval = 2 * time.Second
+)
+
+// Recorder 审计日志后台写入器
+type Recorder struct {
+	queue    chan *adminModel.AuditLog
+	mu       sync.RWMutex // 保护stopped，避免向已关闭的队列写入
+	stopped  bool
+	stopOnce sync.Once
+	done     chan struct{} // 后台goroutine退出时关闭
+}
+
+var (
+	recorder     *Recorder
+	recorderOnce sync.Once
+)
+
+// GetRecorder 获取审计日志写入器单例，首次调用时启动后台写入goroutine
+func GetRecorder() *Recorder {
+	recorderOnce.Do(func() {
+		recorder = &Recorder{
+			queue: make(chan *adminModel.AuditLog, queueSize),
+			done:  make(chan struct{}),
+		}
+		go recorder.run()
+	})
+	return recorder
+}
+
+// Record 异步写入一条审计日志
+func Record(entry *adminModel.AuditLog) {
+	if entry == nil || !global.APP_CONFIG.Audit.Enabled {
+		return
+	}
+	GetRecorder().record(entry)
+}
+
+// record 将日志放入队列，队列已满或写入器已停止时丢弃
+func (r *Recorder) record(entry *adminModel.AuditLog) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.stopped {
+		global.APP_LOG.Warn("审计日志写入器已停止，丢弃日志",
+			zap.String("username", entry.Username),
+			zap.String("method", entry.Method),
+			zap.String("path", entry.Path))
+		return
+	}
+
+	select {
+	case r.queue <- entry:
+	default:
+		global.APP_LOG.Warn("审计日志队列已满，丢弃日志",
+			zap.String("username", entry.Username),
+			zap.String("method", entry.Method),
+			zap.String("path", entry.Path))
+	}
+}
+
+// Stop 停止写入器：关闭队列，写入队列中剩余的日志，并等待后台goroutine退出
+func (r *Recorder) Stop(timeout time.Duration) error {
+	r.stopOnce.Do(func() {
+		r.mu.Lock()
+		r.stopped = true
+		close(r.queue)
+		r.mu.Unlock()
+	})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("等待审计日志写入超时，剩余 %d 条未写入", len(r.queue))
+	}
+}
+
+// run 后台批量写入审计日志，队列关闭后写入剩余日志并退出
+func (r *Recorder) run() {
+	defer close(r.done)
+	defer func() {
+		if rec := recover(); rec != nil {
+			global.APP_LOG.Error("审计日志写入goroutine panic", zap.Any("panic", rec), zap.Stack("stack"))
+		}
+	}()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*adminModel.AuditLog, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if global.APP_DB == nil {
+			global.APP_LOG.Warn("数据库未初始化，丢弃审计日志", zap.Int("count", len(batch)))
+		} else if err := global.APP_DB.CreateInBatches(batch, batchSize).Error; err != nil {
+			global.APP_LOG.Error("写入审计日志失败", zap.Int("count", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-r.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
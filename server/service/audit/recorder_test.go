@@ -0,0 +1,49 @@
+//go:build cgo
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRecorderStopDrainsQueue(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/audit.db"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	if err := db.AutoMigrate(&adminModel.AuditLog{}); err != nil {
+		t.Fatalf("创建审计日志表失败: %v", err)
+	}
+	global.APP_DB, global.APP_LOG = db, zap.NewNop()
+
+	r := &Recorder{queue: make(chan *adminModel.AuditLog, queueSize), done: make(chan struct{})}
+	go r.run()
+	for i := 0; i < 3; i++ {
+		r.record(&adminModel.AuditLog{Username: "alice", Method: "POST", Path: "/api/v1/user/instances"})
+	}
+
+	// 刷新间隔内停止，队列中的日志也应全部写入
+	if err := r.Stop(5 * time.Second); err != nil {
+		t.Fatalf("停止写入器失败: %v", err)
+	}
+	var count int64
+	db.Model(&adminModel.AuditLog{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("停止后应写入3条审计日志，实际为 %d", count)
+	}
+
+	// 停止后的日志被丢弃，且重复停止不会panic
+	r.record(&adminModel.AuditLog{Username: "bob"})
+	if err := r.Stop(time.Second); err != nil {
+		t.Fatalf("重复停止失败: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+
+	"go.uber.org/zap"
+)
+
+// defaultRetentionDays 未配置时审计日志的保留天数
+const defaultRetentionDays = 180
+
+// Service 审计日志服务
+type Service struct{}
+
+// NewService 创建审计日志服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// List 分页查询审计日志，可按操作者、资源、操作、结果和时间范围过滤
+func (s *Service) List(req adminModel.AuditLogListRequest) ([]adminModel.AuditLog, int64, error) {
+	query := global.APP_DB.Model(&adminModel.AuditLog{})
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Username != "" {
+		query = query.Where("username = ?", req.Username)
+	}
+	if req.UserType != "" {
+		query = query.Where("user_type = ?", req.UserType)
+	}
+	if req.Resource != "" {
+		query = query.Where("resource = ?", req.Resource)
+	}
+	if req.ResourceID != "" {
+		query = query.Where("resource_id = ?", req.ResourceID)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.Method != "" {
+		query = query.Where("method = ?", req.Method)
+	}
+	if req.Success != nil {
+		query = query.Where("success = ?", *req.Success)
+	}
+	if req.Keyword != "" {
+		query = query.Where("path LIKE ?", "%"+req.Keyword+"%")
+	}
+	if req.StartTime != "" {
+		startTime, err := parseTime(req.StartTime)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("created_at >= ?", startTime)
+	}
+	if req.EndTime != "" {
+		endTime, err := parseTime(req.EndTime)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("created_at <= ?", endTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计审计日志数量失败: %v", err)
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var logs []adminModel.AuditLog
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取审计日志失败: %v", err)
+	}
+	return logs, total, nil
+}
+
+// parseTime 解析时间参数，支持RFC3339和 2006-01-02 15:04:05、2006-01-02 格式
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("时间格式无效: %s", value)
+}
+
+// Cleanup 清理超过保留天数的审计日志
+func (s *Service) Cleanup() {
+	if global.APP_DB == nil {
+		return
+	}
+	days := global.APP_CONFIG.Audit.RetentionDays
+	if days <= 0 {
+		days = defaultRetentionDays
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := global.APP_DB.Unscoped().Where("created_at < ?", cutoff).Delete(&adminModel.AuditLog{})
+	if result.Error != nil {
+		global.APP_LOG.Error("清理过期审计日志失败", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		global.APP_LOG.Info("清理过期审计日志",
+			zap.Int64("count", result.RowsAffected),
+			zap.Int("retentionDays", days))
+	}
+}
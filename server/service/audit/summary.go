@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxSummaryLength 请求摘要的最大长度（字符）
+	maxSummaryLength = 2000
+	redactedValue    = "******"
+)
+
+// sensitiveKeyParts 字段名（去掉下划线和连字符并转为小写后）包含这些片段时脱敏
+var sensitiveKeyParts = []string{
+	"password", "passwd", "pwd", "secret", "token", "apikey", "accesskey",
+	"privatekey", "sshkey", "credential", "cert", "otp", "recoverycode",
+	"userdata", "authconfig", // cloud-init脚本和Provider认证配置中通常包含密码和密钥
+}
+
+// sensitiveKeys 字段名完全匹配时脱敏，避免 keyword、inviteCodeId 等字段被误伤
+var sensitiveKeys = map[string]bool{"key": true, "code": true, "cookie": true, "authorization": true}
+
+// isSensitiveKey 判断字段名是否敏感
+func isSensitiveKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	if sensitiveKeys[normalized] {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue 递归脱敏JSON值中的敏感字段
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isSensitiveKey(k) {
+				val[k] = redactedValue
+			} else {
+				val[k] = redactValue(item)
+			}
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// SummarizeRequest 生成脱敏后的请求摘要，包含查询参数和请求体
+func SummarizeRequest(rawQuery, contentType string, body []byte) string {
+	var parts []string
+	if rawQuery != "" {
+		if values, err := url.ParseQuery(rawQuery); err == nil {
+			parts = append(parts, "query: "+redactForm(values))
+		}
+	}
+	if len(body) > 0 {
+		parts = append(parts, "body: "+summarizeBody(contentType, body))
+	}
+	return Truncate(strings.Join(parts, "\n"), maxSummaryLength)
+}
+
+// summarizeBody 按内容类型生成请求体摘要，无法解析的内容只记录长度
+func summarizeBody(contentType string, body []byte) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "multipart/"):
+		return fmt.Sprintf("[multipart %d bytes]", len(body))
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Sprintf("[form %d bytes]", len(body))
+		}
+		return redactForm(values)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	data, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	return string(data)
+}
+
+// redactForm 脱敏表单或查询参数
+func redactForm(values url.Values) string {
+	for k := range values {
+		if isSensitiveKey(k) {
+			values[k] = []string{redactedValue}
+		}
+	}
+	return values.Encode()
+}
+
+// Truncate 按字符截断到不超过max个字符，避免截断多字节字符
+func Truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string([]rune(s)[:max])
+	}
+	return string([]rune(s)[:max-3]) + "..."
+}
+
+// ParseRoute 根据路由模板解析目标资源和操作
+// 如 PUT /api/v1/admin/users/:id/status 解析为资源 users、操作 status；
+// 没有子操作时按请求方法解析为 create、update、delete
+func ParseRoute(method, fullPath string) (resource, action string) {
+	path := fullPath
+	if idx := strings.Index(path, "/v1/"); idx >= 0 {
+		path = path[idx+len("/v1/"):]
+	}
+	path = strings.Trim(path, "/")
+	for _, scope := range []string{"admin/", "user/"} {
+		if strings.HasPrefix(path, scope) {
+			path = strings.TrimPrefix(path, scope)
+			break
+		}
+	}
+
+	var literals []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			continue
+		}
+		if resource == "" {
+			resource = segment
+			continue
+		}
+		literals = append(literals, segment)
+	}
+
+	if len(literals) > 0 {
+		return resource, Truncate(strings.Join(literals, "/"), 64)
+	}
+	switch method {
+	case "POST":
+		return resource, "create"
+	case "PUT", "PATCH":
+		return resource, "update"
+	case "DELETE":
+		return resource, "delete"
+	}
+	return resource, strings.ToLower(method)
+}
+
+// ParseResponse 按HTTP状态码和统一响应体中的code判断操作结果，返回结果和响应消息
+func ParseResponse(status int, body []byte) (bool, string) {
+	var resp struct {
+		Code    *int   `json:"code"`
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+		Details string `json:"details"`
+	}
+	success := status < http.StatusBadRequest
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return success, http.StatusText(status)
+	}
+
+	// 统一响应使用0表示成功，部分旧接口使用200
+	if resp.Code != nil && *resp.Code != 0 && *resp.Code != http.StatusOK {
+		success = false
+	}
+	message := resp.Message
+	if message == "" {
+		message = resp.Msg
+	}
+	if !success && resp.Details != "" {
+		message += ": " + resp.Details
+	}
+	return success, Truncate(message, 500)
+}
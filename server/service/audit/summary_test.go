@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeRequestRedactsSecrets(t *testing.T) {
+	body := []byte(`{"username":"alice","password":"p@ss","newPassword":"x","profile":{"api_key":"k1","sshKey":"ssh-rsa AAA"},"tokens":[{"access_token":"t"}],"code":"123456","keyword":"web"}`)
+	summary := SummarizeRequest("token=abc&page=1", "application/json", body)
+
+	for _, secret := range []string{"p@ss", `"x"`, "k1", "ssh-rsa", `"t"`, "123456", "abc"} {
+		if strings.Contains(summary, secret) {
+			t.Errorf("摘要中包含未脱敏的值 %s: %s", secret, summary)
+		}
+	}
+	for _, keep := range []string{"alice", "web", "page=1"} {
+		if !strings.Contains(summary, keep) {
+			t.Errorf("摘要缺少非敏感字段 %s: %s", keep, summary)
+		}
+	}
+
+	body = []byte(`{"name":"web1","userData":"#cloud-config\nchpasswd: {list: root:hunter2}","user_data":"echo s3cr3t","authConfig":"{\"password\":\"pw\"}"}`)
+	summary = SummarizeRequest("", "application/json", body)
+	for _, secret := range []string{"hunter2", "s3cr3t", "cloud-config", "pw"} {
+		if strings.Contains(summary, secret) {
+			t.Errorf("摘要中包含未脱敏的user-data或认证配置 %s: %s", secret, summary)
+		}
+	}
+	if !strings.Contains(summary, "web1") {
+		t.Errorf("摘要缺少非敏感字段 web1: %s", summary)
+	}
+
+	if got := SummarizeRequest("", "multipart/form-data; boundary=x", []byte("raw")); got != "body: [multipart 3 bytes]" {
+		t.Errorf("multipart摘要 = %q", got)
+	}
+	if got := SummarizeRequest("", "application/x-www-form-urlencoded", []byte("name=a&secret=b")); strings.Contains(got, "secret=b") {
+		t.Errorf("表单摘要未脱敏: %q", got)
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	cases := []struct {
+		method, path     string
+		resource, action string
+	}{
+		{"POST", "/api/v1/admin/users", "users", "create"},
+		{"PUT", "/api/v1/admin/users/:id", "users", "update"},
+		{"DELETE", "/api/v1/admin/users/:id", "users", "delete"},
+		{"PUT", "/api/v1/admin/users/:id/status", "users", "status"},
+		{"POST", "/api/v1/admin/users/batch-delete", "users", "batch-delete"},
+		{"POST", "/api/v1/user/instances/:id/domains", "instances", "domains"},
+		{"POST", "/api/v1/user/backups/:backupId/restore", "backups", "restore"},
+		{"PUT", "/api/v1/config", "config", "update"},
+		{"POST", "/api/v1/auth/logout", "auth", "logout"},
+	}
+	for _, tc := range cases {
+		resource, action := ParseRoute(tc.method, tc.path)
+		if resource != tc.resource || action != tc.action {
+			t.Errorf("ParseRoute(%s %s) = (%s, %s), 期望 (%s, %s)",
+				tc.method, tc.path, resource, action, tc.resource, tc.action)
+		}
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	if ok, msg := ParseResponse(200, []byte(`{"code":0,"message":"操作成功","data":null}`)); !ok || msg != "操作成功" {
+		t.Errorf("成功响应解析为 (%v, %q)", ok, msg)
+	}
+	if ok, msg := ParseResponse(200, []byte(`{"code":200,"msg":"ok"}`)); !ok || msg != "ok" {
+		t.Errorf("旧格式成功响应解析为 (%v, %q)", ok, msg)
+	}
+	if ok, msg := ParseResponse(400, []byte(`{"code":1001,"message":"参数错误","details":"name required"}`)); ok || msg != "参数错误: name required" {
+		t.Errorf("失败响应解析为 (%v, %q)", ok, msg)
+	}
+	if ok, _ := ParseResponse(500, []byte("internal")); ok {
+		t.Error("5xx非JSON响应应判定为失败")
+	}
+	if got := Truncate(strings.Repeat("审", 10), 5); got != "审审..." {
+		t.Errorf("Truncate = %q", got)
+	}
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/common"
+	userModel "oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	apiTokenMaxPerUser     = 20          // 每个用户最多可持有的有效令牌数
+	apiTokenTouchInterval  = time.Minute // 最近使用信息的最小更新间隔，避免每次请求都写库
+	apiTokenDisplayPrefix  = 12          // 记录的明文前缀长度
+	apiTokenRandomByteSize = 24
+)
+
+// apiTokenBlockedRoutes 不允许API令牌访问的路由前缀（账号安全相关操作必须通过浏览器会话完成）
+var apiTokenBlockedRoutes = []string{
+	"/user/api-tokens",
+	"/user/2fa",
+	"/user/profile",
+	"/user/reset-password",
+	"/upload/",
+}
+
+// apiTokenScopeRoutes 路由前缀对应的资源权限范围，按顺序匹配，更具体的前缀在前
+var apiTokenScopeRoutes = []struct {
+	prefix string
+	scope  string
+}{
+	{"/user/traffic", userModel.APITokenScopeTraffic},
+	{"/user/instances/:id/traffic", userModel.APITokenScopeTraffic},
+	{"/user/port-mappings", userModel.APITokenScopePorts},
+	{"/user/instances/:id/ports", userModel.APITokenScopePorts},
+	{"/user/instances", userModel.APITokenScopeInstances},
+	{"/user/resources/claim", userModel.APITokenScopeInstances},
+	{"/user/tasks", userModel.APITokenScopeInstances},
+	{"/user/backups", userModel.APITokenScopeInstances},
+	{"/user/security-groups", userModel.APITokenScopeInstances},
+	{"/user/domains", userModel.APITokenScopeInstances},
+	{"/instances", userModel.APITokenScopeInstances},
+}
+
+// apiTokenInstanceOnlyRoutes 虽然是GET请求，但可直接操作实例或返回实例登录凭据的路由后缀，要求 instances 范围
+var apiTokenInstanceOnlyRoutes = []string{
+	"/ssh",
+	"/console",
+	"/password/:taskId",
+}
+
+// APITokenService 个人API令牌服务
+type APITokenService struct{}
+
+// IsAPIToken 判断认证令牌是否为API令牌
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, userModel.APITokenPrefix)
+}
+
+// hashAPIToken 计算令牌哈希（令牌为高熵随机值，SHA-256即可）
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeScopes 校验并整理权限范围
+func normalizeScopes(scopes []string) (string, error) {
+	valid := make(map[string]bool, len(userModel.APITokenScopes))
+	for _, s := range userModel.APITokenScopes {
+		valid[s] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" || seen[s] {
+			continue
+		}
+		if !valid[s] {
+			return "", fmt.Errorf("不支持的权限范围: %s", s)
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	if len(result) == 0 {
+		return "", errors.New("至少需要选择一个权限范围")
+	}
+	return strings.Join(result, ","), nil
+}
+
+// ListTokens 获取用户的API令牌列表
+func (s *APITokenService) ListTokens(userID uint) ([]userModel.APIToken, error) {
+	var tokens []userModel.APIToken
+	if err := global.APP_DB.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("获取API令牌列表失败: %v", err)
+	}
+	return tokens, nil
+}
+
+// CreateToken 创建API令牌，明文令牌仅在此时返回
+func (s *APITokenService) CreateToken(userID uint, req userModel.CreateAPITokenRequest) (*userModel.CreateAPITokenResponse, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, common.NewError(common.CodeValidationError, err.Error())
+	}
+
+	now := time.Now()
+	var active int64
+	if err := global.APP_DB.Model(&userModel.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&active).Error; err != nil {
+		return nil, fmt.Errorf("统计API令牌数量失败: %v", err)
+	}
+	if active >= apiTokenMaxPerUser {
+		return nil, common.NewError(common.CodeConflict, fmt.Sprintf("最多只能持有%d个有效的API令牌", apiTokenMaxPerUser))
+	}
+
+	buf := make([]byte, apiTokenRandomByteSize)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("生成API令牌失败: %v", err)
+	}
+	plain := userModel.APITokenPrefix + hex.EncodeToString(buf)
+
+	token := userModel.APIToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    plain[:apiTokenDisplayPrefix],
+		TokenHash: hashAPIToken(plain),
+		Scopes:    scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := global.APP_DB.Create(&token).Error; err != nil {
+		return nil, fmt.Errorf("保存API令牌失败: %v", err)
+	}
+
+	global.APP_LOG.Info("用户创建API令牌",
+		zap.Uint("userID", userID),
+		zap.Uint("tokenID", token.ID),
+		zap.String("scopes", scopes))
+	return &userModel.CreateAPITokenResponse{APIToken: token, Token: plain}, nil
+}
+
+// RevokeToken 撤销API令牌
+func (s *APITokenService) RevokeToken(userID, tokenID uint) error {
+	now := time.Now()
+	result := global.APP_DB.Model(&userModel.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", &now)
+	if result.Error != nil {
+		return fmt.Errorf("撤销API令牌失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return common.NewError(common.CodeNotFound, "API令牌不存在或已撤销")
+	}
+
+	global.APP_LOG.Info("用户撤销API令牌", zap.Uint("userID", userID), zap.Uint("tokenID", tokenID))
+	return nil
+}
+
+// Authenticate 校验API令牌并记录最近使用信息
+func (s *APITokenService) Authenticate(plain, clientIP string) (*userModel.APIToken, error) {
+	var token userModel.APIToken
+	if err := global.APP_DB.Where("token_hash = ?", hashAPIToken(plain)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.NewError(common.CodeUnauthorized, "无效的API令牌")
+		}
+		return nil, common.NewError(common.CodeUnauthorized, "API令牌校验失败")
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, common.NewError(common.CodeUnauthorized, "API令牌已撤销")
+	}
+	if !token.IsActive(now) {
+		return nil, common.NewError(common.CodeUnauthorized, "API令牌已过期")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != clientIP {
+		if err := global.APP_DB.Model(&token).Updates(map[string]interface{}{
+			"last_used_at": &now,
+			"last_used_ip": clientIP,
+		}).Error; err != nil {
+			global.APP_LOG.Warn("更新API令牌使用信息失败", zap.Uint("tokenID", token.ID), zap.Error(err))
+		}
+	}
+	return &token, nil
+}
+
+// RequiredScope 返回访问指定路由所需的资源权限范围，无对应资源时返回空
+// route 为去掉 /api/v1 前缀的路由模板（如 /user/instances/:id），按前缀匹配
+func RequiredScope(route string) string {
+	for _, r := range apiTokenScopeRoutes {
+		if route == r.prefix || strings.HasPrefix(route, r.prefix+"/") {
+			return r.scope
+		}
+	}
+	return ""
+}
+
+// CheckScope 检查API令牌是否允许当前请求，route 为去掉 /api/v1 前缀的路由模板
+// GET请求拥有 read 或对应资源范围即可；其他请求必须拥有对应资源范围
+// SSH、控制台等交互式连接和实例密码虽然是GET请求，但可直接操作实例，因此要求 instances 范围
+func (s *APITokenService) CheckScope(token *userModel.APIToken, method, route string) error {
+	for _, blocked := range apiTokenBlockedRoutes {
+		if strings.HasPrefix(route, blocked) {
+			return common.NewError(common.CodeForbidden, "该操作不支持使用API令牌")
+		}
+	}
+
+	required := RequiredScope(route)
+	if isInstanceOnlyRoute(route) {
+		required = userModel.APITokenScopeInstances
+	} else if method == http.MethodGet || method == http.MethodHead {
+		if token.HasScope(userModel.APITokenScopeRead) {
+			return nil
+		}
+	}
+
+	if required != "" && token.HasScope(required) {
+		return nil
+	}
+	return common.NewError(common.CodeForbidden, "API令牌权限范围不足")
+}
+
+// isInstanceOnlyRoute 判断路由是否要求 instances 范围（即使是GET请求）
+func isInstanceOnlyRoute(route string) bool {
+	for _, suffix := range apiTokenInstanceOnlyRoutes {
+		if strings.HasSuffix(route, suffix) {
+			return true
+		}
+	}
+	return false
+}
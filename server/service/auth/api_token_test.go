@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	userModel "oneclickvirt/model/user"
+)
+
+func TestAPITokenCheckScope(t *testing.T) {
+	s := &APITokenService{}
+	readOnly := &userModel.APIToken{Scopes: userModel.APITokenScopeRead}
+	traffic := &userModel.APIToken{Scopes: userModel.APITokenScopeTraffic}
+	instances := &userModel.APIToken{Scopes: userModel.APITokenScopeInstances}
+
+	cases := []struct {
+		token  *userModel.APIToken
+		method string
+		route  string
+		allow  bool
+	}{
+		{readOnly, http.MethodGet, "/user/instances/:id", true},
+		{readOnly, http.MethodPost, "/user/instances/action", false},
+		{readOnly, http.MethodGet, "/user/instances/:id/ssh", false},
+		{readOnly, http.MethodGet, "/user/instances/:id/password/:taskId", false},
+		{instances, http.MethodGet, "/user/instances/:id/password/:taskId", true},
+		{readOnly, http.MethodGet, "/user/api-tokens", false},
+		{traffic, http.MethodGet, "/user/instances/:id/traffic/history", true},
+		// 路由模板按前缀匹配，实例ID等路径参数中的内容不影响权限范围
+		{traffic, http.MethodPost, "/user/instances/action", false},
+		{traffic, http.MethodGet, "/user/instances/:id", false},
+	}
+	for _, tc := range cases {
+		err := s.CheckScope(tc.token, tc.method, tc.route)
+		if (err == nil) != tc.allow {
+			t.Errorf("%s %s scopes=%s: allow=%v, err=%v", tc.method, tc.route, tc.token.Scopes, tc.allow, err)
+		}
+	}
+}
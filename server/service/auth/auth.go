@@ -29,209 +29,207 @@ import (
 
 type AuthService struct{}
 
-func (s *AuthService) Login(req auth.LoginRequest) (*userModel.User, string, error) {
+// Login 校验登录凭证，启用两步验证的用户返回验证挑战而非JWT令牌
+func (s *AuthService) Login(req auth.LoginRequest) (*userModel.User, string, *auth.TwoFactorChallenge, error) {
 	// 根据登录类型调用不同的登录逻辑
 	loginType := req.LoginType
 	if loginType == "" {
 		loginType = "username" // 默认使用用户名密码登录
 	}
 
+	var user *userModel.User
+	var err error
 	switch loginType {
 	case "username":
-		return s.loginWithPassword(req)
+		user, err = s.loginWithPassword(req)
 	case "email":
-		return s.loginWithEmailCode(req)
+		user, err = s.loginWithEmailCode(req)
 	case "telegram":
-		return s.loginWithTelegramCode(req)
+		user, err = s.loginWithTelegramCode(req)
 	case "qq":
-		return s.loginWithQQCode(req)
+		user, err = s.loginWithQQCode(req)
 	default:
-		return nil, "", common.NewError(common.CodeInvalidParam, "不支持的登录类型")
+		return nil, "", nil, common.NewError(common.CodeInvalidParam, "不支持的登录类型")
 	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// 启用两步验证（或被策略要求启用）的用户需完成第二步后才签发令牌
+	twoFactorService := TwoFactorService{}
+	challenge, err := twoFactorService.Challenge(user)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if challenge != nil {
+		return nil, "", challenge, nil
+	}
+
+	token, err := s.issueLoginToken(user)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return user, token, nil, nil
+}
+
+// issueLoginToken 签发JWT令牌并更新最后登录时间
+func (s *AuthService) issueLoginToken(user *userModel.User) (string, error) {
+	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
+	if err != nil {
+		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
+		return "", errors.New("登录失败，请稍后重试")
+	}
+	// 更新最后登录时间
+	global.APP_DB.Model(user).Update("last_login_at", time.Now())
+	return token, nil
 }
 
 // loginWithPassword 用户名密码登录
-func (s *AuthService) loginWithPassword(req auth.LoginRequest) (*userModel.User, string, error) {
+func (s *AuthService) loginWithPassword(req auth.LoginRequest) (*userModel.User, error) {
 	// 先检查验证码格式，但不消费
 	authValidationService := AuthValidationService{}
 	if authValidationService.ShouldCheckCaptcha() {
 		if req.CaptchaId == "" || req.Captcha == "" {
-			return nil, "", common.NewError(common.CodeCaptchaRequired, "请填写验证码")
+			return nil, common.NewError(common.CodeCaptchaRequired, "请填写验证码")
 		}
 	}
 
 	// 检查必要参数
 	if req.Username == "" || req.Password == "" {
-		return nil, "", common.NewError(common.CodeInvalidParam, "用户名和密码不能为空")
+		return nil, common.NewError(common.CodeInvalidParam, "用户名和密码不能为空")
 	}
 
 	// 先查询用户是否存在
 	var user userModel.User
 	if err := global.APP_DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
 		global.APP_LOG.Debug("用户登录失败", zap.String("username", utils.SanitizeUserInput(req.Username)), zap.String("error", "record not found"))
-		return nil, "", common.NewError(common.CodeInvalidCredentials)
+		return nil, common.NewError(common.CodeInvalidCredentials)
 	}
 
 	// 检查用户状态
 	if user.Status != 1 {
 		global.APP_LOG.Warn("禁用用户尝试登录", zap.String("username", utils.SanitizeUserInput(req.Username)), zap.Int("status", user.Status))
-		return nil, "", common.NewError(common.CodeUserDisabled)
+		return nil, common.NewError(common.CodeUserDisabled)
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		global.APP_LOG.Debug("用户密码验证失败", zap.String("username", utils.SanitizeUserInput(req.Username)), zap.String("userType", user.UserType))
-		return nil, "", common.NewError(common.CodeInvalidCredentials)
+		return nil, common.NewError(common.CodeInvalidCredentials)
 	}
 
 	// 所有检查通过后，验证并消费验证码
 	// 这样可以避免用户名或密码错误时验证码被消费
 	if authValidationService.ShouldCheckCaptcha() {
 		if err := s.verifyCaptcha(req.CaptchaId, req.Captcha); err != nil {
-			return nil, "", common.NewError(common.CodeCaptchaInvalid, err.Error())
+			return nil, common.NewError(common.CodeCaptchaInvalid, err.Error())
 		}
 	}
 
 	global.APP_LOG.Info("用户登录成功", zap.String("username", user.Username), zap.String("userType", user.UserType), zap.Uint("userID", user.ID))
-
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
-	if err != nil {
-		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
-		return nil, "", errors.New("登录失败，请稍后重试")
-	}
-	// 更新最后登录时间
-	global.APP_DB.Model(&user).Update("last_login_at", time.Now())
-	return &user, token, nil
+	return &user, nil
 }
 
 // loginWithEmailCode 邮箱验证码登录
-func (s *AuthService) loginWithEmailCode(req auth.LoginRequest) (*userModel.User, string, error) {
+func (s *AuthService) loginWithEmailCode(req auth.LoginRequest) (*userModel.User, error) {
 	// 检查邮箱登录是否启用
 	if !global.APP_CONFIG.Auth.EnableEmail {
-		return nil, "", common.NewError(common.CodeInvalidParam, "邮箱登录未启用")
+		return nil, common.NewError(common.CodeInvalidParam, "邮箱登录未启用")
 	}
 
 	// 检查必要参数
 	if req.Target == "" || req.VerifyCode == "" {
-		return nil, "", common.NewError(common.CodeInvalidParam, "邮箱地址和验证码不能为空")
+		return nil, common.NewError(common.CodeInvalidParam, "邮箱地址和验证码不能为空")
 	}
 
 	// 验证验证码
 	if err := s.verifyCode("email", req.Target, req.VerifyCode); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	// 查找用户
 	var user userModel.User
 	if err := global.APP_DB.Where("email = ?", req.Target).First(&user).Error; err != nil {
 		global.APP_LOG.Debug("邮箱登录失败", zap.String("email", req.Target), zap.String("error", "record not found"))
-		return nil, "", common.NewError(common.CodeInvalidCredentials, "该邮箱未绑定任何账号")
+		return nil, common.NewError(common.CodeInvalidCredentials, "该邮箱未绑定任何账号")
 	}
 
 	// 检查用户状态
 	if user.Status != 1 {
 		global.APP_LOG.Warn("禁用用户尝试登录", zap.String("email", req.Target), zap.Int("status", user.Status))
-		return nil, "", common.NewError(common.CodeUserDisabled)
+		return nil, common.NewError(common.CodeUserDisabled)
 	}
 
 	global.APP_LOG.Info("用户邮箱登录成功", zap.String("email", req.Target), zap.String("username", user.Username), zap.Uint("userID", user.ID))
-
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
-	if err != nil {
-		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
-		return nil, "", errors.New("登录失败，请稍后重试")
-	}
-	// 更新最后登录时间
-	global.APP_DB.Model(&user).Update("last_login_at", time.Now())
-	return &user, token, nil
+	return &user, nil
 }
 
 // loginWithTelegramCode Telegram验证码登录
-func (s *AuthService) loginWithTelegramCode(req auth.LoginRequest) (*userModel.User, string, error) {
+func (s *AuthService) loginWithTelegramCode(req auth.LoginRequest) (*userModel.User, error) {
 	// 检查Telegram登录是否启用
 	if !global.APP_CONFIG.Auth.EnableTelegram {
-		return nil, "", common.NewError(common.CodeInvalidParam, "Telegram登录未启用")
+		return nil, common.NewError(common.CodeInvalidParam, "Telegram登录未启用")
 	}
 
 	// 检查必要参数
 	if req.Target == "" || req.VerifyCode == "" {
-		return nil, "", common.NewError(common.CodeInvalidParam, "Telegram用户名和验证码不能为空")
+		return nil, common.NewError(common.CodeInvalidParam, "Telegram用户名和验证码不能为空")
 	}
 
 	// 验证验证码
 	if err := s.verifyCode("telegram", req.Target, req.VerifyCode); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	// 查找用户
 	var user userModel.User
 	if err := global.APP_DB.Where("telegram = ?", req.Target).First(&user).Error; err != nil {
 		global.APP_LOG.Debug("Telegram登录失败", zap.String("telegram", req.Target), zap.String("error", "record not found"))
-		return nil, "", common.NewError(common.CodeInvalidCredentials, "该Telegram账号未绑定任何账号")
+		return nil, common.NewError(common.CodeInvalidCredentials, "该Telegram账号未绑定任何账号")
 	}
 
 	// 检查用户状态
 	if user.Status != 1 {
 		global.APP_LOG.Warn("禁用用户尝试登录", zap.String("telegram", req.Target), zap.Int("status", user.Status))
-		return nil, "", common.NewError(common.CodeUserDisabled)
+		return nil, common.NewError(common.CodeUserDisabled)
 	}
 
 	global.APP_LOG.Info("用户Telegram登录成功", zap.String("telegram", req.Target), zap.String("username", user.Username), zap.Uint("userID", user.ID))
-
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
-	if err != nil {
-		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
-		return nil, "", errors.New("登录失败，请稍后重试")
-	}
-	// 更新最后登录时间
-	global.APP_DB.Model(&user).Update("last_login_at", time.Now())
-	return &user, token, nil
+	return &user, nil
 }
 
 // loginWithQQCode QQ验证码登录
-func (s *AuthService) loginWithQQCode(req auth.LoginRequest) (*userModel.User, string, error) {
+func (s *AuthService) loginWithQQCode(req auth.LoginRequest) (*userModel.User, error) {
 	// 检查QQ登录是否启用
 	if !global.APP_CONFIG.Auth.EnableQQ {
-		return nil, "", common.NewError(common.CodeInvalidParam, "QQ登录未启用")
+		return nil, common.NewError(common.CodeInvalidParam, "QQ登录未启用")
 	}
 
 	// 检查必要参数
 	if req.Target == "" || req.VerifyCode == "" {
-		return nil, "", common.NewError(common.CodeInvalidParam, "QQ号和验证码不能为空")
+		return nil, common.NewError(common.CodeInvalidParam, "QQ号和验证码不能为空")
 	}
 
 	// 验证验证码
 	if err := s.verifyCode("qq", req.Target, req.VerifyCode); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	// 查找用户
 	var user userModel.User
 	if err := global.APP_DB.Where("qq = ?", req.Target).First(&user).Error; err != nil {
 		global.APP_LOG.Debug("QQ登录失败", zap.String("qq", req.Target), zap.String("error", "record not found"))
-		return nil, "", common.NewError(common.CodeInvalidCredentials, "该QQ号未绑定任何账号")
+		return nil, common.NewError(common.CodeInvalidCredentials, "该QQ号未绑定任何账号")
 	}
 
 	// 检查用户状态
 	if user.Status != 1 {
 		global.APP_LOG.Warn("禁用用户尝试登录", zap.String("qq", req.Target), zap.Int("status", user.Status))
-		return nil, "", common.NewError(common.CodeUserDisabled)
+		return nil, common.NewError(common.CodeUserDisabled)
 	}
 
 	global.APP_LOG.Info("用户QQ登录成功", zap.String("qq", req.Target), zap.String("username", user.Username), zap.Uint("userID", user.ID))
-
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username, user.UserType)
-	if err != nil {
-		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
-		return nil, "", errors.New("登录失败，请稍后重试")
-	}
-	// 更新最后登录时间
-	global.APP_DB.Model(&user).Update("last_login_at", time.Now())
-	return &user, token, nil
+	return &user, nil
 }
 
 func (s *AuthService) RegisterWithContext(req auth.RegisterRequest, ip string, userAgent string) error {
@@ -6,25 +6,19 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	"oneclickvirt/service/cache"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
-// blacklistItem 黑名单项
-type blacklistItem struct {
-	userID    uint
-	expiresAt time.Time
-	reason    string
-	revokedBy uint
-}
+// blacklistKeyPrefix 黑名单在存储中的键前缀
+const blacklistKeyPrefix = "jwt:blacklist:"
 
-// JWTBlacklistService 基于内存的JWT黑名单服务
-type JWTBlacklistService struct {
-	data        map[string]*blacklistItem
-	mutex       sync.RWMutex
-	stopCleanup chan struct{}
-}
+// JWTBlacklistService JWT黑名单服务
+// 数据保存在 cache.Store 中：默认为进程内存，启用Redis后在多个面板实例之间共享，
+// 每条记录的过期时间与Token一致，由存储自动清理
+type JWTBlacklistService struct{}
 
 var (
 	blacklistService     *JWTBlacklistService
@@ -34,54 +28,13 @@ var (
 // GetJWTBlacklistService 获取JWT黑名单服务单例
 func GetJWTBlacklistService() *JWTBlacklistService {
 	blacklistServiceOnce.Do(func() {
-		blacklistService = &JWTBlacklistService{
-			data:        make(map[string]*blacklistItem),
-			stopCleanup: make(chan struct{}),
-		}
-		blacklistService.startCleanup()
+		blacklistService = &JWTBlacklistService{}
 	})
 	return blacklistService
 }
 
-// startCleanup 启动自适应自动清理过期Token
-func (s *JWTBlacklistService) startCleanup() {
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				global.APP_LOG.Error("JWT黑名单清理goroutine panic", zap.Any("panic", r))
-			}
-		}()
-
-		ticker := time.NewTicker(5 * time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				// 检查黑名单Token数量
-				s.mutex.RLock()
-				tokenCount := len(s.data)
-				s.mutex.RUnlock()
-
-				// 有Token时5分钟清理，无Token时30分钟检查（节省资源）
-				newInterval := 30 * time.Minute
-				if tokenCount > 0 {
-					newInterval = 5 * time.Minute
-					s.CleanExpiredTokens()
-				}
-				ticker.Reset(newInterval)
-
-			case <-s.stopCleanup:
-				return
-			}
-		}
-	}()
-}
-
-// Stop 停止清理任务
-func (s *JWTBlacklistService) Stop() {
-	close(s.stopCleanup)
-}
+// Stop 停止服务（过期清理由存储负责，这里无需处理）
+func (s *JWTBlacklistService) Stop() {}
 
 // AddToBlacklist 将Token添加到黑名单
 func (s *JWTBlacklistService) AddToBlacklist(tokenString string, userID uint, reason string, revokedBy uint) error {
@@ -91,40 +44,28 @@ func (s *JWTBlacklistService) AddToBlacklist(tokenString string, userID uint, re
 		return fmt.Errorf("解析Token失败: %w", err)
 	}
 
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Token已过期，无需加入黑名单
+		return nil
+	}
 
-	s.data[jti] = &blacklistItem{
-		userID:    userID,
-		expiresAt: expiresAt,
-		reason:    reason,
-		revokedBy: revokedBy,
+	if err := cache.GetStore().Set(blacklistKeyPrefix+jti, []byte(reason), ttl); err != nil {
+		return fmt.Errorf("写入Token黑名单失败: %w", err)
 	}
 
-	global.APP_LOG.Debug("Token已加入内存黑名单",
+	global.APP_LOG.Debug("Token已加入黑名单",
 		zap.String("jti", jti),
 		zap.Uint("userID", userID),
-		zap.String("reason", reason))
+		zap.String("reason", reason),
+		zap.Uint("revokedBy", revokedBy))
 
 	return nil
 }
 
 // IsBlacklisted 检查Token是否在黑名单中
 func (s *JWTBlacklistService) IsBlacklisted(jti string) bool {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	item, exists := s.data[jti]
-	if !exists {
-		return false
-	}
-
-	// 检查是否过期
-	if time.Now().After(item.expiresAt) {
-		return false
-	}
-
-	return true
+	return cache.GetStore().Exists(blacklistKeyPrefix + jti)
 }
 
 // RevokeUserTokens 撤销指定用户的所有Token
@@ -139,28 +80,6 @@ func (s *JWTBlacklistService) RevokeUserTokens(userID uint, reason string, revok
 	return nil
 }
 
-// CleanExpiredTokens 清理过期的黑名单Token
-func (s *JWTBlacklistService) CleanExpiredTokens() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	now := time.Now()
-	count := 0
-
-	for jti, item := range s.data {
-		if now.After(item.expiresAt) {
-			delete(s.data, jti)
-			count++
-		}
-	}
-
-	if count > 0 {
-		global.APP_LOG.Debug("清理过期内存黑名单Token", zap.Int("count", count))
-	}
-
-	return nil
-}
-
 // extractTokenInfo 从Token字符串中提取JTI和过期时间
 func (s *JWTBlacklistService) extractTokenInfo(tokenString string) (string, time.Time, error) {
 	// 解析Token但不验证签名（因为只需要提取信息）
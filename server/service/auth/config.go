@@ -41,6 +41,7 @@ func (s *ConfigService) UpdateConfig(req configModel.UpdateConfigRequest) error
 		"telegramBotToken":         req.Auth.TelegramBotToken,
 		"qqAppID":                  req.Auth.QQAppID,
 		"qqAppKey":                 req.Auth.QQAppKey,
+		"forceAdminTwoFactor":      req.Auth.ForceAdminTwoFactor,
 	}
 	configUpdates["auth"] = authConfig
 
@@ -102,10 +103,25 @@ func (s *ConfigService) UpdateConfig(req configModel.UpdateConfigRequest) error
 				return fmt.Errorf("等级 %d 的带宽配置不能小于等于0", level)
 			}
 
+			if modelLimit.MaxSnapshots < 0 {
+				return fmt.Errorf("等级 %d 的快照数量限制不能小于0", level)
+			}
+
+			if modelLimit.MaxBackups < 0 {
+				return fmt.Errorf("等级 %d 的备份数量限制不能小于0", level)
+			}
+
+			if modelLimit.MaxDomains < 0 {
+				return fmt.Errorf("等级 %d 的域名数量限制不能小于0", level)
+			}
+
 			levelLimits[levelKey] = map[string]interface{}{
 				"max-instances": modelLimit.MaxInstances,
 				"max-resources": modelLimit.MaxResources,
 				"max-traffic":   modelLimit.MaxTraffic,
+				"max-snapshots": modelLimit.MaxSnapshots,
+				"max-backups":   modelLimit.MaxBackups,
+				"max-domains":   modelLimit.MaxDomains,
 			}
 		}
 		quotaConfig["levelLimits"] = levelLimits
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"oneclickvirt/service/database"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -314,7 +316,7 @@ func (s *JWTKeyService) ShouldRotateKey() (bool, error) {
 // getActiveKeyVersion 获取活跃密钥版本
 func (s *JWTKeyService) getActiveKeyVersion() (int, error) {
 	var config adminModel.SystemConfig
-	err := global.APP_DB.Where("category = ? AND `key` = ?", JWT_KEY_CATEGORY, JWT_ACTIVE_KEY).First(&config).Error
+	err := global.APP_DB.Where("category = ? AND "+utils.QuoteColumn(global.APP_DB, "key")+" = ?", JWT_KEY_CATEGORY, JWT_ACTIVE_KEY).First(&config).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			global.APP_LOG.Debug("未找到活跃密钥配置")
@@ -346,7 +348,7 @@ func (s *JWTKeyService) setActiveKeyVersion(version int) error {
 	}
 
 	// 使用upsert逻辑
-	err := global.APP_DB.Where("category = ? AND `key` = ?", JWT_KEY_CATEGORY, JWT_ACTIVE_KEY).
+	err := global.APP_DB.Where("category = ? AND "+utils.QuoteColumn(global.APP_DB, "key")+" = ?", JWT_KEY_CATEGORY, JWT_ACTIVE_KEY).
 		Assign(config).
 		FirstOrCreate(&config).Error
 
@@ -365,18 +367,20 @@ func (s *JWTKeyService) setActiveKeyVersion(version int) error {
 func (s *JWTKeyService) getNextKeyVersion() (int, error) {
 	var maxVersion int
 
-	// 查询最大版本号
-	row := global.APP_DB.Raw(`
-		SELECT COALESCE(MAX(CAST(SUBSTR(`+"`key`"+`, LENGTH(?) + 1) AS SIGNED)), 0)
-		FROM system_configs 
-		WHERE category = ? AND `+"`key`"+` LIKE ?
-	`, JWT_KEY_PREFIX, JWT_KEY_CATEGORY, JWT_KEY_PREFIX+"%").Row()
-
-	if err := row.Scan(&maxVersion); err != nil {
+	// 查询最大版本号（包含已删除的密钥，避免版本号重复）
+	var keyNames []string
+	if err := global.APP_DB.Unscoped().Model(&adminModel.SystemConfig{}).
+		Where("category = ? AND "+utils.QuoteColumn(global.APP_DB, "key")+" LIKE ?", JWT_KEY_CATEGORY, JWT_KEY_PREFIX+"%").
+		Pluck("key", &keyNames).Error; err != nil {
 		global.APP_LOG.Error("查询最大版本号失败",
 			zap.String("error", utils.TruncateString(err.Error(), 200)))
 		return 0, fmt.Errorf("查询最大版本号失败: %w", err)
 	}
+	for _, name := range keyNames {
+		if version, err := strconv.Atoi(strings.TrimPrefix(name, JWT_KEY_PREFIX)); err == nil && version > maxVersion {
+			maxVersion = version
+		}
+	}
 
 	nextVersion := maxVersion + 1
 	global.APP_LOG.Debug("获取下一个密钥版本",
@@ -391,7 +395,7 @@ func (s *JWTKeyService) getKeyByVersion(version int) (*JWTKey, error) {
 	var config adminModel.SystemConfig
 	keyName := fmt.Sprintf("%s%d", JWT_KEY_PREFIX, version)
 
-	err := global.APP_DB.Where("category = ? AND `key` = ?", JWT_KEY_CATEGORY, keyName).First(&config).Error
+	err := global.APP_DB.Where("category = ? AND "+utils.QuoteColumn(global.APP_DB, "key")+" = ?", JWT_KEY_CATEGORY, keyName).First(&config).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			global.APP_LOG.Warn("密钥版本不存在", zap.Int("version", version))
@@ -446,8 +450,8 @@ func (s *JWTKeyService) cleanupExpiredKeys() error {
 
 	// 获取所有密钥版本，按版本号降序排列
 	var configs []adminModel.SystemConfig
-	err := global.APP_DB.Where("category = ? AND `key` LIKE ?", JWT_KEY_CATEGORY, JWT_KEY_PREFIX+"%").
-		Order("`key` DESC").
+	err := global.APP_DB.Where("category = ? AND "+utils.QuoteColumn(global.APP_DB, "key")+" LIKE ?", JWT_KEY_CATEGORY, JWT_KEY_PREFIX+"%").
+		Order(utils.QuoteColumn(global.APP_DB, "key") + " DESC").
 		Limit(100). // 限制最多100条配置
 		Find(&configs).Error
 	if err != nil {
@@ -0,0 +1,438 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/auth"
+	"oneclickvirt/model/common"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorIssuer         = "OneClickVirt"
+	twoFactorRecoveryCount  = 10               // 每次生成的恢复码数量
+	twoFactorMaxFailures    = 5                // 连续失败多少次后锁定
+	twoFactorLockDuration   = 15 * time.Minute // 锁定时长
+	twoFactorRecoveryCodeID = "recovery"       // 日志中标记使用了恢复码
+)
+
+// TwoFactorService TOTP两步验证服务
+type TwoFactorService struct{}
+
+// IsRequiredFor 系统策略是否要求该用户启用两步验证
+func (s *TwoFactorService) IsRequiredFor(user *userModel.User) bool {
+	return global.APP_CONFIG.Auth.ForceAdminTwoFactor && user.UserType == "admin"
+}
+
+// getRecord 获取用户的两步验证记录，不存在时返回nil
+func (s *TwoFactorService) getRecord(userID uint) (*userModel.UserTwoFactor, error) {
+	var record userModel.UserTwoFactor
+	if err := global.APP_DB.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取两步验证信息失败: %v", err)
+	}
+	return &record, nil
+}
+
+// Challenge 凭证校验通过后判断是否需要两步验证，需要时返回临时令牌
+func (s *TwoFactorService) Challenge(user *userModel.User) (*auth.TwoFactorChallenge, error) {
+	required := s.IsRequiredFor(user)
+	if !user.TwoFactorEnabled && !required {
+		return nil, nil
+	}
+
+	token, err := utils.GenerateTwoFactorToken(user.ID)
+	if err != nil {
+		global.APP_LOG.Error("生成两步验证令牌失败", zap.Uint("userID", user.ID), zap.Error(err))
+		return nil, errors.New("登录失败，请稍后重试")
+	}
+
+	return &auth.TwoFactorChallenge{
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: !user.TwoFactorEnabled,
+		TwoFactorToken:         token,
+		ExpiresIn:              int(utils.TwoFactorTokenTTL.Seconds()),
+	}, nil
+}
+
+// loadChallengeUser 根据临时令牌加载用户并检查状态
+func (s *TwoFactorService) loadChallengeUser(twoFactorToken string) (*userModel.User, error) {
+	userID, err := utils.ValidateTwoFactorToken(twoFactorToken)
+	if err != nil {
+		return nil, common.NewError(common.CodeTwoFactorTokenInvalid)
+	}
+
+	var user userModel.User
+	if err := global.APP_DB.First(&user, userID).Error; err != nil {
+		return nil, common.NewError(common.CodeTwoFactorTokenInvalid)
+	}
+	if user.Status != 1 {
+		return nil, common.NewError(common.CodeUserDisabled)
+	}
+	return &user, nil
+}
+
+// BeginLoginSetup 被强制启用两步验证但尚未绑定的用户在登录过程中获取绑定信息
+func (s *TwoFactorService) BeginLoginSetup(req auth.TwoFactorLoginSetupRequest) (*userModel.TwoFactorSetupResponse, error) {
+	user, err := s.loadChallengeUser(req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled || !s.IsRequiredFor(user) {
+		return nil, common.NewError(common.CodeInvalidParam, "当前账号无需在登录时绑定两步验证")
+	}
+	return s.Setup(user.ID)
+}
+
+// VerifyLogin 登录第二步：校验验证码后签发JWT
+// 强制绑定场景下验证码会同时确认绑定，并返回首次生成的恢复码
+func (s *TwoFactorService) VerifyLogin(req auth.TwoFactorLoginRequest) (*userModel.User, string, []string, error) {
+	user, err := s.loadChallengeUser(req.TwoFactorToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var recoveryCodes []string
+	if user.TwoFactorEnabled {
+		if err := s.verify(user.ID, req.Code, true); err != nil {
+			return nil, "", nil, err
+		}
+	} else if s.IsRequiredFor(user) {
+		recoveryCodes, err = s.Enable(user.ID, req.Code)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		user.TwoFactorEnabled = true
+	} else {
+		// 登录过程中两步验证被管理员重置或策略被关闭
+		return nil, "", nil, common.NewError(common.CodeTwoFactorTokenInvalid)
+	}
+
+	authService := AuthService{}
+	token, err := authService.issueLoginToken(user)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	global.APP_LOG.Info("用户两步验证登录成功", zap.String("username", user.Username), zap.Uint("userID", user.ID))
+	return user, token, recoveryCodes, nil
+}
+
+// GetStatus 获取用户两步验证状态
+func (s *TwoFactorService) GetStatus(userID uint) (*userModel.TwoFactorStatusResponse, error) {
+	var user userModel.User
+	if err := global.APP_DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	status := &userModel.TwoFactorStatusResponse{
+		Required: s.IsRequiredFor(&user),
+	}
+	record, err := s.getRecord(userID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.Enabled {
+		status.Enabled = true
+		status.EnabledAt = record.EnabledAt
+		status.RecoveryCodesRemaining = len(decodeRecoveryCodes(record.RecoveryCodes))
+	}
+	return status, nil
+}
+
+// Setup 生成新的待确认密钥，需调用 Enable 提交验证码后才会生效
+func (s *TwoFactorService) Setup(userID uint) (*userModel.TwoFactorSetupResponse, error) {
+	var user userModel.User
+	if err := global.APP_DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	record, err := s.getRecord(userID)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.Enabled {
+		return nil, common.NewError(common.CodeConflict, "两步验证已启用，如需更换设备请先关闭")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("生成两步验证密钥失败: %v", err)
+	}
+
+	if record == nil {
+		record = &userModel.UserTwoFactor{UserID: userID, PendingSecret: secret}
+		if err := global.APP_DB.Create(record).Error; err != nil {
+			return nil, fmt.Errorf("保存两步验证密钥失败: %v", err)
+		}
+	} else if err := global.APP_DB.Model(record).Update("pending_secret", secret).Error; err != nil {
+		return nil, fmt.Errorf("保存两步验证密钥失败: %v", err)
+	}
+
+	return &userModel.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(twoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 使用验证器App生成的验证码确认绑定，返回一次性恢复码
+func (s *TwoFactorService) Enable(userID uint, code string) ([]string, error) {
+	record, err := s.getRecord(userID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.PendingSecret == "" {
+		return nil, common.NewError(common.CodeInvalidParam, "请先获取两步验证绑定信息")
+	}
+	if record.Enabled {
+		return nil, common.NewError(common.CodeConflict, "两步验证已启用")
+	}
+	if err := s.checkLocked(record); err != nil {
+		return nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(record.PendingSecret, code, time.Now())
+	if !ok {
+		s.recordFailure(record)
+		return nil, common.NewError(common.CodeTwoFactorInvalid)
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("生成恢复码失败: %v", err)
+	}
+
+	now := time.Now()
+	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(record).Updates(map[string]interface{}{
+			"enabled":         true,
+			"secret":          record.PendingSecret,
+			"pending_secret":  "",
+			"recovery_codes":  hashes,
+			"last_used_step":  step,
+			"enabled_at":      &now,
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&userModel.User{}).Where("id = ?", userID).Update("two_factor_enabled", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("启用两步验证失败: %v", err)
+	}
+
+	global.APP_LOG.Info("用户启用两步验证", zap.Uint("userID", userID))
+	return codes, nil
+}
+
+// Disable 关闭两步验证，需要提交当前验证码或恢复码
+func (s *TwoFactorService) Disable(userID uint, code string) error {
+	var user userModel.User
+	if err := global.APP_DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if s.IsRequiredFor(&user) {
+		return common.NewError(common.CodeForbidden, "系统要求管理员必须启用两步验证")
+	}
+	if err := s.verify(userID, code, true); err != nil {
+		return err
+	}
+	if err := s.remove(userID); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("用户关闭两步验证", zap.Uint("userID", userID))
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	// 只接受验证器验证码，避免用最后一个恢复码无限续期
+	if err := s.verify(userID, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("生成恢复码失败: %v", err)
+	}
+	if err := global.APP_DB.Model(&userModel.UserTwoFactor{}).
+		Where("user_id = ?", userID).
+		Update("recovery_codes", hashes).Error; err != nil {
+		return nil, fmt.Errorf("保存恢复码失败: %v", err)
+	}
+
+	global.APP_LOG.Info("用户重新生成两步验证恢复码", zap.Uint("userID", userID))
+	return codes, nil
+}
+
+// AdminReset 管理员重置用户的两步验证（用户丢失验证器和恢复码时使用）
+// 若系统要求该用户启用两步验证，其下次登录时需重新绑定
+func (s *TwoFactorService) AdminReset(userID uint) error {
+	var user userModel.User
+	if err := global.APP_DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if err := s.remove(userID); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info("管理员重置用户两步验证", zap.Uint("userID", userID), zap.String("username", user.Username))
+	return nil
+}
+
+// remove 删除两步验证记录并清除用户标记
+func (s *TwoFactorService) remove(userID uint) error {
+	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&userModel.UserTwoFactor{}).Error; err != nil {
+			return fmt.Errorf("删除两步验证信息失败: %v", err)
+		}
+		if err := tx.Model(&userModel.User{}).Where("id = ?", userID).Update("two_factor_enabled", false).Error; err != nil {
+			return fmt.Errorf("更新用户两步验证状态失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// verify 校验已启用的两步验证，allowRecovery 为true时也接受恢复码（使用后作废）
+func (s *TwoFactorService) verify(userID uint, code string, allowRecovery bool) error {
+	record, err := s.getRecord(userID)
+	if err != nil {
+		return err
+	}
+	if record == nil || !record.Enabled {
+		return common.NewError(common.CodeInvalidParam, "未启用两步验证")
+	}
+	if err := s.checkLocked(record); err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTP(record.Secret, code, time.Now()); ok {
+		// 同一时间步内的验证码只能使用一次
+		result := global.APP_DB.Model(&userModel.UserTwoFactor{}).
+			Where("id = ? AND last_used_step < ?", record.ID, step).
+			Updates(map[string]interface{}{"last_used_step": step, "failed_attempts": 0})
+		if result.Error != nil {
+			return fmt.Errorf("更新两步验证状态失败: %v", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+	} else if allowRecovery && s.consumeRecoveryCode(record, code) {
+		global.APP_LOG.Info("用户使用恢复码通过两步验证",
+			zap.Uint("userID", userID),
+			zap.String("method", twoFactorRecoveryCodeID))
+		return nil
+	}
+
+	s.recordFailure(record)
+	return common.NewError(common.CodeTwoFactorInvalid)
+}
+
+// checkLocked 检查是否因连续失败被锁定
+func (s *TwoFactorService) checkLocked(record *userModel.UserTwoFactor) error {
+	if record.LockedUntil != nil && record.LockedUntil.After(time.Now()) {
+		return common.NewError(common.CodeTwoFactorLocked)
+	}
+	return nil
+}
+
+// recordFailure 记录一次校验失败，达到上限后锁定一段时间
+func (s *TwoFactorService) recordFailure(record *userModel.UserTwoFactor) {
+	failures := record.FailedAttempts + 1
+	updates := map[string]interface{}{"failed_attempts": failures}
+	if failures >= twoFactorMaxFailures {
+		lockedUntil := time.Now().Add(twoFactorLockDuration)
+		updates["failed_attempts"] = 0
+		updates["locked_until"] = &lockedUntil
+		global.APP_LOG.Warn("两步验证连续失败次数过多，暂时锁定",
+			zap.Uint("userID", record.UserID),
+			zap.Time("lockedUntil", lockedUntil))
+	}
+	if err := global.APP_DB.Model(record).Updates(updates).Error; err != nil {
+		global.APP_LOG.Error("记录两步验证失败次数失败", zap.Uint("userID", record.UserID), zap.Error(err))
+	}
+}
+
+// consumeRecoveryCode 校验并作废恢复码
+func (s *TwoFactorService) consumeRecoveryCode(record *userModel.UserTwoFactor, code string) bool {
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return false
+	}
+
+	hashes := decodeRecoveryCodes(record.RecoveryCodes)
+	for i, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) != nil {
+			continue
+		}
+		remaining := append(hashes[:i:i], hashes[i+1:]...)
+		data, _ := json.Marshal(remaining)
+		// 以旧值为条件更新，避免并发请求重复使用同一恢复码
+		result := global.APP_DB.Model(&userModel.UserTwoFactor{}).
+			Where("id = ? AND recovery_codes = ?", record.ID, record.RecoveryCodes).
+			Updates(map[string]interface{}{"recovery_codes": string(data), "failed_attempts": 0})
+		return result.Error == nil && result.RowsAffected == 1
+	}
+	return false
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文和bcrypt哈希的JSON
+func generateRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, 0, twoFactorRecoveryCount)
+	hashes := make([]string, 0, twoFactorRecoveryCount)
+	for i := 0; i < twoFactorRecoveryCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		raw := hex.EncodeToString(buf)
+		code := raw[:5] + "-" + raw[5:]
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, "", err
+	}
+	return codes, string(data), nil
+}
+
+// normalizeRecoveryCode 统一恢复码格式（忽略大小写、空格和连字符）
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+// decodeRecoveryCodes 解析恢复码哈希列表
+func decodeRecoveryCodes(data string) []string {
+	var hashes []string
+	if data == "" {
+		return hashes
+	}
+	if err := json.Unmarshal([]byte(data), &hashes); err != nil {
+		return nil
+	}
+	return hashes
+}
@@ -0,0 +1,446 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/auth"
+	"oneclickvirt/service/ipam"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/storage"
+	"oneclickvirt/service/task"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// backupTaskTypes 备份相关的任务类型
+var backupTaskTypes = []string{"backup", "restore"}
+
+// Service 实例备份服务
+type Service struct{}
+
+// NewService 创建备份服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// CheckProviderSupport 检查Provider是否支持备份
+// Provider未加载到内存时无法判断，放行并由任务执行时最终确认
+func (s *Service) CheckProviderSupport(providerID uint) error {
+	prov, exists := provider2.GetProviderService().GetProviderByID(providerID)
+	if !exists {
+		return nil
+	}
+	if _, ok := prov.(provider.BackupProvider); !ok {
+		return fmt.Errorf("该实例所在的Provider（%s）不支持备份", prov.GetType())
+	}
+	return nil
+}
+
+// checkBackupAllowed 检查用户等级是否允许备份
+func (s *Service) checkBackupAllowed(userID uint) error {
+	permissionService := auth.PermissionService{}
+	effective, err := permissionService.GetUserEffectivePermission(userID)
+	if err != nil {
+		return fmt.Errorf("获取用户权限失败: %v", err)
+	}
+
+	// 管理员不受限制
+	if effective.EffectiveType == "admin" {
+		return nil
+	}
+
+	levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[effective.EffectiveLevel]
+	if !exists || levelLimits.MaxBackups <= 0 {
+		return errors.New("当前用户等级不允许创建备份")
+	}
+	return nil
+}
+
+// checkNoRunningBackupTask 检查实例是否有进行中的备份/恢复任务
+func (s *Service) checkNoRunningBackupTask(instanceID uint) error {
+	var count int64
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id = ? AND task_type IN (?) AND status IN (?)", instanceID,
+			backupTaskTypes, []string{"pending", "running"}).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("检查备份任务失败: %v", err)
+	}
+	if count > 0 {
+		return errors.New("该实例已有进行中的备份或恢复任务，请稍后再试")
+	}
+	return nil
+}
+
+// getProviderType 获取Provider类型
+func (s *Service) getProviderType(providerID uint) string {
+	var providerInfo providerModel.Provider
+	if err := global.APP_DB.Select("type").First(&providerInfo, providerID).Error; err != nil {
+		return ""
+	}
+	return providerInfo.Type
+}
+
+// checkRestoreAddresses 检查备份能否恢复到其他实例
+// 归档中包含源实例的网络配置，地址池分配的独立IP无法随之转移：源实例的地址会出现在目标实例上，
+// 目标实例原有的地址配置则会被覆盖，因此涉及地址池地址时只允许恢复到源实例本身
+func (s *Service) checkRestoreAddresses(backup *providerModel.InstanceBackup, target *providerModel.Instance) error {
+	ipamService := ipam.NewService()
+
+	allocated, err := ipamService.HasInstanceAllocations(target.ID)
+	if err != nil {
+		return err
+	}
+	if allocated {
+		return errors.New("目标实例持有地址池分配的独立IP地址，只能恢复该实例自身的备份")
+	}
+
+	for _, providerID := range []uint{backup.ProviderID, target.ProviderID} {
+		var providerInfo providerModel.Provider
+		if err := global.APP_DB.Unscoped().Select("id", "network_type").First(&providerInfo, providerID).Error; err != nil {
+			continue
+		}
+		managed, err := ipamService.ManagesProviderAddresses(providerInfo.ID, providerInfo.NetworkType)
+		if err != nil {
+			return err
+		}
+		if managed {
+			return errors.New("备份或目标实例所在Provider通过地址池分配独立IP地址，只能恢复到源实例")
+		}
+	}
+	return nil
+}
+
+// createTask 创建备份/恢复任务
+func (s *Service) createTask(instance *providerModel.Instance, taskType string, taskReq interface{}) (*adminModel.Task, error) {
+	taskData, err := json.Marshal(taskReq)
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	instanceID := instance.ID
+	providerID := instance.ProviderID
+	return task.GetTaskService().CreateTask(instance.UserID, &providerID, &instanceID, taskType, string(taskData), 0)
+}
+
+// CreateBackup 为实例创建备份
+// enforceLimit 为 false 时跳过用户等级的备份权限检查（管理员操作）
+func (s *Service) CreateBackup(instance *providerModel.Instance, trigger string, enforceLimit bool) (*providerModel.InstanceBackup, *adminModel.Task, error) {
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, nil, errors.New("只有运行中或已停止的实例才能创建备份")
+	}
+
+	if err := s.CheckProviderSupport(instance.ProviderID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkNoRunningBackupTask(instance.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if enforceLimit {
+		if err := s.checkBackupAllowed(instance.UserID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	backup := &providerModel.InstanceBackup{
+		Status:       providerModel.BackupStatusCreating,
+		Trigger:      trigger,
+		InstanceID:   instance.ID,
+		InstanceName: instance.Name,
+		InstanceType: instance.InstanceType,
+		OSType:       instance.OSType,
+		ProviderID:   instance.ProviderID,
+		ProviderType: s.getProviderType(instance.ProviderID),
+		UserID:       instance.UserID,
+	}
+	if err := global.APP_DB.Create(backup).Error; err != nil {
+		return nil, nil, fmt.Errorf("创建备份记录失败: %v", err)
+	}
+
+	taskModel, err := s.createTask(instance, "backup", adminModel.BackupTaskRequest{
+		BackupID:   backup.ID,
+		InstanceID: instance.ID,
+		ProviderID: instance.ProviderID,
+	})
+	if err != nil {
+		global.APP_DB.Delete(backup)
+		return nil, nil, fmt.Errorf("创建备份任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建实例备份任务",
+		zap.Uint("instanceId", instance.ID),
+		zap.Uint("backupId", backup.ID),
+		zap.String("trigger", trigger),
+		zap.Uint("taskId", taskModel.ID))
+
+	return backup, taskModel, nil
+}
+
+// RestoreBackup 将备份恢复到目标实例
+// 目标实例可以是源实例，也可以是其他节点上同类型Provider的同类型实例（不涉及地址池分配的独立IP时）；目标实例的网络与规格配置保持不变
+func (s *Service) RestoreBackup(backup *providerModel.InstanceBackup, target *providerModel.Instance) (*adminModel.Task, error) {
+	if backup.Status != providerModel.BackupStatusAvailable {
+		return nil, errors.New("只能恢复可用状态的备份")
+	}
+	if target.Status != "running" && target.Status != "stopped" {
+		return nil, errors.New("只有运行中或已停止的实例才能恢复备份")
+	}
+	if target.InstanceType != backup.InstanceType {
+		return nil, errors.New("备份与目标实例的类型不一致")
+	}
+	if providerType := s.getProviderType(target.ProviderID); providerType != backup.ProviderType {
+		return nil, fmt.Errorf("备份来自 %s 类型的Provider，无法恢复到 %s 类型的Provider", backup.ProviderType, providerType)
+	}
+
+	if backup.InstanceID != target.ID {
+		if err := s.checkRestoreAddresses(backup, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.CheckProviderSupport(target.ProviderID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNoRunningBackupTask(target.ID); err != nil {
+		return nil, err
+	}
+
+	originalStatus := target.Status
+	result := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", target.ID, originalStatus).
+		Update("status", "restoring")
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新实例状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("实例状态已变更，请刷新后重试")
+	}
+
+	taskModel, err := s.createTask(target, "restore", adminModel.RestoreBackupTaskRequest{
+		BackupID:         backup.ID,
+		TargetInstanceID: target.ID,
+		ProviderID:       target.ProviderID,
+		OriginalStatus:   originalStatus,
+	})
+	if err != nil {
+		global.APP_DB.Model(&providerModel.Instance{}).Where("id = ?", target.ID).Update("status", originalStatus)
+		return nil, fmt.Errorf("创建恢复任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建备份恢复任务",
+		zap.Uint("backupId", backup.ID),
+		zap.Uint("sourceInstanceId", backup.InstanceID),
+		zap.Uint("targetInstanceId", target.ID),
+		zap.Uint("taskId", taskModel.ID))
+
+	return taskModel, nil
+}
+
+// DeleteBackup 删除备份归档及记录
+func (s *Service) DeleteBackup(backup *providerModel.InstanceBackup) error {
+	if backup.Status == providerModel.BackupStatusCreating {
+		return errors.New("备份正在创建中，无法删除")
+	}
+
+	var restoring int64
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Where("task_type = ? AND status IN (?) AND task_data LIKE ?", "restore",
+			[]string{"pending", "running"}, fmt.Sprintf(`%%"backupId":%d,%%`, backup.ID)).
+		Count(&restoring).Error; err != nil {
+		return fmt.Errorf("检查恢复任务失败: %v", err)
+	}
+	if restoring > 0 {
+		return errors.New("该备份正在被恢复，无法删除")
+	}
+
+	if backup.StorageKey != "" {
+		backend, err := storage.GetBackupBackendByType(backup.StorageType)
+		if err != nil {
+			return fmt.Errorf("初始化备份存储失败: %v", err)
+		}
+		if err := backend.Delete(context.Background(), backup.StorageKey); err != nil {
+			return fmt.Errorf("删除备份归档失败: %v", err)
+		}
+	}
+
+	if err := global.APP_DB.Delete(backup).Error; err != nil {
+		return fmt.Errorf("删除备份记录失败: %v", err)
+	}
+
+	global.APP_LOG.Info("删除实例备份",
+		zap.Uint("backupId", backup.ID),
+		zap.Uint("instanceId", backup.InstanceID))
+	return nil
+}
+
+// GetBackup 获取备份
+func (s *Service) GetBackup(backupID uint) (*providerModel.InstanceBackup, error) {
+	var backup providerModel.InstanceBackup
+	if err := global.APP_DB.First(&backup, backupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("备份不存在")
+		}
+		return nil, fmt.Errorf("获取备份失败: %v", err)
+	}
+	return &backup, nil
+}
+
+// ListInstanceBackups 获取实例的备份列表
+func (s *Service) ListInstanceBackups(instanceID uint) ([]providerModel.InstanceBackup, error) {
+	var backups []providerModel.InstanceBackup
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).
+		Order("created_at DESC").
+		Find(&backups).Error; err != nil {
+		return nil, fmt.Errorf("获取备份列表失败: %v", err)
+	}
+	return backups, nil
+}
+
+// ListBackups 分页查询备份
+func (s *Service) ListBackups(req adminModel.BackupListRequest) ([]providerModel.InstanceBackup, int64, error) {
+	query := global.APP_DB.Model(&providerModel.InstanceBackup{})
+	if req.InstanceID > 0 {
+		query = query.Where("instance_id = ?", req.InstanceID)
+	}
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		query = query.Where("instance_name LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计备份数量失败: %v", err)
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	var backups []providerModel.InstanceBackup
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&backups).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取备份列表失败: %v", err)
+	}
+	return backups, total, nil
+}
+
+// GetSchedule 获取实例的定时备份计划，未设置时返回nil
+func (s *Service) GetSchedule(instanceID uint) (*providerModel.BackupSchedule, error) {
+	var schedule providerModel.BackupSchedule
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取定时备份计划失败: %v", err)
+	}
+	return &schedule, nil
+}
+
+// SetSchedule 创建或更新实例的定时备份计划
+func (s *Service) SetSchedule(instance *providerModel.Instance, req adminModel.BackupScheduleRequest, enforceLimit bool) (*providerModel.BackupSchedule, error) {
+	if err := s.CheckProviderSupport(instance.ProviderID); err != nil {
+		return nil, err
+	}
+	if enforceLimit && req.Enabled {
+		if err := s.checkBackupAllowed(instance.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule, err := s.GetSchedule(instance.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRunAt := time.Now().Add(time.Duration(req.IntervalHours) * time.Hour)
+	if schedule == nil {
+		schedule = &providerModel.BackupSchedule{
+			InstanceID:    instance.ID,
+			UserID:        instance.UserID,
+			Enabled:       req.Enabled,
+			IntervalHours: req.IntervalHours,
+			NextRunAt:     nextRunAt,
+		}
+		if err := global.APP_DB.Create(schedule).Error; err != nil {
+			return nil, fmt.Errorf("创建定时备份计划失败: %v", err)
+		}
+		return schedule, nil
+	}
+
+	// 间隔变化时按新间隔重新计算下次执行时间
+	if schedule.IntervalHours != req.IntervalHours || (!schedule.Enabled && req.Enabled) {
+		schedule.NextRunAt = nextRunAt
+	}
+	schedule.Enabled = req.Enabled
+	schedule.IntervalHours = req.IntervalHours
+	if err := global.APP_DB.Model(schedule).Select("enabled", "interval_hours", "next_run_at").Updates(schedule).Error; err != nil {
+		return nil, fmt.Errorf("更新定时备份计划失败: %v", err)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule 删除实例的定时备份计划
+func (s *Service) DeleteSchedule(instanceID uint) error {
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).Delete(&providerModel.BackupSchedule{}).Error; err != nil {
+		return fmt.Errorf("删除定时备份计划失败: %v", err)
+	}
+	return nil
+}
+
+// RunDueSchedules 为到期的定时备份计划创建备份任务
+func (s *Service) RunDueSchedules() {
+	now := time.Now()
+	var schedules []providerModel.BackupSchedule
+	if err := global.APP_DB.Where("enabled = ? AND next_run_at <= ?", true, now).
+		Find(&schedules).Error; err != nil {
+		global.APP_LOG.Error("查询到期的定时备份计划失败", zap.Error(err))
+		return
+	}
+
+	for _, schedule := range schedules {
+		var instance providerModel.Instance
+		if err := global.APP_DB.First(&instance, schedule.InstanceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				global.APP_DB.Delete(&schedule)
+			}
+			continue
+		}
+
+		// 无论本次是否成功都推进到下一周期，避免失败时每轮重复触发
+		global.APP_DB.Model(&schedule).Updates(map[string]interface{}{
+			"next_run_at": now.Add(time.Duration(schedule.IntervalHours) * time.Hour),
+			"last_run_at": now,
+		})
+
+		if _, _, err := s.CreateBackup(&instance, providerModel.BackupTriggerScheduled, true); err != nil {
+			global.APP_LOG.Warn("定时备份创建失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Uint("scheduleId", schedule.ID),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/notify"
+	"oneclickvirt/service/webhook"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RunCharges 对所有运行中的实例执行一轮计费
+// 每个实例按匹配的价格规则预付一个计费周期，已付费截止时间到达后才会再次扣款；
+// 余额不足时冻结该用户的全部实例，余额低于提醒阈值时发送一次低余额提醒
+func (s *Service) RunCharges() error {
+	if !global.APP_CONFIG.Billing.Enabled {
+		return nil
+	}
+
+	prices, err := s.loadEnabledPrices()
+	if err != nil {
+		return err
+	}
+	if len(prices) == 0 {
+		return nil
+	}
+
+	// 管理员不参与计费
+	var instances []providerModel.Instance
+	if err := global.APP_DB.
+		Where("status = ? AND is_frozen = ?", "running", false).
+		Where("user_id NOT IN (?)", global.APP_DB.Model(&userModel.User{}).Select("id").Where("user_type = ?", "admin")).
+		Find(&instances).Error; err != nil {
+		return fmt.Errorf("获取运行中实例失败: %v", err)
+	}
+
+	now := time.Now()
+	exhausted := make(map[uint]bool)
+	charged := make(map[uint]bool)
+	for i := range instances {
+		inst := &instances[i]
+		if exhausted[inst.UserID] {
+			continue
+		}
+		price := matchPrice(prices, inst)
+		if price == nil {
+			continue
+		}
+
+		ok, err := s.chargeInstance(inst, price, now)
+		if errors.Is(err, ErrInsufficientBalance) {
+			exhausted[inst.UserID] = true
+			continue
+		}
+		if err != nil {
+			global.APP_LOG.Error("实例计费失败",
+				zap.Uint("instanceId", inst.ID),
+				zap.Uint("userId", inst.UserID),
+				zap.Error(err))
+			continue
+		}
+		if ok {
+			charged[inst.UserID] = true
+		}
+	}
+
+	for userID := range exhausted {
+		s.freezeForInsufficientBalance(userID)
+	}
+	for userID := range charged {
+		if !exhausted[userID] {
+			s.checkLowBalance(userID)
+		}
+	}
+	return nil
+}
+
+// chargeInstance 为实例扣除一个计费周期的费用，未到扣款时间时返回false
+func (s *Service) chargeInstance(inst *providerModel.Instance, price *providerModel.BillingPrice, now time.Time) (bool, error) {
+	amount, period := price.PeriodCost()
+
+	var charged bool
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var state providerModel.InstanceBilling
+		if err := tx.Where(providerModel.InstanceBilling{InstanceID: inst.ID}).
+			Attrs(providerModel.InstanceBilling{UserID: inst.UserID}).
+			FirstOrCreate(&state).Error; err != nil {
+			return fmt.Errorf("获取实例计费状态失败: %v", err)
+		}
+		if state.PaidUntil.After(now) {
+			return nil
+		}
+
+		// 首次计费或停机超过一个周期后重新开始计费，避免补扣停机期间的费用
+		periodStart := state.PaidUntil
+		if periodStart.IsZero() || now.Sub(periodStart) > period {
+			periodStart = now
+		}
+		periodEnd := periodStart.Add(period)
+
+		instanceID := inst.ID
+		priceID := price.ID
+		if _, err := applyBalanceChangeInTx(tx, balanceChange{
+			UserID:      inst.UserID,
+			Type:        userModel.BalanceTxCharge,
+			Amount:      -amount,
+			InstanceID:  &instanceID,
+			PriceID:     &priceID,
+			PeriodStart: &periodStart,
+			PeriodEnd:   &periodEnd,
+			Description: fmt.Sprintf("实例 %s 计费（%s）", inst.Name, price.Name),
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&state).Updates(map[string]interface{}{
+			"user_id":         inst.UserID,
+			"price_id":        price.ID,
+			"cycle":           price.Cycle(),
+			"paid_until":      periodEnd,
+			"last_charged_at": now,
+			"total_charged":   state.TotalCharged + amount,
+		}).Error; err != nil {
+			return fmt.Errorf("更新实例计费状态失败: %v", err)
+		}
+		charged = true
+		return nil
+	})
+	return charged, err
+}
+
+// freezeForInsufficientBalance 余额不足时冻结用户所有未冻结的实例
+func (s *Service) freezeForInsufficientBalance(userID uint) {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Select("id, name, user_id, provider_id").
+		Where("user_id = ? AND is_frozen = ?", userID, false).
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Error("查询待冻结实例失败", zap.Uint("userId", userID), zap.Error(err))
+		return
+	}
+	if len(instances) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(instances))
+	for _, inst := range instances {
+		ids = append(ids, inst.ID)
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id IN ? AND is_frozen = ?", ids, false).
+		Updates(map[string]interface{}{
+			"is_frozen":     true,
+			"frozen_at":     time.Now(),
+			"frozen_reason": FrozenReasonInsufficientBalance,
+		}).Error; err != nil {
+		global.APP_LOG.Error("余额不足冻结实例失败", zap.Uint("userId", userID), zap.Error(err))
+		return
+	}
+
+	balance, _ := s.GetBalance(userID)
+	var current int64
+	if balance != nil {
+		current = balance.Balance
+	}
+	global.APP_LOG.Warn("用户余额不足，已冻结实例",
+		zap.Uint("userId", userID),
+		zap.Int64("balance", current),
+		zap.Int("count", len(instances)))
+
+	webhook.Publish(system.WebhookEventBalanceExhausted, map[string]interface{}{
+		"userId":      userID,
+		"balance":     current,
+		"instanceIds": ids,
+	})
+	for _, inst := range instances {
+		webhook.Publish(system.WebhookEventInstanceFrozen, map[string]interface{}{
+			"instanceId":   inst.ID,
+			"instanceName": inst.Name,
+			"userId":       inst.UserID,
+			"providerId":   inst.ProviderID,
+			"reason":       FrozenReasonInsufficientBalance,
+		})
+	}
+	notify.NotifyFrozen(userID, fmt.Sprintf("您的 %d 个实例", len(instances)), FrozenReasonInsufficientBalance)
+}
+
+// checkLowBalance 余额低于提醒阈值且尚未提醒时发送低余额提醒
+func (s *Service) checkLowBalance(userID uint) {
+	threshold := global.APP_CONFIG.Billing.LowBalanceThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	now := time.Now()
+	result := global.APP_DB.Model(&userModel.UserBalance{}).
+		Where("user_id = ? AND balance <= ? AND low_balance_warned_at IS NULL", userID, threshold).
+		Update("low_balance_warned_at", now)
+	if result.Error != nil {
+		global.APP_LOG.Error("更新低余额提醒状态失败", zap.Uint("userId", userID), zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	balance, err := s.GetBalance(userID)
+	if err != nil {
+		return
+	}
+	global.APP_LOG.Info("用户余额低于提醒阈值",
+		zap.Uint("userId", userID),
+		zap.Int64("balance", balance.Balance),
+		zap.Int64("threshold", threshold))
+
+	webhook.Publish(system.WebhookEventBalanceLow, map[string]interface{}{
+		"userId":    userID,
+		"balance":   balance.Balance,
+		"threshold": threshold,
+	})
+}
@@ -0,0 +1,133 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+
+	"gorm.io/gorm"
+)
+
+// ListPrices 分页获取价格规则
+func (s *Service) ListPrices(req adminModel.BillingPriceListRequest) ([]providerModel.BillingPrice, int64, error) {
+	query := global.APP_DB.Model(&providerModel.BillingPrice{})
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.InstanceType != "" {
+		query = query.Where("instance_type = ?", req.InstanceType)
+	}
+	if req.Enabled != nil {
+		query = query.Where("enabled = ?", *req.Enabled)
+	}
+	if req.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计价格规则失败: %v", err)
+	}
+
+	var prices []providerModel.BillingPrice
+	if err := query.Order("id DESC").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&prices).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取价格规则失败: %v", err)
+	}
+	return prices, total, nil
+}
+
+// CreatePrice 创建价格规则
+func (s *Service) CreatePrice(req adminModel.BillingPriceRequest) (*providerModel.BillingPrice, error) {
+	price := &providerModel.BillingPrice{}
+	if err := applyPriceRequest(price, req); err != nil {
+		return nil, err
+	}
+	if err := global.APP_DB.Create(price).Error; err != nil {
+		return nil, fmt.Errorf("创建价格规则失败: %v", err)
+	}
+	return price, nil
+}
+
+// UpdatePrice 更新价格规则，已扣款的周期不受影响，新规则从下一个计费周期开始生效
+func (s *Service) UpdatePrice(id uint, req adminModel.BillingPriceRequest) (*providerModel.BillingPrice, error) {
+	var price providerModel.BillingPrice
+	if err := global.APP_DB.First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("价格规则不存在")
+		}
+		return nil, fmt.Errorf("获取价格规则失败: %v", err)
+	}
+	if err := applyPriceRequest(&price, req); err != nil {
+		return nil, err
+	}
+	if err := global.APP_DB.Save(&price).Error; err != nil {
+		return nil, fmt.Errorf("更新价格规则失败: %v", err)
+	}
+	return &price, nil
+}
+
+// DeletePrice 删除价格规则
+func (s *Service) DeletePrice(id uint) error {
+	result := global.APP_DB.Delete(&providerModel.BillingPrice{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除价格规则失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("价格规则不存在")
+	}
+	return nil
+}
+
+// applyPriceRequest 校验并填充价格规则字段
+func applyPriceRequest(price *providerModel.BillingPrice, req adminModel.BillingPriceRequest) error {
+	if req.HourlyPrice <= 0 && req.MonthlyPrice <= 0 {
+		return errors.New("小时价格和月价格至少需要设置一项")
+	}
+	if req.ProviderID > 0 {
+		var count int64
+		global.APP_DB.Model(&providerModel.Provider{}).Where("id = ?", req.ProviderID).Count(&count)
+		if count == 0 {
+			return errors.New("Provider不存在")
+		}
+	}
+
+	price.Name = req.Name
+	price.ProviderID = req.ProviderID
+	price.InstanceType = req.InstanceType
+	price.CPU = req.CPU
+	price.Memory = req.Memory
+	price.Disk = req.Disk
+	price.HourlyPrice = req.HourlyPrice
+	price.MonthlyPrice = req.MonthlyPrice
+	price.Enabled = req.Enabled
+	price.Description = req.Description
+	return nil
+}
+
+// loadEnabledPrices 获取所有启用的价格规则
+func (s *Service) loadEnabledPrices() ([]providerModel.BillingPrice, error) {
+	var prices []providerModel.BillingPrice
+	if err := global.APP_DB.Where("enabled = ?", true).Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("获取价格规则失败: %v", err)
+	}
+	return prices, nil
+}
+
+// matchPrice 选出适用于实例的最具体的价格规则，具体程度相同时取ID较小的规则
+func matchPrice(prices []providerModel.BillingPrice, inst *providerModel.Instance) *providerModel.BillingPrice {
+	var best *providerModel.BillingPrice
+	for i := range prices {
+		p := &prices[i]
+		if !p.Enabled || !p.Matches(inst) {
+			continue
+		}
+		if best == nil || p.Specificity() > best.Specificity() ||
+			(p.Specificity() == best.Specificity() && p.ID < best.ID) {
+			best = p
+		}
+	}
+	return best
+}
@@ -0,0 +1,56 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+)
+
+// TestMatchPrice 测试价格规则匹配优先使用最具体的规则
+func TestMatchPrice(t *testing.T) {
+	prices := []providerModel.BillingPrice{
+		{ID: 1, Name: "默认", MonthlyPrice: 3000, Enabled: true},
+		{ID: 2, Name: "节点1容器", ProviderID: 1, InstanceType: "container", HourlyPrice: 5, Enabled: true},
+		{ID: 3, Name: "节点1 2核", ProviderID: 1, CPU: 2, HourlyPrice: 8, Enabled: true},
+		{ID: 4, Name: "已停用", ProviderID: 1, InstanceType: "container", CPU: 2, Memory: 1024, HourlyPrice: 1, Enabled: false},
+	}
+
+	tests := []struct {
+		name string
+		inst providerModel.Instance
+		want uint
+	}{
+		{"其他节点使用默认规则", providerModel.Instance{ProviderID: 2, InstanceType: "vm", CPU: 2}, 1},
+		{"实例类型比CPU更具体", providerModel.Instance{ProviderID: 1, InstanceType: "container", CPU: 2, Memory: 1024}, 2},
+		{"虚拟机匹配CPU规则", providerModel.Instance{ProviderID: 1, InstanceType: "vm", CPU: 2}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchPrice(prices, &tt.inst)
+			if got == nil || got.ID != tt.want {
+				t.Errorf("matchPrice() = %v, want ID %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := matchPrice(prices[3:], &providerModel.Instance{ProviderID: 1, InstanceType: "container", CPU: 2, Memory: 1024}); got != nil {
+		t.Errorf("停用的规则不应匹配: %v", got)
+	}
+}
+
+// TestPeriodCost 测试计费周期的金额和时长
+func TestPeriodCost(t *testing.T) {
+	hourly := providerModel.BillingPrice{HourlyPrice: 10, MonthlyPrice: 5000}
+	if amount, period := hourly.PeriodCost(); amount != 10 || period != time.Hour {
+		t.Errorf("小时计费 = %d/%v, want 10/1h", amount, period)
+	}
+
+	monthly := providerModel.BillingPrice{MonthlyPrice: 7200}
+	if amount, period := monthly.PeriodCost(); amount != 7200 || period != 30*24*time.Hour {
+		t.Errorf("月计费 = %d/%v, want 7200/720h", amount, period)
+	}
+	if got := monthly.HourlyCost(); got != 10 {
+		t.Errorf("月价格折算小时费用 = %d, want 10", got)
+	}
+}
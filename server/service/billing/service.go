@@ -0,0 +1,285 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FrozenReasonInsufficientBalance 余额不足导致的实例冻结原因
+const FrozenReasonInsufficientBalance = "insufficient_balance"
+
+// ErrInsufficientBalance 余额不足
+var ErrInsufficientBalance = errors.New("余额不足")
+
+// Service 预付费计费服务，金额单位均为分
+// 余额的所有变动都通过 applyBalanceChangeInTx 完成，保证余额与流水同时写入
+type Service struct{}
+
+// NewService 创建计费服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// balanceChange 一次余额变动
+type balanceChange struct {
+	UserID      uint
+	Type        string
+	Amount      int64 // 扣款为负数
+	InstanceID  *uint
+	PriceID     *uint
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	OperatorID  uint
+	Description string
+	// AllowNegative 为false时余额不足以扣减将返回 ErrInsufficientBalance
+	AllowNegative bool
+}
+
+// lockBalanceInTx 在事务中获取并锁定用户余额记录，不存在时自动创建
+func lockBalanceInTx(tx *gorm.DB, userID uint) (*userModel.UserBalance, error) {
+	var balance userModel.UserBalance
+	if err := tx.Where(userModel.UserBalance{UserID: userID}).FirstOrCreate(&balance).Error; err != nil {
+		return nil, fmt.Errorf("获取用户余额失败: %v", err)
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&balance, balance.ID).Error; err != nil {
+		return nil, fmt.Errorf("锁定用户余额失败: %v", err)
+	}
+	return &balance, nil
+}
+
+// applyBalanceChangeInTx 在事务中变更余额并写入流水
+func applyBalanceChangeInTx(tx *gorm.DB, change balanceChange) (*userModel.BalanceTransaction, error) {
+	balance, err := lockBalanceInTx(tx, change.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	newBalance := balance.Balance + change.Amount
+	if change.Amount < 0 && newBalance < 0 && !change.AllowNegative {
+		return nil, ErrInsufficientBalance
+	}
+
+	updates := map[string]interface{}{"balance": newBalance}
+	switch {
+	case change.Type == userModel.BalanceTxRecharge:
+		updates["total_recharged"] = balance.TotalRecharged + change.Amount
+	case change.Type == userModel.BalanceTxCharge:
+		updates["total_charged"] = balance.TotalCharged - change.Amount
+	}
+	// 余额回升到提醒阈值以上后允许再次提醒
+	if newBalance > global.APP_CONFIG.Billing.LowBalanceThreshold {
+		updates["low_balance_warned_at"] = nil
+	}
+	if err := tx.Model(balance).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("更新用户余额失败: %v", err)
+	}
+
+	record := &userModel.BalanceTransaction{
+		UserID:       change.UserID,
+		Type:         change.Type,
+		Amount:       change.Amount,
+		BalanceAfter: newBalance,
+		InstanceID:   change.InstanceID,
+		PriceID:      change.PriceID,
+		PeriodStart:  change.PeriodStart,
+		PeriodEnd:    change.PeriodEnd,
+		OperatorID:   change.OperatorID,
+		Description:  change.Description,
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("写入余额流水失败: %v", err)
+	}
+	return record, nil
+}
+
+// GetBalance 获取用户余额记录，没有记录时返回零余额
+func (s *Service) GetBalance(userID uint) (*userModel.UserBalance, error) {
+	var balance userModel.UserBalance
+	err := global.APP_DB.Where("user_id = ?", userID).First(&balance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &userModel.UserBalance{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取用户余额失败: %v", err)
+	}
+	return &balance, nil
+}
+
+// GetBalanceInfo 获取用户余额及当前运行实例的小时费用
+func (s *Service) GetBalanceInfo(userID uint) (*userModel.BalanceResponse, error) {
+	balance, err := s.GetBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userModel.BalanceResponse{
+		BillingEnabled:      global.APP_CONFIG.Billing.Enabled,
+		Currency:            global.APP_CONFIG.Billing.Currency,
+		Balance:             balance.Balance,
+		TotalRecharged:      balance.TotalRecharged,
+		TotalCharged:        balance.TotalCharged,
+		LowBalanceThreshold: global.APP_CONFIG.Billing.LowBalanceThreshold,
+	}
+	if resp.Currency == "" {
+		resp.Currency = "CNY"
+	}
+
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Where("user_id = ? AND status = ?", userID, "running").Find(&instances).Error; err != nil {
+		return nil, fmt.Errorf("获取运行中实例失败: %v", err)
+	}
+	prices, err := s.loadEnabledPrices()
+	if err != nil {
+		return nil, err
+	}
+	for i := range instances {
+		if price := matchPrice(prices, &instances[i]); price != nil {
+			resp.HourlyCost += price.HourlyCost()
+		}
+	}
+	return resp, nil
+}
+
+// ListTransactions 分页获取余额流水，userID为0时返回全部用户
+func (s *Service) ListTransactions(userID, instanceID uint, txType string, page, pageSize int) ([]userModel.BalanceTransaction, int64, error) {
+	query := global.APP_DB.Model(&userModel.BalanceTransaction{})
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if instanceID > 0 {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+	if txType != "" {
+		query = query.Where("type = ?", txType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计余额流水失败: %v", err)
+	}
+
+	var records []userModel.BalanceTransaction
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取余额流水失败: %v", err)
+	}
+	return records, total, nil
+}
+
+// AdjustBalance 管理员为用户充值、退款或调整余额
+// 余额变为正数后自动解冻因余额不足被冻结的实例
+func (s *Service) AdjustBalance(operatorID, userID uint, req adminModel.BalanceAdjustRequest) (*userModel.BalanceTransaction, error) {
+	if req.Amount == 0 {
+		return nil, errors.New("金额不能为0")
+	}
+	if req.Type != userModel.BalanceTxAdjust && req.Amount < 0 {
+		return nil, errors.New("充值和退款金额必须为正数")
+	}
+
+	var u userModel.User
+	if err := global.APP_DB.Select("id").First(&u, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	var record *userModel.BalanceTransaction
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		record, err = applyBalanceChangeInTx(tx, balanceChange{
+			UserID:        userID,
+			Type:          req.Type,
+			Amount:        req.Amount,
+			OperatorID:    operatorID,
+			Description:   req.Description,
+			AllowNegative: true,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	global.APP_LOG.Info("管理员变更用户余额",
+		zap.Uint("operatorId", operatorID),
+		zap.Uint("userId", userID),
+		zap.String("type", req.Type),
+		zap.Int64("amount", req.Amount),
+		zap.Int64("balanceAfter", record.BalanceAfter))
+
+	if record.BalanceAfter > 0 {
+		s.unfreezeUserInstances(userID)
+	}
+	return record, nil
+}
+
+// CheckCreateAllowed 创建实例前检查余额是否足够支付首个计费周期，未启用计费、没有匹配的价格或管理员时直接通过
+func (s *Service) CheckCreateAllowed(userID uint, inst *providerModel.Instance) error {
+	if !global.APP_CONFIG.Billing.Enabled {
+		return nil
+	}
+
+	prices, err := s.loadEnabledPrices()
+	if err != nil {
+		return err
+	}
+	price := matchPrice(prices, inst)
+	if price == nil {
+		return nil
+	}
+
+	var u userModel.User
+	if err := global.APP_DB.Select("id, user_type").First(&u, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if u.UserType == "admin" {
+		return nil
+	}
+
+	balance, err := s.GetBalance(userID)
+	if err != nil {
+		return err
+	}
+	amount, _ := price.PeriodCost()
+	if balance.Balance < amount {
+		return fmt.Errorf("余额不足：当前余额 %s，该规格首个计费周期需要 %s", formatAmount(balance.Balance), formatAmount(amount))
+	}
+	return nil
+}
+
+// unfreezeUserInstances 解冻用户因余额不足被冻结的实例
+func (s *Service) unfreezeUserInstances(userID uint) {
+	result := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("user_id = ? AND is_frozen = ? AND frozen_reason = ?", userID, true, FrozenReasonInsufficientBalance).
+		Updates(map[string]interface{}{
+			"is_frozen":     false,
+			"frozen_at":     nil,
+			"frozen_reason": "",
+		})
+	if result.Error != nil {
+		global.APP_LOG.Error("解冻余额不足冻结的实例失败", zap.Uint("userId", userID), zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		global.APP_LOG.Info("余额充足，已解冻实例",
+			zap.Uint("userId", userID),
+			zap.Int64("count", result.RowsAffected))
+	}
+}
+
+// formatAmount 将分格式化为带两位小数的金额
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
@@ -0,0 +1,47 @@
+package cache
+
+import "time"
+
+const (
+	captchaKeyPrefix = "captcha:"
+	captchaTTL       = 10 * time.Minute // 与内存LRU验证码缓存的有效期保持一致
+)
+
+// CaptchaStore 基于共享存储的验证码存储（兼容base64Captcha.Store接口）
+type CaptchaStore struct {
+	store Store
+}
+
+// NewCaptchaStore 创建验证码存储
+func NewCaptchaStore(store Store) *CaptchaStore {
+	return &CaptchaStore{store: store}
+}
+
+// Set 设置验证码
+func (s *CaptchaStore) Set(id string, value string) error {
+	return s.store.Set(captchaKeyPrefix+id, []byte(value), captchaTTL)
+}
+
+// Get 获取验证码，clear为true时读取后删除
+func (s *CaptchaStore) Get(id string, clear bool) string {
+	var value []byte
+	var ok bool
+	if clear {
+		value, ok = s.store.GetDel(captchaKeyPrefix + id)
+	} else {
+		value, ok = s.store.Get(captchaKeyPrefix + id)
+	}
+	if !ok {
+		return ""
+	}
+	return string(value)
+}
+
+// Verify 验证验证码
+func (s *CaptchaStore) Verify(id, answer string, clear bool) bool {
+	value := s.Get(id, clear)
+	if value == "" {
+		return false
+	}
+	return value == answer
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"oneclickvirt/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyPrefix   = "oneclickvirt:" // 所有键统一加前缀，避免与同库其他应用冲突
+	redisOpTimeout   = 3 * time.Second
+	redisScanBatch   = 500
+	redisDialTimeout = 5 * time.Second
+)
+
+// incrScript 计数器加一，首次创建时设置过期时间（保证原子性）
+var incrScript = redis.NewScript(`
+local v = redis.call("INCR", KEYS[1])
+if v == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return v
+`)
+
+// RedisStore 基于Redis的共享存储
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建Redis存储并检查连接
+func NewRedisStore(cfg config.Redis) (*RedisStore, error) {
+	if cfg.Addr == "" {
+		return nil, errors.New("未配置Redis地址")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  redisDialTimeout,
+		ReadTimeout:  redisOpTimeout,
+		WriteTimeout: redisOpTimeout,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisDialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("Redis连接测试失败: %v", err)
+	}
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), redisOpTimeout)
+}
+
+// Get 获取值
+func (s *RedisStore) Get(key string) ([]byte, bool) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	value, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set 设置值
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+// SetNX 仅在键不存在时设置
+func (s *RedisStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.SetNX(ctx, redisKeyPrefix+key, value, ttl).Result()
+}
+
+// GetDel 获取值并删除
+func (s *RedisStore) GetDel(key string) ([]byte, bool) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	value, err := s.client.GetDel(ctx, redisKeyPrefix+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Exists 判断键是否存在
+func (s *RedisStore) Exists(key string) bool {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	n, err := s.client.Exists(ctx, redisKeyPrefix+key).Result()
+	return err == nil && n > 0
+}
+
+// Delete 删除键
+func (s *RedisStore) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisKeyPrefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// DeleteByPrefix 删除指定前缀的所有键（使用SCAN，避免KEYS阻塞Redis）
+func (s *RedisStore) DeleteByPrefix(prefix string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var cursor uint64
+	for {
+		keys, next, err := s.client.Scan(ctx, cursor, redisKeyPrefix+prefix+"*", redisScanBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := s.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// Incr 计数器加一
+func (s *RedisStore) Incr(key string, ttl time.Duration) (int64, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	return incrScript.Run(ctx, s.client, []string{redisKeyPrefix + key}, ttl.Milliseconds()).Int64()
+}
+
+// Shared Redis存储在多个进程之间共享
+func (s *RedisStore) Shared() bool {
+	return true
+}
+
+// Close 关闭Redis连接
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// encodeCounter 计数器编码（与Redis INCR的十进制字符串格式一致）
+func encodeCounter(v int64) []byte {
+	return []byte(strconv.FormatInt(v, 10))
+}
+
+// decodeCounter 计数器解码
+func decodeCounter(b []byte) int64 {
+	v, _ := strconv.ParseInt(string(b), 10, 64)
+	return v
+}
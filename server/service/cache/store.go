@@ -0,0 +1,291 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+
+	"go.uber.org/zap"
+)
+
+// Store 键值存储接口
+// 默认使用进程内存实现；启用 system.use-redis 后切换为Redis实现，
+// 使JWT黑名单、验证码、用户缓存和限流计数可在多个面板实例之间共享
+type Store interface {
+	// Get 获取值，不存在或已过期时返回false
+	Get(key string) ([]byte, bool)
+	// Set 设置值，ttl<=0表示永不过期
+	Set(key string, value []byte, ttl time.Duration) error
+	// SetNX 仅在键不存在时设置，返回是否设置成功
+	SetNX(key string, value []byte, ttl time.Duration) (bool, error)
+	// GetDel 获取值并删除
+	GetDel(key string) ([]byte, bool)
+	// Exists 判断键是否存在
+	Exists(key string) bool
+	// Delete 删除键
+	Delete(keys ...string) error
+	// DeleteByPrefix 删除指定前缀的所有键
+	DeleteByPrefix(prefix string) error
+	// Incr 计数器加一并返回新值，计数器首次创建时设置过期时间
+	Incr(key string, ttl time.Duration) (int64, error)
+	// Shared 存储是否在多个进程之间共享
+	Shared() bool
+	// Close 关闭存储
+	Close() error
+}
+
+var (
+	storeInstance Store
+	storeMutex    sync.RWMutex
+)
+
+// GetStore 获取全局存储，未初始化时使用内存存储
+func GetStore() Store {
+	storeMutex.RLock()
+	s := storeInstance
+	storeMutex.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	if storeInstance == nil {
+		storeInstance = NewMemoryStore()
+	}
+	return storeInstance
+}
+
+// InitStore 根据配置初始化全局存储
+// Redis连接失败时回退到内存存储，此时多实例部署下的数据不会共享
+func InitStore() Store {
+	var s Store
+	if global.APP_CONFIG.System.UseRedis {
+		redisStore, err := NewRedisStore(global.APP_CONFIG.Redis)
+		if err != nil {
+			global.APP_LOG.Error("Redis连接失败，回退到内存存储（多实例部署时会话与缓存将无法共享）",
+				zap.String("addr", global.APP_CONFIG.Redis.Addr),
+				zap.Error(err))
+		} else {
+			global.APP_LOG.Info("Redis存储初始化完成",
+				zap.String("addr", global.APP_CONFIG.Redis.Addr),
+				zap.Int("db", global.APP_CONFIG.Redis.DB))
+			s = redisStore
+		}
+	}
+	if s == nil {
+		s = NewMemoryStore()
+	}
+
+	storeMutex.Lock()
+	old := storeInstance
+	storeInstance = s
+	storeMutex.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return s
+}
+
+// memoryItem 内存存储项
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time // 零值表示永不过期
+}
+
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// MemoryStore 进程内存存储
+type MemoryStore struct {
+	data      map[string]*memoryItem
+	mutex     sync.Mutex
+	stopChan  chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryStore 创建内存存储并启动过期清理
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		data:     make(map[string]*memoryItem),
+		stopChan: make(chan struct{}),
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// cleanupLoop 定期清理过期键
+func (s *MemoryStore) cleanupLoop() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil && global.APP_LOG != nil {
+			global.APP_LOG.Error("内存存储清理goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+	}()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.cleanupExpired()
+		}
+	}
+}
+
+// cleanupExpired 清理过期键
+func (s *MemoryStore) cleanupExpired() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for key, item := range s.data {
+		if item.expired(now) {
+			delete(s.data, key)
+		}
+	}
+}
+
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// load 读取未过期的项（需要持有锁）
+func (s *MemoryStore) load(key string) (*memoryItem, bool) {
+	item, ok := s.data[key]
+	if !ok {
+		return nil, false
+	}
+	if item.expired(time.Now()) {
+		delete(s.data, key)
+		return nil, false
+	}
+	return item, true
+}
+
+// Get 获取值
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.load(key)
+	if !ok {
+		return nil, false
+	}
+	return item.value, true
+}
+
+// Set 设置值
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data[key] = &memoryItem{value: value, expiresAt: expiresAt(ttl)}
+	return nil
+}
+
+// SetNX 仅在键不存在时设置
+func (s *MemoryStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.load(key); ok {
+		return false, nil
+	}
+	s.data[key] = &memoryItem{value: value, expiresAt: expiresAt(ttl)}
+	return true, nil
+}
+
+// GetDel 获取值并删除
+func (s *MemoryStore) GetDel(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.load(key)
+	if !ok {
+		return nil, false
+	}
+	delete(s.data, key)
+	return item.value, true
+}
+
+// Exists 判断键是否存在
+func (s *MemoryStore) Exists(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.load(key)
+	return ok
+}
+
+// Delete 删除键
+func (s *MemoryStore) Delete(keys ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(s.data, key)
+	}
+	return nil
+}
+
+// DeleteByPrefix 删除指定前缀的所有键
+func (s *MemoryStore) DeleteByPrefix(prefix string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.data, key)
+		}
+	}
+	return nil
+}
+
+// Incr 计数器加一
+func (s *MemoryStore) Incr(key string, ttl time.Duration) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var count int64
+	item, ok := s.load(key)
+	if ok {
+		count = decodeCounter(item.value)
+	} else {
+		item = &memoryItem{expiresAt: expiresAt(ttl)}
+		s.data[key] = item
+	}
+	count++
+	item.value = encodeCounter(count)
+	return count, nil
+}
+
+// Shared 内存存储仅在当前进程内有效
+func (s *MemoryStore) Shared() bool {
+	return false
+}
+
+// Close 停止清理任务
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stopChan)
+	})
+	return nil
+}
+
+// Len 返回当前键数量（含未清理的过期键）
+func (s *MemoryStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.data)
+}
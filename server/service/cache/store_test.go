@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Basic(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	store.Set("k1", []byte("v1"), time.Minute)
+	if v, ok := store.Get("k1"); !ok || string(v) != "v1" {
+		t.Errorf("读取失败: %q %v", v, ok)
+	}
+
+	// 过期
+	store.Set("k2", []byte("v2"), 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if store.Exists("k2") {
+		t.Error("过期键仍然存在")
+	}
+
+	// GetDel
+	if v, ok := store.GetDel("k1"); !ok || string(v) != "v1" {
+		t.Errorf("GetDel失败: %q %v", v, ok)
+	}
+	if store.Exists("k1") {
+		t.Error("GetDel后键仍然存在")
+	}
+}
+
+func TestMemoryStore_SetNX(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	if ok, _ := store.SetNX("lock", []byte("a"), time.Minute); !ok {
+		t.Error("首次SetNX应该成功")
+	}
+	if ok, _ := store.SetNX("lock", []byte("b"), time.Minute); ok {
+		t.Error("键已存在时SetNX不应该成功")
+	}
+	if v, _ := store.Get("lock"); string(v) != "a" {
+		t.Errorf("SetNX覆盖了已有值: %q", v)
+	}
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		if n, err := store.Incr("counter", 50*time.Millisecond); err != nil || n != i {
+			t.Errorf("第%d次计数错误: %d %v", i, n, err)
+		}
+	}
+
+	// 计数窗口过期后重新计数
+	time.Sleep(100 * time.Millisecond)
+	if n, _ := store.Incr("counter", time.Minute); n != 1 {
+		t.Errorf("窗口过期后应重新计数，实际为%d", n)
+	}
+}
+
+func TestMemoryStore_DeleteByPrefix(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	store.Set("user:1:a", []byte("1"), time.Minute)
+	store.Set("user:1:b", []byte("2"), time.Minute)
+	store.Set("user:2:a", []byte("3"), time.Minute)
+
+	store.DeleteByPrefix("user:1:")
+
+	if store.Exists("user:1:a") || store.Exists("user:1:b") {
+		t.Error("前缀删除失败")
+	}
+	if !store.Exists("user:2:a") {
+		t.Error("不应该删除其他前缀的键")
+	}
+}
+
+func TestUserCacheService_JSON(t *testing.T) {
+	cache := GetUserCacheService()
+
+	key := MakeUserTrafficOverviewKey(99)
+	cache.SetJSON(key, map[string]interface{}{"used": 10}, time.Minute)
+
+	var got map[string]interface{}
+	if !cache.GetJSON(key, &got) {
+		t.Fatal("JSON缓存读取失败")
+	}
+	if got["used"].(float64) != 10 {
+		t.Errorf("JSON缓存内容不正确: %v", got)
+	}
+
+	cache.InvalidateUserCache(99)
+	if cache.GetJSON(key, &got) {
+		t.Error("JSON缓存未被清除")
+	}
+}
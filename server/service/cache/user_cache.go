@@ -13,6 +13,7 @@ import (
 )
 
 // UserCacheService 用户数据缓存服务
+// Get/Set 为进程内缓存，可保存任意对象；GetJSON/SetJSON 经由 Store 读写，启用Redis时在多实例间共享
 type UserCacheService struct {
 	cache       sync.Map // key: string -> *CacheEntry
 	ctx         context.Context
@@ -118,9 +119,38 @@ func (s *UserCacheService) Set(key string, data interface{}, ttl time.Duration)
 	s.cache.Store(key, entry)
 }
 
+// GetJSON 从共享存储获取JSON编码的缓存数据并解码到target
+// 启用Redis时多个面板实例共享同一份缓存，需要跨进程共享的数据应使用 GetJSON/SetJSON
+func (s *UserCacheService) GetJSON(key string, target interface{}) bool {
+	data, ok := GetStore().Get(userCacheKeyPrefix + key)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		GetStore().Delete(userCacheKeyPrefix + key)
+		return false
+	}
+	return true
+}
+
+// SetJSON 将数据JSON编码后写入共享存储
+func (s *UserCacheService) SetJSON(key string, data interface{}, ttl time.Duration) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		if global.APP_LOG != nil {
+			global.APP_LOG.Warn("缓存数据序列化失败", zap.String("key", key), zap.Error(err))
+		}
+		return
+	}
+	if err := GetStore().Set(userCacheKeyPrefix+key, encoded, ttl); err != nil && global.APP_LOG != nil {
+		global.APP_LOG.Warn("写入缓存失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
 // Delete 删除缓存
 func (s *UserCacheService) Delete(key string) {
 	s.cache.Delete(key)
+	GetStore().Delete(userCacheKeyPrefix + key)
 }
 
 // DeleteByPrefix 删除指定前缀的所有缓存
@@ -137,6 +167,7 @@ func (s *UserCacheService) DeleteByPrefix(prefix string) {
 	for _, key := range toRemove {
 		s.cache.Delete(key)
 	}
+	GetStore().DeleteByPrefix(userCacheKeyPrefix + prefix)
 }
 
 // Shutdown 关闭缓存服务
@@ -150,6 +181,9 @@ func (s *UserCacheService) Shutdown() {
 
 // CacheKeys 缓存键常量
 const (
+	// 用户缓存在共享存储中的键前缀
+	userCacheKeyPrefix = "usercache:"
+
 	// Dashboard缓存 - 1分钟
 	KeyUserDashboard = "user:dashboard:%d" // userID
 	TTLUserDashboard = 1 * time.Minute
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"oneclickvirt/model/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 支持的数据库类型（system.db-type）
+const (
+	TypeMySQL    = "mysql"
+	TypeMariaDB  = "mariadb"
+	TypePostgres = "postgres"
+	TypeSQLite   = "sqlite"
+)
+
+// SupportedTypes 支持的数据库类型
+var SupportedTypes = []string{TypeMySQL, TypeMariaDB, TypePostgres, TypeSQLite}
+
+const (
+	// defaultMysqlOptions MySQL/MariaDB默认连接参数
+	defaultMysqlOptions = "charset=utf8mb4&parseTime=True&loc=Local&time_zone=%27%2B08%3A00%27"
+	// defaultPostgresOptions PostgreSQL默认连接参数，时区与MySQL的默认设置保持一致
+	defaultPostgresOptions = "sslmode=disable TimeZone=Asia/Shanghai"
+	// defaultSQLiteOptions SQLite默认连接参数
+	// WAL模式允许读写并发；事务开始即获取写锁，配合忙等待避免并发写入时出现 database is locked
+	defaultSQLiteOptions = "_loc=auto&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+)
+
+// IsSupportedType 判断是否为支持的数据库类型
+func IsSupportedType(dbType string) bool {
+	for _, t := range SupportedTypes {
+		if t == dbType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMySQLCompatible 判断是否为MySQL兼容的数据库（MySQL或MariaDB）
+func IsMySQLCompatible(dbType string) bool {
+	return dbType == "" || dbType == TypeMySQL || dbType == TypeMariaDB
+}
+
+// DefaultPort 获取数据库类型的默认端口，SQLite返回空
+func DefaultPort(dbType string) string {
+	switch dbType {
+	case TypePostgres:
+		return "5432"
+	case TypeSQLite:
+		return ""
+	default:
+		return "3306"
+	}
+}
+
+// DefaultOptions 获取数据库类型的默认连接参数（对应配置项 mysql.config）
+func DefaultOptions(dbType string) string {
+	switch dbType {
+	case TypePostgres:
+		return defaultPostgresOptions
+	case TypeSQLite:
+		return defaultSQLiteOptions
+	default:
+		return defaultMysqlOptions
+	}
+}
+
+// SQLitePath 解析SQLite数据库文件路径
+// 只给出名称（不含目录和扩展名）时，文件放在 storage 目录下，如 oneclickvirt -> storage/oneclickvirt.db
+func SQLitePath(dbname string) string {
+	if dbname == "" {
+		dbname = "oneclickvirt"
+	}
+	if !strings.ContainsAny(dbname, `/\`) && filepath.Ext(dbname) == "" {
+		return filepath.Join("storage", dbname+".db")
+	}
+	return dbname
+}
+
+// NewDialector 根据数据库类型创建GORM方言
+// Dbname 为空时连接到服务器而不指定数据库（PostgreSQL连接默认的 postgres 库），用于检查和创建数据库
+func NewDialector(m config.MysqlConfig) (gorm.Dialector, error) {
+	options := m.Config
+	// 切换数据库类型后 mysql.config 可能仍是MySQL格式的参数，此时使用目标数据库的默认参数
+	if options == "" || (!IsMySQLCompatible(m.DbType) && strings.Contains(options, "charset=")) {
+		options = DefaultOptions(m.DbType)
+	}
+	port := m.Port
+	if port == "" {
+		port = DefaultPort(m.DbType)
+	}
+
+	switch m.DbType {
+	case TypePostgres:
+		dbname := m.Dbname
+		if dbname == "" {
+			dbname = "postgres"
+		}
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s %s",
+			quotePostgresValue(m.Path), port, quotePostgresValue(m.Username),
+			quotePostgresValue(m.Password), quotePostgresValue(dbname), options)
+		return postgres.New(postgres.Config{DSN: dsn}), nil
+	case TypeSQLite:
+		if !sqliteSupported {
+			return nil, fmt.Errorf("当前程序编译时未启用CGO，无法使用SQLite，请使用 CGO_ENABLED=1 重新编译")
+		}
+		return sqlite.Open(SQLitePath(m.Dbname) + "?" + options), nil
+	case "", TypeMySQL, TypeMariaDB:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
+			m.Username, m.Password, m.Path, port, m.Dbname, options)
+		return mysql.New(mysql.Config{
+			DSN:                       dsn,
+			DefaultStringSize:         191,   // string 类型字段的默认长度
+			SkipInitializeWithVersion: false, // 根据版本自动配置
+		}), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库类型: %s，支持: %s", m.DbType, strings.Join(SupportedTypes, ", "))
+	}
+}
+
+// CreateDatabaseIfNotExists 创建数据库（如果不存在），返回是否新建
+// SQLite 只需创建数据库文件所在的目录，文件在首次连接时自动创建
+func CreateDatabaseIfNotExists(m config.MysqlConfig) (bool, error) {
+	if m.DbType == TypeSQLite {
+		path := SQLitePath(m.Dbname)
+		if _, err := os.Stat(path); err == nil {
+			return false, nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return false, fmt.Errorf("创建数据库目录失败: %w", err)
+		}
+		return true, nil
+	}
+	if m.Dbname == "" {
+		return false, fmt.Errorf("数据库名不能为空")
+	}
+
+	// 连接数据库服务器（不指定数据库）
+	serverConfig := m
+	serverConfig.Dbname = ""
+	dialector, err := NewDialector(serverConfig)
+	if err != nil {
+		return false, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return false, fmt.Errorf("连接数据库服务器失败: %w", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	// 检查数据库是否存在
+	var count int64
+	if m.DbType == TypePostgres {
+		err = db.Raw("SELECT COUNT(*) FROM pg_database WHERE datname = ?", m.Dbname).Scan(&count).Error
+	} else {
+		err = db.Raw("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", m.Dbname).Scan(&count).Error
+	}
+	if err != nil {
+		return false, fmt.Errorf("检查数据库是否存在失败: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	// 数据库名不能使用占位符，按方言加引号
+	createSQL := fmt.Sprintf("CREATE DATABASE %s CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", db.Statement.Quote(m.Dbname))
+	if m.DbType == TypePostgres {
+		createSQL = fmt.Sprintf("CREATE DATABASE %s ENCODING 'UTF8'", db.Statement.Quote(m.Dbname))
+	}
+	if err := db.Exec(createSQL).Error; err != nil {
+		return false, fmt.Errorf("创建数据库失败: %w", err)
+	}
+	return true, nil
+}
+
+// quotePostgresValue 为PostgreSQL连接串中的值加引号，允许值中包含空格和引号
+func quotePostgresValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
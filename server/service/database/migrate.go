@@ -0,0 +1,63 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AutoMigrate 按数据库方言迁移表结构
+// MySQL 的索引名只需在表内唯一，模型中不同表复用了相同的索引名（如 idx_status）；
+// PostgreSQL 和 SQLite 的索引名在整个库内唯一，迁移前为这些索引名加上表名前缀
+func AutoMigrate(db *gorm.DB, models ...interface{}) error {
+	if db.Dialector.Name() != TypeMySQL {
+		for _, model := range models {
+			if err := scopeIndexNames(db, model); err != nil {
+				return err
+			}
+		}
+	}
+	return db.AutoMigrate(models...)
+}
+
+// scopeIndexNames 将模型字段标签中显式指定的索引名改为 <表名>_<索引名>
+// GORM 每次解析索引都会读取字段标签，修改缓存中的字段标签即可对后续迁移生效，重复调用不会重复添加前缀
+func scopeIndexNames(db *gorm.DB, model interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return fmt.Errorf("解析模型 %T 失败: %v", model, err)
+	}
+	prefix := stmt.Schema.Table + "_"
+
+	for _, field := range stmt.Schema.Fields {
+		if field.TagSettings["INDEX"] == "" && field.TagSettings["UNIQUEINDEX"] == "" {
+			continue
+		}
+		settings := strings.Split(field.Tag.Get("gorm"), ";")
+		for i, setting := range settings {
+			key, value, found := strings.Cut(setting, ":")
+			upperKey := strings.ToUpper(strings.TrimSpace(key))
+			if !found || (upperKey != "INDEX" && upperKey != "UNIQUEINDEX") {
+				continue
+			}
+			// 未指定名称的索引由GORM按表名生成，本身就是唯一的
+			if value == "" || strings.HasPrefix(value, ",") || strings.HasPrefix(value, prefix) {
+				continue
+			}
+			settings[i] = key + ":" + prefix + value
+		}
+		field.Tag = replaceStructTag(field.Tag, "gorm", strings.Join(settings, ";"))
+	}
+	return nil
+}
+
+// replaceStructTag 替换结构体标签中指定键的值
+func replaceStructTag(tag reflect.StructTag, key, value string) reflect.StructTag {
+	old, ok := tag.Lookup(key)
+	if !ok {
+		return tag
+	}
+	return reflect.StructTag(strings.Replace(string(tag), key+`:"`+old+`"`, key+`:"`+value+`"`, 1))
+}
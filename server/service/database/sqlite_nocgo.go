@@ -0,0 +1,6 @@
+//go:build !cgo
+
+package database
+
+// sqliteSupported SQLite驱动依赖CGO，仅在启用CGO编译时可用
+const sqliteSupported = false
@@ -0,0 +1,69 @@
+//go:build cgo
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestClaimDomain(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/domain.db"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	if err := db.AutoMigrate(&providerModel.InstanceDomain{}); err != nil {
+		t.Fatalf("创建域名绑定表失败: %v", err)
+	}
+	global.APP_DB, global.APP_LOG = db, zap.NewNop()
+
+	// 两个用户同时等待验证同一域名
+	owner := providerModel.InstanceDomain{UserID: 1, InstanceID: 1, ProviderID: 1, Domain: "app.example.com", Protocol: "http", TargetPort: 80}
+	squatter := providerModel.InstanceDomain{UserID: 2, InstanceID: 2, ProviderID: 2, Domain: "app.example.com", Protocol: "http", TargetPort: 80}
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("创建绑定失败: %v", err)
+	}
+	if err := db.Create(&squatter).Error; err != nil {
+		t.Fatalf("其他用户未验证的绑定不应阻止创建: %v", err)
+	}
+
+	s := NewService()
+	providerIDs, err := s.claimDomain(owner.UserID, owner.Domain)
+	if err != nil {
+		t.Fatalf("验证通过后认领域名失败: %v", err)
+	}
+	if len(providerIDs) != 2 {
+		t.Errorf("应同步两个节点的配置，实际为 %v", providerIDs)
+	}
+	if err := db.First(&squatter, squatter.ID).Error; err == nil {
+		t.Error("其他用户的未验证绑定应被删除")
+	}
+	db.First(&owner, owner.ID)
+	if owner.VerifiedAt == nil {
+		t.Error("验证用户的绑定应标记为已验证")
+	}
+
+	if _, err := s.claimDomain(squatter.UserID, owner.Domain); err == nil {
+		t.Error("已被其他用户验证的域名不能再被认领")
+	}
+	if claimed, _ := s.verifiedByOtherUser(squatter.UserID, owner.Domain); !claimed {
+		t.Error("应识别出域名已被其他用户验证")
+	}
+
+	// 过期的未验证绑定被清理
+	expired := providerModel.InstanceDomain{UserID: 3, InstanceID: 3, ProviderID: 3, Domain: "old.example.com", Protocol: "https", TargetPort: 443, VerifyMethod: providerModel.DomainVerifyDNS}
+	db.Create(&expired)
+	db.Model(&expired).UpdateColumn("created_at", time.Now().Add(-pendingBindingTTL-time.Hour))
+	s.CleanupExpiredPending()
+	if err := db.First(&expired, expired.ID).Error; err == nil {
+		t.Error("过期的未验证绑定应被删除")
+	}
+}
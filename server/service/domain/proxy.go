@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	nginxHTTPConf   = "/etc/nginx/conf.d/oneclickvirt-domains.conf"
+	nginxStreamConf = "/etc/nginx/oneclickvirt/stream.conf"
+
+	// syncSuccessMarker 同步脚本成功时输出的标记，失败时输出nginx的错误信息
+	syncSuccessMarker = "OCV_SYNC_OK"
+)
+
+// syncLocks 每个Provider一把锁，避免并发同步时配置文件互相覆盖
+var syncLocks sync.Map
+
+// proxyEntry 反向代理的一条转发记录
+type proxyEntry struct {
+	Domain   string
+	Protocol string // http, https
+	Backend  string // 实例内网地址:端口
+}
+
+// buildNginxConfig 生成节点上的nginx配置
+// http配置按域名生成server块转发到实例；stream配置按SNI将443端口的TLS连接透传到实例
+func buildNginxConfig(entries []proxyEntry) (httpConf, streamConf string) {
+	httpBackends := make(map[string]string)
+	tlsBackends := make(map[string]string)
+
+	for _, e := range entries {
+		if e.Backend == "" {
+			continue
+		}
+		if e.Protocol == providerModel.DomainProtocolHTTPS {
+			tlsBackends[e.Domain] = e.Backend
+		} else {
+			httpBackends[e.Domain] = e.Backend
+		}
+	}
+
+	var h strings.Builder
+	h.WriteString("# managed by oneclickvirt, do not edit\n")
+	for _, domain := range sortedKeys(httpBackends) {
+		fmt.Fprintf(&h, "\nserver {\n    listen 80;\n    server_name %s;\n", domain)
+		fmt.Fprintf(&h, "\n    location / {\n"+
+			"        proxy_pass http://%s;\n"+
+			"        proxy_http_version 1.1;\n"+
+			"        proxy_set_header Host $host;\n"+
+			"        proxy_set_header X-Real-IP $remote_addr;\n"+
+			"        proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;\n"+
+			"        proxy_set_header X-Forwarded-Proto $scheme;\n"+
+			"        proxy_set_header Upgrade $http_upgrade;\n"+
+			"        proxy_set_header Connection $http_connection;\n"+
+			"    }\n", httpBackends[domain])
+		h.WriteString("}\n")
+	}
+
+	var st strings.Builder
+	st.WriteString("# managed by oneclickvirt, do not edit\n")
+	if len(tlsBackends) > 0 {
+		st.WriteString("stream {\n    map $ssl_preread_server_name $oneclickvirt_tls_backend {\n")
+		for _, domain := range sortedKeys(tlsBackends) {
+			fmt.Fprintf(&st, "        %s %s;\n", domain, tlsBackends[domain])
+		}
+		st.WriteString("    }\n\n    server {\n        listen 443;\n        ssl_preread on;\n        proxy_pass $oneclickvirt_tls_backend;\n        proxy_connect_timeout 10s;\n    }\n}\n")
+	}
+	return h.String(), st.String()
+}
+
+// sortedKeys 按字典序返回map的键，保证生成的配置稳定
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildSyncScript 生成写入配置并重载nginx的脚本
+// 配置校验失败时恢复原有配置，保证节点上已生效的域名不受影响
+func buildSyncScript(httpConf, streamConf string) string {
+	var b strings.Builder
+	b.WriteString("command -v nginx >/dev/null 2>&1 || { echo 'nginx is not installed on this node'; exit 0; }\n")
+	fmt.Fprintf(&b, "mkdir -p /etc/nginx/conf.d /etc/nginx/oneclickvirt\n")
+	fmt.Fprintf(&b, "cat > %s.new <<'OCV_EOF'\n%sOCV_EOF\n", nginxHTTPConf, httpConf)
+	fmt.Fprintf(&b, "cat > %s.new <<'OCV_EOF'\n%sOCV_EOF\n", nginxStreamConf, streamConf)
+	fmt.Fprintf(&b, "for f in %s %s; do\n", nginxHTTPConf, nginxStreamConf)
+	b.WriteString("  if [ -f \"$f\" ]; then cp -f \"$f\" \"$f.bak\"; else rm -f \"$f.bak\"; fi\n")
+	b.WriteString("  mv -f \"$f.new\" \"$f\"\n")
+	b.WriteString("done\n")
+	// stream块必须位于nginx.conf顶层，首次使用https转发时追加include
+	fmt.Fprintf(&b, "if grep -q '^stream' %s && ! grep -q '%s' /etc/nginx/nginx.conf; then\n", nginxStreamConf, nginxStreamConf)
+	fmt.Fprintf(&b, "  echo 'include %s;' >> /etc/nginx/nginx.conf\n", nginxStreamConf)
+	b.WriteString("fi\n")
+	b.WriteString("if ! out=$(nginx -t 2>&1); then\n")
+	fmt.Fprintf(&b, "  for f in %s %s; do\n", nginxHTTPConf, nginxStreamConf)
+	b.WriteString("    if [ -f \"$f.bak\" ]; then mv -f \"$f.bak\" \"$f\"; else : > \"$f\"; fi\n")
+	b.WriteString("  done\n")
+	b.WriteString("  echo \"$out\"\n")
+	b.WriteString("  exit 0\n")
+	b.WriteString("fi\n")
+	b.WriteString("if systemctl is-active --quiet nginx 2>/dev/null; then systemctl reload nginx; elif pgrep -x nginx >/dev/null; then nginx -s reload; else systemctl start nginx 2>/dev/null || nginx; fi\n")
+	fmt.Fprintf(&b, "echo %s\n", syncSuccessMarker)
+	return b.String()
+}
+
+// SyncProvider 按Provider上的全部域名绑定重新生成并下发反向代理配置，并更新已验证绑定的状态
+func (s *Service) SyncProvider(providerID uint) error {
+	lock, _ := syncLocks.LoadOrStore(providerID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	var bindings []providerModel.InstanceDomain
+	if err := global.APP_DB.Where("provider_id = ?", providerID).Find(&bindings).Error; err != nil {
+		return fmt.Errorf("获取域名绑定失败: %v", err)
+	}
+
+	instanceIDs := make([]uint, 0, len(bindings))
+	for _, b := range bindings {
+		instanceIDs = append(instanceIDs, b.InstanceID)
+	}
+	var instances []providerModel.Instance
+	if len(instanceIDs) > 0 {
+		if err := global.APP_DB.Select("id, private_ip").Where("id IN ?", instanceIDs).Find(&instances).Error; err != nil {
+			return fmt.Errorf("获取实例信息失败: %v", err)
+		}
+	}
+	privateIPs := make(map[uint]string, len(instances))
+	for _, inst := range instances {
+		privateIPs[inst.ID] = inst.PrivateIP
+	}
+
+	// 只下发已验证的绑定，未验证的绑定不会出现在节点配置中
+	entries := make([]proxyEntry, 0, len(bindings))
+	for _, b := range bindings {
+		if ip := privateIPs[b.InstanceID]; b.VerifiedAt != nil && ip != "" {
+			entries = append(entries, proxyEntry{
+				Domain:   b.Domain,
+				Protocol: b.Protocol,
+				Backend:  fmt.Sprintf("%s:%d", ip, b.TargetPort),
+			})
+		}
+	}
+
+	httpConf, streamConf := buildNginxConfig(entries)
+	syncErr := s.runSyncScript(providerID, buildSyncScript(httpConf, streamConf))
+
+	updates := map[string]interface{}{"status": providerModel.DomainStatusActive, "last_error": ""}
+	if syncErr != nil {
+		updates = map[string]interface{}{
+			"status":     providerModel.DomainStatusError,
+			"last_error": utils.TruncateString(syncErr.Error(), 250),
+		}
+	}
+	if err := global.APP_DB.Model(&providerModel.InstanceDomain{}).
+		Where("provider_id = ? AND verified_at IS NOT NULL", providerID).
+		Updates(updates).Error; err != nil {
+		global.APP_LOG.Warn("更新域名绑定状态失败", zap.Uint("providerId", providerID), zap.Error(err))
+	}
+
+	if syncErr != nil {
+		global.APP_LOG.Error("同步节点反向代理配置失败", zap.Uint("providerId", providerID), zap.Error(syncErr))
+		return syncErr
+	}
+	global.APP_LOG.Info("同步节点反向代理配置成功",
+		zap.Uint("providerId", providerID),
+		zap.Int("bindings", len(bindings)))
+	return nil
+}
+
+// runSyncScript 在Provider节点上执行同步脚本
+func (s *Service) runSyncScript(providerID uint, script string) error {
+	prov, _, err := (&provider2.ProviderApiService{}).GetProviderByID(providerID)
+	if err != nil {
+		return fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	output, err := prov.ExecuteSSHCommand(ctx, script)
+	if err != nil {
+		return fmt.Errorf("执行反向代理配置脚本失败: %v", err)
+	}
+	if !strings.Contains(output, syncSuccessMarker) {
+		output = strings.TrimSpace(output)
+		if output == "" {
+			return errors.New("反向代理配置失败")
+		}
+		return fmt.Errorf("反向代理配置失败: %s", output)
+	}
+	return nil
+}
@@ -0,0 +1,483 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/user"
+	"oneclickvirt/service/auth"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dnsVerifyPrefix DNS验证使用的TXT记录前缀
+const dnsVerifyPrefix = "_oneclickvirt-verify."
+
+// pendingBindingTTL 未验证的绑定保留时间，超时后由维护任务删除
+const pendingBindingTTL = 7 * 24 * time.Hour
+
+// 由接口层映射为对应的响应码
+var (
+	ErrInstanceNotFound = errors.New("实例不存在或无权限")
+	ErrDomainNotFound   = errors.New("域名绑定不存在")
+)
+
+// Service 域名绑定服务
+type Service struct{}
+
+// NewService 创建域名绑定服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// normalizeDomain 校验并规范化域名：转为小写、去掉末尾的点，不允许通配符和IP地址
+func normalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" || len(domain) > 253 {
+		return "", errors.New("域名长度无效")
+	}
+	if net.ParseIP(domain) != nil {
+		return "", errors.New("请填写域名而不是IP地址")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", errors.New("请填写完整的域名")
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", errors.New("域名格式无效")
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z') && !(ch >= '0' && ch <= '9') && ch != '-' {
+				return "", errors.New("域名格式无效")
+			}
+		}
+	}
+	if tld := labels[len(labels)-1]; strings.Trim(tld, "0123456789") == "" {
+		return "", errors.New("域名格式无效")
+	}
+	return domain, nil
+}
+
+// generateVerifyToken 生成验证令牌
+func generateVerifyToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// checkDomainLimit 检查用户等级的域名数量限制
+func (s *Service) checkDomainLimit(userID uint) error {
+	permissionService := auth.PermissionService{}
+	effective, err := permissionService.GetUserEffectivePermission(userID)
+	if err != nil {
+		return fmt.Errorf("获取用户权限失败: %v", err)
+	}
+
+	// 管理员不受限制
+	if effective.EffectiveType == "admin" {
+		return nil
+	}
+
+	levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[effective.EffectiveLevel]
+	if !exists || levelLimits.MaxDomains <= 0 {
+		return errors.New("当前用户等级不允许绑定域名")
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceDomain{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("统计域名数量失败: %v", err)
+	}
+	if int(count) >= levelLimits.MaxDomains {
+		return fmt.Errorf("域名绑定数量已达上限（%d个）", levelLimits.MaxDomains)
+	}
+	return nil
+}
+
+// getUserDomain 获取属于用户的域名绑定
+func (s *Service) getUserDomain(userID, domainID uint) (*providerModel.InstanceDomain, error) {
+	var binding providerModel.InstanceDomain
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", domainID, userID).First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("获取域名绑定失败: %v", err)
+	}
+	return &binding, nil
+}
+
+// ListUserDomains 获取用户的域名绑定，instanceID为0时返回全部
+func (s *Service) ListUserDomains(userID, instanceID uint) ([]providerModel.InstanceDomain, error) {
+	query := global.APP_DB.Where("user_id = ?", userID)
+	if instanceID > 0 {
+		query = query.Where("instance_id = ?", instanceID)
+	}
+	var bindings []providerModel.InstanceDomain
+	if err := query.Order("id DESC").Find(&bindings).Error; err != nil {
+		return nil, fmt.Errorf("获取域名绑定失败: %v", err)
+	}
+	return bindings, nil
+}
+
+// CreateDomain 为实例绑定域名
+// 用户已验证过的域名无需再次验证，直接生效；否则返回验证令牌，由用户添加DNS TXT记录完成验证。
+// 其他用户未验证的绑定不影响创建，只有其他用户已验证的域名不能绑定
+func (s *Service) CreateDomain(userID, instanceID uint, req user.CreateDomainRequest) (*providerModel.InstanceDomain, error) {
+	domain, err := normalizeDomain(req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstanceNotFound
+		}
+		return nil, fmt.Errorf("获取实例失败: %v", err)
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, errors.New("只有运行中或已停止的实例才能绑定域名")
+	}
+	if instance.PrivateIP == "" {
+		return nil, errors.New("实例没有内网IP地址，无法绑定域名")
+	}
+
+	if err := s.checkDomainLimit(userID); err != nil {
+		return nil, err
+	}
+
+	var existing []providerModel.InstanceDomain
+	if err := global.APP_DB.Where("domain = ?", domain).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("检查域名失败: %v", err)
+	}
+	var verifiedAt *time.Time
+	for _, e := range existing {
+		if e.UserID != userID {
+			if e.VerifiedAt != nil {
+				return nil, errors.New("该域名已被其他用户绑定")
+			}
+			continue
+		}
+		if e.Protocol == req.Protocol {
+			return nil, errors.New("该域名已绑定相同协议")
+		}
+		if e.VerifiedAt != nil {
+			verifiedAt = e.VerifiedAt
+		}
+	}
+
+	token, err := generateVerifyToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成验证令牌失败: %v", err)
+	}
+
+	binding := &providerModel.InstanceDomain{
+		UserID:       userID,
+		InstanceID:   instance.ID,
+		ProviderID:   instance.ProviderID,
+		Domain:       domain,
+		Protocol:     req.Protocol,
+		TargetPort:   req.TargetPort,
+		VerifyMethod: providerModel.DomainVerifyDNS,
+		VerifyToken:  token,
+		Status:       providerModel.DomainStatusPending,
+		VerifiedAt:   verifiedAt,
+	}
+	if err := global.APP_DB.Create(binding).Error; err != nil {
+		return nil, fmt.Errorf("创建域名绑定失败: %v", err)
+	}
+
+	// 已验证的域名直接生效，同步失败时 SyncProvider 已将绑定标记为error并记录原因
+	if verifiedAt != nil {
+		_ = s.SyncProvider(binding.ProviderID)
+		global.APP_DB.First(binding, binding.ID)
+	}
+
+	global.APP_LOG.Info("创建域名绑定",
+		zap.Uint("userId", userID),
+		zap.Uint("instanceId", instance.ID),
+		zap.String("domain", domain),
+		zap.String("protocol", binding.Protocol),
+		zap.Int("targetPort", binding.TargetPort))
+	return binding, nil
+}
+
+// VerifyDomain 验证域名所有权，通过后在节点上生效；配置失败的绑定会重新下发配置
+// 只接受DNS TXT记录验证：节点上的反向代理由所有租户共享，域名解析到节点只能说明域名指向该节点，
+// 不能说明当前用户控制该域名（如运营者自己的域名），能修改DNS记录才视为拥有域名
+func (s *Service) VerifyDomain(userID, domainID uint) (*providerModel.InstanceDomain, error) {
+	binding, err := s.getUserDomain(userID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch binding.Status {
+	case providerModel.DomainStatusActive:
+		return binding, nil
+	case providerModel.DomainStatusError:
+		if err := s.SyncProvider(binding.ProviderID); err != nil {
+			return nil, err
+		}
+		return s.getUserDomain(userID, domainID)
+	}
+
+	if claimed, err := s.verifiedByOtherUser(userID, binding.Domain); err != nil {
+		return nil, err
+	} else if claimed {
+		return nil, errors.New("该域名已被其他用户绑定")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// 停用HTTP验证前创建的绑定同样按TXT记录验证，验证令牌不变
+	if err := verifyDNS(ctx, binding.Domain, binding.VerifyToken); err != nil {
+		global.APP_DB.Model(binding).Update("last_error", utils.TruncateString(err.Error(), 250))
+		return nil, err
+	}
+
+	providerIDs, err := s.claimDomain(userID, binding.Domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, providerID := range providerIDs {
+		if err := s.SyncProvider(providerID); err != nil && providerID == binding.ProviderID {
+			return nil, err
+		}
+	}
+
+	global.APP_LOG.Info("域名所有权验证通过",
+		zap.Uint("userId", userID),
+		zap.String("domain", binding.Domain),
+		zap.String("method", binding.VerifyMethod))
+	return s.getUserDomain(userID, domainID)
+}
+
+// verifiedByOtherUser 域名是否已被其他用户验证
+func (s *Service) verifiedByOtherUser(userID uint, domain string) (bool, error) {
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceDomain{}).
+		Where("domain = ? AND user_id <> ? AND verified_at IS NOT NULL", domain, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("检查域名失败: %v", err)
+	}
+	return count > 0, nil
+}
+
+// claimDomain 验证通过后将用户在该域名上的绑定标记为已验证，并删除其他用户未验证的绑定
+// 锁定该域名的全部绑定后再检查，两个用户同时验证时只有一个能成功；返回需要同步配置的Provider
+func (s *Service) claimDomain(userID uint, domain string) ([]uint, error) {
+	var providerIDs []uint
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var bindings []providerModel.InstanceDomain
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("domain = ?", domain).Find(&bindings).Error; err != nil {
+			return fmt.Errorf("检查域名失败: %v", err)
+		}
+
+		seen := make(map[uint]bool)
+		var staleIDs []uint
+		for _, b := range bindings {
+			if b.UserID != userID {
+				if b.VerifiedAt != nil {
+					return errors.New("该域名已被其他用户绑定")
+				}
+				staleIDs = append(staleIDs, b.ID)
+			}
+			if !seen[b.ProviderID] {
+				seen[b.ProviderID] = true
+				providerIDs = append(providerIDs, b.ProviderID)
+			}
+		}
+
+		// 同一用户同一域名的其他绑定一并视为已验证
+		if err := tx.Model(&providerModel.InstanceDomain{}).
+			Where("user_id = ? AND domain = ? AND verified_at IS NULL", userID, domain).
+			Updates(map[string]interface{}{"verified_at": time.Now(), "last_error": ""}).Error; err != nil {
+			return fmt.Errorf("更新域名验证状态失败: %v", err)
+		}
+		if len(staleIDs) > 0 {
+			if err := tx.Where("id IN ?", staleIDs).Delete(&providerModel.InstanceDomain{}).Error; err != nil {
+				return fmt.Errorf("删除其他用户的未验证绑定失败: %v", err)
+			}
+			global.APP_LOG.Info("域名验证通过，删除其他用户的未验证绑定",
+				zap.String("domain", domain),
+				zap.Int("count", len(staleIDs)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return providerIDs, nil
+}
+
+// CleanupExpiredPending 删除超过保留时间仍未验证的绑定，避免长期占用域名和节点上的验证配置
+func (s *Service) CleanupExpiredPending() {
+	var bindings []providerModel.InstanceDomain
+	if err := global.APP_DB.
+		Where("verified_at IS NULL AND created_at < ?", time.Now().Add(-pendingBindingTTL)).
+		Find(&bindings).Error; err != nil {
+		global.APP_LOG.Error("查询过期的未验证域名绑定失败", zap.Error(err))
+		return
+	}
+	for i := range bindings {
+		if err := s.deleteBinding(&bindings[i]); err != nil {
+			global.APP_LOG.Warn("删除过期的未验证域名绑定失败",
+				zap.Uint("domainId", bindings[i].ID),
+				zap.String("domain", bindings[i].Domain),
+				zap.Error(err))
+		}
+	}
+	if len(bindings) > 0 {
+		global.APP_LOG.Info("已清理过期的未验证域名绑定", zap.Int("count", len(bindings)))
+	}
+}
+
+// verifyDNS 检查TXT记录是否包含验证令牌
+func verifyDNS(ctx context.Context, domain, token string) error {
+	records, err := net.DefaultResolver.LookupTXT(ctx, dnsVerifyPrefix+domain)
+	if err != nil {
+		return fmt.Errorf("查询TXT记录 %s%s 失败，请确认记录已添加并生效", dnsVerifyPrefix, domain)
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == token {
+			return nil
+		}
+	}
+	return fmt.Errorf("TXT记录 %s%s 中未找到验证令牌", dnsVerifyPrefix, domain)
+}
+
+// DeleteDomain 删除用户的域名绑定
+func (s *Service) DeleteDomain(userID, domainID uint) error {
+	binding, err := s.getUserDomain(userID, domainID)
+	if err != nil {
+		return err
+	}
+	return s.deleteBinding(binding)
+}
+
+// deleteBinding 删除绑定并同步节点配置，同步失败只记录日志
+func (s *Service) deleteBinding(binding *providerModel.InstanceDomain) error {
+	if err := global.APP_DB.Delete(binding).Error; err != nil {
+		return fmt.Errorf("删除域名绑定失败: %v", err)
+	}
+	// 停用HTTP验证前创建的未验证绑定在节点上仍有验证令牌配置，删除时一并同步清除
+	if binding.VerifiedAt != nil || binding.VerifyMethod == providerModel.DomainVerifyHTTP {
+		if err := s.SyncProvider(binding.ProviderID); err != nil {
+			global.APP_LOG.Warn("删除域名后同步节点配置失败",
+				zap.Uint("providerId", binding.ProviderID),
+				zap.String("domain", binding.Domain),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// ListDomains 管理员分页查询域名绑定
+func (s *Service) ListDomains(req adminModel.DomainListRequest) ([]providerModel.InstanceDomain, int64, error) {
+	query := global.APP_DB.Model(&providerModel.InstanceDomain{})
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		query = query.Where("domain LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计域名绑定数量失败: %v", err)
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	var bindings []providerModel.InstanceDomain
+	if err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&bindings).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取域名绑定失败: %v", err)
+	}
+	return bindings, total, nil
+}
+
+// DeleteDomainAdmin 管理员删除域名绑定
+func (s *Service) DeleteDomainAdmin(domainID uint) error {
+	var binding providerModel.InstanceDomain
+	if err := global.APP_DB.First(&binding, domainID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDomainNotFound
+		}
+		return fmt.Errorf("获取域名绑定失败: %v", err)
+	}
+	return s.deleteBinding(&binding)
+}
+
+// HasInstanceDomains 实例是否绑定了域名
+func HasInstanceDomains(instanceID uint) bool {
+	var count int64
+	global.APP_DB.Model(&providerModel.InstanceDomain{}).Where("instance_id = ?", instanceID).Count(&count)
+	return count > 0
+}
+
+// DeleteByInstanceInTx 删除实例的全部域名绑定，节点配置由调用方在事务提交后同步
+func DeleteByInstanceInTx(tx *gorm.DB, instanceID uint) error {
+	if err := tx.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceDomain{}).Error; err != nil {
+		return fmt.Errorf("删除实例域名绑定失败: %v", err)
+	}
+	return nil
+}
+
+// TransferInTx 将域名绑定转移到新的实例记录（重置会创建新的实例记录）
+func TransferInTx(tx *gorm.DB, oldInstanceID, newInstanceID uint) error {
+	if err := tx.Model(&providerModel.InstanceDomain{}).
+		Where("instance_id = ?", oldInstanceID).
+		Update("instance_id", newInstanceID).Error; err != nil {
+		return fmt.Errorf("转移实例域名绑定失败: %v", err)
+	}
+	return nil
+}
+
+// MoveToProvider 实例迁移后将域名绑定转移到目标Provider，并同步源和目标节点的配置
+func (s *Service) MoveToProvider(instanceID, sourceProviderID, targetProviderID uint) {
+	if err := global.APP_DB.Model(&providerModel.InstanceDomain{}).
+		Where("instance_id = ?", instanceID).
+		Update("provider_id", targetProviderID).Error; err != nil {
+		global.APP_LOG.Error("转移实例域名绑定失败", zap.Uint("instanceId", instanceID), zap.Error(err))
+		return
+	}
+	for _, providerID := range []uint{sourceProviderID, targetProviderID} {
+		if err := s.SyncProvider(providerID); err != nil {
+			global.APP_LOG.Warn("迁移后同步节点反向代理配置失败",
+				zap.Uint("instanceId", instanceID),
+				zap.Uint("providerId", providerID),
+				zap.Error(err))
+		}
+	}
+}
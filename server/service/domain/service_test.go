@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	valid := map[string]string{
+		"Example.COM":         "example.com",
+		" app.example.com. ":  "app.example.com",
+		"a-b.example.co.uk":   "a-b.example.co.uk",
+		"xn--fiq228c.example": "xn--fiq228c.example",
+	}
+	for input, want := range valid {
+		got, err := normalizeDomain(input)
+		if err != nil {
+			t.Errorf("normalizeDomain(%q) 返回错误: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizeDomain(%q) = %q, 期望 %q", input, got, want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"localhost",
+		"*.example.com",
+		"-bad.example.com",
+		"bad-.example.com",
+		"a..example.com",
+		"under_score.example.com",
+		"1.2.3.4",
+		"example.123",
+		"exa mple.com",
+		strings.Repeat("a", 64) + ".com",
+	}
+	for _, input := range invalid {
+		if _, err := normalizeDomain(input); err == nil {
+			t.Errorf("normalizeDomain(%q) 应返回错误", input)
+		}
+	}
+}
+
+func TestBuildNginxConfig(t *testing.T) {
+	httpConf, streamConf := buildNginxConfig([]proxyEntry{
+		{Domain: "web.example.com", Protocol: "http", Backend: "10.0.0.2:8080"},
+		{Domain: "tls.example.com", Protocol: "https", Backend: "10.0.0.3:443"},
+		{Domain: "new.example.com", Protocol: "http"},
+	})
+
+	for _, want := range []string{
+		"server_name web.example.com;",
+		"proxy_pass http://10.0.0.2:8080;",
+	} {
+		if !strings.Contains(httpConf, want) {
+			t.Errorf("http配置缺少 %q:\n%s", want, httpConf)
+		}
+	}
+	if strings.Contains(httpConf, "tls.example.com") {
+		t.Errorf("已验证的https绑定不应出现在http配置中:\n%s", httpConf)
+	}
+	if strings.Contains(httpConf, "new.example.com") || strings.Contains(httpConf, "oneclickvirt-verify") {
+		t.Errorf("没有转发目标的绑定不应出现在http配置中:\n%s", httpConf)
+	}
+
+	if !strings.Contains(streamConf, "tls.example.com 10.0.0.3:443;") {
+		t.Errorf("stream配置缺少SNI映射:\n%s", streamConf)
+	}
+	if strings.Contains(streamConf, "new.example.com") {
+		t.Errorf("未验证的绑定不应出现在stream配置中:\n%s", streamConf)
+	}
+
+	_, emptyStream := buildNginxConfig(nil)
+	if strings.Contains(emptyStream, "stream {") {
+		t.Errorf("没有https绑定时不应生成stream块:\n%s", emptyStream)
+	}
+}
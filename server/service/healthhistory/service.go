@@ -0,0 +1,182 @@
+package healthhistory
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+)
+
+// defaultRetentionDays 未配置时健康检查历史的保留天数
+const defaultRetentionDays = 90
+
+// Service Provider健康检查历史与可用率统计服务
+type Service struct{}
+
+// NewService 创建健康检查历史服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// Record 保存一次健康检查结果，写入失败只记录日志，不影响健康检查本身
+func Record(providerID uint, status, sshStatus, apiStatus string, duration time.Duration, checkErr error) {
+	if global.APP_DB == nil {
+		return
+	}
+	record := providerModel.ProviderHealthRecord{
+		ProviderID: providerID,
+		CheckedAt:  time.Now(),
+		Status:     status,
+		SSHStatus:  sshStatus,
+		APIStatus:  apiStatus,
+		DurationMs: duration.Milliseconds(),
+	}
+	if checkErr != nil {
+		msg := []rune(checkErr.Error())
+		if len(msg) > 255 {
+			msg = msg[:255]
+		}
+		record.Error = string(msg)
+	}
+	if err := global.APP_DB.Create(&record).Error; err != nil {
+		global.APP_LOG.Warn("保存健康检查历史失败", zap.Uint("providerId", providerID), zap.Error(err))
+	}
+}
+
+// loadRecords 获取窗口内的检查记录，同时带上窗口开始前的最后一条记录以确定窗口起点的状态
+func loadRecords(providerID uint, from, to time.Time) ([]providerModel.ProviderHealthRecord, error) {
+	var records []providerModel.ProviderHealthRecord
+	var before providerModel.ProviderHealthRecord
+	err := global.APP_DB.Where("provider_id = ? AND checked_at < ? AND checked_at >= ?", providerID, from, from.Add(-maxSampleGap)).
+		Order("checked_at DESC").Limit(1).Find(&before).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取健康检查历史失败: %v", err)
+	}
+	if before.ID != 0 {
+		records = append(records, before)
+	}
+
+	var inWindow []providerModel.ProviderHealthRecord
+	if err := global.APP_DB.Select("id, provider_id, checked_at, status").
+		Where("provider_id = ? AND checked_at >= ? AND checked_at <= ?", providerID, from, to).
+		Order("checked_at ASC").Find(&inWindow).Error; err != nil {
+		return nil, fmt.Errorf("获取健康检查历史失败: %v", err)
+	}
+	return append(records, inWindow...), nil
+}
+
+// UptimeWindow 一个统计窗口的可用率
+type UptimeWindow struct {
+	Window string `json:"window"` // 窗口名称：24h, 7d, 30d
+	Availability
+}
+
+// ProviderUptime Provider在各统计窗口的可用率
+type ProviderUptime struct {
+	ProviderID   uint           `json:"providerId"`
+	ProviderName string         `json:"providerName"`
+	Status       string         `json:"status"` // 当前状态
+	Windows      []UptimeWindow `json:"windows"`
+}
+
+// uptimeWindows 默认统计窗口
+var uptimeWindows = []struct {
+	Name     string
+	Duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// GetUptime 计算Provider最近24小时、7天、30天的可用率
+func (s *Service) GetUptime(p *providerModel.Provider) (*ProviderUptime, error) {
+	now := time.Now()
+	longest := uptimeWindows[len(uptimeWindows)-1].Duration
+	records, err := loadRecords(p.ID, now.Add(-longest), now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProviderUptime{
+		ProviderID:   p.ID,
+		ProviderName: p.Name,
+		Status:       p.Status,
+		Windows:      make([]UptimeWindow, 0, len(uptimeWindows)),
+	}
+	for _, w := range uptimeWindows {
+		availability, _ := Compute(records, now.Add(-w.Duration), now)
+		result.Windows = append(result.Windows, UptimeWindow{Window: w.Name, Availability: availability})
+	}
+	return result, nil
+}
+
+// GetAllUptime 计算所有Provider的可用率
+func (s *Service) GetAllUptime() ([]ProviderUptime, error) {
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Select("id, name, status").Order("id ASC").Find(&providers).Error; err != nil {
+		return nil, fmt.Errorf("获取Provider列表失败: %v", err)
+	}
+
+	result := make([]ProviderUptime, 0, len(providers))
+	for i := range providers {
+		uptime, err := s.GetUptime(&providers[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *uptime)
+	}
+	return result, nil
+}
+
+// GetOutages 获取Provider最近若干天的离线时段，按开始时间倒序
+func (s *Service) GetOutages(providerID uint, days int) ([]Outage, error) {
+	now := time.Now()
+	records, err := loadRecords(providerID, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return nil, err
+	}
+	_, outages := Compute(records, now.AddDate(0, 0, -days), now)
+	for i, j := 0, len(outages)-1; i < j; i, j = i+1, j-1 {
+		outages[i], outages[j] = outages[j], outages[i]
+	}
+	return outages, nil
+}
+
+// ListRecords 分页获取Provider的原始健康检查记录，按时间倒序
+func (s *Service) ListRecords(providerID uint, page, pageSize int) ([]providerModel.ProviderHealthRecord, int64, error) {
+	query := global.APP_DB.Model(&providerModel.ProviderHealthRecord{}).Where("provider_id = ?", providerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计健康检查历史失败: %v", err)
+	}
+
+	var records []providerModel.ProviderHealthRecord
+	if err := query.Order("checked_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取健康检查历史失败: %v", err)
+	}
+	return records, total, nil
+}
+
+// Cleanup 删除超过保留期的健康检查历史
+func (s *Service) Cleanup() {
+	days := global.APP_CONFIG.Health.HistoryRetentionDays
+	if days <= 0 {
+		days = defaultRetentionDays
+	}
+	result := global.APP_DB.Where("checked_at < ?", time.Now().AddDate(0, 0, -days)).
+		Delete(&providerModel.ProviderHealthRecord{})
+	if result.Error != nil {
+		global.APP_LOG.Error("清理健康检查历史失败", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		global.APP_LOG.Info("已清理过期的健康检查历史",
+			zap.Int64("count", result.RowsAffected),
+			zap.Int("retentionDays", days))
+	}
+}
@@ -0,0 +1,107 @@
+package healthhistory
+
+import (
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+)
+
+// maxSampleGap 一条检查记录最多代表的时长
+// 健康检查每3~10分钟执行一次，超过该时长没有记录的时段视为未监控，不计入可用率
+const maxSampleGap = 15 * time.Minute
+
+// Availability 统计窗口内各状态的时长（秒）和可用率
+// 可用率 = (正常 + 部分可用) / 已监控时长，未监控时段不计入；没有任何监控数据时 UptimePercent 为空
+type Availability struct {
+	UpSeconds       int64    `json:"upSeconds"`       // 正常（active）
+	DegradedSeconds int64    `json:"degradedSeconds"` // 部分可用（partial）
+	DownSeconds     int64    `json:"downSeconds"`     // 离线（inactive）
+	UnknownSeconds  int64    `json:"unknownSeconds"`  // 未监控
+	UptimePercent   *float64 `json:"uptimePercent"`   // 可用率（%）
+	OutageCount     int      `json:"outageCount"`     // 离线次数
+}
+
+// Outage 一段连续的离线时段
+type Outage struct {
+	Start           time.Time  `json:"start"`
+	End             *time.Time `json:"end"`             // 为空表示仍处于离线状态
+	DurationSeconds int64      `json:"durationSeconds"` // 持续时长（秒），未结束时计算到当前
+}
+
+// Compute 根据按时间升序排列的检查记录计算 [from, to] 窗口内的可用率和离线时段
+// 每条记录的状态持续到下一条记录或 maxSampleGap 之后（取较早者），离线时段遇到非离线记录或监控中断时结束
+func Compute(records []providerModel.ProviderHealthRecord, from, to time.Time) (Availability, []Outage) {
+	var result Availability
+	var outages []Outage
+	var current *Outage
+	covered := time.Duration(0)
+
+	closeOutage := func(end time.Time, ongoing bool) {
+		if current == nil {
+			return
+		}
+		current.DurationSeconds = int64(end.Sub(current.Start).Seconds())
+		if !ongoing {
+			e := end
+			current.End = &e
+		}
+		outages = append(outages, *current)
+		current = nil
+	}
+
+	for i, r := range records {
+		start := r.CheckedAt
+		end := start.Add(maxSampleGap)
+		if i+1 < len(records) && records[i+1].CheckedAt.Before(end) {
+			end = records[i+1].CheckedAt
+		}
+		if end.After(to) {
+			end = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		seconds := int64(end.Sub(start).Seconds())
+		covered += end.Sub(start)
+		switch r.Status {
+		case "active":
+			result.UpSeconds += seconds
+		case "partial":
+			result.DegradedSeconds += seconds
+		case "inactive":
+			result.DownSeconds += seconds
+		default:
+			result.UnknownSeconds += seconds
+		}
+
+		if r.Status == "inactive" {
+			if current == nil {
+				current = &Outage{Start: start}
+			}
+		} else {
+			closeOutage(start, false)
+		}
+
+		// 下一条记录与当前段不连续（监控中断）时结束当前离线时段
+		nextContiguous := i+1 < len(records) && !records[i+1].CheckedAt.After(end)
+		if current != nil && !nextContiguous {
+			// 最后一条记录仍在覆盖范围内时视为仍在离线
+			ongoing := i+1 == len(records) && end.Equal(to) && r.CheckedAt.Add(maxSampleGap).After(to)
+			closeOutage(end, ongoing)
+		}
+	}
+
+	if total := to.Sub(from); total > covered {
+		result.UnknownSeconds += int64((total - covered).Seconds())
+	}
+	if monitored := result.UpSeconds + result.DegradedSeconds + result.DownSeconds; monitored > 0 {
+		percent := float64(result.UpSeconds+result.DegradedSeconds) * 100 / float64(monitored)
+		result.UptimePercent = &percent
+	}
+	result.OutageCount = len(outages)
+	return result, outages
+}
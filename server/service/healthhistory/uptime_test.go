@@ -0,0 +1,67 @@
+package healthhistory
+
+import (
+	"testing"
+	"time"
+
+	providerModel "oneclickvirt/model/provider"
+)
+
+// TestCompute 测试可用率、离线时段以及监控中断的处理
+func TestCompute(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return from.Add(time.Duration(minutes) * time.Minute) }
+	rec := func(minutes int, status string) providerModel.ProviderHealthRecord {
+		return providerModel.ProviderHealthRecord{CheckedAt: at(minutes), Status: status}
+	}
+
+	// 0~20 正常，20~30 离线，30~45 部分可用（一条记录最多代表15分钟），45~70 未监控，
+	// 70~85 离线后监控中断，85~100 未监控
+	records := []providerModel.ProviderHealthRecord{
+		rec(0, "active"),
+		rec(10, "active"),
+		rec(20, "inactive"),
+		rec(25, "inactive"),
+		rec(30, "partial"),
+		rec(70, "inactive"),
+	}
+	availability, outages := Compute(records, from, at(100))
+
+	if availability.UpSeconds != 20*60 {
+		t.Errorf("UpSeconds = %d, want %d", availability.UpSeconds, 20*60)
+	}
+	if availability.DegradedSeconds != 15*60 {
+		t.Errorf("DegradedSeconds = %d, want %d", availability.DegradedSeconds, 15*60)
+	}
+	if availability.DownSeconds != 25*60 {
+		t.Errorf("DownSeconds = %d, want %d", availability.DownSeconds, 25*60)
+	}
+	if availability.UnknownSeconds != 40*60 {
+		t.Errorf("UnknownSeconds = %d, want %d", availability.UnknownSeconds, 40*60)
+	}
+	if availability.UptimePercent == nil || *availability.UptimePercent < 58.3 || *availability.UptimePercent > 58.4 {
+		t.Errorf("UptimePercent = %v, want ~58.33", availability.UptimePercent)
+	}
+
+	if len(outages) != 2 || availability.OutageCount != 2 {
+		t.Fatalf("outages = %d, want 2", len(outages))
+	}
+	if !outages[0].Start.Equal(at(20)) || outages[0].End == nil || !outages[0].End.Equal(at(30)) {
+		t.Errorf("第一次离线 = %v ~ %v, want %v ~ %v", outages[0].Start, outages[0].End, at(20), at(30))
+	}
+	if !outages[1].Start.Equal(at(70)) || outages[1].End == nil || !outages[1].End.Equal(at(85)) {
+		t.Errorf("第二次离线 = %v ~ %v, want %v ~ %v", outages[1].Start, outages[1].End, at(70), at(85))
+	}
+
+	// 仍处于离线状态时结束时间为空
+	_, outages = Compute(records, from, at(80))
+	if len(outages) != 2 || outages[1].End != nil || outages[1].DurationSeconds != 10*60 {
+		t.Errorf("进行中的离线时段计算错误: %+v", outages)
+	}
+
+	// 没有任何监控数据时可用率为空
+	availability, _ = Compute(nil, from, at(60))
+	if availability.UptimePercent != nil || availability.UnknownSeconds != 60*60 {
+		t.Errorf("无数据时 = %+v, want UptimePercent=nil UnknownSeconds=3600", availability)
+	}
+}
@@ -348,3 +348,33 @@ func (s *ImageService) GetFilteredImages(providerID uint, instanceType string) (
 	// 根据Provider类型、实例类型和架构过滤镜像
 	return s.GetAvailableImages(provider.Type, instanceType, architecture)
 }
+
+// ValidateReinstallImage 校验重装实例使用的目标镜像
+// 与创建实例使用相同的兼容性、最低硬件要求和user-data校验，另外要求镜像的实例类型与实例一致
+func (s *ImageService) ValidateReinstallImage(imageID uint, instance *providerModel.Instance, provider *providerModel.Provider) (*system.SystemImage, error) {
+	var systemImage system.SystemImage
+	if err := global.APP_DB.First(&systemImage, imageID).Error; err != nil {
+		return nil, fmt.Errorf("镜像不存在")
+	}
+
+	if systemImage.Status != "active" {
+		return nil, fmt.Errorf("镜像 %s 未启用", systemImage.Name)
+	}
+	if err := s.ValidateProviderCompatibility(provider, &systemImage); err != nil {
+		return nil, err
+	}
+	if systemImage.InstanceType != instance.InstanceType {
+		return nil, fmt.Errorf("镜像 %s 的实例类型 %s 与实例类型 %s 不一致", systemImage.Name, systemImage.InstanceType, instance.InstanceType)
+	}
+	if err := s.ValidateMinimumRequirements(&systemImage, instance.Memory, instance.Disk, provider); err != nil {
+		return nil, err
+	}
+	// 重装后会重新应用实例创建时的user-data
+	if instance.UserData != "" {
+		if err := s.ValidateUserData(instance.UserData, provider, &systemImage); err != nil {
+			return nil, fmt.Errorf("实例的user-data不适用于镜像 %s: %v", systemImage.Name, err)
+		}
+	}
+
+	return &systemImage, nil
+}
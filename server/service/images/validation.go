@@ -0,0 +1,112 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/system"
+	"oneclickvirt/utils"
+
+	"go.uber.org/zap"
+)
+
+// ValidateProviderCompatibility 验证Provider和Image的兼容性
+func (s *ImageService) ValidateProviderCompatibility(provider *providerModel.Provider, image *system.SystemImage) error {
+	// 验证Provider类型是否支持该镜像
+	supportedProviders := strings.Split(image.ProviderType, ",")
+	providerSupported := false
+	for _, supportedType := range supportedProviders {
+		if strings.TrimSpace(supportedType) == provider.Type {
+			providerSupported = true
+			break
+		}
+	}
+
+	if !providerSupported {
+		return fmt.Errorf("所选镜像不支持Provider类型 %s，支持的类型: %s", provider.Type, image.ProviderType)
+	}
+
+	// 验证架构兼容性
+	if provider.Architecture != "" && image.Architecture != "" && provider.Architecture != image.Architecture {
+		return fmt.Errorf("架构不匹配：Provider架构为 %s，镜像架构为 %s", provider.Architecture, image.Architecture)
+	}
+
+	// 验证实例类型支持
+	if image.InstanceType == "vm" && !provider.VirtualMachineEnabled {
+		return errors.New("该Provider不支持虚拟机实例")
+	}
+
+	if image.InstanceType == "container" && !provider.ContainerEnabled {
+		return errors.New("该Provider不支持容器实例")
+	}
+
+	return nil
+}
+
+// ValidateUserData 验证用户提供的user-data
+// Docker容器以启动脚本方式执行，不支持cloud-config；Proxmox LXC容器不运行cloud-init
+func (s *ImageService) ValidateUserData(userData string, provider *providerModel.Provider, image *system.SystemImage) error {
+	if err := utils.ValidateUserData(userData); err != nil {
+		return err
+	}
+
+	switch provider.Type {
+	case "docker":
+		if strings.HasPrefix(strings.TrimSpace(userData), "#cloud-config") {
+			return errors.New("Docker实例不支持cloud-config格式，请提供shell脚本")
+		}
+	case "proxmox":
+		if image.InstanceType != "vm" {
+			return errors.New("Proxmox容器不支持user-data，仅虚拟机可用")
+		}
+	}
+
+	return nil
+}
+
+// ValidateMinimumRequirements 验证实例的最低硬件要求（统一验证）
+func (s *ImageService) ValidateMinimumRequirements(image *system.SystemImage, memoryMB, diskMB int64, provider *providerModel.Provider) error {
+	if image == nil {
+		return fmt.Errorf("镜像信息不能为空")
+	}
+
+	// 使用镜像自身的最低硬件要求
+	minMemoryMB := int64(image.MinMemoryMB)
+	minDiskMB := int64(image.MinDiskMB)
+
+	// 验证镜像是否设置了最低要求
+	if minMemoryMB <= 0 || minDiskMB <= 0 {
+		return fmt.Errorf("镜像未设置最低硬件要求，请联系管理员")
+	}
+
+	instanceTypeDesc := "虚拟机"
+	if image.InstanceType == "container" {
+		instanceTypeDesc = "容器"
+	}
+
+	// 验证内存要求
+	if memoryMB < minMemoryMB {
+		return fmt.Errorf("%s镜像 %s 最少需要%dMB内存，当前选择%dMB不足",
+			instanceTypeDesc, image.Name, minMemoryMB, memoryMB)
+	}
+
+	// 验证磁盘要求
+	if diskMB < minDiskMB {
+		return fmt.Errorf("%s镜像 %s 最少需要%dMB硬盘，当前选择%dMB不足",
+			instanceTypeDesc, image.Name, minDiskMB, diskMB)
+	}
+
+	global.APP_LOG.Info("实例最低硬件要求验证通过",
+		zap.String("imageName", image.Name),
+		zap.String("instanceType", image.InstanceType),
+		zap.String("providerType", provider.Type),
+		zap.Int64("requiredMemoryMB", minMemoryMB),
+		zap.Int64("requiredDiskMB", minDiskMB),
+		zap.Int64("selectedMemoryMB", memoryMB),
+		zap.Int64("selectedDiskMB", diskMB))
+
+	return nil
+}
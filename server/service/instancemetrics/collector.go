@@ -0,0 +1,198 @@
+package instancemetrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	provider2 "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// collectConcurrency 同时采集的Provider数量
+	collectConcurrency = 5
+	// collectTimeout 单个Provider的采集超时
+	collectTimeout = 60 * time.Second
+)
+
+// cpuSample 上一次采集到的累计CPU时间，用于计算只提供累计值的平台的CPU使用率
+type cpuSample struct {
+	usageNs uint64
+	at      time.Time
+}
+
+// Collector 实例资源使用采集器
+// 按Provider批量读取所有运行中实例的资源使用情况，一个Provider只发起一次请求
+type Collector struct {
+	mu      sync.Mutex
+	lastCPU map[uint]cpuSample // instanceID -> 上一次的累计CPU时间
+}
+
+// NewCollector 创建采集器，采集器保存CPU累计值，应在整个调度周期内复用
+func NewCollector() *Collector {
+	return &Collector{lastCPU: make(map[uint]cpuSample)}
+}
+
+// Collect 采集所有可用Provider上运行中实例的资源使用情况并写入原始采样
+func (c *Collector) Collect() {
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Select("id, name, type").
+		Where("status IN ? AND is_frozen = ?", []string{"active", "partial"}, false).
+		Find(&providers).Error; err != nil {
+		global.APP_LOG.Error("获取Provider列表失败", zap.Error(err))
+		return
+	}
+
+	semaphore := make(chan struct{}, collectConcurrency)
+	var wg sync.WaitGroup
+	for i := range providers {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(p providerModel.Provider) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+				if r := recover(); r != nil {
+					global.APP_LOG.Error("采集实例资源使用情况panic",
+						zap.Uint("providerId", p.ID),
+						zap.Any("panic", r))
+				}
+			}()
+			c.collectProvider(p)
+		}(providers[i])
+	}
+	wg.Wait()
+	c.pruneCPUSamples()
+}
+
+// collectProvider 采集单个Provider，Provider未连接或不支持资源监控时跳过
+func (c *Collector) collectProvider(p providerModel.Provider) {
+	prov, exists := provider2.GetProviderService().GetProviderByID(p.ID)
+	if !exists || !prov.IsConnected() {
+		return
+	}
+	metricsProvider, ok := prov.(provider.MetricsProvider)
+	if !ok {
+		return
+	}
+
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Select("id, name, user_id, cpu, memory, disk").
+		Where("provider_id = ? AND status = ?", p.ID, "running").
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Error("获取运行中实例失败", zap.Uint("providerId", p.ID), zap.Error(err))
+		return
+	}
+	if len(instances) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	usages, err := metricsProvider.GetInstancesUsage(ctx)
+	if err != nil {
+		global.APP_LOG.Warn("获取实例资源使用情况失败",
+			zap.Uint("providerId", p.ID),
+			zap.String("provider", p.Name),
+			zap.Error(err))
+		return
+	}
+
+	byName := make(map[string]provider.InstanceUsage, len(usages)*2)
+	for _, u := range usages {
+		byName[u.Name] = u
+		if u.ID != "" {
+			byName[u.ID] = u
+		}
+	}
+
+	now := time.Now()
+	records := make([]monitoring.InstanceResourceMetric, 0, len(instances))
+	for _, inst := range instances {
+		usage, ok := byName[inst.Name]
+		if !ok {
+			continue
+		}
+		cpuPercent, ok := c.cpuPercent(inst, usage, now)
+		if !ok {
+			continue
+		}
+		record := monitoring.InstanceResourceMetric{
+			InstanceID:      inst.ID,
+			ProviderID:      p.ID,
+			UserID:          inst.UserID,
+			Resolution:      monitoring.MetricResolutionRaw,
+			Timestamp:       now,
+			CPUPercent:      cpuPercent,
+			CPUPercentMax:   cpuPercent,
+			MemoryUsedMB:    usage.MemoryUsedMB,
+			MemoryUsedMaxMB: usage.MemoryUsedMB,
+			MemoryTotalMB:   usage.MemoryTotalMB,
+			DiskUsedMB:      usage.DiskUsedMB,
+			DiskTotalMB:     usage.DiskTotalMB,
+			Samples:         1,
+		}
+		// 未设置上限的容器平台返回0，使用实例分配的规格
+		if record.MemoryTotalMB <= 0 {
+			record.MemoryTotalMB = inst.Memory
+		}
+		if record.DiskTotalMB <= 0 {
+			record.DiskTotalMB = inst.Disk
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return
+	}
+	if err := global.APP_DB.CreateInBatches(&records, 200).Error; err != nil {
+		global.APP_LOG.Error("保存实例资源使用情况失败", zap.Uint("providerId", p.ID), zap.Error(err))
+	}
+}
+
+// cpuPercent 计算按分配核心数归一化的CPU使用率（0~100）
+// 平台只提供累计CPU时间时根据与上次采样的差值计算，首次采样或计数器重置时返回false，本次不记录
+func (c *Collector) cpuPercent(inst providerModel.Instance, usage provider.InstanceUsage, now time.Time) (float64, bool) {
+	percent := usage.CPUPercent
+	if usage.CPUTimeNs > 0 {
+		c.mu.Lock()
+		prev, exists := c.lastCPU[inst.ID]
+		c.lastCPU[inst.ID] = cpuSample{usageNs: usage.CPUTimeNs, at: now}
+		c.mu.Unlock()
+
+		elapsed := now.Sub(prev.at)
+		if !exists || usage.CPUTimeNs < prev.usageNs || elapsed <= 0 {
+			return 0, false
+		}
+		percent = float64(usage.CPUTimeNs-prev.usageNs) / float64(elapsed.Nanoseconds()) * 100
+	}
+
+	cores := inst.CPU
+	if cores <= 0 {
+		cores = 1
+	}
+	percent /= float64(cores)
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	return percent, true
+}
+
+// pruneCPUSamples 清理长时间未更新的CPU累计值（实例已停止或删除）
+func (c *Collector) pruneCPUSamples() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, sample := range c.lastCPU {
+		if time.Since(sample.at) > time.Hour {
+			delete(c.lastCPU, id)
+		}
+	}
+}
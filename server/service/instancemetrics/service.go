@@ -0,0 +1,298 @@
+package instancemetrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/monitoring"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultCollectInterval 未配置时的采集间隔
+	defaultCollectInterval = 5 * time.Minute
+	// defaultRawRetentionDays 未配置时原始采样的保留天数
+	defaultRawRetentionDays = 3
+	// defaultHourlyRetentionDays 未配置时小时汇总的保留天数
+	defaultHourlyRetentionDays = 90
+)
+
+// queryRanges 支持的查询范围及使用的数据精度，超过原始采样保留期的范围使用小时汇总
+var queryRanges = map[string]struct {
+	Duration   time.Duration
+	Resolution string
+}{
+	"1h":  {time.Hour, monitoring.MetricResolutionRaw},
+	"6h":  {6 * time.Hour, monitoring.MetricResolutionRaw},
+	"24h": {24 * time.Hour, monitoring.MetricResolutionRaw},
+	"7d":  {7 * 24 * time.Hour, monitoring.MetricResolutionHour},
+	"30d": {30 * 24 * time.Hour, monitoring.MetricResolutionHour},
+}
+
+// DefaultRange 默认查询范围
+const DefaultRange = "24h"
+
+// IsValidRange 检查查询范围是否受支持
+func IsValidRange(r string) bool {
+	_, ok := queryRanges[r]
+	return ok
+}
+
+// CollectInterval 返回当前配置的采集间隔
+func CollectInterval() time.Duration {
+	if minutes := global.APP_CONFIG.Metrics.CollectInterval; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultCollectInterval
+}
+
+// Service 实例资源使用历史服务
+type Service struct{}
+
+// NewService 创建实例资源使用历史服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// GetSeries 获取实例在指定范围内的资源使用曲线
+func (s *Service) GetSeries(instanceID uint, rangeName string) (*monitoring.ResourceUsageSeries, error) {
+	r, ok := queryRanges[rangeName]
+	if !ok {
+		return nil, fmt.Errorf("不支持的时间范围: %s", rangeName)
+	}
+
+	var records []monitoring.InstanceResourceMetric
+	if err := global.APP_DB.Where("instance_id = ? AND resolution = ? AND timestamp >= ?",
+		instanceID, r.Resolution, time.Now().Add(-r.Duration)).
+		Order("timestamp ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("获取实例资源使用历史失败: %v", err)
+	}
+
+	series := &monitoring.ResourceUsageSeries{
+		InstanceID: instanceID,
+		Range:      rangeName,
+		Resolution: r.Resolution,
+		Points:     make([]monitoring.ResourceUsagePoint, 0, len(records)),
+	}
+	for _, record := range records {
+		series.Points = append(series.Points, toPoint(record))
+	}
+
+	var latest monitoring.InstanceResourceMetric
+	if err := global.APP_DB.Where("instance_id = ? AND resolution = ? AND timestamp >= ?",
+		instanceID, monitoring.MetricResolutionRaw, time.Now().Add(-3*CollectInterval())).
+		Order("timestamp DESC").Limit(1).Find(&latest).Error; err != nil {
+		return nil, fmt.Errorf("获取实例最近资源使用情况失败: %v", err)
+	}
+	if latest.ID != 0 {
+		point := toPoint(latest)
+		series.Latest = &point
+	}
+	return series, nil
+}
+
+// InstanceLatestUsage 实例最近一次的资源使用情况
+type InstanceLatestUsage struct {
+	InstanceID   uint                          `json:"instanceId"`
+	InstanceName string                        `json:"instanceName"`
+	UserID       uint                          `json:"userId"`
+	Usage        monitoring.ResourceUsagePoint `json:"usage"`
+}
+
+// GetProviderLatest 获取Provider上所有实例最近一次的资源使用情况，按CPU使用率从高到低排列
+func (s *Service) GetProviderLatest(providerID uint) ([]InstanceLatestUsage, error) {
+	var records []monitoring.InstanceResourceMetric
+	if err := global.APP_DB.Where("provider_id = ? AND resolution = ? AND timestamp >= ?",
+		providerID, monitoring.MetricResolutionRaw, time.Now().Add(-3*CollectInterval())).
+		Order("timestamp DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("获取实例资源使用情况失败: %v", err)
+	}
+
+	// 按时间倒序，每个实例只保留第一条（最新的一条）
+	latest := make(map[uint]monitoring.InstanceResourceMetric)
+	order := make([]uint, 0)
+	for _, record := range records {
+		if _, ok := latest[record.InstanceID]; ok {
+			continue
+		}
+		latest[record.InstanceID] = record
+		order = append(order, record.InstanceID)
+	}
+
+	names := make(map[uint]string, len(order))
+	if len(order) > 0 {
+		var instances []providerModel.Instance
+		if err := global.APP_DB.Select("id, name").Where("id IN ?", order).Find(&instances).Error; err != nil {
+			return nil, fmt.Errorf("获取实例信息失败: %v", err)
+		}
+		for _, inst := range instances {
+			names[inst.ID] = inst.Name
+		}
+	}
+
+	result := make([]InstanceLatestUsage, 0, len(order))
+	for _, id := range order {
+		record := latest[id]
+		result = append(result, InstanceLatestUsage{
+			InstanceID:   id,
+			InstanceName: names[id],
+			UserID:       record.UserID,
+			Usage:        toPoint(record),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Usage.CPUPercent > result[j].Usage.CPUPercent
+	})
+	return result, nil
+}
+
+// Downsample 将已结束且尚未汇总的小时内的原始采样汇总为小时数据
+// 以已有的最新小时汇总为起点，服务停机后会补齐原始采样仍在保留期内的小时
+func (s *Service) Downsample() error {
+	end := time.Now().Truncate(time.Hour)
+	start := end.Add(-time.Duration(rawRetentionDays()) * 24 * time.Hour)
+
+	var lastHour monitoring.InstanceResourceMetric
+	if err := global.APP_DB.Where("resolution = ?", monitoring.MetricResolutionHour).
+		Order("timestamp DESC").Limit(1).Find(&lastHour).Error; err != nil {
+		return fmt.Errorf("获取最近的小时汇总失败: %v", err)
+	}
+	if lastHour.ID != 0 && lastHour.Timestamp.Add(time.Hour).After(start) {
+		start = lastHour.Timestamp.Add(time.Hour)
+	}
+
+	// 从第一条尚未汇总的原始采样所在的小时开始
+	var first monitoring.InstanceResourceMetric
+	if err := global.APP_DB.Where("resolution = ? AND timestamp >= ? AND timestamp < ?",
+		monitoring.MetricResolutionRaw, start, end).
+		Order("timestamp ASC").Limit(1).Find(&first).Error; err != nil {
+		return fmt.Errorf("获取原始采样失败: %v", err)
+	}
+	if first.ID == 0 {
+		return nil
+	}
+
+	for hour := first.Timestamp.Truncate(time.Hour); hour.Before(end); hour = hour.Add(time.Hour) {
+		var samples []monitoring.InstanceResourceMetric
+		if err := global.APP_DB.Where("resolution = ? AND timestamp >= ? AND timestamp < ?",
+			monitoring.MetricResolutionRaw, hour, hour.Add(time.Hour)).
+			Order("timestamp ASC").Find(&samples).Error; err != nil {
+			return fmt.Errorf("获取原始采样失败: %v", err)
+		}
+		rows := aggregateHourly(samples, hour)
+		if len(rows) == 0 {
+			continue
+		}
+		if err := global.APP_DB.CreateInBatches(&rows, 200).Error; err != nil {
+			return fmt.Errorf("保存小时汇总失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// aggregateHourly 将一个小时内的原始采样按实例汇总：CPU和内存取平均值与最大值，上限和磁盘取最后一次采样
+func aggregateHourly(samples []monitoring.InstanceResourceMetric, hour time.Time) []monitoring.InstanceResourceMetric {
+	type accumulator struct {
+		row       monitoring.InstanceResourceMetric
+		cpuSum    float64
+		memorySum int64
+	}
+	groups := make(map[uint]*accumulator)
+	order := make([]uint, 0)
+
+	for _, sample := range samples {
+		acc, ok := groups[sample.InstanceID]
+		if !ok {
+			acc = &accumulator{row: monitoring.InstanceResourceMetric{
+				InstanceID: sample.InstanceID,
+				ProviderID: sample.ProviderID,
+				UserID:     sample.UserID,
+				Resolution: monitoring.MetricResolutionHour,
+				Timestamp:  hour,
+			}}
+			groups[sample.InstanceID] = acc
+			order = append(order, sample.InstanceID)
+		}
+		acc.cpuSum += sample.CPUPercent
+		acc.memorySum += sample.MemoryUsedMB
+		acc.row.Samples++
+		if sample.CPUPercentMax > acc.row.CPUPercentMax {
+			acc.row.CPUPercentMax = sample.CPUPercentMax
+		}
+		if sample.MemoryUsedMaxMB > acc.row.MemoryUsedMaxMB {
+			acc.row.MemoryUsedMaxMB = sample.MemoryUsedMaxMB
+		}
+		acc.row.MemoryTotalMB = sample.MemoryTotalMB
+		acc.row.DiskUsedMB = sample.DiskUsedMB
+		acc.row.DiskTotalMB = sample.DiskTotalMB
+	}
+
+	rows := make([]monitoring.InstanceResourceMetric, 0, len(order))
+	for _, id := range order {
+		acc := groups[id]
+		acc.row.CPUPercent = acc.cpuSum / float64(acc.row.Samples)
+		acc.row.MemoryUsedMB = acc.memorySum / int64(acc.row.Samples)
+		rows = append(rows, acc.row)
+	}
+	return rows
+}
+
+// Cleanup 删除超过保留期的原始采样和小时汇总
+func (s *Service) Cleanup() {
+	now := time.Now()
+	retentions := []struct {
+		resolution string
+		days       int
+	}{
+		{monitoring.MetricResolutionRaw, rawRetentionDays()},
+		{monitoring.MetricResolutionHour, hourlyRetentionDays()},
+	}
+	for _, r := range retentions {
+		result := global.APP_DB.Where("resolution = ? AND timestamp < ?", r.resolution, now.AddDate(0, 0, -r.days)).
+			Delete(&monitoring.InstanceResourceMetric{})
+		if result.Error != nil {
+			global.APP_LOG.Error("清理实例资源使用历史失败", zap.String("resolution", r.resolution), zap.Error(result.Error))
+			continue
+		}
+		if result.RowsAffected > 0 {
+			global.APP_LOG.Info("已清理过期的实例资源使用历史",
+				zap.String("resolution", r.resolution),
+				zap.Int64("count", result.RowsAffected),
+				zap.Int("retentionDays", r.days))
+		}
+	}
+}
+
+// rawRetentionDays 原始采样保留天数
+func rawRetentionDays() int {
+	if days := global.APP_CONFIG.Metrics.RawRetentionDays; days > 0 {
+		return days
+	}
+	return defaultRawRetentionDays
+}
+
+// hourlyRetentionDays 小时汇总保留天数
+func hourlyRetentionDays() int {
+	if days := global.APP_CONFIG.Metrics.HourlyRetentionDays; days > 0 {
+		return days
+	}
+	return defaultHourlyRetentionDays
+}
+
+// toPoint 转换为曲线上的点
+func toPoint(record monitoring.InstanceResourceMetric) monitoring.ResourceUsagePoint {
+	return monitoring.ResourceUsagePoint{
+		Timestamp:       record.Timestamp,
+		CPUPercent:      record.CPUPercent,
+		CPUPercentMax:   record.CPUPercentMax,
+		MemoryUsedMB:    record.MemoryUsedMB,
+		MemoryUsedMaxMB: record.MemoryUsedMaxMB,
+		MemoryTotalMB:   record.MemoryTotalMB,
+		DiskUsedMB:      record.DiskUsedMB,
+		DiskTotalMB:     record.DiskTotalMB,
+	}
+}
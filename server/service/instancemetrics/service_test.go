@@ -0,0 +1,62 @@
+package instancemetrics
+
+import (
+	"testing"
+	"time"
+
+	"oneclickvirt/model/monitoring"
+)
+
+// TestAggregateHourly 测试按实例汇总一小时内的原始采样
+func TestAggregateHourly(t *testing.T) {
+	hour := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	raw := func(instanceID uint, minute int, cpu float64, memory, disk int64) monitoring.InstanceResourceMetric {
+		return monitoring.InstanceResourceMetric{
+			InstanceID:      instanceID,
+			ProviderID:      1,
+			UserID:          instanceID + 100,
+			Resolution:      monitoring.MetricResolutionRaw,
+			Timestamp:       hour.Add(time.Duration(minute) * time.Minute),
+			CPUPercent:      cpu,
+			CPUPercentMax:   cpu,
+			MemoryUsedMB:    memory,
+			MemoryUsedMaxMB: memory,
+			MemoryTotalMB:   1024,
+			DiskUsedMB:      disk,
+			DiskTotalMB:     10240,
+			Samples:         1,
+		}
+	}
+
+	rows := aggregateHourly([]monitoring.InstanceResourceMetric{
+		raw(1, 0, 10, 200, 1000),
+		raw(2, 0, 50, 512, 3000),
+		raw(1, 5, 30, 400, 1100),
+		raw(1, 10, 20, 300, 1200),
+	}, hour)
+
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+
+	first := rows[0]
+	if first.InstanceID != 1 || first.UserID != 101 || first.Resolution != monitoring.MetricResolutionHour || !first.Timestamp.Equal(hour) {
+		t.Errorf("汇总行的基本信息错误: %+v", first)
+	}
+	if first.Samples != 3 {
+		t.Errorf("Samples = %d, want 3", first.Samples)
+	}
+	if first.CPUPercent != 20 || first.CPUPercentMax != 30 {
+		t.Errorf("CPU = %v/%v, want 20/30", first.CPUPercent, first.CPUPercentMax)
+	}
+	if first.MemoryUsedMB != 300 || first.MemoryUsedMaxMB != 400 {
+		t.Errorf("Memory = %d/%d, want 300/400", first.MemoryUsedMB, first.MemoryUsedMaxMB)
+	}
+	if first.DiskUsedMB != 1200 || first.DiskTotalMB != 10240 || first.MemoryTotalMB != 1024 {
+		t.Errorf("磁盘和上限应取最后一次采样: %+v", first)
+	}
+
+	if second := rows[1]; second.InstanceID != 2 || second.Samples != 1 || second.CPUPercent != 50 {
+		t.Errorf("第二个实例汇总错误: %+v", second)
+	}
+}
@@ -0,0 +1,102 @@
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+
+	providerModel "oneclickvirt/model/provider"
+)
+
+// maxPoolAddresses 单个地址池最多包含的地址数量
+const maxPoolAddresses = 4096
+
+// parsePoolCIDR 解析地址池网段，返回地址族和网段
+func parsePoolCIDR(cidr string) (string, *net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return "", nil, fmt.Errorf("无效的网段: %s", cidr)
+	}
+	if ipNet.IP.To4() != nil {
+		return providerModel.IPFamilyIPv4, ipNet, nil
+	}
+	return providerModel.IPFamilyIPv6, ipNet, nil
+}
+
+// expandPoolAddresses 计算地址池的可分配地址
+// 指定地址列表时逐个校验是否位于网段内；否则展开网段内全部主机地址（IPv4排除网络地址和广播地址），
+// 两种方式都会排除网关地址
+func expandPoolAddresses(ipNet *net.IPNet, gateway string, addresses []string) ([]string, error) {
+	var gatewayIP net.IP
+	if gateway != "" {
+		gatewayIP = net.ParseIP(gateway)
+		if gatewayIP == nil || !ipNet.Contains(gatewayIP) {
+			return nil, fmt.Errorf("网关 %s 不在网段 %s 内", gateway, ipNet.String())
+		}
+	}
+
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(ip net.IP) error {
+		if gatewayIP != nil && ip.Equal(gatewayIP) {
+			return nil
+		}
+		addr := ip.String()
+		if seen[addr] {
+			return nil
+		}
+		if len(result) >= maxPoolAddresses {
+			return fmt.Errorf("地址数量超过上限 %d，请缩小网段或指定地址列表", maxPoolAddresses)
+		}
+		seen[addr] = true
+		result = append(result, addr)
+		return nil
+	}
+
+	if len(addresses) > 0 {
+		for _, raw := range addresses {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			ip := net.ParseIP(raw)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的IP地址: %s", raw)
+			}
+			if !ipNet.Contains(ip) {
+				return nil, fmt.Errorf("地址 %s 不在网段 %s 内", raw, ipNet.String())
+			}
+			if err := add(ip); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		ones, bits := ipNet.Mask.Size()
+		hostBits := bits - ones
+		if hostBits > 16 {
+			return nil, fmt.Errorf("网段 %s 过大，请缩小网段或指定地址列表", ipNet.String())
+		}
+
+		base := new(big.Int).SetBytes(ipNet.IP)
+		count := int64(1) << uint(hostBits)
+		start, end := int64(0), count-1
+		// IPv4 /31、/32 以外的网段排除网络地址和广播地址
+		if bits == 32 && hostBits > 1 {
+			start, end = 1, count-2
+		}
+		for i := start; i <= end; i++ {
+			n := new(big.Int).Add(base, big.NewInt(i))
+			ip := make(net.IP, len(ipNet.IP))
+			n.FillBytes(ip)
+			if err := add(ip); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("地址池没有可分配的地址")
+	}
+	return result, nil
+}
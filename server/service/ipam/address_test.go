@@ -0,0 +1,65 @@
+package ipam
+
+import (
+	"testing"
+)
+
+// TestExpandPoolAddresses 测试地址池地址展开
+func TestExpandPoolAddresses(t *testing.T) {
+	t.Run("IPv4网段排除网络地址、广播地址和网关", func(t *testing.T) {
+		family, ipNet, err := parsePoolCIDR("203.0.113.0/29")
+		if err != nil {
+			t.Fatalf("解析网段失败: %v", err)
+		}
+		if family != "ipv4" {
+			t.Errorf("地址族应为 ipv4，实际为 %s", family)
+		}
+
+		addresses, err := expandPoolAddresses(ipNet, "203.0.113.1", nil)
+		if err != nil {
+			t.Fatalf("展开地址失败: %v", err)
+		}
+		expected := []string{"203.0.113.2", "203.0.113.3", "203.0.113.4", "203.0.113.5", "203.0.113.6"}
+		if len(addresses) != len(expected) {
+			t.Fatalf("地址数量应为 %d，实际为 %d: %v", len(expected), len(addresses), addresses)
+		}
+		for i, addr := range expected {
+			if addresses[i] != addr {
+				t.Errorf("第 %d 个地址应为 %s，实际为 %s", i, addr, addresses[i])
+			}
+		}
+	})
+
+	t.Run("IPv6地址列表", func(t *testing.T) {
+		family, ipNet, err := parsePoolCIDR("2001:db8::/64")
+		if err != nil {
+			t.Fatalf("解析网段失败: %v", err)
+		}
+		if family != "ipv6" {
+			t.Errorf("地址族应为 ipv6，实际为 %s", family)
+		}
+
+		addresses, err := expandPoolAddresses(ipNet, "2001:db8::1", []string{"2001:db8::10", "2001:db8::1", "2001:db8::10", " 2001:db8::11 "})
+		if err != nil {
+			t.Fatalf("展开地址失败: %v", err)
+		}
+		if len(addresses) != 2 || addresses[0] != "2001:db8::10" || addresses[1] != "2001:db8::11" {
+			t.Errorf("地址列表应去重并排除网关，实际为 %v", addresses)
+		}
+	})
+
+	t.Run("拒绝网段外的地址和过大的网段", func(t *testing.T) {
+		_, ipNet, _ := parsePoolCIDR("198.51.100.0/24")
+		if _, err := expandPoolAddresses(ipNet, "", []string{"192.0.2.1"}); err == nil {
+			t.Error("网段外的地址应返回错误")
+		}
+		if _, err := expandPoolAddresses(ipNet, "192.0.2.1", nil); err == nil {
+			t.Error("网段外的网关应返回错误")
+		}
+
+		_, bigNet, _ := parsePoolCIDR("2001:db8::/64")
+		if _, err := expandPoolAddresses(bigNet, "", nil); err == nil {
+			t.Error("过大的网段应返回错误")
+		}
+	})
+}
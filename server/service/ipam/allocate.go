@@ -0,0 +1,242 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Allocation 分配给实例的独立地址
+type Allocation struct {
+	Family  string `json:"family"`
+	Address string `json:"address"`
+	Prefix  int    `json:"prefix"`  // 前缀长度
+	Gateway string `json:"gateway"` // 网关地址
+}
+
+// 传递给Provider的实例Metadata键
+const (
+	MetadataIPv4        = "dedicated_ipv4"
+	MetadataIPv4Prefix  = "dedicated_ipv4_prefix"
+	MetadataIPv4Gateway = "dedicated_ipv4_gateway"
+	MetadataIPv6        = "dedicated_ipv6"
+	MetadataIPv6Prefix  = "dedicated_ipv6_prefix"
+	MetadataIPv6Gateway = "dedicated_ipv6_gateway"
+)
+
+// NetworkFamilies 返回网络类型需要从地址池分配的地址族，非独立IP网络类型返回空
+func NetworkFamilies(networkType string) []string {
+	switch networkType {
+	case "dedicated_ipv4":
+		return []string{providerModel.IPFamilyIPv4}
+	case "dedicated_ipv4_ipv6":
+		return []string{providerModel.IPFamilyIPv4, providerModel.IPFamilyIPv6}
+	}
+	return nil
+}
+
+// AllocateForInstanceInTx 在事务中为实例分配独立地址
+// 对网络类型需要的每个地址族：Provider没有配置该地址族的地址池时跳过（沿用原有的网络配置方式），
+// 配置了地址池但没有空闲地址时返回错误，使实例创建失败
+func (s *Service) AllocateForInstanceInTx(tx *gorm.DB, providerID, instanceID uint, networkType string) ([]Allocation, error) {
+	var allocations []Allocation
+	for _, family := range NetworkFamilies(networkType) {
+		var poolIDs []uint
+		if err := tx.Model(&providerModel.IPPool{}).
+			Where("provider_id = ? AND family = ? AND status = ?", providerID, family, providerModel.IPPoolStatusActive).
+			Pluck("id", &poolIDs).Error; err != nil {
+			return nil, fmt.Errorf("查询地址池失败: %v", err)
+		}
+		if len(poolIDs) == 0 {
+			continue
+		}
+
+		// 使用悲观锁锁定一个空闲地址，防止并发创建分配到同一地址
+		var addr providerModel.IPAddress
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("pool_id IN ? AND status = ?", poolIDs, providerModel.IPAddressStatusFree).
+			Order("id ASC").
+			First(&addr).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Provider的%s地址池已无空闲地址", family)
+			}
+			return nil, fmt.Errorf("分配%s地址失败: %v", family, err)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&addr).Updates(map[string]interface{}{
+			"status":       providerModel.IPAddressStatusAllocated,
+			"instance_id":  instanceID,
+			"allocated_at": now,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("更新地址分配状态失败: %v", err)
+		}
+
+		allocation, err := s.toAllocation(tx, addr)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, *allocation)
+
+		global.APP_LOG.Info("分配独立IP地址",
+			zap.Uint("providerId", providerID),
+			zap.Uint("instanceId", instanceID),
+			zap.String("family", family),
+			zap.String("address", addr.Address))
+	}
+	return allocations, nil
+}
+
+// GetInstanceAllocations 获取实例已分配的独立地址
+func (s *Service) GetInstanceAllocations(instanceID uint) ([]Allocation, error) {
+	var addresses []providerModel.IPAddress
+	if err := global.APP_DB.Where("instance_id = ? AND status = ?", instanceID, providerModel.IPAddressStatusAllocated).
+		Order("family ASC").Find(&addresses).Error; err != nil {
+		return nil, fmt.Errorf("查询实例地址失败: %v", err)
+	}
+
+	allocations := make([]Allocation, 0, len(addresses))
+	for _, addr := range addresses {
+		allocation, err := s.toAllocation(global.APP_DB, addr)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, *allocation)
+	}
+	return allocations, nil
+}
+
+// HasInstanceAllocations 实例是否持有地址池分配的独立地址
+func (s *Service) HasInstanceAllocations(instanceID uint) (bool, error) {
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.IPAddress{}).
+		Where("instance_id = ? AND status = ?", instanceID, providerModel.IPAddressStatusAllocated).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("查询实例地址失败: %v", err)
+	}
+	return count > 0, nil
+}
+
+// ManagesProviderAddresses Provider的独立IP是否由地址池分配（网络类型为独立IP且配置了对应地址族的可用地址池）
+func (s *Service) ManagesProviderAddresses(providerID uint, networkType string) (bool, error) {
+	families := NetworkFamilies(networkType)
+	if len(families) == 0 {
+		return false, nil
+	}
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.IPPool{}).
+		Where("provider_id = ? AND family IN ? AND status = ?", providerID, families, providerModel.IPPoolStatusActive).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("查询地址池失败: %v", err)
+	}
+	return count > 0, nil
+}
+
+// ValidateMigration 检查实例能否在两个Provider之间迁移
+// 迁移导入的归档中包含源实例的网络配置，地址池分配的地址无法随实例转移，也无法在目标节点上重新配置，
+// 因此要求两端网络类型一致，且实例未持有地址池地址、目标Provider也不通过地址池分配地址
+func (s *Service) ValidateMigration(instanceID uint, source, target *providerModel.Provider) error {
+	if source.NetworkType != target.NetworkType {
+		return fmt.Errorf("目标Provider网络类型 %s 与源Provider网络类型 %s 不一致", target.NetworkType, source.NetworkType)
+	}
+	allocated, err := s.HasInstanceAllocations(instanceID)
+	if err != nil {
+		return err
+	}
+	if allocated {
+		return errors.New("实例持有地址池分配的独立IP地址，不支持迁移")
+	}
+	managed, err := s.ManagesProviderAddresses(target.ID, target.NetworkType)
+	if err != nil {
+		return err
+	}
+	if managed {
+		return errors.New("目标Provider通过地址池分配独立IP地址，不支持迁入实例")
+	}
+	return nil
+}
+
+// toAllocation 根据地址所属地址池补全前缀长度和网关
+func (s *Service) toAllocation(tx *gorm.DB, addr providerModel.IPAddress) (*Allocation, error) {
+	var pool providerModel.IPPool
+	if err := tx.Unscoped().First(&pool, addr.PoolID).Error; err != nil {
+		return nil, fmt.Errorf("获取地址池失败: %v", err)
+	}
+	_, ipNet, err := net.ParseCIDR(pool.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("地址池网段无效: %s", pool.CIDR)
+	}
+	prefix, _ := ipNet.Mask.Size()
+	return &Allocation{
+		Family:  addr.Family,
+		Address: addr.Address,
+		Prefix:  prefix,
+		Gateway: pool.Gateway,
+	}, nil
+}
+
+// ReleaseByInstanceInTx 在事务中释放实例的全部独立地址
+func (s *Service) ReleaseByInstanceInTx(tx *gorm.DB, instanceID uint) error {
+	result := tx.Model(&providerModel.IPAddress{}).
+		Where("instance_id = ? AND status = ?", instanceID, providerModel.IPAddressStatusAllocated).
+		Updates(map[string]interface{}{
+			"status":       providerModel.IPAddressStatusFree,
+			"instance_id":  nil,
+			"allocated_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("释放实例独立地址失败: %v", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		global.APP_LOG.Info("释放实例独立IP地址",
+			zap.Uint("instanceId", instanceID),
+			zap.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
+
+// TransferInTx 在事务中将地址从旧实例转移到新实例（实例重置时保留原地址）
+func (s *Service) TransferInTx(tx *gorm.DB, oldInstanceID, newInstanceID uint) error {
+	if err := tx.Model(&providerModel.IPAddress{}).
+		Where("instance_id = ? AND status = ?", oldInstanceID, providerModel.IPAddressStatusAllocated).
+		Update("instance_id", newInstanceID).Error; err != nil {
+		return fmt.Errorf("转移实例独立地址失败: %v", err)
+	}
+	return nil
+}
+
+// ApplyMetadata 将分配结果写入实例Metadata，供Provider配置网络
+func ApplyMetadata(metadata map[string]string, allocations []Allocation) {
+	for _, a := range allocations {
+		switch a.Family {
+		case providerModel.IPFamilyIPv4:
+			metadata[MetadataIPv4] = a.Address
+			metadata[MetadataIPv4Prefix] = fmt.Sprintf("%d", a.Prefix)
+			metadata[MetadataIPv4Gateway] = a.Gateway
+		case providerModel.IPFamilyIPv6:
+			metadata[MetadataIPv6] = a.Address
+			metadata[MetadataIPv6Prefix] = fmt.Sprintf("%d", a.Prefix)
+			metadata[MetadataIPv6Gateway] = a.Gateway
+		}
+	}
+}
+
+// ApplyInstanceUpdates 用分配的地址覆盖实例的公网地址字段
+func ApplyInstanceUpdates(updates map[string]interface{}, allocations []Allocation) {
+	for _, a := range allocations {
+		switch a.Family {
+		case providerModel.IPFamilyIPv4:
+			updates["public_ip"] = a.Address
+		case providerModel.IPFamilyIPv6:
+			updates["public_ipv6"] = a.Address
+		}
+	}
+}
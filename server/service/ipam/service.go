@@ -0,0 +1,366 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service 独立IP地址池服务
+// 仅依赖global/model，供实例创建、删除、重置流程直接调用而不产生循环依赖
+type Service struct{}
+
+// NewService 创建地址池服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// PoolUsage 地址池使用情况
+type PoolUsage struct {
+	Total     int64 `json:"total"`     // 地址总数
+	Allocated int64 `json:"allocated"` // 已分配
+	Reserved  int64 `json:"reserved"`  // 管理员保留
+	Free      int64 `json:"free"`      // 空闲
+}
+
+// PoolView 地址池及其使用情况
+type PoolView struct {
+	providerModel.IPPool
+	ProviderName string    `json:"providerName"`
+	Usage        PoolUsage `json:"usage"`
+}
+
+// AddressView 地址及其分配的实例
+type AddressView struct {
+	providerModel.IPAddress
+	InstanceName string `json:"instanceName"`
+}
+
+// ListPools 分页获取地址池列表（含使用情况）
+func (s *Service) ListPools(req adminModel.IPPoolListRequest) ([]PoolView, int64, error) {
+	query := global.APP_DB.Model(&providerModel.IPPool{})
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.Family != "" {
+		query = query.Where("family = ?", req.Family)
+	}
+	if req.Keyword != "" {
+		like := "%" + req.Keyword + "%"
+		query = query.Where("name LIKE ? OR cidr LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计地址池数量失败: %v", err)
+	}
+
+	var pools []providerModel.IPPool
+	if err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&pools).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取地址池列表失败: %v", err)
+	}
+
+	views := make([]PoolView, 0, len(pools))
+	if len(pools) == 0 {
+		return views, total, nil
+	}
+
+	poolIDs := make([]uint, 0, len(pools))
+	providerIDs := make([]uint, 0, len(pools))
+	for _, pool := range pools {
+		poolIDs = append(poolIDs, pool.ID)
+		providerIDs = append(providerIDs, pool.ProviderID)
+	}
+
+	usage, err := s.poolUsage(poolIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var providers []providerModel.Provider
+	providerNames := make(map[uint]string)
+	if err := global.APP_DB.Select("id", "name").Where("id IN ?", providerIDs).Find(&providers).Error; err == nil {
+		for _, p := range providers {
+			providerNames[p.ID] = p.Name
+		}
+	}
+
+	for _, pool := range pools {
+		views = append(views, PoolView{
+			IPPool:       pool,
+			ProviderName: providerNames[pool.ProviderID],
+			Usage:        usage[pool.ID],
+		})
+	}
+	return views, total, nil
+}
+
+// poolUsage 按状态统计地址池的地址数量
+func (s *Service) poolUsage(poolIDs []uint) (map[uint]PoolUsage, error) {
+	var rows []struct {
+		PoolID uint
+		Status string
+		Count  int64
+	}
+	if err := global.APP_DB.Model(&providerModel.IPAddress{}).
+		Select("pool_id, status, COUNT(*) AS count").
+		Where("pool_id IN ?", poolIDs).
+		Group("pool_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计地址池使用情况失败: %v", err)
+	}
+
+	result := make(map[uint]PoolUsage, len(poolIDs))
+	for _, row := range rows {
+		usage := result[row.PoolID]
+		usage.Total += row.Count
+		switch row.Status {
+		case providerModel.IPAddressStatusAllocated:
+			usage.Allocated += row.Count
+		case providerModel.IPAddressStatusReserved:
+			usage.Reserved += row.Count
+		case providerModel.IPAddressStatusFree:
+			usage.Free += row.Count
+		}
+		result[row.PoolID] = usage
+	}
+	return result, nil
+}
+
+// GetPool 获取单个地址池（含使用情况）
+func (s *Service) GetPool(id uint) (*PoolView, error) {
+	var pool providerModel.IPPool
+	if err := global.APP_DB.First(&pool, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("地址池不存在")
+		}
+		return nil, fmt.Errorf("获取地址池失败: %v", err)
+	}
+
+	usage, err := s.poolUsage([]uint{pool.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	view := &PoolView{IPPool: pool, Usage: usage[pool.ID]}
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.Select("id", "name").First(&dbProvider, pool.ProviderID).Error; err == nil {
+		view.ProviderName = dbProvider.Name
+	}
+	return view, nil
+}
+
+// CreatePool 创建地址池并生成地址记录
+func (s *Service) CreatePool(req adminModel.CreateIPPoolRequest) (*PoolView, error) {
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.Select("id", "name", "type").First(&dbProvider, req.ProviderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Provider不存在")
+		}
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+	if dbProvider.Type == "docker" {
+		return nil, errors.New("Docker类型的Provider不支持独立IP地址池")
+	}
+
+	family, ipNet, err := parsePoolCIDR(req.CIDR)
+	if err != nil {
+		return nil, err
+	}
+	gateway := strings.TrimSpace(req.Gateway)
+	addresses, err := expandPoolAddresses(ipNet, gateway, req.Addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = providerModel.IPPoolStatusActive
+	}
+	pool := providerModel.IPPool{
+		ProviderID:  req.ProviderID,
+		Name:        strings.TrimSpace(req.Name),
+		Family:      family,
+		CIDR:        ipNet.String(),
+		Gateway:     gateway,
+		Status:      status,
+		Description: req.Description,
+	}
+
+	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		// 同一Provider下的地址不能出现在多个地址池中
+		var conflict providerModel.IPAddress
+		if err := tx.Where("provider_id = ? AND address IN ?", req.ProviderID, addresses).
+			First(&conflict).Error; err == nil {
+			return fmt.Errorf("地址 %s 已存在于该Provider的其他地址池中", conflict.Address)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("检查地址冲突失败: %v", err)
+		}
+
+		if err := tx.Create(&pool).Error; err != nil {
+			return fmt.Errorf("创建地址池失败: %v", err)
+		}
+
+		records := make([]providerModel.IPAddress, 0, len(addresses))
+		for _, addr := range addresses {
+			records = append(records, providerModel.IPAddress{
+				PoolID:     pool.ID,
+				ProviderID: pool.ProviderID,
+				Family:     family,
+				Address:    addr,
+				Status:     providerModel.IPAddressStatusFree,
+			})
+		}
+		if err := tx.CreateInBatches(records, 500).Error; err != nil {
+			return fmt.Errorf("创建地址记录失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	global.APP_LOG.Info("创建独立IP地址池",
+		zap.Uint("poolId", pool.ID),
+		zap.Uint("providerId", pool.ProviderID),
+		zap.String("cidr", pool.CIDR),
+		zap.Int("addressCount", len(addresses)))
+
+	return s.GetPool(pool.ID)
+}
+
+// UpdatePool 更新地址池基本信息
+func (s *Service) UpdatePool(id uint, req adminModel.UpdateIPPoolRequest) (*PoolView, error) {
+	var pool providerModel.IPPool
+	if err := global.APP_DB.First(&pool, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("地址池不存在")
+		}
+		return nil, fmt.Errorf("获取地址池失败: %v", err)
+	}
+
+	gateway := strings.TrimSpace(req.Gateway)
+	if gateway != "" {
+		_, ipNet, err := parsePoolCIDR(pool.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		if ip := net.ParseIP(gateway); ip == nil || !ipNet.Contains(ip) {
+			return nil, fmt.Errorf("网关 %s 不在网段 %s 内", gateway, pool.CIDR)
+		}
+	}
+
+	if err := global.APP_DB.Model(&pool).Updates(map[string]interface{}{
+		"name":        strings.TrimSpace(req.Name),
+		"gateway":     gateway,
+		"status":      req.Status,
+		"description": req.Description,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新地址池失败: %v", err)
+	}
+	return s.GetPool(id)
+}
+
+// DeletePool 删除地址池，仍有已分配地址时拒绝删除
+func (s *Service) DeletePool(id uint) error {
+	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		var pool providerModel.IPPool
+		if err := tx.First(&pool, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("地址池不存在")
+			}
+			return fmt.Errorf("获取地址池失败: %v", err)
+		}
+
+		var allocated int64
+		if err := tx.Model(&providerModel.IPAddress{}).
+			Where("pool_id = ? AND status = ?", id, providerModel.IPAddressStatusAllocated).
+			Count(&allocated).Error; err != nil {
+			return fmt.Errorf("统计已分配地址失败: %v", err)
+		}
+		if allocated > 0 {
+			return fmt.Errorf("地址池仍有 %d 个地址分配给实例，无法删除", allocated)
+		}
+
+		if err := tx.Where("pool_id = ?", id).Delete(&providerModel.IPAddress{}).Error; err != nil {
+			return fmt.Errorf("删除地址记录失败: %v", err)
+		}
+		if err := tx.Delete(&pool).Error; err != nil {
+			return fmt.Errorf("删除地址池失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// ListAddresses 分页获取地址池中的地址
+func (s *Service) ListAddresses(poolID uint, req adminModel.IPAddressListRequest) ([]AddressView, int64, error) {
+	query := global.APP_DB.Model(&providerModel.IPAddress{}).Where("pool_id = ?", poolID)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		query = query.Where("address LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计地址数量失败: %v", err)
+	}
+
+	var addresses []providerModel.IPAddress
+	if err := query.Order("id ASC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&addresses).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取地址列表失败: %v", err)
+	}
+
+	instanceIDs := make([]uint, 0)
+	for _, addr := range addresses {
+		if addr.InstanceID != nil {
+			instanceIDs = append(instanceIDs, *addr.InstanceID)
+		}
+	}
+	instanceNames := make(map[uint]string)
+	if len(instanceIDs) > 0 {
+		var instances []providerModel.Instance
+		if err := global.APP_DB.Select("id", "name").Where("id IN ?", instanceIDs).Find(&instances).Error; err == nil {
+			for _, inst := range instances {
+				instanceNames[inst.ID] = inst.Name
+			}
+		}
+	}
+
+	views := make([]AddressView, 0, len(addresses))
+	for _, addr := range addresses {
+		view := AddressView{IPAddress: addr}
+		if addr.InstanceID != nil {
+			view.InstanceName = instanceNames[*addr.InstanceID]
+		}
+		views = append(views, view)
+	}
+	return views, total, nil
+}
+
+// UpdateAddressStatus 保留或取消保留地址，已分配的地址不能修改
+func (s *Service) UpdateAddressStatus(poolID, addressID uint, status string) error {
+	result := global.APP_DB.Model(&providerModel.IPAddress{}).
+		Where("id = ? AND pool_id = ? AND status <> ?", addressID, poolID, providerModel.IPAddressStatusAllocated).
+		Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("更新地址状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("地址不存在或已分配给实例")
+	}
+	return nil
+}
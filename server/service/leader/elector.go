@@ -0,0 +1,293 @@
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	systemModel "oneclickvirt/model/system"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLeaseDuration = 30 * time.Second // 租约有效期，领导者宕机后最长经过该时间完成切换
+	defaultRenewInterval = 10 * time.Second // 续约/竞选间隔
+)
+
+// Elector 基于数据库租约的领导者选举
+// 所有实例周期性地尝试获取或续约同名租约，只有持有未过期租约的实例成为领导者。
+// 领导者宕机后租约过期，其他实例在下一次竞选时接管。租约过期判断依赖各实例时钟基本同步。
+type Elector struct {
+	name          string
+	holderID      string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+
+	onStartedLeading func(ctx context.Context) // 成为领导者时回调，ctx在失去领导权时取消
+	onStoppedLeading func()                    // 失去领导权时回调
+
+	mu          sync.Mutex
+	isLeader    bool
+	lastRenew   time.Time
+	leaderStop  context.CancelFunc
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+	startedOnce sync.Once
+}
+
+// NewElector 创建领导者选举器
+func NewElector(name string, onStartedLeading func(ctx context.Context), onStoppedLeading func()) *Elector {
+	return &Elector{
+		name:             name,
+		holderID:         localHolderID,
+		leaseDuration:    defaultLeaseDuration,
+		renewInterval:    defaultRenewInterval,
+		onStartedLeading: onStartedLeading,
+		onStoppedLeading: onStoppedLeading,
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// newHolderID 生成实例标识：主机名-进程号-随机串，保证同一主机上的多个进程也不会冲突
+func newHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	if len(hostname) > 64 {
+		hostname = hostname[:64]
+	}
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(buf))
+}
+
+// HolderID 返回当前实例标识
+func (e *Elector) HolderID() string {
+	return e.holderID
+}
+
+// IsLeader 当前实例是否为领导者
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isLeader
+}
+
+// Start 启动选举循环（立即进行一次竞选）
+func (e *Elector) Start(ctx context.Context) {
+	e.startedOnce.Do(func() {
+		global.APP_LOG.Info("领导者选举已启动",
+			zap.String("lease", e.name),
+			zap.String("holderID", e.holderID),
+			zap.Duration("leaseDuration", e.leaseDuration))
+
+		e.tick()
+
+		e.wg.Add(1)
+		go e.run(ctx)
+	})
+}
+
+// Stop 停止选举，若当前为领导者则先停止领导者任务再释放租约，便于其他实例立即接管；同时使本实例心跳失效
+func (e *Elector) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopChan)
+	})
+	e.wg.Wait()
+
+	e.mu.Lock()
+	wasLeader := e.isLeader
+	e.mu.Unlock()
+
+	if wasLeader {
+		e.stepDown("实例关闭")
+		e.release()
+	}
+	releaseHeartbeat()
+}
+
+// run 选举主循环
+func (e *Elector) run(ctx context.Context) {
+	ticker := time.NewTicker(e.renewInterval)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("领导者选举goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		e.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.tick()
+		}
+	}
+}
+
+// tick 执行一次竞选或续约，并根据结果切换领导者状态
+func (e *Elector) tick() {
+	renewHeartbeat(e.leaseDuration)
+
+	acquired, err := e.tryAcquireOrRenew()
+
+	e.mu.Lock()
+	wasLeader := e.isLeader
+	lastRenew := e.lastRenew
+	e.mu.Unlock()
+
+	if err != nil {
+		global.APP_LOG.Warn("领导者租约续约失败",
+			zap.String("lease", e.name),
+			zap.Bool("isLeader", wasLeader),
+			zap.Error(err))
+		// 数据库暂时不可用时保留领导权，直到租约即将过期（避免其他实例接管后出现两个领导者）
+		if wasLeader && time.Since(lastRenew) >= e.leaseDuration-e.renewInterval {
+			e.stepDown("租约续约失败")
+		}
+		return
+	}
+
+	if acquired {
+		e.mu.Lock()
+		e.lastRenew = time.Now()
+		e.mu.Unlock()
+		if !wasLeader {
+			e.startLeading()
+		}
+		return
+	}
+
+	if wasLeader {
+		e.stepDown("租约已被其他实例持有")
+	}
+}
+
+// tryAcquireOrRenew 获取或续约租约，返回当前实例是否持有租约
+func (e *Elector) tryAcquireOrRenew() (bool, error) {
+	if global.APP_DB == nil {
+		return false, fmt.Errorf("数据库连接不存在")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"holder_id":  e.holderID,
+		"renewed_at": now,
+		"expires_at": now.Add(e.leaseDuration),
+	}
+
+	e.mu.Lock()
+	wasLeader := e.isLeader
+	e.mu.Unlock()
+	if !wasLeader {
+		updates["acquired_at"] = now
+	}
+
+	// 仅当租约由自己持有或已过期时才能更新，保证同一时刻只有一个持有者
+	result := global.APP_DB.Model(&systemModel.LeaderLease{}).
+		Where("name = ? AND (holder_id = ? OR expires_at < ?)", e.name, e.holderID, now).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// 租约记录不存在时尝试创建，主键冲突说明其他实例已抢先创建
+	var count int64
+	if err := global.APP_DB.Model(&systemModel.LeaderLease{}).Where("name = ?", e.name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	lease := systemModel.LeaderLease{
+		Name:       e.name,
+		HolderID:   e.holderID,
+		AcquiredAt: now,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(e.leaseDuration),
+	}
+	if err := global.APP_DB.Create(&lease).Error; err != nil {
+		global.APP_LOG.Debug("创建领导者租约失败，可能已被其他实例创建",
+			zap.String("lease", e.name),
+			zap.Error(err))
+		return false, nil
+	}
+	return true, nil
+}
+
+// startLeading 成为领导者
+func (e *Elector) startLeading() {
+	ctx, cancel := context.WithCancel(global.APP_SHUTDOWN_CONTEXT)
+
+	e.mu.Lock()
+	e.isLeader = true
+	e.leaderStop = cancel
+	e.mu.Unlock()
+
+	global.APP_LOG.Info("当前实例成为领导者",
+		zap.String("lease", e.name),
+		zap.String("holderID", e.holderID))
+
+	if e.onStartedLeading != nil {
+		e.onStartedLeading(ctx)
+	}
+}
+
+// stepDown 放弃领导者身份
+func (e *Elector) stepDown(reason string) {
+	e.mu.Lock()
+	if !e.isLeader {
+		e.mu.Unlock()
+		return
+	}
+	e.isLeader = false
+	cancel := e.leaderStop
+	e.leaderStop = nil
+	e.mu.Unlock()
+
+	global.APP_LOG.Warn("当前实例不再是领导者",
+		zap.String("lease", e.name),
+		zap.String("holderID", e.holderID),
+		zap.String("reason", reason))
+
+	if cancel != nil {
+		cancel()
+	}
+	if e.onStoppedLeading != nil {
+		e.onStoppedLeading()
+	}
+}
+
+// release 主动释放租约（将其置为过期），使其他实例无需等待租约到期即可接管
+func (e *Elector) release() {
+	if global.APP_DB == nil {
+		return
+	}
+	if err := global.APP_DB.Model(&systemModel.LeaderLease{}).
+		Where("name = ? AND holder_id = ?", e.name, e.holderID).
+		Update("expires_at", time.Now().Add(-time.Second)).Error; err != nil {
+		global.APP_LOG.Warn("释放领导者租约失败",
+			zap.String("lease", e.name),
+			zap.Error(err))
+		return
+	}
+	global.APP_LOG.Info("领导者租约已释放", zap.String("lease", e.name))
+}
@@ -0,0 +1,62 @@
+package leader
+
+import (
+	"time"
+
+	"oneclickvirt/global"
+	systemModel "oneclickvirt/model/system"
+
+	"go.uber.org/zap"
+)
+
+// localHolderID 当前进程的实例标识，领导者租约和实例心跳共用
+var localHolderID = newHolderID()
+
+// LocalHolderID 返回当前进程的实例标识
+func LocalHolderID() string {
+	return localHolderID
+}
+
+// renewHeartbeat 续约当前实例的心跳，所有实例（无论是否为领导者）在每次竞选时调用
+func renewHeartbeat(leaseDuration time.Duration) {
+	if global.APP_DB == nil {
+		return
+	}
+
+	now := time.Now()
+	result := global.APP_DB.Model(&systemModel.InstanceHeartbeat{}).
+		Where("holder_id = ?", localHolderID).
+		Updates(map[string]interface{}{
+			"renewed_at": now,
+			"expires_at": now.Add(leaseDuration),
+		})
+	if result.Error != nil {
+		global.APP_LOG.Warn("实例心跳续约失败", zap.String("holderID", localHolderID), zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		return
+	}
+
+	heartbeat := systemModel.InstanceHeartbeat{
+		HolderID:  localHolderID,
+		StartedAt: now,
+		RenewedAt: now,
+		ExpiresAt: now.Add(leaseDuration),
+	}
+	if err := global.APP_DB.Create(&heartbeat).Error; err != nil {
+		global.APP_LOG.Warn("创建实例心跳失败", zap.String("holderID", localHolderID), zap.Error(err))
+	}
+}
+
+// releaseHeartbeat 实例关闭时使心跳立即过期，领导者无需等待即可回收本实例中断的任务
+func releaseHeartbeat() {
+	if global.APP_DB == nil {
+		return
+	}
+	if err := global.APP_DB.Model(&systemModel.InstanceHeartbeat{}).
+		Where("holder_id = ?", localHolderID).
+		Update("expires_at", time.Now().Add(-time.Second)).Error; err != nil {
+		global.APP_LOG.Warn("释放实例心跳失败", zap.String("holderID", localHolderID), zap.Error(err))
+	}
+}
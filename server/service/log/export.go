@@ -226,14 +226,18 @@ func (s *ExportService) ExportOperationLogs(req auth.ExportOperationLogsRequest)
 			db = db.Where("user_id = ?", *req.UserID)
 		}
 
+		if len(req.UserIDs) > 0 {
+			db = db.Where("user_id IN ?", req.UserIDs)
+		}
+
 		// 操作类型过滤
 		if req.Action != "" {
-			db = db.Where("path LIKE ?", "%"+req.Action+"%")
+			db = db.Where("action = ?", req.Action)
 		}
 
 		// 资源过滤
 		if req.Resource != "" {
-			db = db.Where("path LIKE ?", "%"+req.Resource+"%")
+			db = db.Where("resource = ?", req.Resource)
 		}
 
 		// 查询操作日志
@@ -276,7 +280,7 @@ func (s *ExportService) exportLogsToCSV(logs []adminModel.AuditLog) ([]byte, err
 	global.APP_LOG.Debug("开始生成日志CSV文件", zap.Int("logCount", len(logs)))
 
 	// 写入表头
-	headers := []string{"ID", "用户ID", "用户名", "方法", "路径", "状态码", "耗时(ms)", "客户端IP", "用户代理", "创建时间"}
+	headers := []string{"ID", "用户ID", "用户名", "角色", "方法", "路径", "操作", "资源", "资源ID", "结果", "状态码", "耗时(ms)", "客户端IP", "用户代理", "请求摘要", "响应消息", "创建时间"}
 	if err := writer.Write(headers); err != nil {
 		global.APP_LOG.Error("写入日志CSV表头失败",
 			zap.String("error", utils.TruncateString(err.Error(), 200)))
@@ -291,17 +295,28 @@ func (s *ExportService) exportLogsToCSV(logs []adminModel.AuditLog) ([]byte, err
 		} else {
 			userIDStr = "0"
 		}
+		result := "失败"
+		if log.Success {
+			result = "成功"
+		}
 
 		row := []string{
 			strconv.FormatUint(uint64(log.ID), 10),
 			userIDStr,
 			log.Username,
+			log.UserType,
 			log.Method,
 			utils.TruncateString(log.Path, 100),
+			log.Action,
+			log.Resource,
+			log.ResourceID,
+			result,
 			strconv.Itoa(log.StatusCode),
 			strconv.FormatInt(log.Latency, 10),
 			log.ClientIP,
 			utils.TruncateString(log.UserAgent, 200),
+			log.Request,
+			log.Response,
 			log.CreatedAt.Format("2006-01-02 15:04:05"),
 		}
 		if err := writer.Write(row); err != nil {
@@ -0,0 +1,180 @@
+package migration
+
+import (
+	"fmt"
+
+	"oneclickvirt/service/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 0001_baseline 基线迁移
+// 引入版本化迁移之前的表结构由 AutoMigrate 维护，基线迁移按冻结的表结构快照创建或补齐全部表，
+// 对已有数据库也可安全执行。基线之后的结构变更必须以新迁移的形式添加
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "baseline",
+		Up:      baselineUp,
+		Down:    baselineDown,
+	})
+}
+
+// baselineModels 基线迁移维护的表，结构定义见 0001_baseline_models.go
+func baselineModels() []interface{} {
+	return []interface{}{
+		// 用户相关表
+		&userV1{},     // 用户基础信息表
+		&roleV1{},     // 角色管理表
+		&userRoleV1{}, // 用户角色关联表
+
+		// OAuth2相关表
+		&oauth2ProviderV1{}, // OAuth2提供商配置表
+
+		// 实例相关表
+		&instanceV1{},         // 虚拟机/容器实例表
+		&providerV1{},         // 服务提供商配置表
+		&portV1{},             // 端口映射表
+		&instanceSnapshotV1{}, // 实例快照表
+		&instanceBackupV1{},   // 实例备份表
+		&backupScheduleV1{},   // 定时备份计划表
+		&ipPoolV1{},           // 独立IP地址池表
+		&ipAddressV1{},        // 地址池地址表
+		&taskV1{},             // 用户任务表
+
+		// 资源管理表
+		&resourceReservationV1{}, // 资源预留表
+
+		// 计费相关表
+		&userBalanceV1{},        // 用户余额表
+		&balanceTransactionV1{}, // 余额流水表
+		&billingPriceV1{},       // 价格规则表
+		&instanceBillingV1{},    // 实例计费状态表
+
+		// 健康检查历史表
+		&providerHealthRecordV1{}, // Provider健康检查历史表
+
+		// 安全组相关表
+		&securityGroupV1{},         // 安全组表
+		&securityGroupRuleV1{},     // 安全组规则表
+		&instanceSecurityGroupV1{}, // 实例安全组绑定表
+
+		// 域名绑定表
+		&instanceDomainV1{}, // 实例域名绑定表
+
+		// SSH主机密钥表
+		&providerHostKeyV1{}, // Provider SSH主机密钥表
+
+		// 通知相关表
+		&notificationTemplateV1{}, // 通知模板表
+		&notificationLogV1{},      // 通知发送记录表
+		&notificationSettingV1{},  // 用户通知设置表
+
+		// 认证相关表
+		&verifyCodeV1{},    // 验证码表（邮箱/短信）
+		&passwordResetV1{}, // 密码重置令牌表
+		&userTwoFactorV1{}, // 用户两步验证表
+		&apiTokenV1{},      // 用户API令牌表
+
+		// 系统配置表
+		&systemConfigV1{}, // 系统配置表
+		&announcementV1{}, // 系统公告表
+		&systemImageV1{},  // 系统镜像模板表
+		&captchaV1{},      // 图形验证码表
+		&jwtSecretV1{},    // JWT密钥表
+		&leaderLeaseV1{},  // 领导者租约表
+
+		// 邀请码相关表
+		&inviteCodeV1{},      // 邀请码表
+		&inviteCodeUsageV1{}, // 邀请码使用记录表
+
+		// Webhook相关表
+		&webhookV1{},         // Webhook配置表
+		&webhookDeliveryV1{}, // Webhook投递记录表
+
+		// 权限管理表
+		&userPermissionV1{}, // 用户权限组合表
+
+		// 审计日志表
+		&auditLogV1{},        // 操作审计日志表
+		&pendingDeletionV1{}, // 待删除资源表
+
+		// 管理员配置任务表
+		&configurationTaskV1{},  // 管理员配置任务表
+		&trafficMonitorTaskV1{}, // 流量监控操作任务表
+
+		// 监控数据表
+		&pmacctTrafficRecordV1{},    // pmacct流量记录表（原始数据，5分钟粒度）
+		&pmacctMonitorV1{},          // pmacct监控配置表
+		&instanceTrafficHistoryV1{}, // 实例流量历史表
+		&providerTrafficHistoryV1{}, // Provider流量历史表
+		&userTrafficHistoryV1{},     // 用户流量历史表
+		&performanceMetricV1{},      // 性能指标历史表
+		&instanceResourceMetricV1{}, // 实例资源使用历史表
+	}
+}
+
+func baselineUp(tx *gorm.DB) error {
+	// 添加唯一索引前先清理老数据库中可能存在的重复数据，避免建索引失败
+	if tx.Migrator().HasTable(&instanceTrafficHistoryV1{}) {
+		if err := fixDuplicateTrafficHistory(tx); err != nil {
+			return err
+		}
+	}
+
+	return database.AutoMigrate(tx, baselineModels()...)
+}
+
+// baselineDown 删除基线创建的全部表，回滚到空数据库
+func baselineDown(tx *gorm.DB) error {
+	models := baselineModels()
+	for i := len(models) - 1; i >= 0; i-- {
+		if err := tx.Migrator().DropTable(models[i]); err != nil {
+			return fmt.Errorf("删除表失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// fixDuplicateTrafficHistory 清理 instance_traffic_histories 表中的重复数据
+// 同一实例同一小时只保留 ID 最小的记录
+func fixDuplicateTrafficHistory(tx *gorm.DB) error {
+	var duplicateCount int64
+	checkSQL := `
+		SELECT COUNT(*) as count FROM (
+			SELECT instance_id, year, month, day, hour, COUNT(*) as cnt
+			FROM instance_traffic_histories
+			GROUP BY instance_id, year, month, day, hour
+			HAVING COUNT(*) > 1
+		) as duplicates
+	`
+	if err := tx.Raw(checkSQL).Scan(&duplicateCount).Error; err != nil {
+		return fmt.Errorf("检查重复数据失败: %v", err)
+	}
+	if duplicateCount == 0 {
+		return nil
+	}
+
+	// 子查询再包一层派生表，MySQL 不允许在 DELETE 的子查询中直接引用被删除的表；
+	// 不使用 DELETE JOIN，以兼容 PostgreSQL 和 SQLite
+	deleteSQL := `
+		DELETE FROM instance_traffic_histories
+		WHERE id NOT IN (
+			SELECT min_id FROM (
+				SELECT MIN(id) as min_id
+				FROM instance_traffic_histories
+				GROUP BY instance_id, year, month, day, hour
+			) AS keep_ids
+		)
+	`
+	result := tx.Exec(deleteSQL)
+	if result.Error != nil {
+		return fmt.Errorf("删除重复数据失败: %v", result.Error)
+	}
+
+	logWarn("已清理实例流量历史中的重复数据",
+		zap.Int64("duplicate_groups", duplicateCount),
+		zap.Int64("deleted_rows", result.RowsAffected))
+	return nil
+}
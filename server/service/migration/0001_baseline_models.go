@@ -0,0 +1,1263 @@
+package migration
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 基线迁移的表结构快照
+// 由引入版本化迁移时的模型定义冻结而来，只保留建表需要的字段和 gorm 标签，之后不再随模型修改。
+// 加密字段的 serializer 标签不影响表结构，快照中已去掉，迁移不依赖密钥。
+// 模型的结构变更需要新增迁移，不要修改这里的定义
+
+// userV1 用户基础信息表
+type userV1 struct {
+	ID               uint   `gorm:"primarykey"`
+	UUID             string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	Username         string         `gorm:"uniqueIndex;not null;size:64"`
+	Password         string         `gorm:"not null;size:128"`
+	Nickname         string         `gorm:"size:64"`
+	Email            string         `gorm:"size:128;index:idx_email"`
+	Phone            string         `gorm:"size:32"`
+	Telegram         string         `gorm:"size:64"`
+	QQ               string         `gorm:"size:32"`
+	Avatar           string         `gorm:"size:255"`
+	Status           int            `gorm:"default:1;index:idx_status"`
+	Level            int            `gorm:"default:1;index:idx_level"`
+	UserType         string         `gorm:"default:user;size:16"`
+	TwoFactorEnabled bool           `gorm:"default:false"`
+	UsedQuota        int            `gorm:"default:0"`
+	PendingQuota     int            `gorm:"default:0"`
+	TotalQuota       int            `gorm:"default:0"`
+	TotalTraffic     int64          `gorm:"default:0"`
+	TrafficResetAt   *time.Time
+	TrafficLimited   bool   `gorm:"default:false"`
+	MaxInstances     int    `gorm:"default:1"`
+	MaxCPU           int    `gorm:"default:1"`
+	MaxMemory        int    `gorm:"default:512"`
+	MaxDisk          int    `gorm:"default:10240"`
+	MaxBandwidth     int    `gorm:"default:100"`
+	InviteCode       string `gorm:"size:32"`
+	LastLoginAt      *time.Time
+	ExpiresAt        *time.Time `gorm:"index:idx_expires_at"`
+	IsManualExpiry   bool       `gorm:"default:false"`
+	OAuth2ProviderID uint       `gorm:"index"`
+	OAuth2UID        string     `gorm:"size:255;index"`
+	OAuth2Username   string     `gorm:"size:255"`
+	OAuth2Email      string     `gorm:"size:255"`
+	OAuth2Avatar     string     `gorm:"size:512"`
+	OAuth2Extra      string     `gorm:"type:text"`
+}
+
+func (userV1) TableName() string {
+	return "users"
+}
+
+// roleV1 角色管理表
+type roleV1 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"uniqueIndex;not null;size:64"`
+	Description string         `gorm:"size:255"`
+	Code        string         `gorm:"size:64"`
+	Status      int            `gorm:"default:1"`
+	Remark      string         `gorm:"size:255"`
+}
+
+func (roleV1) TableName() string {
+	return "roles"
+}
+
+// userRoleV1 用户角色关联表
+type userRoleV1 struct {
+	UserID uint `gorm:"primarykey"`
+	RoleID uint `gorm:"primarykey"`
+}
+
+func (userRoleV1) TableName() string {
+	return "user_roles"
+}
+
+// oauth2ProviderV1 OAuth2提供商配置表
+type oauth2ProviderV1 struct {
+	ID                   uint `gorm:"primarykey"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            gorm.DeletedAt `gorm:"index"`
+	Name                 string         `gorm:"uniqueIndex;not null;size:64"`
+	DisplayName          string         `gorm:"not null;size:128"`
+	ProviderType         string         `gorm:"not null;size:32"`
+	Enabled              bool           `gorm:"default:false"`
+	ClientID             string         `gorm:"not null;size:255"`
+	ClientSecret         string         `gorm:"not null;size:255"`
+	RedirectURL          string         `gorm:"not null;size:512"`
+	AuthURL              string         `gorm:"not null;size:512"`
+	TokenURL             string         `gorm:"not null;size:512"`
+	UserInfoURL          string         `gorm:"not null;size:512"`
+	UserIDField          string         `gorm:"default:id;size:128"`
+	UsernameField        string         `gorm:"default:username;size:128"`
+	EmailField           string         `gorm:"default:email;size:128"`
+	AvatarField          string         `gorm:"default:avatar;size:128"`
+	NicknameField        string         `gorm:"size:128"`
+	TrustLevelField      string         `gorm:"size:128"`
+	MaxRegistrations     int            `gorm:"default:0"`
+	CurrentRegistrations int            `gorm:"default:0"`
+	LevelMapping         string         `gorm:"type:text"`
+	DefaultLevel         int            `gorm:"default:1"`
+	TotalUsers           int            `gorm:"default:0"`
+	Sort                 int            `gorm:"default:0"`
+}
+
+func (oauth2ProviderV1) TableName() string {
+	return "o_auth2_providers"
+}
+
+// instanceV1 虚拟机/容器实例表
+type instanceV1 struct {
+	ID                 uint   `gorm:"primarykey"`
+	UUID               string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index:idx_deleted_at"`
+	Name               string         `gorm:"uniqueIndex:idx_instance_name_provider,priority:1;not null;size:128"`
+	Provider           string         `gorm:"not null;size:32;index:idx_provider_name"`
+	ProviderID         uint           `gorm:"uniqueIndex:idx_instance_name_provider,priority:2;index:idx_provider_id;index:idx_provider_status,priority:1;not null"`
+	Status             string         `gorm:"size:32;index:idx_status;index:idx_provider_status,priority:2"`
+	Image              string         `gorm:"size:128"`
+	InstanceType       string         `gorm:"size:16;default:container;index:idx_instance_type"`
+	CPU                int            `gorm:"default:1"`
+	Memory             int64          `gorm:"default:512"`
+	Disk               int64          `gorm:"default:10240"`
+	Bandwidth          int            `gorm:"default:10"`
+	Network            string         `gorm:"size:64"`
+	PrivateIP          string         `gorm:"size:64"`
+	PublicIP           string         `gorm:"size:64"`
+	IPv6Address        string         `gorm:"size:128"`
+	PublicIPv6         string         `gorm:"size:128"`
+	SSHPort            int            `gorm:"default:22"`
+	PortRangeStart     int
+	PortRangeEnd       int
+	Username           string     `gorm:"size:64"`
+	Password           string     `gorm:"type:text"`
+	OSType             string     `gorm:"size:64"`
+	Region             string     `gorm:"size:64"`
+	MaxTraffic         int64      `gorm:"default:0"`
+	TrafficLimited     bool       `gorm:"default:false"`
+	TrafficLimitReason string     `gorm:"size:16;default:''"`
+	PmacctInterfaceV4  string     `gorm:"size:32"`
+	PmacctInterfaceV6  string     `gorm:"size:32"`
+	ExpiresAt          *time.Time `gorm:"index:idx_expires_at;column:expires_at"`
+	IsFrozen           bool       `gorm:"default:false;index:idx_frozen"`
+	IsManualExpiry     bool       `gorm:"default:false"`
+	FrozenReason       string     `gorm:"size:255"`
+	FrozenAt           *time.Time
+	UserID             uint `gorm:"index:idx_user_id;index:idx_user_status,priority:1"`
+}
+
+func (instanceV1) TableName() string {
+	return "instances"
+}
+
+// providerV1 服务提供商配置表
+type providerV1 struct {
+	ID                         uint   `gorm:"primarykey"`
+	UUID                       string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt                  time.Time
+	UpdatedAt                  time.Time
+	Name                       string     `gorm:"uniqueIndex;not null;size:64"`
+	Type                       string     `gorm:"not null;size:32;index:idx_type"`
+	Endpoint                   string     `gorm:"size:255"`
+	PortIP                     string     `gorm:"size:255"`
+	SSHPort                    int        `gorm:"default:22"`
+	Username                   string     `gorm:"size:128"`
+	Password                   string     `gorm:"type:text"`
+	SSHKey                     string     `gorm:"type:text"`
+	Token                      string     `gorm:"type:text"`
+	Config                     string     `gorm:"type:text"`
+	Status                     string     `gorm:"default:active;size:16;index:idx_status"`
+	Region                     string     `gorm:"size:64;index:idx_region"`
+	Country                    string     `gorm:"size:64"`
+	CountryCode                string     `gorm:"size:8"`
+	City                       string     `gorm:"size:64"`
+	Version                    string     `gorm:"size:32;default:''"`
+	ContainerEnabled           bool       `gorm:"default:true"`
+	VirtualMachineEnabled      bool       `gorm:"default:false"`
+	SupportedTypes             string     `gorm:"size:128"`
+	AllowClaim                 bool       `gorm:"default:true"`
+	IPv4PortMappingMethod      string     `gorm:"size:16;default:device_proxy"`
+	IPv6PortMappingMethod      string     `gorm:"size:16;default:device_proxy"`
+	UsedQuota                  int        `gorm:"default:0"`
+	TotalQuota                 int        `gorm:"default:0"`
+	Architecture               string     `gorm:"size:16;default:amd64"`
+	ExpiresAt                  *time.Time `gorm:"index;column:expires_at"`
+	IsFrozen                   bool       `gorm:"default:false;index:idx_frozen"`
+	IsManualExpiry             bool       `gorm:"default:false"`
+	FrozenReason               string     `gorm:"size:255"`
+	FrozenAt                   *time.Time
+	StoragePool                string `gorm:"size:64;default:local"`
+	StoragePoolPath            string `gorm:"size:255;default:''"`
+	CertPath                   string `gorm:"size:512"`
+	KeyPath                    string `gorm:"size:512"`
+	CACertPath                 string `gorm:"size:512"`
+	CertFingerprint            string `gorm:"size:128"`
+	APIStatus                  string `gorm:"default:unknown;size:16"`
+	SSHStatus                  string `gorm:"default:unknown;size:16"`
+	LastAPICheck               *time.Time
+	LastSSHCheck               *time.Time
+	AuthConfig                 string `gorm:"type:text"`
+	ConfigVersion              int    `gorm:"default:0"`
+	AutoConfigured             bool   `gorm:"default:false"`
+	LastConfigUpdate           *time.Time
+	ConfigBackupPath           string `gorm:"size:512"`
+	CertContent                string `gorm:"type:text"`
+	KeyContent                 string `gorm:"type:text"`
+	TokenContent               string `gorm:"type:text"`
+	NodeCPUCores               int    `gorm:"default:0"`
+	NodeMemoryTotal            int64  `gorm:"default:0"`
+	NodeDiskTotal              int64  `gorm:"default:0"`
+	AllowConcurrentTasks       bool   `gorm:"default:false"`
+	MaxConcurrentTasks         int    `gorm:"default:1"`
+	SSHConnectTimeout          int    `gorm:"default:30"`
+	SSHExecuteTimeout          int    `gorm:"default:300"`
+	TaskPollInterval           int    `gorm:"default:60"`
+	EnableTaskPolling          bool   `gorm:"default:true"`
+	ExecutionRule              string `gorm:"default:auto;size:16"`
+	MaxContainerInstances      int    `gorm:"default:0"`
+	MaxVMInstances             int    `gorm:"default:0"`
+	ContainerLimitCPU          bool   `gorm:"default:false"`
+	ContainerLimitMemory       bool   `gorm:"default:false"`
+	ContainerLimitDisk         bool   `gorm:"default:true"`
+	VMLimitCPU                 bool   `gorm:"default:true"`
+	VMLimitMemory              bool   `gorm:"default:true"`
+	VMLimitDisk                bool   `gorm:"default:true"`
+	DefaultPortCount           int    `gorm:"default:10"`
+	PortRangeStart             int    `gorm:"default:10000"`
+	PortRangeEnd               int    `gorm:"default:65535"`
+	NextAvailablePort          int    `gorm:"default:10000"`
+	NetworkType                string `gorm:"default:nat_ipv4;size:32;not null"`
+	DefaultInboundBandwidth    int    `gorm:"default:300"`
+	DefaultOutboundBandwidth   int    `gorm:"default:300"`
+	MaxInboundBandwidth        int    `gorm:"default:1000"`
+	MaxOutboundBandwidth       int    `gorm:"default:1000"`
+	EnableTrafficControl       bool   `gorm:"default:false"`
+	MaxTraffic                 int64  `gorm:"default:1048576"`
+	TrafficLimited             bool   `gorm:"default:false"`
+	TrafficResetAt             *time.Time
+	TrafficCountMode           string  `gorm:"default:both;size:16"`
+	TrafficMultiplier          float64 `gorm:"default:1.0"`
+	TrafficStatsMode           string  `gorm:"default:light;size:16"`
+	TrafficCollectInterval     int     `gorm:"column:traffic_collect_interval;default:300"`
+	TrafficCollectBatchSize    int     `gorm:"column:traffic_collect_batch_size;default:10"`
+	TrafficLimitCheckInterval  int     `gorm:"default:600"`
+	TrafficLimitCheckBatchSize int     `gorm:"default:10"`
+	TrafficAutoResetInterval   int     `gorm:"default:1800"`
+	TrafficAutoResetBatchSize  int     `gorm:"default:10"`
+	UsedCPUCores               int     `gorm:"default:0"`
+	UsedMemory                 int64   `gorm:"default:0"`
+	UsedDisk                   int64   `gorm:"default:0"`
+	ContainerCount             int     `gorm:"default:0"`
+	VMCount                    int     `gorm:"default:0"`
+	ResourceSynced             bool    `gorm:"default:false"`
+	ResourceSyncedAt           *time.Time
+	CountCacheExpiry           *time.Time
+	AvailableCPUCores          int    `gorm:"default:0"`
+	AvailableMemory            int64  `gorm:"default:0"`
+	UsedInstances              int    `gorm:"default:0"`
+	LevelLimits                string `gorm:"type:text"`
+	HostName                   string `gorm:"size:128"`
+	ContainerPrivileged        bool   `gorm:"default:false"`
+	ContainerAllowNesting      bool   `gorm:"default:false"`
+	ContainerEnableLXCFS       bool   `gorm:"default:true"`
+	ContainerCPUAllowance      string `gorm:"default:100%;size:16"`
+	ContainerMemorySwap        bool   `gorm:"default:true"`
+	ContainerMaxProcesses      int    `gorm:"default:0"`
+	ContainerDiskIOLimit       string `gorm:"size:32"`
+}
+
+func (providerV1) TableName() string {
+	return "providers"
+}
+
+// portV1 端口映射表
+type portV1 struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	InstanceID    uint           `gorm:"index:idx_instance_ssh,priority:1;index:idx_instance_status,priority:1"`
+	ProviderID    uint           `gorm:"index:idx_provider_id"`
+	HostPort      int            `gorm:"not null"`
+	HostPortEnd   int            `gorm:"default:0"`
+	GuestPort     int            `gorm:"not null"`
+	GuestPortEnd  int            `gorm:"default:0"`
+	PortCount     int            `gorm:"default:1"`
+	Protocol      string         `gorm:"default:both;size:8"`
+	Status        string         `gorm:"default:active;size:16;index:idx_instance_status,priority:2"`
+	Description   string         `gorm:"size:256"`
+	IsSSH         bool           `gorm:"default:false;index:idx_instance_ssh,priority:2"`
+	IsAutomatic   bool           `gorm:"default:true"`
+	PortType      string         `gorm:"default:range_mapped;size:16"`
+	IPv6Enabled   bool           `gorm:"default:false"`
+	IPv6Address   string         `gorm:"size:64"`
+	MappingMethod string         `gorm:"size:32;default:native"`
+}
+
+func (portV1) TableName() string {
+	return "ports"
+}
+
+// instanceSnapshotV1 实例快照表
+type instanceSnapshotV1 struct {
+	ID           uint   `gorm:"primarykey"`
+	UUID         string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Name         string         `gorm:"not null;size:64"`
+	Description  string         `gorm:"size:255"`
+	Status       string         `gorm:"default:creating;size:16;index:idx_snapshot_instance_status,priority:2"`
+	ErrorMessage string         `gorm:"size:512"`
+	InstanceName string         `gorm:"size:128"`
+	ProviderType string         `gorm:"size:32"`
+	InstanceID   uint           `gorm:"not null;index:idx_snapshot_instance_status,priority:1"`
+	ProviderID   uint           `gorm:"not null;index"`
+	UserID       uint           `gorm:"not null;index"`
+}
+
+func (instanceSnapshotV1) TableName() string {
+	return "instance_snapshots"
+}
+
+// instanceBackupV1 实例备份表
+type instanceBackupV1 struct {
+	ID           uint   `gorm:"primarykey"`
+	UUID         string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Status       string         `gorm:"default:creating;size:16;index"`
+	Trigger      string         `gorm:"default:manual;size:16"`
+	ErrorMessage string         `gorm:"size:512"`
+	StorageType  string         `gorm:"size:16"`
+	StorageKey   string         `gorm:"size:512"`
+	Size         int64          `gorm:"default:0"`
+	CompletedAt  *time.Time
+	InstanceID   uint   `gorm:"not null;index"`
+	InstanceName string `gorm:"size:128"`
+	InstanceType string `gorm:"size:16"`
+	OSType       string `gorm:"size:64"`
+	ProviderID   uint   `gorm:"not null;index"`
+	ProviderType string `gorm:"size:32"`
+	UserID       uint   `gorm:"not null;index"`
+}
+
+func (instanceBackupV1) TableName() string {
+	return "instance_backups"
+}
+
+// backupScheduleV1 定时备份计划表
+type backupScheduleV1 struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	InstanceID    uint      `gorm:"uniqueIndex;not null"`
+	UserID        uint      `gorm:"not null;index"`
+	Enabled       bool      `gorm:"default:true"`
+	IntervalHours int       `gorm:"not null"`
+	NextRunAt     time.Time `gorm:"index"`
+	LastRunAt     *time.Time
+}
+
+func (backupScheduleV1) TableName() string {
+	return "backup_schedules"
+}
+
+// ipPoolV1 独立IP地址池表
+type ipPoolV1 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	ProviderID  uint           `gorm:"not null;index"`
+	Name        string         `gorm:"size:64;not null"`
+	Family      string         `gorm:"size:8;not null"`
+	CIDR        string         `gorm:"size:64;not null"`
+	Gateway     string         `gorm:"size:64"`
+	Status      string         `gorm:"size:16;default:active;index"`
+	Description string         `gorm:"size:255"`
+}
+
+func (ipPoolV1) TableName() string {
+	return "ip_pools"
+}
+
+// ipAddressV1 地址池地址表
+type ipAddressV1 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	PoolID      uint   `gorm:"not null;uniqueIndex:idx_ip_pool_address"`
+	ProviderID  uint   `gorm:"not null;index"`
+	Family      string `gorm:"size:8;not null"`
+	Address     string `gorm:"size:64;not null;uniqueIndex:idx_ip_pool_address"`
+	Status      string `gorm:"size:16;default:free;index"`
+	InstanceID  *uint  `gorm:"index"`
+	AllocatedAt *time.Time
+}
+
+func (ipAddressV1) TableName() string {
+	return "ip_addresses"
+}
+
+// taskV1 用户任务表
+type taskV1 struct {
+	ID                    uint           `gorm:"primarykey"`
+	UUID                  string         `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt             time.Time      `gorm:"index:idx_status_created,priority:2;index:idx_user_created,priority:2"`
+	UpdatedAt             time.Time      `gorm:"index"`
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+	TaskType              string         `gorm:"not null;size:32"`
+	Status                string         `gorm:"default:pending;size:32;index:idx_status_created,priority:1;index:idx_provider_status,priority:2"`
+	Progress              int            `gorm:"default:0"`
+	ErrorMessage          string         `gorm:"type:text"`
+	CancelReason          string         `gorm:"type:text"`
+	StatusMessage         string         `gorm:"size:512"`
+	TaskData              string         `gorm:"type:text"`
+	StartedAt             *time.Time
+	CompletedAt           *time.Time
+	EstimatedDuration     int   `gorm:"default:0"`
+	TimeoutDuration       int   `gorm:"default:1800"`
+	PreallocatedCPU       int   `gorm:"default:0"`
+	PreallocatedMemory    int   `gorm:"default:0"`
+	PreallocatedDisk      int   `gorm:"default:0"`
+	PreallocatedBandwidth int   `gorm:"default:0"`
+	UserID                uint  `gorm:"index:idx_user_created,priority:1;index:idx_user_status,priority:1"`
+	ProviderID            *uint `gorm:"index:idx_provider_status,priority:1"`
+	InstanceID            *uint
+	Provider              *providerV1 `gorm:"foreignKey:ProviderID"`
+	CanForceStop          bool        `gorm:"default:false"`
+	IsForceStoppable      bool        `gorm:"default:true"`
+}
+
+func (taskV1) TableName() string {
+	return "tasks"
+}
+
+// resourceReservationV1 资源预留表
+type resourceReservationV1 struct {
+	ID           uint   `gorm:"primarykey"`
+	UUID         string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	UserID       uint           `gorm:"not null;index"`
+	ProviderID   uint           `gorm:"not null;index"`
+	SessionID    string         `gorm:"not null;size:64;uniqueIndex"`
+	InstanceType string         `gorm:"not null;size:16"`
+	CPU          int            `gorm:"not null"`
+	Memory       int64          `gorm:"not null"`
+	Disk         int64          `gorm:"not null"`
+	Bandwidth    int            `gorm:"not null"`
+	ExpiresAt    time.Time      `gorm:"index;column:expires_at"`
+}
+
+func (resourceReservationV1) TableName() string {
+	return "resource_reservations"
+}
+
+// userBalanceV1 用户余额表
+type userBalanceV1 struct {
+	ID                 uint `gorm:"primarykey"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	UserID             uint  `gorm:"uniqueIndex;not null"`
+	Balance            int64 `gorm:"not null;default:0"`
+	TotalRecharged     int64 `gorm:"default:0"`
+	TotalCharged       int64 `gorm:"default:0"`
+	LowBalanceWarnedAt *time.Time
+}
+
+func (userBalanceV1) TableName() string {
+	return "user_balances"
+}
+
+// balanceTransactionV1 余额流水表
+type balanceTransactionV1 struct {
+	ID           uint      `gorm:"primarykey"`
+	CreatedAt    time.Time `gorm:"index"`
+	UserID       uint      `gorm:"not null;index"`
+	Type         string    `gorm:"size:16;not null"`
+	Amount       int64     `gorm:"not null"`
+	BalanceAfter int64     `gorm:"not null"`
+	InstanceID   *uint     `gorm:"index"`
+	PriceID      *uint
+	PeriodStart  *time.Time
+	PeriodEnd    *time.Time
+	OperatorID   uint
+	Description  string `gorm:"size:255"`
+}
+
+func (balanceTransactionV1) TableName() string {
+	return "balance_transactions"
+}
+
+// billingPriceV1 价格规则表
+type billingPriceV1 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string `gorm:"size:64;not null"`
+	ProviderID   uint   `gorm:"index;default:0"`
+	InstanceType string `gorm:"size:16"`
+	CPU          int    `gorm:"default:0"`
+	Memory       int64  `gorm:"default:0"`
+	Disk         int64  `gorm:"default:0"`
+	HourlyPrice  int64  `gorm:"default:0"`
+	MonthlyPrice int64  `gorm:"default:0"`
+	Enabled      bool   `gorm:"index"`
+	Description  string `gorm:"size:255"`
+}
+
+func (billingPriceV1) TableName() string {
+	return "billing_prices"
+}
+
+// instanceBillingV1 实例计费状态表
+type instanceBillingV1 struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	InstanceID    uint `gorm:"uniqueIndex;not null"`
+	UserID        uint `gorm:"index;not null"`
+	PriceID       uint
+	Cycle         string    `gorm:"size:16"`
+	PaidUntil     time.Time `gorm:"index"`
+	LastChargedAt *time.Time
+	TotalCharged  int64 `gorm:"default:0"`
+}
+
+func (instanceBillingV1) TableName() string {
+	return "instance_billings"
+}
+
+// providerHealthRecordV1 Provider健康检查历史表
+type providerHealthRecordV1 struct {
+	ID         uint      `gorm:"primarykey"`
+	ProviderID uint      `gorm:"not null;index:idx_provider_health_time,priority:1"`
+	CheckedAt  time.Time `gorm:"not null;index:idx_provider_health_time,priority:2;index"`
+	Status     string    `gorm:"size:16"`
+	SSHStatus  string    `gorm:"size:16"`
+	APIStatus  string    `gorm:"size:16"`
+	DurationMs int64
+	Error      string `gorm:"size:255"`
+}
+
+func (providerHealthRecordV1) TableName() string {
+	return "provider_health_records"
+}
+
+// securityGroupV1 安全组表
+type securityGroupV1 struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uint                  `gorm:"index;not null"`
+	Name          string                `gorm:"size:64;not null"`
+	Description   string                `gorm:"size:255"`
+	DefaultAction string                `gorm:"size:8;default:allow"`
+	Rules         []securityGroupRuleV1 `gorm:"foreignKey:SecurityGroupID"`
+}
+
+func (securityGroupV1) TableName() string {
+	return "security_groups"
+}
+
+// securityGroupRuleV1 安全组规则表
+type securityGroupRuleV1 struct {
+	ID              uint `gorm:"primarykey"`
+	CreatedAt       time.Time
+	SecurityGroupID uint   `gorm:"index;not null"`
+	Action          string `gorm:"size:8;not null"`
+	Protocol        string `gorm:"size:8;not null"`
+	PortStart       int    `gorm:"default:0"`
+	PortEnd         int    `gorm:"default:0"`
+	Source          string `gorm:"size:64"`
+	Description     string `gorm:"size:255"`
+}
+
+func (securityGroupRuleV1) TableName() string {
+	return "security_group_rules"
+}
+
+// instanceSecurityGroupV1 实例安全组绑定表
+type instanceSecurityGroupV1 struct {
+	ID              uint `gorm:"primarykey"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	InstanceID      uint   `gorm:"uniqueIndex;not null"`
+	SecurityGroupID uint   `gorm:"index;not null"`
+	ApplyStatus     string `gorm:"size:16;default:pending"`
+	ApplyError      string `gorm:"size:255"`
+	AppliedAt       *time.Time
+	SecurityGroup   *securityGroupV1 `gorm:"foreignKey:SecurityGroupID"`
+}
+
+func (instanceSecurityGroupV1) TableName() string {
+	return "instance_security_groups"
+}
+
+// instanceDomainV1 实例域名绑定表
+type instanceDomainV1 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uint   `gorm:"index;not null"`
+	InstanceID   uint   `gorm:"index;not null"`
+	ProviderID   uint   `gorm:"index;not null"`
+	Domain       string `gorm:"size:253;not null;uniqueIndex:idx_domain_protocol"`
+	Protocol     string `gorm:"size:8;not null;uniqueIndex:idx_domain_protocol"`
+	TargetPort   int    `gorm:"not null"`
+	VerifyMethod string `gorm:"size:8"`
+	VerifyToken  string `gorm:"size:64"`
+	Status       string `gorm:"size:16;index;default:pending"`
+	VerifiedAt   *time.Time
+	LastError    string `gorm:"size:255"`
+}
+
+func (instanceDomainV1) TableName() string {
+	return "instance_domains"
+}
+
+// providerHostKeyV1 Provider SSH主机密钥表
+type providerHostKeyV1 struct {
+	ID                 uint `gorm:"primarykey"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	ProviderID         uint   `gorm:"uniqueIndex;not null"`
+	KeyType            string `gorm:"size:64"`
+	PublicKey          string `gorm:"type:text"`
+	Fingerprint        string `gorm:"size:128"`
+	PendingKeyType     string `gorm:"size:64"`
+	PendingPublicKey   string `gorm:"type:text"`
+	PendingFingerprint string `gorm:"size:128"`
+	MismatchAt         *time.Time
+}
+
+func (providerHostKeyV1) TableName() string {
+	return "provider_host_keys"
+}
+
+// notificationTemplateV1 通知模板表
+type notificationTemplateV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Event     string `gorm:"uniqueIndex;size:64;not null"`
+	Subject   string `gorm:"size:255;not null"`
+	Body      string `gorm:"type:text"`
+	Enabled   bool
+}
+
+func (notificationTemplateV1) TableName() string {
+	return "notification_templates"
+}
+
+// notificationLogV1 通知发送记录表
+type notificationLogV1 struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	UserID    uint      `gorm:"index"`
+	Event     string    `gorm:"size:64;index"`
+	Channel   string    `gorm:"size:16"`
+	Recipient string    `gorm:"size:128"`
+	Subject   string    `gorm:"size:255"`
+	Content   string    `gorm:"type:text"`
+	Status    string    `gorm:"size:16;index"`
+	Error     string    `gorm:"size:512"`
+	DedupKey  string    `gorm:"size:128;index"`
+}
+
+func (notificationLogV1) TableName() string {
+	return "notification_logs"
+}
+
+// notificationSettingV1 用户通知设置表
+type notificationSettingV1 struct {
+	ID              uint `gorm:"primarykey"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uint `gorm:"uniqueIndex;not null"`
+	EmailEnabled    bool
+	TelegramEnabled bool
+	TelegramChatID  string `gorm:"size:64"`
+	ExpiryReminder  bool
+	FrozenNotice    bool
+	TrafficNotice   bool
+	TaskFailNotice  bool
+}
+
+func (notificationSettingV1) TableName() string {
+	return "notification_settings"
+}
+
+// verifyCodeV1 验证码表（邮箱/短信）
+type verifyCodeV1 struct {
+	ID        uint      `gorm:"primarykey"`
+	Email     string    `gorm:"size:100"`
+	Phone     string    `gorm:"size:20"`
+	Target    string    `gorm:"size:128"`
+	Code      string    `gorm:"size:10;not null"`
+	Type      string    `gorm:"size:20;not null"`
+	Used      bool      `gorm:"default:false"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (verifyCodeV1) TableName() string {
+	return "verify_codes"
+}
+
+// passwordResetV1 密码重置令牌表
+type passwordResetV1 struct {
+	ID        uint      `gorm:"primarykey"`
+	UserUUID  string    `gorm:"size:36;not null"`
+	Token     string    `gorm:"size:64;not null;uniqueIndex"`
+	Used      bool      `gorm:"default:false"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (passwordResetV1) TableName() string {
+	return "password_resets"
+}
+
+// userTwoFactorV1 用户两步验证表
+type userTwoFactorV1 struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	UserID         uint   `gorm:"uniqueIndex;not null"`
+	Enabled        bool   `gorm:"default:false"`
+	Secret         string `gorm:"size:64"`
+	PendingSecret  string `gorm:"size:64"`
+	RecoveryCodes  string `gorm:"type:text"`
+	LastUsedStep   int64  `gorm:"default:0"`
+	EnabledAt      *time.Time
+	FailedAttempts int `gorm:"default:0"`
+	LockedUntil    *time.Time
+}
+
+func (userTwoFactorV1) TableName() string {
+	return "user_two_factors"
+}
+
+// apiTokenV1 用户API令牌表
+type apiTokenV1 struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uint   `gorm:"index;not null"`
+	Name       string `gorm:"size:64;not null"`
+	Prefix     string `gorm:"size:16;not null"`
+	TokenHash  string `gorm:"size:64;uniqueIndex;not null"`
+	Scopes     string `gorm:"size:128;not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string     `gorm:"size:64"`
+	RevokedAt  *time.Time `gorm:"index"`
+}
+
+func (apiTokenV1) TableName() string {
+	return "api_tokens"
+}
+
+// systemConfigV1 系统配置表
+type systemConfigV1 struct {
+	ID          uint   `gorm:"primarykey"`
+	Key         string `gorm:"uniqueIndex;not null;size:64"`
+	Value       string `gorm:"type:text"`
+	Description string `gorm:"size:255"`
+	Category    string `gorm:"size:32"`
+	Type        string `gorm:"size:20;not null;default:string"`
+	IsPublic    bool   `gorm:"not null;default:false"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (systemConfigV1) TableName() string {
+	return "system_configs"
+}
+
+// announcementV1 系统公告表
+type announcementV1 struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Title       string         `gorm:"not null;size:255"`
+	Content     string         `gorm:"size:16777217"`
+	ContentHTML string         `gorm:"size:16777217"`
+	Type        string         `gorm:"size:32;default:homepage"`
+	Priority    int            `gorm:"default:0"`
+	Status      int            `gorm:"default:1"`
+	IsSticky    bool           `gorm:"default:false"`
+	StartTime   *time.Time
+	EndTime     *time.Time
+	CreatedBy   *uint
+}
+
+func (announcementV1) TableName() string {
+	return "announcements"
+}
+
+// systemImageV1 系统镜像模板表
+type systemImageV1 struct {
+	ID           uint   `gorm:"primarykey"`
+	UUID         string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Name         string         `gorm:"not null;size:128"`
+	Description  string         `gorm:"size:512"`
+	URL          string         `gorm:"not null;size:512"`
+	Status       string         `gorm:"default:active;size:16"`
+	ProviderType string         `gorm:"not null;size:32"`
+	InstanceType string         `gorm:"not null;size:16"`
+	Architecture string         `gorm:"not null;size:16"`
+	Checksum     string         `gorm:"size:128"`
+	Size         int64          `gorm:"default:0"`
+	OSType       string         `gorm:"size:32"`
+	OSVersion    string         `gorm:"size:32"`
+	Tags         string         `gorm:"size:255"`
+	MinMemoryMB  int            `gorm:"default:0"`
+	MinDiskMB    int            `gorm:"default:0"`
+	UseCDN       bool           `gorm:"default:true"`
+	CreatedBy    *uint
+}
+
+func (systemImageV1) TableName() string {
+	return "system_images"
+}
+
+// captchaV1 图形验证码表
+type captchaV1 struct {
+	ID        string `gorm:"primarykey;size:64"`
+	Code      string `gorm:"not null;size:16"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ExpiresAt time.Time      `gorm:"index"`
+}
+
+func (captchaV1) TableName() string {
+	return "captchas"
+}
+
+// jwtSecretV1 JWT密钥表
+type jwtSecretV1 struct {
+	ID        uint   `gorm:"primarykey"`
+	SecretKey string `gorm:"type:varchar(512);not null;uniqueIndex;comment:JWT签名密钥"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (jwtSecretV1) TableName() string {
+	return "jwt_secrets"
+}
+
+// leaderLeaseV1 领导者租约表
+type leaderLeaseV1 struct {
+	Name       string    `gorm:"primaryKey;type:varchar(64);comment:租约名称"`
+	HolderID   string    `gorm:"type:varchar(128);not null;comment:当前持有者标识"`
+	AcquiredAt time.Time `gorm:"comment:获得租约时间"`
+	RenewedAt  time.Time `gorm:"comment:最后续约时间"`
+	ExpiresAt  time.Time `gorm:"index;comment:租约过期时间"`
+}
+
+func (leaderLeaseV1) TableName() string {
+	return "leader_leases"
+}
+
+// inviteCodeV1 邀请码表
+type inviteCodeV1 struct {
+	ID          uint       `gorm:"primarykey"`
+	Code        string     `gorm:"size:32;not null;uniqueIndex"`
+	CreatorID   uint       `gorm:"not null;index"`
+	CreatorName string     `gorm:"size:50;not null"`
+	Description string     `gorm:"size:255"`
+	MaxUses     int        `gorm:"not null;default:1"`
+	UsedCount   int        `gorm:"not null;default:0"`
+	ExpiresAt   *time.Time `gorm:"index"`
+	Status      int        `gorm:"not null;default:1;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (inviteCodeV1) TableName() string {
+	return "invite_codes"
+}
+
+// inviteCodeUsageV1 邀请码使用记录表
+type inviteCodeUsageV1 struct {
+	ID           uint      `gorm:"primarykey"`
+	InviteCodeID uint      `gorm:"not null;index"`
+	IP           string    `gorm:"size:45;not null"`
+	UserAgent    string    `gorm:"size:500"`
+	UsedAt       time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (inviteCodeUsageV1) TableName() string {
+	return "invite_code_usages"
+}
+
+// webhookV1 Webhook配置表
+type webhookV1 struct {
+	ID                 uint `gorm:"primarykey"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+	Name               string         `gorm:"size:64;not null"`
+	URL                string         `gorm:"size:512;not null"`
+	Secret             string         `gorm:"size:128;not null"`
+	Events             string         `gorm:"type:text"`
+	Enabled            bool           `gorm:"index"`
+	Description        string         `gorm:"size:255"`
+	MaxAttempts        int            `gorm:"not null;default:8"`
+	LastDeliveryAt     *time.Time
+	LastDeliveryStatus string `gorm:"size:16"`
+}
+
+func (webhookV1) TableName() string {
+	return "webhooks"
+}
+
+// webhookDeliveryV1 Webhook投递记录表
+type webhookDeliveryV1 struct {
+	ID           uint   `gorm:"primarykey"`
+	UUID         string `gorm:"uniqueIndex;not null;size:36"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	WebhookID    uint       `gorm:"not null;index"`
+	Event        string     `gorm:"size:64;index"`
+	Payload      string     `gorm:"type:text"`
+	Status       string     `gorm:"size:16;default:pending;index:idx_webhook_delivery_due,priority:1"`
+	Attempts     int        `gorm:"default:0"`
+	MaxAttempts  int        `gorm:"default:8"`
+	NextRetryAt  *time.Time `gorm:"index:idx_webhook_delivery_due,priority:2"`
+	ResponseCode int
+	ResponseBody string `gorm:"size:1024"`
+	ErrorMessage string `gorm:"size:512"`
+	DurationMs   int64
+	DeliveredAt  *time.Time
+}
+
+func (webhookDeliveryV1) TableName() string {
+	return "webhook_deliveries"
+}
+
+// userPermissionV1 用户权限组合表
+type userPermissionV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    uint           `gorm:"not null;index"`
+	UserTypes string         `gorm:"size:100;not null"`
+	Level     int            `gorm:"default:1"`
+	IsActive  bool           `gorm:"default:true"`
+	Remark    string         `gorm:"size:255"`
+}
+
+func (userPermissionV1) TableName() string {
+	return "user_permissions"
+}
+
+// auditLogV1 操作审计日志表
+type auditLogV1 struct {
+	ID         uint      `gorm:"primarykey"`
+	CreatedAt  time.Time `gorm:"index"`
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	UserID     *uint          `gorm:"index"`
+	Username   string         `gorm:"size:64"`
+	UserType   string         `gorm:"size:16"`
+	APITokenID uint           `gorm:"default:0"`
+	Method     string         `gorm:"size:16"`
+	Path       string         `gorm:"size:255"`
+	Action     string         `gorm:"size:64;index"`
+	Resource   string         `gorm:"size:64;index"`
+	ResourceID string         `gorm:"size:64"`
+	Success    bool           `gorm:"index"`
+	StatusCode int
+	Latency    int64
+	ClientIP   string `gorm:"size:64"`
+	UserAgent  string `gorm:"size:255"`
+	Request    string `gorm:"type:text"`
+	Response   string `gorm:"type:text"`
+}
+
+func (auditLogV1) TableName() string {
+	return "audit_logs"
+}
+
+// pendingDeletionV1 待删除资源表
+type pendingDeletionV1 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	ResourceType string `gorm:"not null;size:32"`
+	ResourceID   uint   `gorm:"not null"`
+	ResourceUUID string `gorm:"not null;size:36"`
+	ScheduledAt  time.Time
+	Status       string `gorm:"default:pending;size:16"`
+}
+
+func (pendingDeletionV1) TableName() string {
+	return "pending_deletions"
+}
+
+// configurationTaskV1 管理员配置任务表
+type configurationTaskV1 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ProviderID   uint           `gorm:"not null;index"`
+	TaskType     string         `gorm:"not null;size:32;default:auto_configure"`
+	Status       string         `gorm:"not null;size:16;default:running"`
+	Progress     int            `gorm:"default:0"`
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+	ExecutorID   uint
+	ExecutorName string      `gorm:"size:64"`
+	Success      bool        `gorm:"default:false"`
+	ErrorMessage string      `gorm:"type:text"`
+	ResultData   string      `gorm:"type:text"`
+	LogOutput    string      `gorm:"size:16777217"`
+	LogSummary   string      `gorm:"type:text"`
+	Provider     *providerV1 `gorm:"foreignKey:ProviderID"`
+}
+
+func (configurationTaskV1) TableName() string {
+	return "configuration_tasks"
+}
+
+// trafficMonitorTaskV1 流量监控操作任务表
+type trafficMonitorTaskV1 struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ProviderID   uint           `gorm:"not null;index"`
+	TaskType     string         `gorm:"type:varchar(50);not null"`
+	Status       string         `gorm:"type:varchar(20);not null;default:'pending'"`
+	Progress     int            `gorm:"default:0"`
+	Message      string         `gorm:"type:text"`
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+	TotalCount   int    `gorm:"default:0"`
+	SuccessCount int    `gorm:"default:0"`
+	FailedCount  int    `gorm:"default:0"`
+	Output       string `gorm:"size:16777217"`
+	ErrorMsg     string `gorm:"type:text"`
+}
+
+func (trafficMonitorTaskV1) TableName() string {
+	return "traffic_monitor_tasks"
+}
+
+// pmacctTrafficRecordV1 pmacct流量记录表
+type pmacctTrafficRecordV1 struct {
+	ID           uint   `gorm:"primaryKey"`
+	InstanceID   uint   `gorm:"index:idx_instance_year_month_timestamp,priority:1;index:idx_instance_year_month,priority:1;not null;uniqueIndex:uk_instance_timestamp,priority:1"`
+	UserID       uint   `gorm:"index:idx_user_year_month,priority:1;not null"`
+	ProviderID   uint   `gorm:"index:idx_provider_year_month,priority:1;not null"`
+	ProviderType string `gorm:"size:50;not null"`
+	MappedIP     string `gorm:"size:64;not null"`
+	RxBytes      int64
+	TxBytes      int64
+	TotalBytes   int64
+	Timestamp    time.Time `gorm:"index:idx_instance_year_month_timestamp,priority:4;index:idx_timestamp;not null;uniqueIndex:uk_instance_timestamp,priority:2"`
+	Year         int       `gorm:"index:idx_instance_year_month,priority:2;index:idx_instance_year_month_timestamp,priority:2;index:idx_user_year_month,priority:2;index:idx_provider_year_month,priority:2"`
+	Month        int       `gorm:"index:idx_instance_year_month,priority:3;index:idx_instance_year_month_timestamp,priority:3;index:idx_user_year_month,priority:3;index:idx_provider_year_month,priority:3"`
+	Day          int
+	Hour         int
+	Minute       int
+	RecordTime   time.Time `gorm:"index:idx_record_time"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+func (pmacctTrafficRecordV1) TableName() string {
+	return "pmacct_traffic_records"
+}
+
+// pmacctMonitorV1 pmacct监控配置表
+type pmacctMonitorV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	InstanceID     uint   `gorm:"uniqueIndex;not null"`
+	ProviderID     uint   `gorm:"index;not null"`
+	ProviderType   string `gorm:"size:50;not null"`
+	MappedIP       string `gorm:"size:64;not null"`
+	MappedIPv6     string `gorm:"size:128"`
+	NetworkIfaceV4 string `gorm:"size:32"`
+	NetworkIfaceV6 string `gorm:"size:32"`
+	IsEnabled      bool   `gorm:"default:true"`
+	LastSync       time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+func (pmacctMonitorV1) TableName() string {
+	return "pmacct_monitors"
+}
+
+// instanceTrafficHistoryV1 实例流量历史表
+type instanceTrafficHistoryV1 struct {
+	ID         uint `gorm:"primaryKey"`
+	InstanceID uint `gorm:"index:idx_instance_time,priority:1;uniqueIndex:uk_instance_period,priority:1;not null"`
+	ProviderID uint `gorm:"index:idx_provider_id;not null"`
+	UserID     uint `gorm:"index:idx_user_id;not null"`
+	TrafficIn  int64
+	TrafficOut int64
+	TotalUsed  int64
+	Year       int       `gorm:"index:idx_instance_time,priority:2;uniqueIndex:uk_instance_period,priority:2;not null"`
+	Month      int       `gorm:"index:idx_instance_time,priority:3;uniqueIndex:uk_instance_period,priority:3;not null"`
+	Day        int       `gorm:"index:idx_instance_time,priority:4;uniqueIndex:uk_instance_period,priority:4;not null"`
+	Hour       int       `gorm:"index:idx_instance_time,priority:5;uniqueIndex:uk_instance_period,priority:5;not null"`
+	RecordTime time.Time `gorm:"index:idx_record_time"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index:idx_deleted_at"`
+}
+
+func (instanceTrafficHistoryV1) TableName() string {
+	return "instance_traffic_histories"
+}
+
+// providerTrafficHistoryV1 Provider流量历史表
+type providerTrafficHistoryV1 struct {
+	ID            uint `gorm:"primaryKey"`
+	ProviderID    uint `gorm:"index:idx_provider_time,priority:1;not null"`
+	TrafficIn     int64
+	TrafficOut    int64
+	TotalUsed     int64
+	InstanceCount int
+	Year          int       `gorm:"index:idx_provider_time,priority:2;not null"`
+	Month         int       `gorm:"index:idx_provider_time,priority:3;not null"`
+	Day           int       `gorm:"index:idx_provider_time,priority:4;not null"`
+	Hour          int       `gorm:"index:idx_provider_time,priority:5;not null"`
+	RecordTime    time.Time `gorm:"index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+func (providerTrafficHistoryV1) TableName() string {
+	return "provider_traffic_histories"
+}
+
+// userTrafficHistoryV1 用户流量历史表
+type userTrafficHistoryV1 struct {
+	ID            uint `gorm:"primaryKey"`
+	UserID        uint `gorm:"index:idx_user_time,priority:1;not null"`
+	TrafficIn     int64
+	TrafficOut    int64
+	TotalUsed     int64
+	InstanceCount int
+	Year          int       `gorm:"index:idx_user_time,priority:2;not null"`
+	Month         int       `gorm:"index:idx_user_time,priority:3;not null"`
+	Day           int       `gorm:"index:idx_user_time,priority:4;not null"`
+	Hour          int       `gorm:"index:idx_user_time,priority:5;not null"`
+	RecordTime    time.Time `gorm:"index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+func (userTrafficHistoryV1) TableName() string {
+	return "user_traffic_histories"
+}
+
+// performanceMetricV1 性能指标历史表
+type performanceMetricV1 struct {
+	ID                      uint `gorm:"primarykey"`
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+	DeletedAt               gorm.DeletedAt `gorm:"index"`
+	Timestamp               time.Time      `gorm:"index:idx_timestamp;not null"`
+	GoroutineCount          int
+	CPUCount                int
+	MemoryAlloc             uint64
+	MemoryTotalAlloc        uint64
+	MemorySys               uint64
+	MemoryHeapAlloc         uint64
+	MemoryHeapSys           uint64
+	MemoryStackInuse        uint64
+	GCCount                 uint32
+	GCPauseTotal            uint64
+	GCPauseAvg              uint64
+	GCLastPause             uint64
+	NextGC                  uint64
+	DBMaxOpenConnections    int
+	DBOpenConnections       int
+	DBInUse                 int
+	DBIdle                  int
+	DBWaitCount             int64
+	DBWaitDuration          int64
+	DBMaxIdleClosed         int64
+	DBMaxLifetimeClosed     int64
+	SSHTotalConnections     int
+	SSHHealthyConnections   int
+	SSHUnhealthyConnections int
+	SSHIdleConnections      int
+	SSHActiveConnections    int
+	SSHMaxConnections       int
+	SSHUtilization          float64
+	SSHOldestConnectionAge  int64
+	SSHNewestConnectionAge  int64
+	SSHAvgConnectionAge     int64
+	TaskRunningContexts     int
+	TaskProviderPools       int
+	TaskTotalQueueSize      int
+}
+
+func (performanceMetricV1) TableName() string {
+	return "performance_metrics"
+}
+
+// instanceResourceMetricV1 实例资源使用历史表
+type instanceResourceMetricV1 struct {
+	ID              uint      `gorm:"primaryKey"`
+	InstanceID      uint      `gorm:"index:idx_instance_metric_time,priority:1;not null"`
+	ProviderID      uint      `gorm:"index:idx_metric_provider_id;not null"`
+	UserID          uint      `gorm:"index:idx_metric_user_id;not null"`
+	Resolution      string    `gorm:"size:8;index:idx_instance_metric_time,priority:2;not null"`
+	Timestamp       time.Time `gorm:"index:idx_instance_metric_time,priority:3;not null"`
+	CPUPercent      float64
+	CPUPercentMax   float64
+	MemoryUsedMB    int64
+	MemoryUsedMaxMB int64
+	MemoryTotalMB   int64
+	DiskUsedMB      int64
+	DiskTotalMB     int64
+	Samples         int
+	CreatedAt       time.Time
+}
+
+func (instanceResourceMetricV1) TableName() string {
+	return "instance_resource_metrics"
+}
@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"time"
+
+	"oneclickvirt/service/database"
+
+	"gorm.io/gorm"
+)
+
+// 0002_task_holder 记录任务的执行实例并添加实例心跳表
+// 领导者据此只回收执行实例已停止的running任务，不影响其他实例上仍在执行的任务
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "task_holder",
+		Up:      taskHolderUp,
+		Down:    taskHolderDown,
+	})
+}
+
+// taskHolderV2 本迁移涉及的 tasks 表字段快照
+type taskHolderV2 struct {
+	HolderID string `gorm:"size:128;index"`
+}
+
+func (taskHolderV2) TableName() string {
+	return "tasks"
+}
+
+// instanceHeartbeatV2 本迁移创建的 instance_heartbeats 表结构快照
+type instanceHeartbeatV2 struct {
+	HolderID  string    `gorm:"primaryKey;type:varchar(128);comment:实例标识"`
+	StartedAt time.Time `gorm:"comment:实例启动时间"`
+	RenewedAt time.Time `gorm:"comment:最后续约时间"`
+	ExpiresAt time.Time `gorm:"index;comment:心跳过期时间"`
+}
+
+func (instanceHeartbeatV2) TableName() string {
+	return "instance_heartbeats"
+}
+
+func taskHolderUp(tx *gorm.DB) error {
+	// AutoMigrate 只补齐缺少的列和索引，中途失败后可安全地重新执行
+	return database.AutoMigrate(tx, &taskHolderV2{}, &instanceHeartbeatV2{})
+}
+
+func taskHolderDown(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if migrator.HasIndex(&taskHolderV2{}, "idx_tasks_holder_id") {
+		if err := migrator.DropIndex(&taskHolderV2{}, "idx_tasks_holder_id"); err != nil {
+			return err
+		}
+	}
+	if migrator.HasColumn(&taskHolderV2{}, "holder_id") {
+		if err := migrator.DropColumn(&taskHolderV2{}, "holder_id"); err != nil {
+			return err
+		}
+	}
+	return migrator.DropTable(&instanceHeartbeatV2{})
+}
@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"encoding/json"
+
+	"oneclickvirt/service/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 0003_instance_user_data 在实例上保存创建时用户提供的user-data
+// 此前user-data只记录在创建/重置任务的任务数据中，任务记录被定期清理后重置实例会丢失user-data，
+// 迁移时从仍保留的任务中回填
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "instance_user_data",
+		Up:      instanceUserDataUp,
+		Down:    instanceUserDataDown,
+	})
+}
+
+// instanceUserDataV3 本迁移涉及的 instances 表字段快照
+type instanceUserDataV3 struct {
+	UserData string `gorm:"type:text"`
+}
+
+func (instanceUserDataV3) TableName() string {
+	return "instances"
+}
+
+func instanceUserDataUp(tx *gorm.DB) error {
+	if err := database.AutoMigrate(tx, &instanceUserDataV3{}); err != nil {
+		return err
+	}
+
+	// 重置任务完成后会关联到新实例，按任务ID升序处理，同一实例以最新的任务为准
+	var tasks []struct {
+		ID         uint
+		InstanceID uint
+		TaskData   string
+	}
+	if err := tx.Table("tasks").
+		Select("id", "instance_id", "task_data").
+		Where("task_type IN ? AND status = ? AND instance_id IS NOT NULL", []string{"create", "reset"}, "completed").
+		Where("task_data LIKE ?", "%\"userData\"%").
+		Order("id").
+		Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	userData := make(map[uint]string)
+	for _, task := range tasks {
+		var data struct {
+			UserData string `json:"userData"`
+		}
+		if err := json.Unmarshal([]byte(task.TaskData), &data); err != nil {
+			logWarn("解析任务数据失败，跳过user-data回填", zap.Uint("taskId", task.ID), zap.Error(err))
+			continue
+		}
+		if data.UserData != "" {
+			userData[task.InstanceID] = data.UserData
+		}
+	}
+
+	for instanceID, data := range userData {
+		if err := tx.Table("instances").
+			Where("id = ? AND (user_data IS NULL OR user_data = '')", instanceID).
+			Update("user_data", data).Error; err != nil {
+			return err
+		}
+	}
+	if len(userData) > 0 {
+		logInfo("已从任务数据回填实例user-data", zap.Int("instances", len(userData)))
+	}
+	return nil
+}
+
+func instanceUserDataDown(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn(&instanceUserDataV3{}, "user_data") {
+		return nil
+	}
+	return tx.Migrator().DropColumn(&instanceUserDataV3{}, "user_data")
+}
@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"oneclickvirt/service/database"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 0004_domain_binding_index 域名绑定的唯一索引改为普通索引
+// 未验证的绑定不再占用域名，多个用户可同时等待验证同一域名，跨用户的唯一性只在验证通过时由程序保证
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "domain_binding_index",
+		Up:      domainBindingIndexUp,
+		Down:    domainBindingIndexDown,
+	})
+}
+
+const domainProtocolIndex = "idx_domain_protocol"
+
+// domainBindingV4 本迁移涉及的 instance_domains 表字段快照
+type domainBindingV4 struct {
+	Domain   string `gorm:"size:253;not null;index:idx_domain_protocol"`
+	Protocol string `gorm:"size:8;not null;index:idx_domain_protocol"`
+}
+
+func (domainBindingV4) TableName() string {
+	return "instance_domains"
+}
+
+// domainBindingV3 版本3的 instance_domains 表字段快照，回滚时恢复唯一索引
+type domainBindingV3 struct {
+	Domain   string `gorm:"size:253;not null;uniqueIndex:idx_domain_protocol"`
+	Protocol string `gorm:"size:8;not null;uniqueIndex:idx_domain_protocol"`
+}
+
+func (domainBindingV3) TableName() string {
+	return "instance_domains"
+}
+
+// domainProtocolIndexName 返回数据库中的索引名，非MySQL数据库的索引名带表名前缀（见 database.AutoMigrate）
+func domainProtocolIndexName(tx *gorm.DB) string {
+	if tx.Dialector.Name() == database.TypeMySQL {
+		return domainProtocolIndex
+	}
+	return "instance_domains_" + domainProtocolIndex
+}
+
+// dropDomainProtocolIndex 删除同名索引，重建时才能改变索引的唯一性
+func dropDomainProtocolIndex(tx *gorm.DB) error {
+	name := domainProtocolIndexName(tx)
+	if !tx.Migrator().HasIndex("instance_domains", name) {
+		return nil
+	}
+	return tx.Migrator().DropIndex("instance_domains", name)
+}
+
+func domainBindingIndexUp(tx *gorm.DB) error {
+	if err := dropDomainProtocolIndex(tx); err != nil {
+		return err
+	}
+	return database.AutoMigrate(tx, &domainBindingV4{})
+}
+
+func domainBindingIndexDown(tx *gorm.DB) error {
+	// 恢复唯一索引前删除重复的未验证绑定，同一域名和协议保留已验证或最早创建的一条
+	result := tx.Exec(`
+		DELETE FROM instance_domains
+		WHERE verified_at IS NULL AND id NOT IN (
+			SELECT keep_id FROM (
+				SELECT MIN(id) AS keep_id FROM instance_domains d
+				WHERE verified_at IS NOT NULL OR NOT EXISTS (
+					SELECT 1 FROM instance_domains v
+					WHERE v.domain = d.domain AND v.protocol = d.protocol AND v.verified_at IS NOT NULL
+				)
+				GROUP BY domain, protocol
+			) AS keep_ids
+		)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logWarn("已删除重复的未验证域名绑定", zap.Int64("deleted_rows", result.RowsAffected))
+	}
+
+	if err := dropDomainProtocolIndex(tx); err != nil {
+		return err
+	}
+	return database.AutoMigrate(tx, &domainBindingV3{})
+}
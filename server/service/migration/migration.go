@@ -0,0 +1,66 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrIrreversible 迁移不支持回滚
+var ErrIrreversible = errors.New("迁移不支持回滚")
+
+// ErrSchemaTooNew 数据库结构版本高于当前程序支持的最高版本
+var ErrSchemaTooNew = errors.New("数据库结构版本高于当前程序")
+
+// Migration 一次数据库结构迁移
+// 版本号全局唯一且只增不减，已发布的迁移不能再修改，结构变更需要新增迁移。
+// Up/Down 在事务中执行，但 MySQL 的 DDL 会隐式提交，因此 Up 需要能在中途失败后安全地重新执行
+// （如先用 Migrator().HasColumn 判断再添加列）。Down 为 nil 表示不可回滚
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// String 迁移标识，如 0001_baseline
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[uint]Migration)
+)
+
+// register 注册迁移，在各迁移文件的 init 中调用，版本号重复属于编码错误，直接panic
+func register(m Migration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if m.Version == 0 || m.Name == "" || m.Up == nil {
+		panic(fmt.Sprintf("迁移定义不完整: %s", m))
+	}
+	if exists, ok := registry[m.Version]; ok {
+		panic(fmt.Sprintf("迁移版本号重复: %s 与 %s", exists, m))
+	}
+	registry[m.Version] = m
+}
+
+// All 按版本号升序返回所有已注册的迁移
+func All() []Migration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	migrations := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations
+}
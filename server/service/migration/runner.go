@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"oneclickvirt/global"
+	systemModel "oneclickvirt/model/system"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   uint      `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"appliedAt"`
+	Unknown   bool      `json:"unknown"` // 数据库中已执行，但当前程序中没有该迁移（通常由更新版本的程序执行）
+}
+
+// Runner 迁移执行器
+type Runner struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewRunner 创建使用全部已注册迁移的执行器
+func NewRunner(db *gorm.DB) *Runner {
+	return &Runner{db: db, migrations: All()}
+}
+
+// LatestVersion 当前程序支持的最高结构版本
+func (r *Runner) LatestVersion() uint {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// CurrentVersion 数据库当前的结构版本（已执行迁移的最高版本），从未执行过迁移时为0
+func (r *Runner) CurrentVersion() (uint, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return 0, err
+	}
+	var current uint
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current, nil
+}
+
+// CheckCompatible 检查数据库结构版本是否被当前程序支持
+// 数据库由更新版本的程序迁移过时返回 ErrSchemaTooNew，此时继续运行可能写坏新结构的数据
+func (r *Runner) CheckCompatible() error {
+	current, err := r.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if latest := r.LatestVersion(); current > latest {
+		return fmt.Errorf("%w: 数据库结构版本为 %d，当前程序最高支持 %d，请升级程序或使用新版本程序执行 migrate down 回滚",
+			ErrSchemaTooNew, current, latest)
+	}
+	return nil
+}
+
+// Status 获取所有迁移的执行状态，按版本号升序
+func (r *Runner) Status() ([]Status, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations)+len(applied))
+	known := make(map[uint]bool, len(r.migrations))
+	for _, m := range r.migrations {
+		known[m.Version] = true
+		status := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		if !known[version] {
+			statuses = append(statuses, Status{
+				Version:   version,
+				Name:      record.Name,
+				Applied:   true,
+				AppliedAt: record.AppliedAt,
+				Unknown:   true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Up 按版本号顺序执行未完成的迁移，target 为0时执行全部，否则只执行到 target 版本（含）
+// 返回本次执行的迁移
+func (r *Runner) Up(target uint) ([]Migration, error) {
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+	if err := r.CheckCompatible(); err != nil {
+		return nil, err
+	}
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range r.migrations {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := r.apply(m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down 按版本号倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (r *Runner) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, nil
+	}
+	if err := r.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var records []systemModel.SchemaMigration
+	if err := r.db.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %v", err)
+	}
+
+	var done []Migration
+	for _, record := range records {
+		m, ok := r.find(record.Version)
+		if !ok {
+			return done, fmt.Errorf("迁移 %04d_%s 不在当前程序中，请使用执行该迁移的程序版本回滚", record.Version, record.Name)
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("%w: %s", ErrIrreversible, m)
+		}
+		if err := r.revert(m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// apply 在事务中执行单个迁移并写入迁移记录
+func (r *Runner) apply(m Migration) error {
+	start := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		// 多个实例同时启动时可能重复执行同一迁移，迁移本身可重复执行，记录已存在时忽略
+		record := systemModel.SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+	})
+	if err != nil {
+		return fmt.Errorf("执行迁移 %s 失败: %v", m, err)
+	}
+	logInfo("数据库迁移已执行", zap.String("migration", m.String()), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// revert 在事务中回滚单个迁移并删除迁移记录
+func (r *Runner) revert(m Migration) error {
+	start := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		return tx.Where("version = ?", m.Version).Delete(&systemModel.SchemaMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("回滚迁移 %s 失败: %v", m, err)
+	}
+	logInfo("数据库迁移已回滚", zap.String("migration", m.String()), zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// ensureTable 创建迁移记录表
+func (r *Runner) ensureTable() error {
+	if err := r.db.AutoMigrate(&systemModel.SchemaMigration{}); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %v", err)
+	}
+	return nil
+}
+
+// applied 读取已执行的迁移记录，迁移记录表不存在时视为没有执行过任何迁移
+func (r *Runner) applied() (map[uint]systemModel.SchemaMigration, error) {
+	applied := make(map[uint]systemModel.SchemaMigration)
+	if !r.db.Migrator().HasTable(&systemModel.SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var records []systemModel.SchemaMigration
+	if err := r.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %v", err)
+	}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// find 按版本号查找迁移
+func (r *Runner) find(version uint) (Migration, bool) {
+	for _, m := range r.migrations {
+		if m.Version == version {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// logInfo 记录迁移日志，命令行子命令初始化日志前也可安全调用
+func logInfo(msg string, fields ...zap.Field) {
+	if global.APP_LOG != nil {
+		global.APP_LOG.Info(msg, fields...)
+	}
+}
+
+// logWarn 记录迁移警告日志
+func logWarn(msg string, fields ...zap.Field) {
+	if global.APP_LOG != nil {
+		global.APP_LOG.Warn(msg, fields...)
+	}
+}
@@ -0,0 +1,160 @@
+//go:build cgo
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	systemModel "oneclickvirt/model/system"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/migration.db?_loc=auto"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	return db
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "create_widgets",
+			Up:      func(tx *gorm.DB) error { return tx.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY)").Error },
+			Down:    func(tx *gorm.DB) error { return tx.Exec("DROP TABLE widgets").Error },
+		},
+		{
+			Version: 2,
+			Name:    "add_widget_name",
+			Up:      func(tx *gorm.DB) error { return tx.Exec("ALTER TABLE widgets ADD COLUMN name TEXT").Error },
+			Down:    func(tx *gorm.DB) error { return tx.Exec("ALTER TABLE widgets DROP COLUMN name").Error },
+		},
+		{
+			Version: 3,
+			Name:    "seed_widgets",
+			Up:      func(tx *gorm.DB) error { return tx.Exec("INSERT INTO widgets (name) VALUES ('a')").Error },
+		},
+	}
+}
+
+func TestRunnerUpDown(t *testing.T) {
+	db := openTestDB(t)
+	runner := &Runner{db: db, migrations: testMigrations()}
+
+	done, err := runner.Up(2)
+	if err != nil || len(done) != 2 {
+		t.Fatalf("执行到版本2失败: done=%v err=%v", done, err)
+	}
+	if current, _ := runner.CurrentVersion(); current != 2 {
+		t.Fatalf("当前版本应为2，实际为 %d", current)
+	}
+
+	done, err = runner.Up(0)
+	if err != nil || len(done) != 1 || done[0].Version != 3 {
+		t.Fatalf("执行剩余迁移失败: done=%v err=%v", done, err)
+	}
+	if done, _ := runner.Up(0); len(done) != 0 {
+		t.Errorf("重复执行不应再执行迁移: %v", done)
+	}
+
+	// 版本3不可回滚，回滚中止且记录保留
+	if _, err := runner.Down(1); !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("不可回滚的迁移应返回 ErrIrreversible，实际: %v", err)
+	}
+
+	runner.migrations[2].Down = func(tx *gorm.DB) error { return tx.Exec("DELETE FROM widgets").Error }
+	done, err = runner.Down(2)
+	if err != nil || len(done) != 2 || done[0].Version != 3 || done[1].Version != 2 {
+		t.Fatalf("应按倒序回滚版本3和2: done=%v err=%v", done, err)
+	}
+	if db.Migrator().HasColumn("widgets", "name") {
+		t.Error("回滚版本2后 name 列应被删除")
+	}
+
+	statuses, err := runner.Status()
+	if err != nil {
+		t.Fatalf("查询状态失败: %v", err)
+	}
+	applied := []bool{true, false, false}
+	for i, s := range statuses {
+		if s.Applied != applied[i] {
+			t.Errorf("版本 %d 的执行状态应为 %v", s.Version, applied[i])
+		}
+	}
+}
+
+func TestRunnerRefusesNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	runner := &Runner{db: db, migrations: testMigrations()}
+	if _, err := runner.Up(0); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	// 模拟更新版本的程序执行过版本4
+	db.Create(&systemModel.SchemaMigration{Version: 4, Name: "from_newer_release"})
+
+	if err := runner.CheckCompatible(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("数据库版本高于程序时应返回 ErrSchemaTooNew，实际: %v", err)
+	}
+	if _, err := runner.Up(0); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("数据库版本高于程序时不应执行迁移，实际: %v", err)
+	}
+	if _, err := runner.Down(1); err == nil {
+		t.Fatal("回滚当前程序中不存在的迁移应失败")
+	}
+
+	statuses, _ := runner.Status()
+	if last := statuses[len(statuses)-1]; last.Version != 4 || !last.Unknown {
+		t.Errorf("未知迁移应出现在状态中: %+v", last)
+	}
+}
+
+func TestRegisteredMigrations(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db)
+	if runner.LatestVersion() == 0 {
+		t.Fatal("应至少注册基线迁移")
+	}
+	if _, err := runner.Up(0); err != nil {
+		t.Fatalf("在空数据库上执行全部迁移失败: %v", err)
+	}
+	// 基线迁移可在已有表结构上重复执行
+	if err := baselineUp(db); err != nil {
+		t.Fatalf("重复执行基线迁移失败: %v", err)
+	}
+
+	// 基线之后的迁移均可回滚并重新执行
+	steps := len(runner.migrations) - 1
+	if _, err := runner.Down(steps); err != nil {
+		t.Fatalf("回滚基线之后的迁移失败: %v", err)
+	}
+	if current, _ := runner.CurrentVersion(); current != 1 {
+		t.Fatalf("回滚后结构版本应为1，实际为 %d", current)
+	}
+	if _, err := runner.Up(0); err != nil {
+		t.Fatalf("回滚后重新执行迁移失败: %v", err)
+	}
+
+	// 基线同样可回滚，全部回滚后回到空数据库
+	if _, err := runner.Down(len(runner.migrations)); err != nil {
+		t.Fatalf("回滚全部迁移失败: %v", err)
+	}
+	if current, _ := runner.CurrentVersion(); current != 0 {
+		t.Fatalf("全部回滚后结构版本应为0，实际为 %d", current)
+	}
+	if db.Migrator().HasTable("users") || db.Migrator().HasTable("tasks") {
+		t.Error("回滚基线后业务表应被删除")
+	}
+	if _, err := runner.Up(0); err != nil {
+		t.Fatalf("全部回滚后重新执行迁移失败: %v", err)
+	}
+}
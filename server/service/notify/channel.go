@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/system"
+)
+
+// Channel 通知渠道驱动
+type Channel interface {
+	Name() string
+	// Available 渠道是否已完成配置
+	Available() bool
+	// Send 发送通知，to 为渠道相关的接收地址（邮箱、Chat ID）
+	Send(to, subject, body string) error
+}
+
+var (
+	channelsMu sync.RWMutex
+	channels   = map[string]Channel{}
+)
+
+// RegisterChannel 注册通知渠道，同名渠道会被覆盖
+func RegisterChannel(ch Channel) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	channels[ch.Name()] = ch
+}
+
+// GetChannel 获取已注册的通知渠道
+func GetChannel(name string) (Channel, bool) {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	ch, ok := channels[name]
+	return ch, ok
+}
+
+func init() {
+	RegisterChannel(smtpChannel{})
+	RegisterChannel(&telegramChannel{
+		apiBase: "https://api.telegram.org",
+		client:  &http.Client{Timeout: 15 * time.Second},
+	})
+}
+
+// smtpChannel 通过 auth 配置中的SMTP服务器发送邮件，465端口使用隐式TLS，其余端口在服务器支持时使用STARTTLS
+type smtpChannel struct{}
+
+func (smtpChannel) Name() string { return system.NotifyChannelEmail }
+
+func (smtpChannel) Available() bool {
+	cfg := global.APP_CONFIG.Auth
+	return cfg.EmailSMTPHost != "" && cfg.EmailUsername != ""
+}
+
+func (smtpChannel) Send(to, subject, body string) error {
+	cfg := global.APP_CONFIG.Auth
+	port := cfg.EmailSMTPPort
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(cfg.EmailSMTPHost, strconv.Itoa(port))
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + cfg.EmailUsername + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	auth := smtp.PlainAuth("", cfg.EmailUsername, cfg.EmailPassword, cfg.EmailSMTPHost)
+	if port != 465 {
+		return smtp.SendMail(addr, auth, cfg.EmailUsername, []string{to}, msg.Bytes())
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 15 * time.Second}, "tcp", addr,
+		&tls.Config{ServerName: cfg.EmailSMTPHost})
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %v", err)
+	}
+	client, err := smtp.NewClient(conn, cfg.EmailSMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("创建SMTP客户端失败: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Auth(auth); err != nil {
+		return fmt.Errorf("SMTP认证失败: %v", err)
+	}
+	if err := client.Mail(cfg.EmailUsername); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// telegramChannel 通过Telegram Bot API的sendMessage发送消息
+type telegramChannel struct {
+	apiBase string
+	client  *http.Client
+}
+
+func (*telegramChannel) Name() string { return system.NotifyChannelTelegram }
+
+func (*telegramChannel) Available() bool {
+	return global.APP_CONFIG.Auth.TelegramBotToken != ""
+}
+
+func (t *telegramChannel) Send(to, subject, body string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  to,
+		"text":                     subject + "\n\n" + body,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.apiBase, global.APP_CONFIG.Auth.TelegramBotToken)
+	resp, err := t.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		// 错误信息中包含带Token的URL，不直接返回
+		var urlErr interface{ Timeout() bool }
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return errors.New("请求Telegram Bot API超时")
+		}
+		return errors.New("请求Telegram Bot API失败")
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("解析Telegram响应失败: HTTP %d", resp.StatusCode)
+	}
+	if !result.OK {
+		return fmt.Errorf("Telegram发送失败: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+)
+
+// expiryTimeLayout 提醒中到期时间的显示格式
+const expiryTimeLayout = "2006-01-02 15:04"
+
+// RunExpiryReminders 为即将到期的用户、实例和节点发送提醒
+// 每个对象的每个到期时间只提醒一次，到期时间被延长后会重新提醒；节点到期提醒发送给所有管理员
+func (s *Service) RunExpiryReminders() {
+	if !global.APP_CONFIG.Notify.Enabled {
+		return
+	}
+	days := global.APP_CONFIG.Notify.ExpiryReminderDays
+	if days <= 0 {
+		days = 3
+	}
+	now := time.Now()
+	deadline := now.AddDate(0, 0, days)
+
+	var users []userModel.User
+	if err := global.APP_DB.Select("id, expires_at").
+		Where("expires_at IS NOT NULL AND expires_at > ? AND expires_at <= ? AND status = ?", now, deadline, 1).
+		Find(&users).Error; err != nil {
+		global.APP_LOG.Error("查询即将到期的用户失败", zap.Error(err))
+	}
+	for _, u := range users {
+		s.sendReminder(u.ID, system.NotifyEventUserExpiring,
+			fmt.Sprintf("expiry:user:%d:%d", u.ID, u.ExpiresAt.Unix()),
+			*u.ExpiresAt, now, nil)
+	}
+
+	var instances []providerModel.Instance
+	if err := global.APP_DB.Select("id, name, user_id, expires_at").
+		Where("expires_at IS NOT NULL AND expires_at > ? AND expires_at <= ? AND is_frozen = ?", now, deadline, false).
+		Where("status NOT IN ?", []string{"deleting", "deleted", "failed"}).
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Error("查询即将到期的实例失败", zap.Error(err))
+	}
+	for _, inst := range instances {
+		s.sendReminder(inst.UserID, system.NotifyEventInstanceExpiring,
+			fmt.Sprintf("expiry:instance:%d:%d", inst.ID, inst.ExpiresAt.Unix()),
+			*inst.ExpiresAt, now, map[string]interface{}{
+				"InstanceID":   inst.ID,
+				"InstanceName": inst.Name,
+			})
+	}
+
+	var providers []providerModel.Provider
+	if err := global.APP_DB.Select("id, name, expires_at").
+		Where("expires_at IS NOT NULL AND expires_at > ? AND expires_at <= ? AND is_frozen = ?", now, deadline, false).
+		Find(&providers).Error; err != nil {
+		global.APP_LOG.Error("查询即将到期的节点失败", zap.Error(err))
+	}
+	if len(providers) == 0 {
+		return
+	}
+	var adminIDs []uint
+	if err := global.APP_DB.Model(&userModel.User{}).
+		Where("user_type = ? AND status = ?", "admin", 1).
+		Pluck("id", &adminIDs).Error; err != nil {
+		global.APP_LOG.Error("查询管理员失败", zap.Error(err))
+		return
+	}
+	for _, p := range providers {
+		for _, adminID := range adminIDs {
+			s.sendReminder(adminID, system.NotifyEventProviderExpiring,
+				fmt.Sprintf("expiry:provider:%d:%d", p.ID, p.ExpiresAt.Unix()),
+				*p.ExpiresAt, now, map[string]interface{}{
+					"ProviderID":   p.ID,
+					"ProviderName": p.Name,
+				})
+		}
+	}
+}
+
+// sendReminder 发送一条到期提醒
+func (s *Service) sendReminder(userID uint, event, dedupKey string, expiresAt, now time.Time, data map[string]interface{}) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["ExpiresAt"] = expiresAt.Format(expiryTimeLayout)
+	data["Days"] = int(math.Ceil(expiresAt.Sub(now).Hours() / 24))
+
+	if _, err := s.Send(userID, event, data, dedupKey); err != nil {
+		global.APP_LOG.Warn("发送到期提醒失败",
+			zap.Uint("userId", userID),
+			zap.String("event", event),
+			zap.Error(err))
+	}
+}
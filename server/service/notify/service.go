@@ -0,0 +1,273 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service 用户通知服务，按用户的通知设置通过邮件和Telegram发送模板化通知，并记录发送结果
+type Service struct{}
+
+// NewService 创建通知服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// Notify 异步发送通知，调用方无需关心发送结果
+func Notify(userID uint, event string, data map[string]interface{}) {
+	if !global.APP_CONFIG.Notify.Enabled || global.APP_DB == nil || userID == 0 {
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				global.APP_LOG.Error("发送通知goroutine panic", zap.Any("panic", r), zap.Stack("stack"))
+			}
+		}()
+		if _, err := NewService().Send(userID, event, data, ""); err != nil {
+			global.APP_LOG.Warn("发送通知失败",
+				zap.Uint("userId", userID),
+				zap.String("event", event),
+				zap.Error(err))
+		}
+	}()
+}
+
+// eventEnabled 用户是否订阅了该事件
+func eventEnabled(setting *userModel.NotificationSetting, event string) bool {
+	switch event {
+	case system.NotifyEventUserExpiring, system.NotifyEventInstanceExpiring, system.NotifyEventProviderExpiring:
+		return setting.ExpiryReminder
+	case system.NotifyEventFrozen:
+		return setting.FrozenNotice
+	case system.NotifyEventTrafficLimited:
+		return setting.TrafficNotice
+	case system.NotifyEventTaskFailed:
+		return setting.TaskFailNotice
+	case system.NotifyEventTest:
+		return true
+	}
+	return false
+}
+
+// Send 同步发送通知，返回成功发送的渠道数
+// dedupKey 不为空时，同一用户已成功发送过相同去重键的通知将被跳过
+func (s *Service) Send(userID uint, event string, data map[string]interface{}, dedupKey string) (int, error) {
+	var setting userModel.NotificationSetting
+	if err := global.APP_DB.Where("user_id = ?", userID).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("获取通知设置失败: %v", err)
+	}
+	if !eventEnabled(&setting, event) {
+		return 0, nil
+	}
+
+	if dedupKey != "" {
+		var count int64
+		global.APP_DB.Model(&system.NotificationLog{}).
+			Where("user_id = ? AND dedup_key = ? AND status = ?", userID, dedupKey, system.NotifyStatusSent).
+			Count(&count)
+		if count > 0 {
+			return 0, nil
+		}
+	}
+
+	var u userModel.User
+	if err := global.APP_DB.Select("id, username, email").First(&u, userID).Error; err != nil {
+		return 0, errors.New("用户不存在")
+	}
+
+	subjectText, bodyText, enabled, err := loadTemplate(event)
+	if err != nil {
+		return 0, err
+	}
+	if !enabled {
+		return 0, nil
+	}
+
+	// 模板中引用的变量缺失时渲染为空字符串，而不是 <no value>
+	vars := make(map[string]interface{}, len(data)+1)
+	for _, v := range builtinTemplates[event].Variables {
+		vars[v] = ""
+	}
+	for k, v := range data {
+		vars[k] = v
+	}
+	vars["Username"] = u.Username
+
+	subject, err := render("subject", subjectText, vars)
+	if err != nil {
+		return 0, fmt.Errorf("渲染通知标题失败: %v", err)
+	}
+	body, err := render("body", bodyText, vars)
+	if err != nil {
+		return 0, fmt.Errorf("渲染通知正文失败: %v", err)
+	}
+
+	targets := map[string]string{}
+	if setting.EmailEnabled && u.Email != "" {
+		targets[system.NotifyChannelEmail] = u.Email
+	}
+	if setting.TelegramEnabled && setting.TelegramChatID != "" {
+		targets[system.NotifyChannelTelegram] = setting.TelegramChatID
+	}
+
+	sent := 0
+	var lastErr error
+	for name, to := range targets {
+		ch, ok := GetChannel(name)
+		if !ok || !ch.Available() {
+			continue
+		}
+
+		record := system.NotificationLog{
+			UserID:    userID,
+			Event:     event,
+			Channel:   name,
+			Recipient: to,
+			Subject:   subject,
+			Content:   body,
+			Status:    system.NotifyStatusSent,
+			DedupKey:  dedupKey,
+		}
+		if global.APP_CONFIG.System.Env == "development" {
+			global.APP_LOG.Info("开发环境模拟发送通知",
+				zap.String("channel", name),
+				zap.String("to", to),
+				zap.String("subject", subject))
+		} else if err := ch.Send(to, subject, body); err != nil {
+			record.Status = system.NotifyStatusFailed
+			record.Error = truncate(err.Error(), 512)
+			lastErr = fmt.Errorf("%s发送失败: %v", name, err)
+		}
+		if record.Status == system.NotifyStatusSent {
+			sent++
+		}
+		if err := global.APP_DB.Create(&record).Error; err != nil {
+			global.APP_LOG.Error("保存通知发送记录失败", zap.Uint("userId", userID), zap.Error(err))
+		}
+	}
+
+	if sent == 0 && lastErr != nil {
+		return 0, lastErr
+	}
+	return sent, nil
+}
+
+// GetSetting 获取用户通知设置，没有记录时返回默认设置（全部关闭）
+func (s *Service) GetSetting(userID uint) (*userModel.NotificationSetting, error) {
+	var setting userModel.NotificationSetting
+	err := global.APP_DB.Where("user_id = ?", userID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &userModel.NotificationSetting{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取通知设置失败: %v", err)
+	}
+	return &setting, nil
+}
+
+// UpdateSetting 更新用户通知设置
+func (s *Service) UpdateSetting(userID uint, req userModel.UpdateNotificationSettingRequest) (*userModel.NotificationSetting, error) {
+	if req.TelegramEnabled && req.TelegramChatID == "" {
+		return nil, errors.New("启用Telegram通知需要填写Chat ID")
+	}
+
+	var setting userModel.NotificationSetting
+	if err := global.APP_DB.Where(userModel.NotificationSetting{UserID: userID}).FirstOrCreate(&setting).Error; err != nil {
+		return nil, fmt.Errorf("获取通知设置失败: %v", err)
+	}
+	if err := global.APP_DB.Model(&setting).Updates(map[string]interface{}{
+		"email_enabled":    req.EmailEnabled,
+		"telegram_enabled": req.TelegramEnabled,
+		"telegram_chat_id": req.TelegramChatID,
+		"expiry_reminder":  req.ExpiryReminder,
+		"frozen_notice":    req.FrozenNotice,
+		"traffic_notice":   req.TrafficNotice,
+		"task_fail_notice": req.TaskFailNotice,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("更新通知设置失败: %v", err)
+	}
+	return s.GetSetting(userID)
+}
+
+// SendTest 向用户已启用的渠道发送测试通知
+func (s *Service) SendTest(userID uint) error {
+	sent, err := s.Send(userID, system.NotifyEventTest, nil, "")
+	if err != nil {
+		return err
+	}
+	if sent == 0 {
+		return errors.New("没有可用的通知渠道，请确认已启用并填写接收地址，且管理员已配置对应渠道")
+	}
+	return nil
+}
+
+// ListLogs 分页获取通知发送记录，userID为0时返回全部用户
+func (s *Service) ListLogs(userID uint, event, channel, status string, page, pageSize int) ([]system.NotificationLog, int64, error) {
+	query := global.APP_DB.Model(&system.NotificationLog{})
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if event != "" {
+		query = query.Where("event = ?", event)
+	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计通知记录失败: %v", err)
+	}
+
+	var logs []system.NotificationLog
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取通知记录失败: %v", err)
+	}
+	return logs, total, nil
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// frozenReasonText 冻结原因的中文说明
+func frozenReasonText(reason string) string {
+	switch reason {
+	case "expired":
+		return "已到期"
+	case "node_frozen":
+		return "所在节点已冻结"
+	case "insufficient_balance":
+		return "余额不足"
+	case "manual", "":
+		return "管理员手动冻结"
+	}
+	return reason
+}
+
+// NotifyFrozen 异步发送冻结通知，target 为被冻结对象的描述，如“您的账号”“实例 xxx”
+func NotifyFrozen(userID uint, target, reason string) {
+	Notify(userID, system.NotifyEventFrozen, map[string]interface{}{
+		"Target": target,
+		"Reason": frozenReasonText(reason),
+	})
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/system"
+
+	"gorm.io/gorm"
+)
+
+// builtinTemplate 内置通知模板及可用变量
+type builtinTemplate struct {
+	Subject   string
+	Body      string
+	Variables []string
+}
+
+// builtinTemplates 内置模板，所有事件都可使用 Username 变量
+var builtinTemplates = map[string]builtinTemplate{
+	system.NotifyEventUserExpiring: {
+		Subject:   "账号即将到期",
+		Body:      "您好 {{.Username}}，您的账号将于 {{.ExpiresAt}} 到期（剩余 {{.Days}} 天），到期后账号将被禁用，请及时续期。",
+		Variables: []string{"Username", "ExpiresAt", "Days"},
+	},
+	system.NotifyEventInstanceExpiring: {
+		Subject:   "实例 {{.InstanceName}} 即将到期",
+		Body:      "您好 {{.Username}}，您的实例 {{.InstanceName}} 将于 {{.ExpiresAt}} 到期（剩余 {{.Days}} 天），到期后实例将被冻结，请及时续期。",
+		Variables: []string{"Username", "InstanceID", "InstanceName", "ExpiresAt", "Days"},
+	},
+	system.NotifyEventProviderExpiring: {
+		Subject:   "节点 {{.ProviderName}} 即将到期",
+		Body:      "节点 {{.ProviderName}} 将于 {{.ExpiresAt}} 到期（剩余 {{.Days}} 天），到期后节点及其实例将被冻结，请及时续期。",
+		Variables: []string{"Username", "ProviderID", "ProviderName", "ExpiresAt", "Days"},
+	},
+	system.NotifyEventFrozen: {
+		Subject:   "{{.Target}}已被冻结",
+		Body:      "您好 {{.Username}}，{{.Target}}已被冻结，原因：{{.Reason}}。如有疑问请联系管理员。",
+		Variables: []string{"Username", "Target", "Reason"},
+	},
+	system.NotifyEventTrafficLimited: {
+		Subject:   "实例 {{.InstanceName}} 流量超限已停止",
+		Body:      "您好 {{.Username}}，您的实例 {{.InstanceName}} 因流量超出限制已被停止。{{.Message}}",
+		Variables: []string{"Username", "InstanceID", "InstanceName", "Message"},
+	},
+	system.NotifyEventTaskFailed: {
+		Subject:   "任务执行失败",
+		Body:      "您好 {{.Username}}，任务 #{{.TaskID}}（{{.TaskType}}）执行失败：{{.ErrorMessage}}",
+		Variables: []string{"Username", "TaskID", "TaskType", "InstanceID", "ErrorMessage"},
+	},
+	system.NotifyEventTest: {
+		Subject:   "测试通知",
+		Body:      "您好 {{.Username}}，这是一条测试通知，收到说明通知渠道配置正确。",
+		Variables: []string{"Username"},
+	},
+}
+
+// TemplateInfo 通知模板信息，未自定义时返回内置模板
+type TemplateInfo struct {
+	Event      string   `json:"event"`
+	Subject    string   `json:"subject"`
+	Body       string   `json:"body"`
+	Enabled    bool     `json:"enabled"`
+	Customized bool     `json:"customized"` // 是否为管理员自定义模板
+	Variables  []string `json:"variables"`  // 可用变量
+}
+
+// ListTemplates 获取所有事件的通知模板
+func (s *Service) ListTemplates() ([]TemplateInfo, error) {
+	var custom []system.NotificationTemplate
+	if err := global.APP_DB.Find(&custom).Error; err != nil {
+		return nil, fmt.Errorf("获取通知模板失败: %v", err)
+	}
+	byEvent := make(map[string]system.NotificationTemplate, len(custom))
+	for _, t := range custom {
+		byEvent[t.Event] = t
+	}
+
+	result := make([]TemplateInfo, 0, len(system.NotifyEvents))
+	for _, event := range system.NotifyEvents {
+		builtin := builtinTemplates[event]
+		info := TemplateInfo{
+			Event:     event,
+			Subject:   builtin.Subject,
+			Body:      builtin.Body,
+			Enabled:   true,
+			Variables: builtin.Variables,
+		}
+		if t, ok := byEvent[event]; ok {
+			info.Subject = t.Subject
+			info.Body = t.Body
+			info.Enabled = t.Enabled
+			info.Customized = true
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// UpdateTemplate 保存事件的自定义模板
+func (s *Service) UpdateTemplate(event string, req adminModel.NotificationTemplateRequest) (*system.NotificationTemplate, error) {
+	if _, ok := builtinTemplates[event]; !ok {
+		return nil, errors.New("不支持的通知事件")
+	}
+	if _, err := template.New("subject").Parse(req.Subject); err != nil {
+		return nil, fmt.Errorf("标题模板语法错误: %v", err)
+	}
+	if _, err := template.New("body").Parse(req.Body); err != nil {
+		return nil, fmt.Errorf("正文模板语法错误: %v", err)
+	}
+
+	var tpl system.NotificationTemplate
+	err := global.APP_DB.Where("event = ?", event).First(&tpl).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取通知模板失败: %v", err)
+	}
+	tpl.Event = event
+	tpl.Subject = req.Subject
+	tpl.Body = req.Body
+	tpl.Enabled = req.Enabled
+	if err := global.APP_DB.Save(&tpl).Error; err != nil {
+		return nil, fmt.Errorf("保存通知模板失败: %v", err)
+	}
+	return &tpl, nil
+}
+
+// ResetTemplate 删除自定义模板，恢复为内置模板
+func (s *Service) ResetTemplate(event string) error {
+	if _, ok := builtinTemplates[event]; !ok {
+		return errors.New("不支持的通知事件")
+	}
+	if err := global.APP_DB.Where("event = ?", event).Delete(&system.NotificationTemplate{}).Error; err != nil {
+		return fmt.Errorf("恢复默认模板失败: %v", err)
+	}
+	return nil
+}
+
+// loadTemplate 获取事件当前生效的模板，返回的enabled为false时不发送
+func loadTemplate(event string) (subject, body string, enabled bool, err error) {
+	builtin, ok := builtinTemplates[event]
+	if !ok {
+		return "", "", false, fmt.Errorf("不支持的通知事件: %s", event)
+	}
+
+	var tpl system.NotificationTemplate
+	err = global.APP_DB.Where("event = ?", event).First(&tpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return builtin.Subject, builtin.Body, true, nil
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("获取通知模板失败: %v", err)
+	}
+	return tpl.Subject, tpl.Body, tpl.Enabled, nil
+}
+
+// render 渲染模板
+func render(name, text string, data map[string]interface{}) (string, error) {
+	tpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"oneclickvirt/model/system"
+)
+
+// TestBuiltinTemplates 测试所有事件都有内置模板，且声明的变量足以完整渲染
+func TestBuiltinTemplates(t *testing.T) {
+	for _, event := range system.NotifyEvents {
+		builtin, ok := builtinTemplates[event]
+		if !ok {
+			t.Errorf("事件 %s 缺少内置模板", event)
+			continue
+		}
+
+		vars := map[string]interface{}{}
+		for _, v := range builtin.Variables {
+			vars[v] = "x"
+		}
+		for name, text := range map[string]string{"subject": builtin.Subject, "body": builtin.Body} {
+			out, err := render(name, text, vars)
+			if err != nil {
+				t.Errorf("事件 %s 的%s渲染失败: %v", event, name, err)
+				continue
+			}
+			if strings.Contains(out, "<no value>") {
+				t.Errorf("事件 %s 的%s引用了未声明的变量: %s", event, name, out)
+			}
+		}
+	}
+}
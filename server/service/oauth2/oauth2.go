@@ -14,9 +14,11 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	authModel "oneclickvirt/model/auth"
 	"oneclickvirt/model/common"
 	oauth2Model "oneclickvirt/model/oauth2"
 	"oneclickvirt/model/user"
+	authService "oneclickvirt/service/auth"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
@@ -378,11 +380,12 @@ func (s *Service) GetUserLevel(provider *oauth2Model.OAuth2Provider, trustLevel
 }
 
 // HandleCallback 处理OAuth2回调
-func (s *Service) HandleCallback(providerID uint, code string) (*user.User, string, error) {
+// 用户启用两步验证时不签发JWT令牌，返回两步验证挑战
+func (s *Service) HandleCallback(providerID uint, code string) (*user.User, string, *authModel.TwoFactorChallenge, error) {
 	// 获取提供商配置
 	provider, err := s.GetProviderByID(providerID)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
 	// 交换授权码获取令牌
@@ -390,21 +393,21 @@ func (s *Service) HandleCallback(providerID uint, code string) (*user.User, stri
 	token, err := oauth2Cfg.Exchange(context.Background(), code)
 	if err != nil {
 		global.APP_LOG.Error("交换OAuth2令牌失败", zap.Error(err))
-		return nil, "", common.NewError(common.CodeOAuth2Failed, "授权失败")
+		return nil, "", nil, common.NewError(common.CodeOAuth2Failed, "授权失败")
 	}
 
 	// 获取用户信息
 	userInfoData, err := s.FetchUserInfo(provider, token)
 	if err != nil {
 		global.APP_LOG.Error("获取OAuth2用户信息失败", zap.Error(err))
-		return nil, "", common.NewError(common.CodeOAuth2Failed, "获取用户信息失败")
+		return nil, "", nil, common.NewError(common.CodeOAuth2Failed, "获取用户信息失败")
 	}
 
 	// 提取用户信息
 	userInfo, err := s.ExtractUserInfo(provider, userInfoData)
 	if err != nil {
 		global.APP_LOG.Error("提取OAuth2用户信息失败", zap.Error(err))
-		return nil, "", common.NewError(common.CodeOAuth2Failed, "用户信息格式错误")
+		return nil, "", nil, common.NewError(common.CodeOAuth2Failed, "用户信息格式错误")
 	}
 
 	// 检查用户是否已存在
@@ -414,26 +417,15 @@ func (s *Service) HandleCallback(providerID uint, code string) (*user.User, stri
 
 	// 如果用户不存在且已达到注册限制，拒绝注册
 	if !isUserExists && provider.MaxRegistrations > 0 && provider.CurrentRegistrations >= provider.MaxRegistrations {
-		return nil, "", common.NewError(common.CodeOAuth2RegistrationLimit, fmt.Sprintf("%s 注册已达限制", provider.DisplayName))
+		return nil, "", nil, common.NewError(common.CodeOAuth2RegistrationLimit, fmt.Sprintf("%s 注册已达限制", provider.DisplayName))
 	}
 
 	// 查找或创建用户
 	usr, isNewUser, err := s.FindOrCreateUser(provider, userInfo)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
-	// 生成JWT令牌
-	jwtToken, err := utils.GenerateToken(usr.ID, usr.Username, usr.UserType)
-	if err != nil {
-		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
-		return nil, "", common.NewError(common.CodeInternalError, "生成令牌失败")
-	}
-
-	// 更新最后登录时间
-	now := time.Now()
-	global.APP_DB.Model(usr).Update("last_login_at", now)
-
 	// 如果是新用户，更新提供商的注册计数
 	if isNewUser {
 		global.APP_DB.Model(&oauth2Model.OAuth2Provider{}).
@@ -444,7 +436,28 @@ func (s *Service) HandleCallback(providerID uint, code string) (*user.User, stri
 			})
 	}
 
-	return usr, jwtToken, nil
+	// 启用两步验证的用户需完成第二步后才签发令牌
+	twoFactorService := authService.TwoFactorService{}
+	challenge, err := twoFactorService.Challenge(usr)
+	if err != nil {
+		return nil, "", nil, common.NewError(common.CodeInternalError, "生成令牌失败")
+	}
+	if challenge != nil {
+		return usr, "", challenge, nil
+	}
+
+	// 生成JWT令牌
+	jwtToken, err := utils.GenerateToken(usr.ID, usr.Username, usr.UserType)
+	if err != nil {
+		global.APP_LOG.Error("生成JWT令牌失败", zap.Error(err))
+		return nil, "", nil, common.NewError(common.CodeInternalError, "生成令牌失败")
+	}
+
+	// 更新最后登录时间
+	now := time.Now()
+	global.APP_DB.Model(usr).Update("last_login_at", now)
+
+	return usr, jwtToken, nil, nil
 }
 
 // FindOrCreateUser 查找或创建用户
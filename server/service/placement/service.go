@@ -0,0 +1,252 @@
+package placement
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	resourceModel "oneclickvirt/model/resource"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoAvailableProvider 没有满足条件的节点
+var ErrNoAvailableProvider = errors.New("没有满足条件的可用节点，请调整地区或规格后重试")
+
+// Service 自动选择节点（调度）服务
+type Service struct{}
+
+// NewService 创建调度服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// Request 调度请求
+type Request struct {
+	InstanceType  string   // container 或 vm
+	ProviderTypes []string // 镜像支持的Provider类型
+	Architecture  string   // 镜像架构，为空时不限制
+	Region        string   // 地区，为空时不限制
+	Country       string   // 国家名称或国家代码，为空时不限制
+	CPU           int
+	Memory        int64 // MB
+	Disk          int64 // MB
+}
+
+// Candidate 通过过滤的候选节点，比例均按放入本实例后计算，取值0~1
+type Candidate struct {
+	Provider        providerModel.Provider
+	FreeCPURatio    float64 // 剩余CPU比例
+	FreeMemoryRatio float64 // 剩余内存比例
+	FreeDiskRatio   float64 // 剩余磁盘比例
+	FreeSlotRatio   float64 // 剩余实例名额比例，未限制数量时为1
+	Score           float64
+}
+
+// SelectCandidates 按当前配置的调度策略返回排好序的候选节点
+// 过滤条件：允许申领、未冻结、未过期、未因流量受限、健康状态正常、支持实例类型和镜像、资源预算充足
+func (s *Service) SelectCandidates(req Request) ([]Candidate, error) {
+	query := global.APP_DB.Model(&providerModel.Provider{}).
+		Where("status IN ? AND allow_claim = ? AND is_frozen = ? AND traffic_limited = ?",
+			[]string{"active", "partial"}, true, false, false).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	if req.InstanceType == "vm" {
+		query = query.Where("virtual_machine_enabled = ?", true)
+	} else {
+		query = query.Where("container_enabled = ?", true)
+	}
+	if req.Region != "" {
+		query = query.Where("region = ?", req.Region)
+	}
+	if req.Country != "" {
+		query = query.Where("country = ? OR country_code = ?", req.Country, strings.ToUpper(req.Country))
+	}
+
+	var providers []providerModel.Provider
+	if err := query.Limit(1000).Find(&providers).Error; err != nil {
+		return nil, fmt.Errorf("查询节点失败: %v", err)
+	}
+	if len(providers) == 0 {
+		return nil, ErrNoAvailableProvider
+	}
+
+	reserved, err := s.loadActiveReservations(providers)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := GetStrategy(global.APP_CONFIG.Placement)
+	resourceService := &resources.ResourceService{}
+
+	candidates := make([]Candidate, 0, len(providers))
+	for i := range providers {
+		p := providers[i]
+		if reason := s.checkCompatibility(&p, req); reason != "" {
+			global.APP_LOG.Debug("调度跳过节点",
+				zap.Uint("providerId", p.ID),
+				zap.String("provider", p.Name),
+				zap.String("reason", reason))
+			continue
+		}
+
+		// 将未过期的预留资源计入已占用量，避免并发创建时集中到同一节点
+		r := reserved[p.ID]
+		p.UsedCPUCores += r.CPU
+		p.UsedMemory += r.Memory
+		p.UsedDisk += r.Disk
+		p.ContainerCount += r.Containers
+		p.VMCount += r.VMs
+
+		check := resourceService.CheckProviderAvailability(&p, resourceModel.ResourceCheckRequest{
+			ProviderID:   p.ID,
+			InstanceType: req.InstanceType,
+			CPU:          req.CPU,
+			Memory:       req.Memory,
+			Disk:         req.Disk,
+		})
+		if !check.Allowed {
+			global.APP_LOG.Debug("调度跳过节点",
+				zap.Uint("providerId", p.ID),
+				zap.String("provider", p.Name),
+				zap.String("reason", check.Reason))
+			continue
+		}
+
+		c := newCandidate(p, req)
+		c.Score = strategy.Score(&c)
+		candidates = append(candidates, c)
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoAvailableProvider
+	}
+
+	// 分数相同时优先实例较少的节点，再按ID保证结果稳定
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		ci := candidates[i].Provider.ContainerCount + candidates[i].Provider.VMCount
+		cj := candidates[j].Provider.ContainerCount + candidates[j].Provider.VMCount
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i].Provider.ID < candidates[j].Provider.ID
+	})
+
+	global.APP_LOG.Info("自动调度候选节点",
+		zap.String("strategy", strategy.Name()),
+		zap.String("instanceType", req.InstanceType),
+		zap.Int("candidates", len(candidates)),
+		zap.Uint("top", candidates[0].Provider.ID),
+		zap.Float64("topScore", candidates[0].Score))
+
+	return candidates, nil
+}
+
+// checkCompatibility 检查节点的类型、架构、健康状态和资源数据，返回不满足时的原因
+func (s *Service) checkCompatibility(p *providerModel.Provider, req Request) string {
+	if len(req.ProviderTypes) > 0 {
+		supported := false
+		for _, t := range req.ProviderTypes {
+			if strings.TrimSpace(t) == p.Type {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return "镜像不支持该Provider类型"
+		}
+	}
+	if req.Architecture != "" && p.Architecture != "" && p.Architecture != req.Architecture {
+		return "架构不匹配"
+	}
+	if p.APIStatus == "offline" && p.SSHStatus == "offline" {
+		return "节点离线"
+	}
+	if p.SSHStatus == providerModel.SSHStatusKeyMismatch {
+		return "节点SSH主机密钥不一致"
+	}
+	if p.NodeCPUCores <= 0 || p.NodeMemoryTotal <= 0 || p.NodeDiskTotal <= 0 {
+		return "节点资源数据未同步"
+	}
+	return ""
+}
+
+// reservedUsage 节点上未过期的预留资源汇总
+type reservedUsage struct {
+	CPU        int
+	Memory     int64
+	Disk       int64
+	Containers int
+	VMs        int
+}
+
+// loadActiveReservations 批量查询候选节点上未过期的预留资源
+func (s *Service) loadActiveReservations(providers []providerModel.Provider) (map[uint]reservedUsage, error) {
+	ids := make([]uint, 0, len(providers))
+	for _, p := range providers {
+		ids = append(ids, p.ID)
+	}
+
+	var reservations []resourceModel.ResourceReservation
+	if err := global.APP_DB.Where("provider_id IN ? AND expires_at > ?", ids, time.Now()).
+		Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("查询预留资源失败: %v", err)
+	}
+
+	result := make(map[uint]reservedUsage, len(providers))
+	for _, r := range reservations {
+		usage := result[r.ProviderID]
+		usage.CPU += r.CPU
+		usage.Memory += r.Memory
+		usage.Disk += r.Disk
+		if r.InstanceType == "vm" {
+			usage.VMs++
+		} else {
+			usage.Containers++
+		}
+		result[r.ProviderID] = usage
+	}
+	return result, nil
+}
+
+// newCandidate 计算放入本实例后节点的各项剩余比例
+func newCandidate(p providerModel.Provider, req Request) Candidate {
+	c := Candidate{
+		Provider:        p,
+		FreeCPURatio:    freeRatio(float64(p.NodeCPUCores), float64(p.UsedCPUCores+req.CPU)),
+		FreeMemoryRatio: freeRatio(float64(p.NodeMemoryTotal), float64(p.UsedMemory+req.Memory)),
+		FreeDiskRatio:   freeRatio(float64(p.NodeDiskTotal), float64(p.UsedDisk+req.Disk)),
+		FreeSlotRatio:   1,
+	}
+
+	maxSlots, used := p.MaxContainerInstances, p.ContainerCount
+	if req.InstanceType == "vm" {
+		maxSlots, used = p.MaxVMInstances, p.VMCount
+	}
+	if maxSlots > 0 {
+		c.FreeSlotRatio = freeRatio(float64(maxSlots), float64(used+1))
+	}
+	return c
+}
+
+// freeRatio 计算剩余比例并限制在0~1之间
+func freeRatio(total, used float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	ratio := (total - used) / total
+	if ratio < 0 {
+		return 0
+	}
+	if ratio > 1 {
+		return 1
+	}
+	return ratio
+}
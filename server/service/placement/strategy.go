@@ -0,0 +1,96 @@
+package placement
+
+import (
+	"sync"
+
+	"oneclickvirt/config"
+)
+
+// 内置调度策略名称
+const (
+	StrategySpread   = "spread"   // 分散：优先选择剩余资源比例最高的节点
+	StrategyPack     = "pack"     // 集中：优先填满已有负载的节点，便于空出整机
+	StrategyWeighted = "weighted" // 加权：按管理员配置的权重综合各项剩余资源
+)
+
+// Strategy 调度策略，对通过过滤的候选节点打分，分数越高越优先
+type Strategy interface {
+	Name() string
+	Score(c *Candidate) float64
+}
+
+// StrategyFactory 根据当前调度配置构造策略实例
+type StrategyFactory func(cfg config.Placement) Strategy
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]StrategyFactory{}
+)
+
+// RegisterStrategy 注册调度策略，同名策略会被覆盖
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = factory
+}
+
+// GetStrategy 按配置获取调度策略，未配置或未注册的策略回退为spread
+func GetStrategy(cfg config.Placement) Strategy {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	if factory, ok := strategies[cfg.Strategy]; ok {
+		return factory(cfg)
+	}
+	return strategies[StrategySpread](cfg)
+}
+
+func init() {
+	RegisterStrategy(StrategySpread, func(config.Placement) Strategy { return spreadStrategy{} })
+	RegisterStrategy(StrategyPack, func(config.Placement) Strategy { return packStrategy{} })
+	RegisterStrategy(StrategyWeighted, func(cfg config.Placement) Strategy { return newWeightedStrategy(cfg.Weights) })
+}
+
+// spreadStrategy 分散策略：CPU、内存、磁盘剩余比例的平均值
+type spreadStrategy struct{}
+
+func (spreadStrategy) Name() string { return StrategySpread }
+
+func (spreadStrategy) Score(c *Candidate) float64 {
+	return (c.FreeCPURatio + c.FreeMemoryRatio + c.FreeDiskRatio) / 3
+}
+
+// packStrategy 集中策略：与分散策略相反，剩余比例越低越优先
+type packStrategy struct{}
+
+func (packStrategy) Name() string { return StrategyPack }
+
+func (packStrategy) Score(c *Candidate) float64 {
+	return 1 - (c.FreeCPURatio+c.FreeMemoryRatio+c.FreeDiskRatio)/3
+}
+
+// weightedStrategy 加权策略：各项剩余比例按权重加权平均
+type weightedStrategy struct {
+	weights config.PlacementWeights
+}
+
+func newWeightedStrategy(weights config.PlacementWeights) weightedStrategy {
+	if weights.CPU <= 0 && weights.Memory <= 0 && weights.Disk <= 0 && weights.Instances <= 0 {
+		weights = config.PlacementWeights{CPU: 1, Memory: 1, Disk: 1, Instances: 1}
+	}
+	return weightedStrategy{weights: weights}
+}
+
+func (weightedStrategy) Name() string { return StrategyWeighted }
+
+func (s weightedStrategy) Score(c *Candidate) float64 {
+	w := s.weights
+	total := max(w.CPU, 0) + max(w.Memory, 0) + max(w.Disk, 0) + max(w.Instances, 0)
+	if total == 0 {
+		return 0
+	}
+	score := max(w.CPU, 0)*c.FreeCPURatio +
+		max(w.Memory, 0)*c.FreeMemoryRatio +
+		max(w.Disk, 0)*c.FreeDiskRatio +
+		max(w.Instances, 0)*c.FreeSlotRatio
+	return score / total
+}
@@ -0,0 +1,84 @@
+package placement
+
+import (
+	"testing"
+
+	"oneclickvirt/config"
+	providerModel "oneclickvirt/model/provider"
+)
+
+// TestStrategies 测试内置调度策略的节点偏好
+func TestStrategies(t *testing.T) {
+	req := Request{InstanceType: "container", CPU: 1, Memory: 512, Disk: 10240}
+	idle := newCandidate(providerModel.Provider{
+		NodeCPUCores: 8, NodeMemoryTotal: 16384, NodeDiskTotal: 204800,
+		UsedCPUCores: 1, UsedMemory: 1024, UsedDisk: 20480,
+	}, req)
+	busy := newCandidate(providerModel.Provider{
+		NodeCPUCores: 8, NodeMemoryTotal: 16384, NodeDiskTotal: 204800,
+		UsedCPUCores: 6, UsedMemory: 12288, UsedDisk: 153600,
+	}, req)
+
+	t.Run("spread优先空闲节点", func(t *testing.T) {
+		s := GetStrategy(config.Placement{Strategy: StrategySpread})
+		if s.Score(&idle) <= s.Score(&busy) {
+			t.Errorf("空闲节点分数应更高: idle=%f busy=%f", s.Score(&idle), s.Score(&busy))
+		}
+	})
+
+	t.Run("pack优先繁忙节点", func(t *testing.T) {
+		s := GetStrategy(config.Placement{Strategy: StrategyPack})
+		if s.Score(&busy) <= s.Score(&idle) {
+			t.Errorf("繁忙节点分数应更高: idle=%f busy=%f", s.Score(&idle), s.Score(&busy))
+		}
+	})
+
+	t.Run("weighted只看实例名额", func(t *testing.T) {
+		a := Candidate{FreeCPURatio: 1, FreeMemoryRatio: 1, FreeDiskRatio: 1, FreeSlotRatio: 0.1}
+		b := Candidate{FreeCPURatio: 0, FreeMemoryRatio: 0, FreeDiskRatio: 0, FreeSlotRatio: 0.9}
+		s := GetStrategy(config.Placement{
+			Strategy: StrategyWeighted,
+			Weights:  config.PlacementWeights{Instances: 1},
+		})
+		if s.Score(&b) <= s.Score(&a) {
+			t.Errorf("剩余名额多的节点分数应更高: a=%f b=%f", s.Score(&a), s.Score(&b))
+		}
+	})
+
+	t.Run("weighted权重全为0时使用等权重", func(t *testing.T) {
+		s := GetStrategy(config.Placement{Strategy: StrategyWeighted})
+		c := Candidate{FreeCPURatio: 1, FreeMemoryRatio: 1, FreeDiskRatio: 1, FreeSlotRatio: 1}
+		if got := s.Score(&c); got != 1 {
+			t.Errorf("全部空闲时分数应为1，实际为 %f", got)
+		}
+	})
+
+	t.Run("未知策略回退为spread", func(t *testing.T) {
+		if name := GetStrategy(config.Placement{Strategy: "unknown"}).Name(); name != StrategySpread {
+			t.Errorf("应回退为 %s，实际为 %s", StrategySpread, name)
+		}
+	})
+}
+
+// TestNewCandidateSlotRatio 测试实例名额比例计算
+func TestNewCandidateSlotRatio(t *testing.T) {
+	p := providerModel.Provider{
+		NodeCPUCores: 4, NodeMemoryTotal: 4096, NodeDiskTotal: 40960,
+		MaxVMInstances: 4, VMCount: 1,
+	}
+	c := newCandidate(p, Request{InstanceType: "vm", CPU: 1, Memory: 1024, Disk: 10240})
+	if c.FreeSlotRatio != 0.5 {
+		t.Errorf("剩余名额比例应为0.5，实际为 %f", c.FreeSlotRatio)
+	}
+	if c.FreeCPURatio != 0.75 {
+		t.Errorf("剩余CPU比例应为0.75，实际为 %f", c.FreeCPURatio)
+	}
+
+	c = newCandidate(p, Request{InstanceType: "container", CPU: 8})
+	if c.FreeSlotRatio != 1 {
+		t.Errorf("未限制数量时剩余名额比例应为1，实际为 %f", c.FreeSlotRatio)
+	}
+	if c.FreeCPURatio != 0 {
+		t.Errorf("超出总量时剩余CPU比例应为0，实际为 %f", c.FreeCPURatio)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	monitoringModel "oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
 	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/utils"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +16,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// trafficHistoryUpsertColumns Provider/用户流量历史汇总冲突时更新的列
+var trafficHistoryUpsertColumns = []string{"traffic_in", "traffic_out", "total_used", "instance_count", "record_time", "updated_at"}
+
+// pmacctRecordUpsertClause 流量记录按 uk_instance_timestamp 去重的冲突子句
+// 时间戳在最近5分钟内的记录直接覆盖，更早的记录只在新值更大时覆盖，避免累积值回退
+// 子句中含4个时间占位符，均传入"5分钟前"的时间
+func pmacctRecordUpsertClause(db *gorm.DB) string {
+	assign := func(column, compareColumn string) string {
+		return fmt.Sprintf(
+			"%[1]s = CASE WHEN pmacct_traffic_records.timestamp >= %[2]s OR %[3]s > pmacct_traffic_records.%[4]s THEN %[5]s ELSE pmacct_traffic_records.%[1]s END",
+			column, utils.TimeParam(db), utils.InsertedValue(db, compareColumn), compareColumn, utils.InsertedValue(db, column))
+	}
+	return utils.UpsertAssignClause(db, []string{"instance_id", "timestamp"},
+		assign("rx_bytes", "rx_bytes"),
+		assign("tx_bytes", "tx_bytes"),
+		assign("total_bytes", "total_bytes"),
+		assign("record_time", "total_bytes"),
+	)
+}
+
 // CollectTrafficFromSQLite 从远程 pmacct SQLite 数据库采集流量数据并导入系统数据库
 // 架构：Memory(1min) -> SQLite(local) -> MySQL(remote, dynamic interval)
 // 参数：预加载的instance和monitor数据
@@ -285,7 +306,7 @@ LIMIT 10000;
 		return nil
 	}
 
-	// 准备批量插入数据（直接使用ON DUPLICATE KEY UPDATE去重）
+	// 准备批量插入数据（插入冲突时更新，依赖唯一索引去重）
 	var recordsToCreate []monitoringModel.PmacctTrafficRecord
 	for _, data := range dataList {
 		recordsToCreate = append(recordsToCreate, monitoringModel.PmacctTrafficRecord{
@@ -446,12 +467,13 @@ LIMIT 10000;
 						}
 
 						insertSQL := fmt.Sprintf(`
-							INSERT IGNORE INTO pmacct_traffic_records 
+							INSERT INTO pmacct_traffic_records 
 							(instance_id, user_id, provider_id, provider_type, mapped_ip, 
 							 rx_bytes, tx_bytes, total_bytes, timestamp, 
 							 year, month, day, hour, minute, record_time)
 							VALUES %s
-						`, strings.Join(values, ","))
+							%s
+						`, strings.Join(values, ","), utils.UpsertIgnoreClause(tx))
 
 						return tx.Exec(insertSQL, args...).Error
 					})
@@ -514,29 +536,11 @@ LIMIT 10000;
 				 rx_bytes, tx_bytes, total_bytes, timestamp, 
 				 year, month, day, hour, minute, record_time)
 				VALUES %s
-				ON DUPLICATE KEY UPDATE
-					rx_bytes = IF(
-						TIMESTAMPDIFF(MINUTE, pmacct_traffic_records.timestamp, NOW()) <= 5 OR VALUES(rx_bytes) > pmacct_traffic_records.rx_bytes,
-						VALUES(rx_bytes),
-						pmacct_traffic_records.rx_bytes
-					),
-					tx_bytes = IF(
-						TIMESTAMPDIFF(MINUTE, pmacct_traffic_records.timestamp, NOW()) <= 5 OR VALUES(tx_bytes) > pmacct_traffic_records.tx_bytes,
-						VALUES(tx_bytes),
-						pmacct_traffic_records.tx_bytes
-					),
-					total_bytes = IF(
-						TIMESTAMPDIFF(MINUTE, pmacct_traffic_records.timestamp, NOW()) <= 5 OR VALUES(total_bytes) > pmacct_traffic_records.total_bytes,
-						VALUES(total_bytes),
-						pmacct_traffic_records.total_bytes
-					),
-					record_time = IF(
-						TIMESTAMPDIFF(MINUTE, pmacct_traffic_records.timestamp, NOW()) <= 5 OR VALUES(total_bytes) > pmacct_traffic_records.total_bytes,
-						VALUES(record_time),
-						pmacct_traffic_records.record_time
-					)
-			`, strings.Join(values, ","))
+				%s
+			`, strings.Join(values, ","), pmacctRecordUpsertClause(tx))
 
+			recent := time.Now().Add(-5 * time.Minute)
+			args = append(args, recent, recent, recent, recent)
 			return tx.Exec(insertSQL, args...).Error
 		})
 
@@ -740,7 +744,7 @@ LIMIT 10000;
 		}
 
 		// 更新Provider流量历史表（小时级，聚合所有实例）
-		if err := global.APP_DB.Exec(`
+		if err := global.APP_DB.Exec(fmt.Sprintf(`
 			INSERT INTO provider_traffic_histories 
 				(provider_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 			SELECT 
@@ -750,27 +754,21 @@ LIMIT 10000;
 				SUM(total_used) as total_used,
 				COUNT(DISTINCT instance_id) as instance_count,
 				year, month, day, hour,
-				? as record_time,
-				? as created_at,
-				? as updated_at
+				%[1]s as record_time,
+				%[1]s as created_at,
+				%[1]s as updated_at
 			FROM instance_traffic_histories
 			WHERE provider_id = ? AND year = ? AND month = ? AND day = ? AND hour = ? AND deleted_at IS NULL
 			GROUP BY provider_id, year, month, day, hour
-			ON DUPLICATE KEY UPDATE
-				traffic_in = VALUES(traffic_in),
-				traffic_out = VALUES(traffic_out),
-				total_used = VALUES(total_used),
-				instance_count = VALUES(instance_count),
-				record_time = VALUES(record_time),
-				updated_at = VALUES(updated_at)
-		`, now, now, now, instance.ProviderID, year, month, day, hour).Error; err != nil {
+			%[2]s
+		`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, now, now, instance.ProviderID, year, month, day, hour).Error; err != nil {
 			global.APP_LOG.Warn("更新Provider流量历史失败",
 				zap.Uint("providerID", instance.ProviderID),
 				zap.Error(err))
 		}
 
 		// 更新Provider月度汇总（day=0, hour=0）
-		if err := global.APP_DB.Exec(`
+		if err := global.APP_DB.Exec(fmt.Sprintf(`
 			INSERT INTO provider_traffic_histories 
 				(provider_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 			SELECT 
@@ -780,27 +778,21 @@ LIMIT 10000;
 				SUM(total_used) as total_used,
 				COUNT(DISTINCT instance_id) as instance_count,
 				year, month, 0 as day, 0 as hour,
-				? as record_time,
-				? as created_at,
-				? as updated_at
+				%[1]s as record_time,
+				%[1]s as created_at,
+				%[1]s as updated_at
 			FROM instance_traffic_histories
 			WHERE provider_id = ? AND year = ? AND month = ? AND day = 0 AND hour = 0 AND deleted_at IS NULL
 			GROUP BY provider_id, year, month
-			ON DUPLICATE KEY UPDATE
-				traffic_in = VALUES(traffic_in),
-				traffic_out = VALUES(traffic_out),
-				total_used = VALUES(total_used),
-				instance_count = VALUES(instance_count),
-				record_time = VALUES(record_time),
-				updated_at = VALUES(updated_at)
-		`, now, now, now, instance.ProviderID, year, month).Error; err != nil {
+			%[2]s
+		`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, now, now, instance.ProviderID, year, month).Error; err != nil {
 			global.APP_LOG.Warn("更新Provider月度汇总失败",
 				zap.Uint("providerID", instance.ProviderID),
 				zap.Error(err))
 		}
 
 		// 更新用户流量历史表（小时级，聚合所有实例）
-		if err := global.APP_DB.Exec(`
+		if err := global.APP_DB.Exec(fmt.Sprintf(`
 			INSERT INTO user_traffic_histories 
 				(user_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 			SELECT 
@@ -810,27 +802,21 @@ LIMIT 10000;
 				SUM(total_used) as total_used,
 				COUNT(DISTINCT instance_id) as instance_count,
 				year, month, day, hour,
-				? as record_time,
-				? as created_at,
-				? as updated_at
+				%[1]s as record_time,
+				%[1]s as created_at,
+				%[1]s as updated_at
 			FROM instance_traffic_histories
 			WHERE user_id = ? AND year = ? AND month = ? AND day = ? AND hour = ? AND deleted_at IS NULL
 			GROUP BY user_id, year, month, day, hour
-			ON DUPLICATE KEY UPDATE
-				traffic_in = VALUES(traffic_in),
-				traffic_out = VALUES(traffic_out),
-				total_used = VALUES(total_used),
-				instance_count = VALUES(instance_count),
-				record_time = VALUES(record_time),
-				updated_at = VALUES(updated_at)
-		`, now, now, now, instance.UserID, year, month, day, hour).Error; err != nil {
+			%[2]s
+		`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, now, now, instance.UserID, year, month, day, hour).Error; err != nil {
 			global.APP_LOG.Warn("更新用户流量历史失败",
 				zap.Uint("userID", instance.UserID),
 				zap.Error(err))
 		}
 
 		// 更新用户月度汇总（day=0, hour=0）
-		if err := global.APP_DB.Exec(`
+		if err := global.APP_DB.Exec(fmt.Sprintf(`
 			INSERT INTO user_traffic_histories 
 				(user_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 			SELECT 
@@ -840,20 +826,14 @@ LIMIT 10000;
 				SUM(total_used) as total_used,
 				COUNT(DISTINCT instance_id) as instance_count,
 				year, month, 0 as day, 0 as hour,
-				? as record_time,
-				? as created_at,
-				? as updated_at
+				%[1]s as record_time,
+				%[1]s as created_at,
+				%[1]s as updated_at
 			FROM instance_traffic_histories
 			WHERE user_id = ? AND year = ? AND month = ? AND day = 0 AND hour = 0 AND deleted_at IS NULL
 			GROUP BY user_id, year, month
-			ON DUPLICATE KEY UPDATE
-				traffic_in = VALUES(traffic_in),
-				traffic_out = VALUES(traffic_out),
-				total_used = VALUES(total_used),
-				instance_count = VALUES(instance_count),
-				record_time = VALUES(record_time),
-				updated_at = VALUES(updated_at)
-		`, now, now, now, instance.UserID, year, month).Error; err != nil {
+			%[2]s
+		`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, now, now, instance.UserID, year, month).Error; err != nil {
 			global.APP_LOG.Warn("更新用户月度汇总失败",
 				zap.Uint("userID", instance.UserID),
 				zap.Error(err))
@@ -15,6 +15,7 @@ import (
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // PmacctTrafficData pmacct流量数据结构
@@ -259,13 +260,19 @@ func (s *Service) refreshProviderCache(providerID uint, providerRecord *provider
 	return nil
 }
 
+// pmacctAggregateUpsertClause 聚合记录写回 pmacct_traffic_records 时的冲突子句
+func pmacctAggregateUpsertClause(db *gorm.DB) string {
+	return utils.UpsertClause(db, []string{"instance_id", "timestamp"},
+		"rx_bytes", "tx_bytes", "total_bytes", "updated_at")
+}
+
 // aggregateToDailyBetween 将小时级数据聚合为日度统计
 func (s *Service) aggregateToDailyBetween(startTime, endTime time.Time) error {
 	global.APP_LOG.Info("开始聚合小时数据到日度统计",
 		zap.Time("startTime", startTime),
 		zap.Time("endTime", endTime))
 
-	aggregateSQL := `
+	aggregateSQL := fmt.Sprintf(`
 		INSERT INTO pmacct_traffic_records (
 			instance_id, user_id, provider_id, provider_type, mapped_ip,
 			rx_bytes, tx_bytes, total_bytes,
@@ -281,27 +288,24 @@ func (s *Service) aggregateToDailyBetween(startTime, endTime time.Time) error {
 			MAX(rx_bytes) as rx_bytes,
 			MAX(tx_bytes) as tx_bytes,
 			MAX(total_bytes) as total_bytes,
-			DATE_FORMAT(timestamp, '%Y-%m-%d 00:00:00') as timestamp,
+			MIN(%[1]s) as timestamp,
 			year,
 			month,
 			day,
 			0 as hour,
 			0 as minute,
 			MAX(record_time) as record_time,
-			NOW() as created_at,
-			NOW() as updated_at
+			%[2]s as created_at,
+			%[2]s as updated_at
 		FROM pmacct_traffic_records
 		WHERE record_time >= ? AND record_time < ?
 			AND (hour > 0 OR minute > 0)
 		GROUP BY instance_id, user_id, provider_id, provider_type, mapped_ip, year, month, day
-		ON DUPLICATE KEY UPDATE
-			rx_bytes = VALUES(rx_bytes),
-			tx_bytes = VALUES(tx_bytes),
-			total_bytes = VALUES(total_bytes),
-			updated_at = VALUES(updated_at)
-	`
+		%[3]s
+	`, utils.TruncateTimeExpr(global.APP_DB, "timestamp", "day"), utils.TimeParam(global.APP_DB), pmacctAggregateUpsertClause(global.APP_DB))
 
-	result := global.APP_DB.Exec(aggregateSQL, startTime, endTime)
+	now := time.Now()
+	result := global.APP_DB.Exec(aggregateSQL, now, now, startTime, endTime)
 	if result.Error != nil {
 		return fmt.Errorf("failed to aggregate to daily: %w", result.Error)
 	}
@@ -318,7 +322,7 @@ func (s *Service) aggregateToHourlyBetween(startTime, endTime time.Time) error {
 		zap.Time("startTime", startTime),
 		zap.Time("endTime", endTime))
 
-	aggregateSQL := `
+	aggregateSQL := fmt.Sprintf(`
 		INSERT INTO pmacct_traffic_records (
 			instance_id, user_id, provider_id, provider_type, mapped_ip,
 			rx_bytes, tx_bytes, total_bytes,
@@ -334,27 +338,24 @@ func (s *Service) aggregateToHourlyBetween(startTime, endTime time.Time) error {
 			MAX(rx_bytes) as rx_bytes,
 			MAX(tx_bytes) as tx_bytes,
 			MAX(total_bytes) as total_bytes,
-			DATE_FORMAT(timestamp, '%Y-%m-%d %H:00:00') as timestamp,
+			MIN(%[1]s) as timestamp,
 			year,
 			month,
 			day,
 			hour,
 			0 as minute,
 			MAX(record_time) as record_time,
-			NOW() as created_at,
-			NOW() as updated_at
+			%[2]s as created_at,
+			%[2]s as updated_at
 		FROM pmacct_traffic_records
 		WHERE record_time >= ? AND record_time < ?
 			AND minute > 0
 		GROUP BY instance_id, user_id, provider_id, provider_type, mapped_ip, year, month, day, hour
-		ON DUPLICATE KEY UPDATE
-			rx_bytes = VALUES(rx_bytes),
-			tx_bytes = VALUES(tx_bytes),
-			total_bytes = VALUES(total_bytes),
-			updated_at = VALUES(updated_at)
-	`
+		%[3]s
+	`, utils.TruncateTimeExpr(global.APP_DB, "timestamp", "hour"), utils.TimeParam(global.APP_DB), pmacctAggregateUpsertClause(global.APP_DB))
 
-	result := global.APP_DB.Exec(aggregateSQL, startTime, endTime)
+	now := time.Now()
+	result := global.APP_DB.Exec(aggregateSQL, now, now, startTime, endTime)
 	if result.Error != nil {
 		return fmt.Errorf("failed to aggregate to hourly: %w", result.Error)
 	}
@@ -459,6 +459,7 @@ func (cs *CertService) autoConfigureProxmoxWithStream(provider *provider.Provide
 func (cs *CertService) executeScriptViaSFTP(provider *provider.Provider, script, filename string) error {
 	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
 	sshConfig := utils.SSHConfig{
+		ProviderID:     provider.ID,
 		Host:           host,
 		Port:           port,
 		Username:       provider.Username,
@@ -495,6 +496,7 @@ func (cs *CertService) executeScriptViaSFTP(provider *provider.Provider, script,
 func (cs *CertService) executeScriptViaSFTPWithStream(provider *provider.Provider, script, filename string, outputChan chan<- string) error {
 	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
 	sshConfig := utils.SSHConfig{
+		ProviderID:     provider.ID,
 		Host:           host,
 		Port:           port,
 		Username:       provider.Username,
@@ -865,6 +867,7 @@ echo "=== Proxmox VE 配置完成 ==="
 func (cs *CertService) getProxmoxTokenFromRemote(provider *provider.Provider, username, tokenId string) (*TokenInfo, error) {
 	host, port := utils.ParseEndpoint(provider.Endpoint, provider.SSHPort)
 	sshConfig := utils.SSHConfig{
+		ProviderID:     provider.ID,
 		Host:           host,
 		Port:           port,
 		Username:       provider.Username,
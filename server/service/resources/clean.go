@@ -60,6 +60,36 @@ func (s *PortMappingService) DeleteInstancePortMappingsInTx(tx *gorm.DB, instanc
 	return nil
 }
 
+// DeleteInstanceProviderPortMappingsInTx 在事务中删除实例在指定Provider上的端口映射（迁移实例时使用）
+func (s *PortMappingService) DeleteInstanceProviderPortMappingsInTx(tx *gorm.DB, instanceID, providerID uint) error {
+	var ports []provider.Port
+	if err := tx.Where("instance_id = ? AND provider_id = ?", instanceID, providerID).Find(&ports).Error; err != nil {
+		return err
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("instance_id = ? AND provider_id = ?", instanceID, providerID).Delete(&provider.Port{}).Error; err != nil {
+		return fmt.Errorf("删除端口映射失败: %v", err)
+	}
+
+	releasedPorts := make([]int, 0, len(ports))
+	for _, port := range ports {
+		releasedPorts = append(releasedPorts, port.HostPort)
+	}
+	if err := s.optimizeNextAvailablePortInTx(tx, providerID, releasedPorts); err != nil {
+		global.APP_LOG.Warn("Provider端口重用失败", zap.Uint("providerId", providerID), zap.Error(err))
+	}
+
+	global.APP_LOG.Info("删除实例在Provider上的端口映射成功",
+		zap.Uint("instance_id", instanceID),
+		zap.Uint("provider_id", providerID),
+		zap.Int("releasedPortCount", len(ports)))
+
+	return nil
+}
+
 // BatchDeletePortMappingWithTask 批量删除端口映射（通过任务系统异步执行，仅支持删除手动添加的端口）
 // 返回任务数据列表（由调用者创建和启动任务）
 func (s *PortMappingService) BatchDeletePortMappingWithTask(req admin.BatchDeletePortMappingRequest) ([]*admin.DeletePortMappingTaskRequest, error) {
@@ -267,48 +267,6 @@ func (s *MonitoringService) GetSystemLogs(level, limit, offset string) map[strin
 	}
 }
 
-// GetOperationLogs 获取操作审计日志
-func (s *MonitoringService) GetOperationLogs(userID, action, startTime, endTime, limit, offset string) map[string]interface{} {
-	// 这里是占位实现，实际需要根据审计日志存储方式实现
-	auditLogs := []map[string]interface{}{
-		{
-			"id":         1,
-			"user_id":    123,
-			"username":   "admin",
-			"action":     "login",
-			"resource":   "auth",
-			"ip_address": "192.168.1.100",
-			"user_agent": "Mozilla/5.0...",
-			"timestamp":  time.Now().Format("2006-01-02 15:04:05"),
-			"status":     "success",
-			"details":    "用户登录成功",
-		},
-		{
-			"id":         2,
-			"user_id":    123,
-			"username":   "admin",
-			"action":     "create_user",
-			"resource":   "user",
-			"ip_address": "192.168.1.100",
-			"user_agent": "Mozilla/5.0...",
-			"timestamp":  time.Now().Add(-time.Hour).Format("2006-01-02 15:04:05"),
-			"status":     "success",
-			"details":    "创建用户成功",
-		},
-	}
-
-	return map[string]interface{}{
-		"logs":       auditLogs,
-		"total":      len(auditLogs),
-		"user_id":    userID,
-		"action":     action,
-		"start_time": startTime,
-		"end_time":   endTime,
-		"limit":      limit,
-		"offset":     offset,
-	}
-}
-
 // calculateCPUUsage 计算CPU使用率
 func (s *MonitoringService) calculateCPUUsage() float64 {
 	// 简化的CPU使用率计算
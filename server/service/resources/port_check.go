@@ -390,10 +390,11 @@ func (s *PortMappingService) createSSHClientForProvider(providerInfo *provider.P
 	hostname := hostParts[0]
 
 	sshConfig := utils.SSHConfig{
-		Host:     hostname,
-		Port:     providerInfo.SSHPort,
-		Username: providerInfo.Username,
-		Password: providerInfo.Password,
+		ProviderID: providerInfo.ID,
+		Host:       hostname,
+		Port:       providerInfo.SSHPort,
+		Username:   providerInfo.Username,
+		Password:   providerInfo.Password,
 	}
 
 	// 如果有SSH密钥，优先使用密钥
@@ -12,8 +12,10 @@ import (
 	"oneclickvirt/global"
 	"oneclickvirt/model/provider"
 	"oneclickvirt/model/user"
+	"oneclickvirt/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // QuotaService 资源配额验证服务
@@ -75,9 +77,11 @@ func (s *QuotaService) ValidateInstanceCreation(req ResourceRequest) (*QuotaChec
 
 	// 开启串行化事务隔离级别（最高级别，完全避免并发问题）
 	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
-		// 设置事务隔离级别为 SERIALIZABLE
-		if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE").Error; err != nil {
-			return fmt.Errorf("设置事务隔离级别失败: %v", err)
+		// 设置事务隔离级别为 SERIALIZABLE（SQLite 的事务本身就是串行化的，不支持该语句）
+		if utils.DBDialect(tx) != utils.DialectSQLite {
+			if err := tx.Exec("SET TRANSACTION ISOLATION LEVEL SERIALIZABLE").Error; err != nil {
+				return fmt.Errorf("设置事务隔离级别失败: %v", err)
+			}
 		}
 
 		result, err = s.validateInTransaction(tx, req)
@@ -288,9 +292,10 @@ func (s *QuotaService) getCurrentResourceUsage(tx *gorm.DB, userID uint) (int, R
 // getCurrentResourceUsageWithPending 获取当前资源使用情况（分别统计稳定和待确认）
 func (s *QuotaService) getCurrentResourceUsageWithPending(tx *gorm.DB, userID uint) (int, ResourceUsage, ResourceUsage, error) {
 	// 稳定状态：running、stopped、paused 等（排除 creating、resetting、deleting、deleted、failed）
+	// migrating、resizing 状态的实例在迁移/调整规格期间仍按原规格占用已确认配额
 	var stableInstances []provider.Instance
 	err := tx.Set("gorm:query_option", "LOCK IN SHARE MODE").
-		Where("user_id = ? AND status IN (?)", userID, []string{"running", "stopped", "paused"}).
+		Where("user_id = ? AND status IN (?)", userID, []string{"running", "stopped", "paused", "migrating", "resizing"}).
 		Find(&stableInstances).Error
 	if err != nil {
 		return 0, ResourceUsage{}, ResourceUsage{}, err
@@ -514,6 +519,75 @@ func (s *QuotaService) ReleaseUsedQuota(tx *gorm.DB, userID uint, resources Reso
 	return nil
 }
 
+// checkProviderInstanceLimit 检查用户在目标 Provider 上的实例数量是否已达节点等级上限
+func (s *QuotaService) checkProviderInstanceLimit(tx *gorm.DB, userID uint, userLevel int, providerID uint) error {
+	providerLevelLimits, err := s.getProviderLevelLimits(tx, providerID, userLevel)
+	if err != nil {
+		return fmt.Errorf("获取 Provider 等级限制失败: %v", err)
+	}
+	if providerLevelLimits == nil || providerLevelLimits.MaxInstances <= 0 {
+		return nil
+	}
+
+	currentProviderInstances, err := s.getCurrentProviderInstanceCount(tx, userID, providerID)
+	if err != nil {
+		return fmt.Errorf("获取节点实例数量失败: %v", err)
+	}
+	if currentProviderInstances >= providerLevelLimits.MaxInstances {
+		return fmt.Errorf("该节点实例数量已达上限：当前在此节点 %d/%d",
+			currentProviderInstances, providerLevelLimits.MaxInstances)
+	}
+	return nil
+}
+
+// ValidateInstanceTransfer 验证实例能否迁移到目标 Provider（不加锁，用于创建迁移任务前的预检查）
+func (s *QuotaService) ValidateInstanceTransfer(instance *provider.Instance, targetProviderID uint) error {
+	var u user.User
+	if err := global.APP_DB.First(&u, instance.UserID).Error; err != nil {
+		return fmt.Errorf("用户不存在: %v", err)
+	}
+	return s.checkProviderInstanceLimit(global.APP_DB, instance.UserID, u.Level, targetProviderID)
+}
+
+// TransferInstanceInTx 在事务中将实例的资源占用从源 Provider 转移到目标 Provider（迁移实例时调用）
+// 实例在迁移前后都处于稳定状态，用户的 used_quota 保持不变，只移动节点层面的资源占用
+func (s *QuotaService) TransferInstanceInTx(tx *gorm.DB, instance *provider.Instance, targetProviderID uint) error {
+	var u user.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, instance.UserID).Error; err != nil {
+		return fmt.Errorf("用户不存在: %v", err)
+	}
+
+	// 按ID顺序先锁定源和目标Provider，避免方向相反的两次迁移互相等待；
+	// 释放和分配资源时会再次加锁读取，同一事务内重复加锁不会阻塞
+	providerIDs := []uint{instance.ProviderID, targetProviderID}
+	if providerIDs[0] > providerIDs[1] {
+		providerIDs[0], providerIDs[1] = providerIDs[1], providerIDs[0]
+	}
+	var lockedProviders []provider.Provider
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", providerIDs).Order("id").Find(&lockedProviders).Error; err != nil {
+		return fmt.Errorf("锁定Provider失败: %v", err)
+	}
+
+	if err := s.checkProviderInstanceLimit(tx, instance.UserID, u.Level, targetProviderID); err != nil {
+		return err
+	}
+
+	resourceService := &ResourceService{}
+	if err := resourceService.ReleaseResourcesInTx(tx, instance.ProviderID, instance.InstanceType,
+		instance.CPU, instance.Memory, instance.Disk); err != nil {
+		return fmt.Errorf("释放源Provider资源失败: %v", err)
+	}
+	if err := resourceService.AllocateResourcesInTx(tx, targetProviderID, instance.InstanceType,
+		instance.CPU, instance.Memory, instance.Disk); err != nil {
+		return fmt.Errorf("分配目标Provider资源失败: %v", err)
+	}
+
+	global.APP_LOG.Info(fmt.Sprintf("实例 %d 资源占用已从 Provider %d 转移到 Provider %d",
+		instance.ID, instance.ProviderID, targetProviderID))
+	return nil
+}
+
 // UpdateUserQuotaAfterCreationWithTx 在指定事务中更新用户配额（向后兼容，已废弃，使用 AllocatePendingQuota）
 func (s *QuotaService) UpdateUserQuotaAfterCreationWithTx(tx *gorm.DB, userID uint, resources ResourceUsage) error {
 	// 为了向后兼容，这里调用新的 AllocatePendingQuota 方法
@@ -0,0 +1,196 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/provider"
+	"oneclickvirt/model/user"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ValidateInstanceResize 验证实例调整规格后是否超出用户等级限制和Provider资源预算（不加锁，用于创建调整任务前的预检查）
+// 只对增加的资源项进行检查，缩减资源总是允许
+func (s *QuotaService) ValidateInstanceResize(instance *provider.Instance, newCPU int, newMemory, newDisk int64) (*QuotaCheckResult, error) {
+	var u user.User
+	if err := global.APP_DB.First(&u, instance.UserID).Error; err != nil {
+		return nil, fmt.Errorf("用户不存在: %v", err)
+	}
+
+	if u.Status != 1 {
+		return &QuotaCheckResult{Allowed: false, Reason: "用户账户已被禁用"}, nil
+	}
+
+	levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[u.Level]
+	if !exists {
+		return &QuotaCheckResult{
+			Allowed: false,
+			Reason:  fmt.Sprintf("用户等级 %d 没有配置资源限制", u.Level),
+		}, nil
+	}
+
+	var prov provider.Provider
+	if err := global.APP_DB.First(&prov, instance.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("Provider 不存在: %v", err)
+	}
+
+	providerLevelLimits, err := s.getProviderLevelLimits(global.APP_DB, prov.ID, u.Level)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Provider 等级限制失败: %v", err)
+	}
+	if providerLevelLimits != nil {
+		levelLimits = s.mergeLevelLimitsWithOvercommit(levelLimits, *providerLevelLimits, &prov, instance.InstanceType)
+	}
+
+	currentInstances, currentResources, pendingResources, err := s.getCurrentResourceUsageWithPending(global.APP_DB, instance.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("获取当前资源使用情况失败: %v", err)
+	}
+
+	maxResources := s.GetLevelMaxResources(levelLimits)
+	result := &QuotaCheckResult{
+		CurrentInstances: currentInstances,
+		MaxInstances:     levelLimits.MaxInstances,
+		CurrentResources: currentResources,
+		PendingResources: pendingResources,
+		MaxResources:     maxResources,
+		MaxQuota:         maxResources,
+		RequiredResources: ResourceUsage{
+			CPU:       newCPU,
+			Memory:    newMemory,
+			Disk:      newDisk,
+			Bandwidth: instance.Bandwidth,
+		},
+	}
+
+	// 当前使用量中已包含实例的原规格，替换为新规格后检查
+	limitCPU, limitMemory, limitDisk := prov.ContainerLimitCPU, prov.ContainerLimitMemory, prov.ContainerLimitDisk
+	if instance.InstanceType == "vm" {
+		limitCPU, limitMemory, limitDisk = prov.VMLimitCPU, prov.VMLimitMemory, prov.VMLimitDisk
+	}
+
+	totalCPU := currentResources.CPU + pendingResources.CPU - instance.CPU + newCPU
+	if limitCPU && newCPU > instance.CPU && totalCPU > maxResources.CPU {
+		result.Allowed = false
+		result.Reason = fmt.Sprintf("CPU资源不足：调整后共需 %d 核，最大允许 %d 核", totalCPU, maxResources.CPU)
+		return result, nil
+	}
+
+	totalMemory := currentResources.Memory + pendingResources.Memory - instance.Memory + newMemory
+	if limitMemory && newMemory > instance.Memory && totalMemory > maxResources.Memory {
+		result.Allowed = false
+		result.Reason = fmt.Sprintf("内存资源不足：调整后共需 %dMB，最大允许 %dMB", totalMemory, maxResources.Memory)
+		return result, nil
+	}
+
+	totalDisk := currentResources.Disk + pendingResources.Disk - instance.Disk + newDisk
+	if limitDisk && newDisk > instance.Disk && totalDisk > maxResources.Disk {
+		result.Allowed = false
+		result.Reason = fmt.Sprintf("磁盘资源不足：调整后共需 %dMB，最大允许 %dMB", totalDisk, maxResources.Disk)
+		return result, nil
+	}
+
+	// 检查Provider剩余资源能否承载增加的部分
+	resourceService := &ResourceService{}
+	if reason := resourceService.checkResizeAvailability(&prov, instance.InstanceType,
+		newCPU-instance.CPU, newMemory-instance.Memory, newDisk-instance.Disk); reason != "" {
+		result.Allowed = false
+		result.Reason = reason
+		return result, nil
+	}
+
+	result.Allowed = true
+	result.Reason = "资源验证通过"
+	return result, nil
+}
+
+// ApplyInstanceResizeInTx 在事务中按新规格更新用户已使用配额和Provider资源占用（调整规格成功后调用）
+func (s *QuotaService) ApplyInstanceResizeInTx(tx *gorm.DB, instance *provider.Instance, newCPU int, newMemory, newDisk int64) error {
+	var u user.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, instance.UserID).Error; err != nil {
+		return fmt.Errorf("用户不存在: %v", err)
+	}
+
+	oldUsage := ResourceUsage{CPU: instance.CPU, Memory: instance.Memory, Disk: instance.Disk}.GetResourceUsage()
+	newUsage := ResourceUsage{CPU: newCPU, Memory: newMemory, Disk: newDisk}.GetResourceUsage()
+	newUsedQuota := u.UsedQuota - oldUsage + newUsage
+	if newUsedQuota < 0 {
+		newUsedQuota = 0
+	}
+	if err := tx.Model(&u).Update("used_quota", newUsedQuota).Error; err != nil {
+		return fmt.Errorf("更新已使用配额失败: %v", err)
+	}
+
+	resourceService := &ResourceService{}
+	if err := resourceService.AdjustResourcesInTx(tx, instance.ProviderID, instance.InstanceType,
+		newCPU-instance.CPU, newMemory-instance.Memory, newDisk-instance.Disk); err != nil {
+		return err
+	}
+
+	global.APP_LOG.Info(fmt.Sprintf("用户 %d 实例 %d 规格调整后已使用配额: %d -> %d",
+		instance.UserID, instance.ID, u.UsedQuota, newUsedQuota))
+	return nil
+}
+
+// checkResizeAvailability 检查Provider剩余资源能否承载实例规格的增量，返回不满足时的原因
+// 实例已计入节点的实例数量，这里不再检查数量上限
+func (s *ResourceService) checkResizeAvailability(prov *provider.Provider, instanceType string, cpuDelta int, memoryDelta, diskDelta int64) string {
+	limitCPU, limitMemory, limitDisk := prov.ContainerLimitCPU, prov.ContainerLimitMemory, prov.ContainerLimitDisk
+	if instanceType == "vm" {
+		limitCPU, limitMemory, limitDisk = prov.VMLimitCPU, prov.VMLimitMemory, prov.VMLimitDisk
+	}
+
+	if availableCPU := prov.NodeCPUCores - prov.UsedCPUCores; limitCPU && cpuDelta > 0 && cpuDelta > availableCPU {
+		return fmt.Sprintf("节点CPU资源不足：需增加 %d 核，可用 %d 核", cpuDelta, availableCPU)
+	}
+	if availableMemory := prov.NodeMemoryTotal - prov.UsedMemory; limitMemory && memoryDelta > 0 && memoryDelta > availableMemory {
+		return fmt.Sprintf("节点内存资源不足：需增加 %d MB，可用 %d MB", memoryDelta, availableMemory)
+	}
+	if availableDisk := prov.NodeDiskTotal - prov.UsedDisk; limitDisk && diskDelta > 0 && diskDelta > availableDisk {
+		return fmt.Sprintf("节点磁盘资源不足：需增加 %d MB，可用 %d MB", diskDelta, availableDisk)
+	}
+	return ""
+}
+
+// AdjustResourcesInTx 在事务中按增量调整Provider资源占用（不改变实例数量）
+// 与分配/释放一致，只调整计入总量预算的资源项
+func (s *ResourceService) AdjustResourcesInTx(tx *gorm.DB, providerID uint, instanceType string, cpuDelta int, memoryDelta, diskDelta int64) error {
+	var prov provider.Provider
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&prov, providerID).Error; err != nil {
+		return fmt.Errorf("Provider不存在或无法锁定: %v", err)
+	}
+
+	limitCPU, limitMemory, limitDisk := prov.ContainerLimitCPU, prov.ContainerLimitMemory, prov.ContainerLimitDisk
+	if instanceType == "vm" {
+		limitCPU, limitMemory, limitDisk = prov.VMLimitCPU, prov.VMLimitMemory, prov.VMLimitDisk
+	}
+
+	updates := map[string]interface{}{
+		"updated_at": time.Now(),
+	}
+	if limitCPU && cpuDelta != 0 {
+		updates["used_cpu_cores"] = max(prov.UsedCPUCores+cpuDelta, 0)
+	}
+	if limitMemory && memoryDelta != 0 {
+		updates["used_memory"] = max(prov.UsedMemory+memoryDelta, 0)
+	}
+	if limitDisk && diskDelta != 0 {
+		updates["used_disk"] = max(prov.UsedDisk+diskDelta, 0)
+	}
+
+	if err := tx.Model(&prov).Updates(updates).Error; err != nil {
+		return fmt.Errorf("更新Provider资源占用失败: %v", err)
+	}
+
+	global.APP_LOG.Info("Provider资源占用已按规格调整更新",
+		zap.Uint("providerId", providerID),
+		zap.String("instanceType", instanceType),
+		zap.Int("cpuDelta", cpuDelta),
+		zap.Int64("memoryDelta", memoryDelta),
+		zap.Int64("diskDelta", diskDelta))
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ResourceService 资源管理服务 - 使用数据库级锁，移除应用级锁
@@ -206,6 +207,12 @@ func (s *ResourceService) checkProviderResourceAvailability(provider *providerMo
 	return result
 }
 
+// CheckProviderAvailability 基于给定的Provider记录检查资源是否充足（不查询数据库）
+// 供自动调度批量评估候选节点时使用，调用方可预先将预留资源计入已占用量
+func (s *ResourceService) CheckProviderAvailability(provider *providerModel.Provider, req resource.ResourceCheckRequest) *resource.ResourceCheckResult {
+	return s.checkProviderResourceAvailability(provider, req)
+}
+
 // AllocateResourcesInTx 在事务中分配资源（不创建新事务，使用悲观锁）
 // 根据Provider的资源限制配置决定是否扣减资源
 func (s *ResourceService) AllocateResourcesInTx(tx *gorm.DB, providerID uint, instanceType string, cpu int, memory, disk int64) error {
@@ -217,8 +224,8 @@ func (s *ResourceService) AllocateResourcesInTx(tx *gorm.DB, providerID uint, in
 		zap.Int64("disk", disk))
 
 	var provider providerModel.Provider
-	// 使用悲观锁锁定Provider记录
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&provider, providerID).Error; err != nil {
+	// 使用悲观锁锁定Provider记录，读取和更新资源占用之间不会被并发修改
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&provider, providerID).Error; err != nil {
 		global.APP_LOG.Error("锁定Provider失败",
 			zap.Uint("providerId", providerID),
 			zap.String("error", utils.TruncateString(err.Error(), 200)))
@@ -317,8 +324,8 @@ func (s *ResourceService) ReleaseResourcesInTx(tx *gorm.DB, providerID uint, ins
 		zap.Int64("disk", disk))
 
 	var provider providerModel.Provider
-	// 使用悲观锁锁定Provider记录
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&provider, providerID).Error; err != nil {
+	// 使用悲观锁锁定Provider记录，读取和更新资源占用之间不会被并发修改
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&provider, providerID).Error; err != nil {
 		global.APP_LOG.Error("锁定Provider失败",
 			zap.Uint("providerId", providerID),
 			zap.String("error", utils.TruncateString(err.Error(), 200)))
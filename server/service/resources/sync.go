@@ -112,6 +112,33 @@ func (s *QuotaSyncService) extractLevelLimits(configMap map[string]interface{})
 				}
 			}
 
+			// 解析 MaxSnapshots
+			if maxSnapshots, exists := limitMap["max-snapshots"]; exists {
+				if snapshots, ok := maxSnapshots.(float64); ok {
+					levelLimit.MaxSnapshots = int(snapshots)
+				} else if snapshots, ok := maxSnapshots.(int); ok {
+					levelLimit.MaxSnapshots = snapshots
+				}
+			}
+
+			// 解析 MaxBackups
+			if maxBackups, exists := limitMap["max-backups"]; exists {
+				if backups, ok := maxBackups.(float64); ok {
+					levelLimit.MaxBackups = int(backups)
+				} else if backups, ok := maxBackups.(int); ok {
+					levelLimit.MaxBackups = backups
+				}
+			}
+
+			// 解析 MaxDomains
+			if maxDomains, exists := limitMap["max-domains"]; exists {
+				if domains, ok := maxDomains.(float64); ok {
+					levelLimit.MaxDomains = int(domains)
+				} else if domains, ok := maxDomains.(int); ok {
+					levelLimit.MaxDomains = domains
+				}
+			}
+
 			// 解析 MaxResources
 			if maxResources, exists := limitMap["max-resources"]; exists {
 				if resourcesMap, ok := maxResources.(map[string]interface{}); ok {
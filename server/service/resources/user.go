@@ -23,10 +23,9 @@ func (s *UserDashboardService) GetUserDashboard(userID uint) (*userModel.UserDas
 	cacheKey := cache.MakeUserDashboardKey(userID)
 
 	// 尝试从缓存获取
-	if cachedData, ok := cacheService.Get(cacheKey); ok {
-		if dashboard, ok := cachedData.(*userModel.UserDashboardResponse); ok {
-			return dashboard, nil
-		}
+	var cached userModel.UserDashboardResponse
+	if cacheService.GetJSON(cacheKey, &cached) {
+		return &cached, nil
 	}
 
 	// 缓存未命中，查询数据库
@@ -36,7 +35,7 @@ func (s *UserDashboardService) GetUserDashboard(userID uint) (*userModel.UserDas
 	}
 
 	// 缓存结果
-	cacheService.Set(cacheKey, dashboard, cache.TTLUserDashboard)
+	cacheService.SetJSON(cacheKey, dashboard, cache.TTLUserDashboard)
 	return dashboard, nil
 }
 
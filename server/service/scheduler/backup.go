@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/backup"
+
+	"go.uber.org/zap"
+)
+
+// BackupSchedulerService 定时备份调度服务
+type BackupSchedulerService struct {
+	backupService *backup.Service
+	stopChan      chan struct{}
+	isRunning     bool
+}
+
+// NewBackupSchedulerService 创建定时备份调度服务
+func NewBackupSchedulerService() *BackupSchedulerService {
+	return &BackupSchedulerService{
+		backupService: backup.NewService(),
+		stopChan:      make(chan struct{}),
+		isRunning:     false,
+	}
+}
+
+// Start 启动定时备份调度器
+func (s *BackupSchedulerService) Start(ctx context.Context) {
+	if s.isRunning {
+		global.APP_LOG.Warn("定时备份调度器已在运行中")
+		return
+	}
+
+	s.isRunning = true
+	global.APP_LOG.Info("启动定时备份调度器")
+
+	go s.startBackupTask(ctx)
+}
+
+// Stop 停止定时备份调度器
+func (s *BackupSchedulerService) Stop() {
+	if !s.isRunning {
+		return
+	}
+
+	global.APP_LOG.Info("停止定时备份调度器")
+	close(s.stopChan)
+	s.isRunning = false
+}
+
+// IsRunning 检查调度器是否正在运行
+func (s *BackupSchedulerService) IsRunning() bool {
+	return s.isRunning
+}
+
+// startBackupTask 每5分钟检查一次到期的备份计划
+func (s *BackupSchedulerService) startBackupTask(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("定时备份goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		global.APP_LOG.Info("定时备份任务已停止")
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if global.APP_DB == nil {
+				continue
+			}
+			s.backupService.RunDueSchedules()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/billing"
+
+	"go.uber.org/zap"
+)
+
+// BillingSchedulerService 实例计费调度服务
+type BillingSchedulerService struct {
+	billingService *billing.Service
+	stopChan       chan struct{}
+	isRunning      bool
+}
+
+// NewBillingSchedulerService 创建实例计费调度服务
+func NewBillingSchedulerService() *BillingSchedulerService {
+	return &BillingSchedulerService{
+		billingService: billing.NewService(),
+		stopChan:       make(chan struct{}),
+		isRunning:      false,
+	}
+}
+
+// Start 启动计费调度器
+func (s *BillingSchedulerService) Start(ctx context.Context) {
+	if s.isRunning {
+		global.APP_LOG.Warn("计费调度器已在运行中")
+		return
+	}
+
+	s.isRunning = true
+	global.APP_LOG.Info("启动计费调度器")
+
+	go s.startBillingTask(ctx)
+}
+
+// Stop 停止计费调度器
+func (s *BillingSchedulerService) Stop() {
+	if !s.isRunning {
+		return
+	}
+
+	global.APP_LOG.Info("停止计费调度器")
+	close(s.stopChan)
+	s.isRunning = false
+}
+
+// IsRunning 检查调度器是否正在运行
+func (s *BillingSchedulerService) IsRunning() bool {
+	return s.isRunning
+}
+
+// startBillingTask 每分钟检查一次，距上次计费超过配置的计费间隔时执行一轮计费
+// 计费间隔在运行中修改后无需重启即可生效
+func (s *BillingSchedulerService) startBillingTask(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("计费goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		global.APP_LOG.Info("计费任务已停止")
+	}()
+
+	var lastRun time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if global.APP_DB == nil || !global.APP_CONFIG.Billing.Enabled {
+				continue
+			}
+			interval := time.Duration(global.APP_CONFIG.Billing.ChargeInterval) * time.Minute
+			if interval <= 0 {
+				interval = 10 * time.Minute
+			}
+			if time.Since(lastRun) < interval {
+				continue
+			}
+			lastRun = time.Now()
+			if err := s.billingService.RunCharges(); err != nil {
+				global.APP_LOG.Error("执行实例计费失败", zap.Error(err))
+			}
+		}
+	}
+}
@@ -5,7 +5,10 @@ import (
 
 	"oneclickvirt/global"
 	"oneclickvirt/model/provider"
+	"oneclickvirt/model/system"
 	"oneclickvirt/model/user"
+	"oneclickvirt/service/notify"
+	"oneclickvirt/service/webhook"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -54,7 +57,8 @@ func (s *ExpiryFreezeService) CheckAndFreezeExpiredProviders() error {
 
 // freezeProvider 冻结Provider及其非手动设置过期时间的实例
 func (s *ExpiryFreezeService) freezeProvider(p *provider.Provider) error {
-	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+	var frozenInstances []provider.Instance
+	err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
 
 		// 1. 冻结Provider
@@ -68,6 +72,11 @@ func (s *ExpiryFreezeService) freezeProvider(p *provider.Provider) error {
 
 		// 2. 冻结该Provider下所有未手动设置过期时间的实例
 		// 手动设置了过期时间的实例不受节点冻结影响
+		if err := tx.Select("id, name, user_id").
+			Where("provider_id = ? AND is_manual_expiry = ? AND is_frozen = ?", p.ID, false, false).
+			Find(&frozenInstances).Error; err != nil {
+			return err
+		}
 		if err := tx.Model(&provider.Instance{}).
 			Where("provider_id = ? AND is_manual_expiry = ? AND is_frozen = ?", p.ID, false, false).
 			Updates(map[string]interface{}{
@@ -80,6 +89,22 @@ func (s *ExpiryFreezeService) freezeProvider(p *provider.Provider) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range frozenInstances {
+		webhook.Publish(system.WebhookEventInstanceFrozen, map[string]interface{}{
+			"instanceId":   inst.ID,
+			"instanceName": inst.Name,
+			"userId":       inst.UserID,
+			"providerId":   p.ID,
+			"providerName": p.Name,
+			"reason":       "node_frozen",
+		})
+		notify.NotifyFrozen(inst.UserID, "实例 "+inst.Name, "node_frozen")
+	}
+	return nil
 }
 
 // CheckAndFreezeExpiredInstances 检查并冻结过期的实例
@@ -124,11 +149,23 @@ func (s *ExpiryFreezeService) CheckAndFreezeExpiredInstances() error {
 func (s *ExpiryFreezeService) freezeInstance(inst *provider.Instance) error {
 	now := time.Now()
 
-	return global.APP_DB.Model(inst).Updates(map[string]interface{}{
+	if err := global.APP_DB.Model(inst).Updates(map[string]interface{}{
 		"is_frozen":     true,
 		"frozen_at":     now,
 		"frozen_reason": "expired",
-	}).Error
+	}).Error; err != nil {
+		return err
+	}
+
+	webhook.Publish(system.WebhookEventInstanceExpired, map[string]interface{}{
+		"instanceId":   inst.ID,
+		"instanceName": inst.Name,
+		"userId":       inst.UserID,
+		"providerId":   inst.ProviderID,
+		"expiresAt":    inst.ExpiresAt,
+	})
+	notify.NotifyFrozen(inst.UserID, "实例 "+inst.Name, "expired")
+	return nil
 }
 
 // CheckAndFreezeExpiredUsers 检查并冻结过期的用户
@@ -171,7 +208,11 @@ func (s *ExpiryFreezeService) CheckAndFreezeExpiredUsers() error {
 
 // disableUser 禁用单个过期用户
 func (s *ExpiryFreezeService) disableUser(u *user.User) error {
-	return global.APP_DB.Model(u).Update("status", 0).Error
+	if err := global.APP_DB.Model(u).Update("status", 0).Error; err != nil {
+		return err
+	}
+	notify.NotifyFrozen(u.ID, "您的账号", "expired")
+	return nil
 }
 
 // CheckAndFreezeAll 检查并冻结所有过期的资源
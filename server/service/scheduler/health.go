@@ -7,7 +7,10 @@ import (
 
 	"oneclickvirt/global"
 	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
 	adminProviderService "oneclickvirt/service/admin/provider"
+	"oneclickvirt/service/healthhistory"
+	"oneclickvirt/service/webhook"
 
 	"go.uber.org/zap"
 )
@@ -19,6 +22,7 @@ type ProviderHealthSchedulerService struct {
 	isRunning       bool
 	maxConcurrency  int           // 最大并发数
 	semaphore       chan struct{} // 信号量，用于限制并发
+	lastCleanup     time.Time     // 上次清理健康检查历史的时间
 }
 
 // NewProviderHealthSchedulerService 创建Provider健康检查调度服务
@@ -105,6 +109,12 @@ func (s *ProviderHealthSchedulerService) startHealthCheckTask(ctx context.Contex
 			ticker.Reset(newInterval)
 
 			s.checkAllProvidersHealth()
+
+			// 每小时清理一次过期的健康检查历史
+			if time.Since(s.lastCleanup) >= time.Hour {
+				s.lastCleanup = time.Now()
+				healthhistory.NewService().Cleanup()
+			}
 		}
 	}
 }
@@ -247,6 +257,15 @@ func (s *ProviderHealthSchedulerService) checkSingleProviderHealth(provider prov
 				zap.String("provider_name", providerName),
 				zap.String("ssh_status", updatedProvider.SSHStatus),
 				zap.String("api_status", updatedProvider.APIStatus))
+			webhook.Publish(systemModel.WebhookEventProviderUnhealthy, map[string]interface{}{
+				"providerId":   providerID,
+				"providerName": providerName,
+				"providerType": providerType,
+				"oldStatus":    oldStatus,
+				"status":       updatedProvider.Status,
+				"sshStatus":    updatedProvider.SSHStatus,
+				"apiStatus":    updatedProvider.APIStatus,
+			})
 		} else if updatedProvider.Status == "active" && oldStatus != "active" {
 			// Provider恢复在线，允许申领新实例
 			s.updateProviderAllowClaim(providerID, true)
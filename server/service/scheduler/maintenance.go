@@ -7,14 +7,19 @@ import (
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	"oneclickvirt/model/provider"
+	"oneclickvirt/service/audit"
+	"oneclickvirt/service/domain"
 	"oneclickvirt/service/system"
 	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 )
 
-// cleanupTimeoutTasks 清理超时任务
+// cleanupTimeoutTasks 清理超时任务和执行实例已停止的任务
 func (s *SchedulerService) cleanupTimeoutTasks() {
+	// 执行实例宕机或重启后，其running状态的任务在心跳过期后标记为失败
+	s.taskService.CleanupInterruptedTasks()
+
 	timeoutThreshold := time.Now().Add(-30 * time.Minute)
 
 	// 使用TaskService的新方法清理超时任务并释放锁
@@ -44,6 +49,12 @@ func (s *SchedulerService) performMaintenance() {
 
 	// 清理旧的任务记录（可选）
 	s.cleanupOldTasks()
+
+	// 清理超过保留天数的审计日志
+	audit.NewService().Cleanup()
+
+	// 清理长期未验证的域名绑定
+	domain.NewService().CleanupExpiredPending()
 }
 
 // cleanupExpiredInstances 清理过期实例
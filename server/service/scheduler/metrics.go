@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/instancemetrics"
+
+	"go.uber.org/zap"
+)
+
+// MetricsSchedulerService 实例资源使用采集调度服务
+type MetricsSchedulerService struct {
+	collector      *instancemetrics.Collector
+	metricsService *instancemetrics.Service
+	stopChan       chan struct{}
+	isRunning      bool
+}
+
+// NewMetricsSchedulerService 创建实例资源使用采集调度服务
+func NewMetricsSchedulerService() *MetricsSchedulerService {
+	return &MetricsSchedulerService{
+		collector:      instancemetrics.NewCollector(),
+		metricsService: instancemetrics.NewService(),
+		stopChan:       make(chan struct{}),
+		isRunning:      false,
+	}
+}
+
+// Start 启动资源采集调度器
+func (s *MetricsSchedulerService) Start(ctx context.Context) {
+	if s.isRunning {
+		global.APP_LOG.Warn("实例资源采集调度器已在运行中")
+		return
+	}
+
+	s.isRunning = true
+	global.APP_LOG.Info("启动实例资源采集调度器")
+
+	go s.startMetricsTask(ctx)
+}
+
+// Stop 停止资源采集调度器
+func (s *MetricsSchedulerService) Stop() {
+	if !s.isRunning {
+		return
+	}
+
+	global.APP_LOG.Info("停止实例资源采集调度器")
+	close(s.stopChan)
+	s.isRunning = false
+}
+
+// IsRunning 检查调度器是否正在运行
+func (s *MetricsSchedulerService) IsRunning() bool {
+	return s.isRunning
+}
+
+// startMetricsTask 每分钟检查一次，距上次采集超过配置的采集间隔时执行一轮采集；
+// 每小时将上一小时的原始采样汇总为小时数据并清理过期数据
+func (s *MetricsSchedulerService) startMetricsTask(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("实例资源采集goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		global.APP_LOG.Info("实例资源采集任务已停止")
+	}()
+
+	var lastCollect, lastDownsample time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if global.APP_DB == nil || !global.APP_CONFIG.Metrics.Enabled {
+				continue
+			}
+
+			if time.Since(lastCollect) >= instancemetrics.CollectInterval() {
+				lastCollect = time.Now()
+				s.collector.Collect()
+			}
+
+			if hour := time.Now().Truncate(time.Hour); hour.After(lastDownsample) {
+				lastDownsample = hour
+				if err := s.metricsService.Downsample(); err != nil {
+					global.APP_LOG.Error("汇总实例资源使用历史失败", zap.Error(err))
+				}
+				s.metricsService.Cleanup()
+			}
+		}
+	}
+}
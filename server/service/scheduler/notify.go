@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/notify"
+
+	"go.uber.org/zap"
+)
+
+// NotifySchedulerService 到期提醒调度服务
+type NotifySchedulerService struct {
+	notifyService *notify.Service
+	stopChan      chan struct{}
+	isRunning     bool
+}
+
+// NewNotifySchedulerService 创建到期提醒调度服务
+func NewNotifySchedulerService() *NotifySchedulerService {
+	return &NotifySchedulerService{
+		notifyService: notify.NewService(),
+		stopChan:      make(chan struct{}),
+		isRunning:     false,
+	}
+}
+
+// Start 启动到期提醒调度器
+func (s *NotifySchedulerService) Start(ctx context.Context) {
+	if s.isRunning {
+		global.APP_LOG.Warn("到期提醒调度器已在运行中")
+		return
+	}
+
+	s.isRunning = true
+	global.APP_LOG.Info("启动到期提醒调度器")
+
+	go s.startReminderTask(ctx)
+}
+
+// Stop 停止到期提醒调度器
+func (s *NotifySchedulerService) Stop() {
+	if !s.isRunning {
+		return
+	}
+
+	global.APP_LOG.Info("停止到期提醒调度器")
+	close(s.stopChan)
+	s.isRunning = false
+}
+
+// IsRunning 检查调度器是否正在运行
+func (s *NotifySchedulerService) IsRunning() bool {
+	return s.isRunning
+}
+
+// startReminderTask 每小时检查一次即将到期的用户、实例和节点
+func (s *NotifySchedulerService) startReminderTask(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer func() {
+		ticker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("到期提醒goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		global.APP_LOG.Info("到期提醒任务已停止")
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if global.APP_DB == nil {
+				continue
+			}
+			s.notifyService.RunExpiryReminders()
+		}
+	}
+}
@@ -32,6 +32,7 @@ type TaskServiceInterface interface {
 	StartTask(taskID uint) error
 	CancelTaskByAdmin(taskID uint, reason string) error
 	CleanupTimeoutTasksWithLockRelease(timeoutThreshold time.Time) (int64, int64)
+	CleanupInterruptedTasks()
 }
 
 // NewSchedulerService 创建新的调度器服务
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/service/webhook"
+
+	"go.uber.org/zap"
+)
+
+// WebhookSchedulerService Webhook重试调度服务
+type WebhookSchedulerService struct {
+	webhookService *webhook.Service
+	stopChan       chan struct{}
+	isRunning      bool
+}
+
+// NewWebhookSchedulerService 创建Webhook重试调度服务
+func NewWebhookSchedulerService() *WebhookSchedulerService {
+	return &WebhookSchedulerService{
+		webhookService: webhook.NewService(),
+		stopChan:       make(chan struct{}),
+		isRunning:      false,
+	}
+}
+
+// Start 启动Webhook重试调度器
+func (s *WebhookSchedulerService) Start(ctx context.Context) {
+	if s.isRunning {
+		global.APP_LOG.Warn("Webhook重试调度器已在运行中")
+		return
+	}
+
+	s.isRunning = true
+	global.APP_LOG.Info("启动Webhook重试调度器")
+
+	go s.startRetryTask(ctx)
+}
+
+// Stop 停止Webhook重试调度器
+func (s *WebhookSchedulerService) Stop() {
+	if !s.isRunning {
+		return
+	}
+
+	global.APP_LOG.Info("停止Webhook重试调度器")
+	close(s.stopChan)
+	s.isRunning = false
+}
+
+// IsRunning 检查调度器是否正在运行
+func (s *WebhookSchedulerService) IsRunning() bool {
+	return s.isRunning
+}
+
+// startRetryTask 每30秒投递一次到期的记录，每天清理一次过期的投递日志
+// 服务重启后未完成的投递仍保存在数据库中，会在此继续重试
+func (s *WebhookSchedulerService) startRetryTask(ctx context.Context) {
+	retryTicker := time.NewTicker(30 * time.Second)
+	cleanupTicker := time.NewTicker(24 * time.Hour)
+	defer func() {
+		retryTicker.Stop()
+		cleanupTicker.Stop()
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("Webhook重试goroutine panic",
+				zap.Any("panic", r),
+				zap.Stack("stack"))
+		}
+		global.APP_LOG.Info("Webhook重试任务已停止")
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-retryTicker.C:
+			if global.APP_DB == nil {
+				continue
+			}
+			s.webhookService.ProcessDueDeliveries()
+		case <-cleanupTicker.C:
+			if global.APP_DB == nil {
+				continue
+			}
+			s.webhookService.CleanupDeliveries()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// envelopePrefix 加密值前缀，数据库中不带此前缀的值视为尚未加密的明文
+const envelopePrefix = "enc:v1:"
+
+// dataKeySize 每个值独立生成的数据密钥长度
+const dataKeySize = 32
+
+// IsEncrypted 判断数据库中的值是否已加密
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// KeyID 返回加密值所用主密钥的ID，明文返回空
+func KeyID(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, envelopePrefix), ":")
+	return id
+}
+
+// Encrypt 使用信封加密保护敏感值
+// 每个值生成独立的数据密钥加密内容，数据密钥再由当前主密钥加密，
+// 结果格式为 enc:v1:<主密钥ID>:<加密的数据密钥>:<加密的内容>。空值和已加密的值原样返回。
+// 是否已加密以能否用密钥环解密为准，恰好以 enc:v1: 开头的明文仍会被加密，不会以明文写入后在读取时解密失败
+func Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return plaintext, nil
+	}
+	kr, err := currentKeyring()
+	if err != nil {
+		return "", err
+	}
+	if IsEncrypted(plaintext) {
+		if _, err := kr.decrypt(plaintext); err == nil {
+			return plaintext, nil
+		}
+	}
+	return kr.encrypt(plaintext)
+}
+
+// Decrypt 解密敏感值，未加密的明文（加密迁移前的历史数据）原样返回
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	kr, err := currentKeyring()
+	if err != nil {
+		return "", err
+	}
+	return kr.decrypt(value)
+}
+
+func (kr *Keyring) encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("生成数据密钥失败: %v", err)
+	}
+
+	sealedContent, err := seal(dataKey, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	// 主密钥ID作为附加数据参与认证，防止加密的数据密钥被挪到其他主密钥下
+	sealedKey, err := seal(kr.keys[kr.primaryID], dataKey, []byte(kr.primaryID))
+	if err != nil {
+		return "", err
+	}
+
+	return envelopePrefix + kr.primaryID + ":" +
+		base64.RawStdEncoding.EncodeToString(sealedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealedContent), nil
+}
+
+func (kr *Keyring) decrypt(value string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("加密值格式错误")
+	}
+	keyID := parts[0]
+	masterKey, ok := kr.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("主密钥 %s 不存在，请确认密钥文件或环境变量 %s 中包含该密钥", keyID, MasterKeyEnv)
+	}
+
+	sealedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("加密值格式错误: %v", err)
+	}
+	sealedContent, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("加密值格式错误: %v", err)
+	}
+
+	dataKey, err := open(masterKey, sealedKey, []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("解密数据密钥失败（主密钥 %s 不正确或数据已损坏）: %v", keyID, err)
+	}
+	plaintext, err := open(dataKey, sealedContent, nil)
+	if err != nil {
+		return "", fmt.Errorf("解密数据失败: %v", err)
+	}
+	return string(plaintext), nil
+}
+
+// seal 使用AES-256-GCM加密，随机nonce放在密文前
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成nonce失败: %v", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open 解密 seal 生成的数据
+func open(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("密文长度不足")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密算法失败: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密算法失败: %v", err)
+	}
+	return gcm, nil
+}
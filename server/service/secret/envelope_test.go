@@ -0,0 +1,168 @@
+package secret
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	providerModel "oneclickvirt/model/provider"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func mustKeyring(t *testing.T, lines ...string) *Keyring {
+	t.Helper()
+	kr, err := ParseKeyring(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatalf("ParseKeyring: %v", err)
+	}
+	return kr
+}
+
+func mustKeyLine(t *testing.T, id string) string {
+	t.Helper()
+	line, err := GenerateKeyLine()
+	if err != nil {
+		t.Fatalf("GenerateKeyLine: %v", err)
+	}
+	_, key, _ := strings.Cut(line, ":")
+	return id + ":" + key
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	oldKey, newKey := mustKeyLine(t, "old"), mustKeyLine(t, "new")
+	SetKeyring(mustKeyring(t, oldKey))
+	defer SetKeyring(nil)
+
+	ciphertext, err := Encrypt("root-password")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(ciphertext) || KeyID(ciphertext) != "old" || strings.Contains(ciphertext, "root-password") {
+		t.Fatalf("密文格式不正确: %s", ciphertext)
+	}
+	if again, _ := Encrypt(ciphertext); again != ciphertext {
+		t.Error("已加密的值不应重复加密")
+	}
+	if plain, _ := Decrypt("legacy-plaintext"); plain != "legacy-plaintext" {
+		t.Error("未加密的历史明文应原样返回")
+	}
+
+	// 以加密前缀开头但无法解密的明文仍需加密，读取时得到原值
+	for _, lookalike := range []string{"enc:v1:not-a-ciphertext", "enc:v1:old:AAAA:BBBB"} {
+		sealed, err := Encrypt(lookalike)
+		if err != nil || sealed == lookalike {
+			t.Fatalf("形似密文的明文应被加密: (%q, %v)", sealed, err)
+		}
+		if plain, err := Decrypt(sealed); err != nil || plain != lookalike {
+			t.Errorf("解密形似密文的明文 = (%q, %v)", plain, err)
+		}
+	}
+
+	// 轮换后新密钥在第一行，旧密钥仍可解密历史数据
+	SetKeyring(mustKeyring(t, newKey, oldKey))
+	if plain, err := Decrypt(ciphertext); err != nil || plain != "root-password" {
+		t.Fatalf("轮换后解密旧数据 = (%q, %v)", plain, err)
+	}
+	if rotated, _ := Encrypt("root-password"); KeyID(rotated) != "new" {
+		t.Errorf("新数据应使用当前密钥加密，实际为 %s", KeyID(rotated))
+	}
+
+	// 移除旧密钥后无法解密
+	SetKeyring(mustKeyring(t, newKey))
+	if _, err := Decrypt(ciphertext); err == nil {
+		t.Error("缺少对应主密钥时应解密失败")
+	}
+
+	// 篡改密钥ID后认证失败
+	SetKeyring(mustKeyring(t, oldKey, "new:"+strings.SplitN(oldKey, ":", 2)[1]))
+	if _, err := Decrypt(strings.Replace(ciphertext, ":old:", ":new:", 1)); err == nil {
+		t.Error("篡改主密钥ID后应解密失败")
+	}
+}
+
+func TestParseKeyringErrors(t *testing.T) {
+	for _, data := range []string{"", "# only comment", "nokey", "k1:not-base64!", "k1:AAAA", mustKeyLine(t, "dup") + "," + mustKeyLine(t, "dup")} {
+		if _, err := ParseKeyring(data); err == nil {
+			t.Errorf("ParseKeyring(%q) 应返回错误", data)
+		}
+	}
+}
+
+func TestUpdateByColumnIsSealed(t *testing.T) {
+	SetKeyring(mustKeyring(t, mustKeyLine(t, "k1")))
+	defer SetKeyring(nil)
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:3306)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, SkipDefaultTransaction: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := RegisterCallbacks(db); err != nil {
+		t.Fatalf("RegisterCallbacks: %v", err)
+	}
+
+	instance := providerModel.Instance{}
+	instance.ID = 1
+	updates := map[string]interface{}{"password": "new-password", "status": "running"}
+	tx := db.Model(&instance).Updates(updates)
+	if tx.Error != nil {
+		t.Fatalf("Updates: %v", tx.Error)
+	}
+	stmt := tx.Statement
+
+	var sealed bool
+	for _, v := range stmt.Vars {
+		if s, ok := v.(sealedValue); ok {
+			sealed = true
+			value, err := s.Value()
+			if err != nil || !IsEncrypted(value.(string)) {
+				t.Errorf("写入数据库的值未加密: %v, %v", value, err)
+			}
+		}
+		if v == "new-password" {
+			t.Error("SQL参数中包含明文密码")
+		}
+	}
+	if !sealed {
+		t.Errorf("按列名更新的加密字段未被替换: %v", stmt.Vars)
+	}
+	if instance.Password != "new-password" {
+		t.Errorf("模型字段应保持明文, 实际 %q", instance.Password)
+	}
+	if updates["password"] != "new-password" {
+		t.Error("不应修改调用方传入的map")
+	}
+}
+
+func TestLoadKeyringDoesNotGenerate(t *testing.T) {
+	t.Setenv(MasterKeyEnv, "")
+	keyFile := t.TempDir() + "/master.key"
+
+	// 密钥文件不存在时不自动生成，由调用方确认数据库中没有加密数据后再生成
+	if _, _, err := LoadKeyring(keyFile); !errors.Is(err, ErrKeyringNotFound) {
+		t.Fatalf("密钥文件不存在时应返回 ErrKeyringNotFound，实际: %v", err)
+	}
+
+	kr, _, generated, err := GenerateKeyFile(keyFile)
+	if err != nil || !generated {
+		t.Fatalf("GenerateKeyFile = (%v, %v)", generated, err)
+	}
+	loaded, _, err := LoadKeyring(keyFile)
+	if err != nil || loaded.PrimaryID() != kr.PrimaryID() {
+		t.Fatalf("应读取已生成的密钥文件: %v", err)
+	}
+	if _, _, generated, _ := GenerateKeyFile(keyFile); generated {
+		t.Error("密钥文件已存在时不应覆盖")
+	}
+
+	SetKeyring(kr)
+	defer SetKeyring(nil)
+	if missing := MissingKeys([]string{kr.PrimaryID(), "lost"}); len(missing) != 1 || missing[0] != "lost" {
+		t.Errorf("MissingKeys = %v", missing)
+	}
+}
@@ -0,0 +1,104 @@
+package secret
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName 模型字段使用的序列化器名称，如 `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, EncryptedSerializer{})
+}
+
+// EncryptedSerializer 字符串字段的加密序列化器，写入时加密，读取时解密
+type EncryptedSerializer struct{}
+
+// Scan 读取时解密，兼容尚未迁移的明文
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var raw string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("加密字段 %s 的数据库类型不支持: %T", field.Name, dbValue)
+	}
+
+	plaintext, err := Decrypt(raw)
+	if err != nil {
+		return fmt.Errorf("解密字段 %s 失败: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value 写入时加密
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("加密字段 %s 只支持字符串类型", field.Name)
+	}
+	ciphertext, err := Encrypt(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("加密字段 %s 失败: %w", field.Name, err)
+	}
+	return ciphertext, nil
+}
+
+// sealedValue 按列名更新时的待加密值
+// 可转换为string，GORM把它赋回模型字段时得到明文；写入数据库时通过driver.Valuer加密
+type sealedValue string
+
+// Value 实现 driver.Valuer
+func (v sealedValue) Value() (driver.Value, error) {
+	return Encrypt(string(v))
+}
+
+// RegisterCallbacks 注册更新回调
+// 序列化器只在以结构体写入时生效，Update("password", ...) 和 Updates(map) 会绕过序列化器，
+// 这里在更新前把加密字段的值替换为 sealedValue，保证各种写法都不会写入明文
+func RegisterCallbacks(db *gorm.DB) error {
+	return db.Callback().Update().Before("gorm:update").Register("secret:encrypt_map_values", encryptMapValues)
+}
+
+func encryptMapValues(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	values, ok := db.Statement.Dest.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	var replaced map[string]interface{}
+	for k, v := range values {
+		plaintext, ok := v.(string)
+		if !ok {
+			continue
+		}
+		field := db.Statement.Schema.LookUpField(k)
+		if field == nil || field.TagSettings["SERIALIZER"] != SerializerName {
+			continue
+		}
+		// 复制一份，避免修改调用方传入的map
+		if replaced == nil {
+			replaced = make(map[string]interface{}, len(values))
+			for key, value := range values {
+				replaced[key] = value
+			}
+		}
+		replaced[k] = sealedValue(plaintext)
+	}
+	if replaced != nil {
+		db.Statement.Dest = replaced
+	}
+}
@@ -0,0 +1,197 @@
+package secret
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MasterKeyEnv 主密钥环境变量，设置后优先于密钥文件
+const MasterKeyEnv = "ONECLICKVIRT_MASTER_KEY"
+
+// masterKeySize 主密钥长度（AES-256）
+const masterKeySize = 32
+
+// ErrNoMasterKey 主密钥未加载
+var ErrNoMasterKey = errors.New("主密钥未加载，无法加解密敏感字段")
+
+// ErrKeyringNotFound 既未设置环境变量也不存在密钥文件
+var ErrKeyringNotFound = errors.New("未找到主密钥")
+
+// Keyring 主密钥环
+// 每行一个密钥，格式为 "<密钥ID>:<base64编码的32字节密钥>"，第一行为当前密钥用于加密，
+// 其余为轮换前的旧密钥，只用于解密尚未重新加密的数据
+type Keyring struct {
+	primaryID string
+	keys      map[string][]byte
+}
+
+var (
+	keyringMu     sync.RWMutex
+	activeKeyring *Keyring
+)
+
+// ParseKeyring 解析密钥环文本，行之间可以用换行或逗号分隔，#开头的行为注释
+func ParseKeyring(data string) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[string][]byte)}
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(data, ",", "\n")))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(line, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("密钥格式错误，应为 <密钥ID>:<base64密钥>")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 不是有效的base64: %v", id, err)
+		}
+		if len(key) != masterKeySize {
+			return nil, fmt.Errorf("密钥 %s 长度为 %d 字节，应为 %d 字节", id, len(key), masterKeySize)
+		}
+		if _, exists := kr.keys[id]; exists {
+			return nil, fmt.Errorf("密钥ID %s 重复", id)
+		}
+
+		kr.keys[id] = key
+		if kr.primaryID == "" {
+			kr.primaryID = id
+		}
+	}
+
+	if kr.primaryID == "" {
+		return nil, fmt.Errorf("未找到任何密钥")
+	}
+	return kr, nil
+}
+
+// PrimaryID 当前用于加密的密钥ID
+func (kr *Keyring) PrimaryID() string {
+	return kr.primaryID
+}
+
+// Len 密钥环中的密钥数量
+func (kr *Keyring) Len() int {
+	return len(kr.keys)
+}
+
+// GenerateKeyLine 生成一行新的主密钥，可直接写入密钥文件或环境变量
+func GenerateKeyLine() (string, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("生成主密钥失败: %v", err)
+	}
+	id := "k" + time.Now().Format("20060102150405")
+	return id + ":" + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadKeyring 加载主密钥环：优先读取环境变量，其次读取密钥文件，两者都不存在时返回 ErrKeyringNotFound
+// 是否可以生成新密钥取决于数据库中是否已有加密数据，由调用方连接数据库后决定
+func LoadKeyring(keyFile string) (kr *Keyring, source string, err error) {
+	if env := strings.TrimSpace(os.Getenv(MasterKeyEnv)); env != "" {
+		kr, err = ParseKeyring(env)
+		if err != nil {
+			return nil, "", fmt.Errorf("解析环境变量 %s 失败: %v", MasterKeyEnv, err)
+		}
+		return kr, "env:" + MasterKeyEnv, nil
+	}
+
+	keyFile = keyFilePath(keyFile)
+	data, err := os.ReadFile(keyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "file:" + keyFile, ErrKeyringNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("读取主密钥文件失败: %v", err)
+	}
+
+	kr, err = ParseKeyring(string(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("解析主密钥文件 %s 失败: %v", keyFile, err)
+	}
+	return kr, "file:" + keyFile, nil
+}
+
+// GenerateKeyFile 生成新的主密钥并写入密钥文件，文件已存在时读取已有的密钥
+// 只能在确认数据库中没有加密数据后调用，否则新密钥无法解密已有数据
+func GenerateKeyFile(keyFile string) (kr *Keyring, source string, generated bool, err error) {
+	keyFile = keyFilePath(keyFile)
+	line, err := GenerateKeyLine()
+	if err != nil {
+		return nil, "", false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, "", false, fmt.Errorf("创建主密钥目录失败: %v", err)
+	}
+	// O_EXCL 避免多个进程同时启动时互相覆盖
+	f, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			kr, source, err = LoadKeyring(keyFile)
+			return kr, source, false, err
+		}
+		return nil, "", false, fmt.Errorf("写入主密钥文件失败: %v", err)
+	}
+	_, writeErr := f.WriteString(line + "\n")
+	closeErr := f.Close()
+	if writeErr != nil || closeErr != nil {
+		return nil, "", false, fmt.Errorf("写入主密钥文件失败: %v", errors.Join(writeErr, closeErr))
+	}
+	kr, err = ParseKeyring(line)
+	return kr, "file:" + keyFile, true, err
+}
+
+// keyFilePath 密钥文件路径，未配置时使用默认路径
+func keyFilePath(keyFile string) string {
+	if keyFile == "" {
+		return "storage/master.key"
+	}
+	return keyFile
+}
+
+// SetKeyring 设置全局使用的密钥环
+func SetKeyring(kr *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	activeKeyring = kr
+}
+
+// KeyringLoaded 是否已设置全局密钥环
+func KeyringLoaded() bool {
+	_, err := currentKeyring()
+	return err == nil
+}
+
+// MissingKeys 返回全局密钥环中不存在的密钥ID，未加载密钥环时全部视为缺失
+func MissingKeys(ids []string) []string {
+	kr, _ := currentKeyring()
+	var missing []string
+	for _, id := range ids {
+		if kr == nil || kr.keys[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// currentKeyring 获取全局密钥环
+func currentKeyring() (*Keyring, error) {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	if activeKeyring == nil {
+		return nil, ErrNoMasterKey
+	}
+	return activeKeyring, nil
+}
@@ -0,0 +1,238 @@
+package secret
+
+import (
+	"fmt"
+	"sort"
+
+	providerModel "oneclickvirt/model/provider"
+
+	"gorm.io/gorm"
+)
+
+// encryptedModels 包含加密字段的模型，新增加密字段的模型需要加入此列表才会被迁移和轮换
+var encryptedModels = []interface{}{
+	&providerModel.Provider{},
+	&providerModel.Instance{},
+}
+
+// migrateBatchSize 每批处理的行数
+const migrateBatchSize = 200
+
+// MigrateStats 加密迁移结果
+type MigrateStats struct {
+	Table     string
+	Rows      int // 扫描的行数
+	Encrypted int // 新加密的明文值数量
+	Rotated   int // 由旧主密钥重新加密的值数量
+	Skipped   int // 处理期间被其他进程修改而跳过的行数
+}
+
+// EncryptExisting 加密数据库中尚未加密的历史明文
+func EncryptExisting(db *gorm.DB) ([]MigrateStats, error) {
+	return reencrypt(db, false)
+}
+
+// Rotate 使用当前主密钥重新加密所有值：历史明文会被加密，旧主密钥加密的值会被重新加密
+// 完成后即可从密钥环中移除旧密钥
+func Rotate(db *gorm.DB) ([]MigrateStats, error) {
+	return reencrypt(db, true)
+}
+
+func reencrypt(db *gorm.DB, rotate bool) ([]MigrateStats, error) {
+	kr, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := encryptedTables(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]MigrateStats, 0, len(tables))
+	for _, t := range tables {
+		stats, err := reencryptTable(db, kr, t.table, t.pk, t.columns, rotate)
+		result = append(result, stats)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// encryptedTable 包含加密字段的表
+type encryptedTable struct {
+	table   string
+	pk      string
+	columns []string
+}
+
+// encryptedTables 解析 encryptedModels 中的表名、主键和加密列
+func encryptedTables(db *gorm.DB) ([]encryptedTable, error) {
+	tables := make([]encryptedTable, 0, len(encryptedModels))
+	for _, model := range encryptedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("解析模型失败: %v", err)
+		}
+		var columns []string
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && field.TagSettings["SERIALIZER"] == SerializerName {
+				columns = append(columns, field.DBName)
+			}
+		}
+		if len(columns) == 0 || stmt.Schema.PrioritizedPrimaryField == nil {
+			continue
+		}
+		tables = append(tables, encryptedTable{
+			table:   stmt.Schema.Table,
+			pk:      stmt.Schema.PrioritizedPrimaryField.DBName,
+			columns: columns,
+		})
+	}
+	return tables, nil
+}
+
+// StoredKeyIDs 返回数据库中已加密的值所用的主密钥ID（去重），包含软删除的行
+// 只读取每个值的开头部分，密钥ID位于前缀之后的第一段
+func StoredKeyIDs(db *gorm.DB) ([]string, error) {
+	tables, err := encryptedTables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, t := range tables {
+		for _, column := range t.columns {
+			var heads []string
+			if err := db.Table(t.table).
+				Where(column+" LIKE ?", envelopePrefix+"%").
+				Pluck("SUBSTR("+column+", 1, 128)", &heads).Error; err != nil {
+				return nil, fmt.Errorf("读取表 %s 的加密字段失败: %v", t.table, err)
+			}
+			for _, head := range heads {
+				if id := KeyID(head); id != "" && !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// reencryptTable 按主键分批处理一张表，直接读写原始列值（不经过序列化器），包含软删除的行
+func reencryptTable(db *gorm.DB, kr *Keyring, table, pk string, columns []string, rotate bool) (MigrateStats, error) {
+	stats := MigrateStats{Table: table}
+	selectColumns := append([]string{pk}, columns...)
+
+	var lastID uint64
+	for {
+		var rows []map[string]interface{}
+		if err := db.Table(table).Select(selectColumns).
+			Where(pk+" > ?", lastID).Order(pk).Limit(migrateBatchSize).
+			Find(&rows).Error; err != nil {
+			return stats, fmt.Errorf("读取表 %s 失败: %v", table, err)
+		}
+		if len(rows) == 0 {
+			return stats, nil
+		}
+
+		for _, row := range rows {
+			id, err := toUint64(row[pk])
+			if err != nil {
+				return stats, fmt.Errorf("表 %s 主键格式错误: %v", table, err)
+			}
+			lastID = id
+			stats.Rows++
+
+			updates := make(map[string]interface{})
+			query := db.Table(table).Where(pk+" = ?", id)
+			encrypted, rotated := 0, 0
+			for _, column := range columns {
+				raw, ok := toString(row[column])
+				if !ok || raw == "" {
+					continue
+				}
+				if IsEncrypted(raw) && (!rotate || KeyID(raw) == kr.primaryID) {
+					continue
+				}
+
+				plaintext, err := kr.decryptOrPlain(raw)
+				if err != nil {
+					return stats, fmt.Errorf("表 %s 第 %d 行字段 %s: %v", table, id, column, err)
+				}
+				ciphertext, err := kr.encrypt(plaintext)
+				if err != nil {
+					return stats, err
+				}
+				updates[column] = ciphertext
+				// 仅当值未被其他进程修改时才更新，避免覆盖并发写入
+				query = query.Where(column+" = ?", raw)
+				if IsEncrypted(raw) {
+					rotated++
+				} else {
+					encrypted++
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			res := query.UpdateColumns(updates)
+			if res.Error != nil {
+				return stats, fmt.Errorf("更新表 %s 第 %d 行失败: %v", table, id, res.Error)
+			}
+			if res.RowsAffected == 0 {
+				stats.Skipped++
+				continue
+			}
+			stats.Encrypted += encrypted
+			stats.Rotated += rotated
+		}
+	}
+}
+
+func (kr *Keyring) decryptOrPlain(raw string) (string, error) {
+	if !IsEncrypted(raw) {
+		return raw, nil
+	}
+	return kr.decrypt(raw)
+}
+
+func toString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	}
+	return "", false
+}
+
+func toUint64(v interface{}) (uint64, error) {
+	switch n := v.(type) {
+	case int64:
+		return uint64(n), nil
+	case uint64:
+		return n, nil
+	case int32:
+		return uint64(n), nil
+	case uint32:
+		return uint64(n), nil
+	case int:
+		return uint64(n), nil
+	case uint:
+		return uint64(n), nil
+	case []byte:
+		var id uint64
+		_, err := fmt.Sscan(string(n), &id)
+		return id, err
+	case string:
+		var id uint64
+		_, err := fmt.Sscan(n, &id)
+		return id, err
+	}
+	return 0, fmt.Errorf("不支持的主键类型 %T", v)
+}
@@ -0,0 +1,44 @@
+//go:build cgo
+
+package secret
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestStoredKeyIDs(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/secret.db"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	// 只创建加密字段涉及的列
+	for _, ddl := range []string{
+		"CREATE TABLE providers (id INTEGER PRIMARY KEY, name TEXT, password TEXT, ssh_key TEXT, token TEXT, auth_config TEXT, key_content TEXT, token_content TEXT)",
+		"CREATE TABLE instances (id INTEGER PRIMARY KEY, name TEXT, password TEXT, deleted_at DATETIME)",
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatalf("创建表失败: %v", err)
+		}
+	}
+
+	if ids, err := StoredKeyIDs(db); err != nil || len(ids) != 0 {
+		t.Fatalf("空数据库 StoredKeyIDs = (%v, %v)", ids, err)
+	}
+
+	SetKeyring(mustKeyring(t, mustKeyLine(t, "k2"), mustKeyLine(t, "k1")))
+	defer SetKeyring(nil)
+	sealed, _ := Encrypt("secret")
+	db.Exec("INSERT INTO providers (name, password, token) VALUES (?, ?, ?)", "p1", sealed, "legacy-plaintext")
+	db.Exec("INSERT INTO instances (name, password, deleted_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
+		"i1", "enc:v1:k1:"+sealed[len("enc:v1:k2:"):])
+
+	// 软删除的行同样计入，明文不计入
+	ids, err := StoredKeyIDs(db)
+	if err != nil || len(ids) != 2 || ids[0] != "k1" || ids[1] != "k2" {
+		t.Fatalf("StoredKeyIDs = (%v, %v)", ids, err)
+	}
+}
@@ -0,0 +1,481 @@
+package securitygroup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/task"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	maxGroupsPerUser = 20 // 每个用户最多创建的安全组数量
+	maxRulesPerGroup = 50 // 每个安全组最多的规则数量
+)
+
+// 由接口层映射为对应的响应码
+var (
+	ErrInstanceNotFound = errors.New("实例不存在或无权限")
+	ErrGroupNotFound    = errors.New("安全组不存在")
+)
+
+// Service 安全组服务
+type Service struct{}
+
+// NewService 创建安全组服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// validateRules 校验并规范化规则：单个地址补全为/32或/128，CIDR取网络地址
+func validateRules(reqs []user.SecurityGroupRuleRequest) ([]providerModel.SecurityGroupRule, error) {
+	if len(reqs) > maxRulesPerGroup {
+		return nil, fmt.Errorf("规则数量不能超过%d条", maxRulesPerGroup)
+	}
+
+	rules := make([]providerModel.SecurityGroupRule, 0, len(reqs))
+	for i, r := range reqs {
+		index := i + 1
+		if r.Action != providerModel.SecurityGroupActionAllow && r.Action != providerModel.SecurityGroupActionDeny {
+			return nil, fmt.Errorf("第%d条规则的动作无效", index)
+		}
+
+		portStart, portEnd := r.PortStart, r.PortEnd
+		switch r.Protocol {
+		case providerModel.SecurityGroupProtocolTCP, providerModel.SecurityGroupProtocolUDP:
+			if portStart == 0 && portEnd != 0 {
+				return nil, fmt.Errorf("第%d条规则缺少起始端口", index)
+			}
+			if portEnd == 0 {
+				portEnd = portStart
+			}
+			if portStart < 0 || portEnd > 65535 || portEnd < portStart {
+				return nil, fmt.Errorf("第%d条规则的端口范围无效", index)
+			}
+		case providerModel.SecurityGroupProtocolICMP, providerModel.SecurityGroupProtocolAll:
+			if portStart != 0 || portEnd != 0 {
+				return nil, fmt.Errorf("第%d条规则的协议不支持指定端口", index)
+			}
+		default:
+			return nil, fmt.Errorf("第%d条规则的协议无效", index)
+		}
+
+		source := strings.TrimSpace(r.Source)
+		if source != "" {
+			if !strings.Contains(source, "/") {
+				ip := net.ParseIP(source)
+				if ip == nil {
+					return nil, fmt.Errorf("第%d条规则的来源地址无效", index)
+				}
+				if ip.To4() != nil {
+					source += "/32"
+				} else {
+					source += "/128"
+				}
+			}
+			_, ipNet, err := net.ParseCIDR(source)
+			if err != nil {
+				return nil, fmt.Errorf("第%d条规则的来源地址无效", index)
+			}
+			source = ipNet.String()
+		}
+
+		rules = append(rules, providerModel.SecurityGroupRule{
+			Action:      r.Action,
+			Protocol:    r.Protocol,
+			PortStart:   portStart,
+			PortEnd:     portEnd,
+			Source:      source,
+			Description: strings.TrimSpace(r.Description),
+		})
+	}
+	return rules, nil
+}
+
+// normalizeDefaultAction 默认动作为空时放行
+func normalizeDefaultAction(action string) string {
+	if action == providerModel.SecurityGroupActionDeny {
+		return providerModel.SecurityGroupActionDeny
+	}
+	return providerModel.SecurityGroupActionAllow
+}
+
+// fillInstanceCount 填充各安全组绑定的实例数
+func fillInstanceCount(groups []providerModel.SecurityGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+
+	var counts []struct {
+		SecurityGroupID uint
+		Count           int64
+	}
+	if err := global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+		Select("security_group_id, COUNT(*) AS count").
+		Where("security_group_id IN ?", ids).
+		Group("security_group_id").
+		Scan(&counts).Error; err != nil {
+		global.APP_LOG.Warn("统计安全组绑定实例数失败", zap.Error(err))
+		return
+	}
+
+	countMap := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.SecurityGroupID] = c.Count
+	}
+	for i := range groups {
+		groups[i].InstanceCount = countMap[groups[i].ID]
+	}
+}
+
+// getUserGroup 获取属于用户的安全组
+func (s *Service) getUserGroup(userID, groupID uint) (*providerModel.SecurityGroup, error) {
+	var group providerModel.SecurityGroup
+	if err := global.APP_DB.Preload("Rules").
+		Where("id = ? AND user_id = ?", groupID, userID).
+		First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("获取安全组失败: %v", err)
+	}
+	return &group, nil
+}
+
+// getUserInstance 获取属于用户的实例
+func (s *Service) getUserInstance(userID, instanceID uint) (*providerModel.Instance, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstanceNotFound
+		}
+		return nil, fmt.Errorf("获取实例失败: %v", err)
+	}
+	return &instance, nil
+}
+
+// ListGroups 获取用户的安全组列表
+func (s *Service) ListGroups(userID uint) ([]providerModel.SecurityGroup, error) {
+	var groups []providerModel.SecurityGroup
+	if err := global.APP_DB.Preload("Rules").
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("获取安全组列表失败: %v", err)
+	}
+	fillInstanceCount(groups)
+	return groups, nil
+}
+
+// CreateGroup 创建安全组
+func (s *Service) CreateGroup(userID uint, req user.SecurityGroupRequest) (*providerModel.SecurityGroup, error) {
+	rules, err := validateRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.SecurityGroup{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("统计安全组数量失败: %v", err)
+	}
+	if count >= maxGroupsPerUser {
+		return nil, fmt.Errorf("安全组数量已达上限（%d个）", maxGroupsPerUser)
+	}
+
+	group := &providerModel.SecurityGroup{
+		UserID:        userID,
+		Name:          strings.TrimSpace(req.Name),
+		Description:   strings.TrimSpace(req.Description),
+		DefaultAction: normalizeDefaultAction(req.DefaultAction),
+		Rules:         rules,
+	}
+	if err := global.APP_DB.Create(group).Error; err != nil {
+		return nil, fmt.Errorf("创建安全组失败: %v", err)
+	}
+	return group, nil
+}
+
+// UpdateGroup 更新安全组并整体替换规则，已绑定的实例会重新下发规则
+func (s *Service) UpdateGroup(userID, groupID uint, req user.SecurityGroupRequest) (*providerModel.SecurityGroup, error) {
+	rules, err := validateRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.getUserGroup(userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Updates(map[string]interface{}{
+			"name":           strings.TrimSpace(req.Name),
+			"description":    strings.TrimSpace(req.Description),
+			"default_action": normalizeDefaultAction(req.DefaultAction),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("security_group_id = ?", groupID).Delete(&providerModel.SecurityGroupRule{}).Error; err != nil {
+			return err
+		}
+		for i := range rules {
+			rules[i].SecurityGroupID = groupID
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("更新安全组失败: %v", err)
+	}
+
+	s.reapplyGroup(groupID)
+	return s.getUserGroup(userID, groupID)
+}
+
+// DeleteGroup 删除安全组，仍有实例绑定时拒绝删除
+func (s *Service) DeleteGroup(userID, groupID uint) error {
+	if _, err := s.getUserGroup(userID, groupID); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+		Where("security_group_id = ?", groupID).Count(&count).Error; err != nil {
+		return fmt.Errorf("统计安全组绑定实例数失败: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("安全组仍绑定了%d个实例，请先解除绑定", count)
+	}
+
+	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("security_group_id = ?", groupID).Delete(&providerModel.SecurityGroupRule{}).Error; err != nil {
+			return fmt.Errorf("删除安全组规则失败: %v", err)
+		}
+		if err := tx.Delete(&providerModel.SecurityGroup{}, groupID).Error; err != nil {
+			return fmt.Errorf("删除安全组失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// GetInstanceGroup 获取实例绑定的安全组，未绑定时返回nil
+func (s *Service) GetInstanceGroup(userID, instanceID uint) (*providerModel.InstanceSecurityGroup, error) {
+	if _, err := s.getUserInstance(userID, instanceID); err != nil {
+		return nil, err
+	}
+
+	var binding providerModel.InstanceSecurityGroup
+	if err := global.APP_DB.Preload("SecurityGroup.Rules").
+		Where("instance_id = ?", instanceID).
+		First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取实例安全组失败: %v", err)
+	}
+	return &binding, nil
+}
+
+// checkInstance 检查实例状态和所在Provider是否支持防火墙
+// Provider未加载到内存时无法判断，放行并由任务执行时最终确认
+func (s *Service) checkInstance(instance *providerModel.Instance) error {
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return errors.New("只有运行中或已停止的实例才能设置安全组")
+	}
+	prov, exists := provider2.GetProviderService().GetProviderByID(instance.ProviderID)
+	if !exists {
+		return nil
+	}
+	if _, ok := prov.(provider.FirewallProvider); !ok {
+		return fmt.Errorf("该实例所在的Provider（%s）不支持安全组", prov.GetType())
+	}
+	return nil
+}
+
+// BindInstance 为实例绑定安全组，已绑定其他安全组时替换
+func (s *Service) BindInstance(userID, instanceID, groupID uint) (*adminModel.Task, error) {
+	instance, err := s.getUserInstance(userID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getUserGroup(userID, groupID); err != nil {
+		return nil, err
+	}
+	if err := s.checkInstance(instance); err != nil {
+		return nil, err
+	}
+
+	var binding providerModel.InstanceSecurityGroup
+	err = global.APP_DB.Where("instance_id = ?", instanceID).First(&binding).Error
+	switch {
+	case err == nil:
+		err = global.APP_DB.Model(&binding).Updates(map[string]interface{}{
+			"security_group_id": groupID,
+			"apply_status":      providerModel.SecurityGroupApplyPending,
+			"apply_error":       "",
+		}).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = global.APP_DB.Create(&providerModel.InstanceSecurityGroup{
+			InstanceID:      instanceID,
+			SecurityGroupID: groupID,
+			ApplyStatus:     providerModel.SecurityGroupApplyPending,
+		}).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("绑定安全组失败: %v", err)
+	}
+
+	return s.queueApply(instance)
+}
+
+// UnbindInstance 解除实例的安全组并移除实例防火墙
+func (s *Service) UnbindInstance(userID, instanceID uint) (*adminModel.Task, error) {
+	instance, err := s.getUserInstance(userID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, errors.New("只有运行中或已停止的实例才能解除安全组")
+	}
+
+	result := global.APP_DB.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceSecurityGroup{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("解除安全组失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("实例未绑定安全组")
+	}
+
+	return s.queueApply(instance)
+}
+
+// ListAllGroups 管理员分页查询安全组
+func (s *Service) ListAllGroups(req adminModel.SecurityGroupListRequest) ([]providerModel.SecurityGroup, int64, error) {
+	query := global.APP_DB.Model(&providerModel.SecurityGroup{})
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+req.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计安全组数量失败: %v", err)
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	var groups []providerModel.SecurityGroup
+	if err := query.Preload("Rules").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&groups).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取安全组列表失败: %v", err)
+	}
+	fillInstanceCount(groups)
+	return groups, total, nil
+}
+
+// Reapply 管理员重新下发实例的安全组规则，用于宿主机重启或规则被手动修改后恢复
+func (s *Service) Reapply(instanceID uint) (*adminModel.Task, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例失败: %v", err)
+	}
+
+	result := global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+		Where("instance_id = ?", instanceID).
+		Update("apply_status", providerModel.SecurityGroupApplyPending)
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新安全组应用状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("实例未绑定安全组")
+	}
+
+	return s.queueApply(&instance)
+}
+
+// reapplyGroup 安全组变更后为绑定的实例重新下发规则
+// 处于重置、迁移等操作中的实例保持pending状态，由对应任务完成后重新下发
+func (s *Service) reapplyGroup(groupID uint) {
+	var instances []providerModel.Instance
+	if err := global.APP_DB.
+		Where("id IN (?)", global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+			Select("instance_id").Where("security_group_id = ?", groupID)).
+		Find(&instances).Error; err != nil {
+		global.APP_LOG.Error("获取安全组绑定的实例失败", zap.Uint("securityGroupId", groupID), zap.Error(err))
+		return
+	}
+
+	global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+		Where("security_group_id = ?", groupID).
+		Update("apply_status", providerModel.SecurityGroupApplyPending)
+
+	for i := range instances {
+		if instances[i].Status != "running" && instances[i].Status != "stopped" {
+			continue
+		}
+		if _, err := s.queueApply(&instances[i]); err != nil {
+			global.APP_LOG.Warn("创建应用安全组任务失败",
+				zap.Uint("securityGroupId", groupID),
+				zap.Uint("instanceId", instances[i].ID),
+				zap.Error(err))
+		}
+	}
+}
+
+// queueApply 创建应用防火墙任务；已有等待执行的任务时复用，任务执行时读取最新的绑定和规则
+func (s *Service) queueApply(instance *providerModel.Instance) (*adminModel.Task, error) {
+	var pending adminModel.Task
+	err := global.APP_DB.Where("instance_id = ? AND task_type = ? AND status = ?", instance.ID, "apply-firewall", "pending").
+		First(&pending).Error
+	if err == nil {
+		return &pending, nil
+	}
+
+	taskData, err := json.Marshal(adminModel.ApplyFirewallTaskRequest{InstanceID: instance.ID})
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	instanceID := instance.ID
+	providerID := instance.ProviderID
+	taskModel, err := task.GetTaskService().CreateTask(instance.UserID, &providerID, &instanceID, "apply-firewall", string(taskData), 0)
+	if err != nil {
+		return nil, fmt.Errorf("创建应用安全组任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建应用安全组任务",
+		zap.Uint("instanceId", instance.ID),
+		zap.Uint("taskId", taskModel.ID))
+	return taskModel, nil
+}
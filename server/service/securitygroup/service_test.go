@@ -0,0 +1,47 @@
+package securitygroup
+
+import (
+	"testing"
+
+	"oneclickvirt/model/user"
+)
+
+// TestValidateRules 测试规则校验与来源地址、端口的规范化
+func TestValidateRules(t *testing.T) {
+	rules, err := validateRules([]user.SecurityGroupRuleRequest{
+		{Action: "allow", Protocol: "tcp", PortStart: 22},
+		{Action: "deny", Protocol: "udp", PortStart: 1000, PortEnd: 2000, Source: "10.1.2.3/8"},
+		{Action: "allow", Protocol: "icmp", Source: "2001:db8::1"},
+		{Action: "deny", Protocol: "all", Source: " 192.0.2.7 "},
+	})
+	if err != nil {
+		t.Fatalf("validateRules() error = %v", err)
+	}
+
+	if rules[0].PortStart != 22 || rules[0].PortEnd != 22 {
+		t.Errorf("单个端口应补全结束端口: %d-%d", rules[0].PortStart, rules[0].PortEnd)
+	}
+	if rules[1].Source != "10.0.0.0/8" {
+		t.Errorf("CIDR应取网络地址: %s", rules[1].Source)
+	}
+	if rules[2].Source != "2001:db8::1/128" {
+		t.Errorf("IPv6地址应补全为/128: %s", rules[2].Source)
+	}
+	if rules[3].Source != "192.0.2.7/32" {
+		t.Errorf("IPv4地址应补全为/32: %s", rules[3].Source)
+	}
+
+	invalid := []user.SecurityGroupRuleRequest{
+		{Action: "allow", Protocol: "tcp", PortStart: 0, PortEnd: 80},
+		{Action: "allow", Protocol: "tcp", PortStart: 443, PortEnd: 80},
+		{Action: "allow", Protocol: "icmp", PortStart: 8},
+		{Action: "allow", Protocol: "tcp", PortStart: 22, Source: "not-an-ip"},
+		{Action: "reject", Protocol: "tcp", PortStart: 22},
+		{Action: "allow", Protocol: "sctp"},
+	}
+	for _, r := range invalid {
+		if _, err := validateRules([]user.SecurityGroupRuleRequest{r}); err == nil {
+			t.Errorf("validateRules(%+v) 应返回错误", r)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/auth"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/task"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// snapshotNamePattern 快照名称规则：字母开头，仅包含字母、数字、下划线和连字符
+// 需同时满足 LXD/Incus/Proxmox 快照名和 Docker 镜像标签的命名要求
+var snapshotNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,39}$`)
+
+// reservedSnapshotNames 保留的快照名称
+var reservedSnapshotNames = map[string]bool{
+	"current": true, // Proxmox 当前状态 / Docker 恢复后使用的镜像标签
+}
+
+// Service 实例快照服务
+type Service struct{}
+
+// NewService 创建快照服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// CheckProviderSupport 检查实例所在Provider是否支持快照
+// Provider未加载到内存时无法判断，放行并由任务执行时最终确认
+func (s *Service) CheckProviderSupport(instance *providerModel.Instance) error {
+	prov, exists := provider2.GetProviderService().GetProviderByID(instance.ProviderID)
+	if !exists {
+		return nil
+	}
+	if _, ok := prov.(provider.SnapshotProvider); !ok {
+		return fmt.Errorf("该实例所在的Provider（%s）不支持快照", prov.GetType())
+	}
+	return nil
+}
+
+// validateSnapshotName 校验快照名称，为空时自动生成
+func (s *Service) validateSnapshotName(instanceID uint, name string) (string, error) {
+	if name == "" {
+		name = "snap" + time.Now().Format("20060102150405")
+	}
+	if !snapshotNamePattern.MatchString(name) || reservedSnapshotNames[name] {
+		return "", errors.New("快照名称必须以字母开头，只能包含字母、数字、下划线和连字符，长度不超过40")
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceSnapshot{}).
+		Where("instance_id = ? AND LOWER(name) = LOWER(?)", instanceID, name).
+		Count(&count).Error; err != nil {
+		return "", fmt.Errorf("检查快照名称失败: %v", err)
+	}
+	if count > 0 {
+		return "", errors.New("快照名称已存在")
+	}
+	return name, nil
+}
+
+// checkSnapshotLimit 检查用户等级的快照数量限制
+func (s *Service) checkSnapshotLimit(instance *providerModel.Instance) error {
+	permissionService := auth.PermissionService{}
+	effective, err := permissionService.GetUserEffectivePermission(instance.UserID)
+	if err != nil {
+		return fmt.Errorf("获取用户权限失败: %v", err)
+	}
+
+	// 管理员不受限制
+	if effective.EffectiveType == "admin" {
+		return nil
+	}
+
+	levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[effective.EffectiveLevel]
+	if !exists || levelLimits.MaxSnapshots <= 0 {
+		return errors.New("当前用户等级不允许创建快照")
+	}
+
+	var count int64
+	if err := global.APP_DB.Model(&providerModel.InstanceSnapshot{}).
+		Where("instance_id = ? AND status != ?", instance.ID, providerModel.SnapshotStatusFailed).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("统计快照数量失败: %v", err)
+	}
+	if int(count) >= levelLimits.MaxSnapshots {
+		return fmt.Errorf("该实例快照数量已达上限（%d个）", levelLimits.MaxSnapshots)
+	}
+	return nil
+}
+
+// checkNoRunningSnapshotTask 检查实例是否有进行中的快照任务
+func (s *Service) checkNoRunningSnapshotTask(instanceID uint) error {
+	var count int64
+	if err := global.APP_DB.Model(&adminModel.Task{}).
+		Where("instance_id = ? AND task_type IN (?) AND status IN (?)", instanceID,
+			[]string{"create-snapshot", "restore-snapshot", "delete-snapshot"},
+			[]string{"pending", "running"}).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("检查快照任务失败: %v", err)
+	}
+	if count > 0 {
+		return errors.New("该实例已有进行中的快照任务，请稍后再试")
+	}
+	return nil
+}
+
+// createSnapshotTask 创建快照任务
+func (s *Service) createSnapshotTask(instance *providerModel.Instance, snapshotID uint, taskType string) (*adminModel.Task, error) {
+	taskReq := adminModel.SnapshotTaskRequest{
+		SnapshotID: snapshotID,
+		InstanceID: instance.ID,
+		ProviderID: instance.ProviderID,
+	}
+	taskData, err := json.Marshal(taskReq)
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	instanceID := instance.ID
+	providerID := instance.ProviderID
+	return task.GetTaskService().CreateTask(instance.UserID, &providerID, &instanceID, taskType, string(taskData), 0)
+}
+
+// CreateSnapshot 为实例创建快照
+// enforceLimit 为 false 时跳过用户等级的快照数量限制（管理员操作）
+func (s *Service) CreateSnapshot(instance *providerModel.Instance, name, description string, enforceLimit bool) (*providerModel.InstanceSnapshot, *adminModel.Task, error) {
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, nil, errors.New("只有运行中或已停止的实例才能创建快照")
+	}
+
+	if err := s.CheckProviderSupport(instance); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkNoRunningSnapshotTask(instance.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if enforceLimit {
+		if err := s.checkSnapshotLimit(instance); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	name, err := s.validateSnapshotName(instance.ID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var providerType string
+	var providerInfo providerModel.Provider
+	if err := global.APP_DB.Select("type").First(&providerInfo, instance.ProviderID).Error; err == nil {
+		providerType = providerInfo.Type
+	}
+
+	snapshot := &providerModel.InstanceSnapshot{
+		Name:         name,
+		Description:  description,
+		Status:       providerModel.SnapshotStatusCreating,
+		InstanceName: instance.Name,
+		ProviderType: providerType,
+		InstanceID:   instance.ID,
+		ProviderID:   instance.ProviderID,
+		UserID:       instance.UserID,
+	}
+	if err := global.APP_DB.Create(snapshot).Error; err != nil {
+		return nil, nil, fmt.Errorf("创建快照记录失败: %v", err)
+	}
+
+	taskModel, err := s.createSnapshotTask(instance, snapshot.ID, "create-snapshot")
+	if err != nil {
+		global.APP_DB.Delete(snapshot)
+		return nil, nil, fmt.Errorf("创建快照任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建实例快照任务",
+		zap.Uint("instanceId", instance.ID),
+		zap.Uint("snapshotId", snapshot.ID),
+		zap.String("snapshot", name),
+		zap.Uint("taskId", taskModel.ID))
+
+	return snapshot, taskModel, nil
+}
+
+// changeSnapshotStatus 将可用快照切换到操作中状态并创建对应任务
+func (s *Service) changeSnapshotStatus(instance *providerModel.Instance, snapshot *providerModel.InstanceSnapshot, status, taskType string) (*adminModel.Task, error) {
+	if snapshot.Status != providerModel.SnapshotStatusAvailable && snapshot.Status != providerModel.SnapshotStatusFailed {
+		return nil, fmt.Errorf("快照当前状态为 %s，无法执行此操作", snapshot.Status)
+	}
+	if taskType == "restore-snapshot" && snapshot.Status != providerModel.SnapshotStatusAvailable {
+		return nil, errors.New("只能恢复可用状态的快照")
+	}
+
+	if err := s.checkNoRunningSnapshotTask(instance.ID); err != nil {
+		return nil, err
+	}
+
+	// 失败的快照在Provider上可能不存在，直接删除记录
+	if taskType == "delete-snapshot" && snapshot.Status == providerModel.SnapshotStatusFailed {
+		if err := global.APP_DB.Delete(snapshot).Error; err != nil {
+			return nil, fmt.Errorf("删除快照记录失败: %v", err)
+		}
+		return nil, nil
+	}
+
+	result := global.APP_DB.Model(&providerModel.InstanceSnapshot{}).
+		Where("id = ? AND status = ?", snapshot.ID, snapshot.Status).
+		Updates(map[string]interface{}{"status": status, "error_message": ""})
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新快照状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("快照状态已变更，请刷新后重试")
+	}
+
+	taskModel, err := s.createSnapshotTask(instance, snapshot.ID, taskType)
+	if err != nil {
+		global.APP_DB.Model(&providerModel.InstanceSnapshot{}).Where("id = ?", snapshot.ID).
+			Update("status", providerModel.SnapshotStatusAvailable)
+		return nil, fmt.Errorf("创建快照任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建快照操作任务",
+		zap.Uint("instanceId", instance.ID),
+		zap.Uint("snapshotId", snapshot.ID),
+		zap.String("taskType", taskType),
+		zap.Uint("taskId", taskModel.ID))
+
+	return taskModel, nil
+}
+
+// RestoreSnapshot 将实例恢复到指定快照
+func (s *Service) RestoreSnapshot(instance *providerModel.Instance, snapshot *providerModel.InstanceSnapshot) (*adminModel.Task, error) {
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, errors.New("只有运行中或已停止的实例才能恢复快照")
+	}
+	return s.changeSnapshotStatus(instance, snapshot, providerModel.SnapshotStatusRestoring, "restore-snapshot")
+}
+
+// DeleteSnapshot 删除快照，失败状态的快照直接删除记录并返回nil任务
+func (s *Service) DeleteSnapshot(instance *providerModel.Instance, snapshot *providerModel.InstanceSnapshot) (*adminModel.Task, error) {
+	return s.changeSnapshotStatus(instance, snapshot, providerModel.SnapshotStatusDeleting, "delete-snapshot")
+}
+
+// GetSnapshot 获取快照及其所属实例
+func (s *Service) GetSnapshot(snapshotID uint) (*providerModel.InstanceSnapshot, *providerModel.Instance, error) {
+	var snapshot providerModel.InstanceSnapshot
+	if err := global.APP_DB.First(&snapshot, snapshotID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("快照不存在")
+		}
+		return nil, nil, fmt.Errorf("获取快照失败: %v", err)
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, snapshot.InstanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("实例不存在")
+		}
+		return nil, nil, fmt.Errorf("获取实例失败: %v", err)
+	}
+	return &snapshot, &instance, nil
+}
+
+// ListInstanceSnapshots 获取实例的快照列表
+func (s *Service) ListInstanceSnapshots(instanceID uint) ([]providerModel.InstanceSnapshot, error) {
+	var snapshots []providerModel.InstanceSnapshot
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).
+		Order("created_at DESC").
+		Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("获取快照列表失败: %v", err)
+	}
+	return snapshots, nil
+}
+
+// ListSnapshots 管理员分页查询快照
+func (s *Service) ListSnapshots(req adminModel.SnapshotListRequest) ([]providerModel.InstanceSnapshot, int64, error) {
+	query := global.APP_DB.Model(&providerModel.InstanceSnapshot{})
+	if req.InstanceID > 0 {
+		query = query.Where("instance_id = ?", req.InstanceID)
+	}
+	if req.ProviderID > 0 {
+		query = query.Where("provider_id = ?", req.ProviderID)
+	}
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Keyword != "" {
+		keyword := "%" + req.Keyword + "%"
+		query = query.Where("name LIKE ? OR instance_name LIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计快照数量失败: %v", err)
+	}
+
+	page, pageSize := req.Page, req.PageSize
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	var snapshots []providerModel.InstanceSnapshot
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&snapshots).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取快照列表失败: %v", err)
+	}
+	return snapshots, total, nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"oneclickvirt/config"
+	"oneclickvirt/global"
+)
+
+// 备份存储类型
+const (
+	BackupStorageLocal = "local"
+	BackupStorageS3    = "s3"
+)
+
+// BackupBackend 备份归档存储后端
+// key 为与存储类型无关的相对路径，如 user-1/instance-2/xxx.tar.gz
+type BackupBackend interface {
+	// Type 返回存储类型
+	Type() string
+	// Put 写入归档，size 为内容长度
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get 读取归档，调用方负责关闭
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除归档，归档不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBackupBackend 根据配置创建备份存储后端
+func NewBackupBackend(cfg config.Backup) (BackupBackend, error) {
+	switch strings.ToLower(cfg.StorageType) {
+	case "", BackupStorageLocal:
+		return newLocalBackupBackend(cfg.LocalPath), nil
+	case BackupStorageS3:
+		return newS3BackupBackend(cfg.S3)
+	default:
+		return nil, fmt.Errorf("不支持的备份存储类型: %s", cfg.StorageType)
+	}
+}
+
+// GetBackupBackend 使用全局配置创建备份存储后端
+func GetBackupBackend() (BackupBackend, error) {
+	return NewBackupBackend(global.APP_CONFIG.Backup)
+}
+
+// GetBackupBackendByType 获取指定类型的备份存储后端
+// 用于读取/删除历史备份，存储类型以备份记录中保存的为准
+func GetBackupBackendByType(storageType string) (BackupBackend, error) {
+	cfg := global.APP_CONFIG.Backup
+	cfg.StorageType = storageType
+	return NewBackupBackend(cfg)
+}
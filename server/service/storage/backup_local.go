@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"oneclickvirt/model/system"
+	"oneclickvirt/utils"
+)
+
+// localBackupBackend 控制端本地磁盘存储
+type localBackupBackend struct {
+	baseDir string
+}
+
+func newLocalBackupBackend(baseDir string) *localBackupBackend {
+	if baseDir == "" {
+		baseDir = filepath.Join(system.DefaultStorageDir, system.BackupsDir)
+	}
+	return &localBackupBackend{baseDir: baseDir}
+}
+
+// Type 返回存储类型
+func (b *localBackupBackend) Type() string {
+	return BackupStorageLocal
+}
+
+// resolve 将key转换为本地路径，禁止越出存储目录
+func (b *localBackupBackend) resolve(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("无效的备份路径: %s", key)
+	}
+	return filepath.Join(b.baseDir, cleaned), nil
+}
+
+// Put 写入归档，先写临时文件再重命名，避免留下不完整的归档
+func (b *localBackupBackend) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	path, err := b.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := utils.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	tmpPath := path + ".part"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %w", err)
+	}
+
+	written, copyErr := io.Copy(file, r)
+	closeErr := file.Close()
+	if copyErr == nil && closeErr != nil {
+		copyErr = closeErr
+	}
+	if copyErr == nil && size > 0 && written != size {
+		copyErr = fmt.Errorf("写入大小不一致: 期望 %d, 实际 %d", size, written)
+	}
+	if copyErr != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入备份文件失败: %w", copyErr)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("保存备份文件失败: %w", err)
+	}
+	return nil
+}
+
+// Get 读取归档
+func (b *localBackupBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := b.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	return file, nil
+}
+
+// Delete 删除归档
+func (b *localBackupBackend) Delete(ctx context.Context, key string) error {
+	path, err := b.resolve(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除备份文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oneclickvirt/config"
+)
+
+const (
+	// s3SinglePutLimit 超过该大小使用分片上传（S3单次PUT上限为5GB）
+	s3SinglePutLimit int64 = 4 << 30
+	// s3PartSize 分片大小
+	s3PartSize int64 = 256 << 20
+	// s3UnsignedPayload 不对请求体签名，避免为计算哈希而缓存整个归档
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// s3BackupBackend S3兼容对象存储，使用 AWS Signature V4 签名
+type s3BackupBackend struct {
+	endpoint     *url.URL
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	prefix       string
+	usePathStyle bool
+	client       *http.Client
+}
+
+func newS3BackupBackend(cfg config.BackupS3) (*s3BackupBackend, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3备份存储缺少 endpoint 或 bucket 配置")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("S3备份存储缺少访问密钥配置")
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("无效的S3 endpoint: %s", cfg.Endpoint)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &s3BackupBackend{
+		endpoint:     endpoint,
+		region:       region,
+		bucket:       cfg.Bucket,
+		accessKey:    cfg.AccessKey,
+		secretKey:    cfg.SecretKey,
+		prefix:       strings.Trim(cfg.Prefix, "/"),
+		usePathStyle: cfg.UsePathStyle,
+		// 归档可能很大，不设置整体超时，由调用方的context控制
+		client: &http.Client{},
+	}, nil
+}
+
+// Type 返回存储类型
+func (b *s3BackupBackend) Type() string {
+	return BackupStorageS3
+}
+
+// objectURL 构造对象URL
+func (b *s3BackupBackend) objectURL(key string, query url.Values) *url.URL {
+	objectKey := strings.TrimLeft(key, "/")
+	if b.prefix != "" {
+		objectKey = b.prefix + "/" + objectKey
+	}
+
+	u := *b.endpoint
+	if b.usePathStyle {
+		u.Path = "/" + b.bucket + "/" + objectKey
+	} else {
+		u.Host = b.bucket + "." + b.endpoint.Host
+		u.Path = "/" + objectKey
+	}
+	if query != nil {
+		u.RawQuery = canonicalQueryString(query)
+	}
+	return &u
+}
+
+// newRequest 创建已签名的请求
+func (b *s3BackupBackend) newRequest(ctx context.Context, method string, u *url.URL, body io.Reader, size int64) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	b.sign(req, s3UnsignedPayload, time.Now().UTC())
+	return req, nil
+}
+
+// sign 按 AWS Signature V4 为请求签名
+func (b *s3BackupBackend) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := dateStamp + "/" + b.region + "/s3/aws4_request"
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashedRequest[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+b.secretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, b.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, signedHeaders, signature))
+}
+
+// do 执行请求并检查状态码
+func (b *s3BackupBackend) do(req *http.Request, okStatus ...int) (*http.Response, error) {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("S3请求失败 (%s %s): HTTP %d, %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// Put 上传归档，大文件使用分片上传
+func (b *s3BackupBackend) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if size > s3SinglePutLimit {
+		return b.putMultipart(ctx, key, r, size)
+	}
+
+	req, err := b.newRequest(ctx, http.MethodPut, b.objectURL(key, nil), r, size)
+	if err != nil {
+		return err
+	}
+	resp, err := b.do(req, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// putMultipart 分片上传
+func (b *s3BackupBackend) putMultipart(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := b.newRequest(ctx, http.MethodPost, b.objectURL(key, url.Values{"uploads": {""}}), nil, 0)
+	if err != nil {
+		return err
+	}
+	resp, err := b.do(req, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	var initResult struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initResult)
+	resp.Body.Close()
+	if err != nil || initResult.UploadID == "" {
+		return fmt.Errorf("初始化分片上传失败: %v", err)
+	}
+	uploadID := initResult.UploadID
+
+	type completedPart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var parts []completedPart
+
+	abort := func() {
+		abortReq, err := b.newRequest(context.Background(), http.MethodDelete, b.objectURL(key, url.Values{"uploadId": {uploadID}}), nil, 0)
+		if err == nil {
+			if resp, err := b.do(abortReq, http.StatusNoContent, http.StatusOK); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+
+	remaining := size
+	for partNumber := 1; remaining > 0; partNumber++ {
+		partSize := s3PartSize
+		if remaining < partSize {
+			partSize = remaining
+		}
+
+		query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+		partReq, err := b.newRequest(ctx, http.MethodPut, b.objectURL(key, query), io.LimitReader(r, partSize), partSize)
+		if err != nil {
+			abort()
+			return err
+		}
+		partResp, err := b.do(partReq, http.StatusOK)
+		if err != nil {
+			abort()
+			return fmt.Errorf("上传第%d个分片失败: %w", partNumber, err)
+		}
+		partResp.Body.Close()
+
+		parts = append(parts, completedPart{PartNumber: partNumber, ETag: partResp.Header.Get("ETag")})
+		remaining -= partSize
+	}
+
+	completeBody, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		abort()
+		return err
+	}
+
+	completeReq, err := b.newRequest(ctx, http.MethodPost, b.objectURL(key, url.Values{"uploadId": {uploadID}}),
+		bytes.NewReader(completeBody), int64(len(completeBody)))
+	if err != nil {
+		abort()
+		return err
+	}
+	completeResp, err := b.do(completeReq, http.StatusOK)
+	if err != nil {
+		abort()
+		return fmt.Errorf("完成分片上传失败: %w", err)
+	}
+	completeResp.Body.Close()
+	return nil
+}
+
+// Get 下载归档
+func (b *s3BackupBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := b.newRequest(ctx, http.MethodGet, b.objectURL(key, nil), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.do(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete 删除归档
+func (b *s3BackupBackend) Delete(ctx context.Context, key string) error {
+	req, err := b.newRequest(ctx, http.MethodDelete, b.objectURL(key, nil), nil, 0)
+	if err != nil {
+		return err
+	}
+	resp, err := b.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// canonicalURI 按S3规则对路径逐段编码
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQueryString 按键排序并编码查询参数
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape RFC3986 编码，仅保留非保留字符
+func s3Escape(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -28,6 +28,7 @@ func GetDefaultStorageConfig() *system.StorageConfig {
 			system.CacheDir,
 			system.TempDir,
 			system.AvatarsDir,
+			system.BackupsDir,
 		},
 	}
 }
@@ -117,6 +118,11 @@ func (s *StorageService) GetAvatarsPath() string {
 	return s.GetStoragePath(system.AvatarsDir)
 }
 
+// GetBackupsPath 获取备份文件存储路径
+func (s *StorageService) GetBackupsPath() string {
+	return s.GetStoragePath(system.BackupsDir)
+}
+
 // CleanupTempFiles 清理临时文件
 func (s *StorageService) CleanupTempFiles() error {
 	tempPath := s.GetTempPath()
@@ -9,6 +9,7 @@ import (
 	userModel "oneclickvirt/model/user"
 
 	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	"oneclickvirt/service/ipam"
 	"oneclickvirt/service/resources"
 
 	"go.uber.org/zap"
@@ -165,6 +166,13 @@ func (s *InstanceCleanupService) cleanupSingleFailedInstance(instance *providerM
 				zap.String("instanceName", instance.Name))
 		}
 
+		// 释放实例占用的独立IP地址
+		if err := ipam.NewService().ReleaseByInstanceInTx(tx, instance.ID); err != nil {
+			global.APP_LOG.Error("释放失败实例独立IP失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+
 		// 2. 释放物理资源（CPU/Memory/Disk）
 		global.APP_LOG.Debug("释放失败实例物理资源",
 			zap.Uint("instanceId", instance.ID),
@@ -310,6 +318,13 @@ func (s *InstanceCleanupService) cleanupSingleExpiredInstance(instance *provider
 				zap.Uint("instanceId", instance.ID))
 		}
 
+		// 释放实例占用的独立IP地址
+		if err := ipam.NewService().ReleaseByInstanceInTx(tx, instance.ID); err != nil {
+			global.APP_LOG.Warn("释放过期实例独立IP失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+
 		// 保存需要用于日志的字段
 		instanceID := instance.ID
 		instanceName := instance.Name
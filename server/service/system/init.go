@@ -7,21 +7,16 @@ import (
 	"strings"
 
 	"oneclickvirt/global"
-	adminModel "oneclickvirt/model/admin"
-	"oneclickvirt/model/auth"
 	"oneclickvirt/model/config"
-	permissionModel "oneclickvirt/model/permission"
-	"oneclickvirt/model/provider"
-	"oneclickvirt/model/resource"
-	"oneclickvirt/model/system"
-	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/database"
+	"oneclickvirt/service/migration"
+	"oneclickvirt/service/secret"
 	"oneclickvirt/utils"
 
 	configManager "oneclickvirt/config"
 
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -49,54 +44,26 @@ func (s *InitService) CheckDatabaseConnection() error {
 
 // TestDatabaseConnection 测试数据库连接（不需要全局DB连接）
 func (s *InitService) TestDatabaseConnection(config config.DatabaseConfig) error {
-	if config.Type != "mysql" && config.Type != "mariadb" {
-		return fmt.Errorf("不支持的数据库类型: %s，仅支持mysql和mariadb", config.Type)
+	if err := validateDatabaseConfig(config); err != nil {
+		return err
 	}
+	connConfig := databaseConnConfig(config)
 
-	// 构建DSN，先不指定数据库名
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/?charset=utf8mb4&parseTime=True&loc=Local&time_zone=%%27%%2B08%%3A00%%27",
-		config.Username, config.Password, config.Host, config.Port)
-
-	// 尝试连接数据库服务器（MySQL或MariaDB使用相同的连接方式）
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-	if err != nil {
-		return fmt.Errorf("连接%s服务器失败: %v", config.Type, err)
-	}
-
-	// 测试连接
-	sqlDB, err := db.DB()
+	// 检查数据库是否存在，如果不存在则创建（SQLite为创建数据库文件所在目录）
+	created, err := database.CreateDatabaseIfNotExists(connConfig)
 	if err != nil {
-		return fmt.Errorf("获取数据库实例失败: %v", err)
+		return fmt.Errorf("连接%s失败: %v", config.Type, err)
 	}
-	defer sqlDB.Close()
-
-	if err := sqlDB.Ping(); err != nil {
-		return fmt.Errorf("数据库连接测试失败: %v", err)
+	if created {
+		global.APP_LOG.Info("数据库不存在，已自动创建", zap.String("database", connConfig.Dbname))
 	}
 
-	// 检查数据库是否存在，如果不存在则创建
-	var count int64
-	err = db.Raw("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", config.Database).Scan(&count).Error
+	// 测试连接到具体数据库
+	dialector, err := database.NewDialector(connConfig)
 	if err != nil {
-		return fmt.Errorf("检查数据库是否存在失败: %v", err)
+		return err
 	}
-
-	if count == 0 {
-		// 数据库不存在，尝试创建
-		err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", config.Database)).Error
-		if err != nil {
-			return fmt.Errorf("创建数据库失败: %v", err)
-		}
-		global.APP_LOG.Info("数据库不存在，已自动创建", zap.String("database", config.Database))
-	}
-
-	// 测试连接到具体数据库
-	dsnWithDB := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local&time_zone=%%27%%2B08%%3A00%%27",
-		config.Username, config.Password, config.Host, config.Port, config.Database)
-
-	dbWithDB, err := gorm.Open(mysql.Open(dsnWithDB), &gorm.Config{
+	dbWithDB, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -116,66 +83,70 @@ func (s *InitService) TestDatabaseConnection(config config.DatabaseConfig) error
 	return nil
 }
 
-// AutoMigrateTables 自动迁移所有表结构
+// validateDatabaseConfig 校验初始化向导提交的数据库配置，SQLite只需要数据库文件路径
+func validateDatabaseConfig(dbConfig config.DatabaseConfig) error {
+	if !database.IsSupportedType(dbConfig.Type) {
+		return fmt.Errorf("不支持的数据库类型: %s，仅支持%s", dbConfig.Type, strings.Join(database.SupportedTypes, "、"))
+	}
+	if dbConfig.Type == database.TypeSQLite {
+		return nil
+	}
+	if dbConfig.Host == "" || dbConfig.Username == "" || dbConfig.Database == "" {
+		return fmt.Errorf("数据库地址、用户名和数据库名不能为空")
+	}
+	return nil
+}
+
+// databaseConnConfig 将初始化向导提交的数据库配置转换为连接配置
+func databaseConnConfig(dbConfig config.DatabaseConfig) config.MysqlConfig {
+	port := ""
+	if dbConfig.Port > 0 {
+		port = strconv.Itoa(dbConfig.Port)
+	}
+	return config.MysqlConfig{
+		DbType:   dbConfig.Type,
+		Path:     dbConfig.Host,
+		Port:     port,
+		Config:   databaseOptions(dbConfig),
+		Dbname:   dbConfig.Database,
+		Username: dbConfig.Username,
+		Password: dbConfig.Password,
+	}
+}
+
+// databaseOptions 初始化向导写入配置文件的连接参数（mysql.config）
+func databaseOptions(dbConfig config.DatabaseConfig) string {
+	options := database.DefaultOptions(dbConfig.Type)
+	if dbConfig.Type == database.TypePostgres && dbConfig.SSLMode != "" {
+		options = strings.Replace(options, "sslmode=disable", "sslmode="+dbConfig.SSLMode, 1)
+	}
+	return options
+}
+
+// AutoMigrateTables 执行未完成的数据库结构迁移
 func (s *InitService) AutoMigrateTables() error {
 	if global.APP_DB == nil {
 		return fmt.Errorf("数据库连接不存在")
 	}
 
-	global.APP_LOG.Debug("开始执行数据库表结构自动迁移")
-
-	// 执行表结构迁移
-	err := global.APP_DB.AutoMigrate(
-		// 用户相关表
-		&userModel.User{},     // 用户基础信息表
-		&auth.Role{},          // 角色管理表
-		&userModel.UserRole{}, // 用户角色关联表
-
-		// 实例相关表
-		&provider.Instance{}, // 虚拟机/容器实例表
-		&provider.Provider{}, // 服务提供商配置表
-		&provider.Port{},     // 端口映射表
-		&adminModel.Task{},   // 用户任务表
-
-		// 资源管理表
-		&resource.ResourceReservation{}, // 资源预留表
-
-		// 认证相关表
-		&userModel.VerifyCode{},    // 验证码表（邮箱/短信）
-		&userModel.PasswordReset{}, // 密码重置令牌表
-
-		// 系统配置表
-		&adminModel.SystemConfig{}, // 系统配置表
-		&system.Announcement{},     // 系统公告表
-		&system.SystemImage{},      // 系统镜像模板表
-		&system.Captcha{},          // 图形验证码表
-
-		// 邀请码相关表
-		&system.InviteCode{},      // 邀请码表
-		&system.InviteCodeUsage{}, // 邀请码使用记录表
-
-		// 权限管理表
-		&permissionModel.UserPermission{}, // 用户权限组合表
-
-		// 审计日志表
-		&adminModel.AuditLog{},      // 操作审计日志表
-		&provider.PendingDeletion{}, // 待删除资源表
-
-		// 管理员配置任务表
-		&adminModel.ConfigurationTask{}, // 管理员配置任务表
-	)
+	global.APP_LOG.Debug("开始执行数据库结构迁移")
 
+	applied, err := migration.NewRunner(global.APP_DB).Up(0)
 	if err != nil {
-		global.APP_LOG.Error("数据库表结构迁移失败", zap.String("error", utils.FormatError(err)))
+		global.APP_LOG.Error("数据库结构迁移失败", zap.String("error", utils.FormatError(err)))
 		return fmt.Errorf("表结构迁移失败: %v", err)
 	}
 
-	global.APP_LOG.Debug("数据库表结构自动迁移完成")
+	global.APP_LOG.Debug("数据库结构迁移完成", zap.Int("applied", len(applied)))
 	return nil
 }
 
 // EnsureDatabase 确保数据库和表结构存在
 func (s *InitService) EnsureDatabase(dbConfig config.DatabaseConfig) error {
+	if err := validateDatabaseConfig(dbConfig); err != nil {
+		return err
+	}
+
 	// 更新数据库配置
 	if err := s.UpdateDatabaseConfig(dbConfig); err != nil {
 		return fmt.Errorf("更新数据库配置失败: %v", err)
@@ -205,14 +176,14 @@ func (s *InitService) UpdateDatabaseConfig(dbConfig config.DatabaseConfig) error
 		// 更新系统配置
 		updates["system.db-type"] = dbConfig.Type
 
-		// 对于MySQL和MariaDB，都使用相同的配置结构
-		if dbConfig.Type == "mysql" || dbConfig.Type == "mariadb" {
+		// 所有数据库类型都使用相同的配置结构，SQLite的 db-name 为数据库文件路径
+		if database.IsSupportedType(dbConfig.Type) {
 			updates["mysql.path"] = dbConfig.Host
-			updates["mysql.port"] = strconv.Itoa(dbConfig.Port)
+			updates["mysql.port"] = databaseConnConfig(dbConfig).Port
 			updates["mysql.db-name"] = dbConfig.Database
 			updates["mysql.username"] = dbConfig.Username
 			updates["mysql.password"] = dbConfig.Password
-			updates["mysql.config"] = "charset=utf8mb4&parseTime=True&loc=Local&time_zone=%27%2B08%3A00%27"
+			updates["mysql.config"] = databaseOptions(dbConfig)
 			updates["mysql.prefix"] = ""
 			updates["mysql.singular"] = false
 			updates["mysql.engine"] = "InnoDB"
@@ -245,15 +216,15 @@ func (s *InitService) UpdateDatabaseConfig(dbConfig config.DatabaseConfig) error
 		global.APP_LOG.Warn("更新 system.db-type 失败", zap.Error(err))
 	}
 
-	// 对于MySQL和MariaDB，更新配置
-	if dbConfig.Type == "mysql" || dbConfig.Type == "mariadb" {
+	// 所有数据库类型都使用相同的配置结构，SQLite的 db-name 为数据库文件路径
+	if database.IsSupportedType(dbConfig.Type) {
 		mysqlUpdates := map[string]interface{}{
 			"mysql.path":           dbConfig.Host,
-			"mysql.port":           strconv.Itoa(dbConfig.Port),
+			"mysql.port":           databaseConnConfig(dbConfig).Port,
 			"mysql.db-name":        dbConfig.Database,
 			"mysql.username":       dbConfig.Username,
 			"mysql.password":       dbConfig.Password,
-			"mysql.config":         "charset=utf8mb4&parseTime=True&loc=Local&time_zone=%27%2B08%3A00%27",
+			"mysql.config":         databaseOptions(dbConfig),
 			"mysql.prefix":         "",
 			"mysql.singular":       "false",
 			"mysql.engine":         "InnoDB",
@@ -443,10 +414,10 @@ func (s *InitService) ReinitializeDatabase() error {
 		return fmt.Errorf("解析配置文件失败: %v", err)
 	}
 
-	// 获取 MySQL 配置
+	// 获取数据库配置（所有数据库类型共用 mysql 配置段）
 	mysqlConfig, ok := c["mysql"].(map[string]interface{})
 	if !ok {
-		return fmt.Errorf("MySQL配置不存在")
+		return fmt.Errorf("数据库配置不存在")
 	}
 
 	// 提取配置信息
@@ -454,7 +425,7 @@ func (s *InitService) ReinitializeDatabase() error {
 	dbname, _ := mysqlConfig["db-name"].(string)
 	username, _ := mysqlConfig["username"].(string)
 	password, _ := mysqlConfig["password"].(string)
-	config, _ := mysqlConfig["config"].(string)
+	options, _ := mysqlConfig["config"].(string)
 
 	// 记录读取到的数据库配置，用于调试
 	global.APP_LOG.Info("从配置文件读取到的数据库配置",
@@ -462,50 +433,52 @@ func (s *InitService) ReinitializeDatabase() error {
 		zap.String("dbname", dbname),
 		zap.String("username", username))
 
-	// 处理端口字段，支持字符串和数字两种类型
+	// 处理端口字段，支持字符串和数字两种类型，为空时使用数据库类型的默认端口
 	var portStr string
-	if portVal, exists := mysqlConfig["port"]; exists {
-		switch v := portVal.(type) {
-		case string:
-			portStr = v
-		case int:
-			portStr = fmt.Sprintf("%d", v)
-		case float64:
-			portStr = fmt.Sprintf("%.0f", v)
-		default:
-			portStr = "3306" // 默认端口
-		}
-	} else {
-		portStr = "3306" // 默认端口
+	switch v := mysqlConfig["port"].(type) {
+	case string:
+		portStr = v
+	case int:
+		portStr = fmt.Sprintf("%d", v)
+	case float64:
+		portStr = fmt.Sprintf("%.0f", v)
 	}
 
-	// 如果端口为空，设置默认值
-	if portStr == "" {
-		portStr = "3306"
+	dbType := database.TypeMySQL
+	if systemConfig, ok := c["system"].(map[string]interface{}); ok {
+		if v, _ := systemConfig["db-type"].(string); v != "" {
+			dbType = v
+		}
 	}
-
-	if host == "" || username == "" || dbname == "" {
+	if dbType != database.TypeSQLite && (host == "" || username == "" || dbname == "") {
 		return fmt.Errorf("数据库配置不完整")
 	}
 
-	// 构建DSN并连接数据库
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s",
-		username, password, host, portStr, dbname, config)
-
-	mysqlDriverConfig := mysql.Config{
-		DSN:                       dsn,
-		DefaultStringSize:         191,
-		SkipInitializeWithVersion: false,
+	// 连接数据库
+	dialector, err := database.NewDialector(config.MysqlConfig{
+		DbType:   dbType,
+		Path:     host,
+		Port:     portStr,
+		Config:   options,
+		Dbname:   dbname,
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		return err
 	}
 
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	}
 
-	db, err := gorm.Open(mysql.New(mysqlDriverConfig), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return fmt.Errorf("重新连接数据库失败: %v", err)
 	}
+	if err := secret.RegisterCallbacks(db); err != nil {
+		return fmt.Errorf("注册加密回调失败: %v", err)
+	}
 
 	// 更新全局数据库连接
 	global.APP_DB = db
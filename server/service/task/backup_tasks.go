@@ -0,0 +1,391 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	"oneclickvirt/service/auth"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultBackupRemoteTempDir 节点上存放临时归档的默认目录
+const defaultBackupRemoteTempDir = "/var/tmp/oneclickvirt-backups"
+
+// backupRemoteTempDir 获取节点上存放临时归档的目录
+func backupRemoteTempDir() string {
+	if dir := strings.TrimRight(global.APP_CONFIG.Backup.RemoteTempDir, "/"); dir != "" {
+		return dir
+	}
+	return defaultBackupRemoteTempDir
+}
+
+// backupArchiveExt 获取归档文件扩展名，保留 .tar.gz 这类双扩展名
+func backupArchiveExt(archivePath string) string {
+	base := filepath.Base(archivePath)
+	if strings.HasSuffix(base, ".tar.gz") {
+		return ".tar.gz"
+	}
+	return filepath.Ext(base)
+}
+
+// getBackupProvider 获取支持备份的Provider
+func getBackupProvider(providerID uint) (provider.Provider, provider.BackupProvider, error) {
+	providerApiService := &provider2.ProviderApiService{}
+	prov, _, err := providerApiService.GetProviderByID(providerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+	backupProvider, ok := prov.(provider.BackupProvider)
+	if !ok {
+		return nil, nil, fmt.Errorf("Provider类型 %s 不支持备份", prov.GetType())
+	}
+	return prov, backupProvider, nil
+}
+
+// removeRemoteArchive 删除节点上的临时归档
+func removeRemoteArchive(prov provider.Provider, remotePath string) {
+	if _, err := prov.ExecuteSSHCommand(context.Background(), fmt.Sprintf("rm -f %s", remotePath)); err != nil {
+		global.APP_LOG.Warn("删除节点临时归档失败",
+			zap.String("path", remotePath),
+			zap.Error(err))
+	}
+}
+
+// createLocalTempFile 在控制端临时目录创建文件，用于中转归档
+func createLocalTempFile(pattern string) (*os.File, error) {
+	tempDir := storage.GetStorageService().GetTempPath()
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %v", err)
+	}
+	return os.CreateTemp(tempDir, pattern)
+}
+
+// markBackupFailed 将备份标记为失败
+func markBackupFailed(backupID uint, errorMessage string) {
+	if err := global.APP_DB.Model(&providerModel.InstanceBackup{}).Where("id = ?", backupID).
+		Updates(map[string]interface{}{
+			"status":        providerModel.BackupStatusFailed,
+			"error_message": errorMessage,
+		}).Error; err != nil {
+		global.APP_LOG.Error("更新备份状态失败",
+			zap.Uint("backupId", backupID),
+			zap.Error(err))
+	}
+}
+
+// revertRestoringInstance 恢复结束后还原目标实例的状态
+func revertRestoringInstance(instanceID uint, originalStatus string) {
+	if originalStatus == "" {
+		originalStatus = "stopped"
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", instanceID, "restoring").
+		Update("status", originalStatus).Error; err != nil {
+		global.APP_LOG.Error("恢复实例状态失败",
+			zap.Uint("instanceId", instanceID),
+			zap.String("status", originalStatus),
+			zap.Error(err))
+	}
+}
+
+// executeBackupTask 执行备份任务：节点导出归档 → 下载到控制端 → 写入备份存储
+func (s *TaskService) executeBackupTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.BackupTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	err := s.runBackup(ctx, task, taskReq)
+	if err != nil {
+		global.APP_LOG.Error("实例备份失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("backupId", taskReq.BackupID),
+			zap.Error(err))
+		markBackupFailed(taskReq.BackupID, err.Error())
+	}
+	return err
+}
+
+// runBackup 备份任务的具体流程
+func (s *TaskService) runBackup(ctx context.Context, task *adminModel.Task, taskReq adminModel.BackupTaskRequest) error {
+	var backup providerModel.InstanceBackup
+	if err := global.APP_DB.First(&backup, taskReq.BackupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("备份不存在")
+		}
+		return fmt.Errorf("获取备份信息失败: %v", err)
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, backup.InstanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 10, "正在连接Provider...")
+
+	prov, backupProvider, err := getBackupProvider(instance.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	backend, err := storage.GetBackupBackend()
+	if err != nil {
+		return fmt.Errorf("初始化备份存储失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 20, "正在导出实例...")
+
+	remotePath, err := backupProvider.ExportInstance(ctx, instance.Name, backupRemoteTempDir())
+	if err != nil {
+		return fmt.Errorf("导出实例失败: %v", err)
+	}
+	defer removeRemoteArchive(prov, remotePath)
+
+	s.updateTaskProgress(task.ID, 50, "正在下载备份归档...")
+
+	localFile, err := createLocalTempFile("backup-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer func() {
+		localFile.Close()
+		os.Remove(localFile.Name())
+	}()
+
+	size, err := backupProvider.DownloadFile(ctx, remotePath, localFile)
+	if err != nil {
+		return fmt.Errorf("下载备份归档失败: %v", err)
+	}
+	if _, err := localFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取备份归档失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 75, "正在保存备份归档...")
+
+	storageKey := fmt.Sprintf("user-%d/instance-%d/%s%s", backup.UserID, backup.InstanceID, backup.UUID, backupArchiveExt(remotePath))
+	if err := backend.Put(ctx, storageKey, localFile, size); err != nil {
+		return fmt.Errorf("保存备份归档失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 90, "正在更新备份状态...")
+
+	now := time.Now()
+	if err := global.APP_DB.Model(&backup).Updates(map[string]interface{}{
+		"status":        providerModel.BackupStatusAvailable,
+		"error_message": "",
+		"storage_type":  backend.Type(),
+		"storage_key":   storageKey,
+		"size":          size,
+		"completed_at":  &now,
+	}).Error; err != nil {
+		// 记录更新失败时归档已无法被引用，直接删除
+		backend.Delete(context.Background(), storageKey)
+		return fmt.Errorf("更新备份记录失败: %v", err)
+	}
+
+	s.enforceBackupRetention(ctx, backup.UserID)
+
+	global.APP_LOG.Info("实例备份成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("backupId", backup.ID),
+		zap.String("instanceName", instance.Name),
+		zap.String("storageType", backend.Type()),
+		zap.Int64("size", size))
+	return nil
+}
+
+// enforceBackupRetention 按用户等级的备份数量限制清理最旧的备份
+func (s *TaskService) enforceBackupRetention(ctx context.Context, userID uint) {
+	permissionService := auth.PermissionService{}
+	effective, err := permissionService.GetUserEffectivePermission(userID)
+	if err != nil {
+		global.APP_LOG.Warn("获取用户权限失败，跳过备份保留数量检查",
+			zap.Uint("userId", userID),
+			zap.Error(err))
+		return
+	}
+
+	// 管理员不受限制
+	if effective.EffectiveType == "admin" {
+		return
+	}
+
+	retention := 0
+	if levelLimits, exists := global.APP_CONFIG.Quota.LevelLimits[effective.EffectiveLevel]; exists {
+		retention = levelLimits.MaxBackups
+	}
+	// 等级不允许备份时，保留管理员为其创建的备份
+	if retention <= 0 {
+		return
+	}
+
+	var backups []providerModel.InstanceBackup
+	if err := global.APP_DB.Where("user_id = ? AND status = ?", userID, providerModel.BackupStatusAvailable).
+		Order("created_at DESC").
+		Find(&backups).Error; err != nil || len(backups) <= retention {
+		return
+	}
+
+	for _, backup := range backups[retention:] {
+		backend, err := storage.GetBackupBackendByType(backup.StorageType)
+		if err == nil {
+			err = backend.Delete(ctx, backup.StorageKey)
+		}
+		if err != nil {
+			global.APP_LOG.Warn("删除过期备份归档失败",
+				zap.Uint("backupId", backup.ID),
+				zap.Error(err))
+			continue
+		}
+		if err := global.APP_DB.Delete(&backup).Error; err != nil {
+			global.APP_LOG.Warn("删除过期备份记录失败",
+				zap.Uint("backupId", backup.ID),
+				zap.Error(err))
+			continue
+		}
+		global.APP_LOG.Info("已清理超出保留数量的备份",
+			zap.Uint("userId", userID),
+			zap.Uint("backupId", backup.ID),
+			zap.Int("retention", retention))
+	}
+}
+
+// executeRestoreTask 执行恢复任务：读取备份存储 → 上传到目标节点 → 覆盖导入目标实例
+func (s *TaskService) executeRestoreTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.RestoreBackupTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+	defer revertRestoringInstance(taskReq.TargetInstanceID, taskReq.OriginalStatus)
+
+	err := s.runRestore(ctx, task, taskReq)
+	if err != nil {
+		global.APP_LOG.Error("备份恢复失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("backupId", taskReq.BackupID),
+			zap.Uint("targetInstanceId", taskReq.TargetInstanceID),
+			zap.Error(err))
+	}
+	return err
+}
+
+// runRestore 恢复任务的具体流程
+func (s *TaskService) runRestore(ctx context.Context, task *adminModel.Task, taskReq adminModel.RestoreBackupTaskRequest) error {
+	var backup providerModel.InstanceBackup
+	if err := global.APP_DB.First(&backup, taskReq.BackupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("备份不存在")
+		}
+		return fmt.Errorf("获取备份信息失败: %v", err)
+	}
+	if backup.Status != providerModel.BackupStatusAvailable {
+		return fmt.Errorf("备份当前不可用")
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, taskReq.TargetInstanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("目标实例不存在")
+		}
+		return fmt.Errorf("获取目标实例信息失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 10, "正在连接Provider...")
+
+	prov, backupProvider, err := getBackupProvider(instance.ProviderID)
+	if err != nil {
+		return err
+	}
+	if prov.GetType() != backup.ProviderType {
+		return fmt.Errorf("备份来自 %s 类型的Provider，无法恢复到 %s 类型的Provider", backup.ProviderType, prov.GetType())
+	}
+
+	backend, err := storage.GetBackupBackendByType(backup.StorageType)
+	if err != nil {
+		return fmt.Errorf("初始化备份存储失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 20, "正在读取备份归档...")
+
+	localFile, err := createLocalTempFile("restore-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer func() {
+		localFile.Close()
+		os.Remove(localFile.Name())
+	}()
+
+	reader, err := backend.Get(ctx, backup.StorageKey)
+	if err != nil {
+		return fmt.Errorf("读取备份归档失败: %v", err)
+	}
+	_, err = io.Copy(localFile, reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("读取备份归档失败: %v", err)
+	}
+	if _, err := localFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取备份归档失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 40, "正在上传备份归档到节点...")
+
+	remoteDir := backupRemoteTempDir()
+	if _, err := prov.ExecuteSSHCommand(ctx, fmt.Sprintf("mkdir -p %s", remoteDir)); err != nil {
+		return fmt.Errorf("创建节点临时目录失败: %v", err)
+	}
+	remotePath := fmt.Sprintf("%s/restore-%s%s", remoteDir, backup.UUID, backupArchiveExt(backup.StorageKey))
+	if err := backupProvider.UploadFile(ctx, localFile, remotePath); err != nil {
+		removeRemoteArchive(prov, remotePath)
+		return fmt.Errorf("上传备份归档失败: %v", err)
+	}
+	defer removeRemoteArchive(prov, remotePath)
+
+	s.updateTaskProgress(task.ID, 60, "正在导入备份...")
+
+	if err := backupProvider.ImportInstance(ctx, instance.Name, remotePath); err != nil {
+		return fmt.Errorf("导入备份失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 90, "正在同步实例信息...")
+
+	// Docker恢复时会重建容器，内网IP可能变化
+	if providerInstance, err := prov.GetInstance(ctx, instance.Name); err == nil && providerInstance.PrivateIP != "" &&
+		providerInstance.PrivateIP != instance.PrivateIP {
+		if err := global.APP_DB.Model(&instance).Update("private_ip", providerInstance.PrivateIP).Error; err != nil {
+			global.APP_LOG.Warn("更新实例内网IP失败",
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+	}
+
+	global.APP_LOG.Info("备份恢复成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("backupId", backup.ID),
+		zap.Uint("targetInstanceId", instance.ID),
+		zap.String("instanceName", instance.Name))
+	return nil
+}
@@ -8,7 +8,10 @@ import (
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
+	"oneclickvirt/service/notify"
 	"oneclickvirt/service/resources"
+	"oneclickvirt/service/webhook"
 	"time"
 
 	"go.uber.org/zap"
@@ -77,6 +80,8 @@ func (s *TaskService) CompleteTask(taskID uint, success bool, errorMessage strin
 		zap.Bool("success", success),
 		zap.String("errorMessage", errorMessage))
 
+	s.publishTaskEvent(&task, success, errorMessage)
+
 	// 任务完成后，立即触发调度器检查pending任务
 	if global.APP_SCHEDULER != nil {
 		global.APP_SCHEDULER.TriggerTaskProcessing()
@@ -86,6 +91,40 @@ func (s *TaskService) CompleteTask(taskID uint, success bool, errorMessage strin
 	return nil
 }
 
+// publishTaskEvent 发送任务完成/失败的Webhook事件，任务失败时同时通知用户
+func (s *TaskService) publishTaskEvent(task *adminModel.Task, success bool, errorMessage string) {
+	event := systemModel.WebhookEventTaskCompleted
+	if !success {
+		event = systemModel.WebhookEventTaskFailed
+	}
+
+	data := map[string]interface{}{
+		"taskId":     task.ID,
+		"taskUuid":   task.UUID,
+		"taskType":   task.TaskType,
+		"userId":     task.UserID,
+		"providerId": task.ProviderID,
+		"instanceId": task.InstanceID,
+	}
+	if !success {
+		data["errorMessage"] = errorMessage
+	}
+	webhook.Publish(event, data)
+
+	if !success {
+		var instanceID uint
+		if task.InstanceID != nil {
+			instanceID = *task.InstanceID
+		}
+		notify.Notify(task.UserID, systemModel.NotifyEventTaskFailed, map[string]interface{}{
+			"TaskID":       task.ID,
+			"TaskType":     task.TaskType,
+			"InstanceID":   instanceID,
+			"ErrorMessage": errorMessage,
+		})
+	}
+}
+
 // ReleaseTaskLocks 空实现 - channel池架构无需显式释放锁
 func (s *TaskService) ReleaseTaskLocks(taskID uint) {
 	// channel池架构自动处理并发控制，无需显式释放
@@ -361,6 +400,54 @@ func (s *TaskService) handleCancelledTaskCleanup(taskID uint) {
 		}
 	}
 
+	// 处理快照任务的清理
+	if task.TaskType == "create-snapshot" || task.TaskType == "restore-snapshot" || task.TaskType == "delete-snapshot" {
+		if snapshotID := snapshotTaskIDFromData(task.TaskData); snapshotID > 0 {
+			if task.TaskType == "create-snapshot" {
+				markSnapshotStatus(snapshotID, providerModel.SnapshotStatusFailed, "任务已取消")
+			} else {
+				markSnapshotStatus(snapshotID, providerModel.SnapshotStatusAvailable, "")
+			}
+		}
+		return
+	}
+
+	// 处理备份任务的清理
+	if task.TaskType == "backup" {
+		var taskReq adminModel.BackupTaskRequest
+		if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err == nil && taskReq.BackupID > 0 {
+			markBackupFailed(taskReq.BackupID, "任务已取消")
+		}
+		return
+	}
+
+	// 处理恢复任务的清理
+	if task.TaskType == "restore" {
+		var taskReq adminModel.RestoreBackupTaskRequest
+		if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err == nil && taskReq.TargetInstanceID > 0 {
+			revertRestoringInstance(taskReq.TargetInstanceID, taskReq.OriginalStatus)
+		}
+		return
+	}
+
+	// 处理迁移任务的清理（执行中的迁移由任务自身回滚目标节点资源）
+	if task.TaskType == "migrate" {
+		var taskReq adminModel.MigrateInstanceTaskRequest
+		if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err == nil && taskReq.InstanceID > 0 {
+			revertMigratingInstance(taskReq.InstanceID, taskReq.OriginalStatus, 0)
+		}
+		return
+	}
+
+	// 处理调整规格任务的清理
+	if task.TaskType == "resize" {
+		var taskReq adminModel.ResizeInstanceTaskRequest
+		if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err == nil && taskReq.InstanceID > 0 {
+			revertResizingInstance(taskReq.InstanceID, taskReq.OriginalStatus)
+		}
+		return
+	}
+
 	// 处理其他操作任务（start、stop、restart）的清理
 	if (task.TaskType == "start" || task.TaskType == "stop" || task.TaskType == "restart") && task.InstanceID != nil {
 		// 获取实例信息
@@ -10,6 +10,8 @@ import (
 	providerModel "oneclickvirt/model/provider"
 	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
 	"oneclickvirt/service/database"
+	"oneclickvirt/service/domain"
+	"oneclickvirt/service/ipam"
 	provider2 "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/service/traffic"
@@ -85,6 +87,16 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 	// 更新进度 (25%)
 	s.updateTaskProgress(task.ID, 25, "正在删除实例...")
 
+	// 移除实例防火墙，LXD网络ACL和Docker的iptables链不会随实例一起删除
+	if hasSecurityGroup(instance.ID) {
+		if err := removeInstanceFirewall(ctx, localProviderID, instance.Name); err != nil {
+			global.APP_LOG.Warn("移除实例防火墙失败",
+				zap.Uint("taskId", task.ID),
+				zap.Uint("instanceId", instance.ID),
+				zap.Error(err))
+		}
+	}
+
 	// 调用Provider删除实例，重试机制
 	providerApiService := &provider2.ProviderApiService{}
 	maxRetries := global.APP_CONFIG.Task.DeleteRetryCount
@@ -161,6 +173,18 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 			zap.Error(err))
 	}
 
+	// 清理实例快照（Provider删除失败时保留快照，便于人工处理）
+	if providerDeleteSuccess {
+		s.cleanupInstanceSnapshots(deleteCtx, &instance, localProviderID)
+	}
+
+	// 删除定时备份计划，已有备份保留，可恢复到其他实例
+	if err := global.APP_DB.Where("instance_id = ?", instance.ID).Delete(&providerModel.BackupSchedule{}).Error; err != nil {
+		global.APP_LOG.Warn("删除实例定时备份计划失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+	}
+
 	// 更新进度 (90%)
 	s.updateTaskProgress(task.ID, 90, "正在清理数据库记录...")
 
@@ -177,6 +201,7 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 	instanceProviderID := instance.ProviderID
 	instanceType := instance.InstanceType
 	instanceUserID := instance.UserID
+	hasDomains := domain.HasInstanceDomains(instanceID)
 
 	// 分离事务操作
 	err := dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
@@ -190,6 +215,30 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 			// 端口映射删除失败不阻止整个流程
 		}
 
+		// 释放实例占用的独立IP地址
+		if err := ipam.NewService().ReleaseByInstanceInTx(tx, instanceID); err != nil {
+			global.APP_LOG.Warn("释放实例独立IP失败",
+				zap.Uint("taskId", task.ID),
+				zap.Uint("instanceId", instanceID),
+				zap.Error(err))
+		}
+
+		// 解除安全组绑定，安全组本身属于用户，保留
+		if err := tx.Where("instance_id = ?", instanceID).Delete(&providerModel.InstanceSecurityGroup{}).Error; err != nil {
+			global.APP_LOG.Warn("解除实例安全组绑定失败",
+				zap.Uint("taskId", task.ID),
+				zap.Uint("instanceId", instanceID),
+				zap.Error(err))
+		}
+
+		// 删除域名绑定，反向代理配置在事务提交后同步
+		if err := domain.DeleteByInstanceInTx(tx, instanceID); err != nil {
+			global.APP_LOG.Warn("删除实例域名绑定失败",
+				zap.Uint("taskId", task.ID),
+				zap.Uint("instanceId", instanceID),
+				zap.Error(err))
+		}
+
 		// 2. 释放Provider资源
 		resourceService := &resources.ResourceService{}
 		if err := resourceService.ReleaseResourcesInTx(tx, instanceProviderID, instanceType,
@@ -259,6 +308,15 @@ func (s *TaskService) executeDeleteInstanceTask(ctx context.Context, task *admin
 		return err
 	}
 
+	if hasDomains {
+		if err := domain.NewService().SyncProvider(instanceProviderID); err != nil {
+			global.APP_LOG.Warn("删除实例后同步域名反向代理配置失败",
+				zap.Uint("taskId", task.ID),
+				zap.Uint("providerId", instanceProviderID),
+				zap.Error(err))
+		}
+	}
+
 	// 标记任务完成
 	operationType := "用户"
 	if taskReq.AdminOperation {
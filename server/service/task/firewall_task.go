@@ -0,0 +1,167 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	provider2 "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// executeApplyFirewallTask 执行应用实例防火墙任务：实例绑定了安全组时下发规则，否则移除实例防火墙
+func (s *TaskService) executeApplyFirewallTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 10, "正在解析任务数据...")
+
+	var taskReq adminModel.ApplyFirewallTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 30, "正在下发防火墙规则...")
+
+	if err := s.applyInstanceFirewall(ctx, taskReq.InstanceID); err != nil {
+		global.APP_LOG.Error("应用实例防火墙失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("instanceId", taskReq.InstanceID),
+			zap.Error(err))
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 100, "防火墙规则已生效")
+	return nil
+}
+
+// applyInstanceFirewall 按实例当前绑定的安全组下发防火墙规则并记录应用结果，未绑定时移除实例防火墙
+func (s *TaskService) applyInstanceFirewall(ctx context.Context, instanceID uint) error {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Select("id, name, provider_id").First(&instance, instanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	var binding providerModel.InstanceSecurityGroup
+	if err := global.APP_DB.Where("instance_id = ?", instanceID).First(&binding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return removeInstanceFirewall(ctx, instance.ProviderID, instance.Name)
+		}
+		return fmt.Errorf("获取实例安全组失败: %v", err)
+	}
+
+	err := func() error {
+		var group providerModel.SecurityGroup
+		if err := global.APP_DB.Preload("Rules").First(&group, binding.SecurityGroupID).Error; err != nil {
+			return fmt.Errorf("获取安全组失败: %v", err)
+		}
+
+		prov, _, err := (&provider2.ProviderApiService{}).GetProviderByID(instance.ProviderID)
+		if err != nil {
+			return fmt.Errorf("获取Provider失败: %v", err)
+		}
+		firewallProvider, ok := prov.(provider.FirewallProvider)
+		if !ok {
+			return fmt.Errorf("Provider类型 %s 不支持实例防火墙", prov.GetType())
+		}
+		return firewallProvider.ApplyFirewall(ctx, instance.Name, buildFirewallPolicy(&group))
+	}()
+
+	updates := map[string]interface{}{
+		"apply_status": providerModel.SecurityGroupApplyApplied,
+		"apply_error":  "",
+	}
+	if err != nil {
+		updates["apply_status"] = providerModel.SecurityGroupApplyFailed
+		updates["apply_error"] = truncateApplyError(err.Error())
+	} else {
+		updates["applied_at"] = time.Now()
+	}
+	if dbErr := global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).
+		Where("id = ?", binding.ID).Updates(updates).Error; dbErr != nil {
+		global.APP_LOG.Warn("更新安全组应用状态失败",
+			zap.Uint("instanceId", instanceID),
+			zap.Error(dbErr))
+	}
+	return err
+}
+
+// removeInstanceFirewall 移除实例在Provider上的防火墙，Provider不支持防火墙时忽略
+func removeInstanceFirewall(ctx context.Context, providerID uint, instanceName string) error {
+	prov, _, err := (&provider2.ProviderApiService{}).GetProviderByID(providerID)
+	if err != nil {
+		return fmt.Errorf("获取Provider失败: %v", err)
+	}
+	firewallProvider, ok := prov.(provider.FirewallProvider)
+	if !ok {
+		return nil
+	}
+	return firewallProvider.RemoveFirewall(ctx, instanceName)
+}
+
+// hasSecurityGroup 实例是否绑定了安全组
+func hasSecurityGroup(instanceID uint) bool {
+	var count int64
+	global.APP_DB.Model(&providerModel.InstanceSecurityGroup{}).Where("instance_id = ?", instanceID).Count(&count)
+	return count > 0
+}
+
+// buildFirewallPolicy 将安全组转换为Provider防火墙策略
+func buildFirewallPolicy(group *providerModel.SecurityGroup) provider.FirewallPolicy {
+	policy := provider.FirewallPolicy{
+		Rules:         make([]provider.FirewallRule, 0, len(group.Rules)),
+		DefaultAction: group.DefaultAction,
+	}
+	for _, r := range group.Rules {
+		policy.Rules = append(policy.Rules, provider.FirewallRule{
+			Action:    r.Action,
+			Protocol:  r.Protocol,
+			PortStart: r.PortStart,
+			PortEnd:   r.PortEnd,
+			Source:    r.Source,
+		})
+	}
+	return policy
+}
+
+// truncateApplyError 截断错误信息以适应apply_error字段长度
+func truncateApplyError(msg string) string {
+	runes := []rune(msg)
+	if len(runes) > 200 {
+		return string(runes[:200])
+	}
+	return msg
+}
+
+// transferSecurityGroupInTx 将安全组绑定转移到新的实例记录（重置会创建新的实例记录）
+func transferSecurityGroupInTx(tx *gorm.DB, oldInstanceID, newInstanceID uint) error {
+	if err := tx.Model(&providerModel.InstanceSecurityGroup{}).
+		Where("instance_id = ?", oldInstanceID).
+		Updates(map[string]interface{}{
+			"instance_id":  newInstanceID,
+			"apply_status": providerModel.SecurityGroupApplyPending,
+		}).Error; err != nil {
+		return fmt.Errorf("转移实例安全组失败: %v", err)
+	}
+	return nil
+}
+
+// reapplyFirewallAfterMove 实例重置或迁移后按原安全组重新下发规则，失败只记录日志，可由管理员重新应用
+func (s *TaskService) reapplyFirewallAfterMove(ctx context.Context, instanceID uint) {
+	if !hasSecurityGroup(instanceID) {
+		return
+	}
+	if err := s.applyInstanceFirewall(ctx, instanceID); err != nil {
+		global.APP_LOG.Warn("重新应用实例安全组失败",
+			zap.Uint("instanceId", instanceID),
+			zap.Error(err))
+	}
+}
@@ -95,6 +95,22 @@ func (s *TaskService) executeTaskLogic(ctx context.Context, task *adminModel.Tas
 		return s.executeCreatePortMappingTask(ctx, task)
 	case "delete-port-mapping":
 		return s.executeDeletePortMappingTask(ctx, task)
+	case "create-snapshot":
+		return s.executeCreateSnapshotTask(ctx, task)
+	case "restore-snapshot":
+		return s.executeRestoreSnapshotTask(ctx, task)
+	case "delete-snapshot":
+		return s.executeDeleteSnapshotTask(ctx, task)
+	case "backup":
+		return s.executeBackupTask(ctx, task)
+	case "restore":
+		return s.executeRestoreTask(ctx, task)
+	case "migrate":
+		return s.executeMigrateTask(ctx, task)
+	case "resize":
+		return s.executeResizeInstanceTask(ctx, task)
+	case "apply-firewall":
+		return s.executeApplyFirewallTask(ctx, task)
 	default:
 		return fmt.Errorf("未知的任务类型: %s", task.TaskType)
 	}
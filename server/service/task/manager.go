@@ -56,6 +56,22 @@ func (s *TaskService) calculateEstimatedDuration(taskType string, instanceType s
 		return 300 // 5分钟 - 删除操作
 	case "reset-password":
 		return 30 // 30秒 - 密码重置操作快
+	case "create-snapshot":
+		return 120 // 2分钟 - 快照创建
+	case "restore-snapshot":
+		return 180 // 3分钟 - 快照恢复
+	case "delete-snapshot":
+		return 60 // 1分钟 - 快照删除
+	case "backup":
+		return 600 // 10分钟 - 导出并上传归档
+	case "restore":
+		return 600 // 10分钟 - 下载并导入归档
+	case "migrate":
+		return 900 // 15分钟 - 导出、创建、导入并切换
+	case "resize":
+		return 60 // 1分钟 - 调整规格
+	case "apply-firewall":
+		return 30 // 30秒 - 下发防火墙规则
 	default:
 		return 120 // 默认2分钟 - 保守估计
 	}
@@ -0,0 +1,634 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	"oneclickvirt/service/domain"
+	"oneclickvirt/service/ipam"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MigrateTaskContext 迁移任务上下文
+type MigrateTaskContext struct {
+	Instance        providerModel.Instance
+	SourceProvider  providerModel.Provider
+	TargetProvider  providerModel.Provider
+	SystemImage     systemModel.SystemImage
+	OldPortMappings []providerModel.Port
+	NewPortMappings []providerModel.Port
+	OriginalStatus  string
+	SourceRunning   bool     // 迁移前源实例是否在运行，回滚时需要重新启动
+	LocalArchive    *os.File // 控制端中转归档
+	ArchiveExt      string
+	TargetCreated   bool // 已在目标Provider上创建实例，回滚时需要删除
+	Switched        bool // 数据库已切换到目标Provider，之后不再回滚
+	NewPrivateIP    string
+}
+
+// executeMigrateTask 执行实例迁移任务：源节点导出 → 目标节点创建同规格实例 → 导入归档 → 切换数据库记录 → 删除源实例
+func (s *TaskService) executeMigrateTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.MigrateInstanceTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	migrateCtx := MigrateTaskContext{OriginalStatus: taskReq.OriginalStatus}
+	defer func() {
+		if migrateCtx.LocalArchive != nil {
+			migrateCtx.LocalArchive.Close()
+			os.Remove(migrateCtx.LocalArchive.Name())
+		}
+	}()
+
+	err := s.runMigrate(ctx, task, &taskReq, &migrateCtx)
+	if err != nil {
+		global.APP_LOG.Error("实例迁移失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("instanceId", taskReq.InstanceID),
+			zap.Uint("sourceProviderId", taskReq.SourceProviderID),
+			zap.Uint("targetProviderId", taskReq.TargetProviderID),
+			zap.Error(err))
+		if !migrateCtx.Switched {
+			s.migrateTask_Rollback(&taskReq, &migrateCtx)
+		}
+	}
+	return err
+}
+
+// runMigrate 迁移任务的具体流程
+func (s *TaskService) runMigrate(ctx context.Context, task *adminModel.Task, taskReq *adminModel.MigrateInstanceTaskRequest, migrateCtx *MigrateTaskContext) error {
+	// 阶段1: 准备阶段 - 收集必要信息
+	if err := s.migrateTask_Prepare(ctx, task, taskReq, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段2: 停止源实例并导出归档
+	if err := s.migrateTask_ExportSource(ctx, task, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段3: 在目标Provider上分配端口
+	if err := s.migrateTask_AllocateTargetPorts(ctx, task, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段4: 在目标Provider上创建同规格实例
+	if err := s.migrateTask_CreateTarget(ctx, task, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段5: 导入归档覆盖目标实例
+	if err := s.migrateTask_ImportTarget(ctx, task, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段6: 原子切换数据库记录和资源占用
+	if err := s.migrateTask_Switch(ctx, task, migrateCtx); err != nil {
+		return err
+	}
+
+	// 阶段7: 删除源实例（失败不影响迁移结果）
+	s.migrateTask_CleanupSource(ctx, task, migrateCtx)
+
+	// 阶段8: 配置目标端口映射和流量监控（失败不影响迁移结果）
+	s.migrateTask_FinalizeTarget(ctx, task, migrateCtx)
+
+	s.updateTaskProgress(task.ID, 100, "迁移完成")
+
+	global.APP_LOG.Info("实例迁移成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.String("instanceName", migrateCtx.Instance.Name),
+		zap.String("sourceProvider", migrateCtx.SourceProvider.Name),
+		zap.String("targetProvider", migrateCtx.TargetProvider.Name))
+	return nil
+}
+
+// migrateTask_Prepare 阶段1: 查询实例、源/目标Provider、系统镜像和端口映射
+func (s *TaskService) migrateTask_Prepare(ctx context.Context, task *adminModel.Task, taskReq *adminModel.MigrateInstanceTaskRequest, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 8, "正在准备迁移...")
+
+	err := s.dbService.ExecuteQuery(ctx, func() error {
+		if err := global.APP_DB.First(&migrateCtx.Instance, taskReq.InstanceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("实例不存在")
+			}
+			return fmt.Errorf("获取实例信息失败: %v", err)
+		}
+		if migrateCtx.Instance.ProviderID != taskReq.SourceProviderID {
+			return fmt.Errorf("实例已不在源Provider上")
+		}
+
+		if err := global.APP_DB.First(&migrateCtx.SourceProvider, taskReq.SourceProviderID).Error; err != nil {
+			return fmt.Errorf("获取源Provider配置失败: %v", err)
+		}
+		if err := global.APP_DB.First(&migrateCtx.TargetProvider, taskReq.TargetProviderID).Error; err != nil {
+			return fmt.Errorf("获取目标Provider配置失败: %v", err)
+		}
+
+		if err := global.APP_DB.Where("name = ? AND provider_type = ? AND instance_type = ? AND architecture = ?",
+			migrateCtx.Instance.Image, migrateCtx.TargetProvider.Type, migrateCtx.Instance.InstanceType, migrateCtx.TargetProvider.Architecture).
+			First(&migrateCtx.SystemImage).Error; err != nil {
+			return fmt.Errorf("目标Provider缺少可用的系统镜像: %v", err)
+		}
+
+		// 软删除的记录同样占用 name+provider_id 唯一索引
+		var count int64
+		if err := global.APP_DB.Unscoped().Model(&providerModel.Instance{}).
+			Where("name = ? AND provider_id = ?", migrateCtx.Instance.Name, migrateCtx.TargetProvider.ID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("检查目标Provider实例名称失败: %v", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("目标Provider上已存在同名实例 %s", migrateCtx.Instance.Name)
+		}
+
+		if err := global.APP_DB.Where("instance_id = ? AND provider_id = ? AND status = ?",
+			migrateCtx.Instance.ID, migrateCtx.SourceProvider.ID, "active").
+			Find(&migrateCtx.OldPortMappings).Error; err != nil {
+			global.APP_LOG.Warn("获取旧端口映射失败", zap.Error(err))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if migrateCtx.SourceProvider.Type != migrateCtx.TargetProvider.Type {
+		return fmt.Errorf("源Provider类型 %s 与目标Provider类型 %s 不一致", migrateCtx.SourceProvider.Type, migrateCtx.TargetProvider.Type)
+	}
+	// 创建任务后地址池配置可能发生变化，执行前再次检查
+	if err := ipam.NewService().ValidateMigration(migrateCtx.Instance.ID, &migrateCtx.SourceProvider, &migrateCtx.TargetProvider); err != nil {
+		return err
+	}
+	if migrateCtx.OriginalStatus == "" {
+		migrateCtx.OriginalStatus = "stopped"
+	}
+
+	global.APP_LOG.Info("迁移准备阶段完成",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.String("instanceName", migrateCtx.Instance.Name),
+		zap.Int("portMappings", len(migrateCtx.OldPortMappings)))
+	return nil
+}
+
+// migrateTask_ExportSource 阶段2: 停止源实例，导出归档并下载到控制端
+func (s *TaskService) migrateTask_ExportSource(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 12, "正在停止源实例...")
+
+	prov, backupProvider, err := getBackupProvider(migrateCtx.SourceProvider.ID)
+	if err != nil {
+		return fmt.Errorf("源Provider: %v", err)
+	}
+
+	// 冷迁移：停机后导出，保证归档数据一致
+	if providerInstance, err := prov.GetInstance(ctx, migrateCtx.Instance.Name); err == nil && providerInstance.Status == "running" {
+		migrateCtx.SourceRunning = true
+		if err := prov.StopInstance(ctx, migrateCtx.Instance.Name); err != nil {
+			return fmt.Errorf("停止源实例失败: %v", err)
+		}
+	}
+
+	s.updateTaskProgress(task.ID, 18, "正在导出源实例...")
+
+	remotePath, err := backupProvider.ExportInstance(ctx, migrateCtx.Instance.Name, backupRemoteTempDir())
+	if err != nil {
+		return fmt.Errorf("导出源实例失败: %v", err)
+	}
+	defer removeRemoteArchive(prov, remotePath)
+	migrateCtx.ArchiveExt = backupArchiveExt(remotePath)
+
+	s.updateTaskProgress(task.ID, 30, "正在下载迁移归档...")
+
+	localFile, err := createLocalTempFile("migrate-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	migrateCtx.LocalArchive = localFile
+
+	if _, err := backupProvider.DownloadFile(ctx, remotePath, localFile); err != nil {
+		return fmt.Errorf("下载迁移归档失败: %v", err)
+	}
+
+	// 源实例即将停用，提前清理其流量监控
+	if err := traffic_monitor.GetManager().DetachMonitor(ctx, migrateCtx.Instance.ID); err != nil {
+		global.APP_LOG.Warn("清理源实例pmacct监控失败", zap.Error(err))
+	}
+
+	global.APP_LOG.Info("源实例导出完成",
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.String("remotePath", remotePath))
+	return nil
+}
+
+// migrateTask_AllocateTargetPorts 阶段3: 在目标Provider上为实例分配端口
+// 默认端口段按目标Provider的配置重新分配，手动添加的端口在目标节点空闲时保留原主机端口
+func (s *TaskService) migrateTask_AllocateTargetPorts(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 40, "正在分配目标端口...")
+
+	portMappingService := &resources.PortMappingService{}
+	if err := portMappingService.CreateDefaultPortMappings(migrateCtx.Instance.ID, migrateCtx.TargetProvider.ID); err != nil {
+		return fmt.Errorf("分配目标端口失败: %v", err)
+	}
+
+	var manualPorts []providerModel.Port
+	for _, oldPort := range migrateCtx.OldPortMappings {
+		if !oldPort.IsAutomatic {
+			manualPorts = append(manualPorts, oldPort)
+		}
+	}
+	if len(manualPorts) > 0 {
+		hostPorts := make([]int, 0, len(manualPorts))
+		for _, port := range manualPorts {
+			hostPorts = append(hostPorts, port.HostPort)
+		}
+		available := portMappingService.BatchCheckPortAvailability(migrateCtx.TargetProvider.ID, hostPorts, "both")
+
+		for _, oldPort := range manualPorts {
+			if !available[oldPort.HostPort] {
+				global.APP_LOG.Warn("目标Provider端口已被占用，跳过手动端口映射",
+					zap.Uint("instanceId", migrateCtx.Instance.ID),
+					zap.Int("hostPort", oldPort.HostPort),
+					zap.Int("guestPort", oldPort.GuestPort))
+				continue
+			}
+			err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+				var existing providerModel.Port
+				if err := tx.Where("provider_id = ? AND host_port = ? AND status = 'active'",
+					migrateCtx.TargetProvider.ID, oldPort.HostPort).First(&existing).Error; err != gorm.ErrRecordNotFound {
+					return fmt.Errorf("端口 %d 已被占用", oldPort.HostPort)
+				}
+				newPort := providerModel.Port{
+					InstanceID:    migrateCtx.Instance.ID,
+					ProviderID:    migrateCtx.TargetProvider.ID,
+					HostPort:      oldPort.HostPort,
+					GuestPort:     oldPort.GuestPort,
+					Protocol:      oldPort.Protocol,
+					Description:   oldPort.Description,
+					Status:        "active",
+					IsSSH:         false,
+					IsAutomatic:   false,
+					PortType:      oldPort.PortType,
+					MappingMethod: oldPort.MappingMethod,
+					IPv6Enabled:   oldPort.IPv6Enabled,
+				}
+				return tx.Create(&newPort).Error
+			})
+			if err != nil {
+				global.APP_LOG.Warn("创建手动端口映射记录失败",
+					zap.Int("hostPort", oldPort.HostPort),
+					zap.Error(err))
+			}
+		}
+	}
+
+	if err := global.APP_DB.Where("instance_id = ? AND provider_id = ? AND status = ?",
+		migrateCtx.Instance.ID, migrateCtx.TargetProvider.ID, "active").
+		Find(&migrateCtx.NewPortMappings).Error; err != nil {
+		return fmt.Errorf("获取目标端口映射失败: %v", err)
+	}
+
+	global.APP_LOG.Info("目标端口分配完成",
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.Int("portCount", len(migrateCtx.NewPortMappings)))
+	return nil
+}
+
+// migrateTask_CreateTarget 阶段4: 在目标Provider上创建同规格实例
+func (s *TaskService) migrateTask_CreateTarget(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 50, "正在目标Provider上创建实例...")
+
+	var user userModel.User
+	if err := global.APP_DB.First(&user, migrateCtx.Instance.UserID).Error; err != nil {
+		return fmt.Errorf("获取用户信息失败: %v", err)
+	}
+
+	target := migrateCtx.TargetProvider
+	createReq := provider2.CreateInstanceRequest{
+		InstanceConfig: providerModel.ProviderInstanceConfig{
+			Name:         migrateCtx.Instance.Name,
+			Image:        migrateCtx.Instance.Image,
+			InstanceType: migrateCtx.Instance.InstanceType,
+			CPU:          fmt.Sprintf("%d", migrateCtx.Instance.CPU),
+			Memory:       fmt.Sprintf("%dm", migrateCtx.Instance.Memory),
+			Disk:         fmt.Sprintf("%dm", migrateCtx.Instance.Disk),
+			Env:          map[string]string{"MIGRATE_OPERATION": "true"},
+			Metadata: map[string]string{
+				"user_level":               fmt.Sprintf("%d", user.Level),
+				"bandwidth_spec":           fmt.Sprintf("%d", migrateCtx.Instance.Bandwidth),
+				"ipv4_port_mapping_method": target.IPv4PortMappingMethod,
+				"ipv6_port_mapping_method": target.IPv6PortMappingMethod,
+				"network_type":             target.NetworkType,
+				"instance_id":              fmt.Sprintf("%d", migrateCtx.Instance.ID),
+				"provider_id":              fmt.Sprintf("%d", target.ID),
+				"migrate_from_provider_id": fmt.Sprintf("%d", migrateCtx.SourceProvider.ID),
+			},
+			Privileged:   boolPtr(target.ContainerPrivileged),
+			AllowNesting: boolPtr(target.ContainerAllowNesting),
+			EnableLXCFS:  boolPtr(target.ContainerEnableLXCFS),
+			CPUAllowance: stringPtr(target.ContainerCPUAllowance),
+			MemorySwap:   boolPtr(target.ContainerMemorySwap),
+			MaxProcesses: intPtr(target.ContainerMaxProcesses),
+			DiskIOLimit:  stringPtr(target.ContainerDiskIOLimit),
+		},
+		SystemImageID: migrateCtx.SystemImage.ID,
+	}
+
+	// Docker端口映射在创建容器时指定
+	if target.Type == "docker" && len(migrateCtx.NewPortMappings) > 0 {
+		var ports []string
+		for _, port := range migrateCtx.NewPortMappings {
+			if port.Protocol == "both" {
+				ports = append(ports,
+					fmt.Sprintf("0.0.0.0:%d:%d/tcp", port.HostPort, port.GuestPort),
+					fmt.Sprintf("0.0.0.0:%d:%d/udp", port.HostPort, port.GuestPort))
+			} else {
+				ports = append(ports,
+					fmt.Sprintf("0.0.0.0:%d:%d/%s", port.HostPort, port.GuestPort, port.Protocol))
+			}
+		}
+		createReq.InstanceConfig.Ports = ports
+	}
+
+	// 创建失败时目标节点上可能残留部分资源，同样需要回滚清理
+	migrateCtx.TargetCreated = true
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.CreateInstanceByProviderID(ctx, target.ID, createReq); err != nil {
+		return fmt.Errorf("目标Provider创建实例失败: %v", err)
+	}
+
+	global.APP_LOG.Info("目标实例创建完成",
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.String("targetProvider", target.Name))
+	return nil
+}
+
+// migrateTask_ImportTarget 阶段5: 上传归档并覆盖导入目标实例
+func (s *TaskService) migrateTask_ImportTarget(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 65, "正在上传迁移归档到目标节点...")
+
+	prov, backupProvider, err := getBackupProvider(migrateCtx.TargetProvider.ID)
+	if err != nil {
+		return fmt.Errorf("目标Provider: %v", err)
+	}
+
+	if _, err := migrateCtx.LocalArchive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取迁移归档失败: %v", err)
+	}
+
+	remoteDir := backupRemoteTempDir()
+	if _, err := prov.ExecuteSSHCommand(ctx, fmt.Sprintf("mkdir -p %s", remoteDir)); err != nil {
+		return fmt.Errorf("创建节点临时目录失败: %v", err)
+	}
+	remotePath := fmt.Sprintf("%s/migrate-%s%s", remoteDir, migrateCtx.Instance.UUID, migrateCtx.ArchiveExt)
+	defer removeRemoteArchive(prov, remotePath)
+	if err := backupProvider.UploadFile(ctx, migrateCtx.LocalArchive, remotePath); err != nil {
+		return fmt.Errorf("上传迁移归档失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 75, "正在导入实例数据...")
+
+	if err := backupProvider.ImportInstance(ctx, migrateCtx.Instance.Name, remotePath); err != nil {
+		return fmt.Errorf("导入实例数据失败: %v", err)
+	}
+
+	// 导入后保持实例运行以获取内网IP并配置端口映射，迁移前为停止状态的实例在最后阶段再停止
+	if providerInstance, err := prov.GetInstance(ctx, migrateCtx.Instance.Name); err == nil && providerInstance.Status != "running" {
+		if err := prov.StartInstance(ctx, migrateCtx.Instance.Name); err != nil {
+			global.APP_LOG.Warn("启动目标实例失败", zap.Error(err))
+		}
+	}
+
+	migrateCtx.NewPrivateIP = getInstancePrivateIP(ctx, prov, migrateCtx.TargetProvider.Type, migrateCtx.Instance.Name)
+	if migrateCtx.NewPrivateIP == "" {
+		if providerInstance, err := prov.GetInstance(ctx, migrateCtx.Instance.Name); err == nil {
+			migrateCtx.NewPrivateIP = providerInstance.PrivateIP
+		}
+	}
+
+	global.APP_LOG.Info("目标实例导入完成",
+		zap.Uint("instanceId", migrateCtx.Instance.ID),
+		zap.String("privateIP", migrateCtx.NewPrivateIP))
+	return nil
+}
+
+// migrateTask_Switch 阶段6: 在单个事务中转移资源占用、切换实例归属并删除源端口记录
+func (s *TaskService) migrateTask_Switch(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) error {
+	s.updateTaskProgress(task.ID, 85, "正在切换实例归属...")
+
+	instance := migrateCtx.Instance
+	target := migrateCtx.TargetProvider
+
+	sshPort := 22
+	for _, port := range migrateCtx.NewPortMappings {
+		if port.IsSSH {
+			sshPort = port.HostPort
+			break
+		}
+	}
+
+	err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		quotaService := resources.NewQuotaService()
+		if err := quotaService.TransferInstanceInTx(tx, &instance, target.ID); err != nil {
+			return err
+		}
+
+		// 准备阶段已确保实例不涉及地址池分配的独立IP（见 ipam.ValidateMigration），公网地址使用目标节点地址
+		updates := map[string]interface{}{
+			"provider_id":         target.ID,
+			"provider":            target.Name,
+			"status":              migrateCtx.OriginalStatus,
+			"public_ip":           target.Endpoint,
+			"private_ip":          migrateCtx.NewPrivateIP,
+			"ssh_port":            sshPort,
+			"pmacct_interface_v4": "",
+			"pmacct_interface_v6": "",
+		}
+		// 未手动设置到期时间的实例跟随新节点的到期时间，并解除因源节点冻结带来的冻结
+		if !instance.IsManualExpiry {
+			updates["expires_at"] = target.ExpiresAt
+		}
+		if instance.IsFrozen && instance.FrozenReason == "node_frozen" && !target.IsFrozen {
+			updates["is_frozen"] = false
+			updates["frozen_at"] = nil
+			updates["frozen_reason"] = ""
+		}
+
+		result := tx.Model(&providerModel.Instance{}).
+			Where("id = ? AND provider_id = ? AND status = ?", instance.ID, migrateCtx.SourceProvider.ID, "migrating").
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("更新实例归属失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("实例状态已变化，无法完成迁移")
+		}
+
+		portMappingService := resources.PortMappingService{}
+		if err := portMappingService.DeleteInstanceProviderPortMappingsInTx(tx, instance.ID, migrateCtx.SourceProvider.ID); err != nil {
+			return fmt.Errorf("删除源端口映射失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	migrateCtx.Switched = true
+	global.APP_LOG.Info("实例归属已切换",
+		zap.Uint("instanceId", instance.ID),
+		zap.Uint("sourceProviderId", migrateCtx.SourceProvider.ID),
+		zap.Uint("targetProviderId", target.ID))
+	return nil
+}
+
+// migrateTask_CleanupSource 阶段7: 清理源节点上的快照和实例
+func (s *TaskService) migrateTask_CleanupSource(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) {
+	s.updateTaskProgress(task.ID, 90, "正在删除源实例...")
+
+	// 快照保存在源节点本地，无法随实例迁移
+	s.cleanupInstanceSnapshots(ctx, &migrateCtx.Instance, migrateCtx.SourceProvider.ID)
+
+	// 源节点上的防火墙规则不会随实例删除（如LXD网络ACL、Docker的iptables链）
+	if hasSecurityGroup(migrateCtx.Instance.ID) {
+		if err := removeInstanceFirewall(ctx, migrateCtx.SourceProvider.ID, migrateCtx.Instance.Name); err != nil {
+			global.APP_LOG.Warn("移除源实例防火墙失败",
+				zap.Uint("instanceId", migrateCtx.Instance.ID),
+				zap.Error(err))
+		}
+	}
+
+	providerApiService := &provider2.ProviderApiService{}
+	if err := providerApiService.DeleteInstanceByProviderID(ctx, migrateCtx.SourceProvider.ID, migrateCtx.Instance.Name); err != nil {
+		global.APP_LOG.Warn("删除源实例失败，请手动清理",
+			zap.Uint("instanceId", migrateCtx.Instance.ID),
+			zap.String("instanceName", migrateCtx.Instance.Name),
+			zap.String("sourceProvider", migrateCtx.SourceProvider.Name),
+			zap.Error(err))
+	}
+}
+
+// migrateTask_FinalizeTarget 阶段8: 在目标节点上配置端口映射并重新附加流量监控
+// 创建目标实例时数据库记录仍属于源Provider，Provider层按名称查找不到实例，需要在切换后补充配置
+func (s *TaskService) migrateTask_FinalizeTarget(ctx context.Context, task *adminModel.Task, migrateCtx *MigrateTaskContext) {
+	s.updateTaskProgress(task.ID, 95, "正在配置端口映射和流量监控...")
+
+	target := migrateCtx.TargetProvider
+	if target.Type != "docker" && migrateCtx.NewPrivateIP != "" {
+		providerApiService := &provider2.ProviderApiService{}
+		prov, _, err := providerApiService.GetProviderByID(target.ID)
+		if err != nil {
+			global.APP_LOG.Warn("获取目标Provider失败，跳过端口映射配置", zap.Error(err))
+		} else if portProv, ok := prov.(interface {
+			SetupPortMappingWithIP(ctx context.Context, instanceName string, hostPort, guestPort int, protocol, method, instanceIP string) error
+		}); ok {
+			for _, port := range migrateCtx.NewPortMappings {
+				if err := portProv.SetupPortMappingWithIP(ctx, migrateCtx.Instance.Name, port.HostPort, port.GuestPort,
+					port.Protocol, target.IPv4PortMappingMethod, migrateCtx.NewPrivateIP); err != nil {
+					global.APP_LOG.Warn("配置目标端口映射失败",
+						zap.Int("hostPort", port.HostPort),
+						zap.Int("guestPort", port.GuestPort),
+						zap.Error(err))
+				}
+			}
+		}
+	}
+
+	// 实例记录已切换到目标Provider，按原安全组在目标节点上下发规则
+	s.reapplyFirewallAfterMove(ctx, migrateCtx.Instance.ID)
+
+	// 域名绑定转移到目标节点的反向代理，并从源节点移除
+	if domain.HasInstanceDomains(migrateCtx.Instance.ID) {
+		domain.NewService().MoveToProvider(migrateCtx.Instance.ID, migrateCtx.SourceProvider.ID, target.ID)
+	}
+
+	if err := traffic_monitor.GetManager().AttachMonitor(ctx, migrateCtx.Instance.ID); err != nil {
+		global.APP_LOG.Warn("附加目标实例流量监控失败", zap.Error(err))
+	}
+
+	if migrateCtx.OriginalStatus != "running" {
+		providerApiService := &provider2.ProviderApiService{}
+		if prov, _, err := providerApiService.GetProviderByID(target.ID); err == nil {
+			if err := prov.StopInstance(ctx, migrateCtx.Instance.Name); err != nil {
+				global.APP_LOG.Warn("停止目标实例失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// migrateTask_Rollback 切换前失败时清理目标节点并恢复源实例
+func (s *TaskService) migrateTask_Rollback(taskReq *adminModel.MigrateInstanceTaskRequest, migrateCtx *MigrateTaskContext) {
+	rollbackCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if migrateCtx.TargetCreated {
+		providerApiService := &provider2.ProviderApiService{}
+		if err := providerApiService.DeleteInstanceByProviderID(rollbackCtx, taskReq.TargetProviderID, migrateCtx.Instance.Name); err != nil {
+			global.APP_LOG.Warn("回滚删除目标实例失败，请手动清理",
+				zap.Uint("targetProviderId", taskReq.TargetProviderID),
+				zap.String("instanceName", migrateCtx.Instance.Name),
+				zap.Error(err))
+		}
+	}
+
+	if err := global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		portMappingService := resources.PortMappingService{}
+		return portMappingService.DeleteInstanceProviderPortMappingsInTx(tx, taskReq.InstanceID, taskReq.TargetProviderID)
+	}); err != nil {
+		global.APP_LOG.Warn("回滚删除目标端口映射失败", zap.Error(err))
+	}
+
+	if migrateCtx.SourceRunning {
+		providerApiService := &provider2.ProviderApiService{}
+		if prov, _, err := providerApiService.GetProviderByID(taskReq.SourceProviderID); err == nil {
+			if err := prov.StartInstance(rollbackCtx, migrateCtx.Instance.Name); err != nil {
+				global.APP_LOG.Warn("回滚启动源实例失败", zap.Error(err))
+			}
+		}
+	}
+
+	// 分配目标端口时会改写ssh_port，这里一并还原
+	revertMigratingInstance(taskReq.InstanceID, taskReq.OriginalStatus, migrateCtx.Instance.SSHPort)
+
+	if err := traffic_monitor.GetManager().AttachMonitor(rollbackCtx, taskReq.InstanceID); err != nil {
+		global.APP_LOG.Warn("回滚恢复源实例流量监控失败", zap.Error(err))
+	}
+}
+
+// revertMigratingInstance 迁移失败或取消后还原实例状态
+func revertMigratingInstance(instanceID uint, originalStatus string, sshPort int) {
+	if originalStatus == "" {
+		originalStatus = "stopped"
+	}
+	updates := map[string]interface{}{"status": originalStatus}
+	if sshPort > 0 {
+		updates["ssh_port"] = sshPort
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", instanceID, "migrating").
+		Updates(updates).Error; err != nil {
+		global.APP_LOG.Error("恢复实例状态失败",
+			zap.Uint("instanceId", instanceID),
+			zap.String("status", originalStatus),
+			zap.Error(err))
+	}
+}
@@ -14,6 +14,9 @@ import (
 	userModel "oneclickvirt/model/user"
 	"oneclickvirt/provider/portmapping"
 	traffic_monitor "oneclickvirt/service/admin/traffic_monitor"
+	"oneclickvirt/service/domain"
+	"oneclickvirt/service/images"
+	"oneclickvirt/service/ipam"
 	provider2 "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/utils"
@@ -52,13 +55,14 @@ type ResetTaskContext struct {
 	NewInstanceID      uint
 	NewPassword        string
 	NewPrivateIP       string
+	UserData           string // 创建实例时用户提供的user-data，重置后重新应用
 }
 
 // executeResetTask 执行实例重置任务
 // 直接复用删除和创建逻辑，避免代码重复和资源管理错误
 func (s *TaskService) executeResetTask(ctx context.Context, task *adminModel.Task) error {
 	// 解析任务数据
-	var taskReq adminModel.InstanceOperationTaskRequest
+	var taskReq adminModel.ResetInstanceTaskRequest
 	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
 		return fmt.Errorf("解析任务数据失败: %v", err)
 	}
@@ -102,6 +106,16 @@ func (s *TaskService) executeResetTask(ctx context.Context, task *adminModel.Tas
 		global.APP_LOG.Warn("重置系统：端口映射恢复部分失败", zap.Error(err))
 	}
 
+	// 重新下发安全组规则（失败不影响重置流程）
+	s.reapplyFirewallAfterMove(ctx, resetCtx.NewInstanceID)
+
+	// 实例内网IP可能变化，重新下发域名反向代理配置
+	if domain.HasInstanceDomains(resetCtx.NewInstanceID) {
+		if err := domain.NewService().SyncProvider(resetCtx.Instance.ProviderID); err != nil {
+			global.APP_LOG.Warn("重置系统：同步域名反向代理配置失败", zap.Error(err))
+		}
+	}
+
 	// 阶段8: 重新初始化监控
 	if err := s.resetTask_ReinitializeMonitoring(ctx, task, &resetCtx); err != nil {
 		// 监控初始化失败不影响重置流程
@@ -121,17 +135,14 @@ func (s *TaskService) executeResetTask(ctx context.Context, task *adminModel.Tas
 }
 
 // resetTask_Prepare 阶段1: 准备阶段 - 查询必要信息
-func (s *TaskService) resetTask_Prepare(ctx context.Context, task *adminModel.Task, taskReq *adminModel.InstanceOperationTaskRequest, resetCtx *ResetTaskContext) error {
+func (s *TaskService) resetTask_Prepare(ctx context.Context, task *adminModel.Task, taskReq *adminModel.ResetInstanceTaskRequest, resetCtx *ResetTaskContext) error {
 	s.updateTaskProgress(task.ID, 5, "正在准备重置...")
 
-	// 解析taskData获取originalStatus（实例重置前的原始状态）
-	var taskData map[string]interface{}
-	if err := json.Unmarshal([]byte(task.TaskData), &taskData); err == nil {
-		if originalStatus, ok := taskData["originalStatus"].(string); ok {
-			resetCtx.OriginalStatus = originalStatus
-			global.APP_LOG.Info("从任务数据中解析到原始状态",
-				zap.String("originalStatus", originalStatus))
-		}
+	// originalStatus 为实例重置前的原始状态
+	if taskReq.OriginalStatus != "" {
+		resetCtx.OriginalStatus = taskReq.OriginalStatus
+		global.APP_LOG.Info("从任务数据中解析到原始状态",
+			zap.String("originalStatus", taskReq.OriginalStatus))
 	}
 
 	// 使用单个短事务查询所有需要的数据
@@ -154,8 +165,15 @@ func (s *TaskService) resetTask_Prepare(ctx context.Context, task *adminModel.Ta
 			return fmt.Errorf("获取Provider配置失败: %v", err)
 		}
 
-		// 3. 查询系统镜像
-		if err := global.APP_DB.Where("name = ? AND provider_type = ? AND instance_type = ? AND architecture = ?",
+		// 3. 查询系统镜像：指定了目标镜像时重装为该镜像，否则使用原镜像
+		if taskReq.ImageId > 0 {
+			imageService := &images.ImageService{}
+			systemImage, err := imageService.ValidateReinstallImage(taskReq.ImageId, &resetCtx.Instance, &resetCtx.Provider)
+			if err != nil {
+				return err
+			}
+			resetCtx.SystemImage = *systemImage
+		} else if err := global.APP_DB.Where("name = ? AND provider_type = ? AND instance_type = ? AND architecture = ?",
 			resetCtx.Instance.Image, resetCtx.Provider.Type, resetCtx.Instance.InstanceType, resetCtx.Provider.Architecture).
 			First(&resetCtx.SystemImage).Error; err != nil {
 			return fmt.Errorf("获取系统镜像信息失败: %v", err)
@@ -187,12 +205,14 @@ func (s *TaskService) resetTask_Prepare(ctx context.Context, task *adminModel.Ta
 	resetCtx.OriginalUserID = resetCtx.Instance.UserID
 	resetCtx.OriginalExpiresAt = resetCtx.Instance.ExpiresAt
 	resetCtx.OriginalMaxTraffic = uint64(resetCtx.Instance.MaxTraffic)
+	resetCtx.UserData = resetCtx.Instance.UserData
 
 	global.APP_LOG.Info("准备阶段完成",
 		zap.Uint("taskId", task.ID),
 		zap.Uint("instanceId", resetCtx.OldInstanceID),
 		zap.String("instanceName", resetCtx.OldInstanceName),
-		zap.Int("portMappings", len(resetCtx.OldPortMappings)))
+		zap.Int("portMappings", len(resetCtx.OldPortMappings)),
+		zap.Bool("hasUserData", resetCtx.UserData != ""))
 
 	return nil
 }
@@ -316,6 +336,12 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 		return fmt.Errorf("获取用户信息失败: %v", err)
 	}
 
+	// 重装为其他镜像时系统类型随镜像变化，镜像未填写时沿用原实例
+	osType := resetCtx.SystemImage.OSType
+	if osType == "" {
+		osType = resetCtx.Instance.OSType
+	}
+
 	// 在事务中创建新实例记录并分配配额
 	err := s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
 		// 创建新实例记录
@@ -323,7 +349,7 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 			Name:         resetCtx.OldInstanceName,
 			Provider:     resetCtx.Provider.Name,
 			ProviderID:   resetCtx.Provider.ID,
-			Image:        resetCtx.Instance.Image,
+			Image:        resetCtx.SystemImage.Name,
 			InstanceType: resetCtx.Instance.InstanceType,
 			CPU:          resetCtx.Instance.CPU,
 			Memory:       resetCtx.Instance.Memory,
@@ -331,10 +357,11 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 			Bandwidth:    resetCtx.Instance.Bandwidth,
 			UserID:       resetCtx.OriginalUserID,
 			Status:       "creating",
-			OSType:       resetCtx.Instance.OSType,
+			OSType:       osType,
 			ExpiresAt:    resetCtx.OriginalExpiresAt,
 			PublicIP:     resetCtx.Provider.Endpoint,
 			MaxTraffic:   int64(resetCtx.OriginalMaxTraffic),
+			UserData:     resetCtx.UserData,
 		}
 
 		if err := tx.Create(&newInstance).Error; err != nil {
@@ -363,6 +390,21 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 			return fmt.Errorf("分配Provider资源失败: %v", err)
 		}
 
+		// 重置保留原有的独立IP地址，转移到新实例
+		if err := ipam.NewService().TransferInTx(tx, resetCtx.OldInstanceID, resetCtx.NewInstanceID); err != nil {
+			return err
+		}
+
+		// 安全组绑定转移到新实例，规则在端口映射恢复后重新下发
+		if err := transferSecurityGroupInTx(tx, resetCtx.OldInstanceID, resetCtx.NewInstanceID); err != nil {
+			return err
+		}
+
+		// 域名绑定转移到新实例
+		if err := domain.TransferInTx(tx, resetCtx.OldInstanceID, resetCtx.NewInstanceID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -380,12 +422,13 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 	createReq := provider2.CreateInstanceRequest{
 		InstanceConfig: providerModel.ProviderInstanceConfig{
 			Name:         resetCtx.OldInstanceName,
-			Image:        resetCtx.Instance.Image,
+			Image:        resetCtx.SystemImage.Name,
 			InstanceType: resetCtx.Instance.InstanceType,
 			CPU:          fmt.Sprintf("%d", resetCtx.Instance.CPU),
 			Memory:       fmt.Sprintf("%dm", resetCtx.Instance.Memory),
 			Disk:         fmt.Sprintf("%dm", resetCtx.Instance.Disk),
 			Env:          map[string]string{"RESET_OPERATION": "true"},
+			UserData:     resetCtx.UserData,
 			Metadata: map[string]string{
 				"user_level":               fmt.Sprintf("%d", user.Level),
 				"bandwidth_spec":           fmt.Sprintf("%d", resetCtx.Instance.Bandwidth),
@@ -407,6 +450,13 @@ func (s *TaskService) resetTask_CreateNewInstance(ctx context.Context, task *adm
 		SystemImageID: resetCtx.SystemImage.ID,
 	}
 
+	// 传递保留的独立IP，新实例使用与原实例相同的地址
+	if allocations, err := ipam.NewService().GetInstanceAllocations(resetCtx.NewInstanceID); err != nil {
+		global.APP_LOG.Warn("获取实例独立IP失败", zap.Uint("instanceId", resetCtx.NewInstanceID), zap.Error(err))
+	} else {
+		ipam.ApplyMetadata(createReq.InstanceConfig.Metadata, allocations)
+	}
+
 	// Docker端口映射特殊处理
 	if resetCtx.Provider.Type == "docker" && len(resetCtx.OldPortMappings) > 0 {
 		var ports []string
@@ -523,6 +573,10 @@ func (s *TaskService) resetTask_UpdateInstanceInfo(ctx context.Context, task *ad
 			updates["private_ip"] = resetCtx.NewPrivateIP
 		}
 
+		if allocations, err := ipam.NewService().GetInstanceAllocations(resetCtx.NewInstanceID); err == nil {
+			ipam.ApplyInstanceUpdates(updates, allocations)
+		}
+
 		if err := tx.Model(&providerModel.Instance{}).Where("id = ?", resetCtx.NewInstanceID).
 			Updates(updates).Error; err != nil {
 			return fmt.Errorf("更新实例信息失败: %v", err)
@@ -0,0 +1,146 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	provider2 "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// executeResizeInstanceTask 执行调整实例规格任务：Provider调整规格 → 同一事务内更新实例规格、用户配额和节点资源占用
+func (s *TaskService) executeResizeInstanceTask(ctx context.Context, task *adminModel.Task) error {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.ResizeInstanceTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	err := s.runResize(ctx, task, &taskReq)
+	if err != nil {
+		global.APP_LOG.Error("调整实例规格失败",
+			zap.Uint("taskId", task.ID),
+			zap.Uint("instanceId", taskReq.InstanceID),
+			zap.Error(err))
+		revertResizingInstance(taskReq.InstanceID, taskReq.OriginalStatus)
+	}
+	return err
+}
+
+// runResize 调整规格任务的具体流程
+func (s *TaskService) runResize(ctx context.Context, task *adminModel.Task, taskReq *adminModel.ResizeInstanceTaskRequest) error {
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, taskReq.InstanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("实例不存在")
+		}
+		return fmt.Errorf("获取实例信息失败: %v", err)
+	}
+	if instance.Status != "resizing" {
+		return fmt.Errorf("实例状态为 %s，无法调整规格", instance.Status)
+	}
+
+	s.updateTaskProgress(task.ID, 15, "正在连接Provider...")
+
+	providerApiService := &provider2.ProviderApiService{}
+	prov, _, err := providerApiService.GetProviderByID(instance.ProviderID)
+	if err != nil {
+		return fmt.Errorf("获取Provider失败: %v", err)
+	}
+	resizeProvider, ok := prov.(provider.ResizeProvider)
+	if !ok {
+		return fmt.Errorf("Provider类型 %s 不支持调整规格", prov.GetType())
+	}
+
+	// 只下发发生变化的规格项
+	spec := provider.ResizeSpec{}
+	if taskReq.NewCPU != instance.CPU {
+		spec.CPU = taskReq.NewCPU
+	}
+	if taskReq.NewMemory != instance.Memory {
+		spec.MemoryMB = taskReq.NewMemory
+	}
+	if taskReq.NewDisk > instance.Disk {
+		spec.DiskMB = taskReq.NewDisk
+	}
+
+	s.updateTaskProgress(task.ID, 40, "正在调整实例规格...")
+
+	if err := resizeProvider.ResizeInstance(ctx, instance.Name, spec); err != nil {
+		return fmt.Errorf("调整实例规格失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 80, "正在更新实例规格和配额...")
+
+	err = s.dbService.ExecuteTransaction(ctx, func(tx *gorm.DB) error {
+		quotaService := resources.NewQuotaService()
+		if err := quotaService.ApplyInstanceResizeInTx(tx, &instance, taskReq.NewCPU, taskReq.NewMemory, taskReq.NewDisk); err != nil {
+			return err
+		}
+
+		result := tx.Model(&providerModel.Instance{}).
+			Where("id = ? AND status = ?", instance.ID, "resizing").
+			Updates(map[string]interface{}{
+				"cpu":    taskReq.NewCPU,
+				"memory": taskReq.NewMemory,
+				"disk":   taskReq.NewDisk,
+				"status": taskReq.OriginalStatus,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("更新实例规格失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("实例状态已变化，无法完成规格调整")
+		}
+		return nil
+	})
+	if err != nil {
+		// Provider上的规格已生效，数据库记录仍为旧规格，需管理员核对
+		global.APP_LOG.Error("实例规格已在Provider上调整，但更新数据库失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Int("cpu", taskReq.NewCPU),
+			zap.Int64("memory", taskReq.NewMemory),
+			zap.Int64("disk", taskReq.NewDisk),
+			zap.Error(err))
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 100, "规格调整完成")
+
+	global.APP_LOG.Info("实例规格调整成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("instanceId", instance.ID),
+		zap.String("instanceName", instance.Name),
+		zap.Int("oldCpu", instance.CPU),
+		zap.Int("newCpu", taskReq.NewCPU),
+		zap.Int64("oldMemory", instance.Memory),
+		zap.Int64("newMemory", taskReq.NewMemory),
+		zap.Int64("oldDisk", instance.Disk),
+		zap.Int64("newDisk", taskReq.NewDisk))
+	return nil
+}
+
+// revertResizingInstance 将调整规格中的实例恢复为原状态
+func revertResizingInstance(instanceID uint, originalStatus string) {
+	if originalStatus == "" {
+		originalStatus = "stopped"
+	}
+	if err := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", instanceID, "resizing").
+		Update("status", originalStatus).Error; err != nil {
+		global.APP_LOG.Error("恢复实例状态失败",
+			zap.Uint("instanceId", instanceID),
+			zap.String("status", originalStatus),
+			zap.Error(err))
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
+	systemModel "oneclickvirt/model/system"
 
 	"go.uber.org/zap"
 )
@@ -85,12 +86,8 @@ func GetTaskService() *TaskService {
 		// 初始化统一任务状态管理器
 		InitTaskStateManager(taskService)
 
-		// 只有在数据库已初始化时才清理running状态的任务
-		if isSystemInitialized() {
-			taskService.cleanupRunningTasksOnStartup()
-		} else {
-			global.APP_LOG.Debug("系统未初始化，跳过任务清理")
-		}
+		// running状态任务的清理由领导者根据执行实例的心跳统一执行（见CleanupInterruptedTasks），
+		// 避免多实例部署时新启动的实例把其他实例正在执行的任务标记为失败
 
 		// 启动context自动清理goroutine
 		go taskService.cleanupStaleContexts()
@@ -121,30 +118,62 @@ func isSystemInitialized() bool {
 	return global.APP_DB.Migrator().HasTable("users")
 }
 
-// cleanupRunningTasksOnStartup 服务启动时清理running状态的任务
-func (s *TaskService) cleanupRunningTasksOnStartup() {
+// CleanupInterruptedTasks 清理被中断的running状态任务
+// 任务可能在任意实例上执行（HTTP接口直接启动的任务在接收请求的实例上运行），每个任务记录执行实例的标识，
+// 只有执行实例的心跳已过期（实例宕机、重启或已关闭）时才将任务标记为失败，不会影响其他实例上仍在执行的任务。
+// 由领导者在获得领导权时及之后定期调用
+func (s *TaskService) CleanupInterruptedTasks() {
 	// 再次检查数据库是否可用，防止在初始化过程中数据库状态发生变化
-	if global.APP_DB == nil {
-		global.APP_LOG.Warn("数据库连接不存在，无法清理运行中的任务")
+	if !isSystemInitialized() {
+		global.APP_LOG.Debug("系统未初始化，跳过任务清理")
 		return
 	}
 
-	// 将所有running状态的任务标记为failed
-	result := global.APP_DB.Model(&adminModel.Task{}).
+	now := time.Now()
+	aliveHolders := global.APP_DB.Model(&systemModel.InstanceHeartbeat{}).
+		Select("holder_id").
+		Where("expires_at >= ?", now)
+
+	// 未记录执行实例的任务由升级前的版本启动，无法判断归属，按中断处理
+	var runningIDs []uint
+	if err := global.APP_DB.Model(&adminModel.Task{}).
 		Where("status = ?", "running").
-		Updates(map[string]interface{}{
-			"status":        "failed",
-			"error_message": "服务重启，任务被中断",
-			"completed_at":  time.Now(),
-		})
-
-	if result.Error != nil {
-		global.APP_LOG.Error("清理运行中任务失败", zap.Error(result.Error))
-	} else if result.RowsAffected > 0 {
-		global.APP_LOG.Info("服务启动时清理了运行中的任务", zap.Int64("count", result.RowsAffected))
+		Where("holder_id = '' OR holder_id IS NULL OR holder_id NOT IN (?)", aliveHolders).
+		Pluck("id", &runningIDs).Error; err != nil {
+		global.APP_LOG.Error("查询运行中任务失败", zap.Error(err))
+		return
+	}
+
+	// 排除本进程中仍在执行的任务（例如本实例心跳短暂续约失败）
+	interruptedIDs := make([]uint, 0, len(runningIDs))
+	for _, id := range runningIDs {
+		if _, ok := s.contextManager.Get(id); !ok {
+			interruptedIDs = append(interruptedIDs, id)
+		}
+	}
+
+	if len(interruptedIDs) > 0 {
+		// 将被中断的任务标记为failed
+		result := global.APP_DB.Model(&adminModel.Task{}).
+			Where("id IN ? AND status = ?", interruptedIDs, "running").
+			Updates(map[string]interface{}{
+				"status":        "failed",
+				"error_message": "执行任务的实例已停止，任务被中断",
+				"completed_at":  now,
+			})
+
+		if result.Error != nil {
+			global.APP_LOG.Error("清理运行中任务失败", zap.Error(result.Error))
+		} else if result.RowsAffected > 0 {
+			global.APP_LOG.Info("清理了被中断的运行中任务", zap.Int64("count", result.RowsAffected))
+		}
 	}
 
-	// 内存计数器从空开始，不需要额外初始化
+	// 清理早已过期的实例心跳记录
+	if err := global.APP_DB.Where("expires_at < ?", now.Add(-24*time.Hour)).
+		Delete(&systemModel.InstanceHeartbeat{}).Error; err != nil {
+		global.APP_LOG.Warn("清理过期实例心跳失败", zap.Error(err))
+	}
 }
 
 // cleanupStaleContexts 定期清理陈旧的任务context，防止内存泄漏
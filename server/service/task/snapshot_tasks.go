@@ -0,0 +1,242 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/provider"
+	provider2 "oneclickvirt/service/provider"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// snapshotTaskContext 快照任务执行所需的上下文
+type snapshotTaskContext struct {
+	Snapshot providerModel.InstanceSnapshot
+	Instance providerModel.Instance
+	Provider provider.SnapshotProvider
+}
+
+// prepareSnapshotTask 解析快照任务数据并获取支持快照的Provider
+func (s *TaskService) prepareSnapshotTask(task *adminModel.Task) (*snapshotTaskContext, error) {
+	s.updateTaskProgress(task.ID, 5, "正在解析任务数据...")
+
+	var taskReq adminModel.SnapshotTaskRequest
+	if err := json.Unmarshal([]byte(task.TaskData), &taskReq); err != nil {
+		return nil, fmt.Errorf("解析任务数据失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 15, "正在获取快照信息...")
+
+	var snapshot providerModel.InstanceSnapshot
+	if err := global.APP_DB.First(&snapshot, taskReq.SnapshotID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("快照不存在")
+		}
+		return nil, fmt.Errorf("获取快照信息失败: %v", err)
+	}
+
+	var instance providerModel.Instance
+	if err := global.APP_DB.First(&instance, snapshot.InstanceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("实例不存在")
+		}
+		return nil, fmt.Errorf("获取实例信息失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 25, "正在连接Provider...")
+
+	providerApiService := &provider2.ProviderApiService{}
+	prov, _, err := providerApiService.GetProviderByID(instance.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("获取Provider失败: %v", err)
+	}
+
+	snapshotProvider, ok := prov.(provider.SnapshotProvider)
+	if !ok {
+		return nil, fmt.Errorf("Provider类型 %s 不支持快照", prov.GetType())
+	}
+
+	return &snapshotTaskContext{
+		Snapshot: snapshot,
+		Instance: instance,
+		Provider: snapshotProvider,
+	}, nil
+}
+
+// markSnapshotStatus 更新快照状态
+func markSnapshotStatus(snapshotID uint, status string, errorMessage string) {
+	updates := map[string]interface{}{
+		"status":        status,
+		"error_message": errorMessage,
+	}
+	if err := global.APP_DB.Model(&providerModel.InstanceSnapshot{}).Where("id = ?", snapshotID).Updates(updates).Error; err != nil {
+		global.APP_LOG.Error("更新快照状态失败",
+			zap.Uint("snapshotId", snapshotID),
+			zap.String("status", status),
+			zap.Error(err))
+	}
+}
+
+// snapshotTaskIDFromData 从任务数据中解析快照ID，解析失败返回0
+func snapshotTaskIDFromData(taskData string) uint {
+	var taskReq adminModel.SnapshotTaskRequest
+	if err := json.Unmarshal([]byte(taskData), &taskReq); err != nil {
+		return 0
+	}
+	return taskReq.SnapshotID
+}
+
+// executeCreateSnapshotTask 执行创建快照任务
+func (s *TaskService) executeCreateSnapshotTask(ctx context.Context, task *adminModel.Task) error {
+	snapshotCtx, err := s.prepareSnapshotTask(task)
+	if err != nil {
+		if snapshotID := snapshotTaskIDFromData(task.TaskData); snapshotID > 0 {
+			markSnapshotStatus(snapshotID, providerModel.SnapshotStatusFailed, err.Error())
+		}
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 50, "正在创建快照...")
+
+	if err := snapshotCtx.Provider.CreateSnapshot(ctx, snapshotCtx.Instance.Name, snapshotCtx.Snapshot.Name); err != nil {
+		global.APP_LOG.Error("Provider创建快照失败",
+			zap.Uint("taskId", task.ID),
+			zap.String("instanceName", snapshotCtx.Instance.Name),
+			zap.String("snapshot", snapshotCtx.Snapshot.Name),
+			zap.Error(err))
+		markSnapshotStatus(snapshotCtx.Snapshot.ID, providerModel.SnapshotStatusFailed, err.Error())
+		return fmt.Errorf("创建快照失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 90, "正在更新快照状态...")
+	markSnapshotStatus(snapshotCtx.Snapshot.ID, providerModel.SnapshotStatusAvailable, "")
+
+	global.APP_LOG.Info("快照创建成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("snapshotId", snapshotCtx.Snapshot.ID),
+		zap.String("instanceName", snapshotCtx.Instance.Name),
+		zap.String("snapshot", snapshotCtx.Snapshot.Name))
+	return nil
+}
+
+// executeRestoreSnapshotTask 执行恢复快照任务
+func (s *TaskService) executeRestoreSnapshotTask(ctx context.Context, task *adminModel.Task) error {
+	snapshotCtx, err := s.prepareSnapshotTask(task)
+	if err != nil {
+		if snapshotID := snapshotTaskIDFromData(task.TaskData); snapshotID > 0 {
+			markSnapshotStatus(snapshotID, providerModel.SnapshotStatusAvailable, err.Error())
+		}
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 50, "正在恢复快照...")
+
+	if err := snapshotCtx.Provider.RestoreSnapshot(ctx, snapshotCtx.Instance.Name, snapshotCtx.Snapshot.Name); err != nil {
+		global.APP_LOG.Error("Provider恢复快照失败",
+			zap.Uint("taskId", task.ID),
+			zap.String("instanceName", snapshotCtx.Instance.Name),
+			zap.String("snapshot", snapshotCtx.Snapshot.Name),
+			zap.Error(err))
+		// 恢复失败不影响快照本身的可用性
+		markSnapshotStatus(snapshotCtx.Snapshot.ID, providerModel.SnapshotStatusAvailable, err.Error())
+		return fmt.Errorf("恢复快照失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 80, "正在同步实例信息...")
+
+	// 部分Provider（如Docker）恢复时会重建实例，内网IP可能变化
+	if prov, ok := snapshotCtx.Provider.(provider.Provider); ok {
+		if providerInstance, err := prov.GetInstance(ctx, snapshotCtx.Instance.Name); err == nil && providerInstance.PrivateIP != "" &&
+			providerInstance.PrivateIP != snapshotCtx.Instance.PrivateIP {
+			if err := global.APP_DB.Model(&snapshotCtx.Instance).Update("private_ip", providerInstance.PrivateIP).Error; err != nil {
+				global.APP_LOG.Warn("更新实例内网IP失败",
+					zap.Uint("instanceId", snapshotCtx.Instance.ID),
+					zap.Error(err))
+			}
+		}
+	}
+
+	markSnapshotStatus(snapshotCtx.Snapshot.ID, providerModel.SnapshotStatusAvailable, "")
+
+	global.APP_LOG.Info("快照恢复成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("snapshotId", snapshotCtx.Snapshot.ID),
+		zap.String("instanceName", snapshotCtx.Instance.Name),
+		zap.String("snapshot", snapshotCtx.Snapshot.Name))
+	return nil
+}
+
+// executeDeleteSnapshotTask 执行删除快照任务
+func (s *TaskService) executeDeleteSnapshotTask(ctx context.Context, task *adminModel.Task) error {
+	snapshotCtx, err := s.prepareSnapshotTask(task)
+	if err != nil {
+		if snapshotID := snapshotTaskIDFromData(task.TaskData); snapshotID > 0 {
+			markSnapshotStatus(snapshotID, providerModel.SnapshotStatusAvailable, err.Error())
+		}
+		return err
+	}
+
+	s.updateTaskProgress(task.ID, 50, "正在删除快照...")
+
+	if err := snapshotCtx.Provider.DeleteSnapshot(ctx, snapshotCtx.Instance.Name, snapshotCtx.Snapshot.Name); err != nil {
+		global.APP_LOG.Error("Provider删除快照失败",
+			zap.Uint("taskId", task.ID),
+			zap.String("instanceName", snapshotCtx.Instance.Name),
+			zap.String("snapshot", snapshotCtx.Snapshot.Name),
+			zap.Error(err))
+		markSnapshotStatus(snapshotCtx.Snapshot.ID, providerModel.SnapshotStatusAvailable, err.Error())
+		return fmt.Errorf("删除快照失败: %v", err)
+	}
+
+	s.updateTaskProgress(task.ID, 90, "正在清理快照记录...")
+
+	if err := global.APP_DB.Delete(&snapshotCtx.Snapshot).Error; err != nil {
+		return fmt.Errorf("删除快照记录失败: %v", err)
+	}
+
+	global.APP_LOG.Info("快照删除成功",
+		zap.Uint("taskId", task.ID),
+		zap.Uint("snapshotId", snapshotCtx.Snapshot.ID),
+		zap.String("instanceName", snapshotCtx.Instance.Name),
+		zap.String("snapshot", snapshotCtx.Snapshot.Name))
+	return nil
+}
+
+// cleanupInstanceSnapshots 实例删除后清理快照
+// LXD/Incus/Proxmox删除实例时快照会随之删除，Docker的快照镜像需要单独清理
+func (s *TaskService) cleanupInstanceSnapshots(ctx context.Context, instance *providerModel.Instance, providerID uint) {
+	var snapshots []providerModel.InstanceSnapshot
+	if err := global.APP_DB.Where("instance_id = ?", instance.ID).Find(&snapshots).Error; err != nil || len(snapshots) == 0 {
+		return
+	}
+
+	providerApiService := &provider2.ProviderApiService{}
+	prov, _, err := providerApiService.GetProviderByID(providerID)
+	if err != nil {
+		global.APP_LOG.Warn("获取Provider失败，跳过快照镜像清理",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+	} else if snapshotProvider, ok := prov.(provider.SnapshotProvider); ok && prov.GetType() == "docker" {
+		for _, snapshot := range snapshots {
+			if err := snapshotProvider.DeleteSnapshot(ctx, instance.Name, snapshot.Name); err != nil {
+				global.APP_LOG.Warn("清理实例快照镜像失败",
+					zap.Uint("instanceId", instance.ID),
+					zap.String("snapshot", snapshot.Name),
+					zap.Error(err))
+			}
+		}
+	}
+
+	if err := global.APP_DB.Where("instance_id = ?", instance.ID).Delete(&providerModel.InstanceSnapshot{}).Error; err != nil {
+		global.APP_LOG.Warn("删除实例快照记录失败",
+			zap.Uint("instanceId", instance.ID),
+			zap.Error(err))
+	}
+}
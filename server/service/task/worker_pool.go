@@ -8,6 +8,7 @@ import (
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
+	"oneclickvirt/service/leader"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -119,6 +120,7 @@ func (pool *ProviderWorkerPool) executeTask(taskReq TaskRequest) {
 			Updates(map[string]interface{}{
 				"status":     "running",
 				"started_at": time.Now(),
+				"holder_id":  leader.LocalHolderID(),
 			})
 
 		if result.Error != nil {
@@ -6,8 +6,10 @@ import (
 
 	"oneclickvirt/global"
 	monitoringModel "oneclickvirt/model/monitoring"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // AggregationService 流量聚合服务 - 定期将pmacct原始数据聚合到缓存表
@@ -122,7 +124,7 @@ func (s *AggregationService) AggregateMonthlyTraffic(year, month int) error {
 
 // saveToCacheWithInfo 保存流量统计到缓存表（使用预加载的实例信息）
 func (s *AggregationService) saveToCacheWithInfo(instanceID, providerID, userID uint, year, month int, stats *TrafficStats) error {
-	// 使用UPSERT逻辑（ON DUPLICATE KEY UPDATE）
+	// 使用UPSERT逻辑
 	record := monitoringModel.InstanceTrafficHistory{
 		InstanceID: instanceID,
 		ProviderID: providerID,
@@ -138,30 +140,28 @@ func (s *AggregationService) saveToCacheWithInfo(instanceID, providerID, userID
 	}
 
 	// 使用原生SQL实现真正的 UPSERT，避免并发问题和重复数据错误
-	// 兼容 MySQL 5.x/9.x 和 MariaDB 的 ON DUPLICATE KEY UPDATE 语法
-	sql := `
-		INSERT INTO instance_traffic_histories 
-			(instance_id, provider_id, user_id, traffic_in, traffic_out, total_used, 
-			 year, month, day, hour, record_time, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
-		ON DUPLICATE KEY UPDATE
-			provider_id = VALUES(provider_id),
-			user_id = VALUES(user_id),
-			traffic_in = VALUES(traffic_in),
-			traffic_out = VALUES(traffic_out),
-			total_used = VALUES(total_used),
-			record_time = VALUES(record_time),
-			updated_at = NOW()
-	`
-
-	return global.APP_DB.Exec(sql,
+	// 冲突子句按数据库方言生成，兼容 MySQL/MariaDB、PostgreSQL 和 SQLite
+	now := time.Now()
+	return global.APP_DB.Exec(instanceTrafficUpsertSQL(global.APP_DB),
 		record.InstanceID, record.ProviderID, record.UserID,
 		record.TrafficIn, record.TrafficOut, record.TotalUsed,
 		record.Year, record.Month, record.Day, record.Hour,
-		record.RecordTime,
+		record.RecordTime, now, now,
 	).Error
 }
 
+// instanceTrafficUpsertSQL 实例流量缓存的 UPSERT 语句，按 uk_instance_period 唯一索引去重
+func instanceTrafficUpsertSQL(db *gorm.DB) string {
+	return `
+		INSERT INTO instance_traffic_histories 
+			(instance_id, provider_id, user_id, traffic_in, traffic_out, total_used, 
+			 year, month, day, hour, record_time, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		` + utils.UpsertClause(db,
+		[]string{"instance_id", "year", "month", "day", "hour"},
+		"provider_id", "user_id", "traffic_in", "traffic_out", "total_used", "record_time", "updated_at")
+}
+
 // saveToCache 保存流量统计到缓存表（保留用于单独调用）
 func (s *AggregationService) saveToCache(instanceID uint, year, month int, stats *TrafficStats) error {
 	// 获取instance的provider_id和user_id
@@ -360,25 +360,11 @@ func (s *AggregationService) saveDailyCacheWithInfo(instanceID, providerID, user
 	totalUsedMB := int64(stats.ActualUsageMB)
 
 	// 使用原生SQL实现真正的 UPSERT（day!=0, hour=0表示按天缓存）
-	sql := `
-		INSERT INTO instance_traffic_histories 
-			(instance_id, provider_id, user_id, traffic_in, traffic_out, total_used, 
-			 year, month, day, hour, record_time, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
-		ON DUPLICATE KEY UPDATE
-			provider_id = VALUES(provider_id),
-			user_id = VALUES(user_id),
-			traffic_in = VALUES(traffic_in),
-			traffic_out = VALUES(traffic_out),
-			total_used = VALUES(total_used),
-			record_time = VALUES(record_time),
-			updated_at = NOW()
-	`
-
-	return global.APP_DB.Exec(sql,
+	now := time.Now()
+	return global.APP_DB.Exec(instanceTrafficUpsertSQL(global.APP_DB),
 		instanceID, providerID, userID,
 		trafficInMB, trafficOutMB, totalUsedMB,
-		year, month, day, 0, time.Now(),
+		year, month, day, 0, now, now, now,
 	).Error
 }
 
@@ -8,11 +8,15 @@ import (
 	monitoringModel "oneclickvirt/model/monitoring"
 	providerModel "oneclickvirt/model/provider"
 	"oneclickvirt/model/system"
+	"oneclickvirt/utils"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// trafficHistoryUpsertColumns Provider/用户流量历史汇总冲突时更新的列
+var trafficHistoryUpsertColumns = []string{"traffic_in", "traffic_out", "total_used", "instance_count", "record_time", "updated_at"}
+
 // HistoryService 流量历史记录服务
 type HistoryService struct{}
 
@@ -149,7 +153,7 @@ func (h *HistoryService) AggregateProviderTrafficHistory(providerID uint) error
 	hour := now.Hour()
 
 	// 聚合该Provider所有实例的当前小时流量
-	return global.APP_DB.Exec(`
+	return global.APP_DB.Exec(fmt.Sprintf(`
 		INSERT INTO provider_traffic_histories 
 			(provider_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 		SELECT 
@@ -162,20 +166,14 @@ func (h *HistoryService) AggregateProviderTrafficHistory(providerID uint) error
 			month,
 			day,
 			hour,
-			? as record_time,
-			? as created_at,
-			? as updated_at
+			%[1]s as record_time,
+			%[1]s as created_at,
+			%[1]s as updated_at
 		FROM instance_traffic_histories
 		WHERE provider_id = ? AND year = ? AND month = ? AND day = ? AND hour = ? AND deleted_at IS NULL
 		GROUP BY provider_id, year, month, day, hour
-		ON DUPLICATE KEY UPDATE
-			traffic_in = VALUES(traffic_in),
-			traffic_out = VALUES(traffic_out),
-			total_used = VALUES(total_used),
-			instance_count = VALUES(instance_count),
-			record_time = VALUES(record_time),
-			updated_at = VALUES(updated_at)
-	`, now, time.Now(), time.Now(), providerID, year, month, day, hour).Error
+		%[2]s
+	`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, time.Now(), time.Now(), providerID, year, month, day, hour).Error
 }
 
 // AggregateDailyProviderTraffic 聚合Provider每日流量
@@ -185,7 +183,7 @@ func (h *HistoryService) AggregateDailyProviderTraffic(providerID uint, date tim
 	day := date.Day()
 
 	// 从小时级数据聚合到日级
-	return global.APP_DB.Exec(`
+	return global.APP_DB.Exec(fmt.Sprintf(`
 		INSERT INTO provider_traffic_histories 
 			(provider_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 		SELECT 
@@ -198,20 +196,14 @@ func (h *HistoryService) AggregateDailyProviderTraffic(providerID uint, date tim
 			month,
 			day,
 			0 as hour,
-			? as record_time,
-			? as created_at,
-			? as updated_at
+			%[1]s as record_time,
+			%[1]s as created_at,
+			%[1]s as updated_at
 		FROM provider_traffic_histories
 		WHERE provider_id = ? AND year = ? AND month = ? AND day = ? AND hour > 0 AND deleted_at IS NULL
 		GROUP BY provider_id, year, month, day
-		ON DUPLICATE KEY UPDATE
-			traffic_in = VALUES(traffic_in),
-			traffic_out = VALUES(traffic_out),
-			total_used = VALUES(total_used),
-			instance_count = VALUES(instance_count),
-			record_time = VALUES(record_time),
-			updated_at = VALUES(updated_at)
-	`, date, time.Now(), time.Now(), providerID, year, month, day).Error
+		%[2]s
+	`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), date, time.Now(), time.Now(), providerID, year, month, day).Error
 }
 
 // AggregateUserTrafficHistory 聚合用户流量历史（小时级）
@@ -224,7 +216,7 @@ func (h *HistoryService) AggregateUserTrafficHistory(userID uint) error {
 	hour := now.Hour()
 
 	// 聚合该用户所有实例的当前小时流量
-	return global.APP_DB.Exec(`
+	return global.APP_DB.Exec(fmt.Sprintf(`
 		INSERT INTO user_traffic_histories 
 			(user_id, traffic_in, traffic_out, total_used, instance_count, year, month, day, hour, record_time, created_at, updated_at)
 		SELECT 
@@ -237,20 +229,14 @@ func (h *HistoryService) AggregateUserTrafficHistory(userID uint) error {
 			month,
 			day,
 			hour,
-			? as record_time,
-			? as created_at,
-			? as updated_at
+			%[1]s as record_time,
+			%[1]s as created_at,
+			%[1]s as updated_at
 		FROM instance_traffic_histories
 		WHERE user_id = ? AND year = ? AND month = ? AND day = ? AND hour = ? AND deleted_at IS NULL
 		GROUP BY user_id, year, month, day, hour
-		ON DUPLICATE KEY UPDATE
-			traffic_in = VALUES(traffic_in),
-			traffic_out = VALUES(traffic_out),
-			total_used = VALUES(total_used),
-			instance_count = VALUES(instance_count),
-			record_time = VALUES(record_time),
-			updated_at = VALUES(updated_at)
-	`, now, time.Now(), time.Now(), userID, year, month, day, hour).Error
+		%[2]s
+	`, utils.TimeParam(global.APP_DB), utils.UpsertClause(global.APP_DB, nil, trafficHistoryUpsertColumns...)), now, time.Now(), time.Now(), userID, year, month, day, hour).Error
 }
 
 // GetInstanceTrafficHistory 获取实例流量历史（用于图表展示）
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"oneclickvirt/global"
+	"oneclickvirt/utils"
 
 	"gorm.io/gorm"
 )
@@ -461,14 +462,14 @@ func (s *QueryService) GetInstanceTrafficHistory(instanceID uint, days int) ([]*
 
 	// 按天聚合查询，处理pmacct重启问题
 	var results []struct {
-		Date    time.Time
+		Date    historyDate
 		RxBytes int64
 		TxBytes int64
 	}
 
 	// 兼容 MySQL 5.x - 不使用 CTE (WITH AS) 和窗口函数
 	// MySQL 5.x 不支持 CTE，改用派生表（子查询）实现相同逻辑
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
 			date,
 			SUM(max_rx) as rx_bytes,
@@ -483,26 +484,26 @@ func (s *QueryService) GetInstanceTrafficHistory(instanceID uint, days int) ([]*
 			FROM (
 				-- 检测累积值重置点（使用相关子查询，兼容MySQL 5.x）
 				SELECT 
-					DATE(t1.timestamp) as date,
+					%[1]s as date,
 					t1.timestamp,
 					t1.rx_bytes,
 					t1.tx_bytes,
 					(SELECT COUNT(*)
 					 FROM pmacct_traffic_records t2
 					 WHERE t2.instance_id = ? 
-					   AND DATE(t2.timestamp) = DATE(t1.timestamp)
+					   AND %[2]s = %[1]s
 					   AND t2.timestamp <= t1.timestamp
 					   AND (
 						 (t2.rx_bytes < (SELECT COALESCE(MAX(t3.rx_bytes), 0)
 										 FROM pmacct_traffic_records t3
 										 WHERE t3.instance_id = ?
-										   AND DATE(t3.timestamp) = DATE(t1.timestamp)
+										   AND %[3]s = %[1]s
 										   AND t3.timestamp < t2.timestamp))
 						 OR
 						 (t2.tx_bytes < (SELECT COALESCE(MAX(t3.tx_bytes), 0)
 										 FROM pmacct_traffic_records t3
 										 WHERE t3.instance_id = ?
-										   AND DATE(t3.timestamp) = DATE(t1.timestamp)
+										   AND %[3]s = %[1]s
 										   AND t3.timestamp < t2.timestamp))
 					   )
 					) as segment_id
@@ -513,7 +514,7 @@ func (s *QueryService) GetInstanceTrafficHistory(instanceID uint, days int) ([]*
 		) AS daily_segment_max
 		GROUP BY date
 		ORDER BY date ASC
-	`
+	`, utils.DateExpr(global.APP_DB, "t1.timestamp"), utils.DateExpr(global.APP_DB, "t2.timestamp"), utils.DateExpr(global.APP_DB, "t3.timestamp"))
 
 	if err := global.APP_DB.Raw(query, instanceID, instanceID, instanceID, instanceID, startDate).Scan(&results).Error; err != nil {
 		return nil, fmt.Errorf("查询实例流量历史失败: %w", err)
@@ -524,7 +525,7 @@ func (s *QueryService) GetInstanceTrafficHistory(instanceID uint, days int) ([]*
 	for _, r := range results {
 		actualUsageMB := s.calculateActualUsage(r.RxBytes, r.TxBytes, config.TrafficCountMode, config.TrafficMultiplier)
 		history = append(history, &HistoryPoint{
-			Date:          r.Date,
+			Date:          r.Date.Time,
 			Year:          r.Date.Year(),
 			Month:         int(r.Date.Month()),
 			Day:           r.Date.Day(),
@@ -574,15 +575,15 @@ func (s *QueryService) GetUserTrafficHistory(userID uint, days int) ([]*HistoryP
 	// 从 pmacct_traffic_records 按天聚合查询（包含 instance_id 用于计算实际用量）
 	// 处理pmacct重启导致的累积值重置问题
 	var rawResults []struct {
-		Date       time.Time
+		Date       historyDate
 		InstanceID uint
 		RxBytes    int64
 		TxBytes    int64
 	}
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT 
-			DATE(t1.timestamp) as date,
+			%[1]s as date,
 			instance_id,
 			SUM(max_rx) as rx_bytes,
 			SUM(max_tx) as tx_bytes
@@ -610,13 +611,13 @@ func (s *QueryService) GetUserTrafficHistory(userID uint, days int) ([]*HistoryP
 								FROM pmacct_traffic_records 
 								WHERE instance_id = t2.instance_id 
 									AND timestamp < t2.timestamp
-									AND DATE(timestamp) = DATE(t2.timestamp)
+									AND %[1]s = %[2]s
 							)
 						WHERE t2.instance_id = t1.instance_id
 							AND t2.user_id = ?
 							AND t2.timestamp >= ?
 							AND t2.timestamp <= t1.timestamp
-							AND DATE(t2.timestamp) = DATE(t1.timestamp)
+							AND %[2]s = %[3]s
 							AND (
 								(t3.rx_bytes IS NOT NULL AND t2.rx_bytes < t3.rx_bytes)
 								OR
@@ -626,11 +627,11 @@ func (s *QueryService) GetUserTrafficHistory(userID uint, days int) ([]*HistoryP
 				FROM pmacct_traffic_records t1
 				WHERE t1.user_id = ? AND t1.timestamp >= ?
 			) AS segments
-			GROUP BY instance_id, DATE(timestamp), segment_id, timestamp
+			GROUP BY instance_id, %[1]s, segment_id, timestamp
 		) AS daily_segments
-		GROUP BY DATE(timestamp), instance_id
+		GROUP BY %[1]s, instance_id
 		ORDER BY date ASC, instance_id
-	`
+	`, utils.DateExpr(global.APP_DB, "timestamp"), utils.DateExpr(global.APP_DB, "t2.timestamp"), utils.DateExpr(global.APP_DB, "t1.timestamp"))
 
 	if err := global.APP_DB.Raw(query, userID, startDate, userID, startDate).Scan(&rawResults).Error; err != nil {
 		return nil, fmt.Errorf("查询用户流量历史失败: %w", err)
@@ -643,7 +644,7 @@ func (s *QueryService) GetUserTrafficHistory(userID uint, days int) ([]*HistoryP
 
 		if _, exists := dayMap[dateKey]; !exists {
 			dayMap[dateKey] = &HistoryPoint{
-				Date:          r.Date,
+				Date:          r.Date.Time,
 				Year:          r.Date.Year(),
 				Month:         int(r.Date.Month()),
 				Day:           r.Date.Day(),
@@ -680,6 +681,35 @@ func (s *QueryService) GetUserTrafficHistory(userID uint, days int) ([]*HistoryP
 	return history, nil
 }
 
+// historyDate 按天聚合查询返回的日期
+// SQLite 中日期表达式的结果是文本，MySQL 和 PostgreSQL 为日期类型
+type historyDate struct {
+	time.Time
+}
+
+// Scan 实现 sql.Scanner
+func (d *historyDate) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		d.Time = v
+		return nil
+	case []byte:
+		return d.parse(string(v))
+	case string:
+		return d.parse(v)
+	}
+	return fmt.Errorf("不支持的日期类型: %T", value)
+}
+
+func (d *historyDate) parse(s string) error {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return fmt.Errorf("解析日期失败: %v", err)
+	}
+	d.Time = t
+	return nil
+}
+
 // HistoryPoint 流量历史数据点
 type HistoryPoint struct {
 	Date          time.Time `json:"date"`
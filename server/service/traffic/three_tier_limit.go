@@ -8,7 +8,10 @@ import (
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	"oneclickvirt/model/provider"
+	"oneclickvirt/model/system"
 	"oneclickvirt/model/user"
+	"oneclickvirt/service/notify"
+	"oneclickvirt/service/webhook"
 
 	"go.uber.org/zap"
 )
@@ -208,9 +211,30 @@ func (s *ThreeTierLimitService) limitInstance(instanceID uint, reason string, me
 			zap.Error(err))
 	}
 
+	s.publishTrafficLimited(LimitLevelInstance, instanceID, []provider.Instance{instance}, message)
+
 	return true, nil
 }
 
+// publishTrafficLimited 发送实例流量受限的Webhook事件和用户通知
+func (s *ThreeTierLimitService) publishTrafficLimited(level TrafficLimitLevel, targetID uint, instances []provider.Instance, message string) {
+	instanceIDs := make([]uint, 0, len(instances))
+	for _, inst := range instances {
+		instanceIDs = append(instanceIDs, inst.ID)
+		notify.Notify(inst.UserID, system.NotifyEventTrafficLimited, map[string]interface{}{
+			"InstanceID":   inst.ID,
+			"InstanceName": inst.Name,
+			"Message":      message,
+		})
+	}
+	webhook.Publish(system.WebhookEventInstanceTrafficLimited, map[string]interface{}{
+		"level":       level,
+		"targetId":    targetID,
+		"instanceIds": instanceIDs,
+		"message":     message,
+	})
+}
+
 // unlimitInstance 解除单个实例的限制
 func (s *ThreeTierLimitService) unlimitInstance(instanceID uint, reason string) (bool, error) {
 	updates := map[string]interface{}{
@@ -383,7 +407,7 @@ func (s *ThreeTierLimitService) limitUserInstances(userID uint, message string)
 
 	// 获取被停止的实例ID列表用于创建任务
 	var instances []provider.Instance
-	if err := global.APP_DB.Select("id, provider_id").
+	if err := global.APP_DB.Select("id, name, user_id, provider_id").
 		Where("user_id = ? AND traffic_limited = ? AND traffic_limit_reason = ?",
 			userID, true, "user").
 		Find(&instances).Error; err != nil {
@@ -399,6 +423,8 @@ func (s *ThreeTierLimitService) limitUserInstances(userID uint, message string)
 		}
 	}
 
+	s.publishTrafficLimited(LimitLevelUser, userID, instances, message)
+
 	global.APP_LOG.Info("已批量限制用户所有实例",
 		zap.Uint("userID", userID),
 		zap.Int64("影响实例数", result.RowsAffected))
@@ -568,7 +594,7 @@ func (s *ThreeTierLimitService) limitProviderInstances(providerID uint, message
 
 	// 获取被停止的实例ID列表用于创建任务
 	var instances []provider.Instance
-	if err := global.APP_DB.Select("id, user_id").
+	if err := global.APP_DB.Select("id, name, user_id").
 		Where("provider_id = ? AND traffic_limited = ? AND traffic_limit_reason = ?",
 			providerID, true, "provider").
 		Find(&instances).Error; err != nil {
@@ -585,6 +611,8 @@ func (s *ThreeTierLimitService) limitProviderInstances(providerID uint, message
 		}
 	}
 
+	s.publishTrafficLimited(LimitLevelProvider, providerID, instances, message)
+
 	global.APP_LOG.Info("已批量限制Provider所有实例",
 		zap.Uint("providerID", providerID),
 		zap.Int64("影响实例数", result.RowsAffected))
@@ -35,10 +35,9 @@ func (s *UserTrafficService) GetUserTrafficOverview(userID uint) (map[string]int
 	cacheKey := cache.MakeUserTrafficOverviewKey(userID)
 
 	// 尝试从缓存获取
-	if cachedData, ok := cacheService.Get(cacheKey); ok {
-		if overview, ok := cachedData.(map[string]interface{}); ok {
-			return overview, nil
-		}
+	var cached map[string]interface{}
+	if cacheService.GetJSON(cacheKey, &cached) {
+		return cached, nil
 	}
 
 	// 缓存未命中，查询数据
@@ -48,7 +47,7 @@ func (s *UserTrafficService) GetUserTrafficOverview(userID uint) (map[string]int
 	}
 
 	// 缓存结果
-	cacheService.Set(cacheKey, overview, cache.TTLUserTrafficOverview)
+	cacheService.SetJSON(cacheKey, overview, cache.TTLUserTrafficOverview)
 	return overview, nil
 }
 
@@ -133,14 +132,13 @@ func (s *UserTrafficService) GetInstanceTrafficDetail(userID, instanceID uint) (
 	cacheKey := cache.MakeInstanceTrafficDetailKey(instanceID)
 
 	// 尝试从缓存获取
-	if cachedData, ok := cacheService.Get(cacheKey); ok {
-		if detail, ok := cachedData.(map[string]interface{}); ok {
-			// 验证用户权限（即使是缓存数据也要验证）
-			if !s.hasInstanceAccess(userID, instanceID) {
-				return nil, fmt.Errorf("用户无权限访问该实例")
-			}
-			return detail, nil
+	var cached map[string]interface{}
+	if cacheService.GetJSON(cacheKey, &cached) {
+		// 验证用户权限（即使是缓存数据也要验证）
+		if !s.hasInstanceAccess(userID, instanceID) {
+			return nil, fmt.Errorf("用户无权限访问该实例")
 		}
+		return cached, nil
 	}
 
 	// 缓存未命中，查询数据
@@ -150,7 +148,7 @@ func (s *UserTrafficService) GetInstanceTrafficDetail(userID, instanceID uint) (
 	}
 
 	// 缓存结果
-	cacheService.Set(cacheKey, detail, cache.TTLInstanceTrafficDetail)
+	cacheService.SetJSON(cacheKey, detail, cache.TTLInstanceTrafficDetail)
 	return detail, nil
 }
 
@@ -249,10 +247,9 @@ func (s *UserTrafficService) GetUserInstancesTrafficSummary(userID uint) (map[st
 	cacheKey := cache.MakeUserTrafficSummaryKey(userID, now.Year(), int(now.Month()))
 
 	// 尝试从缓存获取
-	if cachedData, ok := cacheService.Get(cacheKey); ok {
-		if summary, ok := cachedData.(map[string]interface{}); ok {
-			return summary, nil
-		}
+	var cached map[string]interface{}
+	if cacheService.GetJSON(cacheKey, &cached) {
+		return cached, nil
 	}
 
 	// 缓存未命中，查询数据
@@ -262,7 +259,7 @@ func (s *UserTrafficService) GetUserInstancesTrafficSummary(userID uint) (map[st
 	}
 
 	// 缓存结果
-	cacheService.Set(cacheKey, summary, cache.TTLUserTrafficSummary)
+	cacheService.SetJSON(cacheKey, summary, cache.TTLUserTrafficSummary)
 	return summary, nil
 }
 
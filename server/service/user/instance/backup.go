@@ -0,0 +1,98 @@
+package instance
+
+import (
+	"errors"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/backup"
+)
+
+// getOwnedBackup 获取属于用户的备份
+func (s *Service) getOwnedBackup(userID, backupID uint) (*providerModel.InstanceBackup, error) {
+	var bak providerModel.InstanceBackup
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", backupID, userID).First(&bak).Error; err != nil {
+		return nil, errors.New("备份不存在或无权限")
+	}
+	return &bak, nil
+}
+
+// GetInstanceBackups 获取用户实例的备份列表
+func (s *Service) GetInstanceBackups(userID, instanceID uint) ([]providerModel.InstanceBackup, error) {
+	if _, err := s.getOwnedInstance(userID, instanceID); err != nil {
+		return nil, err
+	}
+	return backup.NewService().ListInstanceBackups(instanceID)
+}
+
+// CreateInstanceBackup 用户为实例创建备份
+func (s *Service) CreateInstanceBackup(userID, instanceID uint) (*userModel.BackupTaskResponse, error) {
+	instance, err := s.getOwnedInstance(userID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	bak, taskModel, err := backup.NewService().CreateBackup(instance, providerModel.BackupTriggerManual, true)
+	if err != nil {
+		return nil, err
+	}
+	return &userModel.BackupTaskResponse{TaskID: taskModel.ID, BackupID: bak.ID}, nil
+}
+
+// RestoreBackup 用户将备份恢复到自己的实例
+func (s *Service) RestoreBackup(userID, backupID uint, req userModel.RestoreBackupRequest) (*userModel.BackupTaskResponse, error) {
+	bak, err := s.getOwnedBackup(userID, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getOwnedInstance(userID, req.TargetInstanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskModel, err := backup.NewService().RestoreBackup(bak, target)
+	if err != nil {
+		return nil, err
+	}
+	return &userModel.BackupTaskResponse{TaskID: taskModel.ID, BackupID: bak.ID}, nil
+}
+
+// DeleteBackup 用户删除备份
+func (s *Service) DeleteBackup(userID, backupID uint) error {
+	bak, err := s.getOwnedBackup(userID, backupID)
+	if err != nil {
+		return err
+	}
+	return backup.NewService().DeleteBackup(bak)
+}
+
+// GetBackupSchedule 获取用户实例的定时备份计划
+func (s *Service) GetBackupSchedule(userID, instanceID uint) (*providerModel.BackupSchedule, error) {
+	if _, err := s.getOwnedInstance(userID, instanceID); err != nil {
+		return nil, err
+	}
+	return backup.NewService().GetSchedule(instanceID)
+}
+
+// SetBackupSchedule 设置用户实例的定时备份计划
+func (s *Service) SetBackupSchedule(userID, instanceID uint, req userModel.BackupScheduleRequest) (*providerModel.BackupSchedule, error) {
+	instance, err := s.getOwnedInstance(userID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return backup.NewService().SetSchedule(instance, adminModel.BackupScheduleRequest{
+		Enabled:       req.Enabled,
+		IntervalHours: req.IntervalHours,
+	}, true)
+}
+
+// DeleteBackupSchedule 删除用户实例的定时备份计划
+func (s *Service) DeleteBackupSchedule(userID, instanceID uint) error {
+	if _, err := s.getOwnedInstance(userID, instanceID); err != nil {
+		return err
+	}
+	return backup.NewService().DeleteSchedule(instanceID)
+}
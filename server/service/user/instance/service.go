@@ -8,6 +8,8 @@ import (
 	"oneclickvirt/service/auth"
 	"oneclickvirt/service/cache"
 	"oneclickvirt/service/database"
+	"oneclickvirt/service/images"
+	"oneclickvirt/service/instancemetrics"
 	"oneclickvirt/service/task"
 	trafficService "oneclickvirt/service/traffic"
 	"oneclickvirt/utils"
@@ -276,11 +278,30 @@ func (s *Service) InstanceAction(userID uint, req userModel.InstanceActionReques
 			return errors.New("实例已有重置任务正在进行")
 		}
 
+		// 指定了目标镜像时重装为该镜像，需与Provider和实例规格匹配
+		if req.ImageID > 0 {
+			var provider providerModel.Provider
+			if err := global.APP_DB.First(&provider, instance.ProviderID).Error; err != nil {
+				return fmt.Errorf("获取Provider信息失败: %v", err)
+			}
+			imageService := &images.ImageService{}
+			if _, err := imageService.ValidateReinstallImage(req.ImageID, &instance, &provider); err != nil {
+				return err
+			}
+		}
+
 		// 创建重置任务，记录原始状态
-		originalStatus := instance.Status
+		taskData, err := json.Marshal(adminModel.ResetInstanceTaskRequest{
+			InstanceId:     instance.ID,
+			ProviderId:     instance.ProviderID,
+			OriginalStatus: instance.Status,
+			ImageId:        req.ImageID,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化任务数据失败: %v", err)
+		}
 		taskService := getTaskService()
-		taskData := fmt.Sprintf(`{"instanceId":%d,"providerId":%d,"originalStatus":"%s"}`, instance.ID, instance.ProviderID, originalStatus)
-		_, err := taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "reset", taskData, 1800)
+		_, err = taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "reset", string(taskData), 1800)
 		if err != nil {
 			return fmt.Errorf("创建重置任务失败: %v", err)
 		}
@@ -418,8 +439,8 @@ func (s *Service) extractIPFromEndpoint(endpoint string) string {
 	return utils.ExtractIPFromEndpoint(endpoint)
 }
 
-// GetInstanceMonitoring 获取实例监控数据
-func (s *Service) GetInstanceMonitoring(userID, instanceID uint) (*userModel.InstanceMonitoringResponse, error) {
+// GetInstanceMonitoring 获取实例监控数据，metricsRange 为资源使用曲线的时间范围
+func (s *Service) GetInstanceMonitoring(userID, instanceID uint, metricsRange string) (*userModel.InstanceMonitoringResponse, error) {
 	// 首先验证实例是否属于该用户
 	var instance providerModel.Instance
 	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
@@ -516,6 +537,18 @@ func (s *Service) GetInstanceMonitoring(userID, instanceID uint) (*userModel.Ins
 		},
 	}
 
+	// CPU、内存、磁盘使用曲线，获取失败不影响流量数据的返回
+	if global.APP_CONFIG.Metrics.Enabled {
+		resourceData, err := instancemetrics.NewService().GetSeries(instanceID, metricsRange)
+		if err != nil {
+			global.APP_LOG.Warn("获取实例资源使用历史失败",
+				zap.Uint("instanceID", instanceID),
+				zap.Error(err))
+		} else {
+			monitoring.ResourceData = resourceData
+		}
+	}
+
 	return monitoring, nil
 }
 
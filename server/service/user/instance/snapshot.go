@@ -0,0 +1,88 @@
+package instance
+
+import (
+	"errors"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/snapshot"
+)
+
+// getOwnedInstance 获取属于用户的实例
+func (s *Service) getOwnedInstance(userID, instanceID uint) (*providerModel.Instance, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
+		return nil, errors.New("实例不存在或无权限")
+	}
+	return &instance, nil
+}
+
+// getOwnedSnapshot 获取属于用户实例的快照
+func (s *Service) getOwnedSnapshot(userID, instanceID, snapshotID uint) (*providerModel.Instance, *providerModel.InstanceSnapshot, error) {
+	instance, err := s.getOwnedInstance(userID, instanceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var snap providerModel.InstanceSnapshot
+	if err := global.APP_DB.Where("id = ? AND instance_id = ?", snapshotID, instanceID).First(&snap).Error; err != nil {
+		return nil, nil, errors.New("快照不存在")
+	}
+	return instance, &snap, nil
+}
+
+// GetInstanceSnapshots 获取用户实例的快照列表
+func (s *Service) GetInstanceSnapshots(userID, instanceID uint) ([]providerModel.InstanceSnapshot, error) {
+	if _, err := s.getOwnedInstance(userID, instanceID); err != nil {
+		return nil, err
+	}
+	return snapshot.NewService().ListInstanceSnapshots(instanceID)
+}
+
+// CreateInstanceSnapshot 用户为实例创建快照
+func (s *Service) CreateInstanceSnapshot(userID, instanceID uint, req userModel.CreateSnapshotRequest) (*userModel.SnapshotTaskResponse, error) {
+	instance, err := s.getOwnedInstance(userID, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, taskModel, err := snapshot.NewService().CreateSnapshot(instance, req.Name, req.Description, true)
+	if err != nil {
+		return nil, err
+	}
+	return &userModel.SnapshotTaskResponse{TaskID: taskModel.ID, SnapshotID: snap.ID}, nil
+}
+
+// RestoreInstanceSnapshot 用户将实例恢复到快照
+func (s *Service) RestoreInstanceSnapshot(userID, instanceID, snapshotID uint) (*userModel.SnapshotTaskResponse, error) {
+	instance, snap, err := s.getOwnedSnapshot(userID, instanceID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskModel, err := snapshot.NewService().RestoreSnapshot(instance, snap)
+	if err != nil {
+		return nil, err
+	}
+	return &userModel.SnapshotTaskResponse{TaskID: taskModel.ID, SnapshotID: snap.ID}, nil
+}
+
+// DeleteInstanceSnapshot 用户删除实例快照
+func (s *Service) DeleteInstanceSnapshot(userID, instanceID, snapshotID uint) (*userModel.SnapshotTaskResponse, error) {
+	instance, snap, err := s.getOwnedSnapshot(userID, instanceID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskModel, err := snapshot.NewService().DeleteSnapshot(instance, snap)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userModel.SnapshotTaskResponse{SnapshotID: snap.ID}
+	if taskModel != nil {
+		resp.TaskID = taskModel.ID
+	}
+	return resp, nil
+}
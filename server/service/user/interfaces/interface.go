@@ -14,7 +14,7 @@ type InstanceServiceInterface interface {
 	GetUserInstances(userID uint, req userModel.UserInstanceListRequest) ([]userModel.UserInstanceResponse, int64, error)
 	InstanceAction(userID uint, req userModel.InstanceActionRequest) error
 	GetInstanceDetail(userID, instanceID uint) (*userModel.UserInstanceDetailResponse, error)
-	GetInstanceMonitoring(userID, instanceID uint) (*userModel.InstanceMonitoringResponse, error)
+	GetInstanceMonitoring(userID, instanceID uint, metricsRange string) (*userModel.InstanceMonitoringResponse, error)
 	PerformInstanceAction(userID uint, req userModel.InstanceActionRequest) error
 }
 
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"oneclickvirt/constant"
@@ -10,8 +11,10 @@ import (
 	providerModel "oneclickvirt/model/provider"
 	systemModel "oneclickvirt/model/system"
 	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/billing"
 	"oneclickvirt/service/cache"
 	"oneclickvirt/service/database"
+	"oneclickvirt/service/images"
 	"oneclickvirt/service/resources"
 	"time"
 
@@ -35,6 +38,20 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		zap.String("bandwidthId", req.BandwidthId),
 		zap.String("description", req.Description))
 
+	// 未指定节点时由调度器自动选择
+	if req.ProviderId == 0 {
+		providerID, err := s.selectPlacementProvider(userID, &req)
+		if err != nil {
+			global.APP_LOG.Warn("自动选择节点失败",
+				zap.Uint("userID", userID),
+				zap.String("region", req.Region),
+				zap.String("country", req.Country),
+				zap.Error(err))
+			return nil, err
+		}
+		req.ProviderId = providerID
+	}
+
 	// 快速验证基本参数
 	var provider providerModel.Provider
 	if err := global.APP_DB.First(&provider, req.ProviderId).Error; err != nil {
@@ -73,7 +90,8 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 	}
 
 	// 验证Provider和Image的匹配性
-	if err := s.validateProviderImageCompatibility(&provider, &systemImage); err != nil {
+	imageService := &images.ImageService{}
+	if err := imageService.ValidateProviderCompatibility(&provider, &systemImage); err != nil {
 		global.APP_LOG.Error("Provider和镜像不匹配",
 			zap.Uint("providerId", req.ProviderId),
 			zap.Uint("imageId", req.ImageId),
@@ -85,6 +103,18 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		return nil, err
 	}
 
+	// 验证用户提供的user-data
+	if req.UserData != "" {
+		if err := imageService.ValidateUserData(req.UserData, &provider, &systemImage); err != nil {
+			global.APP_LOG.Warn("user-data验证失败",
+				zap.Uint("userID", userID),
+				zap.Uint("providerId", req.ProviderId),
+				zap.Int("size", len(req.UserData)),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
 	// 验证规格ID并获取规格信息，同时验证用户权限
 	global.APP_LOG.Info("开始验证规格ID",
 		zap.String("cpuId", req.CPUId),
@@ -137,7 +167,7 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 	}
 
 	// 验证实例的最低硬件要求（统一验证虚拟机和容器）
-	if err := s.validateInstanceMinimumRequirements(&systemImage, memorySpec, diskSpec, &provider); err != nil {
+	if err := imageService.ValidateMinimumRequirements(&systemImage, int64(memorySpec.SizeMB), int64(diskSpec.SizeMB), &provider); err != nil {
 		global.APP_LOG.Error("实例最低硬件要求验证失败",
 			zap.Uint("imageId", req.ImageId),
 			zap.String("imageName", systemImage.Name),
@@ -149,6 +179,21 @@ func (s *Service) CreateUserInstance(userID uint, req userModel.CreateInstanceRe
 		return nil, err
 	}
 
+	// 启用计费时检查余额是否足够支付首个计费周期
+	if err := billing.NewService().CheckCreateAllowed(userID, &providerModel.Instance{
+		ProviderID:   req.ProviderId,
+		InstanceType: systemImage.InstanceType,
+		CPU:          cpuSpec.Cores,
+		Memory:       int64(memorySpec.SizeMB),
+		Disk:         int64(diskSpec.SizeMB),
+	}); err != nil {
+		global.APP_LOG.Warn("余额检查未通过",
+			zap.Uint("userID", userID),
+			zap.Uint("providerId", req.ProviderId),
+			zap.Error(err))
+		return nil, err
+	}
+
 	global.APP_LOG.Info("所有验证通过，开始创建实例",
 		zap.Uint("userID", userID),
 		zap.Uint("providerId", req.ProviderId),
@@ -282,8 +327,21 @@ func (s *Service) createInstanceWithMinimalTransaction(userID uint, req *userMod
 		}
 
 		// 2. 创建任务
-		taskData := fmt.Sprintf(`{"providerId":%d,"imageId":%d,"cpuId":"%s","memoryId":"%s","diskId":"%s","bandwidthId":"%s","description":"%s","sessionId":"%s"}`,
-			req.ProviderId, req.ImageId, req.CPUId, req.MemoryId, req.DiskId, req.BandwidthId, req.Description, sessionID)
+		taskDataBytes, err := json.Marshal(adminModel.CreateInstanceTaskRequest{
+			ProviderId:  req.ProviderId,
+			ImageId:     req.ImageId,
+			CPUId:       req.CPUId,
+			MemoryId:    req.MemoryId,
+			DiskId:      req.DiskId,
+			BandwidthId: req.BandwidthId,
+			Description: req.Description,
+			SessionId:   sessionID,
+			UserData:    req.UserData,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化任务数据失败: %v", err)
+		}
+		taskData := string(taskDataBytes)
 
 		// 计算预计执行时长
 		estimatedDuration := 300 // 默认5分钟
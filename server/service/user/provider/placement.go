@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/constant"
+	"oneclickvirt/global"
+	systemModel "oneclickvirt/model/system"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/service/placement"
+
+	"go.uber.org/zap"
+)
+
+// selectPlacementProvider 自动调度模式下为创建请求选择节点
+// 调度器按镜像、规格、地区过滤并排序候选节点，这里再按用户在各节点上的等级限制逐个确认，返回第一个满足条件的节点
+func (s *Service) selectPlacementProvider(userID uint, req *userModel.CreateInstanceRequest) (uint, error) {
+	var systemImage systemModel.SystemImage
+	if err := global.APP_DB.Where("id = ?", req.ImageId).First(&systemImage).Error; err != nil {
+		return 0, errors.New("无效的镜像ID")
+	}
+	if systemImage.Status != "active" {
+		return 0, errors.New("所选镜像不可用")
+	}
+
+	cpuSpec, err := constant.GetCPUSpecByID(req.CPUId)
+	if err != nil {
+		return 0, fmt.Errorf("无效的CPU规格ID: %v", err)
+	}
+	memorySpec, err := constant.GetMemorySpecByID(req.MemoryId)
+	if err != nil {
+		return 0, fmt.Errorf("无效的内存规格ID: %v", err)
+	}
+	diskSpec, err := constant.GetDiskSpecByID(req.DiskId)
+	if err != nil {
+		return 0, fmt.Errorf("无效的磁盘规格ID: %v", err)
+	}
+	bandwidthSpec, err := constant.GetBandwidthSpecByID(req.BandwidthId)
+	if err != nil {
+		return 0, fmt.Errorf("无效的带宽规格ID: %v", err)
+	}
+
+	candidates, err := placement.NewService().SelectCandidates(placement.Request{
+		InstanceType:  systemImage.InstanceType,
+		ProviderTypes: strings.Split(systemImage.ProviderType, ","),
+		Architecture:  systemImage.Architecture,
+		Region:        req.Region,
+		Country:       req.Country,
+		CPU:           cpuSpec.Cores,
+		Memory:        int64(memorySpec.SizeMB),
+		Disk:          int64(diskSpec.SizeMB),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		if err := s.validateUserSpecPermissions(userID, c.Provider.ID, cpuSpec, memorySpec, diskSpec, bandwidthSpec); err != nil {
+			lastErr = err
+			continue
+		}
+		global.APP_LOG.Info("自动调度选定节点",
+			zap.Uint("userID", userID),
+			zap.Uint("providerId", c.Provider.ID),
+			zap.String("providerName", c.Provider.Name),
+			zap.Float64("score", c.Score),
+			zap.String("region", req.Region),
+			zap.String("country", req.Country))
+		return c.Provider.ID, nil
+	}
+
+	// 所有候选节点都超出用户等级限制时返回最后一个原因，便于用户调整规格
+	if lastErr != nil {
+		return 0, lastErr
+	}
+	return 0, placement.ErrNoAvailableProvider
+}
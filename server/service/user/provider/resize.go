@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"oneclickvirt/constant"
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	providerModel "oneclickvirt/model/provider"
+	userModel "oneclickvirt/model/user"
+	"oneclickvirt/provider"
+	providerService "oneclickvirt/service/provider"
+	"oneclickvirt/service/resources"
+
+	"go.uber.org/zap"
+)
+
+// ResizeInstance 用户调整实例的CPU、内存和磁盘规格（异步任务）
+// 在创建任务前完成等级规格、用户配额和Provider资源预算的校验，配额在任务成功后按新规格调整
+func (s *Service) ResizeInstance(userID, instanceID uint, req userModel.ResizeInstanceRequest) (*adminModel.Task, error) {
+	var instance providerModel.Instance
+	if err := global.APP_DB.Where("id = ? AND user_id = ?", instanceID, userID).First(&instance).Error; err != nil {
+		return nil, errors.New("实例不存在或无权限")
+	}
+
+	if instance.Status != "running" && instance.Status != "stopped" {
+		return nil, fmt.Errorf("实例当前状态为 %s，只有运行中或已停止的实例可以调整规格", instance.Status)
+	}
+	if instance.IsFrozen {
+		return nil, errors.New("实例已被冻结，无法调整规格")
+	}
+
+	var existingTask adminModel.Task
+	if err := global.APP_DB.Where("instance_id = ? AND status IN ('pending', 'running')", instance.ID).First(&existingTask).Error; err == nil {
+		return nil, fmt.Errorf("实例有正在进行的%s任务，请稍后重试", existingTask.TaskType)
+	}
+
+	// 解析新规格，未传的规格保持不变且不参与等级规格校验
+	newCPU, newMemory, newDisk := instance.CPU, instance.Memory, instance.Disk
+	cpuSpec, memorySpec, diskSpec := &constant.CPUSpec{}, &constant.MemorySpec{}, &constant.DiskSpec{}
+	var err error
+	if req.CPUId != "" {
+		if cpuSpec, err = constant.GetCPUSpecByID(req.CPUId); err != nil {
+			return nil, fmt.Errorf("无效的CPU规格ID: %v", err)
+		}
+		newCPU = cpuSpec.Cores
+	}
+	if req.MemoryId != "" {
+		if memorySpec, err = constant.GetMemorySpecByID(req.MemoryId); err != nil {
+			return nil, fmt.Errorf("无效的内存规格ID: %v", err)
+		}
+		newMemory = int64(memorySpec.SizeMB)
+	}
+	if req.DiskId != "" {
+		if diskSpec, err = constant.GetDiskSpecByID(req.DiskId); err != nil {
+			return nil, fmt.Errorf("无效的磁盘规格ID: %v", err)
+		}
+		newDisk = int64(diskSpec.SizeMB)
+	}
+
+	if newCPU == instance.CPU && newMemory == instance.Memory && newDisk == instance.Disk {
+		return nil, errors.New("新规格与当前规格相同")
+	}
+	if newDisk < instance.Disk {
+		return nil, fmt.Errorf("磁盘只能扩容：当前 %dMB，目标 %dMB", instance.Disk, newDisk)
+	}
+
+	var dbProvider providerModel.Provider
+	if err := global.APP_DB.First(&dbProvider, instance.ProviderID).Error; err != nil {
+		return nil, fmt.Errorf("获取Provider信息失败: %v", err)
+	}
+	if newDisk > instance.Disk && dbProvider.Type == "docker" {
+		return nil, errors.New("Docker实例不支持调整磁盘大小")
+	}
+
+	// Provider未加载到内存时无法判断，放行并由任务执行时最终确认
+	if prov, exists := providerService.GetProviderService().GetProviderByID(instance.ProviderID); exists {
+		if _, ok := prov.(provider.ResizeProvider); !ok {
+			return nil, fmt.Errorf("该实例所在的Provider（%s）不支持调整规格", prov.GetType())
+		}
+	}
+
+	if err := s.validateUserSpecPermissions(userID, instance.ProviderID, cpuSpec, memorySpec, diskSpec, &constant.BandwidthSpec{}); err != nil {
+		return nil, err
+	}
+
+	checkResult, err := resources.NewQuotaService().ValidateInstanceResize(&instance, newCPU, newMemory, newDisk)
+	if err != nil {
+		return nil, fmt.Errorf("配额验证失败: %v", err)
+	}
+	if !checkResult.Allowed {
+		return nil, errors.New(checkResult.Reason)
+	}
+
+	taskReq := adminModel.ResizeInstanceTaskRequest{
+		InstanceID:     instance.ID,
+		ProviderID:     instance.ProviderID,
+		OldCPU:         instance.CPU,
+		OldMemory:      instance.Memory,
+		OldDisk:        instance.Disk,
+		NewCPU:         newCPU,
+		NewMemory:      newMemory,
+		NewDisk:        newDisk,
+		OriginalStatus: instance.Status,
+	}
+	taskDataJSON, err := json.Marshal(taskReq)
+	if err != nil {
+		return nil, fmt.Errorf("序列化任务数据失败: %v", err)
+	}
+
+	// 先锁定实例状态，防止创建任务期间有其他操作
+	result := global.APP_DB.Model(&providerModel.Instance{}).
+		Where("id = ? AND status = ?", instance.ID, instance.Status).
+		Update("status", "resizing")
+	if result.Error != nil {
+		return nil, fmt.Errorf("更新实例状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("实例状态已变化，请刷新后重试")
+	}
+
+	task, err := s.taskService.CreateTask(userID, &instance.ProviderID, &instance.ID, "resize", string(taskDataJSON), 1800)
+	if err != nil {
+		global.APP_DB.Model(&providerModel.Instance{}).
+			Where("id = ? AND status = ?", instance.ID, "resizing").
+			Update("status", instance.Status)
+		return nil, fmt.Errorf("创建调整规格任务失败: %v", err)
+	}
+
+	global.APP_LOG.Info("用户创建实例调整规格任务成功",
+		zap.Uint("userId", userID),
+		zap.Uint("instanceId", instance.ID),
+		zap.Int("cpu", newCPU),
+		zap.Int64("memory", newMemory),
+		zap.Int64("disk", newDisk),
+		zap.Uint("taskId", task.ID))
+
+	return task, nil
+}
@@ -19,6 +19,7 @@ import (
 	"oneclickvirt/provider/lxd"
 	"oneclickvirt/service/database"
 	"oneclickvirt/service/interfaces"
+	"oneclickvirt/service/ipam"
 	providerService "oneclickvirt/service/provider"
 	"oneclickvirt/service/resources"
 	"oneclickvirt/service/traffic"
@@ -208,6 +209,7 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 			MaxTraffic:         0,     // 默认为0，表示继承用户等级限制，不单独限制实例
 			TrafficLimited:     false, // 显式设置为false，确保不会因流量误判为超限
 			TrafficLimitReason: "",    // 初始无限制原因
+			UserData:           taskReq.UserData,
 		}
 
 		// 创建实例
@@ -230,6 +232,11 @@ func (s *Service) prepareInstanceCreation(ctx context.Context, task *adminModel.
 			return fmt.Errorf("分配Provider资源失败: %v", err)
 		}
 
+		// 独立IP网络类型从地址池分配公网地址（使用悲观锁）
+		if _, err := ipam.NewService().AllocateForInstanceInTx(tx, provider.ID, instance.ID, provider.NetworkType); err != nil {
+			return fmt.Errorf("分配独立IP地址失败: %v", err)
+		}
+
 		// 消费预留资源（实例已创建成功）
 		reservationService := resources.GetResourceReservationService()
 		if err := reservationService.ConsumeReservationBySessionInTx(tx, taskReq.SessionId); err != nil {
@@ -398,6 +405,7 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 		Disk:         fmt.Sprintf("%dm", diskSpec.SizeMB),   // 使用实际磁盘大小（MB格式）
 		InstanceType: instance.InstanceType,
 		ImageURL:     systemImage.URL, // 镜像URL用于下载
+		UserData:     taskReq.UserData,
 		Metadata: map[string]string{
 			"user_level":               fmt.Sprintf("%d", user.Level),              // 用户等级，用于带宽限制配置
 			"bandwidth_spec":           fmt.Sprintf("%d", bandwidthSpec.SpeedMbps), // 用户选择的带宽规格
@@ -417,6 +425,13 @@ func (s *Service) executeProviderCreation(ctx context.Context, task *adminModel.
 		DiskIOLimit:  stringPtr(dbProvider.ContainerDiskIOLimit),
 	}
 
+	// 将预处理阶段分配的独立IP传递给Provider
+	if allocations, err := ipam.NewService().GetInstanceAllocations(instance.ID); err != nil {
+		global.APP_LOG.Warn("获取实例独立IP失败", zap.Uint("taskId", task.ID), zap.Error(err))
+	} else {
+		ipam.ApplyMetadata(instanceConfig.Metadata, allocations)
+	}
+
 	// 预分配端口映射（所有Provider类型都需要）
 	portMappingService := &resources.PortMappingService{}
 
@@ -534,6 +549,11 @@ func (s *Service) finalizeInstanceCreation(ctx context.Context, task *adminModel
 					zap.Uint("instanceId", instance.ID))
 			}
 
+			// 释放已分配的独立IP地址
+			if err := ipam.NewService().ReleaseByInstanceInTx(tx, instance.ID); err != nil {
+				global.APP_LOG.Error("释放失败实例独立IP失败", zap.Uint("instanceId", instance.ID), zap.Error(err))
+			}
+
 			// 释放已分配的Provider资源
 			resourceService := &resources.ResourceService{}
 			if err := resourceService.ReleaseResourcesInTx(tx, instance.ProviderID, instance.InstanceType,
@@ -855,6 +875,10 @@ func (s *Service) finalizeInstanceCreation(ctx context.Context, task *adminModel
 				}
 			}
 		}
+		// 从地址池分配的独立IP即为实例的公网地址
+		if allocations, err := ipam.NewService().GetInstanceAllocations(instance.ID); err == nil {
+			ipam.ApplyInstanceUpdates(instanceUpdates, allocations)
+		}
 		if err := tx.Model(instance).Updates(instanceUpdates).Error; err != nil {
 			return fmt.Errorf("更新实例信息失败: %v", err)
 		}
@@ -2,16 +2,13 @@ package provider
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"strings"
 
 	"oneclickvirt/constant"
 	"oneclickvirt/global"
 	adminModel "oneclickvirt/model/admin"
 	providerModel "oneclickvirt/model/provider"
 	resourceModel "oneclickvirt/model/resource"
-	systemModel "oneclickvirt/model/system"
 	"oneclickvirt/service/auth"
 	"oneclickvirt/service/resources"
 
@@ -19,39 +16,6 @@ import (
 	"gorm.io/gorm"
 )
 
-// validateProviderImageCompatibility 验证Provider和Image的兼容性
-func (s *Service) validateProviderImageCompatibility(provider *providerModel.Provider, image *systemModel.SystemImage) error {
-	// 验证Provider类型是否支持该镜像
-	supportedProviders := strings.Split(image.ProviderType, ",")
-	providerSupported := false
-	for _, supportedType := range supportedProviders {
-		if strings.TrimSpace(supportedType) == provider.Type {
-			providerSupported = true
-			break
-		}
-	}
-
-	if !providerSupported {
-		return fmt.Errorf("所选镜像不支持Provider类型 %s，支持的类型: %s", provider.Type, image.ProviderType)
-	}
-
-	// 验证架构兼容性
-	if provider.Architecture != "" && image.Architecture != "" && provider.Architecture != image.Architecture {
-		return fmt.Errorf("架构不匹配：Provider架构为 %s，镜像架构为 %s", provider.Architecture, image.Architecture)
-	}
-
-	// 验证实例类型支持
-	if image.InstanceType == "vm" && !provider.VirtualMachineEnabled {
-		return errors.New("该Provider不支持虚拟机实例")
-	}
-
-	if image.InstanceType == "container" && !provider.ContainerEnabled {
-		return errors.New("该Provider不支持容器实例")
-	}
-
-	return nil
-}
-
 // validateUserSpecPermissions 验证用户等级限制和资源规格权限
 //
 // 功能说明：
@@ -193,53 +157,6 @@ func (s *Service) validateUserSpecPermissions(userID uint, providerID uint, cpuS
 	return nil
 }
 
-// validateInstanceMinimumRequirements 验证实例的最低硬件要求（统一验证）
-func (s *Service) validateInstanceMinimumRequirements(image *systemModel.SystemImage, memorySpec *constant.MemorySpec, diskSpec *constant.DiskSpec, provider *providerModel.Provider) error {
-	if image == nil {
-		return fmt.Errorf("镜像信息不能为空")
-	}
-
-	// 使用镜像自身的最低硬件要求
-	minMemoryMB := image.MinMemoryMB
-	minDiskMB := image.MinDiskMB
-
-	// 验证镜像是否设置了最低要求
-	if minMemoryMB <= 0 || minDiskMB <= 0 {
-		return fmt.Errorf("镜像未设置最低硬件要求，请联系管理员")
-	}
-
-	// 验证内存要求
-	if memorySpec.SizeMB < minMemoryMB {
-		instanceTypeDesc := "虚拟机"
-		if image.InstanceType == "container" {
-			instanceTypeDesc = "容器"
-		}
-		return fmt.Errorf("%s镜像 %s 最少需要%dMB内存，当前选择%dMB不足",
-			instanceTypeDesc, image.Name, minMemoryMB, memorySpec.SizeMB)
-	}
-
-	// 验证磁盘要求
-	if diskSpec.SizeMB < minDiskMB {
-		instanceTypeDesc := "虚拟机"
-		if image.InstanceType == "container" {
-			instanceTypeDesc = "容器"
-		}
-		return fmt.Errorf("%s镜像 %s 最少需要%dMB硬盘，当前选择%dMB不足",
-			instanceTypeDesc, image.Name, minDiskMB, diskSpec.SizeMB)
-	}
-
-	global.APP_LOG.Info("实例最低硬件要求验证通过",
-		zap.String("imageName", image.Name),
-		zap.String("instanceType", image.InstanceType),
-		zap.String("providerType", provider.Type),
-		zap.Int("requiredMemoryMB", minMemoryMB),
-		zap.Int("requiredDiskMB", minDiskMB),
-		zap.Int("selectedMemoryMB", memorySpec.SizeMB),
-		zap.Int("selectedDiskMB", diskSpec.SizeMB))
-
-	return nil
-}
-
 // validateCreateTaskPermissionsInTx 在事务中验证任务创建权限（三重验证）
 // 保持事务和行锁直到验证完成，防止并发创建导致超出配额
 func (s *Service) validateCreateTaskPermissionsInTx(tx *gorm.DB, userID uint, providerID uint, instanceType string,
@@ -67,8 +67,8 @@ func (s *Service) GetInstanceDetail(userID, instanceID uint) (*userModel.UserIns
 }
 
 // GetInstanceMonitoring 获取实例监控数据
-func (s *Service) GetInstanceMonitoring(userID, instanceID uint) (*userModel.InstanceMonitoringResponse, error) {
-	return s.instance.GetInstanceMonitoring(userID, instanceID)
+func (s *Service) GetInstanceMonitoring(userID, instanceID uint, metricsRange string) (*userModel.InstanceMonitoringResponse, error) {
+	return s.instance.GetInstanceMonitoring(userID, instanceID, metricsRange)
 }
 
 // PerformInstanceAction 执行实例操作（兼容原方法名）
@@ -174,6 +174,11 @@ func (s *Service) GetInstanceTypePermissions(userID uint) (map[string]interface{
 	return s.provider.GetInstanceTypePermissions(userID)
 }
 
+// ResizeInstance 调整实例规格
+func (s *Service) ResizeInstance(userID, instanceID uint, req userModel.ResizeInstanceRequest) (*adminModel.Task, error) {
+	return s.provider.ResizeInstance(userID, instanceID, req)
+}
+
 // ===== 实例创建处理相关方法 =====
 
 // ProcessCreateInstanceTask 处理创建实例的后台任务
@@ -195,3 +200,58 @@ func (s *Service) ResetInstancePassword(userID uint, instanceID uint) (uint, err
 func (s *Service) GetInstanceNewPassword(userID uint, instanceID uint, taskID uint) (string, int64, error) {
 	return s.instance.GetInstanceNewPassword(userID, instanceID, taskID)
 }
+
+// GetInstanceSnapshots 获取实例快照列表
+func (s *Service) GetInstanceSnapshots(userID, instanceID uint) ([]providerModel.InstanceSnapshot, error) {
+	return s.instance.GetInstanceSnapshots(userID, instanceID)
+}
+
+// CreateInstanceSnapshot 创建实例快照
+func (s *Service) CreateInstanceSnapshot(userID, instanceID uint, req userModel.CreateSnapshotRequest) (*userModel.SnapshotTaskResponse, error) {
+	return s.instance.CreateInstanceSnapshot(userID, instanceID, req)
+}
+
+// RestoreInstanceSnapshot 恢复实例快照
+func (s *Service) RestoreInstanceSnapshot(userID, instanceID, snapshotID uint) (*userModel.SnapshotTaskResponse, error) {
+	return s.instance.RestoreInstanceSnapshot(userID, instanceID, snapshotID)
+}
+
+// DeleteInstanceSnapshot 删除实例快照
+func (s *Service) DeleteInstanceSnapshot(userID, instanceID, snapshotID uint) (*userModel.SnapshotTaskResponse, error) {
+	return s.instance.DeleteInstanceSnapshot(userID, instanceID, snapshotID)
+}
+
+// GetInstanceBackups 获取实例备份列表
+func (s *Service) GetInstanceBackups(userID, instanceID uint) ([]providerModel.InstanceBackup, error) {
+	return s.instance.GetInstanceBackups(userID, instanceID)
+}
+
+// CreateInstanceBackup 创建实例备份
+func (s *Service) CreateInstanceBackup(userID, instanceID uint) (*userModel.BackupTaskResponse, error) {
+	return s.instance.CreateInstanceBackup(userID, instanceID)
+}
+
+// RestoreBackup 恢复备份到实例
+func (s *Service) RestoreBackup(userID, backupID uint, req userModel.RestoreBackupRequest) (*userModel.BackupTaskResponse, error) {
+	return s.instance.RestoreBackup(userID, backupID, req)
+}
+
+// DeleteBackup 删除备份
+func (s *Service) DeleteBackup(userID, backupID uint) error {
+	return s.instance.DeleteBackup(userID, backupID)
+}
+
+// GetBackupSchedule 获取实例定时备份计划
+func (s *Service) GetBackupSchedule(userID, instanceID uint) (*providerModel.BackupSchedule, error) {
+	return s.instance.GetBackupSchedule(userID, instanceID)
+}
+
+// SetBackupSchedule 设置实例定时备份计划
+func (s *Service) SetBackupSchedule(userID, instanceID uint, req userModel.BackupScheduleRequest) (*providerModel.BackupSchedule, error) {
+	return s.instance.SetBackupSchedule(userID, instanceID, req)
+}
+
+// DeleteBackupSchedule 删除实例定时备份计划
+func (s *Service) DeleteBackupSchedule(userID, instanceID uint) error {
+	return s.instance.DeleteBackupSchedule(userID, instanceID)
+}
@@ -0,0 +1,406 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"oneclickvirt/global"
+	"oneclickvirt/model/system"
+	"oneclickvirt/utils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	deliveryTimeout     = 15 * time.Second // 单次请求超时
+	deliveryLease       = 2 * time.Minute  // 投递抢占租期，超过后其他投递者可重新处理
+	retryBaseDelay      = 30 * time.Second // 首次重试间隔，之后指数增长
+	retryMaxDelay       = 6 * time.Hour    // 最大重试间隔
+	maxResponseBodySize = 1024             // 保存的响应内容长度
+	dueBatchSize        = 100              // 每轮处理的到期投递数
+	maxConcurrentSends  = 5                // 最大并发投递数
+	deliveryRetention   = 30 * 24 * time.Hour
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-OneClickVirt-Event"
+	HeaderDelivery  = "X-OneClickVirt-Delivery"
+	HeaderTimestamp = "X-OneClickVirt-Timestamp"
+	HeaderSignature = "X-OneClickVirt-Signature"
+)
+
+// sendSemaphore 限制全局并发投递数量
+var sendSemaphore = make(chan struct{}, maxConcurrentSends)
+
+// Payload 投递的请求体
+type Payload struct {
+	ID        string      `json:"id"`        // 投递UUID
+	Event     string      `json:"event"`     // 事件类型
+	Timestamp int64       `json:"timestamp"` // 事件发生时间（Unix秒）
+	Data      interface{} `json:"data"`      // 事件数据
+}
+
+// Sign 计算签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+// 接收方使用相同方式计算并与 X-OneClickVirt-Signature 中 sha256= 之后的部分比对
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publish 发布事件到所有订阅的Webhook
+// 投递记录先持久化再异步发送，失败由调度器按退避策略重试；任何错误都不影响调用方
+func Publish(event string, data interface{}) {
+	NewService().Publish(event, data)
+}
+
+// Publish 发布事件到所有订阅的Webhook
+func (s *Service) Publish(event string, data interface{}) {
+	if global.APP_DB == nil {
+		return
+	}
+
+	var hooks []system.Webhook
+	if err := global.APP_DB.Where("enabled = ?", true).Find(&hooks).Error; err != nil {
+		global.APP_LOG.Warn("查询Webhook失败", zap.String("event", event), zap.Error(err))
+		return
+	}
+
+	var ids []uint
+	for i := range hooks {
+		if !hooks[i].Subscribes(event) {
+			continue
+		}
+		delivery, err := s.enqueue(&hooks[i], event, data, hooks[i].MaxAttempts)
+		if err != nil {
+			global.APP_LOG.Warn("创建Webhook投递记录失败",
+				zap.Uint("webhookId", hooks[i].ID),
+				zap.String("event", event),
+				zap.Error(err))
+			continue
+		}
+		ids = append(ids, delivery.ID)
+	}
+
+	if len(ids) > 0 {
+		go s.deliverBatch(ids)
+	}
+}
+
+// enqueue 创建待投递记录
+func (s *Service) enqueue(hook *system.Webhook, event string, data interface{}, maxAttempts int) (*system.WebhookDelivery, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	now := time.Now()
+	deliveryUUID := uuid.New().String()
+	body, err := json.Marshal(Payload{
+		ID:        deliveryUUID,
+		Event:     event,
+		Timestamp: now.Unix(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化事件数据失败: %v", err)
+	}
+
+	delivery := system.WebhookDelivery{
+		UUID:        deliveryUUID,
+		WebhookID:   hook.ID,
+		Event:       event,
+		Payload:     string(body),
+		Status:      system.WebhookDeliveryPending,
+		MaxAttempts: maxAttempts,
+		NextRetryAt: &now,
+	}
+	if err := global.APP_DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ProcessDueDeliveries 投递所有到期的待投递记录（由调度器定期调用）
+func (s *Service) ProcessDueDeliveries() {
+	var ids []uint
+	if err := global.APP_DB.Model(&system.WebhookDelivery{}).
+		Where("status = ? AND next_retry_at <= ?", system.WebhookDeliveryPending, time.Now()).
+		Order("next_retry_at ASC").
+		Limit(dueBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		global.APP_LOG.Error("查询待投递Webhook失败", zap.Error(err))
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	global.APP_LOG.Debug("处理到期的Webhook投递", zap.Int("count", len(ids)))
+	s.deliverBatch(ids)
+}
+
+// CleanupDeliveries 清理过期的投递记录
+func (s *Service) CleanupDeliveries() {
+	result := global.APP_DB.
+		Where("status <> ? AND created_at < ?", system.WebhookDeliveryPending, time.Now().Add(-deliveryRetention)).
+		Delete(&system.WebhookDelivery{})
+	if result.Error != nil {
+		global.APP_LOG.Error("清理Webhook投递记录失败", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		global.APP_LOG.Info("已清理过期的Webhook投递记录", zap.Int64("count", result.RowsAffected))
+	}
+}
+
+// SendTest 向指定Webhook同步发送测试事件，返回投递结果
+// 测试事件不检查启用状态和订阅事件，且不重试
+func (s *Service) SendTest(webhookID uint) (*system.WebhookDelivery, error) {
+	hook, err := s.GetWebhook(webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.enqueue(hook, system.WebhookEventTest, map[string]interface{}{
+		"webhookId": hook.ID,
+		"name":      hook.Name,
+		"message":   "这是一条来自OneClickVirt的测试事件",
+	}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("创建测试投递失败: %v", err)
+	}
+
+	s.deliver(delivery.ID)
+	return s.getDelivery(delivery.ID)
+}
+
+// Redeliver 重新投递指定记录，重置重试次数并同步发送一次
+func (s *Service) Redeliver(deliveryID uint) (*system.WebhookDelivery, error) {
+	delivery, err := s.getDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetWebhook(delivery.WebhookID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := global.APP_DB.Model(delivery).Updates(map[string]interface{}{
+		"status":        system.WebhookDeliveryPending,
+		"attempts":      0,
+		"next_retry_at": &now,
+		"error_message": "",
+	}).Error; err != nil {
+		return nil, fmt.Errorf("重置投递记录失败: %v", err)
+	}
+
+	s.deliver(deliveryID)
+	return s.getDelivery(deliveryID)
+}
+
+// getDelivery 获取投递记录
+func (s *Service) getDelivery(id uint) (*system.WebhookDelivery, error) {
+	var delivery system.WebhookDelivery
+	if err := global.APP_DB.First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投递记录不存在")
+		}
+		return nil, fmt.Errorf("获取投递记录失败: %v", err)
+	}
+	return &delivery, nil
+}
+
+// deliverBatch 并发投递一批记录
+func (s *Service) deliverBatch(ids []uint) {
+	defer func() {
+		if r := recover(); r != nil {
+			global.APP_LOG.Error("Webhook投递panic", zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		sendSemaphore <- struct{}{}
+		go func(deliveryID uint) {
+			defer func() {
+				<-sendSemaphore
+				wg.Done()
+			}()
+			s.deliver(deliveryID)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// claimDelivery 抢占到期的投递记录，避免即时投递与调度器重试重复发送
+func (s *Service) claimDelivery(id uint) bool {
+	now := time.Now()
+	lease := now.Add(deliveryLease)
+	result := global.APP_DB.Model(&system.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_retry_at <= ?", id, system.WebhookDeliveryPending, now).
+		Update("next_retry_at", &lease)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// deliver 执行一次投递并记录结果
+func (s *Service) deliver(deliveryID uint) {
+	if !s.claimDelivery(deliveryID) {
+		return
+	}
+
+	delivery, err := s.getDelivery(deliveryID)
+	if err != nil {
+		global.APP_LOG.Warn("获取Webhook投递记录失败", zap.Uint("deliveryId", deliveryID), zap.Error(err))
+		return
+	}
+
+	var hook system.Webhook
+	if err := global.APP_DB.First(&hook, delivery.WebhookID).Error; err != nil {
+		s.finish(delivery, nil, 0, "", 0, "Webhook已删除或不存在", false)
+		return
+	}
+	if !hook.Enabled && delivery.Event != system.WebhookEventTest {
+		s.finish(delivery, &hook, 0, "", 0, "Webhook已禁用", false)
+		return
+	}
+
+	start := time.Now()
+	code, body, sendErr := s.send(&hook, delivery)
+	duration := time.Since(start).Milliseconds()
+
+	if sendErr == nil && code >= 200 && code < 300 {
+		s.finish(delivery, &hook, code, body, duration, "", true)
+		return
+	}
+
+	errMsg := fmt.Sprintf("接收方返回状态码 %d", code)
+	if sendErr != nil {
+		errMsg = sendErr.Error()
+	}
+	global.APP_LOG.Warn("Webhook投递失败",
+		zap.Uint("webhookId", hook.ID),
+		zap.Uint("deliveryId", delivery.ID),
+		zap.String("event", delivery.Event),
+		zap.Int("attempt", delivery.Attempts+1),
+		zap.String("error", errMsg))
+	s.finish(delivery, &hook, code, body, duration, errMsg, false)
+}
+
+// finish 记录投递结果，失败且未超过最大次数时按指数退避安排重试
+func (s *Service) finish(delivery *system.WebhookDelivery, hook *system.Webhook, code int, body string, durationMs int64, errMsg string, success bool) {
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	if hook == nil {
+		// Webhook已不存在，无需继续重试
+		attempts = delivery.MaxAttempts
+	}
+
+	updates := map[string]interface{}{
+		"attempts":      attempts,
+		"response_code": code,
+		"response_body": truncate(body, maxResponseBodySize),
+		"error_message": truncate(errMsg, 512),
+		"duration_ms":   durationMs,
+	}
+
+	status := system.WebhookDeliveryPending
+	switch {
+	case success:
+		status = system.WebhookDeliverySuccess
+		updates["delivered_at"] = &now
+		updates["next_retry_at"] = nil
+	case attempts >= delivery.MaxAttempts || (hook != nil && !hook.Enabled && delivery.Event != system.WebhookEventTest):
+		status = system.WebhookDeliveryFailed
+		updates["next_retry_at"] = nil
+	default:
+		next := now.Add(retryDelay(attempts))
+		updates["next_retry_at"] = &next
+	}
+	updates["status"] = status
+
+	if err := global.APP_DB.Model(delivery).Updates(updates).Error; err != nil {
+		global.APP_LOG.Error("更新Webhook投递记录失败", zap.Uint("deliveryId", delivery.ID), zap.Error(err))
+	}
+
+	if hook != nil {
+		lastStatus := system.WebhookDeliveryFailed
+		if success {
+			lastStatus = system.WebhookDeliverySuccess
+		}
+		global.APP_DB.Model(hook).UpdateColumns(map[string]interface{}{
+			"last_delivery_at":     &now,
+			"last_delivery_status": lastStatus,
+		})
+	}
+}
+
+// truncate 按字符截断字符串，避免截断多字节字符导致写库失败
+func truncate(str string, max int) string {
+	str = strings.ToValidUTF8(str, "")
+	runes := []rune(str)
+	if len(runes) <= max {
+		return str
+	}
+	return string(runes[:max])
+}
+
+// retryDelay 计算第 attempts 次失败后的重试间隔
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// send 发送HTTP请求，返回响应状态码和截断后的响应内容
+func (s *Service) send(hook *system.Webhook, delivery *system.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("构造请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OneClickVirt-Webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.UUID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(hook.Secret, timestamp, body))
+
+	resp, err := utils.GetDefaultHTTPClient().Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	// 读完剩余内容以便连接复用
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	return resp.StatusCode, string(respBody), nil
+}
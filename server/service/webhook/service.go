@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"oneclickvirt/global"
+	adminModel "oneclickvirt/model/admin"
+	"oneclickvirt/model/system"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Service Webhook服务
+// 仅依赖global/model/utils，供任务、调度器、流量等模块直接调用而不产生循环依赖
+type Service struct{}
+
+// NewService 创建Webhook服务
+func NewService() *Service {
+	return &Service{}
+}
+
+// defaultMaxAttempts 默认最大投递次数
+const defaultMaxAttempts = 8
+
+// normalizeEvents 校验并整理订阅事件
+func normalizeEvents(events []string) (string, error) {
+	valid := make(map[string]bool, len(system.WebhookEvents)+1)
+	valid[system.WebhookEventAll] = true
+	for _, e := range system.WebhookEvents {
+		valid[e] = true
+	}
+
+	seen := make(map[string]bool, len(events))
+	result := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		if !valid[e] {
+			return "", fmt.Errorf("不支持的事件类型: %s", e)
+		}
+		seen[e] = true
+		result = append(result, e)
+	}
+	if len(result) == 0 {
+		return "", errors.New("至少需要订阅一个事件")
+	}
+	if seen[system.WebhookEventAll] {
+		return system.WebhookEventAll, nil
+	}
+	return strings.Join(result, ","), nil
+}
+
+// generateSecret 生成随机签名密钥
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// GetEvents 获取可订阅的事件列表
+func (s *Service) GetEvents() []string {
+	return append([]string{system.WebhookEventAll}, system.WebhookEvents...)
+}
+
+// ListWebhooks 分页获取Webhook列表
+func (s *Service) ListWebhooks(req adminModel.WebhookListRequest) ([]system.Webhook, int64, error) {
+	query := global.APP_DB.Model(&system.Webhook{})
+	if req.Keyword != "" {
+		like := "%" + req.Keyword + "%"
+		query = query.Where("name LIKE ? OR url LIKE ?", like, like)
+	}
+	if req.Enabled != nil {
+		query = query.Where("enabled = ?", *req.Enabled)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计Webhook数量失败: %v", err)
+	}
+
+	var webhooks []system.Webhook
+	if err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&webhooks).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取Webhook列表失败: %v", err)
+	}
+	return webhooks, total, nil
+}
+
+// GetWebhook 获取单个Webhook
+func (s *Service) GetWebhook(id uint) (*system.Webhook, error) {
+	var hook system.Webhook
+	if err := global.APP_DB.First(&hook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Webhook不存在")
+		}
+		return nil, fmt.Errorf("获取Webhook失败: %v", err)
+	}
+	return &hook, nil
+}
+
+// CreateWebhook 创建Webhook
+func (s *Service) CreateWebhook(req adminModel.CreateWebhookRequest) (*system.Webhook, error) {
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return nil, fmt.Errorf("生成签名密钥失败: %v", err)
+		}
+	}
+
+	maxAttempts := req.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	hook := system.Webhook{
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Enabled:     req.Enabled,
+		Description: req.Description,
+		MaxAttempts: maxAttempts,
+	}
+	if err := global.APP_DB.Create(&hook).Error; err != nil {
+		return nil, fmt.Errorf("创建Webhook失败: %v", err)
+	}
+
+	global.APP_LOG.Info("创建Webhook成功",
+		zap.Uint("webhookId", hook.ID),
+		zap.String("name", hook.Name),
+		zap.String("events", hook.Events))
+	return &hook, nil
+}
+
+// UpdateWebhook 更新Webhook
+func (s *Service) UpdateWebhook(id uint, req adminModel.UpdateWebhookRequest) (*system.Webhook, error) {
+	hook, err := s.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":        req.Name,
+		"url":         req.URL,
+		"events":      events,
+		"enabled":     req.Enabled,
+		"description": req.Description,
+	}
+	if req.MaxAttempts > 0 {
+		updates["max_attempts"] = req.MaxAttempts
+	}
+	if secret := strings.TrimSpace(req.Secret); secret != "" {
+		updates["secret"] = secret
+	}
+
+	if err := global.APP_DB.Model(hook).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("更新Webhook失败: %v", err)
+	}
+	return s.GetWebhook(id)
+}
+
+// DeleteWebhook 删除Webhook，未完成的投递同时标记为失败
+func (s *Service) DeleteWebhook(id uint) error {
+	hook, err := s.GetWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	return global.APP_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(hook).Error; err != nil {
+			return fmt.Errorf("删除Webhook失败: %v", err)
+		}
+		if err := tx.Model(&system.WebhookDelivery{}).
+			Where("webhook_id = ? AND status = ?", id, system.WebhookDeliveryPending).
+			Updates(map[string]interface{}{
+				"status":        system.WebhookDeliveryFailed,
+				"next_retry_at": nil,
+				"error_message": "Webhook已删除",
+			}).Error; err != nil {
+			return fmt.Errorf("取消待投递记录失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// ListDeliveries 分页获取投递记录
+func (s *Service) ListDeliveries(req adminModel.WebhookDeliveryListRequest) ([]system.WebhookDelivery, int64, error) {
+	query := global.APP_DB.Model(&system.WebhookDelivery{})
+	if req.WebhookID > 0 {
+		query = query.Where("webhook_id = ?", req.WebhookID)
+	}
+	if req.Event != "" {
+		query = query.Where("event = ?", req.Event)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计投递记录失败: %v", err)
+	}
+
+	var deliveries []system.WebhookDelivery
+	if err := query.Order("id DESC").
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取投递记录失败: %v", err)
+	}
+	return deliveries, total, nil
+}
@@ -627,8 +627,11 @@ func initLevelConfigurations() {
 			"disk":      1024, // 1GB
 			"bandwidth": 100,  // 100Mbps
 		},
-		MaxTraffic: 102400, // 100GB
-		ExpiryDays: 0,      // 0表示不过期
+		MaxTraffic:   102400, // 100GB
+		ExpiryDays:   0,      // 0表示不过期
+		MaxSnapshots: 1,      // 每个实例最多1个快照
+		MaxBackups:   1,      // 每个用户最多保留1个备份
+		MaxDomains:   1,      // 每个用户最多绑定1个域名
 	}
 
 	// 等级2: 中级档次
@@ -640,8 +643,11 @@ func initLevelConfigurations() {
 			"disk":      20480, // 20GB
 			"bandwidth": 200,   // 200Mbps
 		},
-		MaxTraffic: 204800, // 200GB
-		ExpiryDays: 0,      // 0表示不过期
+		MaxTraffic:   204800, // 200GB
+		ExpiryDays:   0,      // 0表示不过期
+		MaxSnapshots: 2,      // 每个实例最多2个快照
+		MaxBackups:   2,      // 每个用户最多保留2个备份
+		MaxDomains:   3,      // 每个用户最多绑定3个域名
 	}
 
 	// 等级3: 高级档次
@@ -653,8 +659,11 @@ func initLevelConfigurations() {
 			"disk":      40960, // 40GB
 			"bandwidth": 500,   // 500Mbps
 		},
-		MaxTraffic: 307200, // 300GB
-		ExpiryDays: 0,      // 0表示不过期
+		MaxTraffic:   307200, // 300GB
+		ExpiryDays:   0,      // 0表示不过期
+		MaxSnapshots: 3,      // 每个实例最多3个快照
+		MaxBackups:   3,      // 每个用户最多保留3个备份
+		MaxDomains:   5,      // 每个用户最多绑定5个域名
 	}
 
 	// 等级4: 超级档次
@@ -666,8 +675,11 @@ func initLevelConfigurations() {
 			"disk":      81920, // 80GB
 			"bandwidth": 1000,  // 1000Mbps
 		},
-		MaxTraffic: 409600, // 400GB
-		ExpiryDays: 0,      // 0表示不过期
+		MaxTraffic:   409600, // 400GB
+		ExpiryDays:   0,      // 0表示不过期
+		MaxSnapshots: 5,      // 每个实例最多5个快照
+		MaxBackups:   5,      // 每个用户最多保留5个备份
+		MaxDomains:   10,     // 每个用户最多绑定10个域名
 	}
 
 	// 等级5: 管理员档次
@@ -679,8 +691,11 @@ func initLevelConfigurations() {
 			"disk":      163840, // 160GB
 			"bandwidth": 2000,   // 2000Mbps
 		},
-		MaxTraffic: 512000, // 500GB
-		ExpiryDays: 0,      // 0表示不过期
+		MaxTraffic:   512000, // 500GB
+		ExpiryDays:   0,      // 0表示不过期
+		MaxSnapshots: 10,     // 每个实例最多10个快照
+		MaxBackups:   10,     // 每个用户最多保留10个备份
+		MaxDomains:   20,     // 每个用户最多绑定20个域名
 	}
 
 	global.APP_LOG.Info("等级与带宽配置初始化完成")
@@ -126,6 +126,50 @@ func ValidateToken(tokenString string) (*jwt.MapClaims, error) {
 	return claims, nil
 }
 
+// TwoFactorTokenTTL 两步验证令牌有效期
+const TwoFactorTokenTTL = 5 * time.Minute
+
+// getTwoFactorKey 两步验证令牌使用独立的派生密钥签名，确保其不能被当作登录JWT使用
+func getTwoFactorKey() []byte {
+	return []byte(GetJWTKey() + ":two-factor")
+}
+
+// GenerateTwoFactorToken 生成密码校验通过后、两步验证完成前使用的临时令牌
+func GenerateTwoFactorToken(userID uint) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"purpose": "two_factor",
+		"exp":     now.Add(TwoFactorTokenTTL).Unix(),
+		"iat":     now.Unix(),
+		"jti":     generateTokenID(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(getTwoFactorKey())
+}
+
+// ValidateTwoFactorToken 验证两步验证临时令牌，返回用户ID
+func ValidateTwoFactorToken(tokenString string) (uint, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
+		}
+		return getTwoFactorKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, fmt.Errorf("两步验证令牌无效或已过期")
+	}
+	if purpose, _ := claims["purpose"].(string); purpose != "two_factor" {
+		return 0, fmt.Errorf("两步验证令牌无效")
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok || userID <= 0 {
+		return 0, fmt.Errorf("两步验证令牌无效")
+	}
+	return uint(userID), nil
+}
+
 // generateTokenID 生成唯一的token ID
 func generateTokenID() string {
 	return fmt.Sprintf("%d_%d", time.Now().UnixNano(), os.Getpid())
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 数据库方言，取值与 GORM Dialector.Name() 一致，MariaDB 使用 mysql 方言
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// DBDialect 获取数据库连接的SQL方言
+func DBDialect(db *gorm.DB) string {
+	if db == nil || db.Dialector == nil {
+		return DialectMySQL
+	}
+	return db.Dialector.Name()
+}
+
+// UpsertClause 生成"插入冲突时用新值更新指定列"的子句，追加在 INSERT 语句末尾
+// conflictColumns 为触发冲突的唯一索引列，PostgreSQL 和 SQLite 必须明确指定；
+// 为空表示表上除主键外没有唯一索引，MySQL 的 ON DUPLICATE KEY UPDATE 实际不会触发，
+// 其他数据库直接返回空串，行为与 MySQL 保持一致
+func UpsertClause(db *gorm.DB, conflictColumns []string, updateColumns ...string) string {
+	assignments := make([]string, 0, len(updateColumns))
+	for _, column := range updateColumns {
+		assignments = append(assignments, fmt.Sprintf("%s = %s", column, InsertedValue(db, column)))
+	}
+	return UpsertAssignClause(db, conflictColumns, assignments...)
+}
+
+// UpsertAssignClause 与 UpsertClause 相同，但更新内容为完整的赋值表达式（如 "a = a + 1"）
+func UpsertAssignClause(db *gorm.DB, conflictColumns []string, assignments ...string) string {
+	if DBDialect(db) == DialectMySQL {
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	}
+	if len(conflictColumns) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(conflictColumns, ", "), strings.Join(assignments, ", "))
+}
+
+// UpsertIgnoreClause 生成"插入冲突时忽略"的子句，替代 MySQL 的 INSERT IGNORE
+func UpsertIgnoreClause(db *gorm.DB) string {
+	if DBDialect(db) == DialectMySQL {
+		return "ON DUPLICATE KEY UPDATE id = id"
+	}
+	return "ON CONFLICT DO NOTHING"
+}
+
+// InsertedValue 在冲突更新子句中引用本次待插入的列值
+func InsertedValue(db *gorm.DB, column string) string {
+	if DBDialect(db) == DialectMySQL {
+		return "VALUES(" + column + ")"
+	}
+	return "excluded." + column
+}
+
+// TimeParam 时间类型的占位符
+// PostgreSQL 无法推断 SELECT 列表中占位符的类型，会当作文本处理，需要显式转换
+func TimeParam(db *gorm.DB) string {
+	if DBDialect(db) == DialectPostgres {
+		return "CAST(? AS TIMESTAMPTZ)"
+	}
+	return "?"
+}
+
+// DateExpr 取时间列的日期部分
+// SQLite 中时间以带时区的文本存储（如 2006-01-02 15:04:05+08:00），date() 会换算成UTC，
+// 这里直接截取本地日期文本
+func DateExpr(db *gorm.DB, column string) string {
+	if DBDialect(db) == DialectSQLite {
+		return fmt.Sprintf("substr(%s, 1, 10)", column)
+	}
+	return fmt.Sprintf("DATE(%s)", column)
+}
+
+// TruncateTimeExpr 将时间列截断到整天（unit=day）或整点（unit=hour）
+// SQLite 结果保留原值的时区后缀，与驱动写入的格式一致，保证唯一索引能正确命中
+func TruncateTimeExpr(db *gorm.DB, column, unit string) string {
+	switch DBDialect(db) {
+	case DialectPostgres:
+		return fmt.Sprintf("date_trunc('%s', %s)", unit, column)
+	case DialectSQLite:
+		if unit == "hour" {
+			return fmt.Sprintf("substr(%[1]s, 1, 13) || ':00:00' || substr(%[1]s, -6)", column)
+		}
+		return fmt.Sprintf("substr(%[1]s, 1, 10) || ' 00:00:00' || substr(%[1]s, -6)", column)
+	default:
+		if unit == "hour" {
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')", column)
+		}
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d 00:00:00')", column)
+	}
+}
+
+// QuoteColumn 按数据库方言为列名加引号，用于 key 等在部分数据库中是保留字的列名
+func QuoteColumn(db *gorm.DB, column string) string {
+	if db == nil || db.Statement == nil {
+		return "`" + column + "`"
+	}
+	return db.Statement.Quote(column)
+}
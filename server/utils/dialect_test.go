@@ -0,0 +1,87 @@
+//go:build cgo
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type dialectTestRecord struct {
+	ID         uint      `gorm:"primarykey"`
+	InstanceID uint      `gorm:"uniqueIndex:idx_instance_time"`
+	Timestamp  time.Time `gorm:"uniqueIndex:idx_instance_time"`
+	Bytes      int64
+}
+
+func openDialectTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:?_loc=auto"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开SQLite失败: %v", err)
+	}
+	if err := db.AutoMigrate(&dialectTestRecord{}); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return db
+}
+
+func TestUpsertClauseSQLite(t *testing.T) {
+	db := openDialectTestDB(t)
+	if DBDialect(db) != DialectSQLite {
+		t.Fatalf("方言应为 sqlite，实际为 %s", DBDialect(db))
+	}
+
+	ts := time.Date(2025, 1, 2, 10, 0, 0, 0, time.Local)
+	insertSQL := fmt.Sprintf("INSERT INTO dialect_test_records (instance_id, timestamp, bytes) VALUES (?, %s, ?) %s",
+		TimeParam(db), UpsertClause(db, []string{"instance_id", "timestamp"}, "bytes"))
+	for _, bytes := range []int64{100, 300} {
+		if err := db.Exec(insertSQL, 1, ts, bytes).Error; err != nil {
+			t.Fatalf("upsert失败: %v", err)
+		}
+	}
+
+	var records []dialectTestRecord
+	db.Find(&records)
+	if len(records) != 1 || records[0].Bytes != 300 {
+		t.Fatalf("冲突时应更新已有记录，实际: %+v", records)
+	}
+
+	// 没有唯一索引时不生成冲突子句，与MySQL行为一致
+	if clause := UpsertClause(db, nil, "bytes"); clause != "" {
+		t.Errorf("未指定冲突列时应返回空串，实际: %s", clause)
+	}
+}
+
+func TestTruncateTimeExprSQLite(t *testing.T) {
+	db := openDialectTestDB(t)
+
+	ts := time.Date(2025, 1, 2, 10, 23, 45, 0, time.Local)
+	db.Create(&dialectTestRecord{InstanceID: 1, Timestamp: ts})
+
+	// 截断结果写回后应与驱动直接写入整点时间的文本一致，否则唯一索引无法命中
+	cases := map[string]time.Time{
+		"hour": time.Date(2025, 1, 2, 10, 0, 0, 0, time.Local),
+		"day":  time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local),
+	}
+	for unit, want := range cases {
+		insertSQL := fmt.Sprintf("INSERT INTO dialect_test_records (instance_id, timestamp, bytes) SELECT 2, %s, 0 FROM dialect_test_records WHERE instance_id = 1",
+			TruncateTimeExpr(db, "timestamp", unit))
+		if err := db.Exec(insertSQL).Error; err != nil {
+			t.Fatalf("%s 截断写入失败: %v", unit, err)
+		}
+		var count int64
+		db.Model(&dialectTestRecord{}).Where("instance_id = ? AND timestamp = ?", 2, want).Count(&count)
+		if count != 1 {
+			t.Errorf("%s 截断结果与 %v 不匹配", unit, want)
+		}
+		db.Where("instance_id = ?", 2).Delete(&dialectTestRecord{})
+	}
+}
@@ -18,6 +18,7 @@ import (
 )
 
 type SSHConfig struct {
+	ProviderID     uint // 所属Provider ID，用于校验SSH主机密钥
 	Host           string
 	Port           int
 	Username       string
@@ -100,7 +101,7 @@ func dialSSH(config SSHConfig) (*ssh.Client, context.CancelFunc, *sync.WaitGroup
 	sshConfig := &ssh.ClientConfig{
 		User:            config.Username,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: ProviderHostKeyCallback(config.ProviderID),
 		Timeout:         config.ConnectTimeout,
 	}
 
@@ -308,6 +309,15 @@ func (c *SSHClient) Reconnect() error {
 }
 
 func (c *SSHClient) Execute(command string) (string, error) {
+	return c.ExecuteWithTimeout(command, c.config.ExecuteTimeout)
+}
+
+// ExecuteWithTimeout 使用指定超时时间执行命令，用于导出/导入等耗时较长的操作
+func (c *SSHClient) ExecuteWithTimeout(command string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		timeout = c.config.ExecuteTimeout
+	}
+
 	// 检查连接健康状态，如果不健康则尝试重连
 	if !c.IsHealthy() {
 		global.APP_LOG.Warn("SSH连接不健康，尝试重连",
@@ -318,7 +328,7 @@ func (c *SSHClient) Execute(command string) (string, error) {
 	}
 
 	// 尝试执行命令，如果失败则重试一次（可能是连接刚断开）
-	output, err := c.executeCommand(command)
+	output, err := c.executeCommand(command, timeout)
 	if err != nil && strings.Contains(err.Error(), "failed to create SSH session") {
 		global.APP_LOG.Warn("SSH session创建失败，尝试重连后重试",
 			zap.String("host", c.config.Host),
@@ -330,7 +340,7 @@ func (c *SSHClient) Execute(command string) (string, error) {
 		}
 
 		// 重试执行
-		output, err = c.executeCommand(command)
+		output, err = c.executeCommand(command, timeout)
 		if err != nil {
 			return output, fmt.Errorf("command failed after reconnection: %w", err)
 		}
@@ -339,8 +349,18 @@ func (c *SSHClient) Execute(command string) (string, error) {
 	return output, err
 }
 
+// TimeoutFromContext 根据context的截止时间计算剩余超时，没有截止时间时返回fallback
+func TimeoutFromContext(ctx context.Context, fallback time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining > 0 {
+			return remaining
+		}
+	}
+	return fallback
+}
+
 // executeCommand 执行SSH命令的内部方法
-func (c *SSHClient) executeCommand(command string) (string, error) {
+func (c *SSHClient) executeCommand(command string, timeout time.Duration) (string, error) {
 	session, err := c.client.NewSession()
 	if err != nil {
 		return "", fmt.Errorf("failed to create SSH session: %w", err)
@@ -372,7 +392,7 @@ func (c *SSHClient) executeCommand(command string) (string, error) {
 	}()
 
 	// 等待命令完成或超时
-	timeoutTimer := time.NewTimer(c.config.ExecuteTimeout)
+	timeoutTimer := time.NewTimer(timeout)
 	defer timeoutTimer.Stop()
 
 	select {
@@ -391,7 +411,7 @@ func (c *SSHClient) executeCommand(command string) (string, error) {
 		return string(output), nil
 	case <-timeoutTimer.C:
 		session.Signal(ssh.SIGKILL) // 强制终止会话
-		return "", fmt.Errorf("command execution timeout after %v", c.config.ExecuteTimeout)
+		return "", fmt.Errorf("command execution timeout after %v", timeout)
 	}
 }
 
@@ -658,6 +678,57 @@ func (c *SSHClient) UploadContent(content, remotePath string, perm os.FileMode)
 	return nil
 }
 
+// DownloadToWriter 通过SFTP将远程文件写入writer，返回写入的字节数
+func (c *SSHClient) DownloadToWriter(remotePath string, w io.Writer) (int64, error) {
+	sftpClient, err := sftp.NewClient(c.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+	defer sftpClient.Close()
+
+	remoteFile, err := sftpClient.Open(remotePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open remote file %s: %w", remotePath, err)
+	}
+	defer remoteFile.Close()
+
+	written, err := io.Copy(w, remoteFile)
+	if err != nil {
+		return written, fmt.Errorf("failed to download remote file %s: %w", remotePath, err)
+	}
+	return written, nil
+}
+
+// UploadFromReader 通过SFTP将reader中的内容上传到远程文件
+func (c *SSHClient) UploadFromReader(r io.Reader, remotePath string, perm os.FileMode) error {
+	sftpClient, err := sftp.NewClient(c.client)
+	if err != nil {
+		return fmt.Errorf("failed to create SFTP client: %w", err)
+	}
+	defer sftpClient.Close()
+
+	if lastSlash := strings.LastIndex(remotePath, "/"); lastSlash > 0 {
+		if err := sftpClient.MkdirAll(remotePath[:lastSlash]); err != nil {
+			return fmt.Errorf("failed to create remote directory %s: %w", remotePath[:lastSlash], err)
+		}
+	}
+
+	remoteFile, err := sftpClient.Create(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file %s: %w", remotePath, err)
+	}
+	defer remoteFile.Close()
+
+	if _, err := remoteFile.ReadFrom(r); err != nil {
+		return fmt.Errorf("failed to upload to remote file %s: %w", remotePath, err)
+	}
+
+	if err := sftpClient.Chmod(remotePath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	return nil
+}
+
 // ResolveHostToIP 解析主机名到IP地址
 // 如果host已经是IP地址，直接返回；如果是域名，解析为IP地址
 func ResolveHostToIP(host string) ([]string, error) {
@@ -716,6 +787,7 @@ func VerifySSHConnection(client *ssh.Client, expectedHost string) error {
 
 // CreateSSHConnection 创建SSH连接（全局统一函数，用于WebSocket SSH等场景）
 // 返回 SSH client, session 和可能的错误
+// 连接目标是实例而不是Provider节点，实例重置或重装后主机密钥会重新生成，因此不校验主机密钥
 func CreateSSHConnection(host string, port int, username, password string) (*ssh.Client, *ssh.Session, error) {
 	config := &ssh.ClientConfig{
 		User: username,
@@ -744,7 +816,7 @@ func CreateSSHConnection(host string, port int, username, password string) (*ssh
 }
 
 // CreateSSHConnectionFromAddress 创建SSH连接（全局统一函数，直接使用地址字符串）
-// address 格式: "host:port"
+// address 格式: "host:port"，与 CreateSSHConnection 相同，连接目标是实例，不校验主机密钥
 func CreateSSHConnectionFromAddress(address, username, password string) (*ssh.Client, *ssh.Session, error) {
 	config := &ssh.ClientConfig{
 		User: username,
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"oneclickvirt/global"
+	providerModel "oneclickvirt/model/provider"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HostKeyMismatchError 节点SSH主机密钥与记录不一致
+type HostKeyMismatchError struct {
+	ProviderID uint
+	Expected   string // 已记录的密钥指纹
+	Actual     string // 本次连接的密钥指纹
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("SSH主机密钥与记录不一致（Provider %d，已记录 %s，当前 %s），可能存在中间人攻击，请核实后在管理后台确认新密钥",
+		e.ProviderID, e.Expected, e.Actual)
+}
+
+// IsHostKeyMismatch 判断错误是否由SSH主机密钥不一致引起
+func IsHostKeyMismatch(err error) bool {
+	var mismatchErr *HostKeyMismatchError
+	return errors.As(err, &mismatchErr)
+}
+
+// marshalHostKey 将公钥转换为authorized_keys格式
+func marshalHostKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+// ProviderHostKeyCallback 返回Provider节点的SSH主机密钥校验函数
+// 首次连接时记录主机密钥，之后必须与记录一致。providerID为0时（Provider尚未保存，如添加前的连接测试）无法记录，直接接受
+func ProviderHostKeyCallback(providerID uint) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if providerID == 0 || global.APP_DB == nil {
+			return nil
+		}
+
+		presented := marshalHostKey(key)
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		var record providerModel.ProviderHostKey
+		err := global.APP_DB.Where("provider_id = ?", providerID).First(&record).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			record = providerModel.ProviderHostKey{
+				ProviderID:  providerID,
+				KeyType:     key.Type(),
+				PublicKey:   presented,
+				Fingerprint: fingerprint,
+			}
+			// 并发首次连接时只保留先写入的记录，随后按记录校验
+			if err := global.APP_DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+				return fmt.Errorf("记录SSH主机密钥失败: %w", err)
+			}
+			if err := global.APP_DB.Where("provider_id = ?", providerID).First(&record).Error; err != nil {
+				return fmt.Errorf("读取SSH主机密钥失败: %w", err)
+			}
+			if record.PublicKey == presented {
+				global.APP_LOG.Info("首次连接，已记录Provider SSH主机密钥",
+					zap.Uint("providerID", providerID),
+					zap.String("host", hostname),
+					zap.String("keyType", key.Type()),
+					zap.String("fingerprint", fingerprint))
+				return nil
+			}
+		} else if err != nil {
+			return fmt.Errorf("读取SSH主机密钥失败: %w", err)
+		}
+
+		if record.PublicKey == presented {
+			return nil
+		}
+
+		now := time.Now()
+		if err := global.APP_DB.Model(&record).Updates(map[string]interface{}{
+			"pending_key_type":    key.Type(),
+			"pending_public_key":  presented,
+			"pending_fingerprint": fingerprint,
+			"mismatch_at":         now,
+		}).Error; err != nil {
+			global.APP_LOG.Error("保存不一致的SSH主机密钥失败", zap.Uint("providerID", providerID), zap.Error(err))
+		}
+		global.APP_LOG.Error("Provider SSH主机密钥与记录不一致，已拒绝连接",
+			zap.Uint("providerID", providerID),
+			zap.String("host", hostname),
+			zap.String("remote", remote.String()),
+			zap.String("expected", record.Fingerprint),
+			zap.String("actual", fingerprint))
+		return &HostKeyMismatchError{ProviderID: providerID, Expected: record.Fingerprint, Actual: fingerprint}
+	}
+}
+
+// HasHostKeyMismatch 检查Provider是否存在未确认的主机密钥不一致
+func HasHostKeyMismatch(providerID uint) bool {
+	if providerID == 0 || global.APP_DB == nil {
+		return false
+	}
+	var count int64
+	global.APP_DB.Model(&providerModel.ProviderHostKey{}).
+		Where("provider_id = ? AND mismatch_at IS NOT NULL", providerID).
+		Count(&count)
+	return count > 0
+}
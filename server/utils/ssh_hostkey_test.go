@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsHostKeyMismatch(t *testing.T) {
+	mismatch := &HostKeyMismatchError{ProviderID: 1, Expected: "SHA256:old", Actual: "SHA256:new"}
+
+	// ssh.Dial 以 %w 包装握手错误，外层再包装一次也应能识别
+	wrapped := fmt.Errorf("SSH连接失败: %w", fmt.Errorf("ssh: handshake failed: %w", mismatch))
+	if !IsHostKeyMismatch(wrapped) {
+		t.Errorf("未识别包装后的主机密钥不一致错误: %v", wrapped)
+	}
+	if IsHostKeyMismatch(errors.New("ssh: handshake failed: EOF")) {
+		t.Error("普通握手错误不应判定为主机密钥不一致")
+	}
+	if IsHostKeyMismatch(nil) {
+		t.Error("nil 不应判定为主机密钥不一致")
+	}
+}
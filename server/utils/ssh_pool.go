@@ -53,6 +53,11 @@ func NewSSHConnectionPool(maxIdleTime time.Duration, logger *zap.Logger) *SSHCon
 // GetOrCreate 获取或创建SSH连接（线程安全，支持配置变更检测）
 // 重要：每个providerID对应一个独立的SSH连接，不会串用
 func (p *SSHConnectionPool) GetOrCreate(providerID uint, config SSHConfig) (*SSHClient, error) {
+	// 主机密钥按Provider校验
+	if config.ProviderID == 0 {
+		config.ProviderID = providerID
+	}
+
 	// 先尝试获取现有连接（读锁）
 	p.mu.RLock()
 	if client, exists := p.conns[providerID]; exists {
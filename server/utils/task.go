@@ -94,6 +94,14 @@ func GetDefaultTaskTimeout(taskType string) int {
 		"create-port-mapping": 600,  // 10分钟
 		"delete-port-mapping": 300,  // 5分钟
 		"reset-password":      600,  // 10分钟
+		"create-snapshot":     1200, // 20分钟
+		"restore-snapshot":    1800, // 30分钟
+		"delete-snapshot":     600,  // 10分钟
+		"backup":              7200, // 2小时
+		"restore":             7200, // 2小时
+		"migrate":             7200, // 2小时
+		"resize":              1800, // 30分钟
+		"apply-firewall":      300,  // 5分钟
 	}
 
 	if timeout, exists := timeouts[taskType]; exists {
@@ -1,11 +1,17 @@
 package utils
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// MaxUserDataSize 用户提供的cloud-init user-data最大字节数
+// Proxmox snippets与LXD/Incus配置项均可容纳该大小，同时避免任务数据过大
+const MaxUserDataSize = 16 * 1024
+
 // IsValidLXDInstanceName 检查LXD/Incus实例名称是否有效
 // LXD/Incus实例名称规则：
 // - 长度不超过63个字符
@@ -47,3 +53,18 @@ func IsFloat(s string) bool {
 	_, err := strconv.ParseFloat(s, 64)
 	return err == nil
 }
+
+// ValidateUserData 检查用户提供的cloud-init user-data或启动脚本
+// 要求为不超过 MaxUserDataSize 字节的UTF-8文本，且不包含NUL字符
+func ValidateUserData(userData string) error {
+	if len(userData) > MaxUserDataSize {
+		return fmt.Errorf("user-data大小不能超过%dKB", MaxUserDataSize/1024)
+	}
+	if !utf8.ValidString(userData) {
+		return fmt.Errorf("user-data必须是UTF-8文本")
+	}
+	if strings.ContainsRune(userData, 0) {
+		return fmt.Errorf("user-data不能包含NUL字符")
+	}
+	return nil
+}